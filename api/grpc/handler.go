@@ -2,13 +2,20 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/igodwin/notifier/api/grpc/pb"
+	"github.com/igodwin/notifier/internal/auth"
 	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
+	grpclib "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -18,6 +25,7 @@ type NotifierHandler struct {
 	pb.UnimplementedNotifierServiceServer
 	service domain.NotificationService
 	logger  *logging.Logger
+	version *domain.VersionInfo
 }
 
 // NewNotifierHandler creates a new gRPC handler
@@ -28,6 +36,32 @@ func NewNotifierHandler(svc domain.NotificationService, logger *logging.Logger)
 	}
 }
 
+// WithVersionInfo sets the build and feature information returned by GetVersion
+func (h *NotifierHandler) WithVersionInfo(info *domain.VersionInfo) {
+	h.version = info
+}
+
+// GetVersion returns build information and the enabled subsystems
+func (h *NotifierHandler) GetVersion(ctx context.Context, req *pb.GetVersionRequest) (*pb.GetVersionResponse, error) {
+	if h.version == nil {
+		return nil, status.Errorf(codes.Unimplemented, "version information is not available")
+	}
+
+	notifiers := make([]pb.NotificationType, 0, len(h.version.Notifiers))
+	for _, notifType := range h.version.Notifiers {
+		notifiers = append(notifiers, convertDomainTypeToProto(notifType))
+	}
+
+	return &pb.GetVersionResponse{
+		Version:   h.version.Version,
+		GitCommit: h.version.GitCommit,
+		BuildTime: h.version.BuildTime,
+		Profile:   h.version.Profile,
+		Features:  h.version.Features,
+		Notifiers: notifiers,
+	}, nil
+}
+
 // HealthCheck verifies the service is operational
 func (h *NotifierHandler) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	// TODO: Implement proper health check logic
@@ -58,6 +92,38 @@ func (h *NotifierHandler) SendNotification(ctx context.Context, req *pb.SendNoti
 	// Convert content type, defaulting to text
 	contentType := convertProtoContentTypeToDomain(req.ContentType)
 
+	options := convertProtoOptionsToDomain(req.Options)
+	targets, err := convertProtoTargetsToDomain(req.Targets)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) > 0 {
+		if req.Type != pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED || req.Account != "" {
+			return nil, status.Error(codes.InvalidArgument, "type and account can't be combined with targets")
+		}
+		notifType = ""
+		// Each target gets the options block for its channel
+		for _, target := range targets {
+			if err := options.ForType(target.Type).Validate(target.Type); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid options: %v", err)
+			}
+		}
+	} else if err := options.Validate(notifType); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid options: %v", err)
+	}
+	var replyTo string
+	if options != nil && options.Email != nil {
+		replyTo = options.Email.ReplyTo
+	}
+
+	fallbacks := convertProtoFallbacksToDomain(req.Fallbacks)
+	if err := domain.ValidateFallbacks(fallbacks); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid fallbacks: %v", err)
+	}
+	if _, ok := req.Tags[""]; ok {
+		return nil, status.Error(codes.InvalidArgument, "tag keys must not be empty")
+	}
+
 	// Build notification
 	notification := &domain.Notification{
 		ID:          uuid.New().String(),
@@ -68,23 +134,58 @@ func (h *NotifierHandler) SendNotification(ctx context.Context, req *pb.SendNoti
 		Body:        req.Body,
 		HTMLBody:    req.HtmlBody,
 		ContentType: contentType,
+		Template:    req.Template,
+		Locale:      req.Locale,
 		Recipients:  req.Recipients,
+		Contacts:    req.Contacts,
 		CC:          req.Cc,
 		BCC:         req.Bcc,
+		ReplyTo:     replyTo,
 		Metadata:    convertStringMapToInterface(req.Metadata),
+		Options:     options,
 		MaxRetries:  maxRetries,
+		Fallbacks:   fallbacks,
+		DedupKey:    req.DedupKey,
+		Tags:        req.Tags,
+		Targets:     targets,
+	}
+
+	if req.Origin != nil {
+		notification.Origin = domain.Origin{System: req.Origin.System, User: req.Origin.User}
 	}
 
 	if req.ScheduledFor != nil {
 		scheduledTime := req.ScheduledFor.AsTime()
 		notification.ScheduledFor = &scheduledTime
 	}
+	if req.Deadline != nil {
+		deadline := req.Deadline.AsTime()
+		if notification.ScheduledFor != nil && !deadline.After(*notification.ScheduledFor) {
+			return nil, status.Errorf(codes.InvalidArgument, "deadline must be after scheduled_for")
+		}
+		notification.Deadline = &deadline
+	}
+	notification.Recurrence = req.Recurrence
+	if len(req.TemplateVars) > 0 {
+		notification.TemplateVars = convertStringMapToInterface(req.TemplateVars)
+	}
 
 	// Send notification
 	result, err := h.service.Send(ctx, notification)
 	if err != nil {
 		h.logger.Errorf("gRPC: Failed to send notification - type=%s, account=%s, error=%v",
 			req.Type, req.Account, err)
+		if errors.Is(err, domain.ErrBudgetExceeded) || errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, status.Errorf(codes.ResourceExhausted, "failed to send notification: %v", err)
+		}
+		if errors.Is(err, domain.ErrTenantAccount) {
+			return nil, status.Errorf(codes.PermissionDenied, "failed to send notification: %v", err)
+		}
+		if errors.Is(err, domain.ErrContentBlocked) || errors.Is(err, domain.ErrUnknownKeys) || errors.Is(err, domain.ErrLimitExceeded) ||
+			errors.Is(err, domain.ErrInvalidRecurrence) || errors.Is(err, domain.ErrTemplateNotFound) || errors.Is(err, domain.ErrInvalidTemplate) ||
+			errors.Is(err, domain.ErrContactNotFound) || errors.Is(err, domain.ErrInvalidContact) || errors.Is(err, domain.ErrInvalidTargets) {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to send notification: %v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to send notification: %v", err)
 	}
 
@@ -99,6 +200,9 @@ func (h *NotifierHandler) SendNotification(ctx context.Context, req *pb.SendNoti
 			Success:        result.Success,
 			Message:        result.Message,
 			SentAt:         timestamppb.New(result.SentAt),
+			Links:          result.Links,
+			Suppressed:     result.Suppressed,
+			Targets:        convertDomainTargetResultsToProto(result.Targets),
 		},
 	}, nil
 }
@@ -133,6 +237,25 @@ func (h *NotifierHandler) SendBatchNotifications(ctx context.Context, req *pb.Se
 	}, nil
 }
 
+// setStalenessHeader annotates a lookup or listing with its read source, in the x-read-source and
+// x-replication-lag header metadata, when the service may read from a replica
+func (h *NotifierHandler) setStalenessHeader(ctx context.Context) {
+	if reporter, ok := h.service.(domain.ReadStalenessReporter); ok {
+		if staleness := reporter.ReadStaleness(ctx); staleness != nil {
+			writeStalenessHeader(ctx, staleness)
+		}
+	}
+}
+
+// writeStalenessHeader sets the staleness header metadata. It only fails outside a gRPC call,
+// as when tests call the handler directly.
+func writeStalenessHeader(ctx context.Context, staleness *domain.ReadStaleness) {
+	_ = grpclib.SetHeader(ctx, metadata.Pairs(
+		"x-read-source", staleness.Source,
+		"x-replication-lag", strconv.FormatFloat(staleness.LagSeconds, 'f', -1, 64),
+	))
+}
+
 // GetNotification retrieves a notification by ID
 func (h *NotifierHandler) GetNotification(ctx context.Context, req *pb.GetNotificationRequest) (*pb.GetNotificationResponse, error) {
 	notification, err := h.service.GetNotification(ctx, req.Id)
@@ -140,6 +263,7 @@ func (h *NotifierHandler) GetNotification(ctx context.Context, req *pb.GetNotifi
 		return nil, status.Errorf(codes.NotFound, "notification not found: %v", err)
 	}
 
+	h.setStalenessHeader(ctx)
 	return &pb.GetNotificationResponse{
 		Notification: convertDomainToProtoNotification(notification),
 	}, nil
@@ -150,19 +274,24 @@ func (h *NotifierHandler) ListNotifications(ctx context.Context, req *pb.ListNot
 	// Convert proto filter to domain filter
 	filter := convertProtoFilterToDomain(req.Filter)
 
-	notifications, err := h.service.ListNotifications(ctx, filter)
+	page, err := h.service.ListNotifications(ctx, filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list notifications: %v", err)
 	}
 
-	protoNotifications := make([]*pb.Notification, len(notifications))
-	for i, notif := range notifications {
+	h.setStalenessHeader(ctx)
+	protoNotifications := make([]*pb.Notification, len(page.Notifications))
+	for i, notif := range page.Notifications {
 		protoNotifications[i] = convertDomainToProtoNotification(notif)
 	}
 
 	return &pb.ListNotificationsResponse{
 		Notifications: protoNotifications,
-		Total:         int64(len(notifications)),
+		Total:         page.Total,
+		NextCursor:    page.NextCursor,
 	}, nil
 }
 
@@ -204,12 +333,89 @@ func (h *NotifierHandler) RetryNotification(ctx context.Context, req *pb.RetryNo
 	}, nil
 }
 
+// RescheduleNotification pushes back when a pending, queued or scheduled notification is delivered
+func (h *NotifierHandler) RescheduleNotification(ctx context.Context, req *pb.RescheduleNotificationRequest) (*pb.RescheduleNotificationResponse, error) {
+	rescheduler, ok := h.service.(domain.NotificationRescheduler)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "rescheduling is not supported")
+	}
+
+	var at time.Time
+	switch {
+	case req.ScheduledFor != nil && req.Delay != "":
+		return nil, status.Errorf(codes.InvalidArgument, "set scheduled_for or delay, not both")
+	case req.ScheduledFor != nil:
+		at = req.ScheduledFor.AsTime()
+	case req.Delay != "":
+		delay, err := time.ParseDuration(req.Delay)
+		if err != nil || delay <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid delay %q (must be a positive duration)", req.Delay)
+		}
+		at = time.Now().Add(delay)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "scheduled_for or delay is required")
+	}
+
+	notification, err := rescheduler.RescheduleNotification(ctx, req.Id, at)
+	switch {
+	case errors.Is(err, domain.ErrNotificationNotFound):
+		return nil, status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, domain.ErrNotReschedulable):
+		return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+	case errors.Is(err, domain.ErrRescheduleEarlier):
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "failed to reschedule notification: %v", err)
+	}
+
+	return &pb.RescheduleNotificationResponse{
+		Notification: convertDomainToProtoNotification(notification),
+	}, nil
+}
+
 // GetStats returns notification statistics
 func (h *NotifierHandler) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
 	stats, err := h.service.GetStats(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if stats.Staleness != nil {
+		writeStalenessHeader(ctx, stats.Staleness)
+	}
+
+	slos := make([]*pb.SLOStatus, 0, len(stats.SLOs))
+	for _, slo := range stats.SLOs {
+		slos = append(slos, &pb.SLOStatus{
+			Name:            slo.Name,
+			Target:          slo.Target,
+			ThresholdMs:     slo.ThresholdMs,
+			Compliance:      slo.Compliance,
+			Total:           slo.Total,
+			WithinThreshold: slo.WithinThreshold,
+			Breached:        slo.Breached,
+		})
+	}
+
+	byOrigin := make(map[string]*pb.OriginStats, len(stats.ByOrigin))
+	for system, origin := range stats.ByOrigin {
+		byOrigin[system] = &pb.OriginStats{
+			Total:         origin.Total,
+			UserTriggered: origin.UserTriggered,
+			ByType:        origin.ByType,
+		}
+	}
+
+	budgets := make([]*pb.BudgetStatus, 0, len(stats.Budgets))
+	for _, budget := range stats.Budgets {
+		budgets = append(budgets, &pb.BudgetStatus{
+			Origin:   budget.Origin,
+			Type:     budget.Type,
+			Limit:    budget.Limit,
+			Used:     budget.Used,
+			Action:   budget.Action,
+			Exceeded: budget.Exceeded,
+		})
+	}
 
 	return &pb.GetStatsResponse{
 		TotalSent:    stats.TotalSent,
@@ -218,6 +424,20 @@ func (h *NotifierHandler) GetStats(ctx context.Context, req *pb.GetStatsRequest)
 		TotalQueued:  stats.TotalQueued,
 		ByType:       stats.ByType,
 		ByStatus:     stats.ByStatus,
+		Slos:         slos,
+		ByOrigin:     byOrigin,
+		Budgets:      budgets,
+
+		TotalSuppressed:  stats.TotalSuppressed,
+		AverageLatencyMs: stats.AverageLatency,
+		Delivery: &pb.DeliveryStats{
+			Delivered:      stats.Delivery.Delivered,
+			Failed:         stats.Delivery.Failed,
+			LatencyP50Ms:   stats.Delivery.LatencyP50Ms,
+			LatencyP95Ms:   stats.Delivery.LatencyP95Ms,
+			DeadlineMet:    stats.Delivery.DeadlineMet,
+			DeadlineMissed: stats.Delivery.DeadlineMissed,
+		},
 	}, nil
 }
 
@@ -246,6 +466,434 @@ func (h *NotifierHandler) GetNotifiers(ctx context.Context, req *pb.GetNotifiers
 	}, nil
 }
 
+// ListRecurringNotifications returns every recurring notification, oldest first
+func (h *NotifierHandler) ListRecurringNotifications(ctx context.Context, req *pb.ListRecurringNotificationsRequest) (*pb.ListRecurringNotificationsResponse, error) {
+	manager, err := h.recurrenceManager()
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := manager.ListRecurringNotifications(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list recurring notifications: %v", err)
+	}
+
+	recurring := make([]*pb.RecurringNotification, 0, len(definitions))
+	for _, definition := range definitions {
+		recurring = append(recurring, convertDomainToProtoRecurring(definition))
+	}
+	return &pb.ListRecurringNotificationsResponse{Recurring: recurring}, nil
+}
+
+// PauseRecurringNotification skips a recurring notification's occurrences until it's resumed
+func (h *NotifierHandler) PauseRecurringNotification(ctx context.Context, req *pb.RecurringNotificationRequest) (*pb.RecurringNotificationResponse, error) {
+	return h.controlRecurringNotification(ctx, req.Id, "paused", domain.RecurrenceManager.PauseRecurringNotification)
+}
+
+// ResumeRecurringNotification sends a paused recurring notification's occurrences again
+func (h *NotifierHandler) ResumeRecurringNotification(ctx context.Context, req *pb.RecurringNotificationRequest) (*pb.RecurringNotificationResponse, error) {
+	return h.controlRecurringNotification(ctx, req.Id, "resumed", domain.RecurrenceManager.ResumeRecurringNotification)
+}
+
+// DeleteRecurringNotification stops a recurring notification for good
+func (h *NotifierHandler) DeleteRecurringNotification(ctx context.Context, req *pb.RecurringNotificationRequest) (*pb.DeleteRecurringNotificationResponse, error) {
+	manager, err := h.recurrenceManager()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.DeleteRecurringNotification(ctx, req.Id); err != nil {
+		return nil, recurrenceStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Recurring notification deleted - id=%s", req.Id)
+	return &pb.DeleteRecurringNotificationResponse{Success: true}, nil
+}
+
+// controlRecurringNotification applies a pause or resume and returns the recurring notification
+func (h *NotifierHandler) controlRecurringNotification(ctx context.Context, id, action string, control func(domain.RecurrenceManager, context.Context, string) error) (*pb.RecurringNotificationResponse, error) {
+	manager, err := h.recurrenceManager()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := control(manager, ctx, id); err != nil {
+		return nil, recurrenceStatusError(err)
+	}
+	definition, err := manager.GetRecurringNotification(ctx, id)
+	if err != nil {
+		return nil, recurrenceStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Recurring notification %s - id=%s", action, id)
+	return &pb.RecurringNotificationResponse{Recurring: convertDomainToProtoRecurring(definition)}, nil
+}
+
+// recurrenceManager returns the service's recurrence manager, or Unimplemented if it has none
+func (h *NotifierHandler) recurrenceManager() (domain.RecurrenceManager, error) {
+	manager, ok := h.service.(domain.RecurrenceManager)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "recurring notifications are not supported")
+	}
+	return manager, nil
+}
+
+// recurrenceStatusError converts an error from a recurring notification operation to a gRPC status
+func recurrenceStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrRecurrenceNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, domain.ErrRecurrenceFinished):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "failed to update recurring notification: %v", err)
+	}
+}
+
+// CreateTemplate saves a template notifications can be rendered from; one that doesn't parse is rejected
+func (h *NotifierHandler) CreateTemplate(ctx context.Context, req *pb.CreateTemplateRequest) (*pb.CreateTemplateResponse, error) {
+	manager, err := h.templateManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.Template == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "template is required")
+	}
+
+	created, err := manager.CreateTemplate(ctx, convertProtoTemplateToDomain(req.Template))
+	if err != nil {
+		return nil, templateStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Template created - name=%s, type=%s", created.Name, created.Type)
+	return &pb.CreateTemplateResponse{Template: convertDomainToProtoTemplate(created)}, nil
+}
+
+// GetTemplate returns a template by name
+func (h *NotifierHandler) GetTemplate(ctx context.Context, req *pb.GetTemplateRequest) (*pb.GetTemplateResponse, error) {
+	manager, err := h.templateManager(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := manager.GetTemplate(ctx, req.Name)
+	if err != nil {
+		return nil, templateStatusError(err)
+	}
+	return &pb.GetTemplateResponse{Template: convertDomainToProtoTemplate(tmpl)}, nil
+}
+
+// ListTemplates returns the templates, optionally only those for one channel type
+func (h *NotifierHandler) ListTemplates(ctx context.Context, req *pb.ListTemplatesRequest) (*pb.ListTemplatesResponse, error) {
+	manager, err := h.templateManager(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifType domain.NotificationType
+	if req.Type != pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED {
+		notifType = convertProtoTypeToDomain(req.Type)
+	}
+	list, err := manager.ListTemplates(ctx, notifType)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list templates: %v", err)
+	}
+
+	templates := make([]*pb.Template, 0, len(list))
+	for _, tmpl := range list {
+		templates = append(templates, convertDomainToProtoTemplate(tmpl))
+	}
+	return &pb.ListTemplatesResponse{Templates: templates}, nil
+}
+
+// UpdateTemplate replaces a template created through the API
+func (h *NotifierHandler) UpdateTemplate(ctx context.Context, req *pb.UpdateTemplateRequest) (*pb.UpdateTemplateResponse, error) {
+	manager, err := h.templateManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.Template == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "template is required")
+	}
+
+	updated, err := manager.UpdateTemplate(ctx, convertProtoTemplateToDomain(req.Template))
+	if err != nil {
+		return nil, templateStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Template updated - name=%s, type=%s", updated.Name, updated.Type)
+	return &pb.UpdateTemplateResponse{Template: convertDomainToProtoTemplate(updated)}, nil
+}
+
+// DeleteTemplate removes a template created through the API
+func (h *NotifierHandler) DeleteTemplate(ctx context.Context, req *pb.DeleteTemplateRequest) (*pb.DeleteTemplateResponse, error) {
+	manager, err := h.templateManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.DeleteTemplate(ctx, req.Name); err != nil {
+		return nil, templateStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Template deleted - name=%s", req.Name)
+	return &pb.DeleteTemplateResponse{Success: true}, nil
+}
+
+// RenderTemplate returns what a template renders for the given variables without sending anything
+func (h *NotifierHandler) RenderTemplate(ctx context.Context, req *pb.RenderTemplateRequest) (*pb.RenderTemplateResponse, error) {
+	manager, err := h.templateManager(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifType domain.NotificationType
+	if req.Type != pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED {
+		notifType = convertProtoTypeToDomain(req.Type)
+	}
+	var vars map[string]interface{}
+	if len(req.TemplateVars) > 0 {
+		vars = convertStringMapToInterface(req.TemplateVars)
+	}
+
+	rendered, err := manager.RenderTemplate(ctx, req.Name, notifType, req.Locale, vars)
+	if err != nil {
+		return nil, templateStatusError(err)
+	}
+
+	resp := &pb.RenderTemplateResponse{
+		Subject:  rendered.Subject,
+		Body:     rendered.Body,
+		HtmlBody: rendered.HTMLBody,
+		Locale:   rendered.Locale,
+	}
+	if rendered.Type != "" {
+		resp.Type = convertDomainToProtoType(rendered.Type)
+	}
+	return resp, nil
+}
+
+// templateManager returns the service's template manager, or Unimplemented if it has none.
+// Changing templates requires the admin role when the caller is authenticated, except for a
+// tenant's clients, who change their own tenant's.
+func (h *NotifierHandler) templateManager(ctx context.Context, change bool) (domain.TemplateManager, error) {
+	manager, ok := h.service.(domain.TemplateManager)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "templates are not supported")
+	}
+	if authCtx, ok := auth.GetAuthContext(ctx); ok && change && authCtx.Tenant == "" && !slices.Contains(authCtx.Roles, "admin") {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+	return manager, nil
+}
+
+// templateStatusError converts an error from a template operation to a gRPC status
+func templateStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrTemplateNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, domain.ErrInvalidTemplate):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, domain.ErrTemplateExists):
+		return status.Errorf(codes.AlreadyExists, "%v", err)
+	case errors.Is(err, domain.ErrTemplateConfigured):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "template operation failed: %v", err)
+	}
+}
+
+// CreateContact saves a contact or group notifications can be sent to by name
+func (h *NotifierHandler) CreateContact(ctx context.Context, req *pb.CreateContactRequest) (*pb.CreateContactResponse, error) {
+	manager, err := h.contactManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.Contact == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "contact is required")
+	}
+
+	created, err := manager.CreateContact(ctx, convertProtoContactToDomain(req.Contact))
+	if err != nil {
+		return nil, contactStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Contact created - name=%s", created.Name)
+	return &pb.CreateContactResponse{Contact: convertDomainToProtoContact(created)}, nil
+}
+
+// GetContact returns a contact by name
+func (h *NotifierHandler) GetContact(ctx context.Context, req *pb.GetContactRequest) (*pb.GetContactResponse, error) {
+	manager, err := h.contactManager(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := manager.GetContact(ctx, req.Name)
+	if err != nil {
+		return nil, contactStatusError(err)
+	}
+	return &pb.GetContactResponse{Contact: convertDomainToProtoContact(contact)}, nil
+}
+
+// ListContacts returns every contact, sorted by name
+func (h *NotifierHandler) ListContacts(ctx context.Context, req *pb.ListContactsRequest) (*pb.ListContactsResponse, error) {
+	manager, err := h.contactManager(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := manager.ListContacts(ctx)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list contacts: %v", err)
+	}
+
+	contacts := make([]*pb.Contact, 0, len(list))
+	for _, contact := range list {
+		contacts = append(contacts, convertDomainToProtoContact(contact))
+	}
+	return &pb.ListContactsResponse{Contacts: contacts}, nil
+}
+
+// UpdateContact replaces a contact created through the API
+func (h *NotifierHandler) UpdateContact(ctx context.Context, req *pb.UpdateContactRequest) (*pb.UpdateContactResponse, error) {
+	manager, err := h.contactManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	if req.Contact == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "contact is required")
+	}
+
+	updated, err := manager.UpdateContact(ctx, convertProtoContactToDomain(req.Contact))
+	if err != nil {
+		return nil, contactStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Contact updated - name=%s", updated.Name)
+	return &pb.UpdateContactResponse{Contact: convertDomainToProtoContact(updated)}, nil
+}
+
+// DeleteContact removes a contact created through the API
+func (h *NotifierHandler) DeleteContact(ctx context.Context, req *pb.DeleteContactRequest) (*pb.DeleteContactResponse, error) {
+	manager, err := h.contactManager(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.DeleteContact(ctx, req.Name); err != nil {
+		return nil, contactStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Contact deleted - name=%s", req.Name)
+	return &pb.DeleteContactResponse{Success: true}, nil
+}
+
+// contactManager returns the service's contact manager, or Unimplemented if it has none.
+// Changing contacts requires the admin role when the caller is authenticated, except for a
+// tenant's clients, who change their own tenant's.
+func (h *NotifierHandler) contactManager(ctx context.Context, change bool) (domain.ContactManager, error) {
+	manager, ok := h.service.(domain.ContactManager)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "contacts are not supported")
+	}
+	if authCtx, ok := auth.GetAuthContext(ctx); ok && change && authCtx.Tenant == "" && !slices.Contains(authCtx.Roles, "admin") {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required")
+	}
+	return manager, nil
+}
+
+// contactStatusError converts an error from a contact operation to a gRPC status
+func contactStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrContactNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, domain.ErrInvalidContact):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	case errors.Is(err, domain.ErrContactExists):
+		return status.Errorf(codes.AlreadyExists, "%v", err)
+	case errors.Is(err, domain.ErrContactConfigured):
+		return status.Errorf(codes.FailedPrecondition, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "contact operation failed: %v", err)
+	}
+}
+
+// GetPreferences returns how a contact wants to be reached
+func (h *NotifierHandler) GetPreferences(ctx context.Context, req *pb.GetPreferencesRequest) (*pb.GetPreferencesResponse, error) {
+	manager, ok := h.service.(domain.PreferenceManager)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "preferences are not supported")
+	}
+
+	prefs, err := manager.GetPreferences(ctx, req.Contact)
+	if err != nil {
+		return nil, preferencesStatusError(err)
+	}
+	return &pb.GetPreferencesResponse{Preferences: convertDomainToProtoPreferences(prefs)}, nil
+}
+
+// SetPreferences replaces a contact's preferences. Unlike the contact itself it doesn't need the
+// admin role to change its own, so users can manage their own preferences.
+func (h *NotifierHandler) SetPreferences(ctx context.Context, req *pb.SetPreferencesRequest) (*pb.SetPreferencesResponse, error) {
+	if req.Preferences == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "preferences are required")
+	}
+	manager, err := h.preferenceManager(ctx, req.Preferences.Contact)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := manager.SetPreferences(ctx, convertProtoPreferencesToDomain(req.Preferences))
+	if err != nil {
+		return nil, preferencesStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Preferences set - contact=%s", prefs.Contact)
+	return &pb.SetPreferencesResponse{Preferences: convertDomainToProtoPreferences(prefs)}, nil
+}
+
+// DeletePreferences removes a contact's preferences
+func (h *NotifierHandler) DeletePreferences(ctx context.Context, req *pb.DeletePreferencesRequest) (*pb.DeletePreferencesResponse, error) {
+	manager, err := h.preferenceManager(ctx, req.Contact)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := manager.DeletePreferences(ctx, req.Contact); err != nil {
+		return nil, preferencesStatusError(err)
+	}
+
+	h.logger.Infof("gRPC: Preferences deleted - contact=%s", req.Contact)
+	return &pb.DeletePreferencesResponse{Success: true}, nil
+}
+
+// preferenceManager returns the service's preference manager, or Unimplemented if it has none.
+// When the caller is authenticated, changing a contact's preferences requires the admin role
+// unless the contact is named after the caller's client ID.
+func (h *NotifierHandler) preferenceManager(ctx context.Context, contact string) (domain.PreferenceManager, error) {
+	manager, ok := h.service.(domain.PreferenceManager)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "preferences are not supported")
+	}
+	if authCtx, ok := auth.GetAuthContext(ctx); ok && authCtx.ClientID != contact && !slices.Contains(authCtx.Roles, "admin") {
+		return nil, status.Errorf(codes.PermissionDenied, "admin role required to change another contact's preferences")
+	}
+	return manager, nil
+}
+
+// preferencesStatusError converts an error from a preferences operation to a gRPC status
+func preferencesStatusError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrPreferencesNotFound), errors.Is(err, domain.ErrContactNotFound):
+		return status.Errorf(codes.NotFound, "%v", err)
+	case errors.Is(err, domain.ErrInvalidPreferences):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	default:
+		return status.Errorf(codes.Internal, "preferences operation failed: %v", err)
+	}
+}
+
 // Helper functions to convert between proto and domain types
 
 // convertStringMapToInterface converts proto's map[string]string to domain's map[string]interface{}
@@ -274,6 +922,118 @@ func convertInterfaceMapToString(m map[string]interface{}) map[string]string {
 	return result
 }
 
+// convertProtoOptionsToDomain converts proto send options, returning nil if none are set
+func convertProtoOptionsToDomain(options *pb.SendOptions) *domain.SendOptions {
+	if options == nil {
+		return nil
+	}
+	result := &domain.SendOptions{}
+	if email := options.Email; email != nil {
+		result.Email = &domain.EmailOptions{ReplyTo: email.ReplyTo, Headers: email.Headers}
+	}
+	if slack := options.Slack; slack != nil {
+		result.Slack = &domain.SlackOptions{ThreadTS: slack.ThreadTs, Icon: slack.Icon}
+	}
+	if ntfy := options.Ntfy; ntfy != nil {
+		result.Ntfy = &domain.NtfyOptions{Tags: ntfy.Tags, Click: ntfy.Click, Delay: ntfy.Delay}
+	}
+	if result.Email == nil && result.Slack == nil && result.Ntfy == nil {
+		return nil
+	}
+	return result
+}
+
+// convertDomainOptionsToProto converts domain send options to proto
+func convertDomainOptionsToProto(options *domain.SendOptions) *pb.SendOptions {
+	if options == nil {
+		return nil
+	}
+	result := &pb.SendOptions{}
+	if email := options.Email; email != nil {
+		result.Email = &pb.EmailOptions{ReplyTo: email.ReplyTo, Headers: email.Headers}
+	}
+	if slack := options.Slack; slack != nil {
+		result.Slack = &pb.SlackOptions{ThreadTs: slack.ThreadTS, Icon: slack.Icon}
+	}
+	if ntfy := options.Ntfy; ntfy != nil {
+		result.Ntfy = &pb.NtfyOptions{Tags: ntfy.Tags, Click: ntfy.Click, Delay: ntfy.Delay}
+	}
+	return result
+}
+
+// convertProtoFallbacksToDomain converts a proto fallback chain to domain
+func convertProtoFallbacksToDomain(fallbacks []*pb.Fallback) []domain.Fallback {
+	if len(fallbacks) == 0 {
+		return nil
+	}
+	result := make([]domain.Fallback, 0, len(fallbacks))
+	for _, fallback := range fallbacks {
+		result = append(result, domain.Fallback{
+			Type:       convertProtoTypeToDomain(fallback.Type),
+			Account:    fallback.Account,
+			Recipients: fallback.Recipients,
+			Contacts:   fallback.Contacts,
+		})
+	}
+	return result
+}
+
+// convertProtoTargetsToDomain converts fan-out targets to domain, rejecting targets without a type
+func convertProtoTargetsToDomain(targets []*pb.Target) ([]domain.Target, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	result := make([]domain.Target, 0, len(targets))
+	for i, target := range targets {
+		if target.Type == pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED {
+			return nil, status.Errorf(codes.InvalidArgument, "targets[%d]: type is required", i)
+		}
+		result = append(result, domain.Target{
+			Type:       convertProtoTypeToDomain(target.Type),
+			Account:    target.Account,
+			Recipients: target.Recipients,
+			Contacts:   target.Contacts,
+		})
+	}
+	return result, nil
+}
+
+// convertDomainTargetResultsToProto converts the per-target results of a fanned-out notification to proto
+func convertDomainTargetResultsToProto(results []domain.TargetResult) []*pb.TargetResult {
+	if len(results) == 0 {
+		return nil
+	}
+	converted := make([]*pb.TargetResult, 0, len(results))
+	for _, result := range results {
+		converted = append(converted, &pb.TargetResult{
+			Type:           convertDomainTypeToProto(result.Type),
+			Account:        result.Account,
+			NotificationId: result.NotificationID,
+			Success:        result.Success,
+			Message:        result.Message,
+			Error:          result.Error,
+		})
+	}
+	return converted
+}
+
+// convertDomainFallbacksToProto converts a domain fallback chain to proto
+func convertDomainFallbacksToProto(fallbacks []domain.Fallback) []*pb.Fallback {
+	if len(fallbacks) == 0 {
+		return nil
+	}
+	result := make([]*pb.Fallback, 0, len(fallbacks))
+	for _, fallback := range fallbacks {
+		result = append(result, &pb.Fallback{
+			Type:       convertDomainToProtoType(fallback.Type),
+			Account:    fallback.Account,
+			Recipients: fallback.Recipients,
+			Contacts:   fallback.Contacts,
+		})
+	}
+	return result
+}
+
 func convertProtoTypeToDomain(protoType pb.NotificationType) domain.NotificationType {
 	switch protoType {
 	case pb.NotificationType_NOTIFICATION_TYPE_EMAIL:
@@ -284,6 +1044,30 @@ func convertProtoTypeToDomain(protoType pb.NotificationType) domain.Notification
 		return domain.TypeNtfy
 	case pb.NotificationType_NOTIFICATION_TYPE_STDOUT:
 		return domain.TypeStdout
+	case pb.NotificationType_NOTIFICATION_TYPE_DISCORD:
+		return domain.TypeDiscord
+	case pb.NotificationType_NOTIFICATION_TYPE_PAGERDUTY:
+		return domain.TypePagerDuty
+	case pb.NotificationType_NOTIFICATION_TYPE_FCM:
+		return domain.TypeFCM
+	case pb.NotificationType_NOTIFICATION_TYPE_WEBPUSH:
+		return domain.TypeWebPush
+	case pb.NotificationType_NOTIFICATION_TYPE_XMPP:
+		return domain.TypeXMPP
+	case pb.NotificationType_NOTIFICATION_TYPE_WHATSAPP:
+		return domain.TypeWhatsApp
+	case pb.NotificationType_NOTIFICATION_TYPE_POSTMARK:
+		return domain.TypePostmark
+	case pb.NotificationType_NOTIFICATION_TYPE_AMQP:
+		return domain.TypeAMQP
+	case pb.NotificationType_NOTIFICATION_TYPE_FILE:
+		return domain.TypeFile
+	case pb.NotificationType_NOTIFICATION_TYPE_BARK:
+		return domain.TypeBark
+	case pb.NotificationType_NOTIFICATION_TYPE_DINGTALK:
+		return domain.TypeDingTalk
+	case pb.NotificationType_NOTIFICATION_TYPE_SMS:
+		return domain.TypeSMS
 	default:
 		return domain.TypeStdout
 	}
@@ -299,6 +1083,30 @@ func convertDomainTypeToProto(domainType domain.NotificationType) pb.Notificatio
 		return pb.NotificationType_NOTIFICATION_TYPE_NTFY
 	case domain.TypeStdout:
 		return pb.NotificationType_NOTIFICATION_TYPE_STDOUT
+	case domain.TypeDiscord:
+		return pb.NotificationType_NOTIFICATION_TYPE_DISCORD
+	case domain.TypePagerDuty:
+		return pb.NotificationType_NOTIFICATION_TYPE_PAGERDUTY
+	case domain.TypeFCM:
+		return pb.NotificationType_NOTIFICATION_TYPE_FCM
+	case domain.TypeWebPush:
+		return pb.NotificationType_NOTIFICATION_TYPE_WEBPUSH
+	case domain.TypeXMPP:
+		return pb.NotificationType_NOTIFICATION_TYPE_XMPP
+	case domain.TypeWhatsApp:
+		return pb.NotificationType_NOTIFICATION_TYPE_WHATSAPP
+	case domain.TypePostmark:
+		return pb.NotificationType_NOTIFICATION_TYPE_POSTMARK
+	case domain.TypeAMQP:
+		return pb.NotificationType_NOTIFICATION_TYPE_AMQP
+	case domain.TypeFile:
+		return pb.NotificationType_NOTIFICATION_TYPE_FILE
+	case domain.TypeBark:
+		return pb.NotificationType_NOTIFICATION_TYPE_BARK
+	case domain.TypeDingTalk:
+		return pb.NotificationType_NOTIFICATION_TYPE_DINGTALK
+	case domain.TypeSMS:
+		return pb.NotificationType_NOTIFICATION_TYPE_SMS
 	default:
 		return pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED
 	}
@@ -336,6 +1144,30 @@ func convertDomainToProtoType(domainType domain.NotificationType) pb.Notificatio
 		return pb.NotificationType_NOTIFICATION_TYPE_NTFY
 	case domain.TypeStdout:
 		return pb.NotificationType_NOTIFICATION_TYPE_STDOUT
+	case domain.TypeDiscord:
+		return pb.NotificationType_NOTIFICATION_TYPE_DISCORD
+	case domain.TypePagerDuty:
+		return pb.NotificationType_NOTIFICATION_TYPE_PAGERDUTY
+	case domain.TypeFCM:
+		return pb.NotificationType_NOTIFICATION_TYPE_FCM
+	case domain.TypeWebPush:
+		return pb.NotificationType_NOTIFICATION_TYPE_WEBPUSH
+	case domain.TypeXMPP:
+		return pb.NotificationType_NOTIFICATION_TYPE_XMPP
+	case domain.TypeWhatsApp:
+		return pb.NotificationType_NOTIFICATION_TYPE_WHATSAPP
+	case domain.TypePostmark:
+		return pb.NotificationType_NOTIFICATION_TYPE_POSTMARK
+	case domain.TypeAMQP:
+		return pb.NotificationType_NOTIFICATION_TYPE_AMQP
+	case domain.TypeFile:
+		return pb.NotificationType_NOTIFICATION_TYPE_FILE
+	case domain.TypeBark:
+		return pb.NotificationType_NOTIFICATION_TYPE_BARK
+	case domain.TypeDingTalk:
+		return pb.NotificationType_NOTIFICATION_TYPE_DINGTALK
+	case domain.TypeSMS:
+		return pb.NotificationType_NOTIFICATION_TYPE_SMS
 	default:
 		return pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED
 	}
@@ -355,6 +1187,14 @@ func convertDomainToProtoStatus(status domain.NotificationStatus) pb.Notificatio
 		return pb.NotificationStatus_NOTIFICATION_STATUS_FAILED
 	case domain.StatusRetrying:
 		return pb.NotificationStatus_NOTIFICATION_STATUS_RETRYING
+	case domain.StatusHeld:
+		return pb.NotificationStatus_NOTIFICATION_STATUS_HELD
+	case domain.StatusPaused:
+		return pb.NotificationStatus_NOTIFICATION_STATUS_PAUSED
+	case domain.StatusScheduled:
+		return pb.NotificationStatus_NOTIFICATION_STATUS_SCHEDULED
+	case domain.StatusDigested:
+		return pb.NotificationStatus_NOTIFICATION_STATUS_DIGESTED
 	default:
 		return pb.NotificationStatus_NOTIFICATION_STATUS_UNSPECIFIED
 	}
@@ -370,12 +1210,32 @@ func convertDomainToProtoNotification(notif *domain.Notification) *pb.Notificati
 		Subject:    notif.Subject,
 		Body:       notif.Body,
 		HtmlBody:   notif.HTMLBody,
+		Template:   notif.Template,
+		Locale:     notif.Locale,
 		Recipients: notif.Recipients,
+		Contacts:   notif.Contacts,
+		DedupKey:   notif.DedupKey,
+		DigestId:   notif.DigestID,
+		DigestOf:   notif.DigestOf,
 		Metadata:   convertInterfaceMapToString(notif.Metadata),
+		Tags:       notif.Tags,
+		FanoutId:   notif.FanoutID,
+		Tenant:     notif.Tenant,
 		CreatedAt:  timestamppb.New(notif.CreatedAt),
 		RetryCount: int32(notif.RetryCount),
 		MaxRetries: int32(notif.MaxRetries),
 		LastError:  notif.LastError,
+		Origin:     &pb.Origin{System: notif.Origin.System, User: notif.Origin.User},
+		Links:      notif.Links,
+		Options:    convertDomainOptionsToProto(notif.Options),
+
+		RecurrenceId: notif.RecurrenceID,
+
+		Fallbacks:  convertDomainFallbacksToProto(notif.Fallbacks),
+		FallbackOf: notif.FallbackOf,
+		FallbackId: notif.FallbackID,
+
+		DeadlineMissed: notif.DeadlineMissed,
 	}
 
 	// Handle optional timestamp fields
@@ -385,10 +1245,219 @@ func convertDomainToProtoNotification(notif *domain.Notification) *pb.Notificati
 	if notif.SentAt != nil {
 		protoNotif.SentAt = timestamppb.New(*notif.SentAt)
 	}
+	if notif.NextAttemptAt != nil {
+		protoNotif.NextAttemptAt = timestamppb.New(*notif.NextAttemptAt)
+	}
+	if notif.Deadline != nil {
+		protoNotif.Deadline = timestamppb.New(*notif.Deadline)
+	}
 
 	return protoNotif
 }
 
+// convertProtoTemplateToDomain converts a proto template to domain; an unspecified type
+// renders for every channel
+func convertProtoTemplateToDomain(tmpl *pb.Template) domain.Template {
+	result := domain.Template{
+		Name:        tmpl.Name,
+		Description: tmpl.Description,
+		Subject:     tmpl.Subject,
+		Body:        tmpl.Body,
+		HTMLBody:    tmpl.HtmlBody,
+		Layout:      tmpl.Layout,
+	}
+	if tmpl.Type != pb.NotificationType_NOTIFICATION_TYPE_UNSPECIFIED {
+		result.Type = convertProtoTypeToDomain(tmpl.Type)
+	}
+	result.Variants = convertProtoVariantsToDomain(tmpl.Variants)
+	if len(tmpl.Locales) > 0 {
+		result.Locales = make(map[string]domain.TemplateLocale, len(tmpl.Locales))
+		for tag, locale := range tmpl.Locales {
+			result.Locales[tag] = domain.TemplateLocale{
+				Subject:  locale.GetSubject(),
+				Body:     locale.GetBody(),
+				HTMLBody: locale.GetHtmlBody(),
+				Variants: convertProtoVariantsToDomain(locale.GetVariants()),
+			}
+		}
+	}
+	return result
+}
+
+// convertProtoVariantsToDomain converts proto template variants, keyed by notification type
+// name, to domain
+func convertProtoVariantsToDomain(variants map[string]*pb.TemplateVariant) map[domain.NotificationType]domain.TemplateVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+	result := make(map[domain.NotificationType]domain.TemplateVariant, len(variants))
+	for notifType, variant := range variants {
+		result[domain.NotificationType(notifType)] = domain.TemplateVariant{
+			Subject:  variant.GetSubject(),
+			Body:     variant.GetBody(),
+			HTMLBody: variant.GetHtmlBody(),
+		}
+	}
+	return result
+}
+
+// convertDomainToProtoTemplate converts a template to proto
+func convertDomainToProtoTemplate(tmpl *domain.Template) *pb.Template {
+	result := &pb.Template{
+		Name:        tmpl.Name,
+		Description: tmpl.Description,
+		Subject:     tmpl.Subject,
+		Body:        tmpl.Body,
+		HtmlBody:    tmpl.HTMLBody,
+		Layout:      tmpl.Layout,
+		Configured:  tmpl.Configured,
+	}
+	if tmpl.Type != "" {
+		result.Type = convertDomainToProtoType(tmpl.Type)
+	}
+	if !tmpl.CreatedAt.IsZero() {
+		result.CreatedAt = timestamppb.New(tmpl.CreatedAt)
+	}
+	if !tmpl.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(tmpl.UpdatedAt)
+	}
+	result.Variants = convertDomainVariantsToProto(tmpl.Variants)
+	if len(tmpl.Locales) > 0 {
+		result.Locales = make(map[string]*pb.TemplateLocale, len(tmpl.Locales))
+		for tag, locale := range tmpl.Locales {
+			result.Locales[tag] = &pb.TemplateLocale{
+				Subject:  locale.Subject,
+				Body:     locale.Body,
+				HtmlBody: locale.HTMLBody,
+				Variants: convertDomainVariantsToProto(locale.Variants),
+			}
+		}
+	}
+	return result
+}
+
+// convertDomainVariantsToProto converts template variants to proto, keyed by notification
+// type name
+func convertDomainVariantsToProto(variants map[domain.NotificationType]domain.TemplateVariant) map[string]*pb.TemplateVariant {
+	if len(variants) == 0 {
+		return nil
+	}
+	result := make(map[string]*pb.TemplateVariant, len(variants))
+	for notifType, variant := range variants {
+		result[string(notifType)] = &pb.TemplateVariant{
+			Subject:  variant.Subject,
+			Body:     variant.Body,
+			HtmlBody: variant.HTMLBody,
+		}
+	}
+	return result
+}
+
+// convertProtoContactToDomain converts a proto contact to domain
+func convertProtoContactToDomain(contact *pb.Contact) domain.Contact {
+	result := domain.Contact{
+		Name:        contact.Name,
+		Description: contact.Description,
+		Members:     contact.Members,
+	}
+	if len(contact.Addresses) > 0 {
+		result.Addresses = make(map[domain.NotificationType][]string, len(contact.Addresses))
+		for notifType, addresses := range contact.Addresses {
+			result.Addresses[domain.NotificationType(notifType)] = addresses.GetAddresses()
+		}
+	}
+	return result
+}
+
+// convertDomainToProtoContact converts a contact to proto
+func convertDomainToProtoContact(contact *domain.Contact) *pb.Contact {
+	result := &pb.Contact{
+		Name:        contact.Name,
+		Description: contact.Description,
+		Members:     contact.Members,
+		Configured:  contact.Configured,
+	}
+	if len(contact.Addresses) > 0 {
+		result.Addresses = make(map[string]*pb.ContactAddresses, len(contact.Addresses))
+		for notifType, addresses := range contact.Addresses {
+			result.Addresses[string(notifType)] = &pb.ContactAddresses{Addresses: addresses}
+		}
+	}
+	if !contact.CreatedAt.IsZero() {
+		result.CreatedAt = timestamppb.New(contact.CreatedAt)
+	}
+	if !contact.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(contact.UpdatedAt)
+	}
+	return result
+}
+
+// convertProtoPreferencesToDomain converts proto preferences to domain
+func convertProtoPreferencesToDomain(prefs *pb.Preferences) domain.Preferences {
+	result := domain.Preferences{
+		Contact:         prefs.Contact,
+		MutedCategories: prefs.MutedCategories,
+	}
+	for _, channel := range prefs.Channels {
+		result.Channels = append(result.Channels, domain.NotificationType(channel))
+	}
+	for _, hours := range prefs.QuietHours {
+		result.QuietHours = append(result.QuietHours, domain.QuietHours{
+			Start:    hours.Start,
+			End:      hours.End,
+			Timezone: hours.Timezone,
+			Days:     hours.Days,
+		})
+	}
+	return result
+}
+
+// convertDomainToProtoPreferences converts preferences to proto
+func convertDomainToProtoPreferences(prefs *domain.Preferences) *pb.Preferences {
+	result := &pb.Preferences{
+		Contact:         prefs.Contact,
+		MutedCategories: prefs.MutedCategories,
+	}
+	for _, channel := range prefs.Channels {
+		result.Channels = append(result.Channels, string(channel))
+	}
+	for _, hours := range prefs.QuietHours {
+		result.QuietHours = append(result.QuietHours, &pb.QuietHours{
+			Start:    hours.Start,
+			End:      hours.End,
+			Timezone: hours.Timezone,
+			Days:     hours.Days,
+		})
+	}
+	if !prefs.UpdatedAt.IsZero() {
+		result.UpdatedAt = timestamppb.New(prefs.UpdatedAt)
+	}
+	return result
+}
+
+// convertDomainToProtoRecurring converts a recurring notification to proto
+func convertDomainToProtoRecurring(definition *domain.RecurringNotification) *pb.RecurringNotification {
+	recurring := &pb.RecurringNotification{
+		Id:                 definition.ID,
+		Schedule:           definition.Schedule,
+		Status:             string(definition.Status),
+		Occurrences:        int32(definition.Occurrences),
+		LastNotificationId: definition.LastNotificationID,
+		LastError:          definition.LastError,
+		CreatedAt:          timestamppb.New(definition.CreatedAt),
+	}
+	if definition.Notification != nil {
+		recurring.Notification = convertDomainToProtoNotification(definition.Notification)
+	}
+	if definition.NextAt != nil {
+		recurring.NextAt = timestamppb.New(*definition.NextAt)
+	}
+	if definition.LastAt != nil {
+		recurring.LastAt = timestamppb.New(*definition.LastAt)
+	}
+	return recurring
+}
+
 func convertProtoFilterToDomain(filter *pb.NotificationFilter) *domain.NotificationFilter {
 	if filter == nil {
 		return &domain.NotificationFilter{}
@@ -407,12 +1476,18 @@ func convertProtoFilterToDomain(filter *pb.NotificationFilter) *domain.Notificat
 	}
 
 	domainFilter := &domain.NotificationFilter{
-		IDs:        filter.Ids,
-		Types:      types,
-		Statuses:   statuses,
-		Recipients: filter.Recipients,
-		Limit:      int(filter.Limit),
-		Offset:     int(filter.Offset),
+		IDs:           filter.Ids,
+		Types:         types,
+		Statuses:      statuses,
+		Recipients:    filter.Recipients,
+		OriginSystems: filter.OriginSystems,
+		OriginUsers:   filter.OriginUsers,
+		Tags:          filter.Tags,
+		FanoutID:      filter.FanoutId,
+		Tenant:        filter.Tenant,
+		Limit:         int(filter.Limit),
+		Offset:        int(filter.Offset),
+		Cursor:        filter.Cursor,
 	}
 
 	if filter.CreatedAfter != nil {
@@ -442,6 +1517,14 @@ func convertProtoStatusToDomain(protoStatus pb.NotificationStatus) domain.Notifi
 		return domain.StatusFailed
 	case pb.NotificationStatus_NOTIFICATION_STATUS_RETRYING:
 		return domain.StatusRetrying
+	case pb.NotificationStatus_NOTIFICATION_STATUS_HELD:
+		return domain.StatusHeld
+	case pb.NotificationStatus_NOTIFICATION_STATUS_PAUSED:
+		return domain.StatusPaused
+	case pb.NotificationStatus_NOTIFICATION_STATUS_SCHEDULED:
+		return domain.StatusScheduled
+	case pb.NotificationStatus_NOTIFICATION_STATUS_DIGESTED:
+		return domain.StatusDigested
 	default:
 		return domain.StatusPending
 	}
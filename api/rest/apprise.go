@@ -0,0 +1,222 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// appriseSchemes maps Apprise URL schemes onto notifier types. Other schemes are taken as
+// notifier type names, so "stdout://" or "webpush://" work too.
+var appriseSchemes = map[string]domain.NotificationType{
+	"mailto":  domain.TypeEmail,
+	"mailtos": domain.TypeEmail,
+	"ntfys":   domain.TypeNtfy,
+	"xmpps":   domain.TypeXMPP,
+}
+
+// appriseTypePriorities maps Apprise notification types onto priorities
+var appriseTypePriorities = map[string]domain.Priority{
+	"info":    domain.PriorityNormal,
+	"success": domain.PriorityNormal,
+	"warning": domain.PriorityHigh,
+	"failure": domain.PriorityCritical,
+}
+
+// AppriseNotify handles POST /notify, Apprise's stateless notification API, so tools that
+// speak Apprise can send through this service. Each of the request's URLs becomes one
+// notification, sent through the accounts configured here: credentials in the URLs are ignored.
+func (h *Handler) AppriseNotify(w http.ResponseWriter, r *http.Request) {
+	var req AppriseRequest
+	if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+	} else {
+		if err := r.ParseMultipartForm(1 << 20); err != nil && err != http.ErrNotMultipart {
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		req = AppriseRequest{
+			URLs:   r.FormValue("urls"),
+			Title:  r.FormValue("title"),
+			Body:   r.FormValue("body"),
+			Type:   r.FormValue("type"),
+			Format: r.FormValue("format"),
+		}
+	}
+
+	// Check every URL before sending any, so a typo doesn't leave a partial send
+	notifications, err := req.ToNotifications()
+	if err != nil {
+		h.logger.Errorf("REST: Invalid Apprise request - error=%v", err)
+		respondError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	// Apprise reports undeliverable URLs right away, so catch notifiers that aren't configured
+	// here rather than leaving the notification to fail in the queue
+	available, err := h.service.GetNotifiers(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to get notifiers", err)
+		return
+	}
+	accounts := make(map[domain.NotificationType][]string)
+	for _, info := range available.Notifiers {
+		accounts[info.Type] = info.Accounts
+	}
+
+	response := AppriseResponse{Results: make([]AppriseResult, 0, len(notifications))}
+	status := http.StatusOK
+	for _, notification := range notifications {
+		notification.DryRun = isDryRun(r)
+		result := AppriseResult{Type: string(notification.Type), Recipients: notification.Recipients}
+		configured, ok := accounts[notification.Type]
+		if !ok || (notification.Account != "" && !slices.Contains(configured, notification.Account)) {
+			result.Error = fmt.Sprintf("no %s notifier is configured", notification.Type)
+			if notification.Account != "" {
+				result.Error = fmt.Sprintf("no %s account %q is configured", notification.Type, notification.Account)
+			}
+			status = http.StatusFailedDependency
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		sent, err := h.service.Send(r.Context(), notification)
+		if err != nil {
+			h.logger.Errorf("REST: Failed to send Apprise notification - type=%s, error=%v", notification.Type, err)
+			result.Error = err.Error()
+			status = http.StatusFailedDependency
+		} else {
+			result.NotificationID = sent.NotificationID
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	h.logger.Infof("REST: Apprise request handled - urls=%d, status=%d", len(notifications), status)
+	respondJSON(w, status, response)
+}
+
+// ToNotifications builds one notification per Apprise URL in the request
+func (r *AppriseRequest) ToNotifications() ([]*domain.Notification, error) {
+	if strings.TrimSpace(r.Body) == "" {
+		return nil, fmt.Errorf("body is required")
+	}
+
+	priority := domain.PriorityNormal
+	if r.Type != "" {
+		p, ok := appriseTypePriorities[strings.ToLower(r.Type)]
+		if !ok {
+			return nil, fmt.Errorf("invalid type %q (must be info, success, warning or failure)", r.Type)
+		}
+		priority = p
+	}
+
+	// Apprise separates URLs with commas or whitespace
+	rawURLs := strings.FieldsFunc(r.URLs, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r'
+	})
+	if len(rawURLs) == 0 {
+		return nil, fmt.Errorf("at least one url is required")
+	}
+
+	notifications := make([]*domain.Notification, 0, len(rawURLs))
+	for i, rawURL := range rawURLs {
+		send, err := appriseTarget(rawURL)
+		if err != nil {
+			// The URL itself isn't echoed, since Apprise URLs often carry credentials
+			return nil, fmt.Errorf("url %d: %w", i+1, err)
+		}
+		send.Subject = r.Title
+		send.Body = r.Body
+		send.Priority = int(priority)
+		send.Metadata = make(map[string]interface{})
+		if r.Type != "" {
+			send.Metadata["apprise_type"] = strings.ToLower(r.Type)
+		}
+		switch strings.ToLower(r.Format) {
+		case "", "text":
+		case "html":
+			send.HTMLBody = r.Body
+		case "markdown":
+			send.Metadata["format"] = "markdown"
+		default:
+			return nil, fmt.Errorf("invalid format %q (must be text, markdown or html)", r.Format)
+		}
+		if err := send.Validate(); err != nil {
+			return nil, fmt.Errorf("url %d: %w", i+1, err)
+		}
+		notifications = append(notifications, send.ToNotification())
+	}
+	return notifications, nil
+}
+
+// appriseTarget reads the notifier type, account and recipients from an Apprise URL. The
+// scheme picks the type; the host, path segments and fragment ("#channel") are recipients,
+// as are the comma-separated "to" query values. "cc" and "bcc" add email copies and "account"
+// picks the account.
+func appriseTarget(rawURL string) (*SendNotificationRequest, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return nil, fmt.Errorf("not a valid Apprise URL")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	notificationType, ok := appriseSchemes[scheme]
+	if !ok {
+		notificationType = domain.NotificationType(scheme)
+	}
+	send := &SendNotificationRequest{Type: string(notificationType)}
+
+	query := u.Query()
+	send.Account = query.Get("account")
+
+	host := u.Hostname()
+	if notificationType == domain.TypeEmail && u.User != nil && host != "" {
+		// mailto://user@example.com
+		send.Recipients = append(send.Recipients, u.User.Username()+"@"+host)
+	} else if host != "" {
+		send.Recipients = append(send.Recipients, host)
+	}
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "" {
+			send.Recipients = append(send.Recipients, segment)
+		}
+	}
+	if u.Fragment != "" {
+		send.Recipients = append(send.Recipients, "#"+u.Fragment)
+	}
+	send.Recipients = append(send.Recipients, splitList(query.Get("to"))...)
+	send.CC = splitList(query.Get("cc"))
+	send.BCC = splitList(query.Get("bcc"))
+
+	return send, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// appriseCredentials lets Apprise clients, which can't set custom headers, authenticate with
+// HTTP basic auth by passing the API key as the password
+func appriseCredentials(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); ok && password != "" && r.Header.Get("X-API-Key") == "" {
+			r.Header.Set("X-API-Key", password)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,97 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// CreateBlackoutWindow handles POST /api/v1/dispatch/blackouts, adding a maintenance window
+// during which notifications for every notifier, one type or one account are held
+func (h *Handler) CreateBlackoutWindow(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeBlackouts(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateBlackoutWindowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	window, err := req.window(time.Now())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid blackout window", err)
+		return
+	}
+	window.CreatedBy = operator
+
+	created, err := manager.CreateBlackoutWindow(r.Context(), window)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "failed to create blackout window", err)
+		return
+	}
+
+	h.logger.Infof("REST: Blackout window created - id=%s, type=%s, account=%s, by=%s", created.ID, req.Type, req.Account, operator)
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// ListBlackoutWindows handles GET /api/v1/dispatch/blackouts
+func (h *Handler) ListBlackoutWindows(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.BlackoutManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "blackout windows are not supported", nil)
+		return
+	}
+
+	windows, err := manager.ListBlackoutWindows(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list blackout windows", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListBlackoutWindowsResponse{Windows: windows})
+}
+
+// DeleteBlackoutWindow handles DELETE /api/v1/dispatch/blackouts/{id}, ending a window early
+func (h *Handler) DeleteBlackoutWindow(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeBlackouts(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := manager.DeleteBlackoutWindow(r.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrBlackoutNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrBlackoutConfigured):
+			status = http.StatusConflict
+		}
+		respondError(w, status, "failed to delete blackout window", err)
+		return
+	}
+
+	h.logger.Infof("REST: Blackout window deleted - id=%s, by=%s", id, operator)
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "blackout window deleted",
+	})
+}
+
+// authorizeBlackouts checks the service supports blackout windows and that the caller may
+// manage them. It returns the manager and the operator's client ID.
+func (h *Handler) authorizeBlackouts(w http.ResponseWriter, r *http.Request) (domain.BlackoutManager, string, bool) {
+	manager, ok := h.service.(domain.BlackoutManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "blackout windows are not supported", nil)
+		return nil, "", false
+	}
+
+	operator, ok := authorizeOperator(w, r)
+	return manager, operator, ok
+}
@@ -0,0 +1,294 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// CompatProfile maps another notification service's send request shape onto ours, so clients
+// migrating from it can keep sending their existing requests
+type CompatProfile struct {
+	// Name identifies the profile in logs
+	Name string
+
+	// APIKeys and ClientIDs select the requests the profile applies to, by API key name or client ID
+	APIKeys   []string
+	ClientIDs []string
+
+	// Envelope is the dotted path to the notification in a send request; empty means the whole body
+	Envelope string
+
+	// BatchField is the dotted path to the list of notifications in a batch request
+	BatchField string
+
+	// Fields maps dotted paths in their requests onto our fields, in order
+	Fields []CompatField
+
+	// Types maps their notification type names onto ours, case-insensitively
+	Types map[string]string
+
+	// Unmapped is what happens to top-level fields that are neither ours nor mapped:
+	// "metadata" copies them into metadata (the default), "drop" ignores them and "reject" fails the request
+	Unmapped string
+}
+
+// CompatField maps one field of another service's requests onto one of ours
+type CompatField struct {
+	From string // Dotted path in their request, matched case-insensitively
+	To   string // Our field, "origin.system", "origin.user" or "metadata.<key>"
+}
+
+// requestFields are the top-level fields of a send request, which pass through a profile unchanged
+var requestFields = map[string]string{
+	"type": "type", "account": "account", "priority": "priority", "subject": "subject", "body": "body",
+	"html_body": "html_body", "content_type": "content_type", "recipients": "recipients", "cc": "cc",
+	"bcc": "bcc", "metadata": "metadata", "origin": "origin", "scheduled_for": "scheduled_for",
+	"max_retries": "max_retries", "dry_run": "dry_run",
+}
+
+// compat rewrites send requests from clients assigned a compatibility profile
+type compat struct {
+	byKey    map[string]*CompatProfile // Lowercased API key name
+	byClient map[string]*CompatProfile
+	types    map[*CompatProfile]map[string]string // Lowercased type names per profile
+	logger   *logging.Logger
+}
+
+// newCompat indexes the compatibility profiles by the API keys and clients they apply to
+func newCompat(profiles []CompatProfile, logger *logging.Logger) *compat {
+	c := &compat{
+		byKey:    make(map[string]*CompatProfile),
+		byClient: make(map[string]*CompatProfile),
+		types:    make(map[*CompatProfile]map[string]string),
+		logger:   logger,
+	}
+	for i := range profiles {
+		profile := &profiles[i]
+		if profile.BatchField == "" {
+			profile.BatchField = "notifications"
+		}
+		for _, key := range profile.APIKeys {
+			c.byKey[strings.ToLower(key)] = profile
+		}
+		for _, clientID := range profile.ClientIDs {
+			c.byClient[clientID] = profile
+		}
+		types := make(map[string]string, len(profile.Types))
+		for from, to := range profile.Types {
+			types[strings.ToLower(from)] = to
+		}
+		c.types[profile] = types
+	}
+	return c
+}
+
+// profile returns the compatibility profile for a request's API key, or nil if it has none
+func (c *compat) profile(r *http.Request) *CompatProfile {
+	authCtx, ok := auth.GetAuthContext(r.Context())
+	if !ok {
+		return nil
+	}
+	if authCtx.APIKey != nil {
+		if profile, ok := c.byKey[strings.ToLower(authCtx.APIKey.Name)]; ok {
+			return profile
+		}
+	}
+	return c.byClient[authCtx.ClientID]
+}
+
+// wrap translates the bodies of requests with a compatibility profile before next handles
+// them. batch selects the batch request shape.
+func (c *compat) wrap(next http.HandlerFunc, batch bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		profile := c.profile(r)
+		if profile == nil {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		translated, err := c.translateRequest(profile, body, batch)
+		if err != nil {
+			c.logger.Errorf("REST: Failed to translate request with compat profile - profile=%s, error=%v", profile.Name, err)
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		c.logger.Debugf("REST: Translated request with compat profile - profile=%s, uri=%s", profile.Name, r.URL.RequestURI())
+
+		r.Body = io.NopCloser(bytes.NewReader(translated))
+		r.ContentLength = int64(len(translated))
+		next(w, r)
+	}
+}
+
+// translateRequest rewrites a send or batch request body into our shape
+func (c *compat) translateRequest(profile *CompatProfile, body []byte, batch bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var root interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	if !batch {
+		source, ok := lookupPath(root, profile.Envelope)
+		if !ok {
+			return nil, fmt.Errorf("request has no %s", profile.Envelope)
+		}
+		notification, err := c.translate(profile, source)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(notification)
+	}
+
+	list, _ := lookupPath(root, profile.BatchField)
+	items, ok := list.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a list of notifications", profile.BatchField)
+	}
+	notifications := make([]interface{}, 0, len(items))
+	for i, item := range items {
+		notification, err := c.translate(profile, item)
+		if err != nil {
+			return nil, fmt.Errorf("notification %d: %w", i, err)
+		}
+		notifications = append(notifications, notification)
+	}
+	return json.Marshal(map[string]interface{}{"notifications": notifications})
+}
+
+// translate maps one notification in another service's shape onto a send request
+func (c *compat) translate(profile *CompatProfile, source interface{}) (map[string]interface{}, error) {
+	fields, ok := source.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("notification must be an object")
+	}
+
+	// Top-level fields read by a mapping aren't passed through
+	mapped := make(map[string]bool)
+	for _, field := range profile.Fields {
+		top, _, _ := strings.Cut(field.From, ".")
+		mapped[strings.ToLower(top)] = true
+	}
+
+	// Our own fields first, so flattened fields can be added to metadata
+	request := make(map[string]interface{})
+	var unmapped []string
+	for key, value := range fields {
+		if mapped[strings.ToLower(key)] {
+			continue
+		}
+		if name, ok := requestFields[strings.ToLower(key)]; ok {
+			request[name] = value
+			continue
+		}
+		unmapped = append(unmapped, key)
+	}
+	for _, key := range unmapped {
+		switch profile.Unmapped {
+		case "drop":
+		case "reject":
+			return nil, fmt.Errorf("unknown field %q", key)
+		default:
+			metadata, ok := request["metadata"].(map[string]interface{})
+			if !ok {
+				metadata = make(map[string]interface{})
+				request["metadata"] = metadata
+			}
+			metadata[key] = fields[key]
+		}
+	}
+
+	for _, field := range profile.Fields {
+		if value, ok := lookupPath(fields, field.From); ok {
+			setPath(request, field.To, value)
+		}
+	}
+
+	if notificationType, ok := request["type"].(string); ok {
+		if to, ok := c.types[profile][strings.ToLower(notificationType)]; ok {
+			request["type"] = to
+		}
+	}
+	if err := normalizeCompatValues(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// normalizeCompatValues converts the value shapes other services commonly use: a single
+// recipient instead of a list, and named or quoted priorities
+func normalizeCompatValues(request map[string]interface{}) error {
+	for _, name := range []string{"recipients", "cc", "bcc"} {
+		if recipient, ok := request[name].(string); ok {
+			request[name] = []interface{}{recipient}
+		}
+	}
+
+	if name, ok := request["priority"].(string); ok {
+		if n, err := strconv.Atoi(name); err == nil {
+			request["priority"] = n
+		} else if priority, err := domain.ParsePriority(strings.ToLower(name)); err == nil {
+			request["priority"] = int(priority)
+		} else {
+			return fmt.Errorf("invalid priority %q", name)
+		}
+	}
+	return nil
+}
+
+// lookupPath returns the value at a dotted path in decoded JSON, matching keys
+// case-insensitively. An empty path is the value itself.
+func lookupPath(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+	for _, segment := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[segment]; ok {
+			continue
+		}
+		found := false
+		for key, v := range object {
+			if strings.EqualFold(key, segment) {
+				value, found = v, true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// setPath sets the value at a dotted path, creating objects along the way
+func setPath(object map[string]interface{}, path string, value interface{}) {
+	segments := strings.Split(path, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		child, ok := object[segment].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			object[segment] = child
+		}
+		object = child
+	}
+	object[segments[len(segments)-1]] = value
+}
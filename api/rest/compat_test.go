@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestCompatProfile tests that requests from a key with a compatibility profile are translated
+// and that other keys are unaffected
+func TestCompatProfile(t *testing.T) {
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+
+	store := auth.NewAPIKeyStore()
+	legacyKey, err := store.CreateKey("legacy-app", []string{"admin"}, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	nativeKey, err := store.CreateKey("native-app", []string{"admin"}, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	router := NewRouterWithOptions(svc, logger, RouterOptions{
+		AuthStore: store,
+		Compat: []CompatProfile{{
+			Name:      "legacy",
+			ClientIDs: []string{"legacy-app"},
+			Envelope:  "message",
+			Fields: []CompatField{
+				{From: "channel", To: "type"},
+				{From: "to", To: "recipients"},
+				{From: "content.title", To: "subject"},
+				{From: "content.text", To: "body"},
+				{From: "urgency", To: "priority"},
+				{From: "sender", To: "origin.user"},
+			},
+			Types:    map[string]string{"Console": "stdout"},
+			Unmapped: "metadata",
+		}},
+	})
+
+	post := func(key *auth.APIKey, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+key.Key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	get := func(key *auth.APIKey, id string) *Notification {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/"+id, nil)
+		req.Header.Set("Authorization", "Bearer "+key.Key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var notification Notification
+		if err := json.NewDecoder(rec.Body).Decode(&notification); err != nil {
+			t.Fatalf("Failed to decode notification: %v", err)
+		}
+		return &notification
+	}
+
+	rec := post(legacyKey, "/api/v1/notifications",
+		`{"message":{"Channel":"console","to":"ops","content":{"title":"Disk","text":"Disk full"},"urgency":"HIGH","sender":"jo","campaign":"q3"}}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Legacy send returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var response SendNotificationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	notification := get(legacyKey, response.Result.NotificationID)
+	if notification.Type != string(domain.TypeStdout) || notification.Subject != "Disk" || notification.Body != "Disk full" ||
+		notification.Priority != int(domain.PriorityHigh) || len(notification.Recipients) != 1 || notification.Recipients[0] != "ops" {
+		t.Errorf("Unexpected translated notification: %+v", notification)
+	}
+	if notification.Origin.User != "jo" || notification.Metadata["campaign"] != "q3" {
+		t.Errorf("Expected origin user and flattened metadata, got origin=%+v metadata=%v", notification.Origin, notification.Metadata)
+	}
+
+	rec = post(legacyKey, "/api/v1/notifications/batch",
+		`{"notifications":[{"channel":"console","to":["a"],"content":{"text":"one"}},{"channel":"console","to":"b","content":{"text":"two"}}]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Legacy batch returned %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec = post(legacyKey, "/api/v1/notifications", `{"channel":"console"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a request without the envelope to be rejected, got %d", rec.Code)
+	}
+
+	// Other keys keep the native request shape
+	rec = post(nativeKey, "/api/v1/notifications", `{"type":"stdout","recipients":["a"],"body":"native"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Native send returned %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// CreateContact handles POST /api/v1/contacts, saving a contact or group notifications can be
+// sent to by name
+func (h *Handler) CreateContact(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeContacts(w, r)
+	if !ok {
+		return
+	}
+
+	var req ContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	created, err := manager.CreateContact(r.Context(), req.contact())
+	if err != nil {
+		respondError(w, contactErrorStatus(err), "failed to create contact", err)
+		return
+	}
+
+	h.logger.Infof("REST: Contact created - name=%s, by=%s", created.Name, operator)
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// UpdateContact handles PUT /api/v1/contacts/{name}, replacing a contact created through the
+// API
+func (h *Handler) UpdateContact(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeContacts(w, r)
+	if !ok {
+		return
+	}
+
+	var req ContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if req.Name != "" && req.Name != name {
+		respondError(w, http.StatusBadRequest, "contact name can't be changed", nil)
+		return
+	}
+	req.Name = name
+
+	updated, err := manager.UpdateContact(r.Context(), req.contact())
+	if err != nil {
+		respondError(w, contactErrorStatus(err), "failed to update contact", err)
+		return
+	}
+
+	h.logger.Infof("REST: Contact updated - name=%s, by=%s", updated.Name, operator)
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// GetContact handles GET /api/v1/contacts/{name}
+func (h *Handler) GetContact(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.ContactManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "contacts are not supported", nil)
+		return
+	}
+
+	contact, err := manager.GetContact(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		respondError(w, contactErrorStatus(err), "failed to get contact", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, contact)
+}
+
+// ListContacts handles GET /api/v1/contacts
+func (h *Handler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.ContactManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "contacts are not supported", nil)
+		return
+	}
+
+	contacts, err := manager.ListContacts(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list contacts", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListContactsResponse{Contacts: contacts})
+}
+
+// DeleteContact handles DELETE /api/v1/contacts/{name}
+func (h *Handler) DeleteContact(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeContacts(w, r)
+	if !ok {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := manager.DeleteContact(r.Context(), name); err != nil {
+		respondError(w, contactErrorStatus(err), "failed to delete contact", err)
+		return
+	}
+
+	h.logger.Infof("REST: Contact deleted - name=%s, by=%s", name, operator)
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "contact deleted",
+	})
+}
+
+// authorizeContacts checks the service supports contacts and that the caller may change
+// them. It returns the manager and the operator's client ID.
+func (h *Handler) authorizeContacts(w http.ResponseWriter, r *http.Request) (domain.ContactManager, string, bool) {
+	manager, ok := h.service.(domain.ContactManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "contacts are not supported", nil)
+		return nil, "", false
+	}
+
+	operator, ok := authorizeTenantOperator(w, r)
+	return manager, operator, ok
+}
+
+// contactErrorStatus returns the HTTP status for an error from managing contacts
+func contactErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrContactNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidContact):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrContactExists), errors.Is(err, domain.ErrContactConfigured):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
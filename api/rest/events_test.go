@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestStreamStatusEvents tests that status transitions are streamed as server-sent events
+func TestStreamStatusEvents(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	server := httptest.NewServer(NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logger), logger))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/notifications/events?origin=billing")
+	if err != nil {
+		t.Fatalf("Failed to open event stream: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// Wait for the stream to be established before sending
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || !strings.HasPrefix(line, ":") {
+		t.Fatalf("Expected initial comment, got %q, %v", line, err)
+	}
+
+	for _, origin := range []string{"web", "billing"} {
+		body := `{"type":"stdout","body":"hello","recipients":["console"],"origin":{"system":"` + origin + `"}}`
+		sendResp, err := http.Post(server.URL+"/api/v1/notifications", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		sendResp.Body.Close()
+	}
+
+	events := make(chan domain.StatusEvent, 1)
+	go func() {
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event domain.StatusEvent
+				if err := json.Unmarshal([]byte(data), &event); err == nil {
+					events <- event
+				}
+				return
+			}
+		}
+	}()
+
+	select {
+	case event := <-events:
+		if event.Origin.System != "billing" || event.Status != domain.StatusQueued || event.NotificationID == "" {
+			t.Errorf("Unexpected event: %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for status event")
+	}
+}
@@ -0,0 +1,188 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// exportPageSize is how many notifications an export reads from the store at a time
+const exportPageSize = 500
+
+// exportColumns are the CSV columns of an export, in order
+var exportColumns = []string{
+	"id", "type", "account", "status", "priority", "subject", "recipients", "origin_system", "origin_user",
+	"retry_count", "last_error", "job_id", "dry_run", "created_at", "scheduled_for", "sent_at", "acknowledged_at",
+}
+
+// notificationWriter writes one notification per row or line of an export
+type notificationWriter interface {
+	Write(notification *domain.Notification) error
+	Flush() error
+}
+
+// ExportNotifications handles GET /api/v1/notifications/export, streaming every notification
+// matching the listing filters as CSV or JSONL, newest first. limit caps the number exported,
+// and offset or cursor start the export part way through the listing.
+func (h *Handler) ExportNotifications(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "csv" && format != "jsonl" {
+		respondError(w, http.StatusBadRequest, "format must be csv or jsonl", nil)
+		return
+	}
+
+	filter := parseNotificationFilter(r)
+	remaining := filter.Limit
+	filter.Limit = exportPageSize
+	if remaining > 0 {
+		filter.Limit = min(remaining, exportPageSize)
+	}
+
+	// Read the first page before responding, so a bad filter still gets an error status
+	page, err := h.service.ListNotifications(r.Context(), filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		respondError(w, http.StatusBadRequest, "invalid cursor", err)
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to export notifications", err)
+		return
+	}
+
+	// Large exports outlive the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debugf("REST: Failed to clear write deadline for export - error=%v", err)
+	}
+
+	filename := "notifications-" + time.Now().UTC().Format("20060102T150405Z") + "." + format
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	setStalenessHeaders(w, h.readStaleness(r.Context()))
+	var out notificationWriter
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		out = newCSVExport(w)
+	} else {
+		w.Header().Set("Content-Type", NDJSONContentType)
+		out = &jsonlExport{encoder: json.NewEncoder(w)}
+	}
+	w.WriteHeader(http.StatusOK)
+
+	exported := 0
+	for {
+		for _, notification := range page.Notifications {
+			if err := out.Write(notification); err != nil {
+				h.logger.Warnf("REST: Export interrupted - exported=%d, error=%v", exported, err)
+				return
+			}
+			exported++
+		}
+		if err := out.Flush(); err != nil {
+			h.logger.Warnf("REST: Export interrupted - exported=%d, error=%v", exported, err)
+			return
+		}
+
+		if remaining > 0 {
+			remaining -= len(page.Notifications)
+			if remaining <= 0 {
+				return
+			}
+			filter.Limit = min(remaining, exportPageSize)
+		}
+		if page.NextCursor == "" {
+			return
+		}
+		filter.Cursor, filter.Offset = page.NextCursor, 0
+
+		page, err = h.service.ListNotifications(r.Context(), filter)
+		if err != nil {
+			// The status is already sent; cutting the response short tells the client it failed
+			h.logger.Errorf("REST: Export failed part way - exported=%d, error=%v", exported, err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// jsonlExport writes notifications in their API format, one per line
+type jsonlExport struct {
+	encoder *json.Encoder
+}
+
+// Write writes one notification
+func (e *jsonlExport) Write(notification *domain.Notification) error {
+	return e.encoder.Encode(NotificationFromDomain(notification))
+}
+
+// Flush does nothing; each line is written as it's encoded
+func (e *jsonlExport) Flush() error {
+	return nil
+}
+
+// csvExport writes a header row, then one row per notification
+type csvExport struct {
+	writer *csv.Writer
+}
+
+// newCSVExport creates a CSV export and writes its header row
+func newCSVExport(w io.Writer) *csvExport {
+	e := &csvExport{writer: csv.NewWriter(w)}
+	e.writer.Write(exportColumns)
+	return e
+}
+
+// Write writes one notification. Free-text cells that a spreadsheet would read as a
+// formula are prefixed with a quote.
+func (e *csvExport) Write(n *domain.Notification) error {
+	return e.writer.Write([]string{
+		n.ID,
+		string(n.Type),
+		n.Account,
+		strconv.Itoa(int(n.Priority)),
+		string(n.Status),
+		csvText(n.Subject),
+		csvText(strings.Join(n.Recipients, ";")),
+		csvText(n.Origin.System),
+		csvText(n.Origin.User),
+		strconv.Itoa(n.RetryCount),
+		csvText(n.LastError),
+		n.JobID,
+		strconv.FormatBool(n.DryRun),
+		csvTime(&n.CreatedAt),
+		csvTime(n.ScheduledFor),
+		csvTime(n.SentAt),
+		csvTime(n.AcknowledgedAt),
+	})
+}
+
+// Flush writes buffered rows to the response
+func (e *csvExport) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// csvText neutralizes text that spreadsheet applications would evaluate as a formula
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// csvTime formats an optional time as RFC 3339, or an empty cell
+func csvTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestExportNotifications tests CSV and JSONL exports with listing filters
+func TestExportNotifications(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	router := NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logger), logger)
+
+	for _, subject := range []string{"first", "=HYPERLINK(\"x\")", "third"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications",
+			strings.NewReader(`{"type":"stdout","subject":`+jsonString(subject)+`,"body":"hello","recipients":["a","b"]}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/export?format=csv&type=stdout", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("CSV export returned %d (%s): %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), ".csv") || rec.Header().Get("X-Total-Count") != "3" {
+		t.Errorf("Unexpected export headers: %v", rec.Header())
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(rows) != 4 || rows[0][0] != "id" || len(rows[1]) != len(exportColumns) {
+		t.Fatalf("Expected a header and 3 rows, got %v", rows)
+	}
+	if rows[1][5] != "third" || rows[2][5] != `'=HYPERLINK("x")` || rows[1][6] != "a;b" {
+		t.Errorf("Unexpected rows, newest first with formulas neutralized: %v", rows[1:])
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/export?limit=2", nil))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if rec.Code != http.StatusOK || len(lines) != 2 {
+		t.Fatalf("Expected 2 JSONL lines, got %d (%d): %s", len(lines), rec.Code, rec.Body.String())
+	}
+	var exported Notification
+	if err := json.Unmarshal([]byte(lines[1]), &exported); err != nil || exported.Subject != `=HYPERLINK("x")` {
+		t.Errorf("Unexpected JSONL line %q (%v)", lines[1], err)
+	}
+
+	for _, url := range []string{"/api/v1/notifications/export?format=xml", "/api/v1/notifications/export?cursor=bogus"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s returned %d, want 400", url, rec.Code)
+		}
+	}
+}
+
+// jsonString encodes a string as a JSON string literal
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
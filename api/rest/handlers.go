@@ -1,12 +1,22 @@
 package rest
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/auth"
 	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
 )
@@ -43,6 +53,7 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 
 	// Convert to domain notification
 	notification := req.ToNotification()
+	notification.DryRun = notification.DryRun || isDryRun(r)
 
 	// Log incoming request
 	h.logger.Infof("REST: Received notification request - type=%s, account=%s, recipients=%d, subject=%s",
@@ -53,7 +64,7 @@ func (h *Handler) SendNotification(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Errorf("REST: Failed to send notification - type=%s, account=%s, error=%v",
 			notification.Type, notification.Account, err)
-		respondError(w, http.StatusInternalServerError, "failed to send notification", err)
+		respondError(w, sendErrorStatus(err), "failed to send notification", err)
 		return
 	}
 
@@ -85,14 +96,16 @@ func (h *Handler) SendBatchNotifications(w http.ResponseWriter, r *http.Request)
 			respondError(w, http.StatusBadRequest, "validation failed", err)
 			return
 		}
-		notifications = append(notifications, notifReq.ToNotification())
+		notification := notifReq.ToNotification()
+		notification.DryRun = notification.DryRun || isDryRun(r)
+		notifications = append(notifications, notification)
 	}
 
 	// Send batch
 	results, err := h.service.SendBatch(r.Context(), notifications)
 	if err != nil {
 		h.logger.Errorf("REST: Failed to send batch notifications - error=%v", err)
-		respondError(w, http.StatusInternalServerError, "failed to send batch notifications", err)
+		respondError(w, sendErrorStatus(err), "failed to send batch notifications", err)
 		return
 	}
 
@@ -118,6 +131,107 @@ func (h *Handler) SendBatchNotifications(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// NDJSONContentType is the content type of newline-delimited JSON streams
+const NDJSONContentType = "application/x-ndjson"
+
+// Streamed send limits: the whole stream, and each line (the single send limit)
+const (
+	maxStreamBodySize = 1 << 30
+	maxStreamLineSize = 1 << 20
+)
+
+// StreamNotifications handles POST /api/v1/notifications/stream. The body is NDJSON with one
+// send request per line; each line is sent as soon as it's read and answered with a line of
+// the NDJSON response, so neither side buffers the whole batch. A summary line ends the response.
+func (h *Handler) StreamNotifications(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, _ := strings.Cut(r.Header.Get("Content-Type"), ";"); strings.TrimSpace(mediaType) != NDJSONContentType {
+		respondError(w, http.StatusUnsupportedMediaType, "content type must be "+NDJSONContentType, nil)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "streaming is not supported", nil)
+		return
+	}
+
+	// The stream outlives the server's timeouts, and results are written while the body is still arriving
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		h.logger.Debugf("REST: Failed to clear read deadline for notification stream - error=%v", err)
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debugf("REST: Failed to clear write deadline for notification stream - error=%v", err)
+	}
+	if err := controller.EnableFullDuplex(); err != nil {
+		h.logger.Debugf("REST: Failed to enable full duplex for notification stream - error=%v", err)
+	}
+
+	w.Header().Set("Content-Type", NDJSONContentType)
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	encoder := json.NewEncoder(w)
+
+	var summary StreamSummary
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		result := h.sendStreamedLine(r, line, data)
+		summary.Lines++
+		if result.Result != nil && result.Result.Success {
+			summary.Accepted++
+		} else {
+			summary.Failed++
+		}
+		if err := encoder.Encode(result); err != nil {
+			h.logger.Warnf("REST: Notification stream client went away - line=%d, error=%v", line, err)
+			return
+		}
+		flusher.Flush()
+	}
+	if err := scanner.Err(); err != nil {
+		summary.Error = fmt.Sprintf("stopped reading at line %d: %v", line+1, err)
+	}
+
+	h.logger.Infof("REST: Notification stream completed - lines=%d, accepted=%d, failed=%d",
+		summary.Lines, summary.Accepted, summary.Failed)
+	encoder.Encode(StreamSummaryLine{Summary: summary})
+	flusher.Flush()
+}
+
+// sendStreamedLine decodes, validates and sends one line of a notification stream
+func (h *Handler) sendStreamedLine(r *http.Request, line int, data []byte) StreamNotificationResult {
+	var req SendNotificationRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return StreamNotificationResult{Line: line, Status: http.StatusBadRequest, Error: "invalid request: " + err.Error()}
+	}
+	if err := req.Validate(); err != nil {
+		return StreamNotificationResult{Line: line, Status: http.StatusBadRequest, Error: "validation failed: " + err.Error()}
+	}
+
+	notification := req.ToNotification()
+	notification.DryRun = notification.DryRun || isDryRun(r)
+	result, err := h.service.Send(r.Context(), notification)
+	if err != nil {
+		status := StreamNotificationResult{Line: line, Status: sendErrorStatus(err), Error: err.Error()}
+		if result != nil {
+			apiResult := NotificationResultFromDomain(result)
+			status.Result = &apiResult
+		}
+		return status
+	}
+
+	apiResult := NotificationResultFromDomain(result)
+	return StreamNotificationResult{Line: line, Status: http.StatusAccepted, Result: &apiResult}
+}
+
 // GetNotification handles GET /api/v1/notifications/{id}
 func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -129,6 +243,7 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setStalenessHeaders(w, h.readStaleness(r.Context()))
 	respondJSON(w, http.StatusOK, NotificationFromDomain(notification))
 }
 
@@ -136,24 +251,200 @@ func (h *Handler) GetNotification(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ListNotifications(w http.ResponseWriter, r *http.Request) {
 	filter := parseNotificationFilter(r)
 
-	notifications, err := h.service.ListNotifications(r.Context(), filter)
+	page, err := h.service.ListNotifications(r.Context(), filter)
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		respondError(w, http.StatusBadRequest, "invalid cursor", err)
+		return
+	}
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "failed to list notifications", err)
 		return
 	}
 
 	// Convert to API format
-	apiNotifications := make([]Notification, 0, len(notifications))
-	for _, notif := range notifications {
+	apiNotifications := make([]Notification, 0, len(page.Notifications))
+	for _, notif := range page.Notifications {
 		apiNotifications = append(apiNotifications, NotificationFromDomain(notif))
 	}
 
 	respondJSON(w, http.StatusOK, ListNotificationsResponse{
 		Notifications: apiNotifications,
-		Total:         int64(len(apiNotifications)),
+		Total:         page.Total,
+		NextCursor:    page.NextCursor,
+		Staleness:     h.readStaleness(r.Context()),
 	})
 }
 
+// maxStatusIDs is the most notification IDs a single status poll may request
+const maxStatusIDs = 10000
+
+// GetNotificationStatuses handles GET /api/v1/notifications/status?ids=a,b,c
+// Returns only the status of each notification and supports If-None-Match, so monitors
+// tracking many in-flight notifications can poll cheaply.
+func (h *Handler) GetNotificationStatuses(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+	for _, value := range r.URL.Query()["ids"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) == 0 {
+		respondError(w, http.StatusBadRequest, "ids is required", nil)
+		return
+	}
+	if len(ids) > maxStatusIDs {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("at most %d ids may be requested", maxStatusIDs), nil)
+		return
+	}
+
+	resp := NotificationStatusesResponse{Statuses: make(map[string]string, len(ids))}
+	for _, id := range ids {
+		if _, seen := resp.Statuses[id]; seen {
+			continue
+		}
+		notification, err := h.service.GetNotification(r.Context(), id)
+		if err != nil {
+			resp.Missing = append(resp.Missing, id)
+			continue
+		}
+		resp.Statuses[id] = string(notification.Status)
+	}
+
+	etag := statusesETag(resp)
+	setStalenessHeaders(w, h.readStaleness(r.Context()))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// statusEventKeepalive is how often an idle status event stream sends a comment to keep proxies from closing it
+const statusEventKeepalive = 15 * time.Second
+
+// StreamStatusEvents handles GET /api/v1/notifications/events, streaming status transitions as
+// server-sent events. Optional id, type and origin query parameters restrict the stream.
+func (h *Handler) StreamStatusEvents(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.service.(domain.StatusEventSource)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "status events are not supported", nil)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, http.StatusInternalServerError, "streaming is not supported", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	ids := make(map[string]bool)
+	for _, value := range query["id"] {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids[id] = true
+			}
+		}
+	}
+	notifType := domain.NotificationType(query.Get("type"))
+	origin := query.Get("origin")
+	tenant := auth.TenantFromContext(r.Context())
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Debugf("REST: Failed to clear write deadline for status stream - error=%v", err)
+	}
+
+	events, unsubscribe := source.SubscribeStatus(0)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepalive := time.NewTicker(statusEventKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if len(ids) > 0 && !ids[event.NotificationID] {
+				continue
+			}
+			if notifType != "" && event.Type != notifType {
+				continue
+			}
+			if origin != "" && event.Origin.StatsKey() != origin {
+				continue
+			}
+			if tenant != "" && event.Tenant != tenant {
+				continue
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				h.logger.Errorf("REST: Failed to encode status event - id=%s, error=%v", event.NotificationID, err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// statusesETag derives an ETag from the statuses and missing IDs, independent of request order
+func statusesETag(resp NotificationStatusesResponse) string {
+	ids := make([]string, 0, len(resp.Statuses))
+	for id := range resp.Statuses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	missing := append([]string(nil), resp.Missing...)
+	sort.Strings(missing)
+
+	hash := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(hash, "%s=%s\n", id, resp.Statuses[id])
+	}
+	for _, id := range missing {
+		fmt.Fprintf(hash, "%s\n", id)
+	}
+	return fmt.Sprintf(`"%x"`, hash.Sum(nil)[:16])
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // CancelNotification handles DELETE /api/v1/notifications/{id}
 func (h *Handler) CancelNotification(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -177,7 +468,11 @@ func (h *Handler) RetryNotification(w http.ResponseWriter, r *http.Request) {
 
 	result, err := h.service.RetryNotification(r.Context(), id)
 	if err != nil {
-		respondError(w, http.StatusInternalServerError, "failed to retry notification", err)
+		status := sendErrorStatus(err)
+		if errors.Is(err, domain.ErrNotHeld) {
+			status = http.StatusConflict
+		}
+		respondError(w, status, "failed to retry notification", err)
 		return
 	}
 
@@ -186,6 +481,168 @@ func (h *Handler) RetryNotification(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// RescheduleNotification handles POST /api/v1/notifications/{id}/reschedule, pushing back when
+// a pending, queued or scheduled notification is delivered
+func (h *Handler) RescheduleNotification(w http.ResponseWriter, r *http.Request) {
+	rescheduler, ok := h.service.(domain.NotificationRescheduler)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "rescheduling is not supported", nil)
+		return
+	}
+
+	var req RescheduleNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	at, err := req.deliveryTime(time.Now())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid reschedule request", err)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	notification, err := rescheduler.RescheduleNotification(r.Context(), id, at)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, domain.ErrNotificationNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, domain.ErrNotReschedulable):
+			status = http.StatusConflict
+		case errors.Is(err, domain.ErrRescheduleEarlier):
+			status = http.StatusBadRequest
+		}
+		respondError(w, status, "failed to reschedule notification", err)
+		return
+	}
+
+	h.logger.Infof("REST: Notification rescheduled - id=%s, scheduled_for=%s", id, at.UTC().Format(time.RFC3339))
+	respondJSON(w, http.StatusOK, NotificationFromDomain(notification))
+}
+
+// ApproveNotification handles POST /api/v1/notifications/{id}/approve, releasing a notification
+// held by a content policy rule for delivery
+func (h *Handler) ApproveNotification(w http.ResponseWriter, r *http.Request) {
+	approver, reviewer, ok := h.authorizeReview(w, r)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := approver.ApproveNotification(r.Context(), id, reviewer); err != nil {
+		respondError(w, reviewErrorStatus(err), "failed to approve notification", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "notification approved and queued",
+	})
+}
+
+// RejectNotification handles POST /api/v1/notifications/{id}/reject, failing a held notification
+// without sending it
+func (h *Handler) RejectNotification(w http.ResponseWriter, r *http.Request) {
+	approver, reviewer, ok := h.authorizeReview(w, r)
+	if !ok {
+		return
+	}
+
+	var req RejectNotificationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := approver.RejectNotification(r.Context(), id, reviewer, req.Reason); err != nil {
+		respondError(w, reviewErrorStatus(err), "failed to reject notification", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "notification rejected",
+	})
+}
+
+// authorizeReview checks the service supports approvals and, when authentication is enabled,
+// that the caller has the admin role. It returns the approver and the reviewer's client ID.
+func (h *Handler) authorizeReview(w http.ResponseWriter, r *http.Request) (domain.NotificationApprover, string, bool) {
+	approver, ok := h.service.(domain.NotificationApprover)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "notification approval is not supported", nil)
+		return nil, "", false
+	}
+
+	reviewer := "anonymous"
+	if authCtx, ok := auth.GetAuthContext(r.Context()); ok {
+		if !hasRole(authCtx, "admin") {
+			respondError(w, http.StatusForbidden, "admin role required", nil)
+			return nil, "", false
+		}
+		reviewer = authCtx.ClientID
+	}
+	return approver, reviewer, true
+}
+
+// reviewErrorStatus returns the HTTP status for an error from approving or rejecting a notification
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotHeld):
+		return http.StatusConflict
+	case strings.HasPrefix(err.Error(), "notification not found"):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// defaultPreviewWindow is how much history a policy preview replays when no window is given
+const defaultPreviewWindow = 24 * time.Hour
+
+// PreviewPolicies handles POST /api/v1/policies/preview, replaying recent notifications through
+// a proposed content_policy, budgets or hedging change (a YAML body) and reporting how their
+// outcomes would differ. The history replayed is set with ?window= (default 24h).
+func (h *Handler) PreviewPolicies(w http.ResponseWriter, r *http.Request) {
+	simulator, ok := h.service.(domain.PolicySimulator)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "policy preview is not supported", nil)
+		return
+	}
+	if authCtx, ok := auth.GetAuthContext(r.Context()); ok && !hasRole(authCtx, "admin") {
+		respondError(w, http.StatusForbidden, "admin role required", nil)
+		return
+	}
+
+	window := defaultPreviewWindow
+	if value := r.URL.Query().Get("window"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			respondError(w, http.StatusBadRequest, "invalid window", err)
+			return
+		}
+		window = parsed
+	}
+
+	proposal, err := io.ReadAll(r.Body)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	report, err := simulator.SimulatePolicies(r.Context(), proposal, window)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "failed to preview policies", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, report)
+}
+
 // GetStats handles GET /api/v1/stats
 func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.service.GetStats(r.Context())
@@ -211,13 +668,28 @@ func (h *Handler) GetNotifiers(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, notifiers)
 }
 
-// HealthCheck handles GET /health
+// HealthCheck handles GET /health. It responds 503 while the service reports health problems,
+// such as failing canaries.
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	respondJSON(w, http.StatusOK, map[string]interface{}{
+	payload := map[string]interface{}{
 		"status":  "healthy",
 		"service": "notifier",
 		"time":    time.Now().UTC(),
-	})
+	}
+	if reporter, ok := h.service.(domain.QueueMetricsReporter); ok {
+		payload["queue"] = reporter.QueueMetrics()
+	}
+
+	if reporter, ok := h.service.(domain.HealthReporter); ok {
+		if problems := reporter.HealthProblems(); len(problems) > 0 {
+			payload["status"] = "unhealthy"
+			payload["problems"] = problems
+			respondJSON(w, http.StatusServiceUnavailable, payload)
+			return
+		}
+	}
+
+	respondJSON(w, http.StatusOK, payload)
 }
 
 // parseNotificationFilter parses query parameters into a NotificationFilter
@@ -239,6 +711,17 @@ func parseNotificationFilter(r *http.Request) *domain.NotificationFilter {
 		}
 	}
 
+	filter.Cursor = query.Get("cursor")
+	filter.IDs = query["id"]
+
+	// Parse creation time bounds
+	if after, err := time.Parse(time.RFC3339Nano, query.Get("created_after")); err == nil {
+		filter.CreatedAfter = &after
+	}
+	if before, err := time.Parse(time.RFC3339Nano, query.Get("created_before")); err == nil {
+		filter.CreatedBefore = &before
+	}
+
 	// Parse types
 	if types := query["type"]; len(types) > 0 {
 		for _, t := range types {
@@ -258,9 +741,68 @@ func parseNotificationFilter(r *http.Request) *domain.NotificationFilter {
 		filter.Recipients = recipients
 	}
 
+	// Parse origin systems and triggering users
+	if origins := query["origin"]; len(origins) > 0 {
+		filter.OriginSystems = origins
+	}
+	if users := query["origin_user"]; len(users) > 0 {
+		filter.OriginUsers = users
+	}
+
+	filter.FanoutID = query.Get("fanout_id")
+	filter.Tenant = query.Get("tenant")
+
+	// Parse tags, given as key:value
+	for _, tag := range query["tag"] {
+		if key, value, ok := strings.Cut(tag, ":"); ok && key != "" {
+			if filter.Tags == nil {
+				filter.Tags = make(map[string]string)
+			}
+			filter.Tags[key] = value
+		}
+	}
+
 	return filter
 }
 
+// sendErrorStatus returns the HTTP status for an error from queueing notifications
+func sendErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrBudgetExceeded), errors.Is(err, domain.ErrQuotaExceeded):
+		return http.StatusTooManyRequests
+	case errors.Is(err, domain.ErrContentBlocked):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrUnknownKeys), errors.Is(err, domain.ErrInvalidRecurrence), errors.Is(err, domain.ErrInvalidTargets),
+		errors.Is(err, domain.ErrTemplateNotFound), errors.Is(err, domain.ErrInvalidTemplate),
+		errors.Is(err, domain.ErrContactNotFound), errors.Is(err, domain.ErrInvalidContact):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrLimitExceeded):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrTenantAccount):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// readStaleness reports how stale the service's lookups and listings may be, or nil when
+// they're always current
+func (h *Handler) readStaleness(ctx context.Context) *domain.ReadStaleness {
+	if reporter, ok := h.service.(domain.ReadStalenessReporter); ok {
+		return reporter.ReadStaleness(ctx)
+	}
+	return nil
+}
+
+// setStalenessHeaders annotates a response that has no staleness field with its read source
+func setStalenessHeaders(w http.ResponseWriter, staleness *domain.ReadStaleness) {
+	if staleness == nil {
+		return
+	}
+	w.Header().Set("X-Read-Source", staleness.Source)
+	w.Header().Set("X-Replication-Lag", strconv.FormatFloat(staleness.LagSeconds, 'f', -1, 64))
+}
+
 // respondJSON sends a JSON response
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -277,8 +819,21 @@ func respondError(w http.ResponseWriter, status int, message string, err error)
 		errMsg = message + ": " + err.Error()
 	}
 
-	respondJSON(w, status, map[string]interface{}{
+	response := map[string]interface{}{
 		"error":   message,
 		"details": errMsg,
-	})
+	}
+	// Limit errors carry a code saying which limit was exceeded
+	var limitErr *domain.LimitError
+	if errors.As(err, &limitErr) {
+		response["code"] = limitErr.Code
+	}
+	// Quota errors say which quota was used up and when it resets
+	var quotaErr *domain.QuotaError
+	if errors.As(err, &quotaErr) {
+		response["code"] = quotaErr.Kind
+		auth.SetQuotaHeaders(w.Header(), quotaErr.Kind, &domain.QuotaCounter{Limit: quotaErr.Limit, ResetAt: quotaErr.ResetAt})
+		auth.SetRetryAfter(w.Header(), quotaErr.ResetAt, time.Now())
+	}
+	respondJSON(w, status, response)
 }
@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// PingHeartbeat handles POST /api/v1/heartbeats/{name}, recording a ping from the heartbeat's producer
+func (h *Handler) PingHeartbeat(w http.ResponseWriter, r *http.Request) {
+	monitor, ok := h.heartbeatMonitor(w)
+	if !ok {
+		return
+	}
+
+	heartbeat, err := monitor.PingHeartbeat(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		respondError(w, heartbeatErrorStatus(err), "failed to ping heartbeat", err)
+		return
+	}
+
+	h.logger.Debugf("REST: Heartbeat pinged - name=%s", heartbeat.Name)
+	respondJSON(w, http.StatusOK, heartbeat)
+}
+
+// GetHeartbeat handles GET /api/v1/heartbeats/{name}
+func (h *Handler) GetHeartbeat(w http.ResponseWriter, r *http.Request) {
+	monitor, ok := h.heartbeatMonitor(w)
+	if !ok {
+		return
+	}
+
+	heartbeat, err := monitor.GetHeartbeat(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		respondError(w, heartbeatErrorStatus(err), "failed to get heartbeat", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, heartbeat)
+}
+
+// ListHeartbeats handles GET /api/v1/heartbeats
+func (h *Handler) ListHeartbeats(w http.ResponseWriter, r *http.Request) {
+	monitor, ok := h.heartbeatMonitor(w)
+	if !ok {
+		return
+	}
+
+	heartbeats, err := monitor.ListHeartbeats(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list heartbeats", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListHeartbeatsResponse{Heartbeats: heartbeats})
+}
+
+// heartbeatMonitor returns the service's heartbeat monitor, responding 501 if it has none
+func (h *Handler) heartbeatMonitor(w http.ResponseWriter) (domain.HeartbeatMonitor, bool) {
+	monitor, ok := h.service.(domain.HeartbeatMonitor)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "heartbeats are not supported", nil)
+	}
+	return monitor, ok
+}
+
+// heartbeatErrorStatus returns the HTTP status for an error from a heartbeat operation
+func heartbeatErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrHeartbeatNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
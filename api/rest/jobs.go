@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// maxSendJobBodySize limits send job submissions, which carry their whole recipient list
+const maxSendJobBodySize = 64 << 20
+
+// SubmitSendJob handles POST /api/v1/jobs, starting a background send to a large recipient list
+func (h *Handler) SubmitSendJob(w http.ResponseWriter, r *http.Request) {
+	runner, ok := h.jobRunner(w)
+	if !ok {
+		return
+	}
+
+	var req SubmitSendJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		respondError(w, http.StatusBadRequest, "validation failed", err)
+		return
+	}
+
+	template := req.Notification.ToNotification()
+	template.DryRun = template.DryRun || isDryRun(r)
+	job, err := runner.SubmitSendJob(r.Context(), template, req.Recipients, domain.SendJobOptions{
+		RecipientsPerNotification: req.RecipientsPerNotification,
+		MaxInFlight:               req.MaxInFlight,
+	})
+	if err != nil {
+		h.logger.Errorf("REST: Failed to submit send job - type=%s, recipients=%d, error=%v",
+			template.Type, len(req.Recipients), err)
+		respondError(w, sendErrorStatus(err), "failed to submit send job", err)
+		return
+	}
+
+	respondJSON(w, http.StatusAccepted, job)
+}
+
+// ListSendJobs handles GET /api/v1/jobs
+func (h *Handler) ListSendJobs(w http.ResponseWriter, r *http.Request) {
+	runner, ok := h.jobRunner(w)
+	if !ok {
+		return
+	}
+
+	jobs, err := runner.ListSendJobs(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list send jobs", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListSendJobsResponse{Jobs: jobs})
+}
+
+// GetSendJob handles GET /api/v1/jobs/{id}, reporting a job's progress
+func (h *Handler) GetSendJob(w http.ResponseWriter, r *http.Request) {
+	runner, ok := h.jobRunner(w)
+	if !ok {
+		return
+	}
+
+	job, err := runner.GetSendJob(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, jobErrorStatus(err), "failed to get send job", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, job)
+}
+
+// PauseSendJob handles POST /api/v1/jobs/{id}/pause
+func (h *Handler) PauseSendJob(w http.ResponseWriter, r *http.Request) {
+	h.controlSendJob(w, r, "paused", domain.SendJobRunner.PauseSendJob)
+}
+
+// ResumeSendJob handles POST /api/v1/jobs/{id}/resume
+func (h *Handler) ResumeSendJob(w http.ResponseWriter, r *http.Request) {
+	h.controlSendJob(w, r, "resumed", domain.SendJobRunner.ResumeSendJob)
+}
+
+// CancelSendJob handles POST /api/v1/jobs/{id}/cancel
+func (h *Handler) CancelSendJob(w http.ResponseWriter, r *http.Request) {
+	h.controlSendJob(w, r, "cancelled", domain.SendJobRunner.CancelSendJob)
+}
+
+// controlSendJob applies a pause, resume or cancel and responds with the job's progress
+func (h *Handler) controlSendJob(w http.ResponseWriter, r *http.Request, action string, control func(domain.SendJobRunner, context.Context, string) error) {
+	runner, ok := h.jobRunner(w)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := control(runner, r.Context(), id); err != nil {
+		respondError(w, jobErrorStatus(err), "failed to update send job", err)
+		return
+	}
+
+	job, err := runner.GetSendJob(r.Context(), id)
+	if err != nil {
+		respondError(w, jobErrorStatus(err), "failed to get send job", err)
+		return
+	}
+
+	h.logger.Infof("REST: Send job %s - id=%s", action, id)
+	respondJSON(w, http.StatusOK, job)
+}
+
+// jobRunner returns the service's send job runner, responding 501 if it has none
+func (h *Handler) jobRunner(w http.ResponseWriter) (domain.SendJobRunner, bool) {
+	runner, ok := h.service.(domain.SendJobRunner)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "send jobs are not supported", nil)
+	}
+	return runner, ok
+}
+
+// jobErrorStatus returns the HTTP status for an error from a send job operation
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrJobFinished):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
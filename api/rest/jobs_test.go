@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestSubmitSendJob tests that send jobs accept recipient lists beyond the normal body limit
+func TestSubmitSendJob(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	router := NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logging.New(logging.ErrorLevel, os.Stderr)), logging.New(logging.ErrorLevel, os.Stderr))
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// About 2 MB of recipients
+	recipients := `"user@example.com"` + strings.Repeat(`,"user@example.com"`, 100000)
+	rec := post("/api/v1/notifications", `{"type":"stdout","body":"hi","recipients":[`+recipients+`]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected an oversized send to be rejected, got %d", rec.Code)
+	}
+
+	rec = post("/api/v1/jobs", `{"notification":{"type":"stdout","body":"hi"},"recipients":[`+recipients+`],"max_in_flight":1}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Submit returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var job domain.SendJob
+	if err := json.NewDecoder(rec.Body).Decode(&job); err != nil {
+		t.Fatalf("Failed to decode job: %v", err)
+	}
+	if job.Total != 100001 || job.Status != domain.JobRunning {
+		t.Errorf("Unexpected job: %+v", job)
+	}
+
+	if rec = post("/api/v1/jobs/"+job.ID+"/cancel", ""); rec.Code != http.StatusOK {
+		t.Errorf("Cancel returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec = post("/api/v1/jobs/"+job.ID+"/resume", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected resuming a cancelled job to conflict, got %d", rec.Code)
+	}
+	if rec = post("/api/v1/jobs/missing/pause", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing job, got %d", rec.Code)
+	}
+	if rec = post("/api/v1/jobs", `{"notification":{"type":"stdout","body":"hi","recipients":["a"]},"recipients":["b"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected template recipients to be rejected, got %d", rec.Code)
+	}
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -48,7 +49,8 @@ type CreateKeyResponse struct {
 
 // ListKeysResponse is the response body for listing API keys
 type ListKeysResponse struct {
-	Keys []*KeyInfo `json:"keys"`
+	Keys      []*KeyInfo          `json:"keys"`
+	Staleness *auth.ReadStaleness `json:"staleness,omitempty"`
 }
 
 // KeyInfo contains metadata about an API key (without the key itself)
@@ -185,7 +187,7 @@ func (h *KeyManagementHandler) ListKeys(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	h.respondJSON(w, http.StatusOK, ListKeysResponse{Keys: keyInfos})
+	h.respondJSON(w, http.StatusOK, ListKeysResponse{Keys: keyInfos, Staleness: h.readStaleness(ctx)})
 }
 
 // RevokeKeyRequest is the request body for revoking a key
@@ -262,8 +264,9 @@ func (h *KeyManagementHandler) RotateKey(w http.ResponseWriter, r *http.Request)
 
 // GetAuditLogResponse is the response for audit log
 type GetAuditLogResponse struct {
-	Key      string                   `json:"key_preview"`
-	AuditLog []map[string]interface{} `json:"audit_log"`
+	Key       string                   `json:"key_preview"`
+	AuditLog  []map[string]interface{} `json:"audit_log"`
+	Staleness *auth.ReadStaleness      `json:"staleness,omitempty"`
 }
 
 // GetAuditLog retrieves the audit log for a key
@@ -296,8 +299,9 @@ func (h *KeyManagementHandler) GetAuditLog(w http.ResponseWriter, r *http.Reques
 	}
 
 	resp := GetAuditLogResponse{
-		Key:      keyName,
-		AuditLog: logs,
+		Key:       keyName,
+		AuditLog:  logs,
+		Staleness: h.readStaleness(ctx),
 	}
 
 	h.respondJSON(w, http.StatusOK, resp)
@@ -305,6 +309,16 @@ func (h *KeyManagementHandler) GetAuditLog(w http.ResponseWriter, r *http.Reques
 
 // Helper methods
 
+// readStaleness annotates listings with their read source; omitted if the lag can't be determined
+func (h *KeyManagementHandler) readStaleness(ctx context.Context) *auth.ReadStaleness {
+	staleness, err := h.keyStore.ReadStaleness(ctx)
+	if err != nil {
+		h.logger.Warnf("Failed to determine read staleness: %v", err)
+		return nil
+	}
+	return staleness
+}
+
 // hasRole checks if the auth context has a specific role
 func (h *KeyManagementHandler) hasRole(authCtx *auth.AuthContext, role string) bool {
 	for _, r := range authCtx.Roles {
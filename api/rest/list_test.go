@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestListNotificationsPages tests that listing walks every notification newest first with
+// cursors, reporting the full total on each page
+func TestListNotificationsPages(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	router := NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logger), logger)
+
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications",
+			strings.NewReader(`{"type":"stdout","body":"hello","recipients":["console"]}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	var listed []Notification
+	url := "/api/v1/notifications?limit=2"
+	for pages := 1; ; pages++ {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("List returned %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp ListNotificationsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode list response: %v", err)
+		}
+		if resp.Total != 5 {
+			t.Errorf("Page %d total = %d, want 5", pages, resp.Total)
+		}
+		listed = append(listed, resp.Notifications...)
+		if resp.NextCursor == "" {
+			if pages != 3 {
+				t.Errorf("Expected 3 pages, got %d", pages)
+			}
+			break
+		}
+		if pages == 3 {
+			t.Fatal("Expected no cursor after the last page")
+		}
+		url = "/api/v1/notifications?limit=2&cursor=" + resp.NextCursor
+	}
+
+	seen := make(map[string]bool)
+	for i, notification := range listed {
+		if seen[notification.ID] {
+			t.Errorf("Notification %s listed twice", notification.ID)
+		}
+		seen[notification.ID] = true
+		if i > 0 && notification.CreatedAt.After(listed[i-1].CreatedAt) {
+			t.Errorf("Expected newest first, but %s is newer than %s", notification.ID, listed[i-1].ID)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("Expected all 5 notifications across pages, got %d", len(seen))
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications?cursor=bogus", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid cursor, got %d", rec.Code)
+	}
+}
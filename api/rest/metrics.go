@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// MetricsHandler serves queue metrics, retention metrics when the reporter prunes its
+// notification store, and delivery and SLO metrics when it tracks them, in the Prometheus
+// text exposition format or as JSON
+type MetricsHandler struct {
+	reporter   domain.QueueMetricsReporter
+	prometheus bool
+}
+
+// NewMetricsHandler creates a metrics handler. Without prometheus the metrics are served as JSON.
+func NewMetricsHandler(reporter domain.QueueMetricsReporter, prometheus bool) *MetricsHandler {
+	return &MetricsHandler{reporter: reporter, prometheus: prometheus}
+}
+
+// ServeHTTP handles GET on the metrics path
+func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		respondError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+
+	metrics := h.reporter.QueueMetrics()
+	retention, hasRetention := h.reporter.(domain.RetentionMetricsReporter)
+	delivery, hasDelivery := h.reporter.(domain.DeliveryMetricsReporter)
+	var slos []domain.SLOStatus
+	if reporter, ok := h.reporter.(domain.SLOMetricsReporter); ok {
+		slos = reporter.SLOMetrics()
+	}
+	if !h.prometheus {
+		body := map[string]interface{}{"queue": metrics}
+		if hasRetention {
+			body["retention"] = retention.RetentionMetrics()
+		}
+		if hasDelivery {
+			body["delivery"] = delivery.DeliveryMetrics()
+		}
+		if len(slos) > 0 {
+			body["slos"] = slos
+		}
+		respondJSON(w, http.StatusOK, body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, formatQueueMetrics(metrics))
+	if hasRetention {
+		fmt.Fprint(w, formatRetentionMetrics(retention.RetentionMetrics()))
+	}
+	if hasDelivery {
+		fmt.Fprint(w, formatDeliveryMetrics(delivery.DeliveryMetrics()))
+	}
+	if len(slos) > 0 {
+		fmt.Fprint(w, formatSLOMetrics(slos))
+	}
+}
+
+// labelReplacer escapes a Prometheus label value
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatQueueMetrics renders queue metrics in the Prometheus text exposition format
+func formatQueueMetrics(m domain.QueueMetrics) string {
+	backend := m.Backend
+	if backend == "" {
+		backend = "unknown"
+	}
+	labels := fmt.Sprintf(`{backend="%s"}`, labelReplacer.Replace(backend))
+
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, kind, help string
+		value            interface{}
+	}{
+		{"notifier_queue_depth", "gauge", "Messages waiting to be dequeued.", m.Depth},
+		{"notifier_queue_in_flight", "gauge", "Messages dequeued but not yet acked or nacked.", m.InFlight},
+		{"notifier_queue_delayed", "gauge", "Messages waiting for their delivery time.", m.Delayed},
+		{"notifier_queue_oldest_message_age_seconds", "gauge", "Age of the oldest unacknowledged message.", m.OldestAgeSeconds},
+		{"notifier_queue_enqueued_total", "counter", "Messages added to the queue.", m.Enqueued},
+		{"notifier_queue_acked_total", "counter", "Messages acknowledged.", m.Acked},
+		{"notifier_queue_nacked_total", "counter", "Messages negatively acknowledged, requeued or not.", m.Nacked},
+		{"notifier_queue_requeued_total", "counter", "Nacked messages that were requeued.", m.Requeued},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s%s %v\n", metric.name, metric.help, metric.name, metric.kind, metric.name, labels, metric.value)
+	}
+	return b.String()
+}
+
+// formatRetentionMetrics renders retention metrics in the Prometheus text exposition format
+func formatRetentionMetrics(m domain.RetentionMetrics) string {
+	var lastRun float64
+	if !m.LastRunAt.IsZero() {
+		lastRun = float64(m.LastRunAt.UnixMilli()) / 1000
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# HELP notifier_notifications_stored Notifications in the store after the last retention run.\n# TYPE notifier_notifications_stored gauge\nnotifier_notifications_stored %d\n", m.Stored)
+	fmt.Fprintf(&b, "# HELP notifier_retention_runs_total Retention cleanup runs.\n# TYPE notifier_retention_runs_total counter\nnotifier_retention_runs_total %d\n", m.Runs)
+	fmt.Fprintf(&b, "# HELP notifier_retention_failures_total Retention cleanup runs that failed.\n# TYPE notifier_retention_failures_total counter\nnotifier_retention_failures_total %d\n", m.Failures)
+	fmt.Fprintf(&b, "# HELP notifier_retention_pruned_total Sent or failed notifications removed by retention cleanup.\n# TYPE notifier_retention_pruned_total counter\n")
+	fmt.Fprintf(&b, "notifier_retention_pruned_total{reason=\"expired\"} %d\nnotifier_retention_pruned_total{reason=\"max_size\"} %d\n", m.PrunedExpired, m.PrunedExcess)
+	fmt.Fprintf(&b, "# HELP notifier_retention_last_run_timestamp_seconds When retention cleanup last ran.\n# TYPE notifier_retention_last_run_timestamp_seconds gauge\nnotifier_retention_last_run_timestamp_seconds %v\n", lastRun)
+	return b.String()
+}
+
+// formatDeliveryMetrics renders delivery metrics in the Prometheus text exposition format
+func formatDeliveryMetrics(m domain.DeliveryStats) string {
+	var b strings.Builder
+	// A summary: the quantiles cover recent deliveries, the sum and count all of them
+	fmt.Fprintf(&b, "# HELP notifier_delivery_latency_seconds Time from when a notification was due until it was delivered.\n# TYPE notifier_delivery_latency_seconds summary\n")
+	fmt.Fprintf(&b, "notifier_delivery_latency_seconds{quantile=\"0.5\"} %v\nnotifier_delivery_latency_seconds{quantile=\"0.95\"} %v\n", m.LatencyP50Ms/1000, m.LatencyP95Ms/1000)
+	fmt.Fprintf(&b, "notifier_delivery_latency_seconds_sum %v\nnotifier_delivery_latency_seconds_count %d\n", m.LatencySumMs/1000, m.Delivered)
+	fmt.Fprintf(&b, "# HELP notifier_deliveries_total Notifications that reached a final outcome.\n# TYPE notifier_deliveries_total counter\n")
+	fmt.Fprintf(&b, "notifier_deliveries_total{outcome=\"delivered\"} %d\nnotifier_deliveries_total{outcome=\"failed\"} %d\n", m.Delivered, m.Failed)
+	fmt.Fprintf(&b, "# HELP notifier_deadlines_total Notifications with a deadline, by whether they were delivered by it.\n# TYPE notifier_deadlines_total counter\n")
+	fmt.Fprintf(&b, "notifier_deadlines_total{outcome=\"met\"} %d\nnotifier_deadlines_total{outcome=\"missed\"} %d\n", m.DeadlineMet, m.DeadlineMissed)
+	return b.String()
+}
+
+// formatSLOMetrics renders the compliance of each SLO over its rolling window in the
+// Prometheus text exposition format
+func formatSLOMetrics(statuses []domain.SLOStatus) string {
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, help string
+		value      func(domain.SLOStatus) float64
+	}{
+		{"notifier_slo_compliance", "Share of notifications delivered within the SLO threshold over its window.", func(s domain.SLOStatus) float64 { return s.Compliance }},
+		{"notifier_slo_target", "Compliance the SLO requires.", func(s domain.SLOStatus) float64 { return s.Target }},
+		{"notifier_slo_breached", "Whether the SLO is breached (1) or not (0).", func(s domain.SLOStatus) float64 {
+			if s.Breached {
+				return 1
+			}
+			return 0
+		}},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name)
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "%s{slo=\"%s\"} %v\n", metric.name, labelReplacer.Replace(status.Name), metric.value(status))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestQueueMetricsEndpoints tests queue metrics in the Prometheus endpoint and the health payload
+func TestQueueMetricsEndpoints(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	router := NewRouter(svc, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications",
+		strings.NewReader(`{"type":"stdout","body":"hello","recipients":["console"]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	NewMetricsHandler(svc, true).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("Metrics returned %d (%s)", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, line := range []string{
+		"# TYPE notifier_queue_depth gauge",
+		`notifier_queue_depth{backend="local"} 1`,
+		`notifier_queue_enqueued_total{backend="local"} 1`,
+		`notifier_queue_requeued_total{backend="local"} 0`,
+		"# TYPE notifier_retention_runs_total counter",
+		`notifier_retention_pruned_total{reason="expired"} 0`,
+		"# TYPE notifier_delivery_latency_seconds summary",
+		`notifier_delivery_latency_seconds{quantile="0.95"} 0`,
+		"notifier_delivery_latency_seconds_count 0",
+		`notifier_deliveries_total{outcome="delivered"} 0`,
+		`notifier_deadlines_total{outcome="missed"} 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("Metrics missing %q:\n%s", line, rec.Body.String())
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	var health struct {
+		Status string              `json:"status"`
+		Queue  domain.QueueMetrics `json:"queue"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&health); err != nil {
+		t.Fatalf("Failed to decode health: %v", err)
+	}
+	if health.Status != "healthy" || health.Queue.Depth != 1 || health.Queue.Backend != "local" {
+		t.Errorf("Unexpected health payload: %+v", health)
+	}
+}
+
+// TestSLOMetrics tests that SLO compliance, target and breach state are exported per SLO
+func TestSLOMetrics(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+
+	rec := httptest.NewRecorder()
+	NewMetricsHandler(svc, true).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(rec.Body.String(), "notifier_slo_") {
+		t.Errorf("SLO metrics exported while tracking is disabled:\n%s", rec.Body.String())
+	}
+
+	if err := svc.WithSLOConfig(config.SLOConfig{
+		Enabled:        true,
+		Window:         "1h",
+		CheckFrequency: "1m",
+		Objectives: []config.SLOObjectiveConfig{
+			{Name: "critical-fast", Priority: "critical", Target: 0.99, Threshold: "60s"},
+		},
+	}); err != nil {
+		t.Fatalf("WithSLOConfig() error = %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	NewMetricsHandler(svc, true).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, line := range []string{
+		"# TYPE notifier_slo_compliance gauge",
+		`notifier_slo_compliance{slo="critical-fast"} 1`,
+		`notifier_slo_target{slo="critical-fast"} 0.99`,
+		`notifier_slo_breached{slo="critical-fast"} 0`,
+	} {
+		if !strings.Contains(rec.Body.String(), line+"\n") {
+			t.Errorf("Metrics missing %q:\n%s", line, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// DryRunHeader marks a send request as a dry run: notifications are processed and validated
+// but not delivered. Mirrored requests carry it so a shadow deployment never sends.
+const DryRunHeader = "X-Notifier-Dry-Run"
+
+// isDryRun reports whether a request asks for a dry run
+func isDryRun(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get(DryRunHeader), "true")
+}
+
+// MirrorConfig configures mirroring of send requests to a shadow deployment
+type MirrorConfig struct {
+	// URL is the base URL of the shadow deployment (e.g., "http://notifier-canary:8080")
+	URL string
+
+	// Percent is the share of send requests mirrored, from 0 to 100
+	Percent float64
+
+	// APIKey authenticates mirrored requests with the shadow. If empty, the caller's
+	// Authorization and X-API-Key headers are forwarded.
+	APIKey string
+
+	// Timeout bounds each mirrored request
+	Timeout time.Duration
+
+	// MaxInFlight caps concurrent mirrored requests; requests beyond it are not mirrored
+	MaxInFlight int
+}
+
+// mirror duplicates sampled send requests to a shadow deployment as dry runs. Mirroring is
+// best-effort and never delays or changes the response to the caller.
+type mirror struct {
+	config     MirrorConfig
+	httpClient *http.Client
+	inFlight   chan struct{}
+	logger     *logging.Logger
+	sample     func() bool
+}
+
+// newMirror creates a mirror for the configured shadow
+func newMirror(config MirrorConfig, logger *logging.Logger) *mirror {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxInFlight <= 0 {
+		config.MaxInFlight = 100
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	return &mirror{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		inFlight:   make(chan struct{}, config.MaxInFlight),
+		logger:     logger,
+		sample:     func() bool { return rand.Float64()*100 < config.Percent },
+	}
+}
+
+// wrap mirrors a sample of the requests served by next, once next has responded
+func (m *mirror) wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Requests that are already dry runs may themselves be mirrored traffic
+		if isDryRun(r) || !m.sample() {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		select {
+		case m.inFlight <- struct{}{}:
+		default:
+			m.logger.Debugf("Mirror: too many shadow requests in flight, skipping - uri=%s", r.URL.RequestURI())
+			return
+		}
+		header := m.shadowHeader(r)
+		go func() {
+			defer func() { <-m.inFlight }()
+			m.send(r.Method, r.URL.RequestURI(), header, body, recorder.status)
+		}()
+	}
+}
+
+// shadowHeader builds the headers for a mirrored request
+func (m *mirror) shadowHeader(r *http.Request) http.Header {
+	header := http.Header{}
+	header.Set("Content-Type", r.Header.Get("Content-Type"))
+	header.Set(DryRunHeader, "true")
+	if m.config.APIKey != "" {
+		header.Set("Authorization", "Bearer "+m.config.APIKey)
+	} else {
+		for _, name := range []string{"Authorization", "X-API-Key"} {
+			if value := r.Header.Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+	}
+	return header
+}
+
+// send replays a request to the shadow and logs when its response differs from the primary's
+func (m *mirror) send(method, uri string, header http.Header, body []byte, primaryStatus int) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, m.config.URL+uri, bytes.NewReader(body))
+	if err != nil {
+		m.logger.Warnf("Mirror: failed to create shadow request - error=%v", err)
+		return
+	}
+	req.Header = header
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		m.logger.Warnf("Mirror: shadow request failed - uri=%s, error=%v", uri, err)
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != primaryStatus {
+		m.logger.Warnf("Mirror: shadow response differs - uri=%s, primary_status=%d, shadow_status=%d",
+			uri, primaryStatus, resp.StatusCode)
+		return
+	}
+	m.logger.Debugf("Mirror: shadow response matches - uri=%s, status=%d", uri, resp.StatusCode)
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status before writing it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
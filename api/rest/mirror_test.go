@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestMirrorToShadow tests that sampled send requests are replayed to a shadow as dry runs
+func TestMirrorToShadow(t *testing.T) {
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	newService := func() domain.NotificationService {
+		factory := notifier.NewFactory()
+		if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+			t.Fatalf("Failed to register notifier: %v", err)
+		}
+		q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+		if err != nil {
+			t.Fatalf("Failed to create queue: %v", err)
+		}
+		return service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	}
+
+	shadowService := newService()
+	shadowRouter := NewRouter(shadowService, logger)
+	mirrored := make(chan *http.Request, 10)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shadowRouter.ServeHTTP(w, r)
+		mirrored <- r
+	}))
+	defer shadow.Close()
+
+	primaryService := newService()
+	router := NewRouterWithOptions(primaryService, logger, RouterOptions{
+		Mirror: &MirrorConfig{URL: shadow.URL + "/", Percent: 100, APIKey: "shadow-key"},
+	})
+
+	send := func(path, body string, dryRun bool) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer production-key")
+		if dryRun {
+			req.Header.Set(DryRunHeader, "true")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	send("/api/v1/notifications", `{"type":"stdout","body":"hello","recipients":["console"]}`, false)
+	select {
+	case r := <-mirrored:
+		if r.URL.Path != "/api/v1/notifications" || !isDryRun(r) || r.Header.Get("Authorization") != "Bearer shadow-key" {
+			t.Errorf("Unexpected mirrored request: %s %v", r.URL.Path, r.Header)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Request was not mirrored")
+	}
+
+	page, _ := shadowService.ListNotifications(context.Background(), &domain.NotificationFilter{})
+	notifications := page.Notifications
+	if len(notifications) != 1 || !notifications[0].DryRun {
+		t.Fatalf("Expected one dry-run notification on the shadow, got %+v", notifications)
+	}
+	page, _ = primaryService.ListNotifications(context.Background(), &domain.NotificationFilter{})
+	notifications = page.Notifications
+	if len(notifications) != 1 || notifications[0].DryRun {
+		t.Fatalf("Expected one real notification on the primary, got %+v", notifications)
+	}
+
+	// Dry runs are honored locally but not mirrored again
+	send("/api/v1/notifications/batch", `{"notifications":[{"type":"stdout","body":"hi","recipients":["console"]}]}`, true)
+	select {
+	case r := <-mirrored:
+		t.Errorf("Expected dry run not to be mirrored, got %s", r.URL.Path)
+	case <-time.After(100 * time.Millisecond):
+	}
+	page, _ = primaryService.ListNotifications(context.Background(), &domain.NotificationFilter{})
+	notifications = page.Notifications
+	dryRuns := 0
+	for _, notification := range notifications {
+		if notification.DryRun {
+			dryRuns++
+		}
+	}
+	if dryRuns != 1 {
+		t.Errorf("Expected the batch to be a dry run on the primary, got %d dry runs", dryRuns)
+	}
+}
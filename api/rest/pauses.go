@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// PauseDispatch handles POST /api/v1/dispatch/pause, the kill-switch that stops sending
+// notifications for every notifier, one type or one account. Queued notifications are kept.
+func (h *Handler) PauseDispatch(w http.ResponseWriter, r *http.Request) {
+	var req DispatchPauseRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	h.pauseDispatch(w, r, req)
+}
+
+// ResumeDispatch handles POST /api/v1/dispatch/resume, lifting a pause with the same type and account
+func (h *Handler) ResumeDispatch(w http.ResponseWriter, r *http.Request) {
+	var req DispatchPauseRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	h.resumeDispatch(w, r, req)
+}
+
+// PauseNotifier handles POST /api/v1/notifiers/{type}/pause and
+// /api/v1/notifiers/{type}/{account}/pause, parking one channel's or account's traffic.
+// The body may give a reason.
+func (h *Handler) PauseNotifier(w http.ResponseWriter, r *http.Request) {
+	var req DispatchPauseRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	vars := mux.Vars(r)
+	req.Type, req.Account = vars["type"], vars["account"]
+	h.pauseDispatch(w, r, req)
+}
+
+// ResumeNotifier handles POST /api/v1/notifiers/{type}/resume and
+// /api/v1/notifiers/{type}/{account}/resume
+func (h *Handler) ResumeNotifier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	h.resumeDispatch(w, r, DispatchPauseRequest{Type: vars["type"], Account: vars["account"]})
+}
+
+// pauseDispatch pauses the scope of a request
+func (h *Handler) pauseDispatch(w http.ResponseWriter, r *http.Request, req DispatchPauseRequest) {
+	controller, operator, ok := h.authorizeDispatchControl(w, r)
+	if !ok {
+		return
+	}
+
+	pause, err := controller.PauseDispatch(r.Context(), domain.DispatchPause{
+		Type:     domain.NotificationType(req.Type),
+		Account:  req.Account,
+		Reason:   req.Reason,
+		PausedBy: operator,
+	})
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "failed to pause dispatch", err)
+		return
+	}
+
+	h.logger.Infof("REST: Dispatch paused - type=%s, account=%s, by=%s", req.Type, req.Account, operator)
+	respondJSON(w, http.StatusOK, pause)
+}
+
+// resumeDispatch lifts the pause with the scope of a request
+func (h *Handler) resumeDispatch(w http.ResponseWriter, r *http.Request, req DispatchPauseRequest) {
+	controller, operator, ok := h.authorizeDispatchControl(w, r)
+	if !ok {
+		return
+	}
+
+	if err := controller.ResumeDispatch(r.Context(), domain.NotificationType(req.Type), req.Account); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrNotPaused) {
+			status = http.StatusNotFound
+		}
+		respondError(w, status, "failed to resume dispatch", err)
+		return
+	}
+
+	h.logger.Infof("REST: Dispatch resumed - type=%s, account=%s, by=%s", req.Type, req.Account, operator)
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "dispatch resumed",
+	})
+}
+
+// ListDispatchPauses handles GET /api/v1/dispatch/pauses
+func (h *Handler) ListDispatchPauses(w http.ResponseWriter, r *http.Request) {
+	controller, ok := h.service.(domain.DispatchController)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "dispatch pausing is not supported", nil)
+		return
+	}
+
+	pauses, err := controller.ListDispatchPauses(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list dispatch pauses", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListDispatchPausesResponse{Paused: len(pauses) > 0, Pauses: pauses})
+}
+
+// authorizeDispatchControl checks the service supports pausing and, when authentication is
+// enabled, that the caller has the admin role. It returns the controller and the operator's client ID.
+func (h *Handler) authorizeDispatchControl(w http.ResponseWriter, r *http.Request) (domain.DispatchController, string, bool) {
+	controller, ok := h.service.(domain.DispatchController)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "dispatch pausing is not supported", nil)
+		return nil, "", false
+	}
+
+	operator, ok := authorizeOperator(w, r)
+	return controller, operator, ok
+}
+
+// authorizeOperator checks, when authentication is enabled, that the caller has the admin role.
+// It returns the operator's client ID.
+func authorizeOperator(w http.ResponseWriter, r *http.Request) (string, bool) {
+	operator := "anonymous"
+	if authCtx, ok := auth.GetAuthContext(r.Context()); ok {
+		if !hasRole(authCtx, "admin") {
+			respondError(w, http.StatusForbidden, "admin role required", nil)
+			return "", false
+		}
+		operator = authCtx.ClientID
+	}
+	return operator, true
+}
+
+// authorizeTenantOperator is authorizeOperator for resources kept per tenant, such as templates
+// and contacts: a tenant's clients may also change their own tenant's
+func authorizeTenantOperator(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if authCtx, ok := auth.GetAuthContext(r.Context()); ok && authCtx.Tenant != "" {
+		return authCtx.ClientID, true
+	}
+	return authorizeOperator(w, r)
+}
+
+// decodeOptionalBody decodes a JSON request body, leaving v unchanged when the body is empty
+func decodeOptionalBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// GetPreferences handles GET /api/v1/contacts/{name}/preferences
+func (h *Handler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.PreferenceManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "preferences are not supported", nil)
+		return
+	}
+
+	prefs, err := manager.GetPreferences(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		respondError(w, preferencesErrorStatus(err), "failed to get preferences", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, prefs)
+}
+
+// SetPreferences handles PUT /api/v1/contacts/{name}/preferences, replacing how the contact
+// wants to be reached. Unlike the contact itself it doesn't need the admin role to change its
+// own, so users can manage their own preferences.
+func (h *Handler) SetPreferences(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.PreferenceManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "preferences are not supported", nil)
+		return
+	}
+	if !authorizePreferences(w, r, mux.Vars(r)["name"]) {
+		return
+	}
+
+	var req PreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	prefs, err := manager.SetPreferences(r.Context(), req.preferences(mux.Vars(r)["name"]))
+	if err != nil {
+		respondError(w, preferencesErrorStatus(err), "failed to set preferences", err)
+		return
+	}
+
+	h.logger.Infof("REST: Preferences set - contact=%s", prefs.Contact)
+	respondJSON(w, http.StatusOK, prefs)
+}
+
+// DeletePreferences handles DELETE /api/v1/contacts/{name}/preferences
+func (h *Handler) DeletePreferences(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.PreferenceManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "preferences are not supported", nil)
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if !authorizePreferences(w, r, name) {
+		return
+	}
+	if err := manager.DeletePreferences(r.Context(), name); err != nil {
+		respondError(w, preferencesErrorStatus(err), "failed to delete preferences", err)
+		return
+	}
+
+	h.logger.Infof("REST: Preferences deleted - contact=%s", name)
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "preferences deleted",
+	})
+}
+
+// authorizePreferences checks, when authentication is enabled, that the caller may change a
+// contact's preferences: its own, where the contact is named after the caller's client ID, or
+// anyone's with the admin role
+func authorizePreferences(w http.ResponseWriter, r *http.Request, contact string) bool {
+	if authCtx, ok := auth.GetAuthContext(r.Context()); ok && authCtx.ClientID != contact && !hasRole(authCtx, "admin") {
+		respondError(w, http.StatusForbidden, "admin role required to change another contact's preferences", nil)
+		return false
+	}
+	return true
+}
+
+// preferencesErrorStatus returns the HTTP status for an error from managing preferences
+func preferencesErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPreferencesNotFound), errors.Is(err, domain.ErrContactNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidPreferences):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
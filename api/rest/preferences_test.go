@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestPreferencesAuthorization tests that a client can change its own contact's preferences
+// but needs the admin role to change anyone else's
+func TestPreferencesAuthorization(t *testing.T) {
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeEmail, "", notifier.NewStdoutNotifier())
+	q, _ := queue.NewLocalQueue(nil)
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	router := NewRouter(svc, logger)
+
+	ctx := context.Background()
+	for _, name := range []string{"alice", "bob"} {
+		contact := domain.Contact{Name: name, Addresses: map[domain.NotificationType][]string{domain.TypeEmail: {name + "@example.com"}}}
+		if _, err := svc.CreateContact(ctx, contact); err != nil {
+			t.Fatalf("CreateContact() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		caller  *auth.AuthContext
+		method  string
+		contact string
+		want    int
+	}{
+		{"own preferences", &auth.AuthContext{ClientID: "alice"}, http.MethodPut, "alice", http.StatusOK},
+		{"another contact's preferences", &auth.AuthContext{ClientID: "alice"}, http.MethodPut, "bob", http.StatusForbidden},
+		{"another contact's preferences as admin", &auth.AuthContext{ClientID: "ops", Roles: []string{"admin"}}, http.MethodPut, "bob", http.StatusOK},
+		{"delete another contact's preferences", &auth.AuthContext{ClientID: "alice"}, http.MethodDelete, "bob", http.StatusForbidden},
+		{"delete own preferences", &auth.AuthContext{ClientID: "alice"}, http.MethodDelete, "alice", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/v1/contacts/"+tt.contact+"/preferences", strings.NewReader(`{"channels":["email"]}`))
+			req = req.WithContext(auth.ContextWithAuth(req.Context(), tt.caller))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("%s returned %d, want %d: %s", tt.method, rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
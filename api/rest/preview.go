@@ -0,0 +1,256 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/preview"
+)
+
+// PreviewConfig configures signed share links that show a notification as its recipient saw it
+type PreviewConfig struct {
+	// Secret signs link tokens. Changing it invalidates every outstanding link.
+	Secret []byte
+
+	// TTL is the lifetime of a link when the caller doesn't ask for one
+	TTL time.Duration
+
+	// MaxTTL is the longest lifetime a caller may ask for
+	MaxTTL time.Duration
+
+	// BaseURL is the public URL links point at (e.g., "https://notifier.example.com").
+	// If empty, links use the host of the request that created them.
+	BaseURL string
+}
+
+// previewHandler creates share links and serves the pages behind them
+type previewHandler struct {
+	service domain.NotificationService
+	config  PreviewConfig
+	signer  *preview.Signer
+	logger  *logging.Logger
+	now     func() time.Time
+}
+
+// newPreviewHandler creates a preview handler
+func newPreviewHandler(service domain.NotificationService, config PreviewConfig, logger *logging.Logger) *previewHandler {
+	if config.TTL <= 0 {
+		config.TTL = time.Hour
+	}
+	if config.MaxTTL < config.TTL {
+		config.MaxTTL = config.TTL
+	}
+	return &previewHandler{service: service, config: config, signer: preview.NewSigner(config.Secret), logger: logger, now: time.Now}
+}
+
+// CreateLink handles POST /api/v1/notifications/{id}/preview-link, returning a signed URL that
+// shows the notification in a browser without credentials until it expires. The body may ask
+// for a lifetime with "ttl" (e.g., "30m"), up to the configured maximum.
+func (p *previewHandler) CreateLink(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req PreviewLinkRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	ttl := p.config.TTL
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed <= 0 {
+			respondError(w, http.StatusBadRequest, "invalid ttl", err)
+			return
+		}
+		if parsed > p.config.MaxTTL {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("ttl exceeds the maximum of %s", p.config.MaxTTL), nil)
+			return
+		}
+		ttl = parsed
+	}
+
+	if _, err := p.service.GetNotification(r.Context(), id); err != nil {
+		respondError(w, http.StatusNotFound, "notification not found", err)
+		return
+	}
+
+	expiresAt := p.now().Add(ttl).Truncate(time.Second)
+	baseURL := p.config.BaseURL
+	if baseURL == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		baseURL = scheme + "://" + r.Host
+	}
+
+	p.logger.Infof("REST: Preview link created - id=%s, expires_at=%s", id, expiresAt.Format(time.RFC3339))
+	respondJSON(w, http.StatusCreated, PreviewLinkResponse{
+		URL:       strings.TrimSuffix(baseURL, "/") + "/preview/" + p.signer.Sign(preview.PurposePreview, id, expiresAt),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// ServePage handles GET /preview/{token}, rendering the notification the token was issued for
+func (p *previewHandler) ServePage(w http.ResponseWriter, r *http.Request) {
+	setPrivatePageHeaders(w)
+
+	id, expiresAt, err := p.signer.Verify(preview.PurposePreview, mux.Vars(r)["token"])
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, preview.ErrExpired) {
+			status = http.StatusGone
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	notification, err := p.service.GetNotification(r.Context(), id)
+	if err != nil {
+		http.Error(w, "notification is no longer available", http.StatusNotFound)
+		return
+	}
+
+	// Sandbox the message: no scripts, forms or plugins, and only remote images and inline styles
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'; frame-src 'self'; frame-ancestors 'none'")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := previewTemplate.Execute(w, previewPage{Notification: notification, ExpiresAt: expiresAt}); err != nil {
+		p.logger.Errorf("REST: Failed to render preview - id=%s, error=%v", id, err)
+	}
+}
+
+// ServeWebCopy handles GET /view/{token}, serving the hosted web copy of an HTML email that
+// the "View in browser" link in the message points at
+func (p *previewHandler) ServeWebCopy(w http.ResponseWriter, r *http.Request) {
+	setPrivatePageHeaders(w)
+
+	id, _, err := p.signer.Verify(preview.PurposeWebCopy, mux.Vars(r)["token"])
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, preview.ErrExpired) {
+			status = http.StatusGone
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	// The copy goes when the notification is cleaned up by retention
+	notification, err := p.service.GetNotification(r.Context(), id)
+	if err != nil {
+		http.Error(w, "message is no longer available", http.StatusGone)
+		return
+	}
+	body := previewPage{Notification: notification}.HTML()
+	if body == "" {
+		http.Error(w, "message has no web copy", http.StatusNotFound)
+		return
+	}
+
+	// The message is served as its own document, so sandbox it rather than frame it
+	w.Header().Set("Content-Security-Policy", "sandbox; default-src 'none'; img-src https: data:; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := io.WriteString(w, preview.StripWebCopyLink(body)); err != nil {
+		p.logger.Debugf("REST: Failed to write web copy - id=%s, error=%v", id, err)
+	}
+}
+
+// Acknowledge handles POST /actions/{token}, the acknowledge button added to ntfy
+// notifications. It acknowledges the notification the token was issued for and cancels its
+// pending escalations.
+func (p *previewHandler) Acknowledge(w http.ResponseWriter, r *http.Request) {
+	id, _, err := p.signer.Verify(preview.PurposeAcknowledge, mux.Vars(r)["token"])
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, preview.ErrExpired) {
+			status = http.StatusGone
+		}
+		respondError(w, status, err.Error(), nil)
+		return
+	}
+
+	acknowledger, ok := p.service.(domain.Acknowledger)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "acknowledgements are not supported", nil)
+		return
+	}
+
+	ack, err := acknowledger.AcknowledgeNotification(r.Context(), id, "action button")
+	if err != nil {
+		respondError(w, http.StatusNotFound, "notification is no longer available", err)
+		return
+	}
+	respondJSON(w, http.StatusOK, ack)
+}
+
+// setPrivatePageHeaders keeps a page carrying message content out of caches, search indexes
+// and referrers
+func setPrivatePageHeaders(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+}
+
+// previewPage is the data for the preview template
+type previewPage struct {
+	Notification *domain.Notification
+	ExpiresAt    time.Time
+}
+
+// HTML returns the notification's HTML body, if it has one
+func (p previewPage) HTML() string {
+	if p.Notification.HTMLBody != "" {
+		return p.Notification.HTMLBody
+	}
+	if p.Notification.ContentType == domain.ContentTypeHTML {
+		return p.Notification.Body
+	}
+	return ""
+}
+
+// previewTemplate renders a notification roughly as its recipient saw it. HTML bodies are shown
+// in a sandboxed frame so the message's markup can't reach the page.
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{with .Notification.Subject}}{{.}}{{else}}Notification {{.Notification.ID}}{{end}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; background: #f4f4f5; color: #18181b; }
+header { background: #fff; border-bottom: 1px solid #e4e4e7; padding: 16px 24px; }
+header h1 { font-size: 18px; margin: 0 0 8px; }
+dl { display: grid; grid-template-columns: max-content 1fr; gap: 2px 12px; margin: 0; font-size: 13px; }
+dt { color: #71717a; }
+dd { margin: 0; }
+main { max-width: 860px; margin: 24px auto; background: #fff; border: 1px solid #e4e4e7; }
+.text { white-space: pre-wrap; padding: 24px; font-size: 15px; line-height: 1.5; }
+iframe { border: 0; width: 100%; height: 80vh; }
+footer { text-align: center; font-size: 12px; color: #71717a; padding-bottom: 24px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{with .Notification.Subject}}{{.}}{{else}}(no subject){{end}}</h1>
+<dl>
+<dt>Channel</dt><dd>{{.Notification.Type}}{{with .Notification.Account}} ({{.}}){{end}}</dd>
+<dt>To</dt><dd>{{range $i, $r := .Notification.Recipients}}{{if $i}}, {{end}}{{$r}}{{end}}</dd>
+{{with .Notification.CC}}<dt>Cc</dt><dd>{{range $i, $r := .}}{{if $i}}, {{end}}{{$r}}{{end}}</dd>{{end}}
+<dt>Status</dt><dd>{{.Notification.Status}}</dd>
+{{with .Notification.SentAt}}<dt>Sent</dt><dd>{{.Format "2006-01-02 15:04:05 MST"}}</dd>{{end}}
+</dl>
+</header>
+<main>
+{{with .HTML}}<iframe sandbox srcdoc="{{.}}" title="Message"></iframe>{{else}}<div class="text">{{.Notification.Body}}</div>{{end}}
+</main>
+<footer>Shared preview of notification {{.Notification.ID}}. This link expires {{.ExpiresAt.UTC.Format "2006-01-02 15:04 MST"}}.</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,212 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestPreviewLinks tests creating a share link and viewing the notification through it
+func TestPreviewLinks(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	router := NewRouterWithOptions(svc, logger, RouterOptions{Preview: &PreviewConfig{
+		Secret:  []byte("0123456789abcdef0123456789abcdef"),
+		TTL:     time.Hour,
+		MaxTTL:  2 * time.Hour,
+		BaseURL: "https://notifier.example.com/",
+	}})
+
+	result, err := svc.Send(t.Context(), &domain.Notification{
+		ID:         "receipt-1",
+		Type:       domain.TypeStdout,
+		Subject:    "Your receipt",
+		Body:       "Thanks for your order",
+		HTMLBody:   `<p>Thanks for your <b>order</b></p><script>alert(1)</script>`,
+		Recipients: []string{"customer@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/notifications/"+result.NotificationID+"/preview-link", strings.NewReader(`{"ttl":"3h"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected a ttl over the maximum to be rejected, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/notifications/"+result.NotificationID+"/preview-link", nil))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Create link returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var link PreviewLinkResponse
+	if err := json.NewDecoder(rec.Body).Decode(&link); err != nil {
+		t.Fatalf("Failed to decode link: %v", err)
+	}
+	path, ok := strings.CutPrefix(link.URL, "https://notifier.example.com/preview/")
+	if !ok || time.Until(link.ExpiresAt) < 59*time.Minute {
+		t.Fatalf("Unexpected link: %+v", link)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/preview/"+path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Preview returned %d: %s", rec.Code, rec.Body.String())
+	}
+	page := rec.Body.String()
+	if !strings.Contains(page, "<title>Your receipt</title>") || !strings.Contains(page, "customer@example.com") {
+		t.Errorf("Preview is missing the message details:\n%s", page)
+	}
+	if !strings.Contains(page, `srcdoc="&lt;p&gt;Thanks for your &lt;b&gt;order`) || strings.Contains(page, "<script>") {
+		t.Errorf("Expected the HTML body escaped into a sandboxed frame:\n%s", page)
+	}
+	if !strings.Contains(rec.Header().Get("Content-Security-Policy"), "default-src 'none'") || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Unexpected headers: %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/preview/"+path[:len(path)-2]+"xx", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a tampered link to be rejected, got %d", rec.Code)
+	}
+}
+
+// TestWebCopy tests serving the web copy an HTML email links to
+func TestWebCopy(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeEmail, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	secret := "0123456789abcdef0123456789abcdef"
+	err = svc.WithWebCopyConfig(config.PreviewConfig{
+		Enabled: true,
+		Secret:  secret,
+		BaseURL: "https://notifier.example.com",
+		WebCopy: config.WebCopyConfig{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("WithWebCopyConfig() error = %v", err)
+	}
+	router := NewRouterWithOptions(svc, logger, RouterOptions{Preview: &PreviewConfig{Secret: []byte(secret)}})
+
+	notification := &domain.Notification{
+		ID:         "receipt-1",
+		Type:       domain.TypeEmail,
+		Subject:    "Your receipt",
+		HTMLBody:   `<body><p>Thanks for your order</p></body>`,
+		Recipients: []string{"customer@example.com"},
+	}
+	if _, err := svc.Send(t.Context(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	path, ok := strings.CutPrefix(notification.WebCopyURL, "https://notifier.example.com")
+	if !ok {
+		t.Fatalf("Unexpected web copy URL: %q", notification.WebCopyURL)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Web copy returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != `<body><p>Thanks for your order</p></body>` {
+		t.Errorf("Expected the message without its link, got:\n%s", rec.Body.String())
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Security-Policy"), "sandbox;") || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Unexpected headers: %v", rec.Header())
+	}
+
+	// A web copy link doesn't open the staff preview
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.Replace(path, "/view/", "/preview/", 1), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected a web copy token to be rejected by the preview, got %d", rec.Code)
+	}
+}
+
+// TestAcknowledgeAction tests the acknowledge button added to ntfy notifications
+func TestAcknowledgeAction(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeNtfy, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	secret := "0123456789abcdef0123456789abcdef"
+	err = svc.WithActionsConfig(config.PreviewConfig{
+		Enabled: true,
+		Secret:  secret,
+		BaseURL: "https://notifier.example.com",
+		Actions: config.ActionsConfig{Enabled: true, Label: "Acknowledge"},
+	})
+	if err != nil {
+		t.Fatalf("WithActionsConfig() error = %v", err)
+	}
+	router := NewRouterWithOptions(svc, logger, RouterOptions{Preview: &PreviewConfig{Secret: []byte(secret)}})
+
+	notification := &domain.Notification{
+		ID:         "disk-full",
+		Type:       domain.TypeNtfy,
+		Body:       "/var is at 98%",
+		Recipients: []string{"ops"},
+		Metadata:   map[string]interface{}{"ack_action": true},
+	}
+	if _, err := svc.Send(t.Context(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	actions, _ := notification.Metadata["actions"].([]interface{})
+	if len(actions) != 1 {
+		t.Fatalf("Expected an acknowledge button, got %v", notification.Metadata)
+	}
+	path, ok := strings.CutPrefix(actions[0].(map[string]interface{})["url"].(string), "https://notifier.example.com")
+	if !ok {
+		t.Fatalf("Unexpected button: %v", actions[0])
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Acknowledge returned %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, err := svc.GetNotification(t.Context(), "disk-full")
+	if err != nil || stored.AcknowledgedAt == nil {
+		t.Errorf("Expected the notification to be acknowledged, got %+v, %v", stored, err)
+	}
+
+	// An acknowledge token doesn't open the preview
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, strings.Replace(path, "/actions/", "/preview/", 1), nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected an acknowledge token to be rejected by the preview, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// serveQuotaUsage handles GET /api/v1/quota, reporting the caller's use of its client and
+// tenant quotas. Admins can inspect another client's with ?client= or a tenant's with ?tenant=.
+func serveQuotaUsage(quotas *auth.Quotas, tenants *auth.TenantResolver) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authCtx, ok := auth.GetAuthContext(r.Context())
+		if !ok {
+			respondError(w, http.StatusUnauthorized, "authentication required", nil)
+			return
+		}
+
+		client, tenant := r.URL.Query().Get("client"), strings.ToLower(r.URL.Query().Get("tenant"))
+		if client != "" || tenant != "" {
+			if !hasRole(authCtx, "admin") {
+				respondError(w, http.StatusForbidden, "admin role required to inspect other quotas", nil)
+				return
+			}
+			authCtx = &auth.AuthContext{ClientID: client, Tenant: tenant}
+			if client != "" && tenant == "" && tenants != nil {
+				_ = tenants.Resolve(authCtx)
+			}
+		}
+
+		usage := quotas.Usage(authCtx, time.Now())
+		if authCtx.ClientID == "" {
+			// Only the tenant was asked for
+			usage = filterQuotaUsage(usage, "tenant:")
+		}
+		respondJSON(w, http.StatusOK, QuotaUsageResponse{Quotas: usage})
+	}
+}
+
+// filterQuotaUsage keeps the usage of subjects with a prefix
+func filterQuotaUsage(usage []domain.QuotaUsage, prefix string) []domain.QuotaUsage {
+	filtered := make([]domain.QuotaUsage, 0, len(usage))
+	for _, entry := range usage {
+		if strings.HasPrefix(entry.Subject, prefix) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// ListRecurringNotifications handles GET /api/v1/recurring
+func (h *Handler) ListRecurringNotifications(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.recurrenceManager(w)
+	if !ok {
+		return
+	}
+
+	recurring, err := manager.ListRecurringNotifications(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list recurring notifications", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListRecurringNotificationsResponse{Recurring: recurring})
+}
+
+// GetRecurringNotification handles GET /api/v1/recurring/{id}
+func (h *Handler) GetRecurringNotification(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.recurrenceManager(w)
+	if !ok {
+		return
+	}
+
+	recurring, err := manager.GetRecurringNotification(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		respondError(w, recurrenceErrorStatus(err), "failed to get recurring notification", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, recurring)
+}
+
+// PauseRecurringNotification handles POST /api/v1/recurring/{id}/pause
+func (h *Handler) PauseRecurringNotification(w http.ResponseWriter, r *http.Request) {
+	h.controlRecurringNotification(w, r, "paused", domain.RecurrenceManager.PauseRecurringNotification)
+}
+
+// ResumeRecurringNotification handles POST /api/v1/recurring/{id}/resume
+func (h *Handler) ResumeRecurringNotification(w http.ResponseWriter, r *http.Request) {
+	h.controlRecurringNotification(w, r, "resumed", domain.RecurrenceManager.ResumeRecurringNotification)
+}
+
+// DeleteRecurringNotification handles DELETE /api/v1/recurring/{id}
+func (h *Handler) DeleteRecurringNotification(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.recurrenceManager(w)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := manager.DeleteRecurringNotification(r.Context(), id); err != nil {
+		respondError(w, recurrenceErrorStatus(err), "failed to delete recurring notification", err)
+		return
+	}
+
+	h.logger.Infof("REST: Recurring notification deleted - id=%s", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// controlRecurringNotification applies a pause or resume and responds with the recurring notification
+func (h *Handler) controlRecurringNotification(w http.ResponseWriter, r *http.Request, action string, control func(domain.RecurrenceManager, context.Context, string) error) {
+	manager, ok := h.recurrenceManager(w)
+	if !ok {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := control(manager, r.Context(), id); err != nil {
+		respondError(w, recurrenceErrorStatus(err), "failed to update recurring notification", err)
+		return
+	}
+
+	recurring, err := manager.GetRecurringNotification(r.Context(), id)
+	if err != nil {
+		respondError(w, recurrenceErrorStatus(err), "failed to get recurring notification", err)
+		return
+	}
+
+	h.logger.Infof("REST: Recurring notification %s - id=%s", action, id)
+	respondJSON(w, http.StatusOK, recurring)
+}
+
+// recurrenceManager returns the service's recurrence manager, responding 501 if it has none
+func (h *Handler) recurrenceManager(w http.ResponseWriter) (domain.RecurrenceManager, bool) {
+	manager, ok := h.service.(domain.RecurrenceManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "recurring notifications are not supported", nil)
+	}
+	return manager, ok
+}
+
+// recurrenceErrorStatus returns the HTTP status for an error from a recurring notification operation
+func recurrenceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrRecurrenceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrRecurrenceFinished):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
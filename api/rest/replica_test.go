@@ -0,0 +1,123 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+	"github.com/igodwin/notifier/internal/store"
+)
+
+// replicaStore is a notification store whose API reads come from a replica that hasn't
+// caught up with the primary
+type replicaStore struct {
+	*store.MemoryStore
+	replica *store.MemoryStore
+}
+
+func (s *replicaStore) Reader() domain.NotificationReader {
+	return s.replica
+}
+
+func (s *replicaStore) ReadStaleness(ctx context.Context) (*domain.ReadStaleness, error) {
+	return &domain.ReadStaleness{Source: "replica", LagSeconds: 2.5}, nil
+}
+
+// TestReadReplica tests that lookups, listings, stats and exports are read from the store's
+// replica and say so, while sending still uses the primary
+func TestReadReplica(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	notifications := &replicaStore{MemoryStore: store.NewMemoryStore(), replica: store.NewMemoryStore()}
+	svc.WithNotificationStore(notifications)
+	router := NewRouter(svc, logger)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications",
+		strings.NewReader(`{"type":"stdout","body":"hello","recipients":["console"]}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var sent SendNotificationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&sent); err != nil {
+		t.Fatalf("Failed to decode send response: %v", err)
+	}
+	id := sent.Result.NotificationID
+	if _, err := notifications.MemoryStore.Get(context.Background(), id); err != nil {
+		t.Fatalf("Expected the notification on the primary: %v", err)
+	}
+
+	// The replica hasn't seen the notification yet
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/"+id, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected lookup to miss on the replica, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications", nil))
+	var list ListNotificationsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode list response: %v", err)
+	}
+	if list.Total != 0 {
+		t.Errorf("Expected the replica's empty listing, got %d notifications", list.Total)
+	}
+	if list.Staleness == nil || list.Staleness.Source != "replica" || list.Staleness.LagSeconds != 2.5 {
+		t.Errorf("Unexpected listing staleness: %+v", list.Staleness)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil))
+	var stats domain.NotificationStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats response: %v", err)
+	}
+	if stats.TotalQueued+stats.TotalSent != 0 || stats.Staleness == nil || stats.Staleness.Source != "replica" {
+		t.Errorf("Expected replica stats, got %+v", stats)
+	}
+
+	// Once replicated, lookups and exports find it and carry the staleness headers
+	stored, err := notifications.MemoryStore.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to read the primary: %v", err)
+	}
+	if err := notifications.replica.Save(context.Background(), stored); err != nil {
+		t.Fatalf("Failed to replicate: %v", err)
+	}
+
+	for _, url := range []string{"/api/v1/notifications/" + id, "/api/v1/notifications/export"} {
+		rec = httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s returned %d: %s", url, rec.Code, rec.Body.String())
+		}
+		if !strings.Contains(rec.Body.String(), id) {
+			t.Errorf("Expected GET %s to include %s", url, id)
+		}
+		if got := rec.Header().Get("X-Read-Source"); got != "replica" {
+			t.Errorf("GET %s X-Read-Source = %q, want replica", url, got)
+		}
+		if got := rec.Header().Get("X-Replication-Lag"); got != "2.5" {
+			t.Errorf("GET %s X-Replication-Lag = %q, want 2.5", url, got)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/inbound"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// maxInboundReplyBytes caps the size of an inbound email webhook body
+const maxInboundReplyBytes = 10 << 20
+
+// RepliesConfig configures the webhook that receives email replies to notifications
+type RepliesConfig struct {
+	// WebhookToken authenticates the provider posting replies, as the "token" query parameter
+	// or the password of HTTP basic auth
+	WebhookToken string
+}
+
+// repliesHandler receives replies from an inbound email provider
+type repliesHandler struct {
+	service domain.NotificationService
+	config  RepliesConfig
+	logger  *logging.Logger
+}
+
+// newRepliesHandler creates a replies handler
+func newRepliesHandler(service domain.NotificationService, config RepliesConfig, logger *logging.Logger) *repliesHandler {
+	return &repliesHandler{service: service, config: config, logger: logger}
+}
+
+// Receive handles POST /inbound/replies. The body is a Postmark inbound webhook payload or a
+// raw email (message/rfc822). Replies that don't match a notification are acknowledged so the
+// provider doesn't retry them; a failed callback returns 502 so it does.
+func (h *repliesHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		respondError(w, http.StatusUnauthorized, "invalid webhook token", nil)
+		return
+	}
+
+	receiver, ok := h.service.(domain.ReplyReceiver)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "reply routing is not supported", nil)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxInboundReplyBytes+1))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if len(body) > maxInboundReplyBytes {
+		respondError(w, http.StatusRequestEntityTooLarge, "email is too large", nil)
+		return
+	}
+
+	var reply *domain.InboundReply
+	if inbound.IsMIME(r.Header.Get("Content-Type"), body) {
+		reply, err = inbound.ParseMIME(bytes.NewReader(body))
+	} else {
+		reply, err = inbound.ParsePostmark(body)
+	}
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid email", err)
+		return
+	}
+
+	id, err := receiver.ReceiveReply(r.Context(), reply)
+	switch {
+	case errors.Is(err, domain.ErrReplyUnmatched):
+		h.logger.Infof("REST: Ignored email that doesn't match a notification - from=%s", reply.From)
+		respondJSON(w, http.StatusOK, InboundReplyResponse{Status: "ignored"})
+	case err != nil:
+		h.logger.Warnf("REST: Failed to deliver reply - id=%s, error=%v", id, err)
+		respondError(w, http.StatusBadGateway, "failed to deliver reply", err)
+	default:
+		respondJSON(w, http.StatusOK, InboundReplyResponse{Status: "delivered", NotificationID: id})
+	}
+}
+
+// authorized reports whether a request presents the webhook token
+func (h *repliesHandler) authorized(r *http.Request) bool {
+	token := r.URL.Query().Get("token")
+	if _, password, ok := r.BasicAuth(); ok {
+		token = password
+	}
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.config.WebhookToken)) == 1
+}
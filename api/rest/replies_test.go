@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestInboundReplies tests authenticating the inbound webhook and routing a raw reply
+func TestInboundReplies(t *testing.T) {
+	var delivered map[string]interface{}
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&delivered)
+	}))
+	defer callback.Close()
+
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeEmail, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+	err = svc.WithRepliesConfig(config.RepliesConfig{
+		Enabled:     true,
+		Domain:      "replies.example.com",
+		LocalPart:   "reply",
+		Secret:      "0123456789abcdef0123456789abcdef",
+		CallbackURL: callback.URL,
+		Timeout:     "5s",
+	}, nil)
+	if err != nil {
+		t.Fatalf("WithRepliesConfig() error = %v", err)
+	}
+	token := "abcdefghijklmnopqrstuvwxyz012345"
+	router := NewRouterWithOptions(svc, logger, RouterOptions{Replies: &RepliesConfig{WebhookToken: token}})
+
+	notification := &domain.Notification{ID: "order-1", Type: domain.TypeEmail, Body: "Shipped", Recipients: []string{"alice@example.com"}}
+	if _, err := svc.Send(t.Context(), notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	message := "From: alice@example.com\r\nTo: " + notification.ReplyTo + "\r\nSubject: Re: Shipped\r\n\r\nWhere is it?\r\n"
+	post := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(message))
+		req.Header.Set("Content-Type", "message/rfc822")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("/inbound/replies?token=wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a wrong token to be rejected, got %d", rec.Code)
+	}
+
+	rec := post("/inbound/replies?token=" + token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Inbound reply returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp InboundReplyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.Status != "delivered" || resp.NotificationID != "order-1" {
+		t.Errorf("Unexpected response: %+v, %v", resp, err)
+	}
+	if reply, _ := delivered["reply"].(map[string]interface{}); reply["text"] != "Where is it?\r\n" {
+		t.Errorf("Unexpected delivery: %v", delivered)
+	}
+
+	message = strings.Replace(message, notification.ReplyTo, "someone@example.com", 1)
+	rec = post("/inbound/replies?token=" + token)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"ignored"`) {
+		t.Errorf("Expected an unmatched email to be acknowledged and ignored, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
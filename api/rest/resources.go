@@ -0,0 +1,280 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/resource"
+)
+
+// ClientTokenHeader carries the client token that makes a resource write safe to retry
+const ClientTokenHeader = "X-Client-Token"
+
+// ResourceHandler handles the declarative resource API used by configuration-as-code tooling
+type ResourceHandler struct {
+	registry *resource.Registry
+	logger   *logging.Logger
+}
+
+// NewResourceHandler creates a new resource handler
+func NewResourceHandler(registry *resource.Registry, logger *logging.Logger) *ResourceHandler {
+	return &ResourceHandler{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// ListKindsResponse is the response body for listing resource kinds
+type ListKindsResponse struct {
+	Kinds []string `json:"kinds"`
+}
+
+// ListResourcesResponse is the response body for listing resources of one kind
+type ListResourcesResponse struct {
+	Resources []*resource.Resource `json:"resources"`
+}
+
+// PutResourceRequest is the request body for creating or replacing a resource
+type PutResourceRequest struct {
+	Spec json.RawMessage `json:"spec"`
+}
+
+// ListKinds lists the resource kinds
+// GET /api/v1/resources
+// Requires: admin role
+func (h *ResourceHandler) ListKinds(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListKindsResponse{Kinds: h.registry.Kinds()})
+}
+
+// ListResources lists the resources of one kind
+// GET /api/v1/resources/:kind
+// Requires: admin role
+func (h *ResourceHandler) ListResources(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	resources, err := h.registry.List(r.Context(), mux.Vars(r)["kind"])
+	if err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+	if resources == nil {
+		resources = []*resource.Resource{}
+	}
+
+	respondJSON(w, http.StatusOK, ListResourcesResponse{Resources: resources})
+}
+
+// GetResource gets one resource, with its version as the ETag
+// GET /api/v1/resources/:kind/:id
+// Requires: admin role
+func (h *ResourceHandler) GetResource(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	vars := mux.Vars(r)
+	res, err := h.registry.Get(r.Context(), vars["kind"], vars["id"])
+	if err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(res.Version))
+	respondJSON(w, http.StatusOK, res)
+}
+
+// PutResource creates or replaces a resource. Sending the same X-Client-Token again returns
+// the original result, and If-Match rejects the write unless the version is unchanged.
+// PUT /api/v1/resources/:kind/:id
+// Requires: admin role
+func (h *ResourceHandler) PutResource(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var body PutResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if len(body.Spec) == 0 {
+		h.respondError(w, http.StatusBadRequest, "Missing spec", "")
+		return
+	}
+
+	expected, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		h.respondError(w, http.StatusBadRequest, "Invalid If-Match header", "expected a quoted resource version")
+		return
+	}
+
+	vars := mux.Vars(r)
+	result, err := h.registry.Put(r.Context(), resource.PutRequest{
+		Kind:            vars["kind"],
+		ID:              vars["id"],
+		Spec:            body.Spec,
+		ClientToken:     r.Header.Get(ClientTokenHeader),
+		ExpectedVersion: expected,
+	})
+	if err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.Created {
+		status = http.StatusCreated
+	}
+
+	h.logger.Infof("Applied resource %s/%s (version=%d, created=%v)", vars["kind"], vars["id"], result.Resource.Version, result.Created)
+	w.Header().Set("ETag", versionETag(result.Resource.Version))
+	respondJSON(w, status, result.Resource)
+}
+
+// DeleteResource deletes a resource, honouring If-Match
+// DELETE /api/v1/resources/:kind/:id
+// Requires: admin role
+func (h *ResourceHandler) DeleteResource(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	expected, ok := parseIfMatch(r.Header.Get("If-Match"))
+	if !ok {
+		h.respondError(w, http.StatusBadRequest, "Invalid If-Match header", "expected a quoted resource version")
+		return
+	}
+
+	vars := mux.Vars(r)
+	if err := h.registry.Delete(r.Context(), vars["kind"], vars["id"], expected); err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+
+	h.logger.Infof("Deleted resource %s/%s", vars["kind"], vars["id"])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExportResources exports the writable resources as a bundle for importing into another instance
+// GET /api/v1/resources/export?kind=templates,groups
+// Requires: admin role
+func (h *ResourceHandler) ExportResources(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var kinds []string
+	if param := r.URL.Query().Get("kind"); param != "" {
+		kinds = strings.Split(param, ",")
+	}
+
+	bundle, err := h.registry.Export(r.Context(), kinds...)
+	if err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="notifier-config.json"`)
+	respondJSON(w, http.StatusOK, bundle)
+}
+
+// ImportResources applies an exported bundle. With dry_run=true it only reports what would
+// change; with prune=true it also deletes resources of the bundle's kinds that it doesn't contain.
+// POST /api/v1/resources/import?dry_run=true&prune=true
+// Requires: admin role
+func (h *ResourceHandler) ImportResources(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var bundle resource.Bundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+	opts := resource.ImportOptions{
+		DryRun: query.Get("dry_run") == "true",
+		Prune:  query.Get("prune") == "true",
+	}
+
+	report, err := h.registry.Import(r.Context(), &bundle, opts)
+	if err != nil {
+		h.respondResourceError(w, err)
+		return
+	}
+
+	if !opts.DryRun {
+		h.logger.Infof("Imported resource bundle (created=%d, updated=%d, unchanged=%d, deleted=%d, failed=%d)",
+			report.Created, report.Updated, report.Unchanged, report.Deleted, report.Failed)
+	}
+	respondJSON(w, http.StatusOK, report)
+}
+
+// versionETag formats a resource version as an ETag
+func versionETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch reads the expected version from an If-Match header; empty and "*" mean any version
+func parseIfMatch(header string) (int64, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// respondResourceError maps resource errors to HTTP statuses
+func (h *ResourceHandler) respondResourceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, resource.ErrUnknownKind), errors.Is(err, resource.ErrNotFound):
+		h.respondError(w, http.StatusNotFound, "Resource not found", err.Error())
+	case errors.Is(err, resource.ErrReadOnly):
+		h.respondError(w, http.StatusMethodNotAllowed, "Resource is read-only", err.Error())
+	case errors.Is(err, resource.ErrInvalidSpec), errors.Is(err, resource.ErrUnsupportedBundle):
+		h.respondError(w, http.StatusBadRequest, "Invalid resource", err.Error())
+	case errors.Is(err, resource.ErrVersionConflict):
+		h.respondError(w, http.StatusPreconditionFailed, "Resource version mismatch", err.Error())
+	case errors.Is(err, resource.ErrTokenReused):
+		h.respondError(w, http.StatusConflict, "Client token reused", err.Error())
+	default:
+		h.logger.Errorf("REST: Resource operation failed - error=%v", err)
+		h.respondError(w, http.StatusInternalServerError, "Resource operation failed", err.Error())
+	}
+}
+
+// authorize checks the caller has the admin role, writing a 403 if not
+func (h *ResourceHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	authCtx, ok := auth.GetAuthContext(r.Context())
+	if !ok || !hasRole(authCtx, "admin") {
+		h.respondError(w, http.StatusForbidden, "Insufficient permissions", "admin role required")
+		return false
+	}
+	return true
+}
+
+// respondError writes an error JSON response
+func (h *ResourceHandler) respondError(w http.ResponseWriter, statusCode int, error string, message string) {
+	respondJSON(w, statusCode, ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/igodwin/notifier/internal/auth"
 	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/resource"
+	"github.com/igodwin/notifier/internal/signing"
 )
 
 // CORSConfig contains CORS middleware configuration
@@ -55,6 +57,29 @@ func NewRouterWithAuth(service domain.NotificationService, logger *logging.Logge
 
 // NewRouterWithAuthAndKeyStore creates a new HTTP router with authentication and key management
 func NewRouterWithAuthAndKeyStore(service domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore, keyStore *auth.HybridKeyStore) *mux.Router {
+	return NewRouterWithOptions(service, logger, RouterOptions{AuthStore: authStore, KeyStore: keyStore})
+}
+
+// RouterOptions configures the optional parts of the router
+type RouterOptions struct {
+	AuthStore *auth.APIKeyStore    // Enables authentication
+	JWT       *auth.JWTVerifier    // Also accepts JWT bearer tokens (requires AuthStore)
+	Tenants   *auth.TenantResolver // Limits clients to their tenant's notifications (requires AuthStore)
+	Quotas    *auth.Quotas         // Limits each client's and tenant's requests and notifications (requires AuthStore)
+	KeyStore  *auth.HybridKeyStore // Enables API key management (requires AuthStore)
+	Signing   *signing.Keyring     // Enables signing key management (requires AuthStore)
+	Resources *resource.Registry   // Enables the declarative resource API (requires AuthStore)
+	Mirror    *MirrorConfig        // Mirrors a share of send requests to a shadow deployment
+	Compat    []CompatProfile      // Translates send requests from clients migrating from another service (requires AuthStore)
+	Apprise   bool                 // Serves Apprise's stateless notification API at /notify
+	Preview   *PreviewConfig       // Enables signed share links to notification previews
+	Replies   *RepliesConfig       // Enables the webhook that receives email replies to notifications
+	Version   *domain.VersionInfo  // Build and feature information served at /api/v1/version
+}
+
+// NewRouterWithOptions creates a new HTTP router with the given optional features
+func NewRouterWithOptions(service domain.NotificationService, logger *logging.Logger, opts RouterOptions) *mux.Router {
+	authStore, keyStore := opts.AuthStore, opts.KeyStore
 	handler := NewHandler(service, logger)
 	router := mux.NewRouter()
 
@@ -63,23 +88,109 @@ func NewRouterWithAuthAndKeyStore(service domain.NotificationService, logger *lo
 
 	// Apply authentication middleware if auth store is provided
 	if authStore != nil {
-		authMiddleware := auth.NewRESTAuthMiddleware(authStore, logger)
+		authMiddleware := auth.NewRESTAuthMiddleware(authStore, logger).WithJWT(opts.JWT).WithTenants(opts.Tenants).WithQuotas(opts.Quotas)
 		v1.Use(authMiddleware.Middleware)
 	}
 
-	// Notification routes
-	v1.HandleFunc("/notifications", handler.SendNotification).Methods(http.MethodPost)
-	v1.HandleFunc("/notifications/batch", handler.SendBatchNotifications).Methods(http.MethodPost)
+	// Notification routes. Send requests are translated for clients with a compatibility profile,
+	// then mirrored to a shadow deployment if configured; the shadow gets the original request.
+	send, sendBatch := handler.SendNotification, handler.SendBatchNotifications
+	if len(opts.Compat) > 0 {
+		c := newCompat(opts.Compat, logger)
+		send, sendBatch = c.wrap(send, false), c.wrap(sendBatch, true)
+	}
+	if opts.Mirror != nil && opts.Mirror.URL != "" {
+		m := newMirror(*opts.Mirror, logger)
+		send, sendBatch = m.wrap(send), m.wrap(sendBatch)
+	}
+	v1.HandleFunc("/notifications", send).Methods(http.MethodPost)
+	v1.HandleFunc("/notifications/batch", sendBatch).Methods(http.MethodPost)
+	v1.HandleFunc("/notifications/stream", handler.StreamNotifications).Methods(http.MethodPost).Name(routeStreamNotifications)
 	v1.HandleFunc("/notifications", handler.ListNotifications).Methods(http.MethodGet)
+	v1.HandleFunc("/notifications/status", handler.GetNotificationStatuses).Methods(http.MethodGet)
+	v1.HandleFunc("/notifications/events", handler.StreamStatusEvents).Methods(http.MethodGet)
+	v1.HandleFunc("/notifications/export", handler.ExportNotifications).Methods(http.MethodGet)
 	v1.HandleFunc("/notifications/{id}", handler.GetNotification).Methods(http.MethodGet)
 	v1.HandleFunc("/notifications/{id}", handler.CancelNotification).Methods(http.MethodDelete)
 	v1.HandleFunc("/notifications/{id}/retry", handler.RetryNotification).Methods(http.MethodPost)
+	v1.HandleFunc("/notifications/{id}/reschedule", handler.RescheduleNotification).Methods(http.MethodPost)
+	v1.HandleFunc("/notifications/{id}/approve", handler.ApproveNotification).Methods(http.MethodPost)
+	v1.HandleFunc("/notifications/{id}/reject", handler.RejectNotification).Methods(http.MethodPost)
+
+	// Preview share links, email web copies and action buttons. They're outside /api/v1 as the
+	// token is their credential.
+	if opts.Preview != nil {
+		preview := newPreviewHandler(service, *opts.Preview, logger)
+		v1.HandleFunc("/notifications/{id}/preview-link", preview.CreateLink).Methods(http.MethodPost)
+		router.HandleFunc("/preview/{token}", preview.ServePage).Methods(http.MethodGet)
+		router.HandleFunc("/view/{token}", preview.ServeWebCopy).Methods(http.MethodGet)
+		router.HandleFunc("/actions/{token}", preview.Acknowledge).Methods(http.MethodPost)
+	}
+
+	// Inbound email webhook. It's outside /api/v1 as providers authenticate with the webhook token.
+	if opts.Replies != nil {
+		replies := newRepliesHandler(service, *opts.Replies, logger)
+		router.HandleFunc("/inbound/replies", replies.Receive).Methods(http.MethodPost)
+	}
+
+	// Send job routes
+	v1.HandleFunc("/jobs", handler.SubmitSendJob).Methods(http.MethodPost).Name(routeSubmitSendJob)
+	v1.HandleFunc("/jobs", handler.ListSendJobs).Methods(http.MethodGet)
+	v1.HandleFunc("/jobs/{id}", handler.GetSendJob).Methods(http.MethodGet)
+	v1.HandleFunc("/jobs/{id}/pause", handler.PauseSendJob).Methods(http.MethodPost)
+	v1.HandleFunc("/jobs/{id}/resume", handler.ResumeSendJob).Methods(http.MethodPost)
+	v1.HandleFunc("/jobs/{id}/cancel", handler.CancelSendJob).Methods(http.MethodPost)
+
+	// Recurring notification routes
+	v1.HandleFunc("/recurring", handler.ListRecurringNotifications).Methods(http.MethodGet)
+	v1.HandleFunc("/recurring/{id}", handler.GetRecurringNotification).Methods(http.MethodGet)
+	v1.HandleFunc("/recurring/{id}", handler.DeleteRecurringNotification).Methods(http.MethodDelete)
+	v1.HandleFunc("/recurring/{id}/pause", handler.PauseRecurringNotification).Methods(http.MethodPost)
+	v1.HandleFunc("/recurring/{id}/resume", handler.ResumeRecurringNotification).Methods(http.MethodPost)
+
+	// Template routes
+	v1.HandleFunc("/templates", handler.ListTemplates).Methods(http.MethodGet)
+	v1.HandleFunc("/templates", handler.CreateTemplate).Methods(http.MethodPost)
+	v1.HandleFunc("/templates/{name}", handler.GetTemplate).Methods(http.MethodGet)
+	v1.HandleFunc("/templates/{name}", handler.UpdateTemplate).Methods(http.MethodPut)
+	v1.HandleFunc("/templates/{name}", handler.DeleteTemplate).Methods(http.MethodDelete)
+	v1.HandleFunc("/templates/{name}/render", handler.RenderTemplate).Methods(http.MethodPost)
+
+	// Contact routes
+	v1.HandleFunc("/contacts", handler.ListContacts).Methods(http.MethodGet)
+	v1.HandleFunc("/contacts", handler.CreateContact).Methods(http.MethodPost)
+	v1.HandleFunc("/contacts/{name}", handler.GetContact).Methods(http.MethodGet)
+	v1.HandleFunc("/contacts/{name}", handler.UpdateContact).Methods(http.MethodPut)
+	v1.HandleFunc("/contacts/{name}", handler.DeleteContact).Methods(http.MethodDelete)
+	v1.HandleFunc("/contacts/{name}/preferences", handler.GetPreferences).Methods(http.MethodGet)
+	v1.HandleFunc("/contacts/{name}/preferences", handler.SetPreferences).Methods(http.MethodPut)
+	v1.HandleFunc("/contacts/{name}/preferences", handler.DeletePreferences).Methods(http.MethodDelete)
+
+	// Heartbeat routes
+	v1.HandleFunc("/heartbeats", handler.ListHeartbeats).Methods(http.MethodGet)
+	v1.HandleFunc("/heartbeats/{name}", handler.PingHeartbeat).Methods(http.MethodPost)
+	v1.HandleFunc("/heartbeats/{name}", handler.GetHeartbeat).Methods(http.MethodGet)
+
+	// Dispatch kill-switch routes
+	v1.HandleFunc("/dispatch/pauses", handler.ListDispatchPauses).Methods(http.MethodGet)
+	v1.HandleFunc("/dispatch/pause", handler.PauseDispatch).Methods(http.MethodPost)
+	v1.HandleFunc("/dispatch/resume", handler.ResumeDispatch).Methods(http.MethodPost)
+	v1.HandleFunc("/dispatch/blackouts", handler.ListBlackoutWindows).Methods(http.MethodGet)
+	v1.HandleFunc("/dispatch/blackouts", handler.CreateBlackoutWindow).Methods(http.MethodPost)
+	v1.HandleFunc("/dispatch/blackouts/{id}", handler.DeleteBlackoutWindow).Methods(http.MethodDelete)
+
+	// Policy preview route
+	v1.HandleFunc("/policies/preview", handler.PreviewPolicies).Methods(http.MethodPost)
 
 	// Stats route
 	v1.HandleFunc("/stats", handler.GetStats).Methods(http.MethodGet)
 
 	// Notifiers route
 	v1.HandleFunc("/notifiers", handler.GetNotifiers).Methods(http.MethodGet)
+	v1.HandleFunc("/notifiers/{type}/pause", handler.PauseNotifier).Methods(http.MethodPost)
+	v1.HandleFunc("/notifiers/{type}/resume", handler.ResumeNotifier).Methods(http.MethodPost)
+	v1.HandleFunc("/notifiers/{type}/{account}/pause", handler.PauseNotifier).Methods(http.MethodPost)
+	v1.HandleFunc("/notifiers/{type}/{account}/resume", handler.ResumeNotifier).Methods(http.MethodPost)
 
 	// Key management routes (requires auth and keystore)
 	if authStore != nil && keyStore != nil {
@@ -91,22 +202,80 @@ func NewRouterWithAuthAndKeyStore(service domain.NotificationService, logger *lo
 		v1.HandleFunc("/admin/keys/{name}/audit", keyHandler.GetAuditLog).Methods(http.MethodGet)
 	}
 
+	// Signing key management routes (requires auth and signing)
+	if authStore != nil && opts.Signing != nil {
+		signingHandler := NewSigningKeyHandler(opts.Signing, logger)
+		v1.HandleFunc("/admin/signing-keys", signingHandler.ListKeys).Methods(http.MethodGet)
+		v1.HandleFunc("/admin/signing-keys", signingHandler.AddKey).Methods(http.MethodPost)
+		v1.HandleFunc("/admin/signing-keys/{id}/activate", signingHandler.ActivateKey).Methods(http.MethodPost)
+		v1.HandleFunc("/admin/signing-keys/{id}", signingHandler.RemoveKey).Methods(http.MethodDelete)
+	}
+
+	// Quota usage route (requires auth and quotas)
+	if authStore != nil && opts.Quotas != nil {
+		v1.HandleFunc("/quota", serveQuotaUsage(opts.Quotas, opts.Tenants)).Methods(http.MethodGet)
+	}
+
+	// Declarative resource routes (requires auth and a registry)
+	if authStore != nil && opts.Resources != nil {
+		resourceHandler := NewResourceHandler(opts.Resources, logger)
+		v1.HandleFunc("/resources", resourceHandler.ListKinds).Methods(http.MethodGet)
+		v1.HandleFunc("/resources/export", resourceHandler.ExportResources).Methods(http.MethodGet)
+		v1.HandleFunc("/resources/import", resourceHandler.ImportResources).Methods(http.MethodPost)
+		v1.HandleFunc("/resources/{kind}", resourceHandler.ListResources).Methods(http.MethodGet)
+		v1.HandleFunc("/resources/{kind}/{id}", resourceHandler.GetResource).Methods(http.MethodGet)
+		v1.HandleFunc("/resources/{kind}/{id}", resourceHandler.PutResource).Methods(http.MethodPut)
+		v1.HandleFunc("/resources/{kind}/{id}", resourceHandler.DeleteResource).Methods(http.MethodDelete)
+	}
+
+	// Apprise-compatible route, outside /api/v1 where Apprise clients expect it
+	if opts.Apprise {
+		var notify http.Handler = http.HandlerFunc(handler.AppriseNotify)
+		if authStore != nil {
+			notify = appriseCredentials(auth.NewRESTAuthMiddleware(authStore, logger).WithJWT(opts.JWT).WithTenants(opts.Tenants).WithQuotas(opts.Quotas).Middleware(notify))
+		}
+		notify = maxBodySizeMiddleware(1<<20, nil)(notify)
+		router.Handle("/notify", notify).Methods(http.MethodPost)
+		router.Handle("/notify/", notify).Methods(http.MethodPost)
+	}
+
+	// Build and feature information
+	if opts.Version != nil {
+		v1.HandleFunc("/version", serveVersion(opts.Version)).Methods(http.MethodGet)
+	}
+
 	// Health check route (no auth required)
 	router.HandleFunc("/health", handler.HealthCheck).Methods(http.MethodGet)
 
 	// Middleware - logging, request size limit, and CORS
 	router.Use(loggingMiddleware)
-	v1.Use(maxBodySizeMiddleware(1 << 20)) // 1 MB limit on API request bodies
+	v1.Use(maxBodySizeMiddleware(1<<20, map[string]int64{ // 1 MB limit on API request bodies
+		routeSubmitSendJob:       maxSendJobBodySize,
+		routeStreamNotifications: maxStreamBodySize,
+	}))
 
 	return router
 }
 
+// Names of routes that need a larger body limit
+const (
+	routeSubmitSendJob       = "submitSendJob"
+	routeStreamNotifications = "streamNotifications"
+)
+
 // maxBodySizeMiddleware limits the size of incoming request bodies to prevent DoS.
-func maxBodySizeMiddleware(maxBytes int64) func(http.Handler) http.Handler {
+// Routes named in overrides get their own limit.
+func maxBodySizeMiddleware(maxBytes int64, overrides map[string]int64) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := maxBytes
+			if route := mux.CurrentRoute(r); route != nil {
+				if override, ok := overrides[route.GetName()]; ok {
+					limit = override
+				}
+			}
 			if r.Body != nil {
-				r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
 			}
 			next.ServeHTTP(w, r)
 		})
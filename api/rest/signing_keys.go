@@ -0,0 +1,176 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/signing"
+)
+
+// SigningKeyHandler handles payload signing key management endpoints.
+// Keys added and activated through the API are saved to signing.persist_path when it's set,
+// and otherwise last until the next restart. Keys set in the config file can't be removed here.
+type SigningKeyHandler struct {
+	keyring *signing.Keyring
+	logger  *logging.Logger
+}
+
+// NewSigningKeyHandler creates a new signing key handler
+func NewSigningKeyHandler(keyring *signing.Keyring, logger *logging.Logger) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		keyring: keyring,
+		logger:  logger,
+	}
+}
+
+// ListSigningKeysResponse is the response body for listing signing keys
+type ListSigningKeysResponse struct {
+	Keys []signing.KeyInfo `json:"keys"`
+}
+
+// AddSigningKeyRequest is the request body for adding a signing key
+type AddSigningKeyRequest struct {
+	ID       string `json:"id"`
+	Secret   string `json:"secret,omitempty"`   // Generated if empty
+	Activate bool   `json:"activate,omitempty"` // Start signing with the key immediately
+}
+
+// AddSigningKeyResponse is the response body when adding a signing key
+type AddSigningKeyResponse struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret,omitempty"` // Only returned when generated
+	Active bool   `json:"active"`
+}
+
+// ListKeys lists the signing keys without their secrets
+// GET /api/v1/admin/signing-keys
+// Requires: admin role
+func (h *SigningKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListSigningKeysResponse{Keys: h.keyring.Keys()})
+}
+
+// AddKey adds a signing key, generating the secret if none is given
+// POST /api/v1/admin/signing-keys
+// Requires: admin role
+func (h *SigningKeyHandler) AddKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	var req AddSigningKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.ID == "" {
+		h.respondError(w, http.StatusBadRequest, "Missing id", "")
+		return
+	}
+
+	resp := AddSigningKeyResponse{ID: req.ID}
+	var err error
+	if req.Secret == "" {
+		resp.Secret, err = h.keyring.GenerateKey(req.ID)
+	} else {
+		err = h.keyring.AddKey(req.ID, req.Secret)
+	}
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "failed to save") {
+			h.respondError(w, http.StatusInternalServerError, "Failed to save signing key", err.Error())
+		} else {
+			h.respondError(w, http.StatusConflict, "Failed to add signing key", err.Error())
+		}
+		return
+	}
+
+	if req.Activate {
+		if err := h.keyring.Activate(req.ID); err != nil {
+			h.respondError(w, http.StatusInternalServerError, "Failed to activate signing key", err.Error())
+			return
+		}
+		resp.Active = true
+	}
+
+	h.logger.Infof("Added signing key '%s' (active=%v)", req.ID, resp.Active)
+	respondJSON(w, http.StatusCreated, resp)
+}
+
+// ActivateKey switches signing to an existing key
+// POST /api/v1/admin/signing-keys/:id/activate
+// Requires: admin role
+func (h *SigningKeyHandler) ActivateKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.keyring.Activate(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.respondError(w, http.StatusNotFound, "Signing key not found", "")
+		} else {
+			h.respondError(w, http.StatusInternalServerError, "Failed to activate signing key", err.Error())
+		}
+		return
+	}
+
+	h.logger.Infof("Activated signing key '%s'", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveKey retires a signing key; receivers can no longer verify signatures made with it
+// DELETE /api/v1/admin/signing-keys/:id
+// Requires: admin role
+func (h *SigningKeyHandler) RemoveKey(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.keyring.RemoveKey(id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			h.respondError(w, http.StatusNotFound, "Signing key not found", "")
+		} else {
+			h.respondError(w, http.StatusConflict, "Failed to remove signing key", err.Error())
+		}
+		return
+	}
+
+	h.logger.Infof("Removed signing key '%s'", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorize checks the caller has the admin role, writing a 403 if not
+func (h *SigningKeyHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	authCtx, ok := auth.GetAuthContext(r.Context())
+	if !ok || !hasRole(authCtx, "admin") {
+		h.respondError(w, http.StatusForbidden, "Insufficient permissions", "admin role required")
+		return false
+	}
+	return true
+}
+
+// hasRole checks if the auth context has a specific role
+func hasRole(authCtx *auth.AuthContext, role string) bool {
+	for _, r := range authCtx.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// respondError writes an error JSON response
+func (h *SigningKeyHandler) respondError(w http.ResponseWriter, statusCode int, error string, message string) {
+	respondJSON(w, statusCode, ErrorResponse{
+		Error:   error,
+		Message: message,
+	})
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestGetNotificationStatuses tests the compact status endpoint and its ETag handling
+func TestGetNotificationStatuses(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	router := NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logger), logger)
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications",
+			strings.NewReader(`{"type":"stdout","body":"hello","recipients":["console"]}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("Send returned %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp SendNotificationResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode send response: %v", err)
+		}
+		ids = append(ids, resp.Result.NotificationID)
+	}
+
+	url := "/api/v1/notifications/status?ids=" + ids[0] + "," + ids[1] + "&ids=unknown-id"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Status returned %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var resp NotificationStatusesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode status response: %v", err)
+	}
+	if len(resp.Statuses) != 2 || resp.Statuses[ids[0]] != string(domain.StatusQueued) {
+		t.Errorf("Unexpected statuses: %+v", resp.Statuses)
+	}
+	if len(resp.Missing) != 1 || resp.Missing[0] != "unknown-id" {
+		t.Errorf("Unexpected missing IDs: %v", resp.Missing)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+
+	// Same set in a different order is not modified
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/notifications/status?ids=unknown-id,"+ids[1]+","+ids[0], nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for matching ETag, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications/status", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without ids, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestStreamNotifications tests that each NDJSON line is answered with its own result line
+func TestStreamNotifications(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	router := NewRouter(service.NewNotificationService(factory, q, 1, nil, nil, logging.New(logging.ErrorLevel, os.Stderr)), logging.New(logging.ErrorLevel, os.Stderr))
+
+	post := func(contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/notifications/stream", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("application/json", `{}`); rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("Expected 415 for a JSON body, got %d", rec.Code)
+	}
+
+	body := strings.Join([]string{
+		`{"type":"stdout","body":"one","recipients":["a"]}`,
+		``,
+		`{"type":"stdout","recipients":["a"]}`,
+		`not json`,
+		`{"type":"stdout","body":"two","recipients":["b"]}`,
+	}, "\n")
+	rec := post(NDJSONContentType+"; charset=utf-8", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Stream returned %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != NDJSONContentType {
+		t.Errorf("Expected %s response, got %q", NDJSONContentType, ct)
+	}
+
+	var results []StreamNotificationResult
+	var summary StreamSummaryLine
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), `{"summary"`) {
+			if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+				t.Fatalf("Failed to decode summary: %v", err)
+			}
+			continue
+		}
+		var result StreamNotificationResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("Failed to decode result line %q: %v", scanner.Text(), err)
+		}
+		results = append(results, result)
+	}
+
+	want := []struct {
+		line   int
+		status int
+	}{{1, http.StatusAccepted}, {3, http.StatusBadRequest}, {4, http.StatusBadRequest}, {5, http.StatusAccepted}}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d result lines, got %d: %s", len(want), len(results), rec.Body.String())
+	}
+	for i, w := range want {
+		if results[i].Line != w.line || results[i].Status != w.status {
+			t.Errorf("Result %d: expected line %d status %d, got %+v", i, w.line, w.status, results[i])
+		}
+	}
+	if results[0].Result == nil || results[0].Result.NotificationID == "" {
+		t.Errorf("Expected an accepted line to carry its notification, got %+v", results[0])
+	}
+
+	if summary.Summary != (StreamSummary{Lines: 4, Accepted: 2, Failed: 2}) {
+		t.Errorf("Unexpected summary: %+v", summary.Summary)
+	}
+
+	// A line over the limit ends the stream with the reason in the summary
+	rec = post(NDJSONContentType, `{"type":"stdout","body":"`+strings.Repeat("x", maxStreamLineSize)+`","recipients":["a"]}`)
+	if !strings.Contains(rec.Body.String(), `"error":"stopped reading at line 1`) {
+		t.Errorf("Expected an oversized line to stop the stream, got %s", rec.Body.String())
+	}
+}
@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// CreateTemplate handles POST /api/v1/templates, saving a template notifications can be
+// rendered from. A template that doesn't parse is rejected.
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeTemplates(w, r)
+	if !ok {
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	created, err := manager.CreateTemplate(r.Context(), req.template())
+	if err != nil {
+		respondError(w, templateErrorStatus(err), "failed to create template", err)
+		return
+	}
+
+	h.logger.Infof("REST: Template created - name=%s, type=%s, by=%s", created.Name, created.Type, operator)
+	respondJSON(w, http.StatusCreated, created)
+}
+
+// UpdateTemplate handles PUT /api/v1/templates/{name}, replacing a template created through
+// the API
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeTemplates(w, r)
+	if !ok {
+		return
+	}
+
+	var req TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if req.Name != "" && req.Name != name {
+		respondError(w, http.StatusBadRequest, "template name can't be changed", nil)
+		return
+	}
+	req.Name = name
+
+	updated, err := manager.UpdateTemplate(r.Context(), req.template())
+	if err != nil {
+		respondError(w, templateErrorStatus(err), "failed to update template", err)
+		return
+	}
+
+	h.logger.Infof("REST: Template updated - name=%s, type=%s, by=%s", updated.Name, updated.Type, operator)
+	respondJSON(w, http.StatusOK, updated)
+}
+
+// GetTemplate handles GET /api/v1/templates/{name}
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.TemplateManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "templates are not supported", nil)
+		return
+	}
+
+	tmpl, err := manager.GetTemplate(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		respondError(w, templateErrorStatus(err), "failed to get template", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, tmpl)
+}
+
+// ListTemplates handles GET /api/v1/templates. ?type= lists only the templates that can render
+// notifications of that type.
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.TemplateManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "templates are not supported", nil)
+		return
+	}
+
+	templates, err := manager.ListTemplates(r.Context(), domain.NotificationType(r.URL.Query().Get("type")))
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "failed to list templates", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, ListTemplatesResponse{Templates: templates})
+}
+
+// DeleteTemplate handles DELETE /api/v1/templates/{name}
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	manager, operator, ok := h.authorizeTemplates(w, r)
+	if !ok {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+	if err := manager.DeleteTemplate(r.Context(), name); err != nil {
+		respondError(w, templateErrorStatus(err), "failed to delete template", err)
+		return
+	}
+
+	h.logger.Infof("REST: Template deleted - name=%s, by=%s", name, operator)
+	respondJSON(w, http.StatusOK, map[string]string{
+		"message": "template deleted",
+	})
+}
+
+// RenderTemplate handles POST /api/v1/templates/{name}/render, returning the subject and
+// bodies a template renders for the given variables without sending anything. The body may
+// set the "type" and "locale" to render the variant and translation for.
+func (h *Handler) RenderTemplate(w http.ResponseWriter, r *http.Request) {
+	manager, ok := h.service.(domain.TemplateManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "templates are not supported", nil)
+		return
+	}
+
+	var req RenderTemplateRequest
+	if err := decodeOptionalBody(r, &req); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+
+	rendered, err := manager.RenderTemplate(r.Context(), mux.Vars(r)["name"], domain.NotificationType(req.Type), req.Locale, req.TemplateVars)
+	if err != nil {
+		respondError(w, templateErrorStatus(err), "failed to render template", err)
+		return
+	}
+
+	respondJSON(w, http.StatusOK, rendered)
+}
+
+// authorizeTemplates checks the service supports templates and that the caller may change
+// them. It returns the manager and the operator's client ID.
+func (h *Handler) authorizeTemplates(w http.ResponseWriter, r *http.Request) (domain.TemplateManager, string, bool) {
+	manager, ok := h.service.(domain.TemplateManager)
+	if !ok {
+		respondError(w, http.StatusNotImplemented, "templates are not supported", nil)
+		return nil, "", false
+	}
+
+	operator, ok := authorizeTenantOperator(w, r)
+	return manager, operator, ok
+}
+
+// templateErrorStatus returns the HTTP status for an error from managing templates
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrTemplateExists), errors.Is(err, domain.ErrTemplateConfigured):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
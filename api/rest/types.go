@@ -18,29 +18,56 @@ type SendNotificationRequest struct {
 	Body         string                 `json:"body"`
 	HTMLBody     string                 `json:"html_body,omitempty"`    // Optional HTML body for email; if set, sends multipart/alternative.
 	ContentType  string                 `json:"content_type,omitempty"` // Deprecated: prefer html_body. "text" or "html".
+	Template     string                 `json:"template,omitempty"`     // Template to render subject and body from
+	TemplateVars map[string]interface{} `json:"template_vars,omitempty"`
+	Locale       string                 `json:"locale,omitempty"` // Recipient's locale, e.g. "fr" or "pt-BR"; selects the template's translation
 	Recipients   []string               `json:"recipients"`
-	CC           []string               `json:"cc,omitempty"`  // Carbon copy recipients (email only)
-	BCC          []string               `json:"bcc,omitempty"` // Blind carbon copy recipients (email only)
+	Contacts     []string               `json:"contacts,omitempty"` // Contacts and groups whose addresses on the channel are added to the recipients
+	CC           []string               `json:"cc,omitempty"`       // Carbon copy recipients (email only)
+	BCC          []string               `json:"bcc,omitempty"`      // Blind carbon copy recipients (email only)
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Tags         map[string]string      `json:"tags,omitempty"`      // Labels routing rules match and notifications can be listed by, e.g. {"team": "payments"}
+	Options      *domain.SendOptions    `json:"options,omitempty"`   // Typed overrides for the target channel
+	Origin       Origin                 `json:"origin"`              // Sending system (defaults to the API key's client ID) and triggering user
+	DedupKey     string                 `json:"dedup_key,omitempty"` // Suppresses later notifications with the same key within the dedup window
 	ScheduledFor *time.Time             `json:"scheduled_for,omitempty"`
+	Recurrence   string                 `json:"recurrence,omitempty"` // Cron expression or RRULE; creates a recurring notification
+	Deadline     *time.Time             `json:"deadline,omitempty"`   // When it must be delivered by
 	MaxRetries   int                    `json:"max_retries,omitempty"`
+	DryRun       bool                   `json:"dry_run,omitempty"`   // Process and validate without delivering
+	Fallbacks    []domain.Fallback      `json:"fallbacks,omitempty"` // Channels to try in order if delivery fails permanently
+	Targets      []domain.Target        `json:"targets,omitempty"`   // Channels to send through at once, instead of type and account
+}
+
+// Origin identifies the system and user that generated a notification
+type Origin struct {
+	System string `json:"system,omitempty"`
+	User   string `json:"user,omitempty"` // Empty for system-triggered notifications
 }
 
 // Validate validates the request
 func (r *SendNotificationRequest) Validate() error {
-	if r.Type == "" {
-		return fmt.Errorf("type is required")
+	if len(r.Targets) > 0 {
+		if r.Type != "" || r.Account != "" {
+			return fmt.Errorf("type and account can't be combined with targets")
+		}
+	} else if r.Type == "" {
+		return fmt.Errorf("type or targets is required")
 	}
 
 	// For email, allow BCC-only (at least one recipient in To, CC, or BCC)
-	// For other types, require Recipients
-	totalRecipients := len(r.Recipients) + len(r.CC) + len(r.BCC)
-	if totalRecipients == 0 {
-		return fmt.Errorf("at least one recipient is required (recipients, cc, or bcc)")
+	// For other types, require Recipients or contacts to resolve them from
+	totalRecipients := len(r.Recipients) + len(r.Contacts) + len(r.CC) + len(r.BCC)
+	if len(r.Targets) > 0 {
+		if err := domain.ValidateTargets(r.Targets, totalRecipients > 0); err != nil {
+			return err
+		}
+	} else if totalRecipients == 0 {
+		return fmt.Errorf("at least one recipient is required (recipients, contacts, cc, or bcc)")
 	}
 
-	if r.Body == "" {
-		return fmt.Errorf("body is required")
+	if r.Body == "" && r.Template == "" {
+		return fmt.Errorf("body or template is required")
 	}
 
 	// Validate content type if specified (must be "text" or "html", case-insensitive)
@@ -51,7 +78,28 @@ func (r *SendNotificationRequest) Validate() error {
 		}
 	}
 
-	return nil
+	if r.Deadline != nil && r.ScheduledFor != nil && !r.Deadline.After(*r.ScheduledFor) {
+		return fmt.Errorf("deadline must be after scheduled_for")
+	}
+
+	if _, ok := r.Tags[""]; ok {
+		return fmt.Errorf("tag keys must not be empty")
+	}
+
+	if err := domain.ValidateFallbacks(r.Fallbacks); err != nil {
+		return err
+	}
+
+	// Each target gets the options block for its channel
+	for _, target := range r.Targets {
+		if err := r.Options.ForType(target.Type).Validate(target.Type); err != nil {
+			return err
+		}
+	}
+	if len(r.Targets) > 0 {
+		return nil
+	}
+	return r.Options.Validate(domain.NotificationType(r.Type))
 }
 
 // ToNotification converts the request to a domain notification
@@ -68,6 +116,11 @@ func (r *SendNotificationRequest) ToNotification() *domain.Notification {
 		contentType = domain.ContentTypeText
 	}
 
+	var replyTo string
+	if r.Options != nil && r.Options.Email != nil {
+		replyTo = r.Options.Email.ReplyTo
+	}
+
 	return &domain.Notification{
 		ID:           uuid.New().String(),
 		Type:         domain.NotificationType(r.Type),
@@ -78,13 +131,27 @@ func (r *SendNotificationRequest) ToNotification() *domain.Notification {
 		Body:         r.Body,
 		HTMLBody:     r.HTMLBody,
 		ContentType:  contentType,
+		Template:     r.Template,
+		TemplateVars: r.TemplateVars,
+		Locale:       r.Locale,
 		Recipients:   r.Recipients,
+		Contacts:     r.Contacts,
 		CC:           r.CC,
 		BCC:          r.BCC,
+		ReplyTo:      replyTo,
 		Metadata:     r.Metadata,
+		Tags:         r.Tags,
+		Options:      r.Options,
+		Origin:       domain.Origin{System: r.Origin.System, User: r.Origin.User},
+		DedupKey:     r.DedupKey,
 		CreatedAt:    time.Now(),
 		ScheduledFor: r.ScheduledFor,
+		Recurrence:   r.Recurrence,
+		Deadline:     r.Deadline,
 		MaxRetries:   maxRetries,
+		DryRun:       r.DryRun,
+		Fallbacks:    r.Fallbacks,
+		Targets:      r.Targets,
 		RetryCount:   0,
 	}
 }
@@ -115,16 +182,49 @@ type Notification struct {
 	Body         string                 `json:"body"`
 	HTMLBody     string                 `json:"html_body,omitempty"`
 	ContentType  string                 `json:"content_type,omitempty"`
+	Template     string                 `json:"template,omitempty"`
+	Locale       string                 `json:"locale,omitempty"`
 	Recipients   []string               `json:"recipients"`
+	Contacts     []string               `json:"contacts,omitempty"`
 	CC           []string               `json:"cc,omitempty"`
 	BCC          []string               `json:"bcc,omitempty"`
+	ReplyTo      string                 `json:"reply_to,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Tags         map[string]string      `json:"tags,omitempty"`
+	Options      *domain.SendOptions    `json:"options,omitempty"`
+	Origin       Origin                 `json:"origin"`
+	Tenant       string                 `json:"tenant,omitempty"`
+	DedupKey     string                 `json:"dedup_key,omitempty"`
 	CreatedAt    time.Time              `json:"created_at"`
 	ScheduledFor *time.Time             `json:"scheduled_for,omitempty"`
 	SentAt       *time.Time             `json:"sent_at,omitempty"`
 	RetryCount   int                    `json:"retry_count"`
 	MaxRetries   int                    `json:"max_retries"`
 	LastError    string                 `json:"last_error,omitempty"`
+	Links        map[string]string      `json:"links,omitempty"`
+	WebCopyURL   string                 `json:"web_copy_url,omitempty"`
+	DryRun       bool                   `json:"dry_run,omitempty"`
+	JobID        string                 `json:"job_id,omitempty"`
+	RecurrenceID string                 `json:"recurrence_id,omitempty"`
+
+	Fallbacks  []domain.Fallback `json:"fallbacks,omitempty"`
+	FallbackOf string            `json:"fallback_of,omitempty"` // Failed notification this one was sent in place of
+	FallbackID string            `json:"fallback_id,omitempty"` // Notification sent in place of this one after it failed
+
+	FanoutID string `json:"fanout_id,omitempty"` // Fanned-out notification this one was sent for, one per target
+
+	DigestID string   `json:"digest_id,omitempty"` // Digest this notification was combined into, once it's sent
+	DigestOf []string `json:"digest_of,omitempty"` // Notifications a digest combines
+
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // When a retrying notification is next attempted
+
+	Deadline       *time.Time `json:"deadline,omitempty"`
+	DeadlineMissed bool       `json:"deadline_missed,omitempty"` // Delivered after the deadline, or failed
+
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+
+	PolicyViolations []domain.PolicyViolation `json:"policy_violations,omitempty"`
 }
 
 // NotificationFromDomain converts a domain notification to API format
@@ -139,27 +239,106 @@ func NotificationFromDomain(n *domain.Notification) Notification {
 		Body:         n.Body,
 		HTMLBody:     n.HTMLBody,
 		ContentType:  string(n.ContentType),
+		Template:     n.Template,
+		Locale:       n.Locale,
 		Recipients:   n.Recipients,
+		Contacts:     n.Contacts,
 		CC:           n.CC,
 		BCC:          n.BCC,
+		ReplyTo:      n.ReplyTo,
 		Metadata:     n.Metadata,
+		Tags:         n.Tags,
+		Options:      n.Options,
+		Origin:       Origin{System: n.Origin.System, User: n.Origin.User},
+		Tenant:       n.Tenant,
+		DedupKey:     n.DedupKey,
 		CreatedAt:    n.CreatedAt,
 		ScheduledFor: n.ScheduledFor,
 		SentAt:       n.SentAt,
 		RetryCount:   n.RetryCount,
 		MaxRetries:   n.MaxRetries,
 		LastError:    n.LastError,
+		Links:        n.Links,
+		WebCopyURL:   n.WebCopyURL,
+		DryRun:       n.DryRun,
+		JobID:        n.JobID,
+		RecurrenceID: n.RecurrenceID,
+
+		Fallbacks:  n.Fallbacks,
+		FallbackOf: n.FallbackOf,
+		FallbackID: n.FallbackID,
+
+		FanoutID: n.FanoutID,
+
+		DigestID: n.DigestID,
+		DigestOf: n.DigestOf,
+
+		NextAttemptAt: n.NextAttemptAt,
+
+		Deadline:       n.Deadline,
+		DeadlineMissed: n.DeadlineMissed,
+
+		AcknowledgedAt: n.AcknowledgedAt,
+		AcknowledgedBy: n.AcknowledgedBy,
+
+		PolicyViolations: n.PolicyViolations,
 	}
 }
 
+// StreamNotificationResult is the NDJSON response line for one line of a notification stream
+type StreamNotificationResult struct {
+	Line   int                 `json:"line"`   // 1-based line number in the request body
+	Status int                 `json:"status"` // HTTP status the line would have had as a single send
+	Result *NotificationResult `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// StreamSummary totals a notification stream
+type StreamSummary struct {
+	Lines    int    `json:"lines"`
+	Accepted int    `json:"accepted"`
+	Failed   int    `json:"failed"`
+	Error    string `json:"error,omitempty"` // Set if the request body couldn't be read to the end
+}
+
+// StreamSummaryLine is the last line of a notification stream response
+type StreamSummaryLine struct {
+	Summary StreamSummary `json:"summary"`
+}
+
+// AppriseRequest is a request to Apprise's stateless notification API
+type AppriseRequest struct {
+	URLs   string `json:"urls"`   // Apprise URLs separated by commas or whitespace
+	Title  string `json:"title"`  // Optional
+	Body   string `json:"body"`   // Required
+	Type   string `json:"type"`   // info (default), success, warning or failure
+	Format string `json:"format"` // text (default), markdown or html
+}
+
+// AppriseResponse reports what happened to each URL of an Apprise request
+type AppriseResponse struct {
+	Results []AppriseResult `json:"results"`
+}
+
+// AppriseResult is the outcome for one URL of an Apprise request
+type AppriseResult struct {
+	Type           string   `json:"type"`
+	Recipients     []string `json:"recipients,omitempty"`
+	NotificationID string   `json:"notification_id,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
 // NotificationResult represents the result of a notification operation
 type NotificationResult struct {
 	NotificationID   string                 `json:"notification_id"`
 	Success          bool                   `json:"success"`
 	Message          string                 `json:"message,omitempty"`
 	Error            string                 `json:"error,omitempty"`
+	Suppressed       bool                   `json:"suppressed,omitempty"` // A duplicate by dedup key; notification_id is the original's
+	Targets          []domain.TargetResult  `json:"targets,omitempty"`    // Per-target results of a fanned-out notification
 	SentAt           time.Time              `json:"sent_at"`
 	ProviderResponse map[string]interface{} `json:"provider_response,omitempty"`
+	Links            map[string]string      `json:"links,omitempty"`
 }
 
 // NotificationResultFromDomain converts a domain result to API format
@@ -169,18 +348,275 @@ func NotificationResultFromDomain(r *domain.NotificationResult) NotificationResu
 		Success:          r.Success,
 		Message:          r.Message,
 		Error:            r.Error,
+		Suppressed:       r.Suppressed,
+		Targets:          r.Targets,
 		SentAt:           r.SentAt,
 		ProviderResponse: r.ProviderResponse,
+		Links:            r.Links,
 	}
 }
 
-// ListNotificationsResponse is the REST API response for listing notifications
+// ListNotificationsResponse is the REST API response for listing notifications, newest first
 type ListNotificationsResponse struct {
-	Notifications []Notification `json:"notifications"`
-	Total         int64          `json:"total"`
+	Notifications []Notification        `json:"notifications"`
+	Total         int64                 `json:"total"`                 // Notifications matching the filter across all pages
+	NextCursor    string                `json:"next_cursor,omitempty"` // Pass as cursor to fetch the next page
+	Staleness     *domain.ReadStaleness `json:"staleness,omitempty"`   // Source of the listing, for stores that can read from a replica
 }
 
 // RetryNotificationResponse is the REST API response for retrying a notification
 type RetryNotificationResponse struct {
 	Result NotificationResult `json:"result"`
 }
+
+// RejectNotificationRequest is the REST API request for rejecting a held notification
+type RejectNotificationRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+// RescheduleNotificationRequest is the REST API request for delaying a notification waiting to
+// be sent. Exactly one of ScheduledFor and Delay is set.
+type RescheduleNotificationRequest struct {
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"` // New delivery time
+	Delay        string     `json:"delay,omitempty"`         // Or how long from now to deliver it (e.g., "2h")
+}
+
+// deliveryTime returns the new delivery time the request asks for
+func (r *RescheduleNotificationRequest) deliveryTime(now time.Time) (time.Time, error) {
+	switch {
+	case r.ScheduledFor != nil && r.Delay != "":
+		return time.Time{}, fmt.Errorf("set scheduled_for or delay, not both")
+	case r.ScheduledFor != nil:
+		return *r.ScheduledFor, nil
+	case r.Delay != "":
+		delay, err := time.ParseDuration(r.Delay)
+		if err != nil || delay <= 0 {
+			return time.Time{}, fmt.Errorf("invalid delay %q (must be a positive duration)", r.Delay)
+		}
+		return now.Add(delay), nil
+	default:
+		return time.Time{}, fmt.Errorf("scheduled_for or delay is required")
+	}
+}
+
+// SubmitSendJobRequest is the REST API request for starting a send job. The notification
+// is the template for every notification the job sends; its recipients come from Recipients.
+type SubmitSendJobRequest struct {
+	Notification              SendNotificationRequest `json:"notification"`
+	Recipients                []string                `json:"recipients"`
+	RecipientsPerNotification int                     `json:"recipients_per_notification,omitempty"` // Default 1
+	MaxInFlight               int                     `json:"max_in_flight,omitempty"`               // Default 1000
+}
+
+// Validate validates the request
+func (r *SubmitSendJobRequest) Validate() error {
+	if len(r.Recipients) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	if len(r.Notification.Recipients)+len(r.Notification.CC)+len(r.Notification.BCC) > 0 {
+		return fmt.Errorf("notification recipients, cc and bcc are not supported for send jobs; use recipients")
+	}
+	if r.RecipientsPerNotification < 0 || r.MaxInFlight < 0 {
+		return fmt.Errorf("recipients_per_notification and max_in_flight must not be negative")
+	}
+
+	template := r.Notification
+	template.Recipients = r.Recipients[:1]
+	return template.Validate()
+}
+
+// ListSendJobsResponse is the REST API response for listing send jobs
+type ListSendJobsResponse struct {
+	Jobs []*domain.SendJob `json:"jobs"`
+}
+
+// ListRecurringNotificationsResponse is the REST API response for listing recurring notifications
+type ListRecurringNotificationsResponse struct {
+	Recurring []*domain.RecurringNotification `json:"recurring"`
+}
+
+// ListHeartbeatsResponse is the REST API response for listing heartbeats
+type ListHeartbeatsResponse struct {
+	Heartbeats []*domain.Heartbeat `json:"heartbeats"`
+}
+
+// DispatchPauseRequest is the REST API request for pausing or resuming dispatch. An empty type
+// covers every notifier; an empty account covers every account of the type.
+type DispatchPauseRequest struct {
+	Type    string `json:"type,omitempty"`
+	Account string `json:"account,omitempty"`
+	Reason  string `json:"reason,omitempty"` // Ignored when resuming
+}
+
+// ListDispatchPausesResponse is the REST API response for listing dispatch pauses
+type ListDispatchPausesResponse struct {
+	Paused bool                    `json:"paused"` // Whether any pause is active
+	Pauses []*domain.DispatchPause `json:"pauses"`
+}
+
+// CreateBlackoutWindowRequest is the REST API request for creating a blackout window. An empty
+// type covers every notifier; an empty account covers every account of the type. The window
+// starts now unless a start is given, and ends at End or after Duration.
+type CreateBlackoutWindowRequest struct {
+	Type     string     `json:"type,omitempty"`
+	Account  string     `json:"account,omitempty"`
+	Start    *time.Time `json:"start,omitempty"`
+	End      *time.Time `json:"end,omitempty"`
+	Duration string     `json:"duration,omitempty"` // Or how long the window lasts (e.g., "2h")
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// window returns the blackout window the request asks for
+func (r *CreateBlackoutWindowRequest) window(now time.Time) (domain.BlackoutWindow, error) {
+	window := domain.BlackoutWindow{
+		Type:    domain.NotificationType(r.Type),
+		Account: r.Account,
+		Start:   now,
+		Reason:  r.Reason,
+	}
+	if r.Start != nil {
+		window.Start = *r.Start
+	}
+
+	switch {
+	case r.End != nil && r.Duration != "":
+		return window, fmt.Errorf("set end or duration, not both")
+	case r.End != nil:
+		window.End = *r.End
+	case r.Duration != "":
+		duration, err := time.ParseDuration(r.Duration)
+		if err != nil || duration <= 0 {
+			return window, fmt.Errorf("invalid duration %q (must be a positive duration)", r.Duration)
+		}
+		window.End = window.Start.Add(duration)
+	default:
+		return window, fmt.Errorf("end or duration is required")
+	}
+	return window, nil
+}
+
+// ListBlackoutWindowsResponse is the REST API response for listing blackout windows
+type ListBlackoutWindowsResponse struct {
+	Windows []*domain.BlackoutWindow `json:"windows"`
+}
+
+// PreviewLinkRequest is the REST API request for a notification preview link
+type PreviewLinkRequest struct {
+	TTL string `json:"ttl,omitempty"` // Link lifetime (e.g., "30m"); default from configuration
+}
+
+// PreviewLinkResponse is the REST API response with a notification preview link
+type PreviewLinkResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// InboundReplyResponse is the REST API response to an inbound email webhook
+type InboundReplyResponse struct {
+	Status         string `json:"status"` // delivered or ignored
+	NotificationID string `json:"notification_id,omitempty"`
+}
+
+// NotificationStatusesResponse is the REST API response for polling the status of many notifications
+type NotificationStatusesResponse struct {
+	Statuses map[string]string `json:"statuses"`          // Notification ID -> status
+	Missing  []string          `json:"missing,omitempty"` // IDs that are unknown or have expired
+}
+
+// TemplateRequest is the REST API request for creating or updating a template. When
+// updating, the name comes from the path.
+type TemplateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Type        string `json:"type,omitempty"` // Channel the template is for; empty renders for every channel
+	Subject     string `json:"subject,omitempty"`
+	Body        string `json:"body,omitempty"`
+	HTMLBody    string `json:"html_body,omitempty"`
+	Layout      string `json:"layout,omitempty"` // Email layout html_body is wrapped in, e.g. "responsive"
+
+	Variants map[domain.NotificationType]domain.TemplateVariant `json:"variants,omitempty"` // Channel-specific renderings
+	Locales  map[string]domain.TemplateLocale                   `json:"locales,omitempty"`  // Translations keyed by locale tag
+}
+
+// template converts the request to a domain template
+func (r *TemplateRequest) template() domain.Template {
+	return domain.Template{
+		Name:        r.Name,
+		Description: r.Description,
+		Type:        domain.NotificationType(r.Type),
+		Subject:     r.Subject,
+		Body:        r.Body,
+		HTMLBody:    r.HTMLBody,
+		Layout:      r.Layout,
+		Variants:    r.Variants,
+		Locales:     r.Locales,
+	}
+}
+
+// RenderTemplateRequest is the REST API request for rendering a template without sending it
+type RenderTemplateRequest struct {
+	Type         string                 `json:"type,omitempty"`   // Channel to render the variant for; empty uses the template's type
+	Locale       string                 `json:"locale,omitempty"` // Locale to render the translation for; empty uses the default locale
+	TemplateVars map[string]interface{} `json:"template_vars,omitempty"`
+}
+
+// ListTemplatesResponse is the REST API response for listing templates
+type ListTemplatesResponse struct {
+	Templates []*domain.Template `json:"templates"`
+}
+
+// ContactRequest is the REST API request for creating or updating a contact. When updating,
+// the name comes from the path.
+type ContactRequest struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Addresses   map[string][]string `json:"addresses,omitempty"` // Addresses keyed by notification type, e.g. {"email": ["dba@example.com"]}
+	Members     []string            `json:"members,omitempty"`   // Contacts in the group
+}
+
+// contact converts the request to a domain contact
+func (r *ContactRequest) contact() domain.Contact {
+	contact := domain.Contact{
+		Name:        r.Name,
+		Description: r.Description,
+		Members:     r.Members,
+	}
+	if len(r.Addresses) > 0 {
+		contact.Addresses = make(map[domain.NotificationType][]string, len(r.Addresses))
+		for notifType, addresses := range r.Addresses {
+			contact.Addresses[domain.NotificationType(notifType)] = addresses
+		}
+	}
+	return contact
+}
+
+// ListContactsResponse is the REST API response for listing contacts
+type ListContactsResponse struct {
+	Contacts []*domain.Contact `json:"contacts"`
+}
+
+// PreferencesRequest is the REST API request for setting a contact's preferences. The contact
+// comes from the path.
+type PreferencesRequest struct {
+	Channels        []string            `json:"channels,omitempty"`         // Channels the contact can be reached on; empty allows every channel
+	MutedCategories []string            `json:"muted_categories,omitempty"` // Categories the contact doesn't want
+	QuietHours      []domain.QuietHours `json:"quiet_hours,omitempty"`      // When notifications to the contact wait
+}
+
+// preferences converts the request to domain preferences for a contact
+func (r *PreferencesRequest) preferences(contact string) domain.Preferences {
+	prefs := domain.Preferences{
+		Contact:         contact,
+		MutedCategories: r.MutedCategories,
+		QuietHours:      r.QuietHours,
+	}
+	for _, channel := range r.Channels {
+		prefs.Channels = append(prefs.Channels, domain.NotificationType(channel))
+	}
+	return prefs
+}
+
+// QuotaUsageResponse is the REST API response for a client's quota usage
+type QuotaUsageResponse struct {
+	Quotas []domain.QuotaUsage `json:"quotas"`
+}
@@ -0,0 +1,100 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSendOptionsValidation tests that options are checked against the target channel
+func TestSendOptionsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		typ     string
+		options *domain.SendOptions
+		wantErr string
+	}{
+		{name: "email", typ: "email", options: &domain.SendOptions{Email: &domain.EmailOptions{
+			ReplyTo: "support@example.com",
+			Headers: map[string]string{"List-Unsubscribe": "<mailto:unsubscribe@example.com>"},
+		}}},
+		{name: "email on postmark", typ: "postmark", options: &domain.SendOptions{Email: &domain.EmailOptions{ReplyTo: "support@example.com"}}},
+		{name: "slack", typ: "slack", options: &domain.SendOptions{Slack: &domain.SlackOptions{ThreadTS: "1700000000.000100", Icon: ":bell:"}}},
+		{name: "ntfy", typ: "ntfy", options: &domain.SendOptions{Ntfy: &domain.NtfyOptions{Tags: []string{"warning"}, Click: "https://example.com", Delay: "30m"}}},
+		{name: "wrong channel", typ: "ntfy", options: &domain.SendOptions{Slack: &domain.SlackOptions{ThreadTS: "1.2"}}, wantErr: "options.slack do not apply to ntfy"},
+		{name: "reserved header", typ: "email", options: &domain.SendOptions{Email: &domain.EmailOptions{Headers: map[string]string{"bcc": "x@example.com"}}}, wantErr: "can't set bcc"},
+		{name: "header injection", typ: "email", options: &domain.SendOptions{Email: &domain.EmailOptions{Headers: map[string]string{"X-Campaign": "a\r\nBcc: x@example.com"}}}, wantErr: "line break"},
+		{name: "bad reply-to", typ: "email", options: &domain.SendOptions{Email: &domain.EmailOptions{ReplyTo: "not an address"}}, wantErr: "reply_to"},
+		{name: "bad thread", typ: "slack", options: &domain.SendOptions{Slack: &domain.SlackOptions{ThreadTS: "yesterday"}}, wantErr: "thread_ts"},
+		{name: "bad icon", typ: "slack", options: &domain.SendOptions{Slack: &domain.SlackOptions{Icon: "bell"}}, wantErr: "icon"},
+		{name: "bad delay", typ: "ntfy", options: &domain.SendOptions{Ntfy: &domain.NtfyOptions{Delay: "soon"}}, wantErr: "delay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &SendNotificationRequest{Type: tt.typ, Body: "hello", Recipients: []string{"someone"}, Options: tt.options}
+			err := req.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestSendOptionsToNotification tests that options are carried to the notification and the
+// email reply-to address is applied
+func TestSendOptionsToNotification(t *testing.T) {
+	req := &SendNotificationRequest{
+		Type:       "email",
+		Body:       "hello",
+		Recipients: []string{"user@example.com"},
+		Options:    &domain.SendOptions{Email: &domain.EmailOptions{ReplyTo: "support@example.com"}},
+	}
+
+	notification := req.ToNotification()
+	if notification.ReplyTo != "support@example.com" {
+		t.Errorf("Expected the reply-to address from options, got %q", notification.ReplyTo)
+	}
+	if notification.Options != req.Options {
+		t.Error("Expected the options on the notification")
+	}
+}
+
+// TestSendTargetsValidation tests fan-out targets in send requests, and that each target's
+// options block is checked against its channel
+func TestSendTargetsValidation(t *testing.T) {
+	options := &domain.SendOptions{
+		Slack: &domain.SlackOptions{Icon: ":fire:"},
+		Email: &domain.EmailOptions{ReplyTo: "support@example.com"},
+	}
+	tests := []struct {
+		name    string
+		req     SendNotificationRequest
+		wantErr string
+	}{
+		{name: "targets", req: SendNotificationRequest{Body: "x", Recipients: []string{"ops"}, Options: options,
+			Targets: []domain.Target{{Type: "slack", Account: "ops"}, {Type: "email", Recipients: []string{"oncall@example.com"}}}}},
+		{name: "own recipients", req: SendNotificationRequest{Body: "x", Targets: []domain.Target{{Type: "slack", Recipients: []string{"#ops"}}}}},
+		{name: "no type or targets", req: SendNotificationRequest{Body: "x", Recipients: []string{"ops"}}, wantErr: "type or targets"},
+		{name: "type with targets", req: SendNotificationRequest{Type: "slack", Body: "x", Recipients: []string{"ops"}, Targets: []domain.Target{{Type: "email"}}}, wantErr: "can't be combined"},
+		{name: "no recipients", req: SendNotificationRequest{Body: "x", Targets: []domain.Target{{Type: "slack"}}}, wantErr: "recipient or contact"},
+		{name: "bad options", req: SendNotificationRequest{Body: "x", Recipients: []string{"ops"}, Options: &domain.SendOptions{Slack: &domain.SlackOptions{Icon: "fire"}},
+			Targets: []domain.Target{{Type: "slack"}}}, wantErr: "icon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
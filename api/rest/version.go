@@ -0,0 +1,14 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// serveVersion handles GET /api/v1/version
+func serveVersion(info *domain.VersionInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, info)
+	}
+}
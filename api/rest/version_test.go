@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// TestVersionEndpoint tests that build and feature information is served when configured
+func TestVersionEndpoint(t *testing.T) {
+	factory := notifier.NewFactory()
+	q, err := queue.NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	logger := logging.New(logging.ErrorLevel, os.Stderr)
+	svc := service.NewNotificationService(factory, q, 1, nil, nil, logger)
+
+	rec := httptest.NewRecorder()
+	NewRouter(svc, logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	if rec.Code == http.StatusOK {
+		t.Error("Expected no version route without version information")
+	}
+
+	info := &domain.VersionInfo{
+		Version:   "1.4.0",
+		GitCommit: "abc1234",
+		BuildTime: "2026-10-01T12:00:00Z",
+		Profile:   "full",
+		Features:  []string{"auth", "grpc", "strict"},
+		Notifiers: []domain.NotificationType{domain.TypeStdout, domain.TypeEmail},
+	}
+	router := NewRouterWithOptions(svc, logger, RouterOptions{Version: info})
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Version returned %d: %s", rec.Code, rec.Body.String())
+	}
+	var got domain.VersionInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got.Version != "1.4.0" || got.GitCommit != "abc1234" || got.Profile != "full" {
+		t.Errorf("Unexpected build info: %+v", got)
+	}
+	if len(got.Features) != 3 || got.Features[2] != "strict" || len(got.Notifiers) != 2 {
+		t.Errorf("Unexpected features or notifiers: %+v", got)
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// completionCommand describes a command and its flags for shell completion
+type completionCommand struct {
+	name  string
+	desc  string
+	flags []string
+}
+
+// completionCommands lists the commands offered by shell completion. Keep in sync with main.
+var completionCommands = []completionCommand{
+	{"send", "Send a notification", []string{"url", "key", "profile", "type", "subject", "body", "account", "recipients", "origin", "origin-user", "output", "timeout"}},
+	{"status", "Get notification status", []string{"url", "key", "profile", "id", "output", "timeout"}},
+	{"list", "List notifications", []string{"url", "key", "profile", "type", "status", "limit", "offset", "cursor", "output", "timeout"}},
+	{"stats", "Get notification statistics", []string{"url", "key", "profile", "output", "timeout"}},
+	{"notifiers", "List available notifiers", []string{"url", "key", "profile", "output", "timeout"}},
+	{"watch", "Stream live notification status changes", []string{"url", "key", "profile", "id", "type", "origin", "output"}},
+	{"health", "Check service health", []string{"url", "profile", "timeout"}},
+	{"loadtest", "Drive synthetic traffic and report throughput and latency", []string{"url", "key", "profile", "rate", "duration", "concurrency", "type", "account", "recipients", "body", "origin", "timeout", "output"}},
+	{"keygen", "Generate a key pair for end-to-end encrypted notifications", []string{"vapid"}},
+	{"decrypt", "Decrypt an end-to-end encrypted notification", []string{"private-key"}},
+	{"profile", "Manage named server profiles", nil},
+	{"completion", "Generate shell completion scripts", nil},
+}
+
+// notificationTypes are offered when completing --type
+var notificationTypes = []string{"stdout", "email", "slack", "ntfy", "discord", "pagerduty", "fcm", "webpush", "xmpp", "whatsapp", "postmark", "amqp", "file", "bark", "dingtalk", "sms"}
+
+// profileCommands are the subcommands of profile
+var profileCommands = []string{"list", "set", "use", "delete"}
+
+// completionShells are the shells completion scripts can be generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// flagValues returns the fixed values offered when completing a flag
+func flagValues(flag string) []string {
+	switch flag {
+	case "type":
+		return notificationTypes
+	case "status":
+		return []string{"pending", "scheduled", "queued", "processing", "sent", "failed", "retrying", "held", "paused"}
+	case "output":
+		return []string{outputJSON, outputTable, outputYAML}
+	}
+	return nil
+}
+
+func cmdCompletion(args []string) {
+	if len(args) != 1 {
+		fmt.Print(`Generate shell completion scripts
+
+Usage:
+  client completion bash|zsh|fish
+
+Examples:
+  # bash (add to ~/.bashrc)
+  source <(client completion bash)
+
+  # zsh (add to ~/.zshrc after compinit)
+  source <(client completion zsh)
+
+  # fish
+  client completion fish > ~/.config/fish/completions/client.fish
+`)
+		os.Exit(1)
+	}
+
+	prog := filepath.Base(os.Args[0])
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion(prog))
+	case "zsh":
+		fmt.Print(zshCompletion(prog))
+	case "fish":
+		fmt.Print(fishCompletion(prog))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported shell %q (supported: %s)\n", args[0], strings.Join(completionShells, ", "))
+		os.Exit(1)
+	}
+}
+
+// completionFunc turns a program name into a shell function name
+func completionFunc(prog string) string {
+	return "_" + strings.NewReplacer("-", "_", ".", "_").Replace(prog)
+}
+
+// flagList renders flag names with their leading dashes
+func flagList(flags []string) string {
+	dashed := make([]string, len(flags))
+	for i, flag := range flags {
+		dashed[i] = "--" + flag
+	}
+	return strings.Join(dashed, " ")
+}
+
+// commandNames returns the names of all commands
+func commandNames() []string {
+	names := make([]string, len(completionCommands))
+	for i, cmd := range completionCommands {
+		names[i] = cmd.name
+	}
+	return names
+}
+
+func bashCompletion(prog string) string {
+	fn := completionFunc(prog)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", prog)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" cmd=\"${COMP_WORDS[1]}\" opts=\"\"\n\n")
+	b.WriteString("    if [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(commandNames(), " "))
+	b.WriteString("        return\n    fi\n\n")
+
+	b.WriteString("    case \"$prev\" in\n")
+	fmt.Fprintf(&b, "        --profile) COMPREPLY=($(compgen -W \"$(%s profile names 2>/dev/null)\" -- \"$cur\")); return ;;\n", prog)
+	for _, flag := range []string{"type", "status", "output"} {
+		fmt.Fprintf(&b, "        --%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", flag, strings.Join(flagValues(flag), " "))
+	}
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    case \"$cmd\" in\n")
+	for _, cmd := range completionCommands {
+		if len(cmd.flags) > 0 {
+			fmt.Fprintf(&b, "        %s) opts=%q ;;\n", cmd.name, flagList(cmd.flags))
+		}
+	}
+	b.WriteString("        profile)\n")
+	b.WriteString("            if [[ $COMP_CWORD -eq 2 ]]; then\n")
+	fmt.Fprintf(&b, "                opts=%q\n", strings.Join(profileCommands, " "))
+	b.WriteString("            elif [[ $COMP_CWORD -eq 3 && \"$prev\" != list ]]; then\n")
+	fmt.Fprintf(&b, "                opts=\"$(%s profile names 2>/dev/null)\"\n", prog)
+	b.WriteString("            else\n                opts=\"--url --key\"\n            fi ;;\n")
+	fmt.Fprintf(&b, "        completion) opts=%q ;;\n", strings.Join(completionShells, " "))
+	b.WriteString("    esac\n\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$opts\" -- \"$cur\"))\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F %s %s\n", fn, prog)
+	return b.String()
+}
+
+func zshCompletion(prog string) string {
+	fn := completionFunc(prog)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n", prog)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local -a commands\n    commands=(\n")
+	for _, cmd := range completionCommands {
+		fmt.Fprintf(&b, "        '%s:%s'\n", cmd.name, cmd.desc)
+	}
+	b.WriteString("    )\n\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n        _describe 'command' commands\n        return\n    fi\n\n")
+
+	b.WriteString("    case $words[CURRENT-1] in\n")
+	fmt.Fprintf(&b, "        --profile) compadd -- ${(f)\"$(%s profile names 2>/dev/null)\"}; return ;;\n", prog)
+	for _, flag := range []string{"type", "status", "output"} {
+		fmt.Fprintf(&b, "        --%s) compadd -- %s; return ;;\n", flag, strings.Join(flagValues(flag), " "))
+	}
+	b.WriteString("    esac\n\n")
+
+	b.WriteString("    case $words[2] in\n")
+	for _, cmd := range completionCommands {
+		if len(cmd.flags) > 0 {
+			fmt.Fprintf(&b, "        %s) compadd -- %s ;;\n", cmd.name, flagList(cmd.flags))
+		}
+	}
+	b.WriteString("        profile)\n")
+	b.WriteString("            if (( CURRENT == 3 )); then\n")
+	fmt.Fprintf(&b, "                compadd -- %s\n", strings.Join(profileCommands, " "))
+	b.WriteString("            elif (( CURRENT == 4 )) && [[ $words[3] != list ]]; then\n")
+	fmt.Fprintf(&b, "                compadd -- ${(f)\"$(%s profile names 2>/dev/null)\"}\n", prog)
+	b.WriteString("            else\n                compadd -- --url --key\n            fi ;;\n")
+	fmt.Fprintf(&b, "        completion) compadd -- %s ;;\n", strings.Join(completionShells, " "))
+	b.WriteString("    esac\n")
+	b.WriteString("}\n\n")
+
+	// Works both when autoloaded from $fpath and when sourced
+	fmt.Fprintf(&b, "if [ \"$funcstack[1]\" = \"%s\" ]; then\n    %s \"$@\"\nelse\n    compdef %s %s\nfi\n", fn, fn, fn, prog)
+	return b.String()
+}
+
+func fishCompletion(prog string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# fish completion for %s\n", prog)
+	fmt.Fprintf(&b, "complete -c %s -f\n\n", prog)
+
+	for _, cmd := range completionCommands {
+		fmt.Fprintf(&b, "complete -c %s -n __fish_use_subcommand -a %s -d '%s'\n", prog, cmd.name, cmd.desc)
+	}
+	b.WriteString("\n")
+
+	for _, cmd := range completionCommands {
+		condition := fmt.Sprintf("'__fish_seen_subcommand_from %s'", cmd.name)
+		for _, flag := range cmd.flags {
+			switch {
+			case flag == "profile":
+				fmt.Fprintf(&b, "complete -c %s -n %s -l profile -x -a '(%s profile names 2>/dev/null)'\n", prog, condition, prog)
+			case flagValues(flag) != nil:
+				fmt.Fprintf(&b, "complete -c %s -n %s -l %s -x -a '%s'\n", prog, condition, flag, strings.Join(flagValues(flag), " "))
+			default:
+				fmt.Fprintf(&b, "complete -c %s -n %s -l %s -r\n", prog, condition, flag)
+			}
+		}
+	}
+	b.WriteString("\n")
+
+	profiles := strings.Join(profileCommands, " ")
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from profile; and not __fish_seen_subcommand_from %s' -a '%s'\n", prog, profiles, profiles)
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from set use delete' -a '(%s profile names 2>/dev/null)'\n", prog, prog)
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from set' -l url -r\n", prog)
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from set' -l key -r\n", prog)
+	fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from completion' -a '%s'\n", prog, strings.Join(completionShells, " "))
+	return b.String()
+}
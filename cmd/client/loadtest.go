@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/igodwin/notifier/pkg/client"
+)
+
+// loadTestReport summarises a load test run
+type loadTestReport struct {
+	Target     string          `json:"target"`
+	Type       string          `json:"type"`
+	Duration   string          `json:"duration"`    // Time actually spent sending
+	TargetRate float64         `json:"target_rate"` // Requests per second asked for
+	Sent       int             `json:"sent"`
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	Skipped    int             `json:"skipped"`    // Not sent because --concurrency requests were in flight
+	Throughput float64         `json:"throughput"` // Successful requests per second achieved
+	Latency    loadTestLatency `json:"latency"`
+	Errors     map[string]int  `json:"errors,omitempty"` // Failures by status code or cause
+}
+
+// loadTestLatency holds request latency percentiles in milliseconds
+type loadTestLatency struct {
+	Min  float64 `json:"min_ms"`
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P90  float64 `json:"p90_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// loadTestRecorder collects the outcome of each request
+type loadTestRecorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failed    int
+	errors    map[string]int
+}
+
+func cmdLoadTest(args []string) {
+	fs := flag.NewFlagSet("loadtest", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Print(`Drive synthetic notification traffic at a fixed rate and report throughput,
+latency percentiles and errors. Interrupt to stop early and report.
+
+Usage:
+  client loadtest [options]
+
+Options:
+  --url          Service URL (default: http://localhost:8080)
+  --key          API key (optional)
+  --profile      Named server profile (default: current profile)
+  --rate         Requests per second (default: 100)
+  --duration     How long to send for (default: 1m)
+  --concurrency  Most requests in flight before sends are skipped (default: 200)
+  --type         Notification type (default: stdout)
+  --account      Account name (optional, uses default)
+  --recipients   Comma-separated recipients
+  --body         Message body (default: "notifier load test")
+  --origin       Sending system (default: loadtest)
+  --timeout      Per-request timeout (default: 10s)
+  --output       Output format: json, table or yaml (default: table)
+
+Example:
+  client loadtest --rate 500 --duration 5m --type stdout
+`)
+	}
+
+	conn := addConnectionFlags(fs)
+	rate := fs.Float64("rate", 100, "")
+	duration := fs.Duration("duration", time.Minute, "")
+	concurrency := fs.Int("concurrency", 200, "")
+	notifType := fs.String("type", "stdout", "")
+	account := fs.String("account", "", "")
+	recipients := fs.String("recipients", "", "")
+	body := fs.String("body", "notifier load test", "")
+	origin := fs.String("origin", "loadtest", "")
+	timeout := fs.Duration("timeout", 10*time.Second, "")
+	output := fs.String("output", outputTable, "")
+
+	fs.Parse(args)
+	checkOutputFormat(fs, *output)
+
+	if *rate <= 0 || *duration <= 0 || *concurrency <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: --rate, --duration and --concurrency must be positive\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	baseURL, apiKey := conn.resolve(fs)
+
+	req := client.NotificationRequest{
+		Type:    *notifType,
+		Subject: "Load test",
+		Body:    *body,
+		Account: *account,
+		Origin:  &client.Origin{System: *origin},
+	}
+	if *recipients != "" {
+		for _, recipient := range strings.Split(*recipients, ",") {
+			req.Recipients = append(req.Recipients, strings.TrimSpace(recipient))
+		}
+	}
+	payload, err := json.Marshal(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to encode request: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	ctx, cancelRun := context.WithTimeout(ctx, *duration)
+	defer cancelRun()
+
+	// One attempt per request: the REST client's retries would hide errors and skew latency
+	httpClient := &http.Client{
+		Timeout: *timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        *concurrency,
+			MaxIdleConnsPerHost: *concurrency,
+		},
+	}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/api/v1/notifications"
+
+	fmt.Fprintf(os.Stderr, "Sending %s notifications to %s at %g/s for %s...\n", *notifType, baseURL, *rate, *duration)
+
+	recorder := &loadTestRecorder{errors: make(map[string]int)}
+	inFlight := make(chan struct{}, *concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rate))
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	sent, skipped := 0, 0
+	start := time.Now()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			skipped++
+			continue
+		}
+		sent++
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			recorder.record(sendLoadTestRequest(httpClient, endpoint, apiKey, payload))
+		}()
+	}
+	elapsed := time.Since(start)
+	wg.Wait()
+
+	report := recorder.report(elapsed)
+	report.Target = baseURL
+	report.Type = *notifType
+	report.TargetRate = *rate
+	report.Sent = sent
+	report.Skipped = skipped
+
+	printOutput(*output, report, loadTestTable(report))
+	if report.Failed > 0 || report.Skipped > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadTestOutcome is the result of one request: its latency and, if it failed, why
+type loadTestOutcome struct {
+	latency time.Duration
+	failure string
+}
+
+// sendLoadTestRequest posts one notification, classifying any failure by status code or cause.
+// Requests still in flight when the run ends complete on their own timeout.
+func sendLoadTestRequest(httpClient *http.Client, endpoint, apiKey string, payload []byte) loadTestOutcome {
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return loadTestOutcome{failure: "invalid request"}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return loadTestOutcome{latency: time.Since(start), failure: "timeout"}
+		}
+		return loadTestOutcome{latency: time.Since(start), failure: "connection error"}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return loadTestOutcome{latency: latency, failure: fmt.Sprintf("HTTP %d", resp.StatusCode)}
+	}
+	return loadTestOutcome{latency: latency}
+}
+
+// record adds a request's outcome. Latency is only kept for successful requests, so fast
+// rejections don't flatter the percentiles.
+func (r *loadTestRecorder) record(outcome loadTestOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if outcome.failure != "" {
+		r.failed++
+		r.errors[outcome.failure]++
+		return
+	}
+	r.latencies = append(r.latencies, outcome.latency)
+}
+
+// report computes throughput and latency percentiles over the run
+func (r *loadTestRecorder) report(elapsed time.Duration) *loadTestReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &loadTestReport{
+		Duration:   elapsed.Round(time.Millisecond).String(),
+		Succeeded:  len(r.latencies),
+		Failed:     r.failed,
+		Throughput: math.Round(float64(len(r.latencies))/elapsed.Seconds()*10) / 10,
+	}
+	if len(r.errors) > 0 {
+		report.Errors = r.errors
+	}
+	if len(r.latencies) == 0 {
+		return report
+	}
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	var total time.Duration
+	for _, latency := range r.latencies {
+		total += latency
+	}
+	report.Latency = loadTestLatency{
+		Min:  milliseconds(r.latencies[0]),
+		Mean: milliseconds(total / time.Duration(len(r.latencies))),
+		P50:  milliseconds(percentile(r.latencies, 0.50)),
+		P90:  milliseconds(percentile(r.latencies, 0.90)),
+		P99:  milliseconds(percentile(r.latencies, 0.99)),
+		Max:  milliseconds(r.latencies[len(r.latencies)-1]),
+	}
+	return report
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// milliseconds converts a duration to milliseconds rounded to 0.01ms
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
+
+// loadTestTable renders a load test report as field/value rows followed by the error breakdown
+func loadTestTable(report *loadTestReport) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintf(w, "Target\t%s\n", report.Target)
+		fmt.Fprintf(w, "Type\t%s\n", report.Type)
+		fmt.Fprintf(w, "Duration\t%s\n", report.Duration)
+		fmt.Fprintf(w, "Rate\t%g/s target, %g/s achieved\n", report.TargetRate, report.Throughput)
+		fmt.Fprintf(w, "Requests\t%d sent, %d succeeded, %d failed, %d skipped\n", report.Sent, report.Succeeded, report.Failed, report.Skipped)
+		fmt.Fprintf(w, "Latency\tmin %.2fms, mean %.2fms, p50 %.2fms, p90 %.2fms, p99 %.2fms, max %.2fms\n",
+			report.Latency.Min, report.Latency.Mean, report.Latency.P50, report.Latency.P90, report.Latency.P99, report.Latency.Max)
+		for _, cause := range sortedErrorKeys(report.Errors) {
+			fmt.Fprintf(w, "Error\t%s: %d\n", cause, report.Errors[cause])
+		}
+	}
+}
+
+// sortedErrorKeys returns the failure causes in order
+func sortedErrorKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
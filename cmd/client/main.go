@@ -2,14 +2,18 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/igodwin/notifier/internal/notifier"
 	"github.com/igodwin/notifier/pkg/client"
+	"github.com/igodwin/notifier/pkg/e2e"
 )
 
 func main() {
@@ -32,8 +36,20 @@ func main() {
 		cmdStats(os.Args[2:])
 	case "notifiers":
 		cmdNotifiers(os.Args[2:])
+	case "watch":
+		cmdWatch(os.Args[2:])
 	case "health":
 		cmdHealth(os.Args[2:])
+	case "loadtest":
+		cmdLoadTest(os.Args[2:])
+	case "keygen":
+		cmdKeygen(os.Args[2:])
+	case "decrypt":
+		cmdDecrypt(os.Args[2:])
+	case "profile":
+		cmdProfile(os.Args[2:])
+	case "completion":
+		cmdCompletion(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
 		printUsage()
@@ -53,12 +69,20 @@ Commands:
   list       List notifications
   stats      Get notification statistics
   notifiers  List available notifiers
+  watch      Stream live notification status changes
   health     Check service health
+  loadtest   Drive synthetic traffic and report throughput and latency
+  keygen     Generate a key pair for end-to-end encrypted notifications
+  decrypt    Decrypt an end-to-end encrypted notification
+  profile    Manage named server profiles
+  completion Generate shell completion scripts (bash, zsh, fish)
 
 Global Options:
   --url      Service URL (default: http://localhost:8080)
   --key      API key for authentication (optional)
+  --profile  Named server profile from ~/.notifier/config (default: current profile)
   --timeout  Request timeout (default: 30s)
+  --output   Output format: json, table or yaml (default: json)
 
 Examples:
   # Send email notification
@@ -71,10 +95,21 @@ Examples:
   client list --limit 10
 
   # Get service stats
-  client stats
+  client stats --output table
+
+  # Follow deliveries from one system during an incident
+  client watch --origin billing --output table
+
+  # Save staging and production servers, then switch between them
+  client profile set staging --url https://notifier.staging.example.com --key nk_xxxxx
+  client profile set prod --url https://notifier.example.com --key nk_yyyyy
+  client stats --profile prod
 
   # Check health
   client health --url http://localhost:8080
+
+  # Measure capacity at 500 notifications per second
+  client loadtest --rate 500 --duration 5m --type stdout
 `)
 }
 
@@ -89,25 +124,32 @@ Usage:
 Options:
   --url          Service URL (default: http://localhost:8080)
   --key          API key (optional)
-  --type         Notification type (stdout, email, slack, ntfy) - required
+  --profile      Named server profile (default: current profile)
+  --type         Notification type (stdout, email, slack, ntfy, discord, pagerduty, fcm, webpush, xmpp, whatsapp, postmark, amqp, file, bark, dingtalk, sms) - required
   --subject      Subject line
   --body         Message body - required
   --account      Account name (optional, uses default)
   --recipients   Comma-separated recipients
+  --origin       Sending system (optional, defaults to the API key's client)
+  --origin-user  User who triggered the notification (optional)
+  --output       Output format: json, table or yaml (default: json)
   --timeout      Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
-	apiKey := fs.String("key", "", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
+	output := fs.String("output", "json", "")
 	notifType := fs.String("type", "", "")
 	subject := fs.String("subject", "", "")
 	body := fs.String("body", "", "")
 	account := fs.String("account", "", "")
 	recipients := fs.String("recipients", "", "")
+	origin := fs.String("origin", "", "")
+	originUser := fs.String("origin-user", "", "")
 
 	fs.Parse(args)
+	checkOutputFormat(fs, *output)
 
 	if *notifType == "" || *body == "" {
 		fmt.Fprintf(os.Stderr, "Error: --type and --body are required\n")
@@ -118,9 +160,10 @@ Options:
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, apiKey := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
-		APIKey:      *apiKey,
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -142,6 +185,9 @@ Options:
 		Account:    *account,
 		Recipients: recipientList,
 	}
+	if *origin != "" || *originUser != "" {
+		req.Origin = &client.Origin{System: *origin, User: *originUser}
+	}
 
 	resp, err := c.Send(ctx, req)
 	if err != nil {
@@ -149,8 +195,7 @@ Options:
 		os.Exit(1)
 	}
 
-	data, _ := json.MarshalIndent(resp, "", "  ")
-	fmt.Println(string(data))
+	printOutput(*output, resp, sendTable(resp))
 }
 
 func cmdStatus(args []string) {
@@ -164,17 +209,20 @@ Usage:
 Options:
   --url       Service URL (default: http://localhost:8080)
   --key       API key (optional)
+  --profile   Named server profile (default: current profile)
   --id        Notification ID - required
+  --output    Output format: json, table or yaml (default: json)
   --timeout   Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
-	apiKey := fs.String("key", "", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
+	output := fs.String("output", "json", "")
 	id := fs.String("id", "", "")
 
 	fs.Parse(args)
+	checkOutputFormat(fs, *output)
 
 	if *id == "" {
 		fmt.Fprintf(os.Stderr, "Error: --id is required\n")
@@ -185,9 +233,10 @@ Options:
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, apiKey := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
-		APIKey:      *apiKey,
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -200,8 +249,7 @@ Options:
 		os.Exit(1)
 	}
 
-	data, _ := json.MarshalIndent(notif, "", "  ")
-	fmt.Println(string(data))
+	printOutput(*output, notif, notificationTable(notif))
 }
 
 func cmdList(args []string) {
@@ -215,30 +263,36 @@ Usage:
 Options:
   --url       Service URL (default: http://localhost:8080)
   --key       API key (optional)
+  --profile   Named server profile (default: current profile)
   --type      Filter by type (comma-separated)
   --status    Filter by status (comma-separated)
   --limit     Limit results (default: 10)
   --offset    Offset (default: 0)
+  --cursor    Continue after a previous page (its next_cursor)
+  --output    Output format: json, table or yaml (default: json)
   --timeout   Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
-	apiKey := fs.String("key", "", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
+	output := fs.String("output", "json", "")
 	filterType := fs.String("type", "", "")
 	filterStatus := fs.String("status", "", "")
 	limit := fs.Int("limit", 10, "")
 	offset := fs.Int("offset", 0, "")
+	cursor := fs.String("cursor", "", "")
 
 	fs.Parse(args)
+	checkOutputFormat(fs, *output)
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, apiKey := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
-		APIKey:      *apiKey,
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -248,6 +302,7 @@ Options:
 	filter := client.ListNotificationsRequest{
 		Limit:  *limit,
 		Offset: *offset,
+		Cursor: *cursor,
 	}
 
 	if *filterType != "" {
@@ -267,8 +322,7 @@ Options:
 		os.Exit(1)
 	}
 
-	data, _ := json.MarshalIndent(resp, "", "  ")
-	fmt.Println(string(data))
+	printOutput(*output, resp, listTable(resp))
 }
 
 func cmdStats(args []string) {
@@ -282,22 +336,26 @@ Usage:
 Options:
   --url       Service URL (default: http://localhost:8080)
   --key       API key (optional)
+  --profile   Named server profile (default: current profile)
+  --output    Output format: json, table or yaml (default: json)
   --timeout   Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
-	apiKey := fs.String("key", "", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
+	output := fs.String("output", "json", "")
 
 	fs.Parse(args)
+	checkOutputFormat(fs, *output)
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, apiKey := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
-		APIKey:      *apiKey,
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -310,8 +368,7 @@ Options:
 		os.Exit(1)
 	}
 
-	data, _ := json.MarshalIndent(stats, "", "  ")
-	fmt.Println(string(data))
+	printOutput(*output, stats, statsTable(stats))
 }
 
 func cmdNotifiers(args []string) {
@@ -325,22 +382,26 @@ Usage:
 Options:
   --url       Service URL (default: http://localhost:8080)
   --key       API key (optional)
+  --profile   Named server profile (default: current profile)
+  --output    Output format: json, table or yaml (default: json)
   --timeout   Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
-	apiKey := fs.String("key", "", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
+	output := fs.String("output", "json", "")
 
 	fs.Parse(args)
+	checkOutputFormat(fs, *output)
 
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, apiKey := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
-		APIKey:      *apiKey,
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -353,8 +414,68 @@ Options:
 		os.Exit(1)
 	}
 
-	data, _ := json.MarshalIndent(notifiers, "", "  ")
-	fmt.Println(string(data))
+	printOutput(*output, notifiers, notifiersTable(notifiers))
+}
+
+func cmdWatch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Print(`Stream live notification status changes until interrupted
+
+Usage:
+  client watch [options]
+
+Options:
+  --url       Service URL (default: http://localhost:8080)
+  --key       API key (optional)
+  --profile   Named server profile (default: current profile)
+  --id        Only these notification IDs (comma-separated)
+  --type      Only this notification type
+  --origin    Only this origin system
+  --output    Output format: json, table or yaml (default: table)
+`)
+	}
+
+	conn := addConnectionFlags(fs)
+	output := fs.String("output", outputTable, "")
+	ids := fs.String("id", "", "")
+	notifType := fs.String("type", "", "")
+	origin := fs.String("origin", "", "")
+
+	fs.Parse(args)
+	checkOutputFormat(fs, *output)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	baseURL, apiKey := conn.resolve(fs)
+	cfg := client.ClientConfig{
+		BaseURL:     baseURL,
+		APIKey:      apiKey,
+		TLSInsecure: false,
+	}
+
+	c := client.NewRESTClient(cfg)
+
+	filter := client.WatchRequest{
+		Type:   *notifType,
+		Origin: *origin,
+	}
+	if *ids != "" {
+		for _, id := range strings.Split(*ids, ",") {
+			filter.IDs = append(filter.IDs, strings.TrimSpace(id))
+		}
+	}
+
+	printStatusEventHeader(*output)
+	err := c.Watch(ctx, filter, func(event client.StatusEvent) error {
+		printStatusEvent(*output, event)
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func cmdHealth(args []string) {
@@ -367,11 +488,12 @@ Usage:
 
 Options:
   --url       Service URL (default: http://localhost:8080)
+  --profile   Named server profile (default: current profile)
   --timeout   Request timeout (default: 30s)
 `)
 	}
 
-	baseURL := fs.String("url", "http://localhost:8080", "")
+	conn := addConnectionFlags(fs)
 	timeout := fs.Duration("timeout", 30*time.Second, "")
 
 	fs.Parse(args)
@@ -379,8 +501,9 @@ Options:
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
+	baseURL, _ := conn.resolve(fs)
 	cfg := client.ClientConfig{
-		BaseURL:     *baseURL,
+		BaseURL:     baseURL,
 		Timeout:     *timeout,
 		TLSInsecure: false,
 	}
@@ -401,3 +524,80 @@ Options:
 		os.Exit(1)
 	}
 }
+
+func cmdKeygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Print(`Generate a key pair for end-to-end encrypted notifications
+
+Usage:
+  client keygen [options]
+
+Put the public key in the notifier config (e.g., ntfy encryption.topic_keys) and keep
+the private key on the receiving device.
+
+Options:
+  --vapid   Generate a VAPID key pair for the webpush notifier instead. The private key
+            goes in the notifier config and the public key is the applicationServerKey
+            browsers subscribe with.
+`)
+	}
+
+	vapid := fs.Bool("vapid", false, "")
+
+	fs.Parse(args)
+
+	generate := e2e.GenerateKey
+	if *vapid {
+		generate = notifier.GenerateVAPIDKeys
+	}
+
+	publicKey, privateKey, err := generate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Public key:  %s\n", publicKey)
+	fmt.Printf("Private key: %s\n", privateKey)
+}
+
+func cmdDecrypt(args []string) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Print(`Decrypt an end-to-end encrypted notification
+
+Usage:
+  client decrypt [options] <message>
+
+Options:
+  --private-key   Base64 private key from keygen (default: $NOTIFIER_E2E_PRIVATE_KEY)
+`)
+	}
+
+	privateKey := fs.String("private-key", os.Getenv("NOTIFIER_E2E_PRIVATE_KEY"), "")
+
+	fs.Parse(args)
+
+	if *privateKey == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	key, err := e2e.ParsePrivateKey(*privateKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	payload, err := e2e.OpenPayload(key, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if payload.Title != "" {
+		fmt.Printf("Title:   %s\n", payload.Title)
+	}
+	fmt.Printf("Message: %s\n", payload.Message)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/igodwin/notifier/pkg/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by --output
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputYAML  = "yaml"
+)
+
+// checkOutputFormat exits with usage if the --output value isn't a known format
+func checkOutputFormat(fs *flag.FlagSet, format string) {
+	switch format {
+	case outputJSON, outputTable, outputYAML:
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Error: --output must be json, table or yaml\n")
+	fs.Usage()
+	os.Exit(1)
+}
+
+// printOutput writes v in the requested format, using table to render the table format
+func printOutput(format string, v interface{}, table func(w io.Writer)) {
+	switch format {
+	case outputTable:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		table(tw)
+		tw.Flush()
+	case outputYAML:
+		data, err := toYAML(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(string(data))
+	default:
+		data, _ := json.MarshalIndent(v, "", "  ")
+		fmt.Println(string(data))
+	}
+}
+
+// toYAML renders v as YAML with the same field names as its JSON form
+func toYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to encode output: %w", err)
+	}
+	return yaml.Marshal(generic)
+}
+
+// formatTime renders a timestamp for tables, or "-" if it isn't set
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// formatOrigin renders an origin for tables
+func formatOrigin(origin client.Origin) string {
+	if origin.System == "" {
+		return "-"
+	}
+	if origin.User != "" {
+		return origin.System + " (" + origin.User + ")"
+	}
+	return origin.System
+}
+
+// orDash returns s, or "-" if it's empty, so table columns stay aligned
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sortedKeys returns the keys of a count map in order
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sendTable renders a send result
+func sendTable(resp *client.NotificationResponse) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSUCCESS\tMESSAGE")
+		message := resp.Message
+		if !resp.Success {
+			message = resp.Error
+		}
+		fmt.Fprintf(w, "%s\t%t\t%s\n", resp.NotificationID, resp.Success, orDash(message))
+	}
+}
+
+// notificationTable renders one notification as field/value rows
+func notificationTable(notif *client.Notification) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintf(w, "ID\t%s\n", notif.ID)
+		fmt.Fprintf(w, "Type\t%s\n", notif.Type)
+		fmt.Fprintf(w, "Account\t%s\n", orDash(notif.Account))
+		fmt.Fprintf(w, "Status\t%s\n", notif.Status)
+		fmt.Fprintf(w, "Retries\t%d/%d\n", notif.RetryCount, notif.MaxRetries)
+		fmt.Fprintf(w, "Origin\t%s\n", formatOrigin(notif.Origin))
+		fmt.Fprintf(w, "Recipients\t%s\n", orDash(strings.Join(notif.Recipients, ", ")))
+		fmt.Fprintf(w, "Subject\t%s\n", orDash(notif.Subject))
+		fmt.Fprintf(w, "Created\t%s\n", formatTime(&notif.CreatedAt))
+		fmt.Fprintf(w, "Sent\t%s\n", formatTime(notif.SentAt))
+		if notif.LastError != "" {
+			fmt.Fprintf(w, "Last Error\t%s\n", notif.LastError)
+		}
+	}
+}
+
+// listTable renders one row per notification
+func listTable(resp *client.ListNotificationsResponse) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tTYPE\tSTATUS\tRETRIES\tORIGIN\tRECIPIENTS\tCREATED")
+		for _, notif := range resp.Notifications {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				notif.ID, notif.Type, notif.Status, notif.RetryCount, formatOrigin(notif.Origin),
+				orDash(strings.Join(notif.Recipients, ",")), formatTime(&notif.CreatedAt))
+		}
+		fmt.Fprintf(w, "\n%d of %d\n", len(resp.Notifications), resp.Total)
+		if resp.NextCursor != "" {
+			fmt.Fprintf(w, "Next page: --cursor %s\n", resp.NextCursor)
+		}
+	}
+}
+
+// statsTable renders counters followed by budget usage
+func statsTable(stats *client.NotificationStats) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintln(w, "METRIC\tCOUNT")
+		fmt.Fprintf(w, "sent\t%d\n", stats.TotalSent)
+		fmt.Fprintf(w, "failed\t%d\n", stats.TotalFailed)
+		fmt.Fprintf(w, "pending\t%d\n", stats.TotalPending)
+		fmt.Fprintf(w, "queued\t%d\n", stats.TotalQueued)
+		for _, key := range sortedKeys(stats.ByType) {
+			fmt.Fprintf(w, "type/%s\t%d\n", key, stats.ByType[key])
+		}
+		for _, key := range sortedKeys(stats.ByStatus) {
+			fmt.Fprintf(w, "status/%s\t%d\n", key, stats.ByStatus[key])
+		}
+
+		if len(stats.Budgets) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "BUDGET ORIGIN\tTYPE\tUSED\tLIMIT\tACTION\tEXCEEDED")
+			for _, budget := range stats.Budgets {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\t%t\n",
+					budget.Origin, orDash(budget.Type), budget.Used, budget.Limit, budget.Action, budget.Exceeded)
+			}
+		}
+	}
+}
+
+// notifiersTable renders one row per notifier type
+func notifiersTable(resp *client.NotifiersResponse) func(io.Writer) {
+	return func(w io.Writer) {
+		fmt.Fprintln(w, "TYPE\tACCOUNTS\tDEFAULT")
+		for _, info := range resp.Notifiers {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", info.Type, orDash(strings.Join(info.Accounts, ",")), orDash(info.DefaultAccount))
+		}
+	}
+}
+
+// statusEventFormat lays out watch rows with fixed widths, since rows are printed as they
+// arrive and can't be aligned after the fact
+const statusEventFormat = "%-8s  %-36s  %-10s  %-10s  %-7v  %-20s  %s\n"
+
+// printStatusEventHeader writes the table header for watch
+func printStatusEventHeader(format string) {
+	if format == outputTable {
+		fmt.Printf(statusEventFormat, "TIME", "ID", "TYPE", "STATUS", "RETRIES", "ORIGIN", "ERROR")
+	}
+}
+
+// printStatusEvent writes one status event as it arrives. JSON events are written one per
+// line and YAML events as separate documents so the output can be piped to other tools.
+func printStatusEvent(format string, event client.StatusEvent) {
+	switch format {
+	case outputTable:
+		fmt.Printf(statusEventFormat, event.At.Local().Format("15:04:05"), event.NotificationID, event.Type,
+			event.Status, event.RetryCount, formatOrigin(event.Origin), orDash(event.Error))
+	case outputYAML:
+		data, err := toYAML(event)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return
+		}
+		fmt.Printf("---\n%s", data)
+	default:
+		data, _ := json.Marshal(event)
+		fmt.Println(string(data))
+	}
+}
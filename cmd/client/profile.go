@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultServiceURL is used when neither --url nor a profile names a server
+const defaultServiceURL = "http://localhost:8080"
+
+// cliConfig is the CLI config file holding named server profiles
+type cliConfig struct {
+	Current  string              `yaml:"current,omitempty"` // Profile used when --profile isn't given
+	Profiles map[string]*profile `yaml:"profiles"`
+}
+
+// profile is a named server and the API key used with it
+type profile struct {
+	URL string `yaml:"url"`
+	Key string `yaml:"key,omitempty"`
+}
+
+// cliConfigPath returns the CLI config file location ($NOTIFIER_CONFIG or ~/.notifier/config)
+func cliConfigPath() (string, error) {
+	if path := os.Getenv("NOTIFIER_CONFIG"); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".notifier", "config"), nil
+}
+
+// loadCLIConfig reads the CLI config file, returning an empty config if it doesn't exist
+func loadCLIConfig() (*cliConfig, error) {
+	cfg := &cliConfig{Profiles: make(map[string]*profile)}
+
+	path, err := cliConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*profile)
+	}
+	return cfg, nil
+}
+
+// save writes the CLI config file. It holds API keys, so it's only readable by the user.
+func (c *cliConfig) save() error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// profileNames returns the configured profile names in order
+func (c *cliConfig) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// connectionFlags are the flags that choose which server to talk to
+type connectionFlags struct {
+	url     *string
+	key     *string
+	profile *string
+}
+
+// addConnectionFlags registers --url, --key and --profile on a command
+func addConnectionFlags(fs *flag.FlagSet) *connectionFlags {
+	return &connectionFlags{
+		url:     fs.String("url", defaultServiceURL, ""),
+		key:     fs.String("key", "", ""),
+		profile: fs.String("profile", os.Getenv("NOTIFIER_PROFILE"), ""),
+	}
+}
+
+// resolve returns the server URL and API key to use. Explicit --url and --key take
+// precedence over the selected profile (--profile, $NOTIFIER_PROFILE or the current
+// profile), which takes precedence over the defaults.
+func (f *connectionFlags) resolve(fs *flag.FlagSet) (string, string) {
+	explicit := make(map[string]bool)
+	fs.Visit(func(fl *flag.Flag) { explicit[fl.Name] = true })
+
+	baseURL, apiKey := *f.url, *f.key
+
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	name := *f.profile
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		return baseURL, apiKey
+	}
+
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown profile %q (available: %s)\n", name, strings.Join(cfg.profileNames(), ", "))
+		os.Exit(1)
+	}
+	if !explicit["url"] && p.URL != "" {
+		baseURL = p.URL
+	}
+	if !explicit["key"] {
+		apiKey = p.Key
+	}
+	return baseURL, apiKey
+}
+
+func cmdProfile(args []string) {
+	usage := func() {
+		fmt.Print(`Manage named server profiles stored in ~/.notifier/config ($NOTIFIER_CONFIG)
+
+Usage:
+  client profile list
+  client profile set <name> --url <url> [--key <api-key>]
+  client profile use <name>
+  client profile delete <name>
+
+Commands use the current profile unless --profile or $NOTIFIER_PROFILE selects
+another. --url and --key override the profile for a single command.
+`)
+	}
+
+	if len(args) == 0 {
+		usage()
+		os.Exit(1)
+	}
+
+	cfg, err := loadCLIConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		for _, name := range cfg.profileNames() {
+			marker := " "
+			if name == cfg.Current {
+				marker = "*"
+			}
+			p := cfg.Profiles[name]
+			key := "-"
+			if p.Key != "" {
+				key = maskKey(p.Key)
+			}
+			fmt.Printf("%s %-16s %-40s %s\n", marker, name, p.URL, key)
+		}
+		return
+
+	case "set":
+		fs := flag.NewFlagSet("profile set", flag.ExitOnError)
+		fs.Usage = usage
+		url := fs.String("url", "", "")
+		key := fs.String("key", "", "")
+		if len(args) < 2 || strings.HasPrefix(args[1], "-") {
+			usage()
+			os.Exit(1)
+		}
+		name := args[1]
+		fs.Parse(args[2:])
+
+		p, ok := cfg.Profiles[name]
+		if !ok {
+			if *url == "" {
+				fmt.Fprintf(os.Stderr, "Error: --url is required for a new profile\n")
+				os.Exit(1)
+			}
+			p = &profile{}
+			cfg.Profiles[name] = p
+		}
+		fs.Visit(func(fl *flag.Flag) {
+			switch fl.Name {
+			case "url":
+				p.URL = *url
+			case "key":
+				p.Key = *key
+			}
+		})
+		if cfg.Current == "" {
+			cfg.Current = name
+		}
+
+	case "use":
+		if len(args) != 2 {
+			usage()
+			os.Exit(1)
+		}
+		if _, ok := cfg.Profiles[args[1]]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown profile %q\n", args[1])
+			os.Exit(1)
+		}
+		cfg.Current = args[1]
+
+	case "delete":
+		if len(args) != 2 {
+			usage()
+			os.Exit(1)
+		}
+		if _, ok := cfg.Profiles[args[1]]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown profile %q\n", args[1])
+			os.Exit(1)
+		}
+		delete(cfg.Profiles, args[1])
+		if cfg.Current == args[1] {
+			cfg.Current = ""
+		}
+
+	case "names":
+		// Used by shell completion
+		for _, name := range cfg.profileNames() {
+			fmt.Println(name)
+		}
+		return
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown profile command: %s\n", args[0])
+		usage()
+		os.Exit(1)
+	}
+
+	if err := cfg.save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// maskKey hides all but the start of an API key for display
+func maskKey(key string) string {
+	if len(key) <= 6 {
+		return "******"
+	}
+	return key[:6] + "******"
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/archive"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/objectstore"
+	"github.com/igodwin/notifier/internal/store"
+)
+
+const archiveUsage = `Usage: server archive list
+       server archive inspect [--json] ARCHIVE
+       server archive restore ARCHIVE
+
+Reads notification archives written to archive.s3. ARCHIVE is an object key or
+name shown by list, or a downloaded archive file.
+
+  list      List the archives in the bucket
+  inspect   Summarize an archive and list its notifications (--json prints them
+            as JSONL instead)
+  restore   Save an archive's notifications back into the PostgreSQL
+            notification store (store.postgres.url), replacing any with the
+            same ID. Restored notifications older than archive.after are
+            archived again at the next run while archiving is enabled.`
+
+// runArchive runs the archive subcommand and returns the process exit code
+func runArchive(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "list":
+		return runArchiveList(args[1:])
+	case "inspect":
+		return runArchiveInspect(args[1:])
+	case "restore":
+		return runArchiveRestore(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+}
+
+// runArchiveList prints the archives in the configured bucket
+func runArchiveList(args []string) int {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+
+	client, _, code := archiveClient()
+	if client == nil {
+		return code
+	}
+	objects, err := client.List(context.Background(), archive.FilePrefix)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to list archives: %v\n", err)
+		return 1
+	}
+
+	for _, object := range objects {
+		fmt.Printf("%-64s  %10d  %s\n", object.Key, object.Size, object.LastModified.Format("2006-01-02 15:04:05 MST"))
+	}
+	fmt.Printf("%d archive(s) in %s\n", len(objects), client.Bucket())
+	return 0
+}
+
+// runArchiveInspect summarizes an archive and lists its notifications
+func runArchiveInspect(args []string) int {
+	fs := flag.NewFlagSet("archive inspect", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, archiveUsage) }
+	asJSON := fs.Bool("json", false, "Print notifications as JSONL")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+
+	notifications, err := readArchive(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read archive: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, notification := range notifications {
+			encoder.Encode(notification)
+		}
+		return 0
+	}
+
+	fmt.Printf("%d notification(s)", len(notifications))
+	if len(notifications) > 0 {
+		fmt.Printf(", created %s to %s",
+			notifications[0].CreatedAt.Format("2006-01-02 15:04:05 MST"),
+			notifications[len(notifications)-1].CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	}
+	fmt.Println()
+
+	counts := make(map[string]int)
+	for _, notification := range notifications {
+		counts[string(notification.Type)+"/"+string(notification.Status)]++
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  %-32s  %d\n", key, counts[key])
+	}
+	fmt.Println()
+
+	for _, notification := range notifications {
+		fmt.Printf("%s  %s  %-10s  %-7s  %s\n", notification.ID, notification.CreatedAt.Format("2006-01-02 15:04:05"),
+			notification.Type, notification.Status, strings.Join(notification.Recipients, ","))
+	}
+	return 0
+}
+
+// runArchiveRestore saves an archive's notifications into the PostgreSQL notification store
+func runArchiveRestore(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, archiveUsage)
+		return 2
+	}
+
+	// Read the whole archive before touching the store
+	notifications, err := readArchive(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Restore aborted: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+	if cfg.Store.Postgres.URL == "" {
+		fmt.Fprintln(os.Stderr, "No notification store to restore into (store.postgres.url)")
+		return 1
+	}
+	notificationStore, err := store.NewPostgresStore(cfg.Store.Postgres.URL, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open notification store: %v\n", err)
+		return 1
+	}
+	defer notificationStore.Close()
+
+	ctx := context.Background()
+	for i, notification := range notifications {
+		if err := notificationStore.Save(ctx, notification); err != nil {
+			fmt.Fprintf(os.Stderr, "Restore failed after %d of %d notifications: %v\n", i, len(notifications), err)
+			return 1
+		}
+	}
+
+	fmt.Printf("Restored %d notification(s) into %s\n", len(notifications), config.SanitizeDatabaseURL(cfg.Store.Postgres.URL))
+	return 0
+}
+
+// readArchive reads an archive from a local file, or from the configured bucket by key or name
+func readArchive(name string) ([]*domain.Notification, error) {
+	if info, err := os.Stat(name); err == nil && info.Mode().IsRegular() {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return archive.Decode(file)
+	}
+
+	client, prefix, _ := archiveClient()
+	if client == nil {
+		return nil, fmt.Errorf("%s is not a local file and no archive bucket is available", name)
+	}
+	key := name
+	if !strings.HasPrefix(key, prefix) {
+		key = prefix + key
+	}
+	data, err := client.Get(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	return archive.Decode(bytes.NewReader(data))
+}
+
+// archiveClient opens the configured archive bucket, returning its key prefix. On failure
+// it prints the reason and returns a nil client with the exit code to use.
+func archiveClient() (*objectstore.Client, string, int) {
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return nil, "", 1
+	}
+	if cfg.Archive.S3.Bucket == "" {
+		fmt.Fprintln(os.Stderr, "No archive bucket configured (archive.s3.bucket)")
+		return nil, "", 2
+	}
+	client, err := objectstore.New(cfg.Archive.S3)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid archive configuration: %v\n", err)
+		return nil, "", 1
+	}
+	return client, cfg.Archive.S3.Prefix, 0
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/igodwin/notifier/internal/backup"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+const backupUsage = `Usage: server backup [--output FILE]
+       server backup verify FILE
+
+Snapshots the API key database, the local queue's persisted messages (JSON
+backend only) and the configuration file into one archive with per-file checksums. Without --output
+the backup is written to backup.path, uploaded to backup.s3 if configured, and
+old backups beyond backup.retain are removed.`
+
+const restoreUsage = `Usage: server restore [--config PATH] FILE
+
+Verifies the archive, then replaces the API key database (auth.database.url) and
+the local queue state (queue.local.persist_path) with its contents. Stop the
+server first. The database must be migrated to the backup's schema version.
+
+Options:
+  --config PATH   Also restore the backed-up configuration file to PATH`
+
+// runBackup runs the backup subcommand and returns the process exit code
+func runBackup(args []string) int {
+	if len(args) > 0 && args[0] == "verify" {
+		return runBackupVerify(args[1:])
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, backupUsage) }
+	output := fs.String("output", "", "Write the backup to this file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	sources, closeDB, err := backupSources(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	if *output != "" {
+		manifest, err := backup.CreateFile(ctx, *output, sources)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Backup failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Backup written to %s (%d files)\n", *output, len(manifest.Files))
+		return 0
+	}
+
+	if cfg.Backup.Path == "" {
+		fmt.Fprintln(os.Stderr, "No backup destination: pass --output or set backup.path")
+		return 2
+	}
+	scheduler, err := backup.NewScheduler(cfg.Backup, sources, logging.New(logging.InfoLevel, os.Stdout))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid backup configuration: %v\n", err)
+		return 1
+	}
+	if _, err := scheduler.Backup(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Backup failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runBackupVerify checks an archive's integrity and prints its manifest
+func runBackupVerify(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, backupUsage)
+		return 2
+	}
+
+	archive, err := backup.OpenFile(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Verification failed: %v\n", err)
+		return 1
+	}
+
+	manifest := archive.Manifest
+	fmt.Printf("Backup OK: created %s", manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	if manifest.ServerVersion != "" {
+		fmt.Printf(" by version %s", manifest.ServerVersion)
+	}
+	if archive.HasDatabase() {
+		fmt.Printf(", schema version %d", manifest.SchemaVersion)
+	}
+	fmt.Println()
+	for _, file := range manifest.Files {
+		fmt.Printf("  %-32s  %10d  %s\n", file.Name, file.Size, file.SHA256)
+	}
+	return 0
+}
+
+// runRestore runs the restore subcommand and returns the process exit code
+func runRestore(args []string) int {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, restoreUsage) }
+	configPath := fs.String("config", "", "Restore the configuration file to this path")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, restoreUsage)
+		return 2
+	}
+
+	// Verify the whole archive before touching anything
+	archive, err := backup.OpenFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Restore aborted: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+
+	targets := backup.Targets{ConfigPath: *configPath, QueuePath: jsonQueuePath(cfg)}
+	if archive.HasDatabase() {
+		if cfg.Auth.Database.URL == "" {
+			fmt.Fprintln(os.Stderr, "Backup contains a database but none is configured (auth.database.url)")
+			return 1
+		}
+		db, err := sql.Open("postgres", cfg.Auth.Database.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+			return 1
+		}
+		defer db.Close()
+		targets.DB = db
+	}
+	if archive.HasQueue() && targets.QueuePath == "" {
+		fmt.Fprintln(os.Stderr, "Warning: backup contains queue state but the local queue isn't persisted to a JSON file; skipping it")
+	}
+
+	if err := archive.Restore(context.Background(), targets); err != nil {
+		fmt.Fprintf(os.Stderr, "Restore failed: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Restored backup from %s\n", archive.Manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	return 0
+}
+
+// backupSources locates the state to back up from the configuration. The returned function
+// closes the database connection, if one was opened.
+func backupSources(cfg *config.Config) (backup.Sources, func(), error) {
+	sources := backup.Sources{ServerVersion: Version, QueuePath: jsonQueuePath(cfg)}
+	// ConfigFile describes the source when no file was found, so only use it if it exists
+	if info, err := os.Stat(cfg.ConfigFile); err == nil && info.Mode().IsRegular() {
+		sources.ConfigPath = cfg.ConfigFile
+	}
+
+	if cfg.Auth.Database.URL == "" {
+		return sources, func() {}, nil
+	}
+	db, err := sql.Open("postgres", cfg.Auth.Database.URL)
+	if err != nil {
+		return sources, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	sources.DB = db
+	return sources, func() { db.Close() }, nil
+}
+
+// jsonQueuePath returns the local queue's state file when it is persisted as JSON. A bbolt
+// queue database is locked by the running server, so it isn't included in backups.
+func jsonQueuePath(cfg *config.Config) string {
+	local := cfg.Queue.Local
+	if local == nil || !local.PersistToDisk {
+		return ""
+	}
+	if local.PersistBackend != "" && local.PersistBackend != queue.PersistJSON {
+		return ""
+	}
+	return local.PersistPath
+}
@@ -0,0 +1,136 @@
+//go:build !lite
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	grpcapi "github.com/igodwin/notifier/api/grpc"
+	pb "github.com/igodwin/notifier/api/grpc/pb"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+const (
+	buildProfile  = "full"
+	grpcSupported = true
+)
+
+// grpcListener is the running gRPC server and its health service
+type grpcListener struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+// drain reports NOT_SERVING so load balancers stop sending new RPCs
+func (l *grpcListener) drain() {
+	l.health.Shutdown()
+}
+
+// stop waits for in-flight RPCs and stops the server
+func (l *grpcListener) stop() {
+	l.server.GracefulStop()
+}
+
+func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, svc domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore, jwtVerifier *auth.JWTVerifier, tenants *auth.TenantResolver, quotas *auth.Quotas) *grpcListener {
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.GRPCPort)
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		logger.Fatalf("Failed to listen on %s: %v", addr, err)
+	}
+
+	// Create gRPC server options
+	serverOpts, err := grpcKeepaliveOptions(cfg.Server.GRPC)
+	if err != nil {
+		logger.Fatalf("Invalid gRPC server config: %v", err)
+	}
+
+	// Add authentication interceptors if enabled
+	if authStore != nil {
+		authMiddleware := auth.NewGRPCAuthMiddleware(authStore, logger).WithJWT(jwtVerifier).WithTenants(tenants).WithQuotas(quotas)
+		serverOpts = append(serverOpts,
+			grpc.UnaryInterceptor(authMiddleware.UnaryInterceptor()),
+			grpc.StreamInterceptor(authMiddleware.StreamInterceptor()),
+		)
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+
+	// Create and register gRPC handler
+	grpcHandler := grpcapi.NewNotifierHandler(svc, logger)
+	grpcHandler.WithVersionInfo(versionInfo(cfg))
+	pb.RegisterNotifierServiceServer(grpcServer, grpcHandler)
+
+	// Register the standard health service for client load balancers and Kubernetes gRPC probes
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus(pb.NotifierService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	// Enable reflection for tools like grpcurl
+	reflection.Register(grpcServer)
+
+	logger.Info("Registered gRPC NotifierService")
+
+	go func() {
+		defer wg.Done()
+		logger.Infof("gRPC server listening on %s", addr)
+		if err := grpcServer.Serve(lis); err != nil {
+			logger.Fatalf("Failed to serve gRPC: %v", err)
+		}
+	}()
+
+	return &grpcListener{server: grpcServer, health: healthServer}
+}
+
+// grpcKeepaliveOptions builds the keepalive server parameters and enforcement policy.
+// Unset durations keep the gRPC defaults.
+func grpcKeepaliveOptions(cfg config.GRPCServerConfig) ([]grpc.ServerOption, error) {
+	var params keepalive.ServerParameters
+	fields := []struct {
+		value string
+		dst   *time.Duration
+	}{
+		{cfg.KeepaliveTime, &params.Time},
+		{cfg.KeepaliveTimeout, &params.Timeout},
+		{cfg.MaxConnectionIdle, &params.MaxConnectionIdle},
+		{cfg.MaxConnectionAge, &params.MaxConnectionAge},
+		{cfg.MaxConnectionAgeGrace, &params.MaxConnectionAgeGrace},
+	}
+	for _, field := range fields {
+		if field.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", field.value, err)
+		}
+		*field.dst = d
+	}
+
+	policy := keepalive.EnforcementPolicy{PermitWithoutStream: cfg.PermitWithoutStream}
+	if cfg.MinClientPingInterval != "" {
+		d, err := time.ParseDuration(cfg.MinClientPingInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", cfg.MinClientPingInterval, err)
+		}
+		policy.MinTime = d
+	}
+
+	return []grpc.ServerOption{
+		grpc.KeepaliveParams(params),
+		grpc.KeepaliveEnforcementPolicy(policy),
+	}, nil
+}
@@ -0,0 +1,31 @@
+//go:build lite
+
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+const (
+	buildProfile  = "lite"
+	grpcSupported = false
+)
+
+// grpcListener stands in for the gRPC server, which lite builds leave out
+type grpcListener struct{}
+
+func (l *grpcListener) drain() {}
+
+func (l *grpcListener) stop() {}
+
+// startGRPCServer is unreachable in lite builds, which switch server.mode to rest at startup
+func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, svc domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore, jwtVerifier *auth.JWTVerifier, tenants *auth.TenantResolver, quotas *auth.Quotas) *grpcListener {
+	logger.Fatal("gRPC is not included in lite builds")
+	return nil
+}
@@ -8,23 +8,25 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/gorilla/mux"
-	grpcapi "github.com/igodwin/notifier/api/grpc"
-	pb "github.com/igodwin/notifier/api/grpc/pb"
 	"github.com/igodwin/notifier/api/rest"
 	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/backup"
 	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/discovery"
 	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
 	"github.com/igodwin/notifier/internal/notifier"
 	"github.com/igodwin/notifier/internal/queue"
+	"github.com/igodwin/notifier/internal/resource"
 	"github.com/igodwin/notifier/internal/service"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/reflection"
+	"github.com/igodwin/notifier/internal/signing"
+	"github.com/igodwin/notifier/internal/store"
 )
 
 var (
@@ -35,13 +37,45 @@ var (
 	BuildTime = "unknown"
 )
 
+// versionInfo describes this build and the subsystems the config enables
+func versionInfo(cfg *config.Config) *domain.VersionInfo {
+	features := cfg.EnabledFeatures()
+	if grpcSupported && (cfg.Server.Mode == "both" || cfg.Server.Mode == "grpc") {
+		features = append(features, "grpc")
+		sort.Strings(features)
+	}
+	return &domain.VersionInfo{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		Profile:   buildProfile,
+		Features:  features,
+		Notifiers: cfg.GetEnabledNotifiers(),
+	}
+}
+
 func main() {
+	// Operator subcommands
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "backup" {
+		os.Exit(runBackup(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		os.Exit(runRestore(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		os.Exit(runArchive(os.Args[2:]))
+	}
+
 	// Print service identifier and build info
 	fmt.Printf("====================================\n")
 	fmt.Printf("Notifier Service\n")
 	fmt.Printf("Version:    %s\n", Version)
 	fmt.Printf("Git Commit: %s\n", GitCommit)
 	fmt.Printf("Build Time: %s\n", BuildTime)
+	fmt.Printf("Profile:    %s\n", buildProfile)
 	fmt.Printf("====================================\n")
 
 	// Load configuration
@@ -69,6 +103,15 @@ func main() {
 		logger.Infof("Configuration:\n%s", string(sanitized))
 	}
 
+	// Lite builds leave gRPC out, so they serve REST only
+	if !grpcSupported && cfg.Server.Mode != "rest" {
+		if cfg.Server.Mode == "grpc" {
+			logger.Fatal("gRPC is not included in lite builds; set server.mode to rest")
+		}
+		logger.Warn("gRPC is not included in lite builds; serving REST only")
+		cfg.Server.Mode = "rest"
+	}
+
 	logger.Infof("Starting Notifier Service in mode: %s", cfg.Server.Mode)
 
 	// Create context
@@ -76,21 +119,19 @@ func main() {
 	defer cancel()
 
 	// Initialize queue
-	var q domain.Queue
-	if cfg.Queue.Type == "local" {
-		q, err = queue.NewLocalQueue(cfg.Queue.Local)
-		if err != nil {
-			logger.Fatalf("Failed to create queue: %v", err)
-		}
-		logger.Info("Using local queue")
-	} else {
-		logger.Fatalf("Queue type %s not implemented yet", cfg.Queue.Type)
+	q, err := queue.New(&cfg.Queue)
+	if err != nil {
+		logger.Fatalf("Failed to create queue: %v", err)
 	}
+	logger.Infof("Using %s queue", cfg.Queue.Type)
 
 	// Initialize authentication if enabled (must be before service creation for RBAC)
 	var authStore *auth.APIKeyStore
 	var hybridKeyStore *auth.HybridKeyStore
 	var authz *auth.NotifierAuthz
+	var jwtVerifier *auth.JWTVerifier
+	var tenants *auth.TenantResolver
+	var quotas *auth.Quotas
 	if cfg.Auth.Enabled {
 		authStore = auth.NewAPIKeyStore()
 		authz = auth.NewNotifierAuthz()
@@ -99,11 +140,18 @@ func main() {
 		// Create database backend if configured
 		var dbStore *auth.KeyStoreDB
 		if cfg.Auth.Database.URL != "" {
-			dbStore, err = auth.NewKeyStoreDB(cfg.Auth.Database.URL)
+			dbStore, err = auth.NewKeyStoreDB(cfg.Auth.Database.URL, cfg.Auth.Database.AutoMigrate)
 			if err != nil {
 				logger.Fatalf("Failed to create database key store: %v", err)
 			}
 			logger.Infof("Connected to authentication database: %s", config.SanitizeDatabaseURL(cfg.Auth.Database.URL))
+
+			if cfg.Auth.Database.ReadURL != "" {
+				if err := dbStore.WithReadReplica(cfg.Auth.Database.ReadURL); err != nil {
+					logger.Fatalf("Failed to connect to read replica: %v", err)
+				}
+				logger.Infof("Using read replica for key listings: %s", config.SanitizeDatabaseURL(cfg.Auth.Database.ReadURL))
+			}
 		} else {
 			logger.Warn("No database configured for authentication - API keys will only be stored in memory")
 		}
@@ -112,6 +160,36 @@ func main() {
 		hybridKeyStore = auth.NewHybridKeyStore(authStore, dbStore)
 		logger.Debugf("Initialized hybrid key store for API key management")
 
+		// Accept JWT bearer tokens if configured
+		if cfg.Auth.JWT.Enabled {
+			jwtVerifier, err = newJWTVerifier(cfg.Auth.JWT)
+			if err != nil {
+				logger.Fatalf("Invalid JWT authentication config: %v", err)
+			}
+			if err := jwtVerifier.Refresh(ctx); err != nil {
+				logger.Warnf("Failed to fetch JWT signing keys, will retry on first use: %v", err)
+			}
+			logger.Infof("JWT authentication enabled: issuer=%s, audience=%v", cfg.Auth.JWT.Issuer, cfg.Auth.JWT.Audience)
+		}
+
+		// Assign clients to tenants if configured
+		if cfg.Tenancy.Enabled {
+			clients := make(map[string]string)
+			for name, tenant := range cfg.Tenancy.Tenants {
+				for _, client := range tenant.Clients {
+					clients[client] = name
+				}
+			}
+			tenants = auth.NewTenantResolver(clients)
+			logger.Infof("Tenancy enabled: tenants=%d, clients=%d", len(cfg.Tenancy.Tenants), len(clients))
+		}
+
+		// Limit each client's and tenant's requests and notifications if configured
+		if cfg.Quotas.Enabled {
+			quotas = auth.NewQuotas(quotaConfig(cfg.Quotas))
+			logger.Infof("Quotas enabled: rules=%d", len(cfg.Quotas.Rules))
+		}
+
 		// Bootstrap admin key if configured
 		if cfg.Auth.Bootstrap.Enabled {
 			bootstrapCfg := &auth.BootstrapConfig{
@@ -158,9 +236,19 @@ func main() {
 		}
 	}
 
+	// Load payload signing keys
+	var signer *signing.Keyring
+	if cfg.Signing.Enabled {
+		signer, err = signing.NewKeyring(cfg.Signing)
+		if err != nil {
+			logger.Fatalf("Failed to load signing keys: %v", err)
+		}
+		logger.Infof("Payload signing enabled with %d key(s)", len(signer.Keys()))
+	}
+
 	// Initialize notifier factory and register notifiers
 	factory := notifier.NewFactory()
-	registerNotifiers(cfg, factory, logger)
+	registerNotifiers(cfg, factory, signer, logger)
 
 	// Check if any notifiers are registered
 	if len(factory.SupportedTypes()) == 0 {
@@ -177,6 +265,37 @@ func main() {
 	// Create notification service (pass config as account resolver and authz for RBAC)
 	svc := service.NewNotificationService(factory, q, cfg.Queue.WorkerCount, cfg, authz, logger)
 
+	// Keep notifications in PostgreSQL or a file if configured, so their status survives restarts
+	switch cfg.Store.Type {
+	case "file":
+		flushInterval, _ := time.ParseDuration(cfg.Store.File.FlushInterval) // Checked by config validation
+		notificationStore, err := store.NewFileStore(cfg.Store.File.Path, flushInterval)
+		if err != nil {
+			logger.Fatalf("Failed to create notification store: %v", err)
+		}
+		defer func() {
+			if err := notificationStore.Close(); err != nil {
+				logger.Errorf("Failed to save notification store: %v", err)
+			}
+		}()
+		svc.WithNotificationStore(notificationStore)
+		logger.Infof("Using file notification store at %s", cfg.Store.File.Path)
+	case "postgres":
+		notificationStore, err := store.NewPostgresStore(cfg.Store.Postgres.URL, cfg.Store.Postgres.AutoMigrate)
+		if err != nil {
+			logger.Fatalf("Failed to create notification store: %v", err)
+		}
+		defer notificationStore.Close()
+		if cfg.Store.Postgres.ReadURL != "" {
+			if err := notificationStore.WithReadReplica(cfg.Store.Postgres.ReadURL); err != nil {
+				logger.Fatalf("Failed to connect to notification store read replica: %v", err)
+			}
+			logger.Infof("Using read replica for notification lookups and listings: %s", config.SanitizeDatabaseURL(cfg.Store.Postgres.ReadURL))
+		}
+		svc.WithNotificationStore(notificationStore)
+		logger.Infof("Using PostgreSQL notification store at %s", config.SanitizeDatabaseURL(cfg.Store.Postgres.URL))
+	}
+
 	// Configure notification retention if enabled
 	if err := svc.WithRetentionConfig(cfg.Retention); err != nil {
 		logger.Warnf("Failed to configure retention: %v", err)
@@ -187,6 +306,229 @@ func main() {
 			cfg.Retention.TTL, cfg.Retention.CheckFrequency, cfg.Retention.MaxSize)
 	}
 
+	// Archive completed notifications to object storage before retention prunes them
+	if err := svc.WithArchiveConfig(cfg.Archive); err != nil {
+		logger.Fatalf("Failed to configure archiving: %v", err)
+	} else if cfg.Archive.Enabled {
+		logger.Infof("Configured notification archiving: after=%s, interval=%s, bucket=%s",
+			cfg.Archive.After, cfg.Archive.Interval, cfg.Archive.S3.Bucket)
+	}
+
+	// Configure per-category queue disciplines
+	if err := svc.WithDispatchConfig(cfg.Dispatch); err != nil {
+		logger.Fatalf("Failed to configure dispatch: %v", err)
+	} else if cfg.Dispatch.Enabled() {
+		logger.Infof("Configured dispatch disciplines: default=%s, categories=%d",
+			cfg.Dispatch.DefaultDiscipline, len(cfg.Dispatch.Categories))
+	}
+
+	// Configure delivery SLO tracking
+	if err := svc.WithSLOConfig(cfg.SLO); err != nil {
+		logger.Fatalf("Failed to configure SLO tracking: %v", err)
+	} else if cfg.SLO.Enabled {
+		logger.Infof("Configured SLO tracking: objectives=%d, window=%s, alert_type=%s",
+			len(cfg.SLO.Objectives), cfg.SLO.Window, cfg.SLO.Alert.Type)
+	}
+
+	// Configure failure-pattern anomaly detection
+	if err := svc.WithWatchdogConfig(cfg.Watchdog); err != nil {
+		logger.Fatalf("Failed to configure watchdog: %v", err)
+	} else if cfg.Watchdog.Enabled {
+		logger.Infof("Configured failure watchdog: window=%s, baseline=%s, max_queue_age=%s, alert_type=%s",
+			cfg.Watchdog.Window, cfg.Watchdog.BaselineWindow, cfg.Watchdog.MaxQueueAge, cfg.Watchdog.Alert.Type)
+	}
+
+	// Configure heartbeat monitoring
+	if err := svc.WithHeartbeatsConfig(cfg.Heartbeats); err != nil {
+		logger.Fatalf("Failed to configure heartbeats: %v", err)
+	} else if cfg.Heartbeats.Enabled {
+		logger.Infof("Configured heartbeat monitoring: checks=%d, check_frequency=%s, alert_type=%s",
+			len(cfg.Heartbeats.Checks), cfg.Heartbeats.CheckFrequency, cfg.Heartbeats.Alert.Type)
+	}
+
+	// Configure canary notifications
+	if err := svc.WithCanaryConfig(cfg.Canary); err != nil {
+		logger.Fatalf("Failed to configure canary: %v", err)
+	} else if cfg.Canary.Enabled {
+		logger.Infof("Configured canary notifications: targets=%d, interval=%s, timeout=%s, alert_type=%s",
+			len(cfg.Canary.Targets), cfg.Canary.Interval, cfg.Canary.Timeout, cfg.Canary.Alert.Type)
+	}
+
+	// Configure provider status polling
+	if err := svc.WithProviderStatusConfig(cfg.ProviderStatus); err != nil {
+		logger.Fatalf("Failed to configure provider status: %v", err)
+	} else if cfg.ProviderStatus.Enabled {
+		logger.Infof("Configured provider status polling: feeds=%d, poll_interval=%s, retry_delay=%s",
+			len(cfg.ProviderStatus.Feeds), cfg.ProviderStatus.PollInterval, cfg.ProviderStatus.RetryDelay)
+	}
+
+	// Configure per-origin budgets
+	if err := svc.WithBudgetConfig(cfg.Budgets); err != nil {
+		logger.Fatalf("Failed to configure origin budgets: %v", err)
+	} else if cfg.Budgets.Enabled {
+		logger.Infof("Configured origin budgets: rules=%d, action=%s, alert_type=%s",
+			len(cfg.Budgets.Rules), cfg.Budgets.Action, cfg.Budgets.Alert.Type)
+	}
+
+	// Configure the global retry budget
+	if err := svc.WithRetryBudgetConfig(cfg.RetryBudget); err != nil {
+		logger.Fatalf("Failed to configure retry budget: %v", err)
+	} else if cfg.RetryBudget.Enabled {
+		logger.Infof("Configured retry budget: max_per_minute=%d", cfg.RetryBudget.MaxPerMinute)
+	}
+
+	// Link HTML email to hosted web copies
+	if err := svc.WithWebCopyConfig(cfg.Preview); err != nil {
+		logger.Fatalf("Failed to configure web copies: %v", err)
+	} else if cfg.Preview.Enabled && cfg.Preview.WebCopy.Enabled {
+		logger.Infof("Configured email web copies: base_url=%s", cfg.Preview.BaseURL)
+	}
+
+	// Add acknowledge buttons to ntfy notifications
+	if err := svc.WithActionsConfig(cfg.Preview); err != nil {
+		logger.Fatalf("Failed to configure action buttons: %v", err)
+	} else if cfg.Preview.Enabled && cfg.Preview.Actions.Enabled {
+		logger.Infof("Configured acknowledge buttons: base_url=%s", cfg.Preview.BaseURL)
+	}
+
+	// Route email replies back to the system that sent the notification
+	if err := svc.WithRepliesConfig(cfg.Replies, signer); err != nil {
+		logger.Fatalf("Failed to configure reply routing: %v", err)
+	} else if cfg.Replies.Enabled {
+		logger.Infof("Configured reply routing: domain=%s, callbacks=%d", cfg.Replies.Domain, len(cfg.Replies.Callbacks))
+	}
+
+	// Space out retries of failed notifications
+	if err := svc.WithRetryBackoffConfig(cfg.Queue); err != nil {
+		logger.Fatalf("Failed to configure retry backoff: %v", err)
+	} else {
+		logger.Infof("Configured retry backoff: strategy=%s, base_delay=%s, max_delay=%s",
+			cfg.Queue.RetryBackoff, cfg.Queue.RetryBaseDelay, cfg.Queue.RetryMaxDelay)
+	}
+
+	// Restore dispatch pauses saved before a restart
+	if err := svc.WithPausesConfig(cfg.Pauses); err != nil {
+		logger.Fatalf("Failed to restore dispatch pauses: %v", err)
+	} else if cfg.Pauses.PersistPath != "" {
+		pauses, _ := svc.ListDispatchPauses(ctx)
+		logger.Infof("Configured dispatch pauses: persist_path=%s, active=%d", cfg.Pauses.PersistPath, len(pauses))
+	}
+
+	// Add blackout windows and restore those created through the API before a restart
+	if err := svc.WithBlackoutsConfig(cfg.Blackouts); err != nil {
+		logger.Fatalf("Failed to configure blackout windows: %v", err)
+	} else if len(cfg.Blackouts.Windows) > 0 || cfg.Blackouts.PersistPath != "" {
+		windows, _ := svc.ListBlackoutWindows(ctx)
+		logger.Infof("Configured blackout windows: persist_path=%s, upcoming=%d", cfg.Blackouts.PersistPath, len(windows))
+	}
+
+	// Add the templates notifications can be rendered from
+	if err := svc.WithTemplatesConfig(cfg.Templates); err != nil {
+		logger.Fatalf("Failed to configure templates: %v", err)
+	} else if len(cfg.Templates.Definitions) > 0 {
+		logger.Infof("Configured templates: count=%d", len(cfg.Templates.Definitions))
+	}
+
+	// Add the contacts and groups notifications can be sent to by name
+	if err := svc.WithContactsConfig(cfg.Contacts); err != nil {
+		logger.Fatalf("Failed to configure contacts: %v", err)
+	} else if len(cfg.Contacts.Definitions) > 0 {
+		logger.Infof("Configured contacts: count=%d", len(cfg.Contacts.Definitions))
+	}
+
+	// Restore recurring notifications saved before a restart
+	if err := svc.WithRecurrenceConfig(cfg.Recurrence); err != nil {
+		logger.Fatalf("Failed to restore recurring notifications: %v", err)
+	} else if cfg.Recurrence.PersistPath != "" {
+		recurring, _ := svc.ListRecurringNotifications(ctx)
+		logger.Infof("Configured recurring notifications: persist_path=%s, defined=%d", cfg.Recurrence.PersistPath, len(recurring))
+	}
+
+	// Configure content policy checks
+	if err := svc.WithContentPolicyConfig(cfg.ContentPolicy); err != nil {
+		logger.Fatalf("Failed to configure content policy: %v", err)
+	} else if cfg.ContentPolicy.Enabled {
+		logger.Infof("Configured content policy: rules=%d, action=%s", len(cfg.ContentPolicy.Rules), cfg.ContentPolicy.Action)
+	}
+
+	// Configure strict mode for unrecognised metadata and options keys
+	svc.WithStrictConfig(cfg.Strict)
+	if cfg.Strict.Enabled {
+		logger.Infof("Configured strict mode for all callers")
+	} else if len(cfg.Strict.Clients) > 0 {
+		logger.Infof("Configured strict mode: clients=%v", cfg.Strict.Clients)
+	}
+
+	// Configure notification size limits
+	svc.WithLimitsConfig(cfg.Limits)
+	if cfg.Limits != (config.LimitsConfig{}) {
+		logger.Infof("Configured notification limits: max_recipients=%d, max_body_bytes=%d, max_metadata_entries=%d",
+			cfg.Limits.MaxRecipients, cfg.Limits.MaxBodyBytes, cfg.Limits.MaxMetadataEntries)
+	}
+
+	// Suppress notifications repeating a dedup key within the window
+	if err := svc.WithDedupConfig(cfg.Dedup); err != nil {
+		logger.Fatalf("Failed to configure deduplication: %v", err)
+	} else if cfg.Dedup.Enabled {
+		logger.Infof("Configured deduplication: window=%s", cfg.Dedup.Window)
+	}
+
+	// Combine low-priority notifications into digests
+	if err := svc.WithDigestConfig(cfg.Digest); err != nil {
+		logger.Fatalf("Failed to configure digests: %v", err)
+	} else if cfg.Digest.Enabled {
+		logger.Infof("Configured digests: interval=%s, max_priority=%s, types=%v", cfg.Digest.Interval, cfg.Digest.MaxPriority, cfg.Digest.Types)
+	}
+
+	// Send the shutdown report to an admin channel as well as the log
+	svc.WithShutdownReportConfig(cfg.ShutdownReport)
+	if cfg.ShutdownReport.Alert.Enabled() {
+		logger.Infof("Configured shutdown report: alert=%s, only_if_stranded=%v",
+			cfg.ShutdownReport.Alert.Type, cfg.ShutdownReport.OnlyIfStranded)
+	}
+
+	// Configure spam scoring of outgoing email
+	if err := svc.WithSpamCheckConfig(cfg.SpamCheck); err != nil {
+		logger.Fatalf("Failed to configure spam check: %v", err)
+	} else if cfg.SpamCheck.Enabled {
+		logger.Infof("Configured spam check: engine=%s, address=%s, threshold=%.1f, action=%s",
+			cfg.SpamCheck.Engine, cfg.SpamCheck.Address, cfg.SpamCheck.Threshold, cfg.SpamCheck.Action)
+	}
+
+	// Configure request hedging
+	if err := svc.WithHedgingConfig(cfg.Hedging); err != nil {
+		logger.Fatalf("Failed to configure hedging: %v", err)
+	} else if cfg.Hedging.Enabled {
+		logger.Infof("Configured request hedging: rules=%d, min_priority=%s", len(cfg.Hedging.Rules), cfg.Hedging.MinPriority)
+	}
+
+	// Give notifications fallback chains by type and account
+	if err := svc.WithFailoverConfig(cfg.Failover); err != nil {
+		logger.Fatalf("Failed to configure failover: %v", err)
+	} else if len(cfg.Failover.Rules) > 0 {
+		logger.Infof("Configured failover: rules=%d", len(cfg.Failover.Rules))
+	}
+
+	// Reserve accounts for tenants and set their default accounts
+	svc.WithTenancyConfig(cfg.Tenancy)
+
+	// Count the notifications clients and tenants send against their daily quotas
+	svc.WithQuotas(quotas)
+
+	// Route notifications by their tags and priority
+	if err := svc.WithRoutingConfig(cfg.Routing); err != nil {
+		logger.Fatalf("Failed to configure routing: %v", err)
+	} else if len(cfg.Routing.Rules) > 0 || len(cfg.Routing.Priorities) > 0 {
+		logger.Infof("Configured routing: rules=%d, priority_types=%d", len(cfg.Routing.Rules), len(cfg.Routing.Priorities))
+	}
+
+	// Defer non-critical notifications arriving during quiet hours
+	if err := svc.WithQuietHoursConfig(cfg.QuietHours); err != nil {
+		logger.Fatalf("Failed to configure quiet hours: %v", err)
+	} else if cfg.QuietHours.Enabled {
+		logger.Infof("Configured quiet hours: windows=%d", len(cfg.QuietHours.Windows))
+	}
+
 	// Start workers
 	if err := svc.Start(ctx); err != nil {
 		logger.Fatalf("Failed to start service: %v", err)
@@ -197,17 +539,34 @@ func main() {
 	var wg sync.WaitGroup
 
 	// Start gRPC server if enabled
-	var grpcServer *grpc.Server
+	var grpcServer *grpcListener
 	if cfg.Server.Mode == "both" || cfg.Server.Mode == "grpc" {
 		wg.Add(1)
-		grpcServer = startGRPCServer(ctx, &wg, cfg, svc, logger, authStore)
+		grpcServer = startGRPCServer(ctx, &wg, cfg, svc, logger, authStore, jwtVerifier, tenants, quotas)
 	}
 
 	// Start REST server if enabled
 	var restServer *http.Server
 	if cfg.Server.Mode == "both" || cfg.Server.Mode == "rest" {
 		wg.Add(1)
-		restServer = startRESTServer(ctx, &wg, cfg, svc, logger, authStore, hybridKeyStore)
+		restServer = startRESTServer(ctx, &wg, cfg, svc, logger, authStore, jwtVerifier, tenants, quotas, hybridKeyStore, signer)
+	}
+
+	// Serve queue metrics on their own port
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = startMetricsServer(cfg, svc, logger)
+	}
+
+	// Register with service discovery once the servers are listening
+	var registrar discovery.Registrar
+	if cfg.Discovery.Enabled {
+		registrar = registerWithDiscovery(ctx, cfg, logger)
+	}
+
+	// Take scheduled backups in the background
+	if cfg.Backup.Enabled {
+		startBackupScheduler(ctx, cfg, logger)
 	}
 
 	// Wait for interrupt signal
@@ -221,6 +580,20 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Deregister first so clients stop routing to this instance while it drains
+	if registrar != nil {
+		if err := registrar.Deregister(shutdownCtx); err != nil {
+			logger.Errorf("Error deregistering from service discovery: %v", err)
+		} else {
+			logger.Infof("Deregistered from %s", cfg.Discovery.Backend)
+		}
+	}
+
+	// Report NOT_SERVING so load balancers stop sending new RPCs before we drain
+	if grpcServer != nil {
+		grpcServer.drain()
+	}
+
 	// Stop REST server
 	if restServer != nil {
 		if err := restServer.Shutdown(shutdownCtx); err != nil {
@@ -228,9 +601,15 @@ func main() {
 		}
 	}
 
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Errorf("Error during metrics server shutdown: %v", err)
+		}
+	}
+
 	// Stop gRPC server
 	if grpcServer != nil {
-		grpcServer.GracefulStop()
+		grpcServer.stop()
 	}
 
 	// Wait for servers to stop
@@ -244,7 +623,73 @@ func main() {
 	logger.Info("Servers stopped")
 }
 
-func registerNotifiers(cfg *config.Config, factory *notifier.Factory, logger *logging.Logger) {
+// startBackupScheduler starts scheduled backups. Configuration errors are logged rather than
+// fatal so a broken backup destination doesn't block startup.
+func startBackupScheduler(ctx context.Context, cfg *config.Config, logger *logging.Logger) {
+	sources, closeDB, err := backupSources(cfg)
+	if err != nil {
+		logger.Errorf("Failed to configure scheduled backups: %v", err)
+		return
+	}
+
+	scheduler, err := backup.NewScheduler(cfg.Backup, sources, logger)
+	if err != nil {
+		closeDB()
+		logger.Errorf("Failed to configure scheduled backups: %v", err)
+		return
+	}
+
+	go func() {
+		defer closeDB()
+		scheduler.Run(ctx)
+	}()
+	logger.Infof("Scheduled backups enabled (interval=%s, path=%s)", cfg.Backup.Interval, cfg.Backup.Path)
+}
+
+// registerWithDiscovery advertises this instance to the configured discovery backend.
+// Registration failures are logged rather than fatal so discovery outages don't block startup.
+func registerWithDiscovery(ctx context.Context, cfg *config.Config, logger *logging.Logger) discovery.Registrar {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	instance := discovery.Instance{
+		ID:      cfg.Discovery.ServiceID,
+		Name:    cfg.Discovery.ServiceName,
+		Address: cfg.Discovery.AdvertiseAddress,
+		Version: Version,
+	}
+	if instance.ID == "" {
+		instance.ID = fmt.Sprintf("%s-%s", instance.Name, hostname)
+	}
+	if instance.Address == "" {
+		instance.Address = hostname
+	}
+	if cfg.Server.Mode == "both" || cfg.Server.Mode == "grpc" {
+		instance.GRPCPort = cfg.Server.GRPCPort
+	}
+	if cfg.Server.Mode == "both" || cfg.Server.Mode == "rest" {
+		instance.RESTPort = cfg.Server.RESTPort
+		instance.HealthURL = fmt.Sprintf("http://%s/health", net.JoinHostPort(instance.Address, fmt.Sprint(cfg.Server.RESTPort)))
+	}
+
+	registrar, err := discovery.NewRegistrar(cfg.Discovery, instance, logger)
+	if err != nil {
+		logger.Errorf("Failed to configure service discovery: %v", err)
+		return nil
+	}
+
+	if err := registrar.Register(ctx); err != nil {
+		logger.Errorf("Failed to register with service discovery: %v", err)
+		return nil
+	}
+
+	logger.Infof("Registered with %s as '%s' (id=%s, address=%s)", cfg.Discovery.Backend, instance.Name, instance.ID, instance.Address)
+	return registrar
+}
+
+func registerNotifiers(cfg *config.Config, factory *notifier.Factory, signer *signing.Keyring, logger *logging.Logger) {
 	if cfg.Notifiers.Stdout {
 		stdoutNotifier := notifier.NewStdoutNotifier()
 		if err := factory.RegisterNotifier(domain.TypeStdout, "", stdoutNotifier); err != nil {
@@ -270,6 +715,23 @@ func registerNotifiers(cfg *config.Config, factory *notifier.Factory, logger *lo
 		}
 	}
 
+	// Register Microsoft Graph notifiers as email accounts alongside SMTP
+	for accountName, graphConfig := range cfg.Notifiers.Graph {
+		graphNotifier, err := notifier.NewGraphNotifier(graphConfig)
+		if err != nil {
+			logger.Warnf("Failed to create Microsoft Graph notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeEmail, accountName, graphNotifier); err != nil {
+				logger.Fatalf("Failed to register Microsoft Graph notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if graphConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered Microsoft Graph email notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
+
 	// Register Slack notifiers (now supports multiple accounts)
 	for accountName, slackConfig := range cfg.Notifiers.Slack {
 		slackNotifier, err := notifier.NewSlackNotifier(slackConfig)
@@ -293,6 +755,9 @@ func registerNotifiers(cfg *config.Config, factory *notifier.Factory, logger *lo
 		if err != nil {
 			logger.Warnf("Failed to create Ntfy notifier for account '%s': %v", accountName, err)
 		} else {
+			if ntfyConfig.SignPayloads && signer != nil {
+				ntfyNotifier.SetSigner(signer)
+			}
 			if err := factory.RegisterNotifier(domain.TypeNtfy, accountName, ntfyNotifier); err != nil {
 				logger.Fatalf("Failed to register Ntfy notifier for account '%s': %v", accountName, err)
 			}
@@ -303,60 +768,229 @@ func registerNotifiers(cfg *config.Config, factory *notifier.Factory, logger *lo
 			logger.Infof("Registered Ntfy notifier for account '%s'%s", accountName, defaultStr)
 		}
 	}
-}
 
-func startGRPCServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, svc domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore) *grpc.Server {
-	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.GRPCPort)
+	// Register Discord notifiers
+	for accountName, discordConfig := range cfg.Notifiers.Discord {
+		discordNotifier, err := notifier.NewDiscordNotifier(discordConfig)
+		if err != nil {
+			logger.Warnf("Failed to create Discord notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeDiscord, accountName, discordNotifier); err != nil {
+				logger.Fatalf("Failed to register Discord notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if discordConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered Discord notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	lis, err := net.Listen("tcp", addr)
-	if err != nil {
-		logger.Fatalf("Failed to listen on %s: %v", addr, err)
+	// Register PagerDuty notifiers
+	for accountName, pagerdutyConfig := range cfg.Notifiers.PagerDuty {
+		pagerdutyNotifier, err := notifier.NewPagerDutyNotifier(pagerdutyConfig)
+		if err != nil {
+			logger.Warnf("Failed to create PagerDuty notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypePagerDuty, accountName, pagerdutyNotifier); err != nil {
+				logger.Fatalf("Failed to register PagerDuty notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if pagerdutyConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered PagerDuty notifier for account '%s'%s", accountName, defaultStr)
+		}
 	}
 
-	// Create gRPC server options
-	var serverOpts []grpc.ServerOption
+	// Register FCM notifiers
+	for accountName, fcmConfig := range cfg.Notifiers.FCM {
+		fcmNotifier, err := notifier.NewFCMNotifier(fcmConfig)
+		if err != nil {
+			logger.Warnf("Failed to create FCM notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeFCM, accountName, fcmNotifier); err != nil {
+				logger.Fatalf("Failed to register FCM notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if fcmConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered FCM notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	// Add authentication interceptors if enabled
-	if authStore != nil {
-		authMiddleware := auth.NewGRPCAuthMiddleware(authStore, logger)
-		serverOpts = append(serverOpts,
-			grpc.UnaryInterceptor(authMiddleware.UnaryInterceptor()),
-			grpc.StreamInterceptor(authMiddleware.StreamInterceptor()),
-		)
+	// Register Web Push notifiers
+	for accountName, webpushConfig := range cfg.Notifiers.WebPush {
+		webpushNotifier, err := notifier.NewWebPushNotifier(webpushConfig)
+		if err != nil {
+			logger.Warnf("Failed to create Web Push notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeWebPush, accountName, webpushNotifier); err != nil {
+				logger.Fatalf("Failed to register Web Push notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if webpushConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered Web Push notifier for account '%s'%s", accountName, defaultStr)
+		}
 	}
 
-	grpcServer := grpc.NewServer(serverOpts...)
+	// Register XMPP notifiers
+	for accountName, xmppConfig := range cfg.Notifiers.XMPP {
+		xmppNotifier, err := notifier.NewXMPPNotifier(xmppConfig)
+		if err != nil {
+			logger.Warnf("Failed to create XMPP notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeXMPP, accountName, xmppNotifier); err != nil {
+				logger.Fatalf("Failed to register XMPP notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if xmppConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered XMPP notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	// Create and register gRPC handler
-	grpcHandler := grpcapi.NewNotifierHandler(svc, logger)
-	pb.RegisterNotifierServiceServer(grpcServer, grpcHandler)
+	// Register WhatsApp notifiers
+	for accountName, whatsAppConfig := range cfg.Notifiers.WhatsApp {
+		whatsAppNotifier, err := notifier.NewWhatsAppNotifier(whatsAppConfig)
+		if err != nil {
+			logger.Warnf("Failed to create WhatsApp notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeWhatsApp, accountName, whatsAppNotifier); err != nil {
+				logger.Fatalf("Failed to register WhatsApp notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if whatsAppConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered WhatsApp notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	// Enable reflection for tools like grpcurl
-	reflection.Register(grpcServer)
+	// Register Postmark notifiers
+	for accountName, postmarkConfig := range cfg.Notifiers.Postmark {
+		postmarkNotifier, err := notifier.NewPostmarkNotifier(postmarkConfig)
+		if err != nil {
+			logger.Warnf("Failed to create Postmark notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypePostmark, accountName, postmarkNotifier); err != nil {
+				logger.Fatalf("Failed to register Postmark notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if postmarkConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered Postmark notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	logger.Info("Registered gRPC NotifierService")
+	// Register AMQP notifiers
+	for accountName, amqpConfig := range cfg.Notifiers.AMQP {
+		amqpNotifier, err := notifier.NewAMQPNotifier(amqpConfig)
+		if err != nil {
+			logger.Warnf("Failed to create AMQP notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeAMQP, accountName, amqpNotifier); err != nil {
+				logger.Fatalf("Failed to register AMQP notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if amqpConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered AMQP notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-	go func() {
-		defer wg.Done()
-		logger.Infof("gRPC server listening on %s", addr)
-		if err := grpcServer.Serve(lis); err != nil {
-			logger.Fatalf("Failed to serve gRPC: %v", err)
+	// Register file notifiers
+	for accountName, fileConfig := range cfg.Notifiers.File {
+		fileNotifier, err := notifier.NewFileNotifier(fileConfig)
+		if err != nil {
+			logger.Warnf("Failed to create file notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeFile, accountName, fileNotifier); err != nil {
+				logger.Fatalf("Failed to register file notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if fileConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered file notifier for account '%s'%s", accountName, defaultStr)
 		}
-	}()
+	}
 
-	return grpcServer
-}
+	// Register Bark notifiers
+	for accountName, barkConfig := range cfg.Notifiers.Bark {
+		barkNotifier, err := notifier.NewBarkNotifier(barkConfig)
+		if err != nil {
+			logger.Warnf("Failed to create Bark notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeBark, accountName, barkNotifier); err != nil {
+				logger.Fatalf("Failed to register Bark notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if barkConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered Bark notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
 
-func startRESTServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, svc domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore, hybridKeyStore *auth.HybridKeyStore) *http.Server {
-	var router *mux.Router
-	if authStore != nil && hybridKeyStore != nil {
-		router = rest.NewRouterWithAuthAndKeyStore(svc, logger, authStore, hybridKeyStore)
-	} else if authStore != nil {
-		router = rest.NewRouterWithAuth(svc, logger, authStore)
-	} else {
-		router = rest.NewRouter(svc, logger)
+	// Register DingTalk notifiers
+	for accountName, dingTalkConfig := range cfg.Notifiers.DingTalk {
+		dingTalkNotifier, err := notifier.NewDingTalkNotifier(dingTalkConfig)
+		if err != nil {
+			logger.Warnf("Failed to create DingTalk notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeDingTalk, accountName, dingTalkNotifier); err != nil {
+				logger.Fatalf("Failed to register DingTalk notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if dingTalkConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered DingTalk notifier for account '%s'%s", accountName, defaultStr)
+		}
 	}
 
+	// Register SMS notifiers
+	for accountName, smsConfig := range cfg.Notifiers.SMS {
+		smsNotifier, err := notifier.NewSMSNotifier(smsConfig)
+		if err != nil {
+			logger.Warnf("Failed to create SMS notifier for account '%s': %v", accountName, err)
+		} else {
+			if err := factory.RegisterNotifier(domain.TypeSMS, accountName, smsNotifier); err != nil {
+				logger.Fatalf("Failed to register SMS notifier for account '%s': %v", accountName, err)
+			}
+			defaultStr := ""
+			if smsConfig.Default {
+				defaultStr = " (default)"
+			}
+			logger.Infof("Registered SMS notifier for account '%s'%s", accountName, defaultStr)
+		}
+	}
+}
+
+func startRESTServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config, svc domain.NotificationService, logger *logging.Logger, authStore *auth.APIKeyStore, jwtVerifier *auth.JWTVerifier, tenants *auth.TenantResolver, quotas *auth.Quotas, hybridKeyStore *auth.HybridKeyStore, signer *signing.Keyring) *http.Server {
+	router := rest.NewRouterWithOptions(svc, logger, rest.RouterOptions{
+		AuthStore: authStore,
+		JWT:       jwtVerifier,
+		Tenants:   tenants,
+		Quotas:    quotas,
+		KeyStore:  hybridKeyStore,
+		Signing:   signer,
+		Resources: newResourceRegistry(svc, logger),
+		Mirror:    mirrorConfig(cfg.Mirror, logger),
+		Compat:    compatProfiles(cfg.Compat),
+		Apprise:   cfg.Apprise.Enabled,
+		Preview:   previewConfig(cfg.Preview, logger),
+		Replies:   repliesConfig(cfg.Replies, logger),
+		Version:   versionInfo(cfg),
+	})
+
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.RESTPort)
 	server := &http.Server{
 		Addr:         addr,
@@ -366,10 +1000,26 @@ func startRESTServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Serve HTTPS when configured, so the API needs no terminating proxy
+	if cfg.Server.TLS.Enabled {
+		tlsConfig, err := restTLSConfig(cfg.Server.TLS)
+		if err != nil {
+			logger.Fatalf("Invalid REST server TLS config: %v", err)
+		}
+		server.TLSConfig = tlsConfig
+	}
+
 	go func() {
 		defer wg.Done()
-		logger.Infof("REST server listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			logger.Infof("REST server listening on %s (TLS, client certificates required: %t)", addr, server.TLSConfig.ClientCAs != nil)
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			logger.Infof("REST server listening on %s", addr)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Fatalf("Failed to start REST server: %v", err)
 		}
 	}()
@@ -377,6 +1027,125 @@ func startRESTServer(ctx context.Context, wg *sync.WaitGroup, cfg *config.Config
 	return server
 }
 
+// newJWTVerifier creates the verifier for JWT bearer tokens from its configuration
+func newJWTVerifier(cfg config.JWTAuthConfig) (*auth.JWTVerifier, error) {
+	// Validated on load
+	leeway, _ := time.ParseDuration(cfg.Leeway)
+	refresh, _ := time.ParseDuration(cfg.JWKSRefresh)
+	return auth.NewJWTVerifier(auth.JWTConfig{
+		Issuer:          cfg.Issuer,
+		JWKSURL:         cfg.JWKSURL,
+		Audience:        cfg.Audience,
+		ClientIDClaim:   cfg.ClientIDClaim,
+		RolesClaim:      cfg.RolesClaim,
+		TypesClaim:      cfg.TypesClaim,
+		TenantClaim:     cfg.TenantClaim,
+		Leeway:          leeway,
+		RefreshInterval: refresh,
+	})
+}
+
+// quotaConfig converts the quota configuration for the auth quotas
+func quotaConfig(cfg config.QuotasConfig) auth.QuotaConfig {
+	limits := func(l config.QuotaLimitsConfig) auth.QuotaLimits {
+		return auth.QuotaLimits{RequestsPerMinute: l.RequestsPerMinute, NotificationsPerDay: l.NotificationsPerDay}
+	}
+	quotas := auth.QuotaConfig{
+		Default: limits(cfg.Default),
+		Clients: make(map[string]auth.QuotaLimits),
+		Tenants: make(map[string]auth.QuotaLimits),
+	}
+	for _, rule := range cfg.Rules {
+		if rule.Client != "" {
+			quotas.Clients[rule.Client] = limits(rule.Limits())
+		} else {
+			// Tenant names are lowercased when the configuration is loaded
+			quotas.Tenants[strings.ToLower(rule.Tenant)] = limits(rule.Limits())
+		}
+	}
+	return quotas
+}
+
+// mirrorConfig converts the traffic mirroring configuration for the REST router, returning nil
+// if mirroring is disabled
+func mirrorConfig(cfg config.MirrorConfig, logger *logging.Logger) *rest.MirrorConfig {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	// Validated on load
+	timeout, _ := time.ParseDuration(cfg.Timeout)
+	logger.Infof("Mirroring %.1f%% of send requests to shadow %s", cfg.Percent, cfg.URL)
+	return &rest.MirrorConfig{
+		URL:         cfg.URL,
+		Percent:     cfg.Percent,
+		APIKey:      cfg.APIKey,
+		Timeout:     timeout,
+		MaxInFlight: cfg.MaxInFlight,
+	}
+}
+
+// previewConfig converts the preview link configuration for the REST router, returning nil if
+// preview links are disabled
+func previewConfig(cfg config.PreviewConfig, logger *logging.Logger) *rest.PreviewConfig {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	// Validated on load
+	ttl, _ := time.ParseDuration(cfg.TTL)
+	maxTTL, _ := time.ParseDuration(cfg.MaxTTL)
+	logger.Infof("Serving notification preview links: ttl=%s, max_ttl=%s", ttl, maxTTL)
+	return &rest.PreviewConfig{
+		Secret:  []byte(cfg.Secret),
+		TTL:     ttl,
+		MaxTTL:  maxTTL,
+		BaseURL: cfg.BaseURL,
+	}
+}
+
+// repliesConfig converts the reply routing configuration for the REST router, returning nil if
+// reply routing is disabled
+func repliesConfig(cfg config.RepliesConfig, logger *logging.Logger) *rest.RepliesConfig {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	logger.Infof("Receiving email replies at /inbound/replies: domain=%s", cfg.Domain)
+	return &rest.RepliesConfig{WebhookToken: cfg.WebhookToken}
+}
+
+// compatProfiles converts the request compatibility profiles for the REST router
+func compatProfiles(cfg config.CompatConfig) []rest.CompatProfile {
+	profiles := make([]rest.CompatProfile, 0, len(cfg.Profiles))
+	for name, profile := range cfg.Profiles {
+		fields := make([]rest.CompatField, 0, len(profile.Fields))
+		for _, field := range profile.Fields {
+			fields = append(fields, rest.CompatField{From: field.From, To: field.To})
+		}
+		profiles = append(profiles, rest.CompatProfile{
+			Name:       name,
+			APIKeys:    profile.APIKeys,
+			ClientIDs:  profile.ClientIDs,
+			Envelope:   profile.Envelope,
+			BatchField: profile.BatchField,
+			Fields:     fields,
+			Types:      profile.Types,
+			Unmapped:   profile.Unmapped,
+		})
+	}
+	return profiles
+}
+
+// newResourceRegistry creates the registry behind the declarative resource API
+func newResourceRegistry(svc domain.NotificationService, logger *logging.Logger) *resource.Registry {
+	registry := resource.NewRegistry()
+	if err := registry.Register(resource.NewAccountsKind(svc)); err != nil {
+		logger.Fatalf("Failed to register accounts resource: %v", err)
+	}
+	return registry
+}
+
 func registerAuthorizationRules(cfg *config.Config, authz *auth.NotifierAuthz, logger *logging.Logger) {
 	// Register SMTP authorization rules
 	for accountName, smtpConfig := range cfg.Notifiers.SMTP {
@@ -386,6 +1155,14 @@ func registerAuthorizationRules(cfg *config.Config, authz *auth.NotifierAuthz, l
 		}
 	}
 
+	// Register Microsoft Graph authorization rules
+	for accountName, graphConfig := range cfg.Notifiers.Graph {
+		if len(graphConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeEmail, accountName, graphConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for Microsoft Graph account '%s' - allowed roles: %v", accountName, graphConfig.AllowedRoles)
+		}
+	}
+
 	// Register Slack authorization rules
 	for accountName, slackConfig := range cfg.Notifiers.Slack {
 		if len(slackConfig.AllowedRoles) > 0 {
@@ -401,6 +1178,102 @@ func registerAuthorizationRules(cfg *config.Config, authz *auth.NotifierAuthz, l
 			logger.Infof("Registered auth rule for Ntfy account '%s' - allowed roles: %v", accountName, ntfyConfig.AllowedRoles)
 		}
 	}
+
+	// Register Discord authorization rules
+	for accountName, discordConfig := range cfg.Notifiers.Discord {
+		if len(discordConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeDiscord, accountName, discordConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for Discord account '%s' - allowed roles: %v", accountName, discordConfig.AllowedRoles)
+		}
+	}
+
+	// Register PagerDuty authorization rules
+	for accountName, pagerdutyConfig := range cfg.Notifiers.PagerDuty {
+		if len(pagerdutyConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypePagerDuty, accountName, pagerdutyConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for PagerDuty account '%s' - allowed roles: %v", accountName, pagerdutyConfig.AllowedRoles)
+		}
+	}
+
+	// Register FCM authorization rules
+	for accountName, fcmConfig := range cfg.Notifiers.FCM {
+		if len(fcmConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeFCM, accountName, fcmConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for FCM account '%s' - allowed roles: %v", accountName, fcmConfig.AllowedRoles)
+		}
+	}
+
+	// Register Web Push authorization rules
+	for accountName, webpushConfig := range cfg.Notifiers.WebPush {
+		if len(webpushConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeWebPush, accountName, webpushConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for Web Push account '%s' - allowed roles: %v", accountName, webpushConfig.AllowedRoles)
+		}
+	}
+
+	// Register XMPP authorization rules
+	for accountName, xmppConfig := range cfg.Notifiers.XMPP {
+		if len(xmppConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeXMPP, accountName, xmppConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for XMPP account '%s' - allowed roles: %v", accountName, xmppConfig.AllowedRoles)
+		}
+	}
+
+	// Register WhatsApp authorization rules
+	for accountName, whatsAppConfig := range cfg.Notifiers.WhatsApp {
+		if len(whatsAppConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeWhatsApp, accountName, whatsAppConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for WhatsApp account '%s' - allowed roles: %v", accountName, whatsAppConfig.AllowedRoles)
+		}
+	}
+
+	// Register Postmark authorization rules
+	for accountName, postmarkConfig := range cfg.Notifiers.Postmark {
+		if len(postmarkConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypePostmark, accountName, postmarkConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for Postmark account '%s' - allowed roles: %v", accountName, postmarkConfig.AllowedRoles)
+		}
+	}
+
+	// Register AMQP authorization rules
+	for accountName, amqpConfig := range cfg.Notifiers.AMQP {
+		if len(amqpConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeAMQP, accountName, amqpConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for AMQP account '%s' - allowed roles: %v", accountName, amqpConfig.AllowedRoles)
+		}
+	}
+
+	// Register file authorization rules
+	for accountName, fileConfig := range cfg.Notifiers.File {
+		if len(fileConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeFile, accountName, fileConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for file account '%s' - allowed roles: %v", accountName, fileConfig.AllowedRoles)
+		}
+	}
+
+	// Register Bark authorization rules
+	for accountName, barkConfig := range cfg.Notifiers.Bark {
+		if len(barkConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeBark, accountName, barkConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for Bark account '%s' - allowed roles: %v", accountName, barkConfig.AllowedRoles)
+		}
+	}
+
+	// Register DingTalk authorization rules
+	for accountName, dingTalkConfig := range cfg.Notifiers.DingTalk {
+		if len(dingTalkConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeDingTalk, accountName, dingTalkConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for DingTalk account '%s' - allowed roles: %v", accountName, dingTalkConfig.AllowedRoles)
+		}
+	}
+
+	// Register SMS authorization rules
+	for accountName, smsConfig := range cfg.Notifiers.SMS {
+		if len(smsConfig.AllowedRoles) > 0 {
+			authz.RegisterRule(domain.TypeSMS, accountName, smsConfig.AllowedRoles)
+			logger.Infof("Registered auth rule for SMS account '%s' - allowed roles: %v", accountName, smsConfig.AllowedRoles)
+		}
+	}
 }
 
 func getDefaultConfig() *config.Config {
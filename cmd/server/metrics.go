@@ -0,0 +1,38 @@
+//go:build !lite
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/igodwin/notifier/api/rest"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// startMetricsServer serves queue metrics on metrics.port. A metrics server that can't listen
+// is logged rather than fatal, so it never takes notification delivery down with it.
+func startMetricsServer(cfg *config.Config, svc domain.QueueMetricsReporter, logger *logging.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Metrics.Path, rest.NewMetricsHandler(svc, cfg.Metrics.PrometheusEnabled))
+
+	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Metrics.Port)
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		logger.Infof("Metrics server listening on %s%s", addr, cfg.Metrics.Path)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Metrics server stopped: %v", err)
+		}
+	}()
+
+	return server
+}
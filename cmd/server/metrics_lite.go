@@ -0,0 +1,18 @@
+//go:build lite
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// startMetricsServer logs that lite builds leave the metrics endpoint out; queue depth is
+// still reported by /health
+func startMetricsServer(cfg *config.Config, svc domain.QueueMetricsReporter, logger *logging.Logger) *http.Server {
+	logger.Warn("Metrics are not included in lite builds; metrics.enabled is ignored")
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/migrate"
+)
+
+const migrateUsage = `Usage: server migrate <command>
+
+Commands:
+  up          Apply all pending migrations
+  down [N]    Revert the last N applied migrations (default 1)
+  status      List migrations and whether they are applied
+
+The database URL is read from auth.database.url in the configuration, or from
+queue.postgres.url or store.postgres.url when only they use PostgreSQL.`
+
+// runMigrate runs the migrate subcommand and returns the process exit code
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		return 1
+	}
+	dbURL := cfg.Auth.Database.URL
+	if dbURL == "" && cfg.Queue.Postgres != nil {
+		dbURL = cfg.Queue.Postgres.URL
+	}
+	if dbURL == "" {
+		dbURL = cfg.Store.Postgres.URL
+	}
+	if dbURL == "" {
+		fmt.Fprintln(os.Stderr, "No database configured (auth.database.url, queue.postgres.url or store.postgres.url)")
+		return 1
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid number of steps: %s\n", args[1])
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s  %s\n", status.Version, status.Name, state)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
@@ -0,0 +1,7 @@
+//go:build !lite
+
+package main
+
+import (
+	_ "github.com/lib/pq" // PostgreSQL driver for the migrate and backup subcommands
+)
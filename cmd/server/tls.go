@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+)
+
+// restTLSConfig builds the REST server's TLS configuration. Client certificates are required
+// and verified when a client CA is set.
+func restTLSConfig(cfg config.ServerTLSConfig) (*tls.Config, error) {
+	certs := &certReloader{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+	if _, err := certs.GetCertificate(nil); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: certs.GetCertificate,
+	}
+	if cfg.MinVersion == "1.3" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+
+	if cfg.ClientCAFile != "" {
+		caData, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
+// certReloader serves a certificate loaded from disk, loading it again when either file
+// changes, e.g. when cert-manager renews it
+type certReloader struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// GetCertificate returns the current certificate. If it can't be reloaded, the previous one is
+// kept so a half-written renewal doesn't take the server down.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var modTime time.Time
+	for _, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			if r.cert != nil {
+				return r.cert, nil
+			}
+			return nil, fmt.Errorf("failed to read TLS certificate: %w", err)
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	if r.cert != nil && modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			return r.cert, nil
+		}
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	r.cert, r.modTime = &cert, modTime
+	return r.cert, nil
+}
@@ -0,0 +1,178 @@
+// Package archive moves completed notifications out of the notification store into object
+// storage. Each archive object is a gzipped JSONL file with one notification per line, so it
+// can be inspected with standard tools (zcat, jq) as well as restored by the server.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/objectstore"
+)
+
+// Archive object names are notifier-archive-<UTC run time>-<sequence>.jsonl.gz so they sort
+// chronologically
+const (
+	FilePrefix = "notifier-archive-"
+	FileSuffix = ".jsonl.gz"
+)
+
+// Config contains notification archival configuration
+type Config struct {
+	Enabled   bool               `mapstructure:"enabled"`    // Archive completed notifications on a schedule while the server runs
+	After     string             `mapstructure:"after"`      // Archive sent and failed notifications older than this (e.g., "720h")
+	Interval  string             `mapstructure:"interval"`   // Time between archive runs (e.g., "24h")
+	BatchSize int                `mapstructure:"batch_size"` // Maximum notifications per archive object
+	S3        objectstore.Config `mapstructure:"s3"`         // Bucket archives are written to (S3, or GCS through its S3-compatible API)
+}
+
+// Result summarizes one archive run
+type Result struct {
+	Objects       []string // Keys of the objects written
+	Notifications int      // Notifications archived and removed from the store
+}
+
+// Archiver writes completed notifications to object storage and then removes them from the store
+type Archiver struct {
+	client    *objectstore.Client
+	after     time.Duration
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+// New creates an archiver from its configuration
+func New(config Config) (*Archiver, error) {
+	after, err := time.ParseDuration(config.After)
+	if err != nil || after <= 0 {
+		return nil, fmt.Errorf("invalid archive after: %q (must be a positive duration)", config.After)
+	}
+	interval, err := time.ParseDuration(config.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid archive interval: %q (must be a positive duration)", config.Interval)
+	}
+	if config.BatchSize <= 0 {
+		return nil, fmt.Errorf("archive batch_size must be positive")
+	}
+
+	client, err := objectstore.New(config.S3)
+	if err != nil {
+		return nil, fmt.Errorf("archive %w", err)
+	}
+
+	return &Archiver{
+		client:    client,
+		after:     after,
+		interval:  interval,
+		batchSize: config.BatchSize,
+		now:       time.Now,
+	}, nil
+}
+
+// NewTicker returns a ticker firing every archive interval
+func (a *Archiver) NewTicker() *time.Ticker {
+	return time.NewTicker(a.interval)
+}
+
+// Bucket returns the bucket archives are written to
+func (a *Archiver) Bucket() string {
+	return a.client.Bucket()
+}
+
+// Archive writes sent, failed and digested notifications older than the configured age to object
+// storage, one object per batch, removing each batch from the store once it is uploaded. A
+// failed upload leaves its batch in the store for the next run.
+func (a *Archiver) Archive(ctx context.Context, store domain.NotificationStore) (Result, error) {
+	var result Result
+	runAt := a.now().UTC()
+	cutoff := runAt.Add(-a.after)
+	filter := &domain.NotificationFilter{
+		Statuses:      []domain.NotificationStatus{domain.StatusSent, domain.StatusFailed, domain.StatusDigested},
+		CreatedBefore: &cutoff,
+		Limit:         a.batchSize,
+	}
+
+	for sequence := 1; ; sequence++ {
+		batch, err := store.List(ctx, filter)
+		if err != nil {
+			return result, fmt.Errorf("failed to list notifications to archive: %w", err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		data, err := Encode(batch)
+		if err != nil {
+			return result, err
+		}
+		name := fmt.Sprintf("%s%s-%04d%s", FilePrefix, runAt.Format("20060102T150405Z"), sequence, FileSuffix)
+		key, err := a.client.Put(ctx, name, data, "application/gzip")
+		if err != nil {
+			return result, err
+		}
+		result.Objects = append(result.Objects, key)
+
+		ids := make([]string, len(batch))
+		for i, notification := range batch {
+			ids[i] = notification.ID
+		}
+		if err := store.Delete(ctx, ids...); err != nil {
+			return result, fmt.Errorf("archived to %s but failed to remove notifications: %w", key, err)
+		}
+		result.Notifications += len(batch)
+
+		if len(batch) < a.batchSize {
+			return result, nil
+		}
+	}
+}
+
+// Encode writes notifications as gzipped JSONL
+func Encode(notifications []*domain.Notification) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, notification := range notifications {
+		if err := encoder.Encode(notification); err != nil {
+			return nil, fmt.Errorf("failed to encode notification %s: %w", notification.ID, err)
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode reads notifications from gzipped JSONL
+func Decode(r io.Reader) ([]*domain.Notification, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	defer gz.Close()
+
+	var notifications []*domain.Notification
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var notification domain.Notification
+		if err := json.Unmarshal(scanner.Bytes(), &notification); err != nil {
+			return nil, fmt.Errorf("invalid notification on line %d: %w", line, err)
+		}
+		notifications = append(notifications, &notification)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	return notifications, nil
+}
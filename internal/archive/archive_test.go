@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/objectstore"
+	"github.com/igodwin/notifier/internal/store"
+)
+
+// TestArchive tests that old sent and failed notifications are uploaded in batches and then
+// removed from the store, leaving recent and undelivered ones
+func TestArchive(t *testing.T) {
+	uploads := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		}
+		uploads[r.URL.Path], _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	archiver, err := New(Config{
+		After:     "720h",
+		Interval:  "24h",
+		BatchSize: 2,
+		S3:        objectstore.Config{Bucket: "archive", Region: "us-east-1", Endpoint: server.URL, Prefix: "prod/", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	archiver.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	notifications := store.NewMemoryStore()
+	old := now.Add(-31 * 24 * time.Hour)
+	for i, n := range []struct {
+		id      string
+		status  domain.NotificationStatus
+		created time.Time
+	}{
+		{"old-sent", domain.StatusSent, old},
+		{"old-failed", domain.StatusFailed, old.Add(time.Second)},
+		{"old-sent-2", domain.StatusSent, old.Add(2 * time.Second)},
+		{"old-queued", domain.StatusQueued, old.Add(3 * time.Second)},
+		{"recent-sent", domain.StatusSent, now.Add(-time.Hour)},
+	} {
+		notifications.Save(ctx, &domain.Notification{ID: n.id, Type: domain.TypeStdout, Status: n.status, Body: strings.Repeat("x", i), CreatedAt: n.created})
+	}
+
+	result, err := archiver.Archive(ctx, notifications)
+	if err != nil {
+		t.Fatalf("Archive() error = %v", err)
+	}
+	if result.Notifications != 3 || len(result.Objects) != 2 {
+		t.Fatalf("Expected 3 notifications in 2 objects, got %+v", result)
+	}
+	if want := "prod/notifier-archive-20260301T000000Z-0001.jsonl.gz"; result.Objects[0] != want {
+		t.Errorf("Object key = %q, want %q", result.Objects[0], want)
+	}
+
+	first, err := Decode(bytes.NewReader(uploads["/archive/"+result.Objects[0]]))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(first) != 2 || first[0].ID != "old-sent" || first[1].ID != "old-failed" || first[1].Body != "x" {
+		t.Errorf("Unexpected first archive contents: %+v", first)
+	}
+
+	remaining, _ := notifications.List(ctx, nil)
+	if len(remaining) != 2 || remaining[0].ID != "old-queued" || remaining[1].ID != "recent-sent" {
+		t.Errorf("Expected undelivered and recent notifications to stay, got %d", len(remaining))
+	}
+
+	if result, err := archiver.Archive(ctx, notifications); err != nil || result.Notifications != 0 {
+		t.Errorf("Expected nothing left to archive, got %+v, %v", result, err)
+	}
+}
+
+// TestDecodeRejectsInvalidLines tests that a damaged archive is reported with its line number
+func TestDecodeRejectsInvalidLines(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("{\"id\":\"a\"}\n\n{truncated\n"))
+	gz.Close()
+
+	if _, err := Decode(&buf); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected an error for line 3, got %v", err)
+	}
+	if _, err := Decode(strings.NewReader("not gzip")); err == nil {
+		t.Error("Expected an error for data that isn't gzipped")
+	}
+}
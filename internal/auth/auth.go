@@ -5,8 +5,11 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
 )
 
 // APIKeyStore manages API keys with rate limiting
@@ -40,9 +43,16 @@ type RateLimiter struct {
 
 // AuthContext holds auth information attached to request context
 type AuthContext struct {
-	APIKey   *APIKey
-	ClientID string
-	Roles    []string
+	APIKey       *APIKey
+	ClientID     string
+	Roles        []string
+	AllowedTypes []domain.NotificationType // Types the client may send, from its token's claims (nil allows all)
+	Tenant       string                    // Tenant the client belongs to, when tenancy is enabled (empty sees every tenant)
+}
+
+// AllowsType reports whether the client may send notifications of the given type
+func (a *AuthContext) AllowsType(notificationType domain.NotificationType) bool {
+	return a.AllowedTypes == nil || slices.Contains(a.AllowedTypes, notificationType)
 }
 
 // NewAPIKeyStore creates a new API key store
@@ -8,10 +8,6 @@ import (
 	"time"
 
 	"github.com/igodwin/notifier/internal/logging"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 // BootstrapConfig holds configuration for bootstrap operations
@@ -204,113 +200,3 @@ func LoadBootstrapKeyFromEnv(ctx context.Context, keyStore *HybridKeyStore, logg
 	logger.Infof("Bootstrap key detected from environment variable")
 	return nil
 }
-
-// getKubernetesNamespace reads the pod's namespace from the service account token
-func getKubernetesNamespace() (string, error) {
-	const namespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
-	data, err := os.ReadFile(namespacePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read namespace from service account: %w", err)
-	}
-	return strings.TrimSpace(string(data)), nil
-}
-
-// LoadAdminKeyFromKubernetesSecret attempts to load an existing admin key from a Kubernetes secret
-// Returns the key string if found, empty string if secret doesn't exist, or error on failure
-func LoadAdminKeyFromKubernetesSecret(ctx context.Context, secretName, secretKey string, logger *logging.Logger) (string, error) {
-	// Try to create Kubernetes client (will fail gracefully if not in cluster)
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		logger.Debugf("Not running in Kubernetes cluster or in-cluster config unavailable: %v", err)
-		return "", nil // Not in Kubernetes, return empty (not an error)
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		logger.Warnf("Failed to create Kubernetes client: %v", err)
-		return "", nil // Failed to create client, but not a fatal error
-	}
-
-	namespace, err := getKubernetesNamespace()
-	if err != nil {
-		logger.Warnf("Failed to determine pod namespace: %v", err)
-		return "", nil // Failed to get namespace, but not a fatal error
-	}
-
-	// Try to get the secret
-	secret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
-	if err != nil {
-		// Secret doesn't exist or other error occurred
-		logger.Debugf("Admin key secret not found in namespace %s: %v", namespace, err)
-		return "", nil // Secret not found is not an error
-	}
-
-	// Extract the key value from the secret
-	if secretValue, exists := secret.Data[secretKey]; exists {
-		logger.Infof("Found existing admin key in Kubernetes secret %s/%s", namespace, secretName)
-		return string(secretValue), nil
-	}
-
-	logger.Warnf("Kubernetes secret %s/%s exists but key %q not found", namespace, secretName, secretKey)
-	return "", nil
-}
-
-// CreateKubernetesSecret creates or updates a Kubernetes secret with the admin key
-func CreateKubernetesSecret(ctx context.Context, secretName, secretKey, adminKey string, logger *logging.Logger) error {
-	// Try to create Kubernetes client (will fail gracefully if not in cluster)
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		logger.Debugf("Not running in Kubernetes cluster, skipping secret creation: %v", err)
-		return nil // Not in Kubernetes, skip (not an error)
-	}
-
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		logger.Warnf("Failed to create Kubernetes client, skipping secret creation: %v", err)
-		return nil // Failed to create client, but not a fatal error
-	}
-
-	namespace, err := getKubernetesNamespace()
-	if err != nil {
-		logger.Warnf("Failed to determine pod namespace, skipping secret creation: %v", err)
-		return nil // Failed to get namespace, but not a fatal error
-	}
-
-	// Create or update the secret
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      secretName,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"app": "notifier",
-			},
-		},
-		Type: corev1.SecretTypeOpaque,
-		Data: map[string][]byte{
-			secretKey: []byte(adminKey),
-		},
-	}
-
-	// Try to get existing secret first
-	existingSecret, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
-	if err == nil {
-		// Secret exists, update it
-		secret.ResourceVersion = existingSecret.ResourceVersion
-		_, err = clientset.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{})
-		if err != nil {
-			logger.Warnf("Failed to update Kubernetes secret %s/%s: %v", namespace, secretName, err)
-			return nil // Log warning but don't fail
-		}
-		logger.Infof("Updated admin key in Kubernetes secret %s/%s", namespace, secretName)
-	} else {
-		// Secret doesn't exist, create it
-		_, err = clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
-		if err != nil {
-			logger.Warnf("Failed to create Kubernetes secret %s/%s: %v", namespace, secretName, err)
-			return nil // Log warning but don't fail
-		}
-		logger.Infof("Created Kubernetes secret %s/%s with admin key", namespace, secretName)
-	}
-
-	return nil
-}
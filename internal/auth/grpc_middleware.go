@@ -1,9 +1,14 @@
+//go:build !lite
+
 package auth
 
 import (
 	"context"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -13,8 +18,11 @@ import (
 
 // GRPCAuthMiddleware provides authentication for gRPC APIs
 type GRPCAuthMiddleware struct {
-	store  *APIKeyStore
-	logger *logging.Logger
+	store   *APIKeyStore
+	jwt     *JWTVerifier
+	tenants *TenantResolver
+	quotas  *Quotas
+	logger  *logging.Logger
 }
 
 // NewGRPCAuthMiddleware creates a new gRPC auth middleware
@@ -25,9 +33,31 @@ func NewGRPCAuthMiddleware(store *APIKeyStore, logger *logging.Logger) *GRPCAuth
 	}
 }
 
+// WithJWT also accepts JWT bearer tokens verified by v
+func (m *GRPCAuthMiddleware) WithJWT(v *JWTVerifier) *GRPCAuthMiddleware {
+	m.jwt = v
+	return m
+}
+
+// WithTenants assigns authenticated clients to tenants, rejecting those without one
+func (m *GRPCAuthMiddleware) WithTenants(t *TenantResolver) *GRPCAuthMiddleware {
+	m.tenants = t
+	return m
+}
+
+// WithQuotas limits the requests each client and tenant may make a minute
+func (m *GRPCAuthMiddleware) WithQuotas(q *Quotas) *GRPCAuthMiddleware {
+	m.quotas = q
+	return m
+}
+
 // UnaryInterceptor returns a unary server interceptor for gRPC authentication
 func (m *GRPCAuthMiddleware) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublicMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
 		// Extract API key from metadata
 		apiKey := m.extractAPIKey(ctx)
 		if apiKey == "" {
@@ -35,6 +65,20 @@ func (m *GRPCAuthMiddleware) UnaryInterceptor() grpc.UnaryServerInterceptor {
 			return nil, status.Error(codes.Unauthenticated, "Missing or invalid Authorization header")
 		}
 
+		// Verify JWT bearer tokens against the issuer's signing keys
+		if m.jwt != nil && LooksLikeJWT(apiKey) {
+			authCtx, err := m.jwt.Verify(ctx, apiKey)
+			if err != nil {
+				m.logger.Warnf("gRPC: Invalid JWT for method=%s - error=%v", info.FullMethod, err)
+				return nil, status.Error(codes.Unauthenticated, "Invalid token")
+			}
+			if err := m.admit(authCtx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+				return nil, err
+			}
+			m.logger.Debugf("gRPC: Authenticated JWT from client=%s method=%s with roles=%v", authCtx.ClientID, info.FullMethod, authCtx.Roles)
+			return handler(ContextWithAuth(ctx, authCtx), req)
+		}
+
 		// Validate API key
 		key, err := m.store.ValidateKey(apiKey)
 		if err != nil {
@@ -60,6 +104,9 @@ func (m *GRPCAuthMiddleware) UnaryInterceptor() grpc.UnaryServerInterceptor {
 			ClientID: key.ClientID,
 			Roles:    key.Roles,
 		}
+		if err := m.admit(authCtx, info.FullMethod, func(md metadata.MD) error { return grpc.SetHeader(ctx, md) }); err != nil {
+			return nil, err
+		}
 
 		// Add auth context to request context
 		newCtx := ContextWithAuth(ctx, authCtx)
@@ -69,9 +116,19 @@ func (m *GRPCAuthMiddleware) UnaryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+// isPublicMethod reports whether a method can be called without an API key.
+// Health checks are public so load balancers and Kubernetes gRPC probes work (like REST /health).
+func isPublicMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
+
 // StreamInterceptor returns a stream server interceptor for gRPC authentication
 func (m *GRPCAuthMiddleware) StreamInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublicMethod(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
 		// Extract API key from metadata
 		apiKey := m.extractAPIKey(ss.Context())
 		if apiKey == "" {
@@ -79,6 +136,20 @@ func (m *GRPCAuthMiddleware) StreamInterceptor() grpc.StreamServerInterceptor {
 			return status.Error(codes.Unauthenticated, "Missing or invalid Authorization header")
 		}
 
+		// Verify JWT bearer tokens against the issuer's signing keys
+		if m.jwt != nil && LooksLikeJWT(apiKey) {
+			authCtx, err := m.jwt.Verify(ss.Context(), apiKey)
+			if err != nil {
+				m.logger.Warnf("gRPC: Invalid JWT for stream method=%s - error=%v", info.FullMethod, err)
+				return status.Error(codes.Unauthenticated, "Invalid token")
+			}
+			if err := m.admit(authCtx, info.FullMethod, ss.SetHeader); err != nil {
+				return err
+			}
+			m.logger.Debugf("gRPC: Authenticated JWT stream from client=%s method=%s with roles=%v", authCtx.ClientID, info.FullMethod, authCtx.Roles)
+			return handler(srv, &wrappedServerStream{ServerStream: ss, ctx: ContextWithAuth(ss.Context(), authCtx)})
+		}
+
 		// Validate API key
 		key, err := m.store.ValidateKey(apiKey)
 		if err != nil {
@@ -104,6 +175,9 @@ func (m *GRPCAuthMiddleware) StreamInterceptor() grpc.StreamServerInterceptor {
 			ClientID: key.ClientID,
 			Roles:    key.Roles,
 		}
+		if err := m.admit(authCtx, info.FullMethod, ss.SetHeader); err != nil {
+			return err
+		}
 
 		// Add auth context to request context
 		newCtx := ContextWithAuth(ss.Context(), authCtx)
@@ -115,6 +189,42 @@ func (m *GRPCAuthMiddleware) StreamInterceptor() grpc.StreamServerInterceptor {
 	}
 }
 
+// admit sets the tenant of an authenticated client, if tenancy is enabled, and counts the
+// call against its request quotas, describing the tightest one in the response headers
+func (m *GRPCAuthMiddleware) admit(authCtx *AuthContext, method string, setHeader func(metadata.MD) error) error {
+	if m.tenants != nil {
+		if err := m.tenants.Resolve(authCtx); err != nil {
+			m.logger.Warnf("gRPC: Rejected client=%s method=%s - error=%v", authCtx.ClientID, method, err)
+			return status.Error(codes.PermissionDenied, "Credentials aren't assigned to a tenant")
+		}
+	}
+	if m.quotas == nil {
+		return nil
+	}
+
+	now := time.Now()
+	counter, err := m.quotas.AllowRequest(authCtx, now)
+	if counter != nil {
+		header := make(http.Header)
+		SetQuotaHeaders(header, domain.QuotaRequests, counter)
+		if err != nil {
+			SetRetryAfter(header, counter.ResetAt, now)
+		}
+		md := metadata.MD{}
+		for key, values := range header {
+			md.Set(key, values...)
+		}
+		if err := setHeader(md); err != nil {
+			m.logger.Debugf("gRPC: Failed to set quota headers for method=%s - error=%v", method, err)
+		}
+	}
+	if err != nil {
+		m.logger.Warnf("gRPC: Quota exceeded for client=%s method=%s - error=%v", authCtx.ClientID, method, err)
+		return status.Error(codes.ResourceExhausted, "Rate limit exceeded")
+	}
+	return nil
+}
+
 // wrappedServerStream wraps grpc.ServerStream to override context
 type wrappedServerStream struct {
 	grpc.ServerStream
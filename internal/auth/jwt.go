@@ -0,0 +1,403 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // Registers the hashes JWT algorithms use
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// ErrInvalidToken is returned when a JWT can't be verified or its claims are rejected
+var ErrInvalidToken = errors.New("invalid token")
+
+// JWTConfig configures JWT bearer token validation
+type JWTConfig struct {
+	Issuer          string        // Expected "iss" claim; also the base URL for OIDC discovery
+	JWKSURL         string        // URL of the signing keys (empty discovers it from the issuer)
+	Audience        []string      // Accepted "aud" values; a token must carry at least one
+	ClientIDClaim   string        // Claim identifying the client (default "sub")
+	RolesClaim      string        // Claim listing the client's roles, dot-separated for nested claims (default "roles")
+	TypesClaim      string        // Claim listing the notification types the client may send (default "notification_types")
+	TenantClaim     string        // Claim naming the client's tenant (empty assigns tenants by client ID)
+	Leeway          time.Duration // Clock skew allowed when checking exp and nbf
+	RefreshInterval time.Duration // How often the signing keys are fetched again (default 1h)
+	HTTPClient      *http.Client  // Client used for discovery and fetching keys
+}
+
+// jwksMinRefresh is the shortest time between fetches prompted by tokens signed with an unknown key
+const jwksMinRefresh = time.Minute
+
+// JWTVerifier validates JWT bearer tokens against an issuer's published signing keys
+type JWTVerifier struct {
+	config JWTConfig
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      map[string]crypto.PublicKey // By key ID
+	fetchedAt time.Time
+}
+
+// NewJWTVerifier creates a verifier. Signing keys are fetched on first use, or by Refresh.
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	if cfg.Issuer == "" && cfg.JWKSURL == "" {
+		return nil, fmt.Errorf("jwt issuer or jwks_url is required")
+	}
+	if len(cfg.Audience) == 0 {
+		return nil, fmt.Errorf("jwt audience is required")
+	}
+	if cfg.ClientIDClaim == "" {
+		cfg.ClientIDClaim = "sub"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.TypesClaim == "" {
+		cfg.TypesClaim = "notification_types"
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = time.Hour
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &JWTVerifier{
+		config:  cfg,
+		jwksURL: cfg.JWKSURL,
+	}, nil
+}
+
+// LooksLikeJWT reports whether a bearer token is shaped like a JWT rather than an API key
+func LooksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2 && !strings.HasPrefix(token, "nk_")
+}
+
+// Refresh fetches the signing keys, discovering where they're published first if needed
+func (v *JWTVerifier) Refresh(ctx context.Context) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.refreshLocked(ctx)
+}
+
+// refreshLocked fetches the signing keys with v.mu held
+func (v *JWTVerifier) refreshLocked(ctx context.Context) error {
+	v.fetchedAt = time.Now()
+
+	if v.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		url := strings.TrimSuffix(v.config.Issuer, "/") + "/.well-known/openid-configuration"
+		if err := v.getJSON(ctx, url, &discovery); err != nil {
+			return fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		if discovery.Issuer != v.config.Issuer {
+			return fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, v.config.Issuer)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("OIDC discovery returned no jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue // Skip key types we don't verify with rather than rejecting the whole set
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no usable signing keys at %s", v.jwksURL)
+	}
+	v.keys = keys
+	return nil
+}
+
+// getJSON fetches url and decodes its JSON body into out
+func (v *JWTVerifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// key returns the signing key with the given ID, fetching the keys again if they're stale or
+// the ID is unknown, e.g. because the issuer rotated them
+func (v *JWTVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key, ok := v.keys[kid]
+	age := time.Since(v.fetchedAt)
+	if age >= v.config.RefreshInterval || (!ok && age >= jwksMinRefresh) {
+		if err := v.refreshLocked(ctx); err != nil && v.keys == nil {
+			return nil, err
+		}
+		key, ok = v.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// Verify checks a token's signature and claims, and returns the auth context it grants
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*AuthContext, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := v.key(ctx, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	if err := v.checkClaims(claims, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	clientID, _ := claimValue(claims, v.config.ClientIDClaim).(string)
+	if clientID == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.config.ClientIDClaim)
+	}
+	authCtx := &AuthContext{
+		ClientID: clientID,
+		Roles:    claimStrings(claimValue(claims, v.config.RolesClaim)),
+	}
+	if v.config.TenantClaim != "" {
+		authCtx.Tenant, _ = claimValue(claims, v.config.TenantClaim).(string)
+	}
+	if types := claimValue(claims, v.config.TypesClaim); types != nil {
+		authCtx.AllowedTypes = []domain.NotificationType{}
+		for _, notifType := range claimStrings(types) {
+			authCtx.AllowedTypes = append(authCtx.AllowedTypes, domain.NotificationType(notifType))
+		}
+	}
+	return authCtx, nil
+}
+
+// checkClaims checks the registered claims: the token must be current, from the issuer and
+// meant for one of the audiences
+func (v *JWTVerifier) checkClaims(claims map[string]interface{}, now time.Time) error {
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(v.config.Leeway)) {
+		return fmt.Errorf("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(v.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not valid yet")
+	}
+	if v.config.Issuer != "" && claims["iss"] != v.config.Issuer {
+		return fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+
+	audiences := claimStrings(claims["aud"])
+	if !slices.ContainsFunc(v.config.Audience, func(aud string) bool { return slices.Contains(audiences, aud) }) {
+		return fmt.Errorf("unexpected audience %v", claims["aud"])
+	}
+	return nil
+}
+
+// claimValue looks up a claim by name, following dots into nested objects (e.g. "realm_access.roles")
+func claimValue(claims map[string]interface{}, name string) interface{} {
+	if value, ok := claims[name]; ok {
+		return value
+	}
+	var value interface{} = claims
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+	return value
+}
+
+// claimStrings reads a claim holding either a list of strings or a space-separated string
+func claimStrings(value interface{}) []string {
+	switch value := value.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment
+func decodeSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// verifySignature checks a JWS signature. Only asymmetric algorithms are accepted, so a token
+// can't be signed with a public key or not at all.
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("algorithm %s doesn't match the signing key", alg)
+		}
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	case "ES":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve.Params().BitSize != map[crypto.Hash]int{crypto.SHA256: 256, crypto.SHA384: 384, crypto.SHA512: 521}[hash] {
+			return fmt.Errorf("algorithm %s doesn't match the signing key", alg)
+		}
+		if len(signature) != 2*((ecKey.Curve.Params().BitSize+7)/8) {
+			return fmt.Errorf("malformed signature")
+		}
+		r := new(big.Int).SetBytes(signature[:len(signature)/2])
+		s := new(big.Int).SetBytes(signature[len(signature)/2:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// jsonWebKey is a public key in a JWK set
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey decodes an RSA or EC key
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
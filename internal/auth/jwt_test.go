@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// testIssuer serves OIDC discovery and a JWK set holding one RSA and one EC key
+type testIssuer struct {
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": issuer.server.URL, "jwks_uri": issuer.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// sign makes a token signed with the issuer's key for alg
+func (i *testIssuer) sign(t *testing.T, alg string, claims map[string]interface{}) string {
+	t.Helper()
+	kid := "rsa"
+	if alg == "ES256" {
+		kid = "ec"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	var err error
+	switch alg {
+	case "RS256":
+		signature, err = rsa.SignPKCS1v15(rand.Reader, i.rsaKey, crypto.SHA256, digest[:])
+	case "ES256":
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, i.ecKey, digest[:])
+		if err == nil {
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	}
+	if err != nil {
+		t.Fatalf("sign error = %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// TestJWTVerifier tests that tokens are verified with discovered keys and that their claims
+// are checked and mapped to the auth context
+func TestJWTVerifier(t *testing.T) {
+	issuer := newTestIssuer(t)
+	verifier, err := NewJWTVerifier(JWTConfig{Issuer: issuer.server.URL, Audience: []string{"notifier"}, RolesClaim: "realm_access.roles"})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+	ctx := context.Background()
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss": issuer.server.URL,
+			"aud": []string{"other", "notifier"},
+			"sub": "billing-service",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"realm_access": map[string]interface{}{
+				"roles": []string{"sender"},
+			},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+			} else {
+				c[k] = v
+			}
+		}
+		return c
+	}
+
+	for _, alg := range []string{"RS256", "ES256"} {
+		authCtx, err := verifier.Verify(ctx, issuer.sign(t, alg, claims(map[string]interface{}{"notification_types": "email slack"})))
+		if err != nil {
+			t.Fatalf("Verify(%s) error = %v", alg, err)
+		}
+		if authCtx.ClientID != "billing-service" || !slices.Equal(authCtx.Roles, []string{"sender"}) {
+			t.Errorf("Unexpected auth context: %+v", authCtx)
+		}
+		if !authCtx.AllowsType(domain.TypeSlack) || authCtx.AllowsType(domain.TypeSMS) {
+			t.Errorf("Expected only email and slack to be allowed, got %v", authCtx.AllowedTypes)
+		}
+	}
+
+	authCtx, err := verifier.Verify(ctx, issuer.sign(t, "RS256", claims(nil)))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !authCtx.AllowsType(domain.TypeSMS) {
+		t.Error("Expected every type to be allowed without a types claim")
+	}
+
+	rejected := map[string]string{
+		"expired":         issuer.sign(t, "RS256", claims(map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"not yet valid":   issuer.sign(t, "RS256", claims(map[string]interface{}{"nbf": time.Now().Add(time.Hour).Unix()})),
+		"wrong issuer":    issuer.sign(t, "RS256", claims(map[string]interface{}{"iss": "https://evil.example.com"})),
+		"wrong audience":  issuer.sign(t, "RS256", claims(map[string]interface{}{"aud": "other"})),
+		"no exp":          issuer.sign(t, "RS256", claims(map[string]interface{}{"exp": nil})),
+		"no subject":      issuer.sign(t, "RS256", claims(map[string]interface{}{"sub": nil})),
+		"tampered claims": tamper(issuer.sign(t, "RS256", claims(nil))),
+		"unsigned":        unsigned(claims(nil)),
+	}
+	for name, token := range rejected {
+		if _, err := verifier.Verify(ctx, token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+// tamper swaps a token's claims for ones granting another client, keeping the signature
+func tamper(token string) string {
+	parts := strings.Split(token, ".")
+	payload, _ := json.Marshal(map[string]interface{}{"sub": "admin", "aud": "notifier", "exp": time.Now().Add(time.Hour).Unix()})
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+}
+
+// unsigned makes a token with the "none" algorithm
+func unsigned(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none", "kid": "rsa"})
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+// TestLooksLikeJWT tests telling JWTs from API keys
+func TestLooksLikeJWT(t *testing.T) {
+	if !LooksLikeJWT("eyJhbGciOiJSUzI1NiJ9.eyJzdWIiOiJ4In0.c2ln") {
+		t.Error("Expected a JWT to be recognised")
+	}
+	if LooksLikeJWT("nk_0123456789abcdef") {
+		t.Error("Expected an API key not to look like a JWT")
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !lite
+
 package auth
 
 import (
@@ -6,6 +8,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/igodwin/notifier/internal/migrate"
 	"github.com/lib/pq"
 	_ "github.com/lib/pq" // PostgreSQL driver
 )
@@ -14,10 +17,16 @@ import (
 // It acts as the backend for the in-memory cache
 type KeyStoreDB struct {
 	db *sql.DB
+
+	// readDB serves listing and audit queries; it is the primary unless a read replica is configured
+	readDB    *sql.DB
+	isReplica bool
 }
 
-// NewKeyStoreDB creates a new database-backed key store
-func NewKeyStoreDB(dbURL string) (*KeyStoreDB, error) {
+// NewKeyStoreDB creates a new database-backed key store.
+// When autoMigrate is set, pending schema migrations are applied; otherwise the
+// schema must already be up to date (see the migrate subcommand).
+func NewKeyStoreDB(dbURL string, autoMigrate bool) (*KeyStoreDB, error) {
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -28,66 +37,68 @@ func NewKeyStoreDB(dbURL string) (*KeyStoreDB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	ks := &KeyStoreDB{db: db}
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
 
-	// Initialize schema
-	if err := ks.initializeSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	ctx := context.Background()
+	if autoMigrate {
+		if _, err := migrator.Up(ctx); err != nil {
+			return nil, fmt.Errorf("failed to migrate schema: %w", err)
+		}
+	} else {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check schema version: %w", err)
+		}
+		if pending > 0 {
+			return nil, fmt.Errorf("database schema has %d pending migration(s); run the migrate subcommand or enable auto_migrate", pending)
+		}
 	}
 
-	return ks, nil
+	return &KeyStoreDB{db: db, readDB: db}, nil
 }
 
-// initializeSchema creates the necessary tables and indexes if they don't exist
-func (ks *KeyStoreDB) initializeSchema() error {
-	// Create tables
-	tableSchema := `
-	-- API Keys table
-	CREATE TABLE IF NOT EXISTS api_keys (
-		id SERIAL PRIMARY KEY,
-		key VARCHAR(255) UNIQUE NOT NULL,
-		name VARCHAR(255) NOT NULL,
-		client_id VARCHAR(255) NOT NULL,
-		roles TEXT[] DEFAULT '{}',
-		created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		last_used_at TIMESTAMP,
-		expires_at TIMESTAMP,
-		is_active BOOLEAN NOT NULL DEFAULT true,
-		rate_limit INTEGER NOT NULL DEFAULT 0,
-		created_by VARCHAR(255),
-		metadata JSONB DEFAULT '{}'::jsonb
-	);
-
-	-- Audit log for key operations
-	CREATE TABLE IF NOT EXISTS api_key_audit_log (
-		id SERIAL PRIMARY KEY,
-		key_id INTEGER NOT NULL REFERENCES api_keys(id),
-		action VARCHAR(50) NOT NULL,
-		performed_by VARCHAR(255) NOT NULL,
-		performed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		details JSONB DEFAULT '{}'::jsonb
-	);
-	`
+// WithReadReplica routes listing and audit log queries to a read-only replica,
+// keeping them off the primary that handles key writes
+func (ks *KeyStoreDB) WithReadReplica(readURL string) error {
+	readDB, err := sql.Open("postgres", readURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	if err := readDB.Ping(); err != nil {
+		readDB.Close()
+		return fmt.Errorf("failed to ping read replica: %w", err)
+	}
+
+	ks.readDB = readDB
+	ks.isReplica = true
+	return nil
+}
 
-	if _, err := ks.db.Exec(tableSchema); err != nil {
-		return fmt.Errorf("failed to create tables: %w", err)
+// ReadStaleness reports the source of listing queries and its replication lag
+func (ks *KeyStoreDB) ReadStaleness(ctx context.Context) (*ReadStaleness, error) {
+	if !ks.isReplica {
+		return &ReadStaleness{Source: "primary"}, nil
 	}
 
-	// Create indexes separately (PostgreSQL syntax)
-	indexSchema := `
-	CREATE INDEX IF NOT EXISTS idx_api_keys_key ON api_keys(key);
-	CREATE INDEX IF NOT EXISTS idx_api_keys_client_id ON api_keys(client_id);
-	CREATE INDEX IF NOT EXISTS idx_api_keys_active ON api_keys(is_active);
-	CREATE INDEX IF NOT EXISTS idx_api_keys_expires ON api_keys(expires_at);
-	CREATE INDEX IF NOT EXISTS idx_api_key_audit_log_key_id ON api_key_audit_log(key_id);
-	CREATE INDEX IF NOT EXISTS idx_api_key_audit_log_performed_at ON api_key_audit_log(performed_at);
+	query := `
+	SELECT CASE WHEN pg_is_in_recovery()
+		THEN COALESCE(EXTRACT(EPOCH FROM (now() - pg_last_xact_replay_timestamp())), 0)
+		ELSE 0 END
 	`
 
-	if _, err := ks.db.Exec(indexSchema); err != nil {
-		return fmt.Errorf("failed to create indexes: %w", err)
+	var lag float64
+	if err := ks.readDB.QueryRowContext(ctx, query).Scan(&lag); err != nil {
+		return nil, fmt.Errorf("failed to query replication lag: %w", err)
+	}
+	if lag < 0 {
+		lag = 0
 	}
 
-	return nil
+	return &ReadStaleness{Source: "replica", LagSeconds: lag}, nil
 }
 
 // SaveKey persists an API key to the database
@@ -172,7 +183,7 @@ func (ks *KeyStoreDB) ListKeys(ctx context.Context, clientID string) ([]*APIKey,
 	ORDER BY created_at DESC
 	`
 
-	rows, err := ks.db.QueryContext(ctx, query, clientID)
+	rows, err := ks.readDB.QueryContext(ctx, query, clientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list keys: %w", err)
 	}
@@ -306,6 +317,9 @@ func (ks *KeyStoreDB) logAudit(ctx context.Context, keyStr string, action string
 
 // Close closes the database connection
 func (ks *KeyStoreDB) Close() error {
+	if ks.isReplica {
+		ks.readDB.Close()
+	}
 	return ks.db.Close()
 }
 
@@ -320,7 +334,7 @@ func (ks *KeyStoreDB) GetAuditLog(ctx context.Context, keyStr string, limit int)
 	LIMIT $2
 	`
 
-	rows, err := ks.db.QueryContext(ctx, query, keyStr, limit)
+	rows, err := ks.readDB.QueryContext(ctx, query, keyStr, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audit log: %w", err)
 	}
@@ -403,7 +417,7 @@ func (ks *KeyStoreDB) GetAuditLogByName(ctx context.Context, name string, limit
 	LIMIT $2
 	`
 
-	rows, err := ks.db.QueryContext(ctx, query, name, limit)
+	rows, err := ks.readDB.QueryContext(ctx, query, name, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get audit log: %w", err)
 	}
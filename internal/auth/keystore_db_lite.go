@@ -0,0 +1,68 @@
+//go:build lite
+
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+// errNoDatabase is returned by every database operation in lite builds, which leave out
+// the PostgreSQL key store. Keys are kept in memory only.
+var errNoDatabase = errors.New("database key store is not included in lite builds")
+
+// KeyStoreDB is unavailable in lite builds; NewKeyStoreDB always fails
+type KeyStoreDB struct{}
+
+// NewKeyStoreDB reports that lite builds have no database key store
+func NewKeyStoreDB(dbURL string, autoMigrate bool) (*KeyStoreDB, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) WithReadReplica(readURL string) error { return errNoDatabase }
+
+func (ks *KeyStoreDB) ReadStaleness(ctx context.Context) (*ReadStaleness, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) SaveKey(ctx context.Context, key *APIKey, createdBy string) error {
+	return errNoDatabase
+}
+
+func (ks *KeyStoreDB) GetKey(ctx context.Context, keyStr string) (*APIKey, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) ListKeys(ctx context.Context, clientID string) ([]*APIKey, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) DeactivateKey(ctx context.Context, keyStr string, deactivatedBy string) error {
+	return errNoDatabase
+}
+
+func (ks *KeyStoreDB) UpdateLastUsed(ctx context.Context, keyStr string) error {
+	return errNoDatabase
+}
+
+func (ks *KeyStoreDB) LoadAllKeys(ctx context.Context) ([]*APIKey, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) Close() error { return nil }
+
+func (ks *KeyStoreDB) GetAuditLog(ctx context.Context, keyStr string, limit int) ([]map[string]interface{}, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) GetKeyByName(ctx context.Context, name string) (*APIKey, error) {
+	return nil, errNoDatabase
+}
+
+func (ks *KeyStoreDB) DeactivateKeyByName(ctx context.Context, name string, deactivatedBy string) error {
+	return errNoDatabase
+}
+
+func (ks *KeyStoreDB) GetAuditLogByName(ctx context.Context, name string, limit int) ([]map[string]interface{}, error) {
+	return nil, errNoDatabase
+}
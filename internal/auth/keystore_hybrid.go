@@ -5,8 +5,13 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
 )
 
+// ReadStaleness describes how far behind the primary a listing may be
+type ReadStaleness = domain.ReadStaleness
+
 // HybridKeyStore combines in-memory cache with persistent database backend
 // Write-through strategy: writes go to DB first, then cache is updated
 // This ensures consistency: if DB write fails, cache is not updated
@@ -162,6 +167,11 @@ func (h *HybridKeyStore) GetAuditLogByName(ctx context.Context, name string, lim
 	return h.db.GetAuditLogByName(ctx, name, limit)
 }
 
+// ReadStaleness reports the source and replication lag of listing queries
+func (h *HybridKeyStore) ReadStaleness(ctx context.Context) (*ReadStaleness, error) {
+	return h.db.ReadStaleness(ctx)
+}
+
 // Close closes the database connection
 func (h *HybridKeyStore) Close() error {
 	return h.db.Close()
@@ -0,0 +1,22 @@
+//go:build lite
+
+package auth
+
+import (
+	"context"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// LoadAdminKeyFromKubernetesSecret always returns an empty key, since lite builds leave out
+// the Kubernetes client
+func LoadAdminKeyFromKubernetesSecret(ctx context.Context, secretName, secretKey string, logger *logging.Logger) (string, error) {
+	logger.Debugf("Kubernetes secrets are not supported in lite builds, skipping admin key lookup")
+	return "", nil
+}
+
+// CreateKubernetesSecret does nothing in lite builds, which leave out the Kubernetes client
+func CreateKubernetesSecret(ctx context.Context, secretName, secretKey, adminKey string, logger *logging.Logger) error {
+	logger.Debugf("Kubernetes secrets are not supported in lite builds, skipping secret creation")
+	return nil
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// QuotaLimits are the request-rate and daily-volume limits of a client or tenant (0 is unlimited)
+type QuotaLimits struct {
+	RequestsPerMinute   int64
+	NotificationsPerDay int64
+}
+
+// QuotaConfig sets the quotas of clients and tenants
+type QuotaConfig struct {
+	Default QuotaLimits            // Each client without its own limits, except admins
+	Clients map[string]QuotaLimits // Client ID -> limits
+	Tenants map[string]QuotaLimits // Tenant -> limits shared by all its clients
+}
+
+// Quotas limits how many API requests a minute, and notifications a UTC day, each client and
+// tenant may make. Unlike an API key's rate limit, quotas also apply to JWT clients.
+type Quotas struct {
+	cfg QuotaConfig
+
+	mu      sync.Mutex
+	usage   map[string]*quotaUsage // Subject -> current windows
+	pruneAt int                    // Number of subjects the usage is next pruned at
+}
+
+// quotaUsage counts one subject's requests this minute and notifications this UTC day
+type quotaUsage struct {
+	minute        time.Time
+	requests      int64
+	day           time.Time
+	notifications int64
+}
+
+// quotaSubject is a client or tenant whose quotas apply to a request
+type quotaSubject struct {
+	key    string
+	limits QuotaLimits
+}
+
+// quotaMinPrune is the fewest subjects the usage is pruned at
+const quotaMinPrune = 1024
+
+// NewQuotas creates quotas from their configuration
+func NewQuotas(cfg QuotaConfig) *Quotas {
+	return &Quotas{cfg: cfg, usage: make(map[string]*quotaUsage), pruneAt: quotaMinPrune}
+}
+
+// subjects returns the client and tenant whose quotas apply to a caller
+func (q *Quotas) subjects(authCtx *AuthContext) []quotaSubject {
+	var subjects []quotaSubject
+	limits, ok := q.cfg.Clients[authCtx.ClientID]
+	if !ok && !slices.Contains(authCtx.Roles, "admin") {
+		limits, ok = q.cfg.Default, true
+	}
+	if ok && limits != (QuotaLimits{}) {
+		subjects = append(subjects, quotaSubject{key: "client:" + authCtx.ClientID, limits: limits})
+	}
+	if limits, ok := q.cfg.Tenants[authCtx.Tenant]; ok && authCtx.Tenant != "" && limits != (QuotaLimits{}) {
+		subjects = append(subjects, quotaSubject{key: "tenant:" + authCtx.Tenant, limits: limits})
+	}
+	return subjects
+}
+
+// use returns a subject's usage, starting new windows once the current ones have passed
+// (must be called with lock held)
+func (q *Quotas) use(key string, now time.Time) *quotaUsage {
+	u, ok := q.usage[key]
+	if !ok {
+		if len(q.usage) >= q.pruneAt {
+			q.prune(now)
+		}
+		u = &quotaUsage{}
+		q.usage[key] = u
+	}
+
+	if minute := now.Truncate(time.Minute); !u.minute.Equal(minute) {
+		u.minute, u.requests = minute, 0
+	}
+	if day := utcDay(now); !u.day.Equal(day) {
+		u.day, u.notifications = day, 0
+	}
+	return u
+}
+
+// prune forgets subjects with nothing counted in the current windows, so clients seen once
+// don't grow the usage forever (must be called with lock held)
+func (q *Quotas) prune(now time.Time) {
+	minute, day := now.Truncate(time.Minute), utcDay(now)
+	for key, u := range q.usage {
+		if u.minute.Before(minute) && u.day.Before(day) {
+			delete(q.usage, key)
+		}
+	}
+	q.pruneAt = max(2*len(q.usage), quotaMinPrune)
+}
+
+// AllowRequest counts a request against the caller's request-rate quotas. It returns the
+// counter with the fewest requests remaining, or nil if the caller has no request quota, and
+// a QuotaError if a quota is used up, in which case nothing is counted.
+func (q *Quotas) AllowRequest(authCtx *AuthContext, now time.Time) (*domain.QuotaCounter, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var limited []quotaSubject
+	for _, subject := range q.subjects(authCtx) {
+		if subject.limits.RequestsPerMinute <= 0 {
+			continue
+		}
+		limited = append(limited, subject)
+		u := q.use(subject.key, now)
+		if u.requests >= subject.limits.RequestsPerMinute {
+			counter := requestCounter(u, subject.limits)
+			return counter, &domain.QuotaError{Subject: subject.key, Kind: domain.QuotaRequests, Limit: counter.Limit, ResetAt: counter.ResetAt}
+		}
+	}
+
+	var tightest *domain.QuotaCounter
+	for _, subject := range limited {
+		u := q.usage[subject.key]
+		u.requests++
+		if counter := requestCounter(u, subject.limits); tightest == nil || counter.Remaining < tightest.Remaining {
+			tightest = counter
+		}
+	}
+	return tightest, nil
+}
+
+// AdmitNotifications counts notifications against the caller's daily-volume quotas. If they
+// would take it past a quota nothing is counted and a QuotaError is returned.
+func (q *Quotas) AdmitNotifications(authCtx *AuthContext, count int, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var limited []quotaSubject
+	for _, subject := range q.subjects(authCtx) {
+		if subject.limits.NotificationsPerDay <= 0 {
+			continue
+		}
+		limited = append(limited, subject)
+		u := q.use(subject.key, now)
+		if u.notifications+int64(count) > subject.limits.NotificationsPerDay {
+			counter := notificationCounter(u, subject.limits)
+			return &domain.QuotaError{Subject: subject.key, Kind: domain.QuotaNotifications, Limit: counter.Limit, ResetAt: counter.ResetAt}
+		}
+	}
+
+	for _, subject := range limited {
+		q.usage[subject.key].notifications += int64(count)
+	}
+	return nil
+}
+
+// RefundNotifications uncounts notifications admitted earlier in the UTC day containing now
+// that weren't sent after all, such as those rejected by a later check
+func (q *Quotas) RefundNotifications(authCtx *AuthContext, count int, now time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := utcDay(now)
+	for _, subject := range q.subjects(authCtx) {
+		if u, ok := q.usage[subject.key]; ok && subject.limits.NotificationsPerDay > 0 && u.day.Equal(day) {
+			u.notifications = max(u.notifications-int64(count), 0)
+		}
+	}
+}
+
+// Usage reports the caller's use of its client and tenant quotas
+func (q *Quotas) Usage(authCtx *AuthContext, now time.Time) []domain.QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	subjects := q.subjects(authCtx)
+	usage := make([]domain.QuotaUsage, 0, len(subjects))
+	for _, subject := range subjects {
+		u := q.use(subject.key, now)
+		entry := domain.QuotaUsage{Subject: subject.key}
+		if subject.limits.RequestsPerMinute > 0 {
+			entry.RequestsPerMinute = requestCounter(u, subject.limits)
+		}
+		if subject.limits.NotificationsPerDay > 0 {
+			entry.NotificationsPerDay = notificationCounter(u, subject.limits)
+		}
+		usage = append(usage, entry)
+	}
+	return usage
+}
+
+// requestCounter reports a subject's requests this minute
+func requestCounter(u *quotaUsage, limits QuotaLimits) *domain.QuotaCounter {
+	return &domain.QuotaCounter{
+		Limit:     limits.RequestsPerMinute,
+		Used:      u.requests,
+		Remaining: max(limits.RequestsPerMinute-u.requests, 0),
+		ResetAt:   u.minute.Add(time.Minute),
+	}
+}
+
+// notificationCounter reports a subject's notifications this UTC day
+func notificationCounter(u *quotaUsage, limits QuotaLimits) *domain.QuotaCounter {
+	return &domain.QuotaCounter{
+		Limit:     limits.NotificationsPerDay,
+		Used:      u.notifications,
+		Remaining: max(limits.NotificationsPerDay-u.notifications, 0),
+		ResetAt:   u.day.AddDate(0, 0, 1),
+	}
+}
+
+// utcDay returns the start of the UTC day containing t
+func utcDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// SetQuotaHeaders describes a quota counter in response headers: X-RateLimit-* for the
+// request rate and X-Quota-* for the daily volume
+func SetQuotaHeaders(h http.Header, kind string, counter *domain.QuotaCounter) {
+	prefix := "X-RateLimit-"
+	if kind == domain.QuotaNotifications {
+		prefix = "X-Quota-"
+	}
+	h.Set(prefix+"Limit", strconv.FormatInt(counter.Limit, 10))
+	h.Set(prefix+"Remaining", strconv.FormatInt(counter.Remaining, 10))
+	h.Set(prefix+"Reset", strconv.FormatInt(counter.ResetAt.Unix(), 10))
+}
+
+// SetRetryAfter tells a client rejected by a quota how many seconds until it resets
+func SetRetryAfter(h http.Header, resetAt, now time.Time) {
+	wait := max(resetAt.Sub(now).Round(time.Second), time.Second)
+	h.Set("Retry-After", strconv.Itoa(int(wait/time.Second)))
+}
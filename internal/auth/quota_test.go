@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestQuotasRequests tests that requests are limited per client and tenant each minute, that
+// admins are exempt from the default and that the limit resets the next minute
+func TestQuotasRequests(t *testing.T) {
+	quotas := NewQuotas(QuotaConfig{
+		Default: QuotaLimits{RequestsPerMinute: 2},
+		Tenants: map[string]QuotaLimits{"payments": {RequestsPerMinute: 3}},
+	})
+	now := time.Date(2026, 3, 2, 10, 15, 20, 0, time.UTC)
+
+	client := &AuthContext{ClientID: "indexer"}
+	for i := 0; i < 2; i++ {
+		if _, err := quotas.AllowRequest(client, now); err != nil {
+			t.Fatalf("AllowRequest() error = %v", err)
+		}
+	}
+	counter, err := quotas.AllowRequest(client, now)
+	var quotaErr *domain.QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.Kind != domain.QuotaRequests {
+		t.Fatalf("Expected the request quota to be exceeded, got %v", err)
+	}
+	if counter.Remaining != 0 || !counter.ResetAt.Equal(time.Date(2026, 3, 2, 10, 16, 0, 0, time.UTC)) {
+		t.Errorf("Expected no requests remaining until the next minute, got %+v", counter)
+	}
+	if _, err := quotas.AllowRequest(client, now.Add(time.Minute)); err != nil {
+		t.Errorf("Expected the quota to reset the next minute, got %v", err)
+	}
+
+	// The tenant's limit is shared by its clients, on top of each client's own
+	for i, clientID := range []string{"billing-service", "ledger", "ledger"} {
+		if _, err := quotas.AllowRequest(&AuthContext{ClientID: clientID, Tenant: "payments"}, now); err != nil {
+			t.Fatalf("AllowRequest() #%d error = %v", i, err)
+		}
+	}
+	if _, err := quotas.AllowRequest(&AuthContext{ClientID: "billing-service", Tenant: "payments"}, now); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected the tenant's quota to be exceeded, got %v", err)
+	}
+
+	admin := &AuthContext{ClientID: "ops", Roles: []string{"admin"}}
+	for i := 0; i < 5; i++ {
+		if counter, err := quotas.AllowRequest(admin, now); err != nil || counter != nil {
+			t.Fatalf("Expected admins not to get the default quota, got %+v, error=%v", counter, err)
+		}
+	}
+}
+
+// TestQuotasNotifications tests that a client's notifications are limited each UTC day, that a
+// batch which doesn't fit isn't counted and that refunds are uncounted
+func TestQuotasNotifications(t *testing.T) {
+	quotas := NewQuotas(QuotaConfig{Clients: map[string]QuotaLimits{"indexer": {NotificationsPerDay: 10}}})
+	client := &AuthContext{ClientID: "indexer"}
+	now := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+
+	if err := quotas.AdmitNotifications(client, 8, now); err != nil {
+		t.Fatalf("AdmitNotifications() error = %v", err)
+	}
+	if err := quotas.AdmitNotifications(client, 3, now); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected the daily quota to be exceeded, got %v", err)
+	}
+	if err := quotas.AdmitNotifications(client, 2, now); err != nil {
+		t.Errorf("Expected the rejected batch not to be counted, got %v", err)
+	}
+
+	usage := quotas.Usage(client, now)
+	if len(usage) != 1 || usage[0].Subject != "client:indexer" || usage[0].NotificationsPerDay.Used != 10 || usage[0].RequestsPerMinute != nil {
+		t.Fatalf("Unexpected usage %+v", usage)
+	}
+	quotas.RefundNotifications(client, 4, now)
+	if err := quotas.AdmitNotifications(client, 4, now); err != nil {
+		t.Errorf("Expected refunded notifications not to be counted, got %v", err)
+	}
+	if err := quotas.AdmitNotifications(client, 10, now.Add(time.Hour)); err != nil {
+		t.Errorf("Expected the quota to reset at midnight UTC, got %v", err)
+	}
+	quotas.RefundNotifications(client, 10, now)
+	if usage := quotas.Usage(client, now.Add(time.Hour)); usage[0].NotificationsPerDay.Used != 10 {
+		t.Errorf("Expected a refund for the previous day not to change today's count, got %d", usage[0].NotificationsPerDay.Used)
+	}
+}
+
+// TestSetQuotaHeaders tests the headers describing request and notification quotas
+func TestSetQuotaHeaders(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 15, 20, 0, time.UTC)
+	h := make(http.Header)
+	SetQuotaHeaders(h, domain.QuotaRequests, &domain.QuotaCounter{Limit: 60, Remaining: 0, ResetAt: now.Add(40 * time.Second)})
+	SetQuotaHeaders(h, domain.QuotaNotifications, &domain.QuotaCounter{Limit: 1000, Remaining: 250, ResetAt: now.Add(time.Hour)})
+	SetRetryAfter(h, now.Add(40*time.Second), now)
+
+	want := map[string]string{
+		"X-RateLimit-Limit":     "60",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     "1772446560",
+		"X-Quota-Limit":         "1000",
+		"X-Quota-Remaining":     "250",
+		"Retry-After":           "40",
+	}
+	for key, value := range want {
+		if got := h.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
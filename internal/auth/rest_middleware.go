@@ -3,14 +3,19 @@ package auth
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
 )
 
 // RESTAuthMiddleware provides authentication for REST APIs
 type RESTAuthMiddleware struct {
-	store  *APIKeyStore
-	logger *logging.Logger
+	store   *APIKeyStore
+	jwt     *JWTVerifier
+	tenants *TenantResolver
+	quotas  *Quotas
+	logger  *logging.Logger
 }
 
 // NewRESTAuthMiddleware creates a new REST auth middleware
@@ -21,8 +26,33 @@ func NewRESTAuthMiddleware(store *APIKeyStore, logger *logging.Logger) *RESTAuth
 	}
 }
 
+// WithJWT also accepts JWT bearer tokens verified by v
+func (m *RESTAuthMiddleware) WithJWT(v *JWTVerifier) *RESTAuthMiddleware {
+	m.jwt = v
+	return m
+}
+
+// WithTenants assigns authenticated clients to tenants, rejecting those without one
+func (m *RESTAuthMiddleware) WithTenants(t *TenantResolver) *RESTAuthMiddleware {
+	m.tenants = t
+	return m
+}
+
+// WithQuotas limits the requests each client and tenant may make a minute
+func (m *RESTAuthMiddleware) WithQuotas(q *Quotas) *RESTAuthMiddleware {
+	m.quotas = q
+	return m
+}
+
 // Middleware returns an HTTP middleware function
 func (m *RESTAuthMiddleware) Middleware(next http.Handler) http.Handler {
+	if m.quotas != nil {
+		next = m.limitRequests(next)
+	}
+	if m.tenants != nil {
+		next = m.resolveTenant(next)
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Extract API key from Authorization header or X-API-Key header
 		apiKey := m.extractAPIKey(r)
@@ -32,6 +62,19 @@ func (m *RESTAuthMiddleware) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		// Verify JWT bearer tokens against the issuer's signing keys
+		if m.jwt != nil && LooksLikeJWT(apiKey) {
+			authCtx, err := m.jwt.Verify(r.Context(), apiKey)
+			if err != nil {
+				m.logger.Warnf("REST: Invalid JWT from %s - error=%v", r.RemoteAddr, err)
+				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				return
+			}
+			m.logger.Debugf("REST: Authenticated JWT from client=%s with roles=%v", authCtx.ClientID, authCtx.Roles)
+			next.ServeHTTP(w, r.WithContext(ContextWithAuth(r.Context(), authCtx)))
+			return
+		}
+
 		// Validate API key
 		key, err := m.store.ValidateKey(apiKey)
 		if err != nil {
@@ -69,6 +112,39 @@ func (m *RESTAuthMiddleware) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// resolveTenant sets the tenant of the authenticated client before next handles the request
+func (m *RESTAuthMiddleware) resolveTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authCtx, _ := GetAuthContext(r.Context())
+		if err := m.tenants.Resolve(authCtx); err != nil {
+			m.logger.Warnf("REST: Rejected client=%s from %s - error=%v", authCtx.ClientID, r.RemoteAddr, err)
+			http.Error(w, "Credentials aren't assigned to a tenant", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitRequests counts the request against the client's and tenant's request quotas before
+// next handles it, describing the tightest quota in the response headers
+func (m *RESTAuthMiddleware) limitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authCtx, _ := GetAuthContext(r.Context())
+		now := time.Now()
+		counter, err := m.quotas.AllowRequest(authCtx, now)
+		if counter != nil {
+			SetQuotaHeaders(w.Header(), domain.QuotaRequests, counter)
+		}
+		if err != nil {
+			m.logger.Warnf("REST: Quota exceeded for client=%s from %s - error=%v", authCtx.ClientID, r.RemoteAddr, err)
+			SetRetryAfter(w.Header(), counter.ResetAt, now)
+			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // extractAPIKey extracts API key from Authorization header or X-API-Key header
 func (m *RESTAuthMiddleware) extractAPIKey(r *http.Request) string {
 	// Try Authorization header first (Bearer token)
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TenantResolver assigns authenticated clients to tenants, so each team sharing an instance
+// only sees its own notifications
+type TenantResolver struct {
+	clients map[string]string // Client ID -> tenant
+}
+
+// NewTenantResolver creates a resolver from a map of client IDs to tenants
+func NewTenantResolver(clients map[string]string) *TenantResolver {
+	return &TenantResolver{clients: clients}
+}
+
+// Resolve sets the tenant of an auth context that doesn't already carry one from its token.
+// Clients without a tenant are rejected unless they're admins, who see every tenant. A tenant's
+// clients can't hold the admin role, which manages the whole instance.
+func (t *TenantResolver) Resolve(authCtx *AuthContext) error {
+	if authCtx.Tenant == "" {
+		authCtx.Tenant = t.clients[authCtx.ClientID]
+	}
+	if authCtx.Tenant == "" {
+		if !slices.Contains(authCtx.Roles, "admin") {
+			return domain.ErrNoTenant
+		}
+		return nil
+	}
+
+	authCtx.Roles = slices.DeleteFunc(slices.Clone(authCtx.Roles), func(role string) bool {
+		return role == "admin"
+	})
+	return nil
+}
+
+// TenantFromContext returns the tenant of the client making a request, or "" if the caller
+// isn't limited to one tenant
+func TenantFromContext(ctx context.Context) string {
+	if authCtx, ok := GetAuthContext(ctx); ok {
+		return authCtx.Tenant
+	}
+	return ""
+}
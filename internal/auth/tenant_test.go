@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestTenantResolver tests that clients are assigned their tenant, that clients without one
+// are rejected unless they're admins, and that tenant clients lose the admin role
+func TestTenantResolver(t *testing.T) {
+	resolver := NewTenantResolver(map[string]string{"billing-service": "payments"})
+
+	roles := []string{"admin", "sender"}
+	client := &AuthContext{ClientID: "billing-service", Roles: roles}
+	if err := resolver.Resolve(client); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if client.Tenant != "payments" || !slices.Equal(client.Roles, []string{"sender"}) {
+		t.Errorf("Expected the payments tenant without the admin role, got %+v", client)
+	}
+	if !slices.Equal(roles, []string{"admin", "sender"}) {
+		t.Errorf("Expected the key's roles to be left unchanged, got %v", roles)
+	}
+
+	claimed := &AuthContext{ClientID: "indexer", Tenant: "search"}
+	if err := resolver.Resolve(claimed); err != nil || claimed.Tenant != "search" {
+		t.Errorf("Expected the token's tenant to be kept, got %q, error=%v", claimed.Tenant, err)
+	}
+
+	if err := resolver.Resolve(&AuthContext{ClientID: "ops", Roles: []string{"admin"}}); err != nil {
+		t.Errorf("Expected an admin without a tenant to be accepted, got %v", err)
+	}
+	if err := resolver.Resolve(&AuthContext{ClientID: "unknown", Roles: []string{"sender"}}); !errors.Is(err, domain.ErrNoTenant) {
+		t.Errorf("Expected ErrNoTenant, got %v", err)
+	}
+}
@@ -0,0 +1,412 @@
+// Package backup snapshots the service's persistent state (the API key database, the local
+// queue's persisted messages and the configuration file) into a single archive, and restores
+// it after verifying every file against the checksums recorded in the archive's manifest.
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FormatVersion is the archive layout version written by Create
+const FormatVersion = 1
+
+// Archive entry names
+const (
+	manifestName = "manifest.json"
+	queueName    = "queue/queue.json"
+	configName   = "config/config"
+	tableDir     = "database/"
+)
+
+// tables are the database tables included in backups, in foreign key order so they can be
+// restored parent-first. Tables added by new migrations must be listed here.
+var tables = []string{"api_keys", "api_key_audit_log", "notification_queue", "notifications"}
+
+// ErrCorrupt is returned when an archive fails its integrity checks
+var ErrCorrupt = errors.New("backup archive is corrupt")
+
+// Config contains scheduled backup configuration
+type Config struct {
+	Enabled  bool     `mapstructure:"enabled"`  // Take backups on a schedule while the server runs
+	Interval string   `mapstructure:"interval"` // Time between backups (e.g., "24h")
+	Path     string   `mapstructure:"path"`     // Directory backups are written to
+	Retain   int      `mapstructure:"retain"`   // Number of backups to keep in path (0 = keep all)
+	S3       S3Config `mapstructure:"s3"`       // Optional upload of each backup to S3
+}
+
+// Sources locates the state to back up. Empty sources are skipped.
+type Sources struct {
+	DB            *sql.DB // API key database
+	QueuePath     string  // Local queue persist_path
+	ConfigPath    string  // Configuration file in use
+	ServerVersion string
+}
+
+// Manifest describes an archive's contents
+type Manifest struct {
+	FormatVersion int         `json:"format_version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	ServerVersion string      `json:"server_version,omitempty"`
+	SchemaVersion int64       `json:"schema_version,omitempty"` // Latest applied migration when the database was captured
+	Files         []FileEntry `json:"files"`
+}
+
+// FileEntry records one archived file and its checksum
+type FileEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Archive is an opened, verified backup
+type Archive struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// Create captures a snapshot of the sources and writes it to w as a gzipped tar archive.
+// Database tables are read in a single repeatable-read transaction so they are consistent
+// with each other.
+func Create(ctx context.Context, w io.Writer, src Sources) (*Manifest, error) {
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		ServerVersion: src.ServerVersion,
+	}
+	files := make(map[string][]byte)
+	var names []string
+	add := func(name string, data []byte) {
+		sum := sha256.Sum256(data)
+		files[name] = data
+		names = append(names, name)
+		manifest.Files = append(manifest.Files, FileEntry{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+	}
+
+	if src.DB != nil {
+		schemaVersion, dumps, err := dumpDatabase(ctx, src.DB)
+		if err != nil {
+			return nil, err
+		}
+		manifest.SchemaVersion = schemaVersion
+		for _, table := range tables {
+			add(tableDir+table+".jsonl", dumps[table])
+		}
+	}
+
+	if src.QueuePath != "" {
+		data, err := os.ReadFile(src.QueuePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read queue state: %w", err)
+		}
+		if err == nil {
+			if !json.Valid(data) {
+				return nil, fmt.Errorf("queue state at %s is not valid JSON", src.QueuePath)
+			}
+			add(queueName, data)
+		}
+	}
+
+	if src.ConfigPath != "" {
+		data, err := os.ReadFile(src.ConfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		add(configName, data)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, name := range append([]string{manifestName}, names...) {
+		data := files[name]
+		if name == manifestName {
+			data = manifestData
+		}
+		header := &tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// CreateFile writes a backup to path. The archive is written to a temporary file and
+// renamed into place, so a failed or interrupted backup never leaves a partial archive.
+func CreateFile(ctx context.Context, path string, src Sources) (*Manifest, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Create(ctx, tmp, src)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+	return manifest, nil
+}
+
+// Open reads an archive and verifies every file against the manifest
+func Open(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	defer gz.Close()
+
+	archive := &Archive{files: make(map[string][]byte)}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+		}
+		archive.files[header.Name] = data
+	}
+
+	manifestData, ok := archive.files[manifestName]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", ErrCorrupt, manifestName)
+	}
+	if err := json.Unmarshal(manifestData, &archive.Manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrCorrupt, err)
+	}
+	if archive.Manifest.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d (expected %d)", archive.Manifest.FormatVersion, FormatVersion)
+	}
+
+	for _, entry := range archive.Manifest.Files {
+		data, ok := archive.files[entry.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", ErrCorrupt, entry.Name)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != entry.Size || hex.EncodeToString(sum[:]) != entry.SHA256 {
+			return nil, fmt.Errorf("%w: checksum mismatch for %s", ErrCorrupt, entry.Name)
+		}
+	}
+	if len(archive.files) != len(archive.Manifest.Files)+1 {
+		return nil, fmt.Errorf("%w: archive contains files not listed in the manifest", ErrCorrupt)
+	}
+
+	return archive, nil
+}
+
+// OpenFile opens and verifies an archive on disk
+func OpenFile(path string) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer f.Close()
+	return Open(f)
+}
+
+// HasDatabase reports whether the archive contains a database snapshot
+func (a *Archive) HasDatabase() bool {
+	_, ok := a.files[tableDir+tables[0]+".jsonl"]
+	return ok
+}
+
+// HasQueue reports whether the archive contains queue state
+func (a *Archive) HasQueue() bool {
+	_, ok := a.files[queueName]
+	return ok
+}
+
+// HasConfig reports whether the archive contains a configuration file
+func (a *Archive) HasConfig() bool {
+	_, ok := a.files[configName]
+	return ok
+}
+
+// Targets locates where archived state is restored to. Empty targets are skipped.
+type Targets struct {
+	DB         *sql.DB
+	QueuePath  string
+	ConfigPath string
+}
+
+// Restore writes the archive's contents to the targets. The database is replaced in a single
+// transaction and refused unless its schema matches the backup's; files are replaced atomically.
+// The server should be stopped while restoring.
+func (a *Archive) Restore(ctx context.Context, dst Targets) error {
+	if dst.DB != nil && a.HasDatabase() {
+		if err := restoreDatabase(ctx, dst.DB, a.Manifest.SchemaVersion, a.files); err != nil {
+			return err
+		}
+	}
+	if dst.QueuePath != "" && a.HasQueue() {
+		if err := writeFileAtomic(dst.QueuePath, a.files[queueName], 0644); err != nil {
+			return fmt.Errorf("failed to restore queue state: %w", err)
+		}
+	}
+	if dst.ConfigPath != "" && a.HasConfig() {
+		if err := writeFileAtomic(dst.ConfigPath, a.files[configName], 0600); err != nil {
+			return fmt.Errorf("failed to restore config file: %w", err)
+		}
+	}
+	return nil
+}
+
+// dumpDatabase reads every backed-up table as JSON lines within one snapshot
+func dumpDatabase(ctx context.Context, db *sql.DB) (int64, map[string][]byte, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to start backup transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	schemaVersion, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dumps := make(map[string][]byte)
+	for _, table := range tables {
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t ORDER BY id`, table))
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		var buf bytes.Buffer
+		for rows.Next() {
+			var row string
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return 0, nil, fmt.Errorf("failed to read %s: %w", table, err)
+			}
+			buf.WriteString(row)
+			buf.WriteByte('\n')
+		}
+		if err := rows.Close(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if err := rows.Err(); err != nil {
+			return 0, nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		dumps[table] = buf.Bytes()
+	}
+
+	return schemaVersion, dumps, tx.Commit()
+}
+
+// restoreDatabase replaces the backed-up tables with the archived rows in one transaction
+func restoreDatabase(ctx context.Context, db *sql.DB, wantSchema int64, files map[string][]byte) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start restore transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := schemaVersion(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if current != wantSchema {
+		return fmt.Errorf("database schema is at migration %d but the backup was taken at %d; migrate the database to match first", current, wantSchema)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`TRUNCATE %s RESTART IDENTITY CASCADE`, strings.Join(tables, ", "))); err != nil {
+		return fmt.Errorf("failed to clear tables: %w", err)
+	}
+
+	for _, table := range tables {
+		insert := fmt.Sprintf(`INSERT INTO %s SELECT * FROM json_populate_record(NULL::%s, $1)`, table, table)
+		for _, row := range bytes.Split(files[tableDir+table+".jsonl"], []byte("\n")) {
+			if len(row) == 0 {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, insert, string(row)); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", table, err)
+			}
+		}
+
+		// Move the id sequence past the restored rows
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			`SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM %s`, table, table))
+		if err != nil {
+			return fmt.Errorf("failed to reset %s sequence: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return nil
+}
+
+// schemaVersion returns the latest applied migration
+func schemaVersion(ctx context.Context, tx *sql.Tx) (int64, error) {
+	var version int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// writeFileAtomic replaces a file by writing a temporary file and renaming it over the original
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
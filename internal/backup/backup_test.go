@@ -0,0 +1,177 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// TestCreateAndRestore tests a file-only backup round trip
+func TestCreateAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	queuePath := filepath.Join(dir, "queue.json")
+	configPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(queuePath, []byte(`{"msg-1":{"id":"msg-1","attempt":1}}`), 0644)
+	os.WriteFile(configPath, []byte("server:\n  rest_port: 8080\n"), 0644)
+
+	archivePath := filepath.Join(dir, "backup.tar.gz")
+	manifest, err := CreateFile(context.Background(), archivePath, Sources{QueuePath: queuePath, ConfigPath: configPath, ServerVersion: "1.2.3"})
+	if err != nil {
+		t.Fatalf("CreateFile() error = %v", err)
+	}
+	if len(manifest.Files) != 2 {
+		t.Fatalf("Expected 2 files in manifest, got %+v", manifest.Files)
+	}
+
+	archive, err := OpenFile(archivePath)
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	if archive.Manifest.ServerVersion != "1.2.3" || archive.HasDatabase() || !archive.HasQueue() || !archive.HasConfig() {
+		t.Errorf("Unexpected archive: %+v", archive.Manifest)
+	}
+
+	restoreDir := t.TempDir()
+	targets := Targets{QueuePath: filepath.Join(restoreDir, "queue.json"), ConfigPath: filepath.Join(restoreDir, "config.yaml")}
+	if err := archive.Restore(context.Background(), targets); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	for src, dst := range map[string]string{queuePath: targets.QueuePath, configPath: targets.ConfigPath} {
+		want, _ := os.ReadFile(src)
+		got, _ := os.ReadFile(dst)
+		if !bytes.Equal(got, want) {
+			t.Errorf("Restored %s = %q, want %q", dst, got, want)
+		}
+	}
+}
+
+// TestOpenDetectsTampering tests that modified, missing and unlisted files fail verification
+func TestOpenDetectsTampering(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(configPath, []byte("logging:\n  level: info\n"), 0644)
+
+	var buf bytes.Buffer
+	if _, err := Create(context.Background(), &buf, Sources{ConfigPath: configPath}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// rewrite copies the archive, letting edit change or drop (nil) each file and add extras
+	rewrite := func(edit func(name string, data []byte) []byte, extra map[string]string) io.Reader {
+		gz, _ := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+		tr := tar.NewReader(gz)
+		var out bytes.Buffer
+		gw := gzip.NewWriter(&out)
+		tw := tar.NewWriter(gw)
+		write := func(name string, data []byte) {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data))})
+			tw.Write(data)
+		}
+		for {
+			header, err := tr.Next()
+			if err != nil {
+				break
+			}
+			data, _ := io.ReadAll(tr)
+			if data = edit(header.Name, data); data != nil {
+				write(header.Name, data)
+			}
+		}
+		for name, data := range extra {
+			write(name, []byte(data))
+		}
+		tw.Close()
+		gw.Close()
+		return &out
+	}
+
+	tests := []struct {
+		name    string
+		archive io.Reader
+	}{
+		{"modified file", rewrite(func(name string, data []byte) []byte {
+			if name == configName {
+				return []byte("logging:\n  level: debug\n")
+			}
+			return data
+		}, nil)},
+		{"missing file", rewrite(func(name string, data []byte) []byte {
+			if name == configName {
+				return nil
+			}
+			return data
+		}, nil)},
+		{"unlisted file", rewrite(func(name string, data []byte) []byte { return data }, map[string]string{"extra": "x"})},
+		{"truncated", bytes.NewReader(buf.Bytes()[:buf.Len()/2])},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Open(tt.archive); !errors.Is(err, ErrCorrupt) {
+				t.Errorf("Expected ErrCorrupt, got %v", err)
+			}
+		})
+	}
+
+	if _, err := Open(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Errorf("Unmodified archive failed verification: %v", err)
+	}
+}
+
+// TestSchedulerBackup tests retention and S3 upload of scheduled backups
+func TestSchedulerBackup(t *testing.T) {
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("Unexpected upload request: %s %v", r.Method, r.Header)
+		}
+		uploads = append(uploads, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	os.WriteFile(configPath, []byte("queue:\n  type: local\n"), 0644)
+
+	backupDir := filepath.Join(dir, "backups")
+	scheduler, err := NewScheduler(Config{
+		Path:   backupDir,
+		Retain: 2,
+		S3:     S3Config{Bucket: "backups", Region: "us-east-1", Endpoint: server.URL, Prefix: "prod/", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	}, Sources{ConfigPath: configPath}, logging.New(logging.ErrorLevel, os.Stderr))
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+
+	start := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		scheduler.now = func() time.Time { return start.Add(time.Duration(i) * time.Hour) }
+		if _, err := scheduler.Backup(context.Background()); err != nil {
+			t.Fatalf("Backup() error = %v", err)
+		}
+	}
+
+	entries, _ := os.ReadDir(backupDir)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	want := []string{"notifier-backup-20260102T040000Z.tar.gz", "notifier-backup-20260102T050000Z.tar.gz"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Retained backups = %v, want %v", names, want)
+	}
+	if len(uploads) != 3 || uploads[2] != "/backups/prod/notifier-backup-20260102T050000Z.tar.gz" {
+		t.Errorf("Unexpected uploads: %v", uploads)
+	}
+}
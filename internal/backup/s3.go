@@ -0,0 +1,36 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/igodwin/notifier/internal/objectstore"
+)
+
+// S3Config contains S3 (or S3-compatible) upload configuration
+type S3Config = objectstore.Config
+
+// s3Uploader uploads backup archives to a bucket
+type s3Uploader struct {
+	client *objectstore.Client
+}
+
+// newS3Uploader creates an uploader for the configured bucket
+func newS3Uploader(config S3Config) (*s3Uploader, error) {
+	client, err := objectstore.New(config)
+	if err != nil {
+		return nil, fmt.Errorf("backup %w", err)
+	}
+	return &s3Uploader{client: client}, nil
+}
+
+// UploadFile uploads a local file under the configured prefix, returning the object key
+func (u *s3Uploader) UploadFile(ctx context.Context, filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read backup: %w", err)
+	}
+	return u.client.Put(ctx, path.Base(filePath), data, "application/gzip")
+}
@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// Backup file names are notifier-backup-<UTC timestamp>.tar.gz so they sort chronologically
+const (
+	filePrefix = "notifier-backup-"
+	fileSuffix = ".tar.gz"
+)
+
+// Scheduler writes backups to a directory, prunes old ones and optionally uploads them to S3
+type Scheduler struct {
+	config   Config
+	sources  Sources
+	interval time.Duration
+	uploader *s3Uploader
+	logger   *logging.Logger
+	now      func() time.Time
+}
+
+// NewScheduler creates a backup scheduler
+func NewScheduler(config Config, sources Sources, logger *logging.Logger) (*Scheduler, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("backup path is required")
+	}
+
+	var interval time.Duration
+	if config.Interval != "" {
+		var err error
+		if interval, err = time.ParseDuration(config.Interval); err != nil {
+			return nil, fmt.Errorf("invalid backup interval: %w", err)
+		}
+	}
+
+	s := &Scheduler{
+		config:   config,
+		sources:  sources,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+
+	if config.S3.Bucket != "" {
+		uploader, err := newS3Uploader(config.S3)
+		if err != nil {
+			return nil, err
+		}
+		s.uploader = uploader
+	}
+
+	return s, nil
+}
+
+// Run takes a backup every interval until the context is cancelled. Failures are logged and
+// retried at the next interval.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Errorf("Scheduled backups disabled: backup interval must be positive")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Backup(ctx); err != nil {
+				s.logger.Errorf("Scheduled backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// Backup writes one backup to the configured directory, uploads it if S3 is configured and
+// removes backups beyond the retention count. It returns the path of the new backup.
+func (s *Scheduler) Backup(ctx context.Context) (string, error) {
+	if err := os.MkdirAll(s.config.Path, 0700); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	path := filepath.Join(s.config.Path, filePrefix+s.now().UTC().Format("20060102T150405Z")+fileSuffix)
+	manifest, err := CreateFile(ctx, path, s.sources)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Infof("Backup written to %s (%d files)", path, len(manifest.Files))
+
+	if s.uploader != nil {
+		key, err := s.uploader.UploadFile(ctx, path)
+		if err != nil {
+			return path, err
+		}
+		s.logger.Infof("Backup uploaded to s3://%s/%s", s.config.S3.Bucket, key)
+	}
+
+	if err := s.prune(); err != nil {
+		s.logger.Warnf("Failed to prune old backups: %v", err)
+	}
+	return path, nil
+}
+
+// prune removes the oldest backups beyond the retention count
+func (s *Scheduler) prune() error {
+	if s.config.Retain <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.config.Path)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, filePrefix) && strings.HasSuffix(name, fileSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > s.config.Retain {
+		if err := os.Remove(filepath.Join(s.config.Path, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/igodwin/notifier/internal/archive"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/objectstore"
 )
 
 func TestSanitizeDatabaseURL(t *testing.T) {
@@ -61,3 +68,786 @@ func TestSanitizeDatabaseURL(t *testing.T) {
 		})
 	}
 }
+
+// TestValidateGRPCServer tests validation of gRPC keepalive durations
+func TestValidateGRPCServer(t *testing.T) {
+	tests := []struct {
+		name    string
+		grpc    GRPCServerConfig
+		wantErr bool
+	}{
+		{"defaults", GRPCServerConfig{}, false},
+		{"valid", GRPCServerConfig{KeepaliveTime: "30s", MaxConnectionAge: "5m", MinClientPingInterval: "10s"}, false},
+		{"unparseable", GRPCServerConfig{KeepaliveTimeout: "ten seconds"}, true},
+		{"negative", GRPCServerConfig{MaxConnectionIdle: "-1m"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{GRPC: tt.grpc}}
+			err := cfg.validateGRPCServer()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateGRPCServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidatePostgresQueue tests the PostgreSQL queue requires a URL and positive durations
+func TestValidatePostgresQueue(t *testing.T) {
+	tests := []struct {
+		name     string
+		postgres *domain.PostgresQueueConfig
+		wantErr  bool
+	}{
+		{"valid", &domain.PostgresQueueConfig{URL: "postgresql://notifier@db/notifier", PollInterval: "250ms", LeaseTimeout: "10m"}, false},
+		{"default durations", &domain.PostgresQueueConfig{URL: "postgresql://notifier@db/notifier"}, false},
+		{"missing config", nil, true},
+		{"missing url", &domain.PostgresQueueConfig{PollInterval: "1s"}, true},
+		{"unparseable poll interval", &domain.PostgresQueueConfig{URL: "postgresql://db", PollInterval: "soon"}, true},
+		{"zero lease", &domain.PostgresQueueConfig{URL: "postgresql://db", LeaseTimeout: "0s"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Queue: domain.QueueConfig{Type: "postgres", Postgres: tt.postgres}}
+			err := cfg.validatePostgresQueue()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validatePostgresQueue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateRetryBackoff tests retry backoff strategy and delay validation
+func TestValidateRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		queue   domain.QueueConfig
+		wantErr bool
+	}{
+		{"defaults", domain.QueueConfig{RetryBackoff: "exponential", RetryBaseDelay: "1s", RetryMaxDelay: "5m"}, false},
+		{"immediate", domain.QueueConfig{RetryBackoff: "fixed", RetryBaseDelay: "0s"}, false},
+		{"invalid strategy", domain.QueueConfig{RetryBackoff: "random"}, true},
+		{"invalid base delay", domain.QueueConfig{RetryBackoff: "linear", RetryBaseDelay: "soon"}, true},
+		{"negative base delay", domain.QueueConfig{RetryBackoff: "linear", RetryBaseDelay: "-1s"}, true},
+		{"zero max delay", domain.QueueConfig{RetryBackoff: "linear", RetryMaxDelay: "0s"}, true},
+		{"base over max", domain.QueueConfig{RetryBackoff: "linear", RetryBaseDelay: "10m", RetryMaxDelay: "5m"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Queue: tt.queue}
+			err := cfg.validateRetryBackoff()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRetryBackoff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateStore tests the notification store type and its PostgreSQL URL
+func TestValidateStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   StoreConfig
+		wantErr bool
+	}{
+		{"default", StoreConfig{}, false},
+		{"memory", StoreConfig{Type: "memory"}, false},
+		{"postgres", StoreConfig{Type: "postgres", Postgres: PostgresStoreConfig{URL: "postgresql://notifier@db/notifier"}}, false},
+		{"postgres without url", StoreConfig{Type: "postgres"}, true},
+		{"file", StoreConfig{Type: "file", File: FileStoreConfig{Path: "/var/lib/notifier/store.json", FlushInterval: "5s"}}, false},
+		{"file without path", StoreConfig{Type: "file", File: FileStoreConfig{FlushInterval: "5s"}}, true},
+		{"file with invalid flush interval", StoreConfig{Type: "file", File: FileStoreConfig{Path: "store.json", FlushInterval: "0s"}}, true},
+		{"unknown type", StoreConfig{Type: "redis"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Store: tt.store}
+			err := cfg.validateStore()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateArchive(t *testing.T) {
+	bucket := objectstore.Config{Bucket: "archive", Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	valid := archive.Config{Enabled: true, After: "720h", Interval: "24h", BatchSize: 1000, S3: bucket}
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"disabled", func(c *Config) { c.Archive = archive.Config{} }, false},
+		{"valid", func(c *Config) {}, false},
+		{"no bucket", func(c *Config) { c.Archive.S3.Bucket = "" }, true},
+		{"no credentials", func(c *Config) { c.Archive.S3.SecretAccessKey = "" }, true},
+		{"bad after", func(c *Config) { c.Archive.After = "30 days" }, true},
+		{"short interval", func(c *Config) { c.Archive.Interval = "10s" }, true},
+		{"no batch size", func(c *Config) { c.Archive.BatchSize = 0 }, true},
+		{"retention longer", func(c *Config) { c.Retention = NotificationRetentionConfig{Enabled: true, TTL: "2160h"} }, false},
+		{"retention shorter", func(c *Config) { c.Retention = NotificationRetentionConfig{Enabled: true, TTL: "168h"} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Archive: valid}
+			tt.modify(cfg)
+			err := cfg.validateArchive()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateBudgets(t *testing.T) {
+	tests := []struct {
+		name    string
+		budgets BudgetConfig
+		wantErr bool
+	}{
+		{"disabled", BudgetConfig{Action: "bogus"}, false},
+		{"valid", BudgetConfig{Enabled: true, Action: "alert", Rules: []BudgetRuleConfig{{Origin: "cron", Limit: 10, Action: "block"}, {Origin: "*", Limit: 100}}}, false},
+		{"invalid action", BudgetConfig{Enabled: true, Action: "drop"}, true},
+		{"missing origin", BudgetConfig{Enabled: true, Action: "alert", Rules: []BudgetRuleConfig{{Limit: 10}}}, true},
+		{"zero limit", BudgetConfig{Enabled: true, Action: "alert", Rules: []BudgetRuleConfig{{Origin: "cron"}}}, true},
+		{"invalid rule action", BudgetConfig{Enabled: true, Action: "alert", Rules: []BudgetRuleConfig{{Origin: "cron", Limit: 10, Action: "drop"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Budgets: tt.budgets}
+			err := cfg.validateBudgets()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBudgets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateQuietHours tests quiet hours window time, timezone and day validation
+func TestValidateQuietHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  QuietHoursWindowConfig
+		wantErr bool
+	}{
+		{"valid", QuietHoursWindowConfig{Type: "slack", Account: "team", Start: "22:00", End: "07:00", Timezone: "Europe/London", Days: []string{"Fri", "saturday"}}, false},
+		{"missing type", QuietHoursWindowConfig{Start: "22:00", End: "07:00"}, true},
+		{"invalid start", QuietHoursWindowConfig{Type: "slack", Start: "10pm", End: "07:00"}, true},
+		{"invalid end", QuietHoursWindowConfig{Type: "slack", Start: "22:00", End: "24:00"}, true},
+		{"empty window", QuietHoursWindowConfig{Type: "slack", Start: "22:00", End: "22:00"}, true},
+		{"invalid timezone", QuietHoursWindowConfig{Type: "slack", Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"}, true},
+		{"invalid day", QuietHoursWindowConfig{Type: "slack", Start: "22:00", End: "07:00", Days: []string{"weekend"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{QuietHours: QuietHoursConfig{Enabled: true, Windows: []QuietHoursWindowConfig{tt.window}}}
+			err := cfg.validateQuietHours()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateQuietHours() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateFailover tests failover rule and fallback chain validation
+func TestValidateFailover(t *testing.T) {
+	ntfy := FallbackConfig{Type: "ntfy", Recipients: []string{"alerts"}}
+	tests := []struct {
+		name    string
+		rule    FailoverRuleConfig
+		wantErr bool
+	}{
+		{"valid", FailoverRuleConfig{Type: "slack", Account: "team", MinPriority: "high", Fallbacks: []FallbackConfig{ntfy, {Type: "email", Account: "work", Recipients: []string{"oncall@example.com"}}}}, false},
+		{"missing type", FailoverRuleConfig{Fallbacks: []FallbackConfig{ntfy}}, true},
+		{"no fallbacks", FailoverRuleConfig{Type: "slack"}, true},
+		{"fallback without type", FailoverRuleConfig{Type: "slack", Fallbacks: []FallbackConfig{{Recipients: []string{"alerts"}}}}, true},
+		{"fallback without recipients", FailoverRuleConfig{Type: "slack", Fallbacks: []FallbackConfig{{Type: "ntfy"}}}, true},
+		{"invalid priority", FailoverRuleConfig{Type: "slack", MinPriority: "urgent", Fallbacks: []FallbackConfig{ntfy}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Failover: FailoverConfig{Rules: []FailoverRuleConfig{tt.rule}}}
+			err := cfg.validateFailover()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFailover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateRouting tests tag routing rule and priority account validation
+func TestValidateRouting(t *testing.T) {
+	payments := map[string]string{"team": "payments"}
+	tests := []struct {
+		name    string
+		rule    RoutingRuleConfig
+		wantErr bool
+	}{
+		{"valid", RoutingRuleConfig{Tags: payments, Type: "slack", Account: "payments", Contacts: []string{"payments-oncall"}}, false},
+		{"contacts only", RoutingRuleConfig{Tags: payments, Contacts: []string{"payments-oncall"}}, false},
+		{"no tags", RoutingRuleConfig{Type: "slack", Account: "payments"}, true},
+		{"nothing routed", RoutingRuleConfig{Tags: payments, Type: "slack"}, true},
+		{"account without type", RoutingRuleConfig{Tags: payments, Account: "payments"}, true},
+		{"empty tag key", RoutingRuleConfig{Tags: map[string]string{"": "payments"}, Contacts: []string{"payments-oncall"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Routing: RoutingConfig{Rules: []RoutingRuleConfig{tt.rule}}}
+			err := cfg.validateRouting()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRouting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	priorities := []struct {
+		name       string
+		priorities map[string]map[string]string
+		wantErr    bool
+	}{
+		{"valid", map[string]map[string]string{"email": {"critical": "transactional", "low": "bulk"}}, false},
+		{"invalid priority", map[string]map[string]string{"email": {"urgent": "transactional"}}, true},
+		{"missing account", map[string]map[string]string{"email": {"critical": ""}}, true},
+	}
+	for _, tt := range priorities {
+		t.Run("priorities "+tt.name, func(t *testing.T) {
+			cfg := &Config{Routing: RoutingConfig{Priorities: tt.priorities}}
+			err := cfg.validateRouting()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRouting() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateTenancy tests tenant client, account and default account validation
+func TestValidateTenancy(t *testing.T) {
+	tests := []struct {
+		name        string
+		authEnabled bool
+		tenants     map[string]TenantConfig
+		wantErr     bool
+	}{
+		{"valid", true, map[string]TenantConfig{
+			"payments": {Clients: []string{"billing-service"}, Accounts: []string{"email:payments"}, DefaultAccounts: map[string]string{"email": "payments"}},
+			"search":   {Clients: []string{"indexer"}},
+		}, false},
+		{"auth disabled", false, map[string]TenantConfig{"payments": {Clients: []string{"billing-service"}}}, true},
+		{"client in two tenants", true, map[string]TenantConfig{
+			"payments": {Clients: []string{"billing-service"}},
+			"search":   {Clients: []string{"billing-service"}},
+		}, true},
+		{"account without type", true, map[string]TenantConfig{"payments": {Accounts: []string{"payments"}}}, true},
+		{"empty default account", true, map[string]TenantConfig{"payments": {DefaultAccounts: map[string]string{"email": ""}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Auth: AuthConfig{Enabled: tt.authEnabled}, Tenancy: TenancyConfig{Enabled: true, Tenants: tt.tenants}}
+			err := cfg.validateTenancy()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTenancy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateQuotas tests quota validation
+func TestValidateQuotas(t *testing.T) {
+	tests := []struct {
+		name        string
+		authEnabled bool
+		rules       []QuotaRuleConfig
+		wantErr     bool
+	}{
+		{"valid", true, []QuotaRuleConfig{
+			{Client: "billing-service", RequestsPerMinute: 600},
+			{Tenant: "Payments", NotificationsPerDay: 50000},
+		}, false},
+		{"auth disabled", false, nil, true},
+		{"no subject", true, []QuotaRuleConfig{{RequestsPerMinute: 60}}, true},
+		{"client and tenant", true, []QuotaRuleConfig{{Client: "billing-service", Tenant: "payments"}}, true},
+		{"unknown tenant", true, []QuotaRuleConfig{{Tenant: "search", RequestsPerMinute: 60}}, true},
+		{"duplicate client", true, []QuotaRuleConfig{{Client: "indexer", RequestsPerMinute: 60}, {Client: "indexer", NotificationsPerDay: 100}}, true},
+		{"negative limit", true, []QuotaRuleConfig{{Client: "indexer", NotificationsPerDay: -1}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Auth:    AuthConfig{Enabled: tt.authEnabled},
+				Tenancy: TenancyConfig{Enabled: true, Tenants: map[string]TenantConfig{"payments": {}}},
+				Quotas:  QuotasConfig{Enabled: true, Default: QuotaLimitsConfig{RequestsPerMinute: 120}, Rules: tt.rules},
+			}
+			err := cfg.validateQuotas()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateQuotas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateServerTLS tests REST server TLS validation
+func TestValidateServerTLS(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	for _, path := range []string{cert, key} {
+		if err := os.WriteFile(path, []byte("pem"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		tls     ServerTLSConfig
+		wantErr bool
+	}{
+		{"disabled", ServerTLSConfig{}, false},
+		{"valid", ServerTLSConfig{Enabled: true, CertFile: cert, KeyFile: key, ClientCAFile: cert, MinVersion: "1.3"}, false},
+		{"missing key", ServerTLSConfig{Enabled: true, CertFile: cert}, true},
+		{"missing file", ServerTLSConfig{Enabled: true, CertFile: cert, KeyFile: filepath.Join(dir, "missing.key")}, true},
+		{"missing client CA", ServerTLSConfig{Enabled: true, CertFile: cert, KeyFile: key, ClientCAFile: filepath.Join(dir, "ca.crt")}, true},
+		{"invalid min version", ServerTLSConfig{Enabled: true, CertFile: cert, KeyFile: key, MinVersion: "1.1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{TLS: tt.tls}}
+			err := cfg.validateServerTLS()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateServerTLS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateJWT tests JWT authentication validation
+func TestValidateJWT(t *testing.T) {
+	valid := JWTAuthConfig{Enabled: true, Issuer: "https://idp.example.com", Audience: []string{"notifier"}, ClientIDClaim: "sub", Leeway: "30s", JWKSRefresh: "1h"}
+	tests := []struct {
+		name        string
+		authEnabled bool
+		modify      func(*JWTAuthConfig)
+		wantErr     bool
+	}{
+		{"valid", true, func(c *JWTAuthConfig) {}, false},
+		{"jwks url only", true, func(c *JWTAuthConfig) { c.Issuer, c.JWKSURL = "", "https://idp.example.com/keys" }, false},
+		{"auth disabled", false, func(c *JWTAuthConfig) {}, true},
+		{"no issuer or jwks url", true, func(c *JWTAuthConfig) { c.Issuer = "" }, true},
+		{"invalid issuer", true, func(c *JWTAuthConfig) { c.Issuer = "idp.example.com" }, true},
+		{"no audience", true, func(c *JWTAuthConfig) { c.Audience = nil }, true},
+		{"invalid leeway", true, func(c *JWTAuthConfig) { c.Leeway = "-1s" }, true},
+		{"invalid refresh", true, func(c *JWTAuthConfig) { c.JWKSRefresh = "0s" }, true},
+		{"tenant claim without tenancy", true, func(c *JWTAuthConfig) { c.TenantClaim = "org" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwt := valid
+			tt.modify(&jwt)
+			cfg := &Config{Auth: AuthConfig{Enabled: tt.authEnabled, JWT: jwt}}
+			err := cfg.validateJWT()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateJWT() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateBlackouts tests blackout window scope and time validation
+func TestValidateBlackouts(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  BlackoutWindowConfig
+		wantErr bool
+	}{
+		{"valid", BlackoutWindowConfig{Type: "email", Account: "smtp", Start: "2026-06-01T22:00:00Z", End: "2026-06-02T02:00:00+01:00", Reason: "SMTP relay upgrade"}, false},
+		{"global", BlackoutWindowConfig{Start: "2026-06-01T22:00:00Z", End: "2026-06-01T23:00:00Z"}, false},
+		{"account without type", BlackoutWindowConfig{Account: "smtp", Start: "2026-06-01T22:00:00Z", End: "2026-06-01T23:00:00Z"}, true},
+		{"invalid start", BlackoutWindowConfig{Start: "22:00", End: "2026-06-01T23:00:00Z"}, true},
+		{"invalid end", BlackoutWindowConfig{Start: "2026-06-01T22:00:00Z", End: "tomorrow"}, true},
+		{"ends before it starts", BlackoutWindowConfig{Start: "2026-06-01T22:00:00Z", End: "2026-06-01T21:00:00Z"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Blackouts: BlackoutsConfig{Windows: []BlackoutWindowConfig{tt.window}}}
+			err := cfg.validateBlackouts()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateBlackouts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateTemplates tests template name and syntax validation
+func TestValidateTemplates(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates []TemplateConfig
+		wantErr   bool
+	}{
+		{"valid", []TemplateConfig{{Name: "welcome", Subject: "Hi {{.name}}", Body: "Welcome, {{.name}}", HTMLBody: "<p>Welcome, {{.name}}</p>"}}, false},
+		{"body only", []TemplateConfig{{Name: "welcome", Body: "Welcome, {{upper .name}}"}}, false},
+		{"variants only", []TemplateConfig{{Name: "welcome", Variants: map[string]TemplateVariantConfig{"slack": {Body: "*Welcome*, {{.name}}"}, "sms": {Body: "Welcome"}}}}, false},
+		{"empty variant", []TemplateConfig{{Name: "welcome", Body: "Welcome", Variants: map[string]TemplateVariantConfig{"sms": {}}}}, true},
+		{"invalid variant", []TemplateConfig{{Name: "welcome", Body: "Welcome", Variants: map[string]TemplateVariantConfig{"slack": {Body: "{{.name"}}}}, true},
+		{"variant for another type", []TemplateConfig{{Name: "welcome", Type: "email", Body: "Welcome", Variants: map[string]TemplateVariantConfig{"sms": {Body: "Hi"}}}}, true},
+		{"missing name", []TemplateConfig{{Body: "Welcome"}}, true},
+		{"duplicate name", []TemplateConfig{{Name: "welcome", Body: "a"}, {Name: "welcome", Body: "b"}}, true},
+		{"empty", []TemplateConfig{{Name: "welcome"}}, true},
+		{"invalid subject", []TemplateConfig{{Name: "welcome", Subject: "Hi {{.name"}}, true},
+		{"invalid html body", []TemplateConfig{{Name: "welcome", HTMLBody: "<p>{{if .name}}</p>"}}, true},
+		{"unknown function", []TemplateConfig{{Name: "welcome", Body: "{{shout .name}}"}}, true},
+		{"locales", []TemplateConfig{{Name: "welcome", Body: "Welcome", Locales: map[string]TemplateLocaleConfig{"fr": {Body: "Bienvenue"}, "pt-br": {Variants: map[string]TemplateVariantConfig{"sms": {Body: "Oi"}}}}}}, false},
+		{"locales only", []TemplateConfig{{Name: "welcome", Locales: map[string]TemplateLocaleConfig{"fr": {Body: "Bienvenue"}}}}, false},
+		{"invalid locale", []TemplateConfig{{Name: "welcome", Body: "Welcome", Locales: map[string]TemplateLocaleConfig{"french!": {Body: "Bienvenue"}}}}, true},
+		{"empty locale", []TemplateConfig{{Name: "welcome", Body: "Welcome", Locales: map[string]TemplateLocaleConfig{"fr": {}}}}, true},
+		{"duplicate locale", []TemplateConfig{{Name: "welcome", Body: "Welcome", Locales: map[string]TemplateLocaleConfig{"pt-br": {Body: "Oi"}, "pt_BR": {Body: "Olá"}}}}, true},
+		{"layout", []TemplateConfig{{Name: "receipt", Type: "email", HTMLBody: "<p>Paid</p>", Layout: "responsive"}}, false},
+		{"unknown layout", []TemplateConfig{{Name: "receipt", HTMLBody: "<p>Paid</p>", Layout: "mjml"}}, true},
+		{"layout for another type", []TemplateConfig{{Name: "receipt", Type: "slack", Body: "Paid", Layout: "responsive"}}, true},
+		{"invalid locale body", []TemplateConfig{{Name: "welcome", Body: "Welcome", Locales: map[string]TemplateLocaleConfig{"fr": {Body: "{{.name"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Templates: TemplatesConfig{Definitions: tt.templates}}
+			err := cfg.validateTemplates()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateContacts tests contact and group validation
+func TestValidateContacts(t *testing.T) {
+	dba := ContactConfig{Name: "oncall-db", Addresses: map[string][]string{"email": {"dba@example.com"}, "slack": {"#db-oncall"}}}
+	tests := []struct {
+		name     string
+		contacts []ContactConfig
+		wantErr  bool
+	}{
+		{"valid", []ContactConfig{dba, {Name: "team-payments", Members: []string{"oncall-db"}}}, false},
+		{"missing name", []ContactConfig{{Addresses: map[string][]string{"email": {"a@example.com"}}}}, true},
+		{"invalid name", []ContactConfig{{Name: "on call", Addresses: map[string][]string{"email": {"a@example.com"}}}}, true},
+		{"duplicate name", []ContactConfig{dba, dba}, true},
+		{"empty", []ContactConfig{{Name: "oncall-db"}}, true},
+		{"no addresses for type", []ContactConfig{{Name: "oncall-db", Addresses: map[string][]string{"email": {}}}}, true},
+		{"unknown member", []ContactConfig{{Name: "team-payments", Members: []string{"oncall-db"}}}, true},
+		{"member of itself", []ContactConfig{{Name: "team-payments", Members: []string{"team-payments"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Contacts: ContactsConfig{Definitions: tt.contacts}}
+			err := cfg.validateContacts()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateContacts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateTemplateLocales tests default locale, locale fallback and email layout validation
+func TestValidateTemplateLocales(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates TemplatesConfig
+		wantErr   bool
+	}{
+		{"none", TemplatesConfig{}, false},
+		{"valid", TemplatesConfig{DefaultLocale: "en-US", LocaleFallbacks: map[string][]string{"pt-br": {"pt-PT", "es"}, "gl": {"pt"}}}, false},
+		{"malformed default locale", TemplatesConfig{DefaultLocale: "en US"}, true},
+		{"malformed locale", TemplatesConfig{LocaleFallbacks: map[string][]string{"p": {"es"}}}, true},
+		{"malformed fallback", TemplatesConfig{LocaleFallbacks: map[string][]string{"pt-br": {"pt--pt"}}}, true},
+		{"email layout", TemplatesConfig{EmailLayout: EmailLayoutConfig{AccentColor: "#2563eb", LogoURL: "https://example.com/logo.png"}}, false},
+		{"invalid accent color", TemplatesConfig{EmailLayout: EmailLayoutConfig{AccentColor: "blue"}}, true},
+		{"invalid logo url", TemplatesConfig{EmailLayout: EmailLayoutConfig{LogoURL: "logo.png"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Templates: tt.templates}
+			err := cfg.validateTemplates()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateContentPolicy tests content policy action, detector and alert validation
+func TestValidateContentPolicy(t *testing.T) {
+	alert := AlertTargetConfig{Type: "slack", Recipients: []string{"#security"}}
+	tests := []struct {
+		name    string
+		policy  ContentPolicyConfig
+		wantErr bool
+	}{
+		{"disabled", ContentPolicyConfig{Action: "bogus"}, false},
+		{"valid", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "secrets", EntropyThreshold: 4.5, Action: "block"}, {Detector: "pii"}}}, false},
+		{"invalid action", ContentPolicyConfig{Enabled: true, Action: "drop"}, true},
+		{"invalid detector", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "profanity"}}}, true},
+		{"phrases without phrases", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "phrases"}}}, true},
+		{"invalid pattern", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "regex", Pattern: "("}}}, true},
+		{"negative entropy", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "secrets", EntropyThreshold: -1}}}, true},
+		{"hold without alert", ContentPolicyConfig{Enabled: true, Action: "warn", Rules: []ContentRuleConfig{{Detector: "pii", Action: "hold"}}}, true},
+		{"hold with alert", ContentPolicyConfig{Enabled: true, Action: "hold", Alert: alert, Rules: []ContentRuleConfig{{Detector: "pii"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{ContentPolicy: tt.policy}
+			err := cfg.validateContentPolicy()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateContentPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateSpamCheck tests spam check engine, address, timeout and action validation
+func TestValidateSpamCheck(t *testing.T) {
+	valid := SpamCheckConfig{Enabled: true, Engine: "rspamd", Address: "http://rspamd:11333", Timeout: "10s", Action: "block"}
+	tests := []struct {
+		name    string
+		modify  func(*SpamCheckConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *SpamCheckConfig) {}, false},
+		{"disabled", func(c *SpamCheckConfig) { c.Enabled = false; c.Engine = "bogus" }, false},
+		{"invalid engine", func(c *SpamCheckConfig) { c.Engine = "bogofilter" }, true},
+		{"missing address", func(c *SpamCheckConfig) { c.Address = "" }, true},
+		{"invalid timeout", func(c *SpamCheckConfig) { c.Timeout = "soon" }, true},
+		{"negative threshold", func(c *SpamCheckConfig) { c.Threshold = -1 }, true},
+		{"invalid action", func(c *SpamCheckConfig) { c.Action = "drop" }, true},
+		{"hold without alert", func(c *SpamCheckConfig) { c.Action = "hold" }, true},
+		{"hold with alert", func(c *SpamCheckConfig) { c.Action = "hold"; c.Alert = AlertTargetConfig{Type: "slack"} }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spamCheck := valid
+			tt.modify(&spamCheck)
+			cfg := &Config{SpamCheck: spamCheck}
+			err := cfg.validateSpamCheck()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSpamCheck() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateMirror tests traffic mirroring URL, percent, timeout and concurrency validation
+func TestValidateMirror(t *testing.T) {
+	valid := MirrorConfig{Enabled: true, URL: "http://notifier-canary:8080", Percent: 10, Timeout: "10s", MaxInFlight: 100}
+	tests := []struct {
+		name    string
+		modify  func(*MirrorConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *MirrorConfig) {}, false},
+		{"disabled", func(c *MirrorConfig) { c.Enabled = false; c.URL = "" }, false},
+		{"missing scheme", func(c *MirrorConfig) { c.URL = "notifier-canary:8080" }, true},
+		{"zero percent", func(c *MirrorConfig) { c.Percent = 0 }, true},
+		{"over 100 percent", func(c *MirrorConfig) { c.Percent = 150 }, true},
+		{"invalid timeout", func(c *MirrorConfig) { c.Timeout = "0s" }, true},
+		{"zero max in flight", func(c *MirrorConfig) { c.MaxInFlight = 0 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mirror := valid
+			tt.modify(&mirror)
+			cfg := &Config{Mirror: mirror}
+			err := cfg.validateMirror()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateMirror() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateHeartbeats tests heartbeat validation
+func TestValidateHeartbeats(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*HeartbeatsConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *HeartbeatsConfig) {}, false},
+		{"disabled", func(c *HeartbeatsConfig) { c.Enabled = false; c.CheckFrequency = "" }, false},
+		{"check alert only", func(c *HeartbeatsConfig) {
+			c.Alert = AlertTargetConfig{}
+			c.Checks[0].Alert = AlertTargetConfig{Type: "email", Recipients: []string{"ops@example.com"}}
+		}, false},
+		{"invalid check frequency", func(c *HeartbeatsConfig) { c.CheckFrequency = "soon" }, true},
+		{"invalid name", func(c *HeartbeatsConfig) { c.Checks[0].Name = "nightly/backup" }, true},
+		{"duplicate name", func(c *HeartbeatsConfig) { c.Checks = append(c.Checks, c.Checks[0]) }, true},
+		{"zero period", func(c *HeartbeatsConfig) { c.Checks[0].Period = "0s" }, true},
+		{"negative grace", func(c *HeartbeatsConfig) { c.Checks[0].Grace = "-1m" }, true},
+		{"no alert", func(c *HeartbeatsConfig) { c.Alert = AlertTargetConfig{} }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Heartbeats: HeartbeatsConfig{
+				Enabled:        true,
+				CheckFrequency: "30s",
+				Alert:          AlertTargetConfig{Type: "slack", Recipients: []string{"#ops"}},
+				Checks:         []HeartbeatCheckConfig{{Name: "nightly-backup", Period: "24h", Grace: "30m"}},
+			}}
+			tt.modify(&cfg.Heartbeats)
+			err := cfg.validateHeartbeats()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateHeartbeats() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateCanary tests canary validation
+func TestValidateCanary(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*CanaryConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *CanaryConfig) {}, false},
+		{"disabled", func(c *CanaryConfig) { c.Enabled = false; c.Targets = nil }, false},
+		{"invalid interval", func(c *CanaryConfig) { c.Interval = "0s" }, true},
+		{"timeout exceeds interval", func(c *CanaryConfig) { c.Timeout = "10m" }, true},
+		{"no targets", func(c *CanaryConfig) { c.Targets = nil }, true},
+		{"target without recipients", func(c *CanaryConfig) { c.Targets[0].Recipients = nil }, true},
+		{"duplicate target", func(c *CanaryConfig) { c.Targets = append(c.Targets, c.Targets[0]) }, true},
+		{"no alert", func(c *CanaryConfig) { c.Alert = AlertTargetConfig{} }, true},
+		{"alert through a target", func(c *CanaryConfig) { c.Alert = AlertTargetConfig{Type: "slack", Recipients: []string{"#ops"}} }, true},
+		{"alert through another account", func(c *CanaryConfig) {
+			c.Alert = AlertTargetConfig{Type: "slack", Account: "backup", Recipients: []string{"#ops"}}
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Canary: CanaryConfig{
+				Enabled:        true,
+				Interval:       "5m",
+				Timeout:        "2m",
+				CheckFrequency: "15s",
+				Alert:          AlertTargetConfig{Type: "pagerduty"},
+				Targets: []AlertTargetConfig{
+					{Type: "slack", Recipients: []string{"#canary"}},
+					{Type: "email", Recipients: []string{"canary@example.com"}},
+				},
+			}}
+			tt.modify(&cfg.Canary)
+			err := cfg.validateCanary()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCanary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidateCompat tests compatibility profile validation and defaults
+func TestValidateCompat(t *testing.T) {
+	valid := CompatProfile{
+		ClientIDs: []string{"legacy-app"},
+		Fields:    []CompatField{{From: "message.title", To: "subject"}, {From: "campaign", To: "metadata.campaign"}},
+	}
+	tests := []struct {
+		name    string
+		modify  func(*CompatConfig)
+		wantErr bool
+	}{
+		{"valid", func(c *CompatConfig) {}, false},
+		{"no keys or clients", func(c *CompatConfig) { p := c.Profiles["legacy"]; p.ClientIDs = nil; c.Profiles["legacy"] = p }, true},
+		{"client in two profiles", func(c *CompatConfig) { c.Profiles["other"] = CompatProfile{ClientIDs: []string{"legacy-app"}} }, true},
+		{"invalid unmapped", func(c *CompatConfig) { p := c.Profiles["legacy"]; p.Unmapped = "keep"; c.Profiles["legacy"] = p }, true},
+		{"unknown target", func(c *CompatConfig) {
+			p := c.Profiles["legacy"]
+			p.Fields = []CompatField{{From: "title", To: "headline"}}
+			c.Profiles["legacy"] = p
+		}, true},
+		{"empty metadata key", func(c *CompatConfig) {
+			p := c.Profiles["legacy"]
+			p.Fields = []CompatField{{From: "title", To: "metadata."}}
+			c.Profiles["legacy"] = p
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Compat: CompatConfig{Profiles: map[string]CompatProfile{"legacy": valid}}}
+			tt.modify(&cfg.Compat)
+			err := cfg.validateCompat()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCompat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if profile := cfg.Compat.Profiles["legacy"]; profile.Unmapped != CompatUnmappedMetadata || profile.BatchField != "notifications" {
+					t.Errorf("Expected defaults to be applied, got %+v", profile)
+				}
+			}
+		})
+	}
+}
+
+// TestParsePolicyProposal tests that a proposal replaces only the sections it contains
+func TestParsePolicyProposal(t *testing.T) {
+	current := PolicySet{
+		ContentPolicy: ContentPolicyConfig{Enabled: true, Action: ContentActionWarn, Rules: []ContentRuleConfig{{Detector: ContentDetectorPII}}},
+		Budgets:       BudgetConfig{Action: BudgetActionAlert},
+	}
+
+	proposed, err := ParsePolicyProposal([]byte("budgets:\n  enabled: true\n  action: block\n  rules:\n    - origin: billing\n      limit: 10\n"), current)
+	if err != nil {
+		t.Fatalf("ParsePolicyProposal() error = %v", err)
+	}
+	if !proposed.Budgets.Enabled || proposed.Budgets.Action != BudgetActionBlock || len(proposed.Budgets.Rules) != 1 || proposed.Budgets.Rules[0].Limit != 10 {
+		t.Errorf("Budgets not replaced: %+v", proposed.Budgets)
+	}
+	if !proposed.ContentPolicy.Enabled || len(proposed.ContentPolicy.Rules) != 1 {
+		t.Errorf("Content policy should be kept: %+v", proposed.ContentPolicy)
+	}
+
+	for name, proposal := range map[string]string{
+		"no policy sections": "server:\n  port: 8080\n",
+		"invalid yaml":       "budgets: [",
+		"invalid action":     "content_policy:\n  enabled: true\n  rules:\n    - detector: pii\n      action: shred\n",
+	} {
+		if _, err := ParsePolicyProposal([]byte(proposal), current); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// TestEnabledFeatures tests that enabled subsystems are listed in order
+func TestEnabledFeatures(t *testing.T) {
+	cfg := &Config{}
+	if features := cfg.EnabledFeatures(); len(features) != 0 {
+		t.Errorf("Expected no features, got %v", features)
+	}
+
+	cfg.Auth.Enabled = true
+	cfg.Strict.Clients = []string{"billing"}
+	cfg.Limits.MaxRecipients = 100
+	cfg.Preview.Enabled = true
+	got := strings.Join(cfg.EnabledFeatures(), ",")
+	if got != "auth,limits,preview,strict" {
+		t.Errorf("Expected auth,limits,preview,strict, got %s", got)
+	}
+}
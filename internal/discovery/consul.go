@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// consulRegistrar registers the instance with the local Consul agent. The agent runs
+// the health check itself, so no heartbeat is needed.
+type consulRegistrar struct {
+	client   *httpClient
+	instance Instance
+	tags     []string
+	interval time.Duration
+}
+
+// consulService is the agent service registration payload
+type consulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Tags    []string          `json:"Tags,omitempty"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Check   *consulCheck      `json:"Check,omitempty"`
+}
+
+// consulCheck is the agent health check definition
+type consulCheck struct {
+	HTTP                           string `json:"HTTP,omitempty"`
+	GRPC                           string `json:"GRPC,omitempty"`
+	Interval                       string `json:"Interval"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+// Register registers the service and its health check with the agent
+func (r *consulRegistrar) Register(ctx context.Context) error {
+	service := consulService{
+		ID:      r.instance.ID,
+		Name:    r.instance.Name,
+		Address: r.instance.Address,
+		Tags:    r.tags,
+		Meta: map[string]string{
+			"version": r.instance.Version,
+		},
+	}
+
+	// Prefer the REST port (and its health endpoint) as the primary port; fall back to gRPC
+	check := &consulCheck{
+		Interval:                       r.interval.String(),
+		DeregisterCriticalServiceAfter: "10m",
+	}
+	if r.instance.GRPCPort > 0 {
+		service.Meta["grpc_port"] = strconv.Itoa(r.instance.GRPCPort)
+		service.Port = r.instance.GRPCPort
+		check.GRPC = net.JoinHostPort(r.instance.Address, strconv.Itoa(r.instance.GRPCPort))
+	}
+	if r.instance.RESTPort > 0 {
+		service.Meta["rest_port"] = strconv.Itoa(r.instance.RESTPort)
+		service.Port = r.instance.RESTPort
+		if r.instance.HealthURL != "" {
+			check.GRPC = ""
+			check.HTTP = r.instance.HealthURL
+		}
+	}
+	if check.HTTP != "" || check.GRPC != "" {
+		service.Check = check
+	}
+
+	if err := r.client.do(ctx, "PUT", "/v1/agent/service/register", service, nil); err != nil {
+		return fmt.Errorf("failed to register with Consul: %w", err)
+	}
+	return nil
+}
+
+// Deregister removes the service from the agent
+func (r *consulRegistrar) Deregister(ctx context.Context) error {
+	if err := r.client.do(ctx, "PUT", "/v1/agent/service/deregister/"+url.PathEscape(r.instance.ID), nil, nil); err != nil {
+		return fmt.Errorf("failed to deregister from Consul: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package discovery registers the running service with a service discovery backend
+// (Consul or etcd) so clients can find notifier instances instead of hard-coding addresses.
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// Supported discovery backends
+const (
+	BackendConsul = "consul"
+	BackendEtcd   = "etcd"
+)
+
+// Config contains service discovery registration configuration
+type Config struct {
+	Enabled          bool     `mapstructure:"enabled"`           // Register with the backend at startup
+	Backend          string   `mapstructure:"backend"`           // consul or etcd
+	Address          string   `mapstructure:"address"`           // Consul agent or etcd endpoint URL (e.g., http://localhost:8500)
+	Token            string   `mapstructure:"token"`             // Consul ACL token (optional)
+	ServiceName      string   `mapstructure:"service_name"`      // Name clients look up (default: notifier)
+	ServiceID        string   `mapstructure:"service_id"`        // Unique instance ID (default: <service_name>-<hostname>)
+	AdvertiseAddress string   `mapstructure:"advertise_address"` // Address clients should dial (default: hostname)
+	Tags             []string `mapstructure:"tags"`              // Consul tags (optional)
+	KeyPrefix        string   `mapstructure:"key_prefix"`        // etcd key prefix (default: /services/)
+	TTL              string   `mapstructure:"ttl"`               // etcd lease TTL and Consul health check interval (e.g., "30s")
+}
+
+// IsValidBackend reports whether a discovery backend is supported
+func IsValidBackend(backend string) bool {
+	return backend == BackendConsul || backend == BackendEtcd
+}
+
+// Instance describes the running service as advertised to clients
+type Instance struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	GRPCPort  int    `json:"grpc_port,omitempty"`
+	RESTPort  int    `json:"rest_port,omitempty"`
+	HealthURL string `json:"health_url,omitempty"`
+	Version   string `json:"version"`
+}
+
+// Registrar registers and deregisters a service instance
+type Registrar interface {
+	// Register advertises the instance; backends that need heartbeats keep them
+	// running until Deregister is called
+	Register(ctx context.Context) error
+
+	// Deregister removes the instance from the backend
+	Deregister(ctx context.Context) error
+}
+
+// NewRegistrar creates a registrar for the configured backend
+func NewRegistrar(cfg Config, instance Instance, logger *logging.Logger) (Registrar, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("discovery address is required")
+	}
+
+	ttl, err := time.ParseDuration(cfg.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery ttl duration: %w", err)
+	}
+	if ttl < time.Second {
+		return nil, fmt.Errorf("discovery ttl must be at least 1s")
+	}
+
+	client := &httpClient{
+		baseURL: strings.TrimSuffix(cfg.Address, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+
+	switch cfg.Backend {
+	case BackendConsul:
+		client.headers = map[string]string{}
+		if cfg.Token != "" {
+			client.headers["X-Consul-Token"] = cfg.Token
+		}
+		return &consulRegistrar{client: client, instance: instance, tags: cfg.Tags, interval: ttl}, nil
+	case BackendEtcd:
+		prefix := cfg.KeyPrefix
+		if prefix == "" {
+			prefix = "/services/"
+		}
+		return &etcdRegistrar{
+			client:   client,
+			instance: instance,
+			key:      strings.TrimSuffix(prefix, "/") + "/" + instance.Name + "/" + instance.ID,
+			ttl:      ttl,
+			logger:   logger,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid discovery backend: %s", cfg.Backend)
+	}
+}
+
+// httpClient is a minimal JSON client for the backends' HTTP APIs
+type httpClient struct {
+	baseURL string
+	headers map[string]string
+	http    *http.Client
+}
+
+// do sends a JSON request and decodes the JSON response into out (if not nil)
+func (c *httpClient) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", path, err)
+		}
+	}
+	return nil
+}
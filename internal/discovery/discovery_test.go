@@ -0,0 +1,161 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+var testInstance = Instance{
+	ID:        "notifier-host1",
+	Name:      "notifier",
+	Address:   "10.0.0.5",
+	GRPCPort:  50051,
+	RESTPort:  8080,
+	HealthURL: "http://10.0.0.5:8080/health",
+	Version:   "1.2.3",
+}
+
+// TestConsulRegistration tests the Consul agent register and deregister calls
+func TestConsulRegistration(t *testing.T) {
+	var registered consulService
+	var deregisteredPath, token string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Consul-Token")
+		switch r.URL.Path {
+		case "/v1/agent/service/register":
+			if err := json.NewDecoder(r.Body).Decode(&registered); err != nil {
+				t.Errorf("Failed to decode registration: %v", err)
+			}
+		default:
+			deregisteredPath = r.URL.Path
+		}
+	}))
+	defer server.Close()
+
+	registrar, err := NewRegistrar(Config{
+		Backend: BackendConsul,
+		Address: server.URL,
+		Token:   "acl-token",
+		Tags:    []string{"prod"},
+		TTL:     "15s",
+	}, testInstance, logging.New(logging.ErrorLevel, os.Stderr))
+	if err != nil {
+		t.Fatalf("NewRegistrar() error = %v", err)
+	}
+
+	if err := registrar.Register(context.Background()); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if token != "acl-token" {
+		t.Errorf("X-Consul-Token = %q, want acl-token", token)
+	}
+	if registered.ID != testInstance.ID || registered.Port != 8080 {
+		t.Errorf("Unexpected registration: %+v", registered)
+	}
+	if registered.Meta["version"] != "1.2.3" || registered.Meta["grpc_port"] != "50051" {
+		t.Errorf("Unexpected meta: %v", registered.Meta)
+	}
+	if registered.Check == nil || registered.Check.HTTP != testInstance.HealthURL || registered.Check.Interval != "15s" {
+		t.Errorf("Unexpected check: %+v", registered.Check)
+	}
+
+	if err := registrar.Deregister(context.Background()); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+	if deregisteredPath != "/v1/agent/service/deregister/notifier-host1" {
+		t.Errorf("Deregister path = %s", deregisteredPath)
+	}
+}
+
+// TestEtcdRegistration tests the etcd lease, key write and revoke calls
+func TestEtcdRegistration(t *testing.T) {
+	var mu sync.Mutex
+	calls := make(map[string]map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]string
+		json.Unmarshal(body, &req)
+
+		mu.Lock()
+		calls[r.URL.Path] = req
+		mu.Unlock()
+
+		if r.URL.Path == "/v3/lease/grant" {
+			w.Write([]byte(`{"ID":"7587862072452093701","TTL":"30"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	registrar, err := NewRegistrar(Config{
+		Backend: BackendEtcd,
+		Address: server.URL,
+		TTL:     "30s",
+	}, testInstance, logging.New(logging.ErrorLevel, os.Stderr))
+	if err != nil {
+		t.Fatalf("NewRegistrar() error = %v", err)
+	}
+
+	if err := registrar.Register(context.Background()); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registrar.Deregister(context.Background()); err != nil {
+		t.Fatalf("Deregister() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if calls["/v3/lease/grant"]["TTL"] != "30" {
+		t.Errorf("Lease TTL = %q, want 30", calls["/v3/lease/grant"]["TTL"])
+	}
+
+	put := calls["/v3/kv/put"]
+	key, _ := base64.StdEncoding.DecodeString(put["key"])
+	if string(key) != "/services/notifier/notifier-host1" {
+		t.Errorf("Key = %q", key)
+	}
+	if put["lease"] != "7587862072452093701" {
+		t.Errorf("Key lease = %q", put["lease"])
+	}
+	value, _ := base64.StdEncoding.DecodeString(put["value"])
+	var advertised Instance
+	if err := json.Unmarshal(value, &advertised); err != nil || advertised != testInstance {
+		t.Errorf("Advertised instance = %+v, err = %v", advertised, err)
+	}
+
+	if calls["/v3/lease/revoke"]["ID"] != "7587862072452093701" {
+		t.Error("Expected the lease to be revoked on deregister")
+	}
+}
+
+// TestNewRegistrarValidation tests invalid configurations are rejected
+func TestNewRegistrarValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing address", Config{Backend: BackendConsul, TTL: "30s"}},
+		{"unknown backend", Config{Backend: "zookeeper", Address: "http://localhost", TTL: "30s"}},
+		{"bad ttl", Config{Backend: BackendEtcd, Address: "http://localhost", TTL: "soon"}},
+		{"short ttl", Config{Backend: BackendEtcd, Address: "http://localhost", TTL: "100ms"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRegistrar(tt.cfg, testInstance, nil); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// etcdRegistrar writes the instance under a key bound to a lease and keeps the lease
+// alive, so the key disappears on its own if the process dies without deregistering.
+// It uses the etcd v3 JSON gateway, which encodes int64 fields as strings.
+type etcdRegistrar struct {
+	client   *httpClient
+	instance Instance
+	key      string
+	ttl      time.Duration
+	logger   *logging.Logger
+
+	mu      sync.Mutex
+	leaseID string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// etcdLeaseResponse is the response of lease grant and keepalive
+type etcdLeaseResponse struct {
+	ID  string `json:"ID"`
+	TTL string `json:"TTL"`
+}
+
+// Register writes the instance key and starts the lease keepalive loop
+func (r *etcdRegistrar) Register(ctx context.Context) error {
+	if err := r.put(ctx); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	r.mu.Unlock()
+
+	go r.keepAlive(r.stop, r.done)
+	return nil
+}
+
+// put grants a new lease and writes the instance key bound to it
+func (r *etcdRegistrar) put(ctx context.Context) error {
+	var lease etcdLeaseResponse
+	grant := map[string]string{"TTL": strconv.Itoa(int(r.ttl.Seconds()))}
+	if err := r.client.do(ctx, "POST", "/v3/lease/grant", grant, &lease); err != nil {
+		return fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+
+	value, err := json.Marshal(r.instance)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instance: %w", err)
+	}
+
+	kv := map[string]string{
+		"key":   base64.StdEncoding.EncodeToString([]byte(r.key)),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": lease.ID,
+	}
+	if err := r.client.do(ctx, "POST", "/v3/kv/put", kv, nil); err != nil {
+		return fmt.Errorf("failed to register with etcd: %w", err)
+	}
+
+	r.mu.Lock()
+	r.leaseID = lease.ID
+	r.mu.Unlock()
+	return nil
+}
+
+// keepAlive refreshes the lease at a third of its TTL, re-registering if it has expired
+// (e.g., after a network partition longer than the TTL)
+func (r *etcdRegistrar) keepAlive(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(r.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.ttl/3)
+			if err := r.refresh(ctx); err != nil {
+				r.logger.Warnf("Failed to refresh etcd registration - key=%s, error=%v", r.key, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// refresh renews the current lease or registers again under a new one
+func (r *etcdRegistrar) refresh(ctx context.Context) error {
+	r.mu.Lock()
+	leaseID := r.leaseID
+	r.mu.Unlock()
+
+	var resp struct {
+		Result etcdLeaseResponse `json:"result"`
+	}
+	if err := r.client.do(ctx, "POST", "/v3/lease/keepalive", map[string]string{"ID": leaseID}, &resp); err != nil {
+		return err
+	}
+
+	// A missing or zero TTL means the lease expired and the key is gone
+	if ttl, _ := strconv.Atoi(resp.Result.TTL); ttl <= 0 {
+		r.logger.Warnf("etcd lease expired, re-registering - key=%s", r.key)
+		return r.put(ctx)
+	}
+	return nil
+}
+
+// Deregister stops the keepalive loop and revokes the lease, which deletes the key
+func (r *etcdRegistrar) Deregister(ctx context.Context) error {
+	r.mu.Lock()
+	stop, done, leaseID := r.stop, r.done, r.leaseID
+	r.stop = nil
+	r.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	if leaseID == "" {
+		return nil
+	}
+	if err := r.client.do(ctx, "POST", "/v3/lease/revoke", map[string]string{"ID": leaseID}, nil); err != nil {
+		return fmt.Errorf("failed to deregister from etcd: %w", err)
+	}
+	return nil
+}
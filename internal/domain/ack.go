@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EscalationMetadata is the metadata key naming the notification a notification escalates.
+// Escalations still waiting to be sent are cancelled when that notification is acknowledged.
+const EscalationMetadata = "escalation_of"
+
+// Acknowledgement records that someone has seen a notification and is dealing with it
+type Acknowledgement struct {
+	NotificationID       string    `json:"notification_id"`
+	AcknowledgedBy       string    `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt       time.Time `json:"acknowledged_at"`
+	CancelledEscalations []string  `json:"cancelled_escalations,omitempty"` // Escalations cancelled by this acknowledgement
+}
+
+// Acknowledger is implemented by services whose notifications can be acknowledged
+type Acknowledger interface {
+	// AcknowledgeNotification acknowledges a notification and cancels its pending escalations.
+	// Acknowledging again keeps the first acknowledgement.
+	AcknowledgeNotification(ctx context.Context, id, by string) (*Acknowledgement, error)
+}
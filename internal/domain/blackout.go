@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBlackoutNotFound is returned when a blackout window doesn't exist
+var ErrBlackoutNotFound = errors.New("blackout window not found")
+
+// ErrBlackoutConfigured is returned when deleting a blackout window that comes from the config file
+var ErrBlackoutConfigured = errors.New("blackout window is configured and can't be deleted")
+
+// BlackoutWindow is a maintenance window during which notifications aren't sent. It covers every
+// notifier, every account of one type, or one account. Notifications that come due during the
+// window are held in the queue and sent when it ends.
+type BlackoutWindow struct {
+	ID         string           `json:"id"`
+	Type       NotificationType `json:"type,omitempty"`    // Empty covers every notifier
+	Account    string           `json:"account,omitempty"` // Empty covers every account of Type
+	Start      time.Time        `json:"start"`
+	End        time.Time        `json:"end"`
+	Reason     string           `json:"reason,omitempty"`
+	CreatedBy  string           `json:"created_by,omitempty"`
+	Configured bool             `json:"configured,omitempty"` // From the config file rather than the API
+}
+
+// BlackoutManager is implemented by services that hold notifications during blackout windows
+type BlackoutManager interface {
+	// CreateBlackoutWindow adds a window, returning it with its ID
+	CreateBlackoutWindow(ctx context.Context, window BlackoutWindow) (*BlackoutWindow, error)
+
+	// ListBlackoutWindows returns the windows that haven't ended, soonest first
+	ListBlackoutWindows(ctx context.Context) ([]*BlackoutWindow, error)
+
+	// DeleteBlackoutWindow removes a window created through the API. Notifications it's
+	// already holding are sent when it would have ended.
+	DeleteBlackoutWindow(ctx context.Context, id string) error
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrContactNotFound is returned when a notification or group references a contact that
+// doesn't exist
+var ErrContactNotFound = errors.New("contact not found")
+
+// ErrInvalidContact is returned when a contact is malformed, or has no address on the channel
+// a notification is sent through
+var ErrInvalidContact = errors.New("invalid contact")
+
+// ErrContactExists is returned when creating a contact with a name that's already taken
+var ErrContactExists = errors.New("contact already exists")
+
+// ErrContactConfigured is returned when changing or deleting a contact that comes from the
+// config file
+var ErrContactConfigured = errors.New("contact is configured and can't be changed")
+
+// validContactName matches the names contacts can have, so they can be used in URL paths
+var validContactName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Contact maps a logical name, such as "oncall-db", to its addresses on each channel. A
+// contact with members is a group: sending to it also sends to each member's addresses.
+type Contact struct {
+	Name        string                        `json:"name"`
+	Description string                        `json:"description,omitempty"`
+	Addresses   map[NotificationType][]string `json:"addresses,omitempty"`  // Addresses on each channel, e.g. email: [dba@example.com]
+	Members     []string                      `json:"members,omitempty"`    // Names of the contacts in the group
+	Configured  bool                          `json:"configured,omitempty"` // From the config file rather than the API
+	Tenant      string                        `json:"tenant,omitempty"`     // Tenant that created it through the API
+	CreatedAt   time.Time                     `json:"created_at,omitzero"`
+	UpdatedAt   time.Time                     `json:"updated_at,omitzero"`
+}
+
+// Validate checks the contact has a usable name and something to send to
+func (c *Contact) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidContact)
+	}
+	if !validContactName.MatchString(c.Name) {
+		return fmt.Errorf("%w: invalid name %q (letters, digits, '.', '_' and '-' only)", ErrInvalidContact, c.Name)
+	}
+	if len(c.Addresses) == 0 && len(c.Members) == 0 {
+		return fmt.Errorf("%w: %s has neither addresses nor members", ErrInvalidContact, c.Name)
+	}
+	for notifType, addresses := range c.Addresses {
+		if notifType == "" {
+			return fmt.Errorf("%w: %s has addresses without a type", ErrInvalidContact, c.Name)
+		}
+		if len(addresses) == 0 {
+			return fmt.Errorf("%w: %s has no %s addresses", ErrInvalidContact, c.Name, notifType)
+		}
+	}
+	for _, member := range c.Members {
+		if member == c.Name {
+			return fmt.Errorf("%w: %s can't be a member of itself", ErrInvalidContact, c.Name)
+		}
+	}
+	return nil
+}
+
+// ContactStore is implemented by notification stores that also keep the contacts created
+// through the API. Each tenant has its own contacts; "" holds those created without one.
+type ContactStore interface {
+	// SaveContact stores a contact, replacing any of its tenant's with the same name
+	SaveContact(ctx context.Context, contact *Contact) error
+
+	// GetContact retrieves a tenant's contact by name, wrapping ErrContactNotFound when it's
+	// unknown
+	GetContact(ctx context.Context, tenant, name string) (*Contact, error)
+
+	// ListContacts retrieves every contact of a tenant, sorted by name
+	ListContacts(ctx context.Context, tenant string) ([]*Contact, error)
+
+	// DeleteContact removes a tenant's contact, wrapping ErrContactNotFound when it's unknown
+	DeleteContact(ctx context.Context, tenant, name string) error
+}
+
+// ContactManager is implemented by services that manage contacts through the API
+type ContactManager interface {
+	// CreateContact validates and saves a new contact
+	CreateContact(ctx context.Context, contact Contact) (*Contact, error)
+
+	// UpdateContact validates and replaces an existing contact
+	UpdateContact(ctx context.Context, contact Contact) (*Contact, error)
+
+	// GetContact returns a contact by name
+	GetContact(ctx context.Context, name string) (*Contact, error)
+
+	// ListContacts returns every contact, sorted by name
+	ListContacts(ctx context.Context) ([]*Contact, error)
+
+	// DeleteContact removes a contact created through the API
+	DeleteContact(ctx context.Context, name string) error
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "fmt"
+
+// Fallback is a channel a notification moves on to when it fails permanently, e.g. ntfy
+// after Slack. Recipients or contacts are needed as each channel addresses people differently.
+type Fallback struct {
+	Type       NotificationType `json:"type"`
+	Account    string           `json:"account,omitempty"` // Empty uses the type's default account
+	Recipients []string         `json:"recipients,omitempty"`
+	Contacts   []string         `json:"contacts,omitempty"` // Contacts whose addresses on that channel are added to the recipients
+}
+
+// ValidateFallbacks checks each fallback of a chain names a channel and who to send to
+func ValidateFallbacks(fallbacks []Fallback) error {
+	for i, fallback := range fallbacks {
+		if fallback.Type == "" {
+			return fmt.Errorf("fallbacks[%d]: type is required", i)
+		}
+		if len(fallback.Recipients) == 0 && len(fallback.Contacts) == 0 {
+			return fmt.Errorf("fallbacks[%d]: at least one recipient or contact is required", i)
+		}
+	}
+	return nil
+}
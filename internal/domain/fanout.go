@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTargets is returned when a notification's fan-out targets can't be sent to
+var ErrInvalidTargets = errors.New("invalid targets")
+
+// Target is one channel a fanned-out notification is sent through, e.g. the ops Slack account
+// alongside email. Recipients and contacts default to the notification's own.
+type Target struct {
+	Type       NotificationType `json:"type"`
+	Account    string           `json:"account,omitempty"`    // Empty uses the type's default account
+	Recipients []string         `json:"recipients,omitempty"` // Empty uses the notification's recipients
+	Contacts   []string         `json:"contacts,omitempty"`   // Empty uses the notification's contacts
+}
+
+// TargetResult is the outcome of sending a fanned-out notification through one of its targets
+type TargetResult struct {
+	Type           NotificationType `json:"type"`
+	Account        string           `json:"account,omitempty"`
+	NotificationID string           `json:"notification_id,omitempty"` // The target's own notification
+	Success        bool             `json:"success"`
+	Message        string           `json:"message,omitempty"`
+	Error          string           `json:"error,omitempty"`
+}
+
+// ValidateTargets checks each target names a channel, has someone to send to of its own or
+// from the notification, and isn't listed twice
+func ValidateTargets(targets []Target, hasRecipients bool) error {
+	seen := make(map[string]bool, len(targets))
+	for i, target := range targets {
+		if target.Type == "" {
+			return fmt.Errorf("%w: targets[%d]: type is required", ErrInvalidTargets, i)
+		}
+		if !hasRecipients && len(target.Recipients) == 0 && len(target.Contacts) == 0 {
+			return fmt.Errorf("%w: targets[%d]: at least one recipient or contact is required", ErrInvalidTargets, i)
+		}
+
+		key := string(target.Type) + "/" + target.Account
+		if seen[key] {
+			return fmt.Errorf("%w: targets[%d]: %s account %q is listed twice", ErrInvalidTargets, i, target.Type, target.Account)
+		}
+		seen[key] = true
+	}
+	return nil
+}
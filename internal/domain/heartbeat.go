@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHeartbeatNotFound is returned when a heartbeat isn't configured
+var ErrHeartbeatNotFound = errors.New("heartbeat not found")
+
+// HeartbeatStatus is whether a heartbeat's producer is pinging on schedule
+type HeartbeatStatus string
+
+const (
+	HeartbeatNew  HeartbeatStatus = "new"  // Not pinged yet, still within its first period
+	HeartbeatUp   HeartbeatStatus = "up"   // Pinged within its period and grace
+	HeartbeatDown HeartbeatStatus = "down" // Missed; an alert has been sent
+)
+
+// Heartbeat is a monitored producer that's expected to ping on a schedule
+type Heartbeat struct {
+	Name      string          `json:"name"`
+	Status    HeartbeatStatus `json:"status"`
+	Period    string          `json:"period"`
+	Grace     string          `json:"grace"`
+	LastPing  *time.Time      `json:"last_ping,omitempty"`
+	Pings     int             `json:"pings"`
+	Deadline  time.Time       `json:"deadline"` // When the heartbeat is missed without another ping
+	DownSince *time.Time      `json:"down_since,omitempty"`
+}
+
+// HeartbeatMonitor is implemented by services that monitor heartbeats
+type HeartbeatMonitor interface {
+	// PingHeartbeat records a ping from a heartbeat's producer
+	PingHeartbeat(ctx context.Context, name string) (*Heartbeat, error)
+
+	// GetHeartbeat returns a heartbeat's status
+	GetHeartbeat(ctx context.Context, name string) (*Heartbeat, error)
+
+	// ListHeartbeats returns every heartbeat, by name
+	ListHeartbeats(ctx context.Context) ([]*Heartbeat, error)
+}
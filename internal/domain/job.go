@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// JobStatus represents the state of a send job
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"   // Notifications are being enqueued or are still in flight
+	JobPaused    JobStatus = "paused"    // Enqueueing is suspended until the job is resumed
+	JobCompleted JobStatus = "completed" // Every notification has been enqueued and has finished
+	JobCancelled JobStatus = "cancelled" // Enqueueing was stopped before every recipient was reached
+)
+
+// SendJobOptions controls how a send job fans a recipient list out into notifications
+type SendJobOptions struct {
+	// RecipientsPerNotification is how many recipients share each notification (default 1)
+	RecipientsPerNotification int `json:"recipients_per_notification,omitempty"`
+
+	// MaxInFlight caps the job's notifications that are queued but not yet sent or failed,
+	// so a large job doesn't flood the queue ahead of other traffic (default 1000)
+	MaxInFlight int `json:"max_in_flight,omitempty"`
+}
+
+// SendJob reports the progress of a long-running send to a large recipient list
+type SendJob struct {
+	ID         string           `json:"id"`
+	Status     JobStatus        `json:"status"`
+	Type       NotificationType `json:"type"`
+	Account    string           `json:"account,omitempty"`
+	Origin     Origin           `json:"origin"`
+	Tenant     string           `json:"tenant,omitempty"`
+	Options    SendJobOptions   `json:"options"`
+	Recipients int              `json:"recipients"`
+
+	// Counts of notifications: Total is how many the job will create in all
+	Total    int `json:"total"`
+	Enqueued int `json:"enqueued"`
+	Sent     int `json:"sent"`
+	Failed   int `json:"failed"`
+	Held     int `json:"held"`     // Held for approval by the content policy or spam check
+	Rejected int `json:"rejected"` // Refused at submission, e.g. by a budget or blocking policy
+
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	EstimatedAt *time.Time `json:"estimated_completion,omitempty"` // Projected from the delivery rate so far
+}
+
+// SendJobRunner is implemented by services that can run send jobs in the background
+type SendJobRunner interface {
+	// SubmitSendJob starts sending template to recipients in the background. The template's
+	// recipients are ignored; each notification gets its share of recipients.
+	SubmitSendJob(ctx context.Context, template *Notification, recipients []string, opts SendJobOptions) (*SendJob, error)
+
+	// GetSendJob returns a job's progress
+	GetSendJob(ctx context.Context, id string) (*SendJob, error)
+
+	// ListSendJobs returns every job, newest first
+	ListSendJobs(ctx context.Context) ([]*SendJob, error)
+
+	// PauseSendJob stops enqueueing a job's notifications until it's resumed
+	PauseSendJob(ctx context.Context, id string) error
+
+	// ResumeSendJob continues a paused job
+	ResumeSendJob(ctx context.Context, id string) error
+
+	// CancelSendJob stops enqueueing a job's notifications. Notifications already queued
+	// are still delivered.
+	CancelSendJob(ctx context.Context, id string) error
+}
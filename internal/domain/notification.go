@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -14,14 +15,42 @@ const (
 	PriorityCritical
 )
 
+// ParsePriority converts a priority name (low, normal, high, critical) to a Priority
+func ParsePriority(name string) (Priority, error) {
+	switch name {
+	case "low":
+		return PriorityLow, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	case "critical":
+		return PriorityCritical, nil
+	default:
+		return PriorityNormal, fmt.Errorf("invalid priority: %s (must be low, normal, high, or critical)", name)
+	}
+}
+
 // NotificationType defines the channel through which to send the notification
 type NotificationType string
 
 const (
-	TypeEmail  NotificationType = "email"
-	TypeSlack  NotificationType = "slack"
-	TypeNtfy   NotificationType = "ntfy"
-	TypeStdout NotificationType = "stdout"
+	TypeEmail     NotificationType = "email"
+	TypeSlack     NotificationType = "slack"
+	TypeNtfy      NotificationType = "ntfy"
+	TypeStdout    NotificationType = "stdout"
+	TypeDiscord   NotificationType = "discord"
+	TypePagerDuty NotificationType = "pagerduty"
+	TypeFCM       NotificationType = "fcm"
+	TypeWebPush   NotificationType = "webpush"
+	TypeXMPP      NotificationType = "xmpp"
+	TypeWhatsApp  NotificationType = "whatsapp"
+	TypePostmark  NotificationType = "postmark"
+	TypeAMQP      NotificationType = "amqp"
+	TypeFile      NotificationType = "file"
+	TypeBark      NotificationType = "bark"
+	TypeDingTalk  NotificationType = "dingtalk"
+	TypeSMS       NotificationType = "sms"
 )
 
 // ContentType defines the format of the notification body
@@ -42,8 +71,39 @@ const (
 	StatusSent       NotificationStatus = "sent"
 	StatusFailed     NotificationStatus = "failed"
 	StatusRetrying   NotificationStatus = "retrying"
+	StatusHeld       NotificationStatus = "held"      // Awaiting approval after matching a content policy rule
+	StatusPaused     NotificationStatus = "paused"    // Waiting for an operator to resume dispatch to its account
+	StatusScheduled  NotificationStatus = "scheduled" // Held in the queue until its ScheduledFor time
+	StatusDigested   NotificationStatus = "digested"  // Combined into a digest instead of being sent on its own
 )
 
+// UnknownOrigin is the stats key for notifications sent without an origin system
+const UnknownOrigin = "unknown"
+
+// Origin records who generated a notification
+type Origin struct {
+	// System is the service or job that sent the notification (e.g., "billing-service").
+	// Defaults to the authenticated API key's client ID when not supplied.
+	System string `json:"system,omitempty"`
+
+	// User is the end user whose action triggered the notification.
+	// Empty for system-triggered notifications (cron jobs, alerts, etc.).
+	User string `json:"user,omitempty"`
+}
+
+// UserTriggered reports whether the notification was triggered by a user action
+func (o Origin) UserTriggered() bool {
+	return o.User != ""
+}
+
+// StatsKey returns the key used to group the origin in statistics
+func (o Origin) StatsKey() string {
+	if o.System == "" {
+		return UnknownOrigin
+	}
+	return o.System
+}
+
 // Notification represents a notification message with metadata
 type Notification struct {
 	// ID is a unique identifier for the notification
@@ -77,25 +137,102 @@ type Notification struct {
 	// Deprecated: prefer setting HTMLBody alongside a plain-text Body.
 	ContentType ContentType `json:"content_type,omitempty"`
 
+	// Template names a template to render Subject, Body and HTMLBody from (optional). It's
+	// rendered with TemplateVars when the notification is sent.
+	Template string `json:"template,omitempty"`
+
+	// TemplateVars are the variables Template is rendered with
+	TemplateVars map[string]interface{} `json:"template_vars,omitempty"`
+
+	// Locale is the recipient's language as a locale tag, e.g. "fr" or "pt-BR" (optional). It
+	// selects the template's translation.
+	Locale string `json:"locale,omitempty"`
+
 	// Recipients contains the target addresses (email, slack channel, ntfy topic, etc.)
 	// For email: these are the "To" recipients
 	Recipients []string `json:"recipients"`
 
+	// Contacts are contacts and groups whose addresses on the notification's channel are
+	// added to its recipients when it's sent (optional)
+	Contacts []string `json:"contacts,omitempty"`
+
 	// CC contains carbon copy recipients (email only, optional)
 	CC []string `json:"cc,omitempty"`
 
 	// BCC contains blind carbon copy recipients (email only, optional)
 	BCC []string `json:"bcc,omitempty"`
 
+	// ReplyTo is the address replies are sent to (email only, optional)
+	ReplyTo string `json:"reply_to,omitempty"`
+
 	// Metadata contains additional provider-specific data
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 
+	// Tags are labels such as team=payments or env=prod that routing rules match and
+	// notifications can be listed by (optional). Unlike metadata, they aren't given to providers.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Options contains typed provider overrides for the notification's channel (optional)
+	Options *SendOptions `json:"options,omitempty"`
+
+	// Origin records the system and user that generated the notification
+	Origin Origin `json:"origin"`
+
+	// Tenant is the team whose credentials sent the notification, when tenancy is enabled.
+	// It's set from the caller's credentials, and only that tenant's clients can see it.
+	Tenant string `json:"tenant,omitempty"`
+
+	// DedupKey identifies what the notification is about, e.g. "db-primary-down". Another
+	// notification with the same key within the dedup window is suppressed (optional).
+	DedupKey string `json:"dedup_key,omitempty"`
+
 	// CreatedAt is when the notification was created
 	CreatedAt time.Time `json:"created_at"`
 
 	// ScheduledFor allows delayed sending (optional)
 	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
 
+	// Recurrence is a cron expression or RRULE. A notification with one isn't sent itself;
+	// it creates a recurring notification that sends a copy at every occurrence.
+	Recurrence string `json:"recurrence,omitempty"`
+
+	// RecurrenceID is the recurring notification that created this one, if any
+	RecurrenceID string `json:"recurrence_id,omitempty"`
+
+	// Fallbacks are channels to try in order if the notification fails permanently (optional).
+	// The first is sent as a new notification with the same content, carrying the rest.
+	Fallbacks []Fallback `json:"fallbacks,omitempty"`
+
+	// FallbackOf is the failed notification this one was sent in place of, if any
+	FallbackOf string `json:"fallback_of,omitempty"`
+
+	// FallbackID is the notification sent in place of this one after it failed, if any
+	FallbackID string `json:"fallback_id,omitempty"`
+
+	// Targets fan the notification out to several channels in one send (optional). Each is sent
+	// as its own notification, sharing a FanoutID; Type and Account are left empty.
+	Targets []Target `json:"targets,omitempty"`
+
+	// FanoutID is the logical notification this one was sent for, when it was fanned out to
+	// several targets
+	FanoutID string `json:"fanout_id,omitempty"`
+
+	// DigestID is the digest this notification was combined into, once the digest is sent
+	DigestID string `json:"digest_id,omitempty"`
+
+	// DigestOf lists the notifications a digest combines
+	DigestOf []string `json:"digest_of,omitempty"`
+
+	// NextAttemptAt is when a notification waiting to be retried will next be sent
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// Deadline is when the notification must be delivered by (optional)
+	Deadline *time.Time `json:"deadline,omitempty"`
+
+	// DeadlineMissed is set when the notification was delivered after its deadline, or failed
+	// without being delivered
+	DeadlineMissed bool `json:"deadline_missed,omitempty"`
+
 	// SentAt is when the notification was successfully sent
 	SentAt *time.Time `json:"sent_at,omitempty"`
 
@@ -107,6 +244,49 @@ type Notification struct {
 
 	// LastError stores the most recent error message if failed
 	LastError string `json:"last_error,omitempty"`
+
+	// AcknowledgedAt is when the notification was acknowledged, if it has been
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+
+	// AcknowledgedBy is who or what acknowledged the notification
+	AcknowledgedBy string `json:"acknowledged_by,omitempty"`
+
+	// Links maps recipients to a URL where the delivered message can be viewed, when the
+	// provider returned one
+	Links map[string]string `json:"links,omitempty"`
+
+	// WebCopyURL is the signed URL of the hosted web copy of an HTML email, if one was linked
+	WebCopyURL string `json:"web_copy_url,omitempty"`
+
+	// DryRun processes the notification as normal but validates it instead of delivering it,
+	// e.g. for traffic mirrored to a shadow deployment
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// JobID is the send job that created the notification, if any
+	JobID string `json:"job_id,omitempty"`
+
+	// PolicyViolations lists the content policy rules the notification matched
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+}
+
+// HasTags reports whether the notification carries every one of the tags
+func (n *Notification) HasTags(tags map[string]string) bool {
+	for key, value := range tags {
+		if tag, ok := n.Tags[key]; !ok || tag != value {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyViolation records one content policy match. The matched text is masked so
+// the violation itself doesn't leak the secret it found.
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Detector string `json:"detector"`
+	Field    string `json:"field"` // subject, body, html_body or metadata.<key>
+	Match    string `json:"match"` // Masked excerpt of the matched text
+	Action   string `json:"action"`
 }
 
 // NotificationResult represents the outcome of sending a notification
@@ -123,11 +303,23 @@ type NotificationResult struct {
 	// Error contains error details if the notification failed
 	Error string `json:"error,omitempty"`
 
+	// Suppressed is set when the notification duplicated the dedup key of one sent within the
+	// dedup window, and wasn't sent. NotificationID is then the original's.
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// Targets are the per-target results of a notification fanned out to several channels.
+	// NotificationID is then its FanoutID.
+	Targets []TargetResult `json:"targets,omitempty"`
+
 	// SentAt is when the notification was sent
 	SentAt time.Time `json:"sent_at"`
 
 	// ProviderResponse contains raw response data from the notification provider
 	ProviderResponse map[string]interface{} `json:"provider_response,omitempty"`
+
+	// Links maps recipients to a URL where the delivered message can be viewed in the
+	// provider's UI (e.g., a Slack permalink), for providers that have one
+	Links map[string]string `json:"links,omitempty"`
 }
 
 // NotificationFilter is used for querying notifications
@@ -136,8 +328,14 @@ type NotificationFilter struct {
 	Types         []NotificationType   `json:"types,omitempty"`
 	Statuses      []NotificationStatus `json:"statuses,omitempty"`
 	Recipients    []string             `json:"recipients,omitempty"`
+	OriginSystems []string             `json:"origin_systems,omitempty"`
+	OriginUsers   []string             `json:"origin_users,omitempty"`
+	Tags          map[string]string    `json:"tags,omitempty"`      // Only notifications carrying every one of these tags
+	FanoutID      string               `json:"fanout_id,omitempty"` // Only the targets of one fanned-out notification
+	Tenant        string               `json:"tenant,omitempty"`    // Only one tenant's notifications
 	CreatedAfter  *time.Time           `json:"created_after,omitempty"`
 	CreatedBefore *time.Time           `json:"created_before,omitempty"`
 	Limit         int                  `json:"limit,omitempty"`
 	Offset        int                  `json:"offset,omitempty"`
+	Cursor        string               `json:"cursor,omitempty"` // Continues a paged listing after the page that returned it
 }
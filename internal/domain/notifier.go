@@ -2,8 +2,62 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 )
 
+// ErrBudgetExceeded is returned when a notification is blocked because its origin has
+// used up its daily budget
+var ErrBudgetExceeded = errors.New("origin budget exceeded")
+
+// ErrContentBlocked is returned when a notification is rejected by a content policy rule
+var ErrContentBlocked = errors.New("notification content blocked by policy")
+
+// ErrUnknownKeys is returned in strict mode when a notification has metadata or options keys
+// its notifier doesn't recognise
+var ErrUnknownKeys = errors.New("unrecognized metadata or options keys")
+
+// ErrLimitExceeded is returned when a notification exceeds a configured size or complexity limit
+var ErrLimitExceeded = errors.New("notification exceeds a configured limit")
+
+// Limit codes identify which limit a notification exceeded
+const (
+	LimitRecipients      = "too_many_recipients"
+	LimitBodyBytes       = "body_too_large"
+	LimitMetadataEntries = "too_many_metadata_entries"
+)
+
+// LimitError reports the limit a notification exceeded. It wraps ErrLimitExceeded.
+type LimitError struct {
+	Code   string // One of the Limit codes
+	Limit  int
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s (%d, limit %d)", ErrLimitExceeded, e.Code, e.Actual, e.Limit)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// ErrNotHeld is returned when approving or rejecting a notification that isn't awaiting approval
+var ErrNotHeld = errors.New("notification is not held for approval")
+
+// ErrNotReschedulable is returned when rescheduling a notification that isn't waiting to be sent
+var ErrNotReschedulable = errors.New("notification is not waiting to be sent")
+
+// ErrRescheduleEarlier is returned when rescheduling a notification for no later than it's due
+var ErrRescheduleEarlier = errors.New("notification can only be rescheduled for later than it's due")
+
+// ErrJobNotFound is returned when a send job doesn't exist
+var ErrJobNotFound = errors.New("send job not found")
+
+// ErrJobFinished is returned when pausing, resuming or cancelling a send job that has finished
+var ErrJobFinished = errors.New("send job has finished")
+
 // Notifier is the core interface that all notification implementations must satisfy
 type Notifier interface {
 	// Send sends a notification and returns the result
@@ -46,8 +100,8 @@ type NotificationService interface {
 	// GetNotification retrieves a notification by ID
 	GetNotification(ctx context.Context, id string) (*Notification, error)
 
-	// ListNotifications retrieves notifications matching the filter
-	ListNotifications(ctx context.Context, filter *NotificationFilter) ([]*Notification, error)
+	// ListNotifications retrieves a page of notifications matching the filter, newest first
+	ListNotifications(ctx context.Context, filter *NotificationFilter) (*NotificationPage, error)
 
 	// CancelNotification cancels a pending notification
 	CancelNotification(ctx context.Context, id string) error
@@ -62,15 +116,200 @@ type NotificationService interface {
 	GetNotifiers(ctx context.Context) (*NotifiersResponse, error)
 }
 
+// StatusEventSource is implemented by services that publish status transitions as they happen
+type StatusEventSource interface {
+	// SubscribeStatus returns a channel of status events and a function that ends the subscription.
+	// Events are dropped rather than blocking delivery when the subscriber falls behind.
+	SubscribeStatus(buffer int) (<-chan StatusEvent, func())
+}
+
+// MessageRenderer is implemented by notifiers that can render the exact message they would
+// send, so it can be inspected (e.g., spam scored) before delivery
+type MessageRenderer interface {
+	// RenderMessage returns the wire-format message for a notification
+	RenderMessage(notification *Notification) ([]byte, error)
+}
+
+// MetadataReader is implemented by notifiers that read a fixed set of metadata keys, so strict
+// mode can reject the others. Notifiers that pass metadata through to the recipient (e.g., as
+// push data) don't implement it, and accept any key.
+type MetadataReader interface {
+	// MetadataKeys returns the metadata keys the notifier reads
+	MetadataKeys() []string
+}
+
+// TimeFormatter is implemented by notifiers that render timestamps in their account's display
+// timezone and layout, so templates can show times the way its recipients expect
+type TimeFormatter interface {
+	// FormatTime renders a timestamp for the account's recipients
+	FormatTime(t time.Time) string
+}
+
+// NativeScheduler is implemented by notifiers that can hand a notification scheduled for later
+// to their provider to deliver at its ScheduledFor time, so it's sent straight away rather than
+// held in the queue until it's due
+type NativeScheduler interface {
+	// SchedulesNatively reports whether the provider can deliver the notification at its
+	// ScheduledFor time
+	SchedulesNatively(notification *Notification) bool
+}
+
+// NotificationRescheduler is implemented by services that can push back when a notification
+// waiting to be sent is delivered
+type NotificationRescheduler interface {
+	// RescheduleNotification delays a pending, queued or scheduled notification until at, which
+	// must be later than it's currently due. It returns the rescheduled notification.
+	RescheduleNotification(ctx context.Context, id string, at time.Time) (*Notification, error)
+}
+
+// NotificationApprover is implemented by services that hold notifications for approval
+type NotificationApprover interface {
+	// ApproveNotification queues a held notification for delivery
+	ApproveNotification(ctx context.Context, id, approvedBy string) error
+
+	// RejectNotification fails a held notification without sending it
+	RejectNotification(ctx context.Context, id, rejectedBy, reason string) error
+}
+
+// PolicySimulator is implemented by services that can preview a policy change against
+// the notifications they've already handled
+type PolicySimulator interface {
+	// SimulatePolicies replays notifications created within window through the active
+	// policies and through proposal, a YAML document with content_policy, budgets and/or
+	// hedging sections, and reports how their outcomes would differ
+	SimulatePolicies(ctx context.Context, proposal []byte, window time.Duration) (*PolicySimulation, error)
+}
+
+// PolicySimulation reports how a proposed policy change would have treated past notifications
+type PolicySimulation struct {
+	From        time.Time                `json:"from"`
+	To          time.Time                `json:"to"`
+	Evaluated   int                      `json:"evaluated"`
+	Changed     int                      `json:"changed"`
+	Current     map[string]int           `json:"current"`     // Notifications per action under the active policies
+	Proposed    map[string]int           `json:"proposed"`    // Notifications per action under the proposal
+	Transitions map[string]int           `json:"transitions"` // Changed actions, keyed "current -> proposed"
+	Changes     []PolicySimulationChange `json:"changes,omitempty"`
+	Truncated   bool                     `json:"truncated,omitempty"` // More notifications changed than are listed
+}
+
+// PolicySimulationChange describes one notification whose outcome would change
+type PolicySimulationChange struct {
+	NotificationID string           `json:"notification_id"`
+	Type           NotificationType `json:"type"`
+	Account        string           `json:"account,omitempty"`
+	Origin         Origin           `json:"origin"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Current        PolicyOutcome    `json:"current"`
+	Proposed       PolicyOutcome    `json:"proposed"`
+}
+
+// PolicyOutcome is what the policies decide for one notification
+type PolicyOutcome struct {
+	Action  string   `json:"action"`          // deliver, warn, hold or block
+	Rules   []string `json:"rules,omitempty"` // Content rules and budgets that matched
+	HedgeTo string   `json:"hedge_to,omitempty"`
+}
+
+// StatusEvent describes a notification's transition to a new status
+type StatusEvent struct {
+	NotificationID string             `json:"notification_id"`
+	Type           NotificationType   `json:"type"`
+	Account        string             `json:"account,omitempty"`
+	Origin         Origin             `json:"origin"`
+	Tenant         string             `json:"tenant,omitempty"`
+	Status         NotificationStatus `json:"status"`
+	RetryCount     int                `json:"retry_count"`
+	Error          string             `json:"error,omitempty"`
+	At             time.Time          `json:"at"`
+}
+
 // NotificationStats contains statistics about notification processing
 type NotificationStats struct {
-	TotalSent      int64            `json:"total_sent"`
-	TotalFailed    int64            `json:"total_failed"`
-	TotalPending   int64            `json:"total_pending"`
-	TotalQueued    int64            `json:"total_queued"`
-	ByType         map[string]int64 `json:"by_type"`
-	ByStatus       map[string]int64 `json:"by_status"`
-	AverageLatency float64          `json:"average_latency_ms"`
+	TotalSent       int64                   `json:"total_sent"`
+	TotalFailed     int64                   `json:"total_failed"`
+	TotalPending    int64                   `json:"total_pending"`
+	TotalQueued     int64                   `json:"total_queued"`
+	TotalSuppressed int64                   `json:"total_suppressed"` // Duplicates suppressed by dedup key since the service started
+	ByType          map[string]int64        `json:"by_type"`
+	ByStatus        map[string]int64        `json:"by_status"`
+	ByOrigin        map[string]*OriginStats `json:"by_origin"`
+	AverageLatency  float64                 `json:"average_latency_ms"`
+	Delivery        DeliveryStats           `json:"delivery"`
+	SLOs            []SLOStatus             `json:"slos,omitempty"`
+	Budgets         []BudgetStatus          `json:"budgets,omitempty"`
+	Canaries        []CanaryStatus          `json:"canaries,omitempty"`
+	Staleness       *ReadStaleness          `json:"staleness,omitempty"` // Source of the counts, for stores that can read from a replica
+}
+
+// DeliveryStats reports how long notifications take to be delivered, measured from when they
+// were due, and how many miss their deadline. Latency percentiles cover recent deliveries.
+type DeliveryStats struct {
+	Delivered      int64   `json:"delivered"`
+	Failed         int64   `json:"failed"`
+	LatencyP50Ms   float64 `json:"latency_p50_ms"`
+	LatencyP95Ms   float64 `json:"latency_p95_ms"`
+	LatencySumMs   float64 `json:"latency_sum_ms"` // Of every delivery
+	DeadlineMet    int64   `json:"deadline_met"`
+	DeadlineMissed int64   `json:"deadline_missed"`
+}
+
+// DeliveryMetricsReporter is implemented by services that track delivery latency and deadlines
+type DeliveryMetricsReporter interface {
+	// DeliveryMetrics returns delivery statistics since the service started
+	DeliveryMetrics() DeliveryStats
+}
+
+// BudgetStatus reports today's usage of an origin's daily budget
+type BudgetStatus struct {
+	Origin   string `json:"origin"`
+	Type     string `json:"type,omitempty"`
+	Limit    int64  `json:"limit"`
+	Used     int64  `json:"used"`
+	Action   string `json:"action"`
+	Exceeded bool   `json:"exceeded"`
+}
+
+// OriginStats breaks down the notifications generated by one origin system
+type OriginStats struct {
+	Total         int64            `json:"total"`
+	UserTriggered int64            `json:"user_triggered"`
+	ByType        map[string]int64 `json:"by_type"`
+}
+
+// SLOStatus reports compliance with a delivery SLO over its rolling window
+type SLOStatus struct {
+	Name            string  `json:"name"`
+	Target          float64 `json:"target"`
+	ThresholdMs     int64   `json:"threshold_ms"`
+	Compliance      float64 `json:"compliance"`
+	Total           int64   `json:"total"`
+	WithinThreshold int64   `json:"within_threshold"`
+	Breached        bool    `json:"breached"`
+}
+
+// SLOMetricsReporter is implemented by services that track delivery SLOs
+type SLOMetricsReporter interface {
+	// SLOMetrics returns the current compliance of every SLO, or nothing when tracking is disabled
+	SLOMetrics() []SLOStatus
+}
+
+// CanaryStatus reports the end-to-end delivery of canary notifications through one channel
+type CanaryStatus struct {
+	Target              string     `json:"target"` // type/account
+	Healthy             bool       `json:"healthy"`
+	LastSent            *time.Time `json:"last_sent,omitempty"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastFailure         *time.Time `json:"last_failure,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastLatencyMs       int64      `json:"last_latency_ms,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// HealthReporter is implemented by services that can detect that they are unhealthy
+type HealthReporter interface {
+	// HealthProblems returns why the service is unhealthy, or nothing if it's healthy
+	HealthProblems() []string
 }
 
 // NotifierInfo contains information about a configured notifier type
@@ -78,6 +317,7 @@ type NotifierInfo struct {
 	Type           NotificationType `json:"type"`
 	Accounts       []string         `json:"accounts"`
 	DefaultAccount string           `json:"default_account"`
+	PausedAccounts []string         `json:"paused_accounts,omitempty"` // Accounts whose dispatch is paused
 }
 
 // NotifiersResponse contains the list of available notifiers
@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SendOptions are typed provider overrides for one notification. Only the block for the
+// notification's channel may be set; they replace the equivalent metadata conventions, which
+// are still read when an option isn't given.
+type SendOptions struct {
+	Email *EmailOptions `json:"email,omitempty"` // Email and Postmark
+	Slack *SlackOptions `json:"slack,omitempty"`
+	Ntfy  *NtfyOptions  `json:"ntfy,omitempty"`
+
+	unknown []string // Keys in the decoded JSON that aren't options, for strict mode
+}
+
+// EmailOptions override how an email is sent
+type EmailOptions struct {
+	ReplyTo string            `json:"reply_to,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"` // Extra headers, e.g. List-Unsubscribe
+}
+
+// SlackOptions override how a Slack message is posted
+type SlackOptions struct {
+	ThreadTS string `json:"thread_ts,omitempty"` // Reply in the thread of this message
+	Icon     string `json:"icon,omitempty"`      // An emoji such as ":rotating_light:" or an image URL
+}
+
+// NtfyOptions override how an ntfy message is published
+type NtfyOptions struct {
+	Tags  []string `json:"tags,omitempty"`
+	Click string   `json:"click,omitempty"` // URL opened when the notification is tapped
+	Delay string   `json:"delay,omitempty"` // A duration such as "30m" or a Unix timestamp
+}
+
+// UnmarshalJSON decodes options, recording any keys that aren't options so strict mode can
+// reject them
+func (o *SendOptions) UnmarshalJSON(data []byte) error {
+	type plain SendOptions
+	if err := json.Unmarshal(data, (*plain)(o)); err != nil {
+		return err
+	}
+
+	var blocks map[string]json.RawMessage
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return err
+	}
+	o.unknown = nil
+	for name, raw := range blocks {
+		var fields map[string]bool
+		switch name {
+		case "email":
+			fields = jsonFields(EmailOptions{})
+		case "slack":
+			fields = jsonFields(SlackOptions{})
+		case "ntfy":
+			fields = jsonFields(NtfyOptions{})
+		default:
+			o.unknown = append(o.unknown, "options."+name)
+			continue
+		}
+
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &keys); err != nil {
+			continue // null; anything else failed to decode above
+		}
+		for key := range keys {
+			if !fields[key] {
+				o.unknown = append(o.unknown, "options."+name+"."+key)
+			}
+		}
+	}
+	sort.Strings(o.unknown)
+	return nil
+}
+
+// UnknownKeys returns the keys in the decoded JSON that aren't options, as dotted paths
+func (o *SendOptions) UnknownKeys() []string {
+	if o == nil {
+		return nil
+	}
+	return o.unknown
+}
+
+// jsonFields returns the JSON names of a struct's fields
+func jsonFields(v interface{}) map[string]bool {
+	t := reflect.TypeOf(v)
+	fields := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
+
+// reservedEmailHeaders are set by the notifier and can't be overridden with options
+var reservedEmailHeaders = map[string]bool{
+	"From":                      true,
+	"To":                        true,
+	"Cc":                        true,
+	"Bcc":                       true,
+	"Reply-To":                  true,
+	"Subject":                   true,
+	"Date":                      true,
+	"Message-Id":                true,
+	"Mime-Version":              true,
+	"Content-Type":              true,
+	"Content-Transfer-Encoding": true,
+}
+
+var (
+	// headerNamePattern matches a header field name (printable ASCII other than colon)
+	headerNamePattern = regexp.MustCompile(`^[!-9;-~]+$`)
+	// slackTSPattern matches a Slack message timestamp
+	slackTSPattern = regexp.MustCompile(`^\d+\.\d+$`)
+)
+
+// ForType returns the options with only the block for a notification type, or nil if it has
+// none. A notification fanned out to several channels can carry a block for each.
+func (o *SendOptions) ForType(notificationType NotificationType) *SendOptions {
+	if o == nil {
+		return nil
+	}
+
+	var options SendOptions
+	switch notificationType {
+	case TypeEmail, TypePostmark:
+		options.Email = o.Email
+	case TypeSlack:
+		options.Slack = o.Slack
+	case TypeNtfy:
+		options.Ntfy = o.Ntfy
+	}
+	if options.Email == nil && options.Slack == nil && options.Ntfy == nil {
+		return nil
+	}
+	return &options
+}
+
+// Validate checks that only the block for the notification type is set and that its values
+// are usable
+func (o *SendOptions) Validate(notificationType NotificationType) error {
+	if o == nil {
+		return nil
+	}
+
+	if o.Email != nil && notificationType != TypeEmail && notificationType != TypePostmark {
+		return fmt.Errorf("options.email do not apply to %s notifications", notificationType)
+	}
+	if o.Slack != nil && notificationType != TypeSlack {
+		return fmt.Errorf("options.slack do not apply to %s notifications", notificationType)
+	}
+	if o.Ntfy != nil && notificationType != TypeNtfy {
+		return fmt.Errorf("options.ntfy do not apply to %s notifications", notificationType)
+	}
+
+	switch {
+	case o.Email != nil:
+		return o.Email.validate()
+	case o.Slack != nil:
+		return o.Slack.validate()
+	case o.Ntfy != nil:
+		return o.Ntfy.validate()
+	}
+	return nil
+}
+
+// validate checks the reply-to address and that headers are well-formed and not reserved
+func (e *EmailOptions) validate() error {
+	if e.ReplyTo != "" {
+		if _, err := mail.ParseAddress(e.ReplyTo); err != nil {
+			return fmt.Errorf("invalid options.email.reply_to: %w", err)
+		}
+	}
+	for name, value := range e.Headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid options.email.headers name: %q", name)
+		}
+		if reservedEmailHeaders[textproto.CanonicalMIMEHeaderKey(name)] {
+			return fmt.Errorf("options.email.headers can't set %s", name)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("options.email.headers %s contains a line break", name)
+		}
+	}
+	return nil
+}
+
+// validate checks the thread timestamp and that the icon is an emoji or a URL
+func (s *SlackOptions) validate() error {
+	if s.ThreadTS != "" && !slackTSPattern.MatchString(s.ThreadTS) {
+		return fmt.Errorf("invalid options.slack.thread_ts: %q", s.ThreadTS)
+	}
+	if s.Icon != "" && !isSlackEmoji(s.Icon) && !isHTTPURL(s.Icon) {
+		return fmt.Errorf("options.slack.icon must be an emoji like :bell: or an http(s) URL")
+	}
+	return nil
+}
+
+// validate checks the tags, click URL and delay
+func (n *NtfyOptions) validate() error {
+	for _, tag := range n.Tags {
+		if tag == "" || strings.Contains(tag, ",") {
+			return fmt.Errorf("invalid options.ntfy.tags entry: %q", tag)
+		}
+	}
+	if n.Click != "" {
+		if u, err := url.Parse(n.Click); err != nil || u.Scheme == "" {
+			return fmt.Errorf("options.ntfy.click must be an absolute URL")
+		}
+	}
+	if n.Delay != "" {
+		if _, err := time.ParseDuration(n.Delay); err != nil {
+			if _, err := strconv.ParseInt(n.Delay, 10, 64); err != nil {
+				return fmt.Errorf("options.ntfy.delay must be a duration like 30m or a Unix timestamp")
+			}
+		}
+	}
+	return nil
+}
+
+// isSlackEmoji reports whether an icon is an emoji code such as ":bell:"
+func isSlackEmoji(icon string) bool {
+	return len(icon) > 2 && strings.HasPrefix(icon, ":") && strings.HasSuffix(icon, ":")
+}
+
+// isHTTPURL reports whether a string is an absolute http or https URL
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// IconIsEmoji reports whether the icon is an emoji code rather than an image URL
+func (s *SlackOptions) IconIsEmoji() bool {
+	return isSlackEmoji(s.Icon)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotPaused is returned when resuming dispatch that isn't paused
+var ErrNotPaused = errors.New("dispatch is not paused")
+
+// DispatchPause is an operator kill-switch that stops notifications being sent. It covers every
+// notifier, every account of one type, or one account. Paused notifications stay queued.
+type DispatchPause struct {
+	Type     NotificationType `json:"type,omitempty"`    // Empty pauses every notifier
+	Account  string           `json:"account,omitempty"` // Empty pauses every account of Type
+	Reason   string           `json:"reason,omitempty"`
+	PausedBy string           `json:"paused_by,omitempty"`
+	PausedAt time.Time        `json:"paused_at"`
+}
+
+// DispatchController is implemented by services whose dispatch can be paused by an operator
+type DispatchController interface {
+	// PauseDispatch stops sending notifications covered by the pause until it's resumed.
+	// Pausing again replaces the reason of an existing pause.
+	PauseDispatch(ctx context.Context, pause DispatchPause) (*DispatchPause, error)
+
+	// ResumeDispatch lifts the pause for a type and account, as they were given when pausing
+	ResumeDispatch(ctx context.Context, notifType NotificationType, account string) error
+
+	// ListDispatchPauses returns the active pauses, broadest first
+	ListDispatchPauses(ctx context.Context) ([]*DispatchPause, error)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrPreferencesNotFound is returned when a contact hasn't registered any preferences
+var ErrPreferencesNotFound = errors.New("preferences not found")
+
+// ErrInvalidPreferences is returned when preferences are malformed or name an unsupported channel
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// Preferences are how a contact wants to be reached. They're consulted when a notification's
+// contacts are resolved to recipients; critical notifications ignore them.
+type Preferences struct {
+	Contact         string             `json:"contact"`
+	Tenant          string             `json:"tenant,omitempty"`           // Tenant whose contact they're for
+	Channels        []NotificationType `json:"channels,omitempty"`         // Channels the contact can be reached on; empty allows every channel
+	MutedCategories []string           `json:"muted_categories,omitempty"` // Categories (metadata "category") the contact doesn't want
+	QuietHours      []QuietHours       `json:"quiet_hours,omitempty"`      // When notifications to the contact wait
+	UpdatedAt       time.Time          `json:"updated_at,omitzero"`
+}
+
+// QuietHours is a daily period during which notifications wait until it ends
+type QuietHours struct {
+	Start    string   `json:"start"`              // Local time the period starts, e.g. "22:00"
+	End      string   `json:"end"`                // Local time it ends; before start for a period spanning midnight
+	Timezone string   `json:"timezone,omitempty"` // IANA time zone the times are in (default UTC)
+	Days     []string `json:"days,omitempty"`     // Days the period starts on, e.g. ["sat", "sun"]; empty is every day
+}
+
+// PreferenceStore is implemented by notification stores that also keep contacts' preferences.
+// Each tenant keeps its own preferences for its contacts; "" holds those set without one.
+type PreferenceStore interface {
+	// SavePreferences stores a contact's preferences, replacing any its tenant had set
+	SavePreferences(ctx context.Context, prefs *Preferences) error
+
+	// GetPreferences retrieves the preferences a tenant set for a contact, wrapping
+	// ErrPreferencesNotFound when it has none
+	GetPreferences(ctx context.Context, tenant, contact string) (*Preferences, error)
+
+	// DeletePreferences removes the preferences a tenant set for a contact, wrapping
+	// ErrPreferencesNotFound when it has none
+	DeletePreferences(ctx context.Context, tenant, contact string) error
+}
+
+// PreferenceManager is implemented by services that let contacts register preferences
+type PreferenceManager interface {
+	// SetPreferences validates and replaces a contact's preferences
+	SetPreferences(ctx context.Context, prefs Preferences) (*Preferences, error)
+
+	// GetPreferences returns a contact's preferences
+	GetPreferences(ctx context.Context, contact string) (*Preferences, error)
+
+	// DeletePreferences removes a contact's preferences, so it's reached on every channel again
+	DeletePreferences(ctx context.Context, contact string) error
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // QueueMessage wraps a notification with queue-specific metadata
@@ -17,6 +18,9 @@ type QueueMessage struct {
 
 	// EnqueuedAt is when the message was added to the queue
 	EnqueuedAt int64 `json:"enqueued_at"`
+
+	// DeliverAt is when a delayed message becomes available to dequeue (zero when not delayed)
+	DeliverAt time.Time `json:"deliver_at,omitzero"`
 }
 
 // Queue defines the interface for a notification queue
@@ -27,6 +31,11 @@ type Queue interface {
 	// EnqueueBatch adds multiple notifications to the queue
 	EnqueueBatch(ctx context.Context, notifications []*Notification) error
 
+	// EnqueueDelayed adds a notification that can't be dequeued before deliverAt.
+	// The notification is marked scheduled; a deliverAt that isn't in the future behaves like
+	// Enqueue.
+	EnqueueDelayed(ctx context.Context, notification *Notification, deliverAt time.Time) error
+
 	// Dequeue retrieves the next notification from the queue
 	// Returns nil if the queue is empty
 	Dequeue(ctx context.Context) (*QueueMessage, error)
@@ -37,6 +46,10 @@ type Queue interface {
 	// Nack indicates processing failure and may requeue the message
 	Nack(ctx context.Context, messageID string, requeue bool) error
 
+	// NackDelayed indicates processing failure and requeues the message so it can't be
+	// dequeued again before deliverAt, e.g. to back off between retries
+	NackDelayed(ctx context.Context, messageID string, deliverAt time.Time) error
+
 	// Size returns the current number of messages in the queue
 	Size(ctx context.Context) (int64, error)
 
@@ -50,9 +63,38 @@ type Queue interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// QueueMetrics is a snapshot of a queue's backlog and throughput. Counters are cumulative
+// since the queue was created.
+type QueueMetrics struct {
+	Backend          string  `json:"backend"`            // Queue implementation, e.g. "local"
+	Depth            int64   `json:"depth"`              // Messages waiting to be dequeued
+	InFlight         int64   `json:"in_flight"`          // Messages dequeued but not yet acked or nacked
+	Delayed          int64   `json:"delayed"`            // Messages waiting for their delivery time
+	OldestAgeSeconds float64 `json:"oldest_age_seconds"` // Age of the oldest unacknowledged message, from its delivery time when delayed (0 when empty)
+	Enqueued         uint64  `json:"enqueued_total"`     // Messages added to the queue
+	Acked            uint64  `json:"acked_total"`        // Messages acknowledged
+	Nacked           uint64  `json:"nacked_total"`       // Messages negatively acknowledged, requeued or not
+	Requeued         uint64  `json:"requeued_total"`     // Nacked messages that were requeued
+}
+
+// QueueMetricsReporter is implemented by queues that report metrics, and by the service
+type QueueMetricsReporter interface {
+	QueueMetrics() QueueMetrics
+}
+
+// QueueInspector is implemented by queues that can list the messages they hold,
+// so the service can report what a shutdown leaves behind
+type QueueInspector interface {
+	// PendingMessages returns the messages not yet acked, whether waiting or in flight
+	PendingMessages() []*QueueMessage
+
+	// Durable reports whether pending messages survive a restart
+	Durable() bool
+}
+
 // QueueConfig contains configuration for queue implementations
 type QueueConfig struct {
-	// Type specifies the queue implementation (local, kafka, etc.)
+	// Type specifies the queue implementation (local, postgres, kafka)
 	Type string `mapstructure:"type"`
 
 	// MaxSize is the maximum number of messages the queue can hold
@@ -67,9 +109,19 @@ type QueueConfig struct {
 	// RetryBackoff is the backoff strategy for retries (exponential, linear, fixed)
 	RetryBackoff string `mapstructure:"retry_backoff"`
 
+	// RetryBaseDelay is the wait before the first retry, which the backoff strategy grows
+	// from (e.g., "1s"; "0s" retries immediately)
+	RetryBaseDelay string `mapstructure:"retry_base_delay"`
+
+	// RetryMaxDelay caps the wait between retries (e.g., "5m")
+	RetryMaxDelay string `mapstructure:"retry_max_delay"`
+
 	// Local queue specific config
 	Local *LocalQueueConfig `mapstructure:"local,omitempty"`
 
+	// PostgreSQL specific config
+	Postgres *PostgresQueueConfig `mapstructure:"postgres,omitempty"`
+
 	// Kafka specific config
 	Kafka *KafkaQueueConfig `mapstructure:"kafka,omitempty"`
 }
@@ -84,6 +136,46 @@ type LocalQueueConfig struct {
 
 	// PersistPath is where to store the queue state
 	PersistPath string `mapstructure:"persist_path"`
+
+	// PersistBackend selects how queue state is stored: "json" (default) rewrites a JSON
+	// file on every change, "bbolt" writes changes transactionally to an embedded database
+	PersistBackend string `mapstructure:"persist_backend"`
+
+	// FairScheduling serves tenants round-robin at dequeue time instead of strict FIFO,
+	// so one tenant's large batch doesn't delay everyone else's notifications.
+	// A tenant is the sending client's tenant, the "tenant" metadata value, or
+	// "<type>/<account>" when neither is set.
+	FairScheduling bool `mapstructure:"fair_scheduling"`
+
+	// TenantWeights gives tenants a larger share of dequeues (default weight 1)
+	TenantWeights map[string]int `mapstructure:"tenant_weights"`
+
+	// PriorityScheduling dequeues critical and high priority notifications before normal
+	// and low ones instead of strict FIFO. It can't be combined with FairScheduling,
+	// which already orders each tenant's notifications by priority.
+	PriorityScheduling bool `mapstructure:"priority_scheduling"`
+
+	// PriorityMaxWait is how long a notification may be passed over by higher priorities
+	// before it's dequeued anyway, so low priorities aren't starved (default "1m")
+	PriorityMaxWait string `mapstructure:"priority_max_wait"`
+}
+
+// PostgresQueueConfig contains configuration for the PostgreSQL queue. Messages are stored
+// in a table and claimed with SELECT ... FOR UPDATE SKIP LOCKED, so they survive restarts
+// and several server instances can consume the same queue.
+type PostgresQueueConfig struct {
+	// URL is the database connection URL
+	URL string `mapstructure:"url"`
+
+	// AutoMigrate applies pending schema migrations at startup
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+
+	// PollInterval is how often an idle consumer checks for new messages (e.g., "500ms")
+	PollInterval string `mapstructure:"poll_interval"`
+
+	// LeaseTimeout is how long a dequeued message stays claimed by its consumer. Messages
+	// not acked or nacked in time are redelivered, e.g. after the consumer crashed.
+	LeaseTimeout string `mapstructure:"lease_timeout"`
 }
 
 // KafkaQueueConfig contains configuration for Kafka queue
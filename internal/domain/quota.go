@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrQuotaExceeded is returned when a client or tenant has used up a request-rate or
+// daily-volume quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota kinds
+const (
+	QuotaRequests      = "requests_per_minute"
+	QuotaNotifications = "notifications_per_day"
+)
+
+// QuotaError reports the quota a request exceeded and when it resets. It wraps ErrQuotaExceeded.
+type QuotaError struct {
+	Subject string // "client:<id>" or "tenant:<name>"
+	Kind    string // One of the Quota kinds
+	Limit   int64
+	ResetAt time.Time
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s: %s has used its %d %s", ErrQuotaExceeded, e.Subject, e.Limit, e.Kind)
+}
+
+func (e *QuotaError) Unwrap() error {
+	return ErrQuotaExceeded
+}
+
+// QuotaCounter is the current use of one quota
+type QuotaCounter struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// QuotaUsage is a client's or tenant's use of its quotas. Quotas that aren't set are omitted.
+type QuotaUsage struct {
+	Subject             string        `json:"subject"`
+	RequestsPerMinute   *QuotaCounter `json:"requests_per_minute,omitempty"`
+	NotificationsPerDay *QuotaCounter `json:"notifications_per_day,omitempty"`
+}
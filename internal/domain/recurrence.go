@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidRecurrence is returned when a notification's recurrence schedule can't be parsed
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// ErrRecurrenceNotFound is returned when a recurring notification doesn't exist
+var ErrRecurrenceNotFound = errors.New("recurring notification not found")
+
+// ErrRecurrenceFinished is returned when pausing or resuming a recurring notification that
+// has no more occurrences
+var ErrRecurrenceFinished = errors.New("recurring notification has finished")
+
+// RecurrenceStatus represents the state of a recurring notification
+type RecurrenceStatus string
+
+const (
+	RecurrenceActive    RecurrenceStatus = "active"    // Occurrences are sent as they come due
+	RecurrencePaused    RecurrenceStatus = "paused"    // Occurrences are skipped until it's resumed
+	RecurrenceCompleted RecurrenceStatus = "completed" // The schedule has no more occurrences
+)
+
+// RecurringNotification sends a copy of a notification at every occurrence of a schedule
+type RecurringNotification struct {
+	ID       string           `json:"id"`
+	Schedule string           `json:"schedule"` // Cron expression or RRULE
+	Status   RecurrenceStatus `json:"status"`
+
+	// Notification is the template each occurrence is copied from
+	Notification *Notification `json:"notification"`
+
+	Occurrences        int        `json:"occurrences"` // Occurrences sent so far
+	NextAt             *time.Time `json:"next_at,omitempty"`
+	LastAt             *time.Time `json:"last_at,omitempty"`
+	LastNotificationID string     `json:"last_notification_id,omitempty"`
+	LastError          string     `json:"last_error,omitempty"` // Why the last occurrence couldn't be sent, if it couldn't
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// RecurrenceManager is implemented by services that send recurring notifications. They're
+// created by sending a notification with a Recurrence schedule.
+type RecurrenceManager interface {
+	// ListRecurringNotifications returns every recurring notification, oldest first
+	ListRecurringNotifications(ctx context.Context) ([]*RecurringNotification, error)
+
+	// GetRecurringNotification returns a recurring notification
+	GetRecurringNotification(ctx context.Context, id string) (*RecurringNotification, error)
+
+	// PauseRecurringNotification skips occurrences until the recurring notification is resumed
+	PauseRecurringNotification(ctx context.Context, id string) error
+
+	// ResumeRecurringNotification sends occurrences again, starting with the next one due
+	ResumeRecurringNotification(ctx context.Context, id string) error
+
+	// DeleteRecurringNotification stops a recurring notification for good. Occurrences
+	// already sent are kept.
+	DeleteRecurringNotification(ctx context.Context, id string) error
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrReplyUnmatched is returned when an inbound email isn't addressed to the reply address of
+// a notification we still hold
+var ErrReplyUnmatched = errors.New("reply does not match a notification")
+
+// InboundReply is an email received in reply to a notification
+type InboundReply struct {
+	From       string    `json:"from"`
+	To         []string  `json:"to"` // Every recipient address, including Cc and the envelope recipient
+	Subject    string    `json:"subject,omitempty"`
+	Text       string    `json:"text,omitempty"`
+	HTML       string    `json:"html,omitempty"`
+	MessageID  string    `json:"message_id,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// ReplyReceiver is implemented by services that route email replies back to the system that
+// sent the original notification
+type ReplyReceiver interface {
+	// ReceiveReply delivers a reply to the callback of the notification it answers and returns
+	// that notification's ID. ErrReplyUnmatched means no notification was found for it.
+	ReceiveReply(ctx context.Context, reply *InboundReply) (string, error)
+}
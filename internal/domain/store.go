@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrNotificationNotFound is returned when a notification isn't in the store
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// ErrInvalidCursor is returned when a page cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NotificationStore persists notifications and their delivery status. The service saves a
+// notification when it's accepted and updates it whenever its status changes.
+type NotificationStore interface {
+	// Save stores a newly accepted notification, replacing any with the same ID
+	Save(ctx context.Context, notification *Notification) error
+
+	// Get retrieves a notification by ID, wrapping ErrNotificationNotFound when it's unknown
+	Get(ctx context.Context, id string) (*Notification, error)
+
+	// List retrieves notifications matching the filter, oldest first, applying its
+	// offset and limit. A nil filter matches every notification.
+	List(ctx context.Context, filter *NotificationFilter) ([]*Notification, error)
+
+	// ListPage retrieves a page of notifications matching the filter, newest first. The
+	// filter's limit is the page size, its cursor continues after a previous page and its
+	// offset skips notifications after that. Total counts every match, ignoring all three.
+	ListPage(ctx context.Context, filter *NotificationFilter) (*NotificationPage, error)
+
+	// Update stores a notification's changed fields, wrapping ErrNotificationNotFound
+	// when it's unknown
+	Update(ctx context.Context, notification *Notification) error
+
+	// Delete removes notifications by ID, ignoring unknown IDs
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// NotificationReader is the read side of a NotificationStore
+type NotificationReader interface {
+	Get(ctx context.Context, id string) (*Notification, error)
+	List(ctx context.Context, filter *NotificationFilter) ([]*Notification, error)
+	ListPage(ctx context.Context, filter *NotificationFilter) (*NotificationPage, error)
+}
+
+// ReplicaStore is implemented by stores that can answer API reads from a read replica,
+// keeping lookups, listings, stats and exports off the primary that takes status updates
+type ReplicaStore interface {
+	// Reader returns the store's reads, served by its replica when one is configured
+	Reader() NotificationReader
+
+	// ReadStaleness reports the source of Reader's queries and its replication lag
+	ReadStaleness(ctx context.Context) (*ReadStaleness, error)
+}
+
+// ReadStaleness describes how far behind the primary a read may be
+type ReadStaleness struct {
+	Source     string  `json:"source"`      // "primary" or "replica"
+	LagSeconds float64 `json:"lag_seconds"` // Replication lag at query time (0 on the primary)
+}
+
+// ReadStalenessReporter is implemented by services whose lookups and listings may come
+// from a read replica
+type ReadStalenessReporter interface {
+	// ReadStaleness reports how stale reads may be, or nil when they're always current
+	ReadStaleness(ctx context.Context) *ReadStaleness
+}
+
+// NotificationPage is one page of a listing, newest first
+type NotificationPage struct {
+	Notifications []*Notification
+	Total         int64  // Notifications matching the filter across all pages
+	NextCursor    string // Cursor for the next page; empty on the last page
+}
+
+// PageCursor marks the last notification of a page. Listings are ordered by creation time
+// and then ID, both descending, so the cursor is stable while notifications are added.
+type PageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// CursorAfter returns the cursor that continues a listing after the notification
+func CursorAfter(notification *Notification) PageCursor {
+	return PageCursor{CreatedAt: notification.CreatedAt, ID: notification.ID}
+}
+
+// String encodes the cursor as an opaque token
+func (c PageCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageCursor decodes a cursor token, wrapping ErrInvalidCursor when it's malformed
+func ParsePageCursor(token string) (PageCursor, error) {
+	var cursor PageCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return PageCursor{}, fmt.Errorf("%w: %q", ErrInvalidCursor, token)
+	}
+	return cursor, nil
+}
+
+// Precedes reports whether a notification comes after the cursor in a newest-first listing
+func (c PageCursor) Precedes(notification *Notification) bool {
+	if !notification.CreatedAt.Equal(c.CreatedAt) {
+		return notification.CreatedAt.Before(c.CreatedAt)
+	}
+	return notification.ID < c.ID
+}
+
+// RetentionMetrics is a snapshot of the retention janitor's pruning. Counters are cumulative
+// since the service started.
+type RetentionMetrics struct {
+	Stored        int64     `json:"stored"`               // Notifications in the store after the last run
+	Runs          uint64    `json:"runs_total"`           // Janitor runs, including failed ones
+	Failures      uint64    `json:"failures_total"`       // Runs that couldn't read or prune the store
+	PrunedExpired uint64    `json:"pruned_expired_total"` // Sent or failed notifications removed for outliving the TTL
+	PrunedExcess  uint64    `json:"pruned_excess_total"`  // Sent or failed notifications removed to stay under max_size
+	LastRunAt     time.Time `json:"last_run_at,omitzero"` // When the janitor last ran
+}
+
+// RetentionMetricsReporter is implemented by services that prune their notification store
+type RetentionMetricsReporter interface {
+	RetentionMetrics() RetentionMetrics
+}
+
+// Matches reports whether a notification satisfies the filter, ignoring its offset, limit and cursor
+func (f *NotificationFilter) Matches(notification *Notification) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.IDs) > 0 && !slices.Contains(f.IDs, notification.ID) {
+		return false
+	}
+	if len(f.Types) > 0 && !slices.Contains(f.Types, notification.Type) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, notification.Status) {
+		return false
+	}
+	if len(f.Recipients) > 0 && !slices.ContainsFunc(notification.Recipients, func(recipient string) bool {
+		return slices.Contains(f.Recipients, recipient)
+	}) {
+		return false
+	}
+	if len(f.OriginSystems) > 0 && !slices.Contains(f.OriginSystems, notification.Origin.System) {
+		return false
+	}
+	if len(f.OriginUsers) > 0 && !slices.Contains(f.OriginUsers, notification.Origin.User) {
+		return false
+	}
+	if !notification.HasTags(f.Tags) {
+		return false
+	}
+	if f.FanoutID != "" && notification.FanoutID != f.FanoutID {
+		return false
+	}
+	if f.Tenant != "" && notification.Tenant != f.Tenant {
+		return false
+	}
+	if f.CreatedAfter != nil && notification.CreatedAt.Before(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && notification.CreatedAt.After(*f.CreatedBefore) {
+		return false
+	}
+	return true
+}
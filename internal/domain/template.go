@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTemplateNotFound is returned when a notification references a template that doesn't exist
+var ErrTemplateNotFound = errors.New("template not found")
+
+// ErrInvalidTemplate is returned when a template doesn't parse or can't be rendered with the
+// variables it was given
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// ErrTemplateExists is returned when creating a template with a name that's already taken
+var ErrTemplateExists = errors.New("template already exists")
+
+// ErrTemplateConfigured is returned when changing or deleting a template that comes from the
+// config file
+var ErrTemplateConfigured = errors.New("template is configured and can't be changed")
+
+// Template renders the subject and bodies of a notification from variables. Subject and Body
+// are Go text/template; HTMLBody is Go html/template, so variables are escaped. A field left
+// empty keeps whatever the notification itself sets.
+type Template struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Type        NotificationType `json:"type,omitempty"` // Channel the template is for; empty renders for every channel
+	Subject     string           `json:"subject,omitempty"`
+	Body        string           `json:"body,omitempty"`
+	HTMLBody    string           `json:"html_body,omitempty"`
+	Configured  bool             `json:"configured,omitempty"` // From the config file rather than the API
+	Tenant      string           `json:"tenant,omitempty"`     // Tenant that created it through the API
+	CreatedAt   time.Time        `json:"created_at,omitzero"`
+	UpdatedAt   time.Time        `json:"updated_at,omitzero"`
+
+	// Layout wraps the HTML body of email notifications in a built-in layout, e.g.
+	// "responsive", with styles inlined. The text body is then generated from the HTML unless
+	// the template sets one.
+	Layout string `json:"layout,omitempty"`
+
+	// Variants are channel-specific renderings, e.g. Slack mrkdwn or a short SMS text. A
+	// notification of a channel with a variant uses the parts the variant sets in place of
+	// the template's own.
+	Variants map[NotificationType]TemplateVariant `json:"variants,omitempty"`
+
+	// Locales are translations keyed by locale tag, e.g. "fr" or "pt-BR". A notification with
+	// a locale uses the parts its translation sets, falling back to the language ("pt" for
+	// "pt-BR"), then any configured fallback locales, then the template's own parts.
+	Locales map[string]TemplateLocale `json:"locales,omitempty"`
+}
+
+// TemplateLocale is a template's translation into one locale, with its own channel variants
+type TemplateLocale struct {
+	Subject  string                               `json:"subject,omitempty"`
+	Body     string                               `json:"body,omitempty"`
+	HTMLBody string                               `json:"html_body,omitempty"`
+	Variants map[NotificationType]TemplateVariant `json:"variants,omitempty"`
+}
+
+// TemplateVariant is one channel's rendering of a template
+type TemplateVariant struct {
+	Subject  string `json:"subject,omitempty"`
+	Body     string `json:"body,omitempty"`
+	HTMLBody string `json:"html_body,omitempty"`
+}
+
+// AppliesTo reports whether the template can render notifications of a type: it's for that
+// type, or for every channel and has either a variant for the type or parts of its own, in
+// any locale
+func (t *Template) AppliesTo(notifType NotificationType) bool {
+	if t.Type != "" {
+		return t.Type == notifType
+	}
+	if _, ok := t.Variants[notifType]; ok || t.Subject != "" || t.Body != "" || t.HTMLBody != "" {
+		return true
+	}
+	for _, locale := range t.Locales {
+		if _, ok := locale.Variants[notifType]; ok || locale.Subject != "" || locale.Body != "" || locale.HTMLBody != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderedTemplate is a template rendered for a preview, without sending anything
+type RenderedTemplate struct {
+	Template string           `json:"template"`
+	Type     NotificationType `json:"type,omitempty"`
+	Locale   string           `json:"locale,omitempty"` // Translation the most specific parts came from; empty for the template's own
+	Subject  string           `json:"subject,omitempty"`
+	Body     string           `json:"body,omitempty"`
+	HTMLBody string           `json:"html_body,omitempty"`
+}
+
+// TemplateStore is implemented by notification stores that also keep the templates created
+// through the API. Each tenant has its own templates; "" holds those created without one.
+type TemplateStore interface {
+	// SaveTemplate stores a template, replacing any of its tenant's with the same name
+	SaveTemplate(ctx context.Context, tmpl *Template) error
+
+	// GetTemplate retrieves a tenant's template by name, wrapping ErrTemplateNotFound when
+	// it's unknown
+	GetTemplate(ctx context.Context, tenant, name string) (*Template, error)
+
+	// ListTemplates retrieves every template of a tenant, sorted by name
+	ListTemplates(ctx context.Context, tenant string) ([]*Template, error)
+
+	// DeleteTemplate removes a tenant's template, wrapping ErrTemplateNotFound when it's unknown
+	DeleteTemplate(ctx context.Context, tenant, name string) error
+}
+
+// TemplateManager is implemented by services that manage templates through the API
+type TemplateManager interface {
+	// CreateTemplate validates and saves a new template, wrapping ErrInvalidTemplate if it
+	// doesn't parse
+	CreateTemplate(ctx context.Context, tmpl Template) (*Template, error)
+
+	// UpdateTemplate validates and replaces an existing template
+	UpdateTemplate(ctx context.Context, tmpl Template) (*Template, error)
+
+	// GetTemplate returns a template by name
+	GetTemplate(ctx context.Context, name string) (*Template, error)
+
+	// ListTemplates returns the templates that can render notifications of a type, or every
+	// template if the type is empty, sorted by name
+	ListTemplates(ctx context.Context, notifType NotificationType) ([]*Template, error)
+
+	// DeleteTemplate removes a template created through the API
+	DeleteTemplate(ctx context.Context, name string) error
+
+	// RenderTemplate renders a template as a notification of a type and locale would be,
+	// without sending anything. An empty type renders a template for one channel as that
+	// channel, and any other template from its own parts.
+	RenderTemplate(ctx context.Context, name string, notifType NotificationType, locale string, vars map[string]interface{}) (*RenderedTemplate, error)
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNoTenant is returned when tenancy is enabled and a caller's credentials aren't assigned
+	// to a tenant
+	ErrNoTenant = errors.New("credentials aren't assigned to a tenant")
+
+	// ErrTenantAccount is returned when a tenant sends through an account scoped to other tenants
+	ErrTenantAccount = errors.New("account not available to tenant")
+)
@@ -0,0 +1,12 @@
+package domain
+
+// VersionInfo describes the running build and what it has enabled, so automation can verify a
+// deployment and gate behaviour on capabilities
+type VersionInfo struct {
+	Version   string             `json:"version"`
+	GitCommit string             `json:"git_commit"`
+	BuildTime string             `json:"build_time"`
+	Profile   string             `json:"profile"`   // Build profile: full or lite
+	Features  []string           `json:"features"`  // Enabled subsystems, sorted
+	Notifiers []NotificationType `json:"notifiers"` // Configured notification types
+}
@@ -0,0 +1,208 @@
+// Package emailhtml prepares HTML email. It wraps message content in a responsive layout with
+// the styles inlined on each element, as many mail clients ignore style sheets, and converts
+// HTML to the plain-text part sent alongside it.
+package emailhtml
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// LayoutResponsive is the built-in layout: the content in a centred white card that narrows
+// to the width of the screen on phones
+const LayoutResponsive = "responsive"
+
+// Layouts are the layouts content can be wrapped in
+var Layouts = []string{LayoutResponsive}
+
+// DefaultAccentColor is the color of links and buttons when the style doesn't set one
+const DefaultAccentColor = "#2563eb"
+
+// validColor matches the hex colors a style can use, so they're safe to put in a style attribute
+var validColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// fontFamily is the font stack of the layout
+const fontFamily = "-apple-system,BlinkMacSystemFont,'Segoe UI',Roboto,Helvetica,Arial,sans-serif"
+
+// Style is the look of the layout, shared by every template that uses it
+type Style struct {
+	AccentColor string // Hex color of links and buttons; empty uses DefaultAccentColor
+	LogoURL     string // Image shown above the content; empty shows none
+	Footer      string // Text shown below the content, e.g. the sender's postal address
+}
+
+// Validate checks the style can be used in a layout
+func (s Style) Validate() error {
+	if s.AccentColor != "" && !validColor.MatchString(s.AccentColor) {
+		return fmt.Errorf("invalid accent color %q (use a hex color such as #2563eb)", s.AccentColor)
+	}
+	if s.LogoURL != "" && !strings.HasPrefix(s.LogoURL, "https://") && !strings.HasPrefix(s.LogoURL, "http://") {
+		return fmt.Errorf("invalid logo URL %q (must be http or https)", s.LogoURL)
+	}
+	return nil
+}
+
+// ValidLayout reports whether a layout is one content can be wrapped in
+func ValidLayout(layout string) bool {
+	return slices.Contains(Layouts, layout)
+}
+
+// elementStyles are the inline styles given to elements of the content, keyed by tag. Styles
+// the content sets itself come after them, so they win.
+var elementStyles = map[atom.Atom]string{
+	atom.P:          "margin:0 0 16px;",
+	atom.H1:         "margin:0 0 16px;font-size:24px;line-height:32px;font-weight:bold;",
+	atom.H2:         "margin:0 0 16px;font-size:20px;line-height:28px;font-weight:bold;",
+	atom.H3:         "margin:0 0 12px;font-size:18px;line-height:26px;font-weight:bold;",
+	atom.Ul:         "margin:0 0 16px;padding:0 0 0 24px;",
+	atom.Ol:         "margin:0 0 16px;padding:0 0 0 24px;",
+	atom.Li:         "margin:0 0 8px;",
+	atom.Blockquote: "margin:0 0 16px;padding:0 0 0 16px;border-left:4px solid #e4e4e7;color:#52525b;",
+	atom.Pre:        "margin:0 0 16px;padding:12px;background-color:#f4f4f5;border-radius:4px;font-family:Menlo,Consolas,monospace;font-size:13px;line-height:20px;white-space:pre-wrap;",
+	atom.Code:       "font-family:Menlo,Consolas,monospace;font-size:14px;",
+	atom.Hr:         "margin:24px 0;border:0;border-top:1px solid #e4e4e7;",
+	atom.Img:        "max-width:100%;height:auto;border:0;",
+	atom.Table:      "margin:0 0 16px;border-collapse:collapse;",
+	atom.Th:         "padding:8px;border-bottom:1px solid #e4e4e7;text-align:left;",
+	atom.Td:         "padding:8px;border-bottom:1px solid #e4e4e7;",
+}
+
+// layoutTemplate is the responsive layout. Tables rather than divs hold it together, as that's
+// what Outlook lays out reliably; the media query is the one style that can't be inlined.
+var layoutTemplate = template.Must(template.New("layout").Parse(`<!DOCTYPE html>
+<html{{with .Lang}} lang="{{.}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="x-apple-disable-message-reformatting">
+<title>{{.Subject}}</title>
+<style>
+@media only screen and (max-width: 620px) {
+  .container { width: 100% !important; }
+  .content { padding: 24px 16px !important; }
+}
+</style>
+</head>
+<body style="margin:0;padding:0;background-color:#f4f4f5;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" border="0" style="background-color:#f4f4f5;">
+<tr><td align="center" style="padding:24px 8px;">
+<table role="presentation" class="container" width="600" cellpadding="0" cellspacing="0" border="0" style="width:600px;max-width:600px;background-color:#ffffff;border-radius:8px;">
+{{with .LogoURL}}<tr><td align="center" style="padding:32px 40px 0;"><img src="{{.}}" alt="" width="120" style="display:block;width:120px;max-width:120px;height:auto;border:0;"></td></tr>
+{{end}}<tr><td class="content" style="padding:32px 40px;font-family:{{.FontFamily}};font-size:16px;line-height:24px;color:#18181b;">
+{{.Content}}
+</td></tr>
+</table>
+{{with .Footer}}<table role="presentation" class="container" width="600" cellpadding="0" cellspacing="0" border="0" style="width:600px;max-width:600px;">
+<tr><td align="center" style="padding:16px 40px;font-family:{{$.FontFamily}};font-size:12px;line-height:18px;color:#71717a;">{{.}}</td></tr>
+</table>
+{{end}}</td></tr>
+</table>
+</body>
+</html>
+`))
+
+// layoutData is the data for the layout template
+type layoutData struct {
+	Lang       string
+	Subject    string
+	LogoURL    string
+	Footer     string
+	FontFamily template.CSS
+	Content    template.HTML
+}
+
+// Wrap puts an HTML fragment in a layout with the styles of each element inlined. subject
+// becomes the document title and lang, a locale tag such as "fr", its language.
+func Wrap(layout string, style Style, subject, lang, content string) (string, error) {
+	if !ValidLayout(layout) {
+		return "", fmt.Errorf("unknown layout %q", layout)
+	}
+	if err := style.Validate(); err != nil {
+		return "", err
+	}
+
+	inlined, err := InlineStyles(content, style)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = layoutTemplate.Execute(&buf, layoutData{
+		Lang:       lang,
+		Subject:    subject,
+		LogoURL:    style.LogoURL,
+		Footer:     style.Footer,
+		FontFamily: template.CSS(fontFamily),
+		Content:    template.HTML(inlined), // Re-rendered by the HTML parser, so it's well formed
+	})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// InlineStyles gives each element of an HTML fragment the layout's style for its tag, ahead
+// of any style it sets itself. Links get the accent color, or are drawn as a button if they
+// have the "button" class.
+func InlineStyles(content string, style Style) (string, error) {
+	accent := style.AccentColor
+	if accent == "" {
+		accent = DefaultAccentColor
+	}
+	context := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var buf bytes.Buffer
+	for _, n := range nodes {
+		inline(n, accent)
+		if err := html.Render(&buf, n); err != nil {
+			return "", fmt.Errorf("failed to render HTML: %w", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// inline sets the inline styles of an element and its descendants
+func inline(n *html.Node, accent string) {
+	if n.Type == html.ElementNode {
+		base := elementStyles[n.DataAtom]
+		if n.DataAtom == atom.A {
+			base = "color:" + accent + ";text-decoration:underline;"
+			if hasClass(n, "button") {
+				base = "display:inline-block;padding:12px 24px;background-color:" + accent + ";color:#ffffff;text-decoration:none;border-radius:4px;font-weight:bold;"
+			}
+		}
+		if base != "" {
+			setStyle(n, base)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		inline(c, accent)
+	}
+}
+
+// setStyle puts base ahead of an element's own style attribute
+func setStyle(n *html.Node, base string) {
+	for i, a := range n.Attr {
+		if a.Namespace == "" && a.Key == "style" {
+			n.Attr[i].Val = base + a.Val
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: "style", Val: base})
+}
+
+// hasClass reports whether an element has a class
+func hasClass(n *html.Node, class string) bool {
+	return slices.Contains(strings.Fields(attr(n, "class")), class)
+}
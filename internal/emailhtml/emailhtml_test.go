@@ -0,0 +1,99 @@
+package emailhtml
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPlainText tests that HTML is converted to the text a reader would see
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"paragraphs", "<p>Hello <b>Alice</b>,</p><p>Your   order\n shipped.</p>", "Hello Alice,\n\nYour order shipped."},
+		{"line breaks", "<h1>Title</h1>Line one<br>Line two", "Title\n\nLine one\nLine two"},
+		{"lists", `<ul><li>One</li><li>Two<ul><li>Nested</li></ul></li></ul><ol start="3"><li>Three</li><li>Four</li></ol>`, "- One\n- Two\n  - Nested\n\n3. Three\n4. Four"},
+		{"links", `<p>Read <a href="https://example.com/docs">the docs</a> or <a href="https://example.com">https://example.com</a>, <a href="mailto:a@b.c">a@b.c</a>, <a href="#top">top</a>.</p>`, "Read the docs (https://example.com/docs) or https://example.com, a@b.c, top."},
+		{"image link", `<a href="https://example.com"><img src="logo.png" alt="Logo"></a> <a href="https://example.com/x"><img src="x.png"></a>`, "Logo (https://example.com) https://example.com/x"},
+		{"hidden content", `<html><head><title>T</title><style>p{color:red}</style></head><body><div style="display: none">Preview</div><script>x()</script><p>Body &amp; soul &lt;3</p></body></html>`, "Body & soul <3"},
+		{"quote", "<blockquote><p>Quoted</p><p>Second</p></blockquote><p>After</p>", "> Quoted\n>\n> Second\n\nAfter"},
+		{"preformatted", "<pre>  code\n    indented</pre><hr><p>After</p>", "  code\n    indented\n\n---\n\nAfter"},
+		{"table", "<table><tr><td>Name</td><td>Alice</td></tr><tr><td>Plan</td><td>Pro</td></tr></table>", "Name Alice\nPlan Pro"},
+		{"plain text", "plain text only", "plain text only"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.html); got != tt.want {
+				t.Errorf("PlainText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestInlineStyles tests that elements get the layout's styles ahead of their own, and that
+// links with the button class are drawn as buttons in the accent color
+func TestInlineStyles(t *testing.T) {
+	got, err := InlineStyles(`<p style="color:red">Hi</p><a href="https://example.com" class="cta button">Go</a>`, Style{AccentColor: "#ff0000"})
+	if err != nil {
+		t.Fatalf("InlineStyles() error = %v", err)
+	}
+	if !strings.Contains(got, `<p style="margin:0 0 16px;color:red">Hi</p>`) {
+		t.Errorf("Expected the paragraph's own style after the layout's, got %s", got)
+	}
+	if !strings.Contains(got, `background-color:#ff0000;color:#ffffff;`) {
+		t.Errorf("Expected a button in the accent color, got %s", got)
+	}
+}
+
+// TestWrap tests that content is put in the layout with the style's logo and footer, and
+// that the footer and subject are escaped
+func TestWrap(t *testing.T) {
+	style := Style{LogoURL: "https://example.com/logo.png", Footer: "Example & Co, 1 Main St"}
+	got, err := Wrap(LayoutResponsive, style, "Hi <there>", "fr", `<p>Bonjour <a href="https://example.com">ici</a></p>`)
+	if err != nil {
+		t.Fatalf("Wrap() error = %v", err)
+	}
+
+	for _, want := range []string{
+		`<html lang="fr">`,
+		`<title>Hi &lt;there&gt;</title>`,
+		`<img src="https://example.com/logo.png"`,
+		`<p style="margin:0 0 16px;">Bonjour <a href="https://example.com" style="color:#2563eb;text-decoration:underline;">ici</a></p>`,
+		`Example &amp; Co, 1 Main St`,
+		`@media only screen and (max-width: 620px)`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected the layout to contain %s, got %s", want, got)
+		}
+	}
+
+	if _, err := Wrap("fancy", Style{}, "", "", "<p>Hi</p>"); err == nil {
+		t.Error("Expected an error for an unknown layout")
+	}
+}
+
+// TestStyleValidate tests that only hex accent colors and web logo URLs are accepted
+func TestStyleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		style   Style
+		wantErr bool
+	}{
+		{"empty", Style{}, false},
+		{"valid", Style{AccentColor: "#0a0", LogoURL: "https://example.com/logo.png", Footer: "Example"}, false},
+		{"named color", Style{AccentColor: "red"}, true},
+		{"css injection", Style{AccentColor: "#fff;background:url(x)"}, true},
+		{"logo scheme", Style{LogoURL: "javascript:alert(1)"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.style.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package emailhtml
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// blockBreaks are the newlines around the content of block elements: a blank line around
+// paragraph-like elements, a line break around the rest. Lists are handled separately, as a
+// list nested in an item only starts a new line.
+var blockBreaks = map[atom.Atom]int{
+	atom.P: 2, atom.H1: 2, atom.H2: 2, atom.H3: 2, atom.H4: 2, atom.H5: 2, atom.H6: 2,
+	atom.Table: 2, atom.Pre: 2, atom.Hr: 2, atom.Blockquote: 2, atom.Dl: 2, atom.Figure: 2,
+	atom.Div: 1, atom.Section: 1, atom.Article: 1, atom.Header: 1, atom.Footer: 1, atom.Main: 1,
+	atom.Nav: 1, atom.Aside: 1, atom.Address: 1, atom.Center: 1, atom.Form: 1, atom.Tr: 1,
+	atom.Li: 1, atom.Dt: 1, atom.Dd: 1, atom.Figcaption: 1, atom.Caption: 1,
+}
+
+// skipped elements have no text a reader would see
+var skipped = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Title: true, atom.Template: true,
+	atom.Noscript: true, atom.Iframe: true, atom.Object: true, atom.Svg: true,
+}
+
+// blankLines matches runs of blank lines beyond the first
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// PlainText converts HTML to the plain text a reader would see: paragraphs and headings are
+// separated by blank lines, list items get a "- " or number, links are followed by their URL
+// and quotes are prefixed with "> ". Scripts, styles and hidden elements are left out.
+func PlainText(htmlContent string) string {
+	doc, err := html.Parse(strings.NewReader(htmlContent))
+	if err != nil {
+		return strings.TrimSpace(htmlContent) // Only returned for reader errors
+	}
+	var w textWriter
+	w.node(doc)
+	return w.String()
+}
+
+// textWriter accumulates the text of a document, tracking the whitespace between its parts
+type textWriter struct {
+	out     strings.Builder
+	started bool  // Something has been written
+	breaks  int   // Newlines at the end of out
+	space   bool  // Whitespace is pending before the next word
+	noSpace bool  // The line starts with a list marker, so pending whitespace is dropped
+	pre     int   // Depth inside <pre>, where whitespace is kept
+	lists   []int // Next item number of each open list; 0 for an unordered list
+}
+
+// node writes the text of a node and its children
+func (w *textWriter) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		w.text(n.Data)
+		return
+	case html.DocumentNode:
+		w.children(n)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+	if skipped[n.DataAtom] || hidden(n) {
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		w.write("\n")
+	case atom.Hr:
+		w.lineBreak(2)
+		w.write("---")
+		w.lineBreak(2)
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			w.text(alt)
+		}
+	case atom.A:
+		before := w.out.Len()
+		w.children(n)
+		w.link(n, w.out.Len() > before)
+	case atom.Ul, atom.Ol:
+		w.list(n)
+	case atom.Li:
+		w.item(n)
+	case atom.Blockquote:
+		w.quote(n)
+	case atom.Pre:
+		w.lineBreak(2)
+		w.pre++
+		w.children(n)
+		w.pre--
+		w.lineBreak(2)
+	case atom.Td, atom.Th:
+		w.space = true // Cells of a row run on, as most tables in email are for layout
+		w.children(n)
+		w.space = true
+	default:
+		breaks := blockBreaks[n.DataAtom]
+		w.lineBreak(breaks)
+		w.children(n)
+		w.lineBreak(breaks)
+	}
+}
+
+// children writes the text of each child of a node
+func (w *textWriter) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.node(c)
+	}
+}
+
+// text writes a text node, collapsing its whitespace unless it's preformatted
+func (w *textWriter) text(s string) {
+	if w.pre > 0 {
+		w.write(s)
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		if s != "" {
+			w.space = true
+		}
+		return
+	}
+	if isSpace(s[0]) {
+		w.space = true
+	}
+	for i, word := range words {
+		if i > 0 {
+			w.space = true
+		}
+		if w.space && w.started && w.breaks == 0 && !w.noSpace {
+			w.out.WriteByte(' ')
+		}
+		w.write(word)
+	}
+	w.space = isSpace(s[len(s)-1])
+}
+
+// write writes text as is
+func (w *textWriter) write(s string) {
+	if s == "" {
+		return
+	}
+	w.out.WriteString(s)
+	w.started = true
+	w.space = false
+	w.noSpace = false
+	if trailing := len(s) - len(strings.TrimRight(s, "\n")); trailing == len(s) {
+		w.breaks += trailing
+	} else {
+		w.breaks = trailing
+	}
+}
+
+// lineBreak ends the current line and adds blank lines until there are n newlines
+func (w *textWriter) lineBreak(n int) {
+	if !w.started || n == 0 {
+		return
+	}
+	for w.breaks < n {
+		w.out.WriteByte('\n')
+		w.breaks++
+	}
+	w.space = false
+}
+
+// link follows a link's text with its URL, unless the text is the URL or the link goes
+// nowhere a reader could follow. A link that showed nothing, not even an image's alt text,
+// is replaced by its URL.
+func (w *textWriter) link(n *html.Node, shown bool) {
+	href := strings.TrimSpace(attr(n, "href"))
+	lower := strings.ToLower(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lower, "javascript:") {
+		return
+	}
+	text := strings.Join(strings.Fields(nodeText(n)), " ")
+	if text == href || "mailto:"+text == href || "tel:"+text == href {
+		return
+	}
+	if !shown {
+		w.text(href)
+		return
+	}
+	w.space = true
+	w.text("(" + href + ")")
+}
+
+// list writes a list, numbering its items if it's ordered
+func (w *textWriter) list(n *html.Node) {
+	breaks := 2
+	if len(w.lists) > 0 {
+		breaks = 1 // Nested in an item
+	}
+	next := 0
+	if n.DataAtom == atom.Ol {
+		next = 1
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			next = start
+		}
+	}
+
+	w.lineBreak(breaks)
+	w.lists = append(w.lists, next)
+	w.children(n)
+	w.lists = w.lists[:len(w.lists)-1]
+	w.lineBreak(breaks)
+}
+
+// item writes a list item on its own line, indented by how deeply its list is nested
+func (w *textWriter) item(n *html.Node) {
+	w.lineBreak(1)
+	marker := "- "
+	if depth := len(w.lists); depth > 0 {
+		marker = strings.Repeat("  ", depth-1) + marker
+		if number := w.lists[depth-1]; number > 0 {
+			marker = strings.Repeat("  ", depth-1) + strconv.Itoa(number) + ". "
+			w.lists[depth-1]++
+		}
+	}
+	w.write(marker)
+	w.noSpace = true
+	w.children(n)
+	w.lineBreak(1)
+}
+
+// quote writes a blockquote with each line prefixed by "> "
+func (w *textWriter) quote(n *html.Node) {
+	var inner textWriter
+	inner.children(n)
+	quoted := inner.String()
+	if quoted == "" {
+		return
+	}
+
+	lines := strings.Split(quoted, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	w.lineBreak(2)
+	w.write(strings.Join(lines, "\n"))
+	w.lineBreak(2)
+}
+
+// String returns the text written, without trailing spaces on each line or more than one
+// blank line in a row
+func (w *textWriter) String() string {
+	lines := strings.Split(w.out.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text := blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimRight(strings.TrimLeft(text, "\n"), "\n") // Keep the indentation of a leading <pre>
+}
+
+// hidden reports whether an element is hidden from readers, like the preview text many
+// emails start with
+func hidden(n *html.Node) bool {
+	if _, ok := attrValue(n, "hidden"); ok {
+		return true
+	}
+	style := strings.ReplaceAll(strings.ToLower(attr(n, "style")), " ", "")
+	return strings.Contains(style, "display:none")
+}
+
+// nodeText returns the text inside a node, as it appears in the markup
+func nodeText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+// attr returns an attribute of an element, or "" if it isn't set
+func attr(n *html.Node, key string) string {
+	value, _ := attrValue(n, key)
+	return value
+}
+
+// attrValue returns an attribute of an element and whether it's set
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+// isSpace reports whether a byte is HTML whitespace
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
@@ -0,0 +1,81 @@
+// Package inbound routes email replies back to the notification they answer. Each email gets
+// a reply-to address carrying its notification ID and a signature, such as
+// reply+550e8400-e29b-41d4-a716-446655440000.3f9a0c7d21b4e8a6@replies.example.com, and the
+// messages that arrive at those addresses are parsed from a provider webhook.
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// macLength is how many bytes of the HMAC go in an address. The local part of an address is
+// limited to 64 characters, which has to fit a UUID and the signature.
+const macLength = 8
+
+// maxLocalPart is the longest local part an address may have
+const maxLocalPart = 64
+
+// addressableID matches notification IDs that can go in an address unquoted
+var addressableID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Addresser issues and recognizes reply-to addresses
+type Addresser struct {
+	secret    []byte
+	localPart string
+	domain    string
+}
+
+// NewAddresser creates an addresser for addresses like <localPart>+<id>.<signature>@<domain>.
+// Changing the secret stops replies to earlier emails being recognized.
+func NewAddresser(secret []byte, localPart, domain string) *Addresser {
+	return &Addresser{secret: secret, localPart: localPart, domain: strings.ToLower(domain)}
+}
+
+// Address returns the reply-to address for a notification. ok is false if the ID can't be put
+// in an address.
+func (a *Addresser) Address(id string) (address string, ok bool) {
+	if !addressableID.MatchString(id) {
+		return "", false
+	}
+	local := a.localPart + "+" + id + "." + a.mac(id)
+	if len(local) > maxLocalPart {
+		return "", false
+	}
+	return local + "@" + a.domain, true
+}
+
+// Match returns the notification ID in one of our reply-to addresses. Addresses in another
+// domain, or whose signature doesn't match, aren't ours.
+func (a *Addresser) Match(address string) (string, bool) {
+	local, domain, ok := strings.Cut(strings.TrimSpace(address), "@")
+	if !ok || !strings.EqualFold(domain, a.domain) {
+		return "", false
+	}
+	// Some mail systems change the case of the local part, so only the ID is case sensitive
+	prefix := a.localPart + "+"
+	if len(local) <= len(prefix) || !strings.EqualFold(local[:len(prefix)], prefix) {
+		return "", false
+	}
+	tag := local[len(prefix):]
+
+	i := strings.LastIndexByte(tag, '.')
+	if i < 0 {
+		return "", false
+	}
+	id, mac := tag[:i], strings.ToLower(tag[i+1:])
+	if !hmac.Equal([]byte(mac), []byte(a.mac(id))) {
+		return "", false
+	}
+	return id, true
+}
+
+// mac returns the hex signature of a notification ID
+func (a *Addresser) mac(id string) string {
+	h := hmac.New(sha256.New, a.secret)
+	h.Write([]byte(id))
+	return hex.EncodeToString(h.Sum(nil)[:macLength])
+}
@@ -0,0 +1,131 @@
+package inbound
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestAddresser tests issuing reply addresses and recognizing them in replies
+func TestAddresser(t *testing.T) {
+	a := NewAddresser([]byte("0123456789abcdef0123456789abcdef"), "reply", "Replies.Example.com")
+
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	address, ok := a.Address(id)
+	if !ok || !strings.HasPrefix(address, "reply+"+id+".") || !strings.HasSuffix(address, "@replies.example.com") {
+		t.Fatalf("Address() = %q, %v", address, ok)
+	}
+	if local, _, _ := strings.Cut(address, "@"); len(local) > maxLocalPart {
+		t.Errorf("Local part is %d characters, over the limit", len(local))
+	}
+
+	if got, ok := a.Match(address); !ok || got != id {
+		t.Errorf("Match() = %q, %v", got, ok)
+	}
+	if got, ok := a.Match(strings.ToUpper(address)); ok {
+		t.Errorf("Expected an upper-cased UUID not to match, got %q", got)
+	}
+	if got, ok := a.Match(strings.Replace(address, "reply+", "REPLY+", 1)); !ok || got != id {
+		t.Errorf("Expected the local part prefix to match in any case, got %q, %v", got, ok)
+	}
+
+	for _, address := range []string{
+		strings.Replace(address, "@replies.", "@other.", 1),
+		strings.Replace(address, id, "550e8400-e29b-41d4-a716-446655440001", 1),
+		"reply+" + id + "@replies.example.com",
+		"support@replies.example.com",
+	} {
+		if got, ok := a.Match(address); ok {
+			t.Errorf("Match(%q) = %q, expected no match", address, got)
+		}
+	}
+	if _, ok := NewAddresser([]byte("rotated-secret-rotated-secret-xx"), "reply", "replies.example.com").Match(address); ok {
+		t.Error("Expected an address signed with another secret not to match")
+	}
+
+	if _, ok := a.Address("has spaces"); ok {
+		t.Error("Expected an ID with spaces not to be addressable")
+	}
+	if _, ok := a.Address(strings.Repeat("x", 60)); ok {
+		t.Error("Expected an ID too long for an address not to be addressable")
+	}
+}
+
+// TestParseMIME tests parsing a raw multipart reply
+func TestParseMIME(t *testing.T) {
+	message := strings.Join([]string{
+		"From: Alice <alice@example.com>",
+		"To: reply+n1.abc@replies.example.com",
+		"Cc: team@example.com",
+		"Subject: =?utf-8?q?Re:_Your_order_=E2=9C=93?=",
+		"Message-ID: <m1@example.com>",
+		"Date: Mon, 02 Mar 2026 10:00:00 +0000",
+		"MIME-Version: 1.0",
+		`Content-Type: multipart/mixed; boundary="outer"`,
+		"",
+		"--outer",
+		`Content-Type: multipart/alternative; boundary="inner"`,
+		"",
+		"--inner",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+		"",
+		"Thanks, that=E2=80=99s great",
+		"--inner",
+		"Content-Type: text/html; charset=utf-8",
+		"Content-Transfer-Encoding: base64",
+		"",
+		"PHA+VGhhbmtzPC9wPg==",
+		"--inner--",
+		"--outer",
+		"Content-Type: application/pdf",
+		"",
+		"%PDF",
+		"--outer--",
+		"",
+	}, "\r\n")
+
+	reply, err := ParseMIME(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("ParseMIME() error = %v", err)
+	}
+	if reply.From != "alice@example.com" || reply.Subject != "Re: Your order ✓" || reply.MessageID != "m1@example.com" {
+		t.Errorf("Unexpected headers: %+v", reply)
+	}
+	if len(reply.To) != 2 || reply.To[0] != "reply+n1.abc@replies.example.com" || reply.To[1] != "team@example.com" {
+		t.Errorf("Unexpected recipients: %v", reply.To)
+	}
+	if reply.Text != "Thanks, that’s great" || reply.HTML != "<p>Thanks</p>" {
+		t.Errorf("Unexpected body: text=%q, html=%q", reply.Text, reply.HTML)
+	}
+	if reply.ReceivedAt.Year() != 2026 {
+		t.Errorf("Unexpected date: %v", reply.ReceivedAt)
+	}
+}
+
+// TestParsePostmark tests parsing a Postmark inbound webhook payload
+func TestParsePostmark(t *testing.T) {
+	reply, err := ParsePostmark([]byte(`{
+		"FromFull": {"Email": "alice@example.com", "Name": "Alice"},
+		"ToFull": [{"Email": "support@example.com"}],
+		"CcFull": [],
+		"OriginalRecipient": "reply+n1.abc@replies.example.com",
+		"Subject": "Re: Your order",
+		"MessageID": "22c74902-a0c1-4511-804f-341342852c90",
+		"TextBody": "Thanks",
+		"HtmlBody": "<p>Thanks</p>",
+		"Date": "Mon, 2 Mar 2026 10:00:00 +0000"
+	}`))
+	if err != nil {
+		t.Fatalf("ParsePostmark() error = %v", err)
+	}
+	if reply.From != "alice@example.com" || reply.Text != "Thanks" || reply.HTML != "<p>Thanks</p>" {
+		t.Errorf("Unexpected reply: %+v", reply)
+	}
+	if len(reply.To) != 2 || reply.To[1] != "reply+n1.abc@replies.example.com" {
+		t.Errorf("Expected the original recipient among the addresses, got %v", reply.To)
+	}
+
+	if !IsMIME("message/rfc822", nil) || IsMIME("application/json", []byte("From: a")) || !IsMIME("", []byte("From: a")) {
+		t.Error("Unexpected IsMIME result")
+	}
+}
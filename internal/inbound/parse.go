@@ -0,0 +1,171 @@
+package inbound
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// maxPartDepth bounds how deeply nested multipart bodies are searched for text
+const maxPartDepth = 5
+
+// postmarkInbound is the subset of Postmark's inbound webhook payload we use
+type postmarkInbound struct {
+	FromFull          postmarkAddress   `json:"FromFull"`
+	ToFull            []postmarkAddress `json:"ToFull"`
+	CcFull            []postmarkAddress `json:"CcFull"`
+	OriginalRecipient string            `json:"OriginalRecipient"`
+	Subject           string            `json:"Subject"`
+	MessageID         string            `json:"MessageID"`
+	TextBody          string            `json:"TextBody"`
+	HtmlBody          string            `json:"HtmlBody"`
+	Date              string            `json:"Date"`
+}
+
+// postmarkAddress is an address in a Postmark inbound payload
+type postmarkAddress struct {
+	Email string `json:"Email"`
+}
+
+// ParsePostmark parses a Postmark inbound webhook payload
+func ParsePostmark(body []byte) (*domain.InboundReply, error) {
+	var payload postmarkInbound
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid Postmark inbound payload: %w", err)
+	}
+
+	reply := &domain.InboundReply{
+		From:       payload.FromFull.Email,
+		Subject:    payload.Subject,
+		Text:       payload.TextBody,
+		HTML:       payload.HtmlBody,
+		MessageID:  payload.MessageID,
+		ReceivedAt: parseDate(payload.Date),
+	}
+	for _, addresses := range [][]postmarkAddress{payload.ToFull, payload.CcFull} {
+		for _, address := range addresses {
+			reply.To = append(reply.To, address.Email)
+		}
+	}
+	if payload.OriginalRecipient != "" {
+		reply.To = append(reply.To, payload.OriginalRecipient)
+	}
+	return reply, nil
+}
+
+// ParseMIME parses a raw RFC 5322 message, as forwarded by a mail server pipe or a provider
+// that posts the original message
+func ParseMIME(r io.Reader) (*domain.InboundReply, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email message: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+	reply := &domain.InboundReply{
+		Subject:    subject,
+		MessageID:  strings.Trim(msg.Header.Get("Message-Id"), "<>"),
+		ReceivedAt: parseDate(msg.Header.Get("Date")),
+	}
+	if from, err := mail.ParseAddress(msg.Header.Get("From")); err == nil {
+		reply.From = from.Address
+	}
+	for _, header := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		addresses, err := msg.Header.AddressList(header)
+		if err != nil {
+			continue
+		}
+		for _, address := range addresses {
+			reply.To = append(reply.To, address.Address)
+		}
+	}
+
+	if err := readParts(reply, msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, 0); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// readParts fills in the reply's text and HTML from the first part of each type
+func readParts(reply *domain.InboundReply, contentType, encoding string, body io.Reader, depth int) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain" // The default for a message without a Content-Type
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		if depth >= maxPartDepth {
+			return nil
+		}
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("invalid multipart body: %w", err)
+			}
+			if err := readParts(reply, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	var target *string
+	switch mediaType {
+	case "text/plain":
+		target = &reply.Text
+	case "text/html":
+		target = &reply.HTML
+	default:
+		return nil // Attachments and other parts aren't passed on
+	}
+	if *target != "" {
+		return nil
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s part: %w", mediaType, err)
+	}
+	*target = string(content)
+	return nil
+}
+
+// parseDate parses a message date, falling back to now
+func parseDate(value string) time.Time {
+	if date, err := mail.ParseDate(value); err == nil {
+		return date
+	}
+	return time.Now()
+}
+
+// IsMIME reports whether a webhook body is a raw message rather than a provider's JSON
+func IsMIME(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "message/rfc822" {
+		return true
+	}
+	return mediaType != "application/json" && !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+}
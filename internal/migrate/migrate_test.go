@@ -0,0 +1,82 @@
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+// TestLoad tests parsing and ordering of migration files
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/0002_add_index.up.sql":      {Data: []byte("CREATE INDEX")},
+		"migrations/0001_create_table.up.sql":   {Data: []byte("CREATE TABLE")},
+		"migrations/0001_create_table.down.sql": {Data: []byte("DROP TABLE")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(migrations) != 2 {
+		t.Fatalf("Expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "create_table" || migrations[0].Down != "DROP TABLE" {
+		t.Errorf("Unexpected first migration: %+v", migrations[0])
+	}
+	if migrations[1].Version != 2 || migrations[1].Up != "CREATE INDEX" || migrations[1].Down != "" {
+		t.Errorf("Unexpected second migration: %+v", migrations[1])
+	}
+}
+
+// TestLoadInvalid tests malformed migration sets are rejected
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{
+			name:  "missing direction",
+			files: fstest.MapFS{"migrations/0001_create.sql": {}},
+		},
+		{
+			name:  "missing version",
+			files: fstest.MapFS{"migrations/create.up.sql": {}},
+		},
+		{
+			name:  "down without up",
+			files: fstest.MapFS{"migrations/0001_create.down.sql": {Data: []byte("DROP")}},
+		},
+		{
+			name: "conflicting names",
+			files: fstest.MapFS{
+				"migrations/0001_create.up.sql": {Data: []byte("CREATE")},
+				"migrations/0001_other.up.sql":  {Data: []byte("CREATE")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.files); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations tests the migrations compiled into the binary are valid
+func TestEmbeddedMigrations(t *testing.T) {
+	migrations, err := Load(embeddedMigrations)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != int64(i+1) {
+			t.Errorf("Expected sequential versions, got %d at position %d", migration.Version, i)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+-- API Keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+	id SERIAL PRIMARY KEY,
+	key VARCHAR(255) UNIQUE NOT NULL,
+	name VARCHAR(255) NOT NULL,
+	client_id VARCHAR(255) NOT NULL,
+	roles TEXT[] DEFAULT '{}',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_used_at TIMESTAMP,
+	expires_at TIMESTAMP,
+	is_active BOOLEAN NOT NULL DEFAULT true,
+	rate_limit INTEGER NOT NULL DEFAULT 0,
+	created_by VARCHAR(255),
+	metadata JSONB DEFAULT '{}'::jsonb
+);
+
+-- Audit log for key operations
+CREATE TABLE IF NOT EXISTS api_key_audit_log (
+	id SERIAL PRIMARY KEY,
+	key_id INTEGER NOT NULL REFERENCES api_keys(id),
+	action VARCHAR(50) NOT NULL,
+	performed_by VARCHAR(255) NOT NULL,
+	performed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	details JSONB DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_key ON api_keys(key);
+CREATE INDEX IF NOT EXISTS idx_api_keys_client_id ON api_keys(client_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_active ON api_keys(is_active);
+CREATE INDEX IF NOT EXISTS idx_api_keys_expires ON api_keys(expires_at);
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_log_key_id ON api_key_audit_log(key_id);
+CREATE INDEX IF NOT EXISTS idx_api_key_audit_log_performed_at ON api_key_audit_log(performed_at);
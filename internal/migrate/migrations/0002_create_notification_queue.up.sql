@@ -0,0 +1,12 @@
+-- Durable notification queue. Consumers claim the oldest unleased message with
+-- SELECT ... FOR UPDATE SKIP LOCKED, so several server instances can share it.
+CREATE TABLE IF NOT EXISTS notification_queue (
+	id BIGSERIAL PRIMARY KEY,
+	message_id VARCHAR(64) UNIQUE NOT NULL,
+	notification JSONB NOT NULL,
+	attempt INTEGER NOT NULL DEFAULT 0,
+	enqueued_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	leased_until TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_queue_leased_until ON notification_queue(leased_until);
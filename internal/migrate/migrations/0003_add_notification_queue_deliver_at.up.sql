@@ -0,0 +1,2 @@
+-- Delayed messages can't be claimed before deliver_at
+ALTER TABLE notification_queue ADD COLUMN IF NOT EXISTS deliver_at TIMESTAMPTZ;
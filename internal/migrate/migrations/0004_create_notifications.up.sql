@@ -0,0 +1,21 @@
+-- Notification store. The full notification is kept as JSON; the columns NotificationFilter
+-- queries on are copied out of it so listings can use indexes.
+CREATE TABLE IF NOT EXISTS notifications (
+	id BIGSERIAL PRIMARY KEY,
+	notification_id VARCHAR(255) UNIQUE NOT NULL,
+	type VARCHAR(64) NOT NULL,
+	status VARCHAR(32) NOT NULL,
+	recipients TEXT[] NOT NULL DEFAULT '{}',
+	origin_system VARCHAR(255) NOT NULL DEFAULT '',
+	origin_user VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	notification JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_status ON notifications(status);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications(type);
+CREATE INDEX IF NOT EXISTS idx_notifications_origin_system ON notifications(origin_system);
+CREATE INDEX IF NOT EXISTS idx_notifications_origin_user ON notifications(origin_user);
+CREATE INDEX IF NOT EXISTS idx_notifications_recipients ON notifications USING GIN (recipients);
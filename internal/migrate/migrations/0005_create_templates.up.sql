@@ -0,0 +1,10 @@
+-- Templates created through the API. The full template is kept as JSON; type is copied out
+-- of it so templates can be listed by channel.
+CREATE TABLE IF NOT EXISTS templates (
+	name VARCHAR(255) PRIMARY KEY,
+	type VARCHAR(64) NOT NULL DEFAULT '',
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	template JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_templates_type ON templates(type);
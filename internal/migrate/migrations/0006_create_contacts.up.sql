@@ -0,0 +1,7 @@
+-- Contacts and groups created through the API. The full contact is kept as JSON, as contacts
+-- are only ever looked up by name.
+CREATE TABLE IF NOT EXISTS contacts (
+	name VARCHAR(255) PRIMARY KEY,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	contact JSONB NOT NULL
+);
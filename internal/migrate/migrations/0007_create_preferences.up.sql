@@ -0,0 +1,6 @@
+-- Contacts' notification preferences, kept as JSON and looked up by contact name
+CREATE TABLE IF NOT EXISTS preferences (
+	contact VARCHAR(255) PRIMARY KEY,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	preferences JSONB NOT NULL
+);
@@ -0,0 +1,2 @@
+-- Tag filters match the notification's tags by containment
+CREATE INDEX IF NOT EXISTS idx_notifications_tags ON notifications USING GIN ((notification->'tags'));
@@ -0,0 +1,2 @@
+-- Listing a fanned-out notification's targets matches on their fanout_id
+CREATE INDEX IF NOT EXISTS idx_notifications_fanout_id ON notifications ((notification->>'fanout_id'));
@@ -0,0 +1,2 @@
+-- Every listing by a tenant's clients is limited to that tenant's notifications
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant ON notifications ((notification->>'tenant'), created_at);
@@ -0,0 +1,16 @@
+-- Tenants' own templates, contacts and preferences can't share the untenanted names, so
+-- they're dropped
+DELETE FROM preferences WHERE tenant <> '';
+ALTER TABLE preferences DROP CONSTRAINT IF EXISTS preferences_pkey;
+ALTER TABLE preferences DROP COLUMN IF EXISTS tenant;
+ALTER TABLE preferences ADD PRIMARY KEY (contact);
+
+DELETE FROM contacts WHERE tenant <> '';
+ALTER TABLE contacts DROP CONSTRAINT IF EXISTS contacts_pkey;
+ALTER TABLE contacts DROP COLUMN IF EXISTS tenant;
+ALTER TABLE contacts ADD PRIMARY KEY (name);
+
+DELETE FROM templates WHERE tenant <> '';
+ALTER TABLE templates DROP CONSTRAINT IF EXISTS templates_pkey;
+ALTER TABLE templates DROP COLUMN IF EXISTS tenant;
+ALTER TABLE templates ADD PRIMARY KEY (name);
@@ -0,0 +1,13 @@
+-- Each tenant has its own templates, contacts and preferences, so names are only unique within
+-- a tenant. Rows created before tenancy, or without a tenant, have an empty one.
+ALTER TABLE templates ADD COLUMN IF NOT EXISTS tenant VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE templates DROP CONSTRAINT IF EXISTS templates_pkey;
+ALTER TABLE templates ADD PRIMARY KEY (tenant, name);
+
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS tenant VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE contacts DROP CONSTRAINT IF EXISTS contacts_pkey;
+ALTER TABLE contacts ADD PRIMARY KEY (tenant, name);
+
+ALTER TABLE preferences ADD COLUMN IF NOT EXISTS tenant VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE preferences DROP CONSTRAINT IF EXISTS preferences_pkey;
+ALTER TABLE preferences ADD PRIMARY KEY (tenant, contact);
@@ -0,0 +1,365 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AMQPConfig contains AMQP 0.9.1 (RabbitMQ) publisher configuration
+type AMQPConfig struct {
+	URL            string        `mapstructure:"url"`             // Broker URL (amqp:// or amqps://, with credentials and vhost)
+	Exchange       string        `mapstructure:"exchange"`        // Exchange to publish to (default: the default exchange, where routing keys are queue names)
+	Mandatory      bool          `mapstructure:"mandatory"`       // Fail when a message can't be routed to any queue
+	Transient      bool          `mapstructure:"transient"`       // Publish non-persistent messages (default: persistent)
+	Format         string        `mapstructure:"format"`          // Message body: "json" (default, the whole notification) or "text" (the body only)
+	ConfirmTimeout int           `mapstructure:"confirm_timeout"` // Seconds to wait for publisher confirms (default: 10)
+	CACertPath     string        `mapstructure:"ca_cert_path"`    // Custom CA certificate (PEM) for amqps:// brokers
+	Default        bool          `mapstructure:"default"`         // Mark this instance as default
+	AllowedRoles   []string      `mapstructure:"allowed_roles"`   // Roles allowed to use this notifier (empty = all authenticated)
+	Display        DisplayConfig `mapstructure:"display"`         // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// AMQPNotifier publishes notifications to an AMQP exchange, one message per routing key
+// (recipient). Every publish is confirmed by the broker before the notification counts as
+// sent. The connection is opened on first use, re-established if the broker drops it, and
+// closed by Close.
+type AMQPNotifier struct {
+	BaseNotifier
+	config         *AMQPConfig
+	tlsConfig      *tls.Config
+	confirmTimeout time.Duration
+
+	// dial opens a connection and a confirm-mode channel; tests replace it with a fake broker
+	dial func(ctx context.Context) (io.Closer, amqpChannel, error)
+
+	mu      sync.Mutex
+	session *amqpSession
+	closed  bool
+}
+
+// amqpChannel is the part of *amqp.Channel the notifier uses
+type amqpChannel interface {
+	Confirm(noWait bool) error
+	NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation
+	NotifyReturn(returns chan amqp.Return) chan amqp.Return
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	IsClosed() bool
+	Close() error
+}
+
+// amqpSession is an open connection with its publishing channel
+type amqpSession struct {
+	conn     io.Closer
+	channel  amqpChannel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+}
+
+// amqpMessage is the JSON message body
+type amqpMessage struct {
+	ID        string                 `json:"id"`
+	Subject   string                 `json:"subject,omitempty"`
+	Body      string                 `json:"body"`
+	Priority  domain.Priority        `json:"priority"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// amqpConfirmBuffer bounds the confirmations a send can have outstanding
+const amqpConfirmBuffer = 256
+
+// NewAMQPNotifier creates a new AMQP notifier
+func NewAMQPNotifier(config *AMQPConfig) (*AMQPNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("AMQP config is required")
+	}
+
+	if config.URL == "" {
+		return nil, fmt.Errorf("AMQP url is required")
+	}
+
+	uri, err := amqp.ParseURI(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AMQP url: %w", err)
+	}
+
+	switch config.Format {
+	case "":
+		config.Format = "json"
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("invalid AMQP format: %s (must be json or text)", config.Format)
+	}
+
+	var tlsConfig *tls.Config
+	if uri.Scheme == "amqps" {
+		tlsConfig = &tls.Config{
+			ServerName: uri.Host,
+			MinVersion: tls.VersionTLS12,
+		}
+		if config.CACertPath != "" {
+			certData, err := os.ReadFile(config.CACertPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read custom CA certificate: %w", err)
+			}
+			certPool := x509.NewCertPool()
+			if !certPool.AppendCertsFromPEM(certData) {
+				return nil, fmt.Errorf("failed to parse custom CA certificate as PEM")
+			}
+			tlsConfig.RootCAs = certPool
+		}
+	}
+
+	confirmTimeout := 10 * time.Second
+	if config.ConfirmTimeout > 0 {
+		confirmTimeout = time.Duration(config.ConfirmTimeout) * time.Second
+	}
+
+	base, err := newBaseNotifier(domain.TypeAMQP, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &AMQPNotifier{
+		BaseNotifier:   base,
+		config:         config,
+		tlsConfig:      tlsConfig,
+		confirmTimeout: confirmTimeout,
+	}
+	a.dial = a.dialBroker
+	return a, nil
+}
+
+// Send publishes the notification once per recipient, using the recipient as the routing key.
+// Metadata "routing_key_prefix" is prepended to every routing key.
+func (a *AMQPNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := a.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = a.withSentFooter(notification)
+
+	msg, err := a.buildPublishing(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, _ := notification.Metadata["routing_key_prefix"].(string)
+	keys := make([]string, len(notification.Recipients))
+	for i, recipient := range notification.Recipients {
+		keys[i] = prefix + recipient
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		session, reused, err := a.connect(ctx)
+		if err == nil {
+			var published int
+			published, err = a.publish(ctx, session, keys, msg)
+			if err == nil {
+				break
+			}
+			a.dropSession()
+
+			// A connection that was already open may have gone stale, so retry once on a new
+			// one, but only if nothing reached the broker
+			if published == 0 && reused && attempt == 0 && ctx.Err() == nil {
+				continue
+			}
+		}
+
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("AMQP message published to %d routing keys", len(keys)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"exchange":     a.config.Exchange,
+			"routing_keys": keys,
+			"persistent":   !a.config.Transient,
+		},
+	}, nil
+}
+
+// buildPublishing creates the message published for every routing key
+func (a *AMQPNotifier) buildPublishing(notification *domain.Notification) (amqp.Publishing, error) {
+	msg := amqp.Publishing{
+		MessageId:    notification.ID,
+		Timestamp:    time.Now(),
+		DeliveryMode: amqp.Persistent,
+		Priority:     uint8(notification.Priority) * 3, // 0-9 scale used by priority queues
+		Headers: amqp.Table{
+			"notification_id": notification.ID,
+			"priority":        int32(notification.Priority),
+		},
+	}
+	if a.config.Transient {
+		msg.DeliveryMode = amqp.Transient
+	}
+	if notification.Subject != "" {
+		msg.Headers["subject"] = notification.Subject
+	}
+
+	if a.config.Format == "text" {
+		msg.ContentType = "text/plain"
+		msg.Body = []byte(notification.Body)
+		return msg, nil
+	}
+
+	body, err := json.Marshal(amqpMessage{
+		ID:        notification.ID,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		Priority:  notification.Priority,
+		Metadata:  notification.Metadata,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to marshal AMQP message: %w", err)
+	}
+	msg.ContentType = "application/json"
+	msg.Body = body
+	return msg, nil
+}
+
+// publish sends the message to every routing key and waits for the broker to confirm them.
+// It reports how many messages were handed to the broker. Must be called with mu held.
+func (a *AMQPNotifier) publish(ctx context.Context, session *amqpSession, keys []string, msg amqp.Publishing) (int, error) {
+	if len(keys) > amqpConfirmBuffer {
+		return 0, fmt.Errorf("too many AMQP routing keys: %d (max %d)", len(keys), amqpConfirmBuffer)
+	}
+
+	for i, key := range keys {
+		if err := session.channel.PublishWithContext(ctx, a.config.Exchange, key, a.config.Mandatory, false, msg); err != nil {
+			return i, fmt.Errorf("failed to publish AMQP message to %q: %w", key, err)
+		}
+	}
+
+	// Confirms arrive in publish order. The broker sends basic.return before the ack for an
+	// unroutable mandatory message, so returns are queued by the time every ack is in.
+	timer := time.NewTimer(a.confirmTimeout)
+	defer timer.Stop()
+	nacked := 0
+	for range keys {
+		select {
+		case confirm, ok := <-session.confirms:
+			if !ok {
+				return len(keys), fmt.Errorf("AMQP channel closed before publishes were confirmed")
+			}
+			if !confirm.Ack {
+				nacked++
+			}
+		case <-timer.C:
+			return len(keys), fmt.Errorf("AMQP publish not confirmed within %s", a.confirmTimeout)
+		case <-ctx.Done():
+			return len(keys), ctx.Err()
+		}
+	}
+
+	var unroutable []string
+	for len(session.returns) > 0 {
+		ret := <-session.returns
+		unroutable = append(unroutable, ret.RoutingKey)
+	}
+
+	switch {
+	case nacked > 0:
+		return len(keys), fmt.Errorf("AMQP broker rejected %d of %d messages", nacked, len(keys))
+	case len(unroutable) > 0:
+		return len(keys), fmt.Errorf("AMQP message could not be routed for routing keys: %s", strings.Join(unroutable, ", "))
+	}
+	return len(keys), nil
+}
+
+// connect returns the open session, opening one if needed, and reports whether it was reused.
+// Must be called with mu held.
+func (a *AMQPNotifier) connect(ctx context.Context) (*amqpSession, bool, error) {
+	if a.closed {
+		return nil, false, fmt.Errorf("AMQP notifier is closed")
+	}
+	if a.session != nil && !a.session.channel.IsClosed() {
+		return a.session, true, nil
+	}
+	a.dropSession()
+
+	conn, channel, err := a.dial(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to enable AMQP publisher confirms: %w", err)
+	}
+
+	a.session = &amqpSession{
+		conn:     conn,
+		channel:  channel,
+		confirms: channel.NotifyPublish(make(chan amqp.Confirmation, amqpConfirmBuffer)),
+		returns:  channel.NotifyReturn(make(chan amqp.Return, amqpConfirmBuffer)),
+	}
+	return a.session, false, nil
+}
+
+// dialBroker connects to the configured broker and opens a channel
+func (a *AMQPNotifier) dialBroker(ctx context.Context) (io.Closer, amqpChannel, error) {
+	conn, err := amqp.DialConfig(a.config.URL, amqp.Config{
+		TLSClientConfig: a.tlsConfig,
+		Properties:      amqp.Table{"connection_name": "notifier"},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to AMQP broker: %w", err)
+	}
+
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("failed to open AMQP channel: %w", err)
+	}
+	return conn, channel, nil
+}
+
+// dropSession closes the current session, if any. Must be called with mu held.
+func (a *AMQPNotifier) dropSession() {
+	if a.session == nil {
+		return
+	}
+	a.session.channel.Close()
+	a.session.conn.Close()
+	a.session = nil
+}
+
+// Close closes the broker connection
+func (a *AMQPNotifier) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.closed = true
+	a.dropSession()
+	return nil
+}
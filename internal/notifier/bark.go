@@ -0,0 +1,227 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// BarkConfig contains Bark (iOS push) configuration
+type BarkConfig struct {
+	ServerURL    string        `mapstructure:"server_url"`    // Bark server URL (default: https://api.day.app)
+	Sound        string        `mapstructure:"sound"`         // Default notification sound (e.g., "minuet")
+	Group        string        `mapstructure:"group"`         // Default group notifications are filed under on the device
+	Icon         string        `mapstructure:"icon"`          // Default icon URL (iOS 15+)
+	Default      bool          `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string      `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+
+	// Encryption seals pushes to per-device public keys so the Bark server can't read them (optional)
+	Encryption *PushEncryptionConfig `mapstructure:"encryption"`
+}
+
+// BarkNotifier sends push notifications to iOS devices through a Bark server, such as a
+// self-hosted bark-server. Recipients are device keys.
+type BarkNotifier struct {
+	BaseNotifier
+	config     *BarkConfig
+	httpClient *http.Client
+	encryption *pushEncryption
+}
+
+// barkRequest represents the Bark push API request format
+type barkRequest struct {
+	DeviceKey string `json:"device_key"`
+	Title     string `json:"title,omitempty"`
+	Body      string `json:"body"`
+	Level     string `json:"level,omitempty"`
+	Sound     string `json:"sound,omitempty"`
+	Group     string `json:"group,omitempty"`
+	Icon      string `json:"icon,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// barkResponse represents the Bark push API response format
+type barkResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewBarkNotifier creates a new Bark notifier
+func NewBarkNotifier(config *BarkConfig) (*BarkNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Bark config is required")
+	}
+
+	if config.ServerURL == "" {
+		config.ServerURL = "https://api.day.app" // Default public Bark server
+	}
+	if !strings.HasPrefix(config.ServerURL, "http://") && !strings.HasPrefix(config.ServerURL, "https://") {
+		return nil, fmt.Errorf("invalid Bark server URL: %q (must start with http:// or https://)", config.ServerURL)
+	}
+
+	base, err := newBaseNotifier(domain.TypeBark, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := newPushEncryption("Bark", config.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BarkNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		encryption: encryption,
+	}, nil
+}
+
+// Send pushes a notification to each device key among the recipients
+func (b *BarkNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := b.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = b.withSentFooter(notification)
+
+	encrypted := 0
+	for _, deviceKey := range notification.Recipients {
+		req := b.buildRequest(deviceKey, notification)
+		title, body, sealed, err := b.encryption.seal(notification, deviceKey, "", req.Title, req.Body)
+		if err == nil {
+			req.Title, req.Body = title, body
+			err = b.push(ctx, req)
+		}
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		if sealed {
+			encrypted++
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Bark notification sent to %d devices", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"server":            b.config.ServerURL,
+			"devices":           len(notification.Recipients),
+			"encrypted_devices": encrypted,
+		},
+	}, nil
+}
+
+// buildRequest constructs the push for one device. The metadata keys sound, group, icon and
+// url override the account's defaults.
+func (b *BarkNotifier) buildRequest(deviceKey string, notification *domain.Notification) *barkRequest {
+	req := &barkRequest{
+		DeviceKey: deviceKey,
+		Title:     notification.Subject,
+		Body:      notification.Body,
+		Level:     barkPriorityLevel(notification.Priority),
+		Sound:     b.config.Sound,
+		Group:     b.config.Group,
+		Icon:      b.config.Icon,
+	}
+
+	if notification.Metadata != nil {
+		if sound, ok := notification.Metadata["sound"].(string); ok {
+			req.Sound = sound
+		}
+		if group, ok := notification.Metadata["group"].(string); ok {
+			req.Group = group
+		}
+		if icon, ok := notification.Metadata["icon"].(string); ok {
+			req.Icon = icon
+		}
+		if url, ok := notification.Metadata["url"].(string); ok {
+			req.URL = url
+		}
+	}
+
+	return req
+}
+
+// barkPriorityLevel returns the iOS interruption level for a priority. Critical alerts play a
+// sound even when the device is muted or in Do Not Disturb.
+func barkPriorityLevel(priority domain.Priority) string {
+	switch priority {
+	case domain.PriorityLow:
+		return "passive"
+	case domain.PriorityHigh:
+		return "timeSensitive"
+	case domain.PriorityCritical:
+		return "critical"
+	default:
+		return "active"
+	}
+}
+
+// push posts a request to the Bark server
+func (b *BarkNotifier) push(ctx context.Context, push *barkRequest) error {
+	jsonData, err := json.Marshal(push)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Bark request: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(b.config.ServerURL, "/") + "/push"
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Bark notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Bark reports failures such as an unknown device key in the body as well as the status
+	var result barkResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil && result.Message != "" {
+			return newStatusCodeError(resp.StatusCode, "Bark server returned status %d: %s", resp.StatusCode, result.Message)
+		}
+		return newStatusCodeError(resp.StatusCode, "Bark server returned status: %d", resp.StatusCode)
+	}
+	if decodeErr == nil && result.Code != 0 && result.Code != http.StatusOK {
+		return fmt.Errorf("Bark server rejected the push (code %d): %s", result.Code, result.Message)
+	}
+
+	return nil
+}
+
+// MetadataKeys returns the metadata keys the Bark notifier reads
+func (b *BarkNotifier) MetadataKeys() []string {
+	return []string{"sound", "group", "icon", "url"}
+}
+
+// Close closes the HTTP client
+func (b *BarkNotifier) Close() error {
+	b.httpClient.CloseIdleConnections()
+	return nil
+}
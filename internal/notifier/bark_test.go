@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/pkg/e2e"
+)
+
+// TestBarkSend tests that a push is sent to each device key with metadata overriding defaults
+func TestBarkSend(t *testing.T) {
+	var received []barkRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/push" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		var req barkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		received = append(received, req)
+		json.NewEncoder(w).Encode(barkResponse{Code: 200, Message: "success"})
+	}))
+	defer server.Close()
+
+	bark, err := NewBarkNotifier(&BarkConfig{ServerURL: server.URL + "/", Sound: "minuet", Group: "alerts"})
+	if err != nil {
+		t.Fatalf("Failed to create Bark notifier: %v", err)
+	}
+
+	result, err := bark.Send(context.Background(), &domain.Notification{
+		ID:         "bark-1",
+		Type:       domain.TypeBark,
+		Subject:    "Disk full",
+		Body:       "/var is at 98%",
+		Priority:   domain.PriorityCritical,
+		Recipients: []string{"key-a", "key-b"},
+		Metadata:   map[string]interface{}{"group": "storage", "url": "https://grafana.example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if len(received) != 2 || received[0].DeviceKey != "key-a" || received[1].DeviceKey != "key-b" {
+		t.Fatalf("Expected a push per device key, got %+v", received)
+	}
+	push := received[0]
+	if push.Title != "Disk full" || push.Body != "/var is at 98%" || push.Level != "critical" {
+		t.Errorf("Unexpected push content: %+v", push)
+	}
+	if push.Sound != "minuet" || push.Group != "storage" || push.URL != "https://grafana.example.com" {
+		t.Errorf("Expected metadata to override defaults: %+v", push)
+	}
+}
+
+// TestBarkEncryptedPush tests that pushes to devices with a public key are sealed, and that
+// requiring encryption refuses devices without one
+func TestBarkEncryptedPush(t *testing.T) {
+	publicKey, privateKey, err := e2e.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	var received []barkRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req barkRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		received = append(received, req)
+		json.NewEncoder(w).Encode(barkResponse{Code: 200, Message: "success"})
+	}))
+	defer server.Close()
+
+	// Config map keys arrive lowercased, so devices are matched case-insensitively
+	config := &BarkConfig{
+		ServerURL:  server.URL,
+		Encryption: &PushEncryptionConfig{DeviceKeys: map[string]string{"key-a": publicKey}},
+	}
+	bark, err := NewBarkNotifier(config)
+	if err != nil {
+		t.Fatalf("Failed to create Bark notifier: %v", err)
+	}
+
+	notification := &domain.Notification{
+		ID:         "bark-e2e",
+		Type:       domain.TypeBark,
+		Subject:    "Login from new device",
+		Body:       "Code 481516",
+		Recipients: []string{"Key-A", "key-b"},
+	}
+	result, err := bark.Send(context.Background(), notification)
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 pushes, got %d", len(received))
+	}
+	sealed := received[0]
+	if strings.Contains(sealed.Body, "481516") || sealed.Title != "Encrypted notification" {
+		t.Errorf("Expected the push to Key-A to hide its content, got %+v", sealed)
+	}
+	key, _ := e2e.ParsePrivateKey(privateKey)
+	payload, err := e2e.OpenPayload(key, sealed.Body)
+	if err != nil {
+		t.Fatalf("Device failed to decrypt: %v", err)
+	}
+	if payload.Title != "Login from new device" || payload.Message != "Code 481516" {
+		t.Errorf("Decrypted payload = %+v", payload)
+	}
+	if received[1].Body != "Code 481516" {
+		t.Errorf("Expected plaintext for a device without a key, got %q", received[1].Body)
+	}
+	if result.ProviderResponse["encrypted_devices"] != 1 {
+		t.Errorf("encrypted_devices = %v", result.ProviderResponse["encrypted_devices"])
+	}
+
+	config.Encryption.Require = true
+	received = nil
+	if _, err := bark.Send(context.Background(), notification); err == nil {
+		t.Error("Expected a required key to refuse key-b")
+	}
+	if len(received) != 1 {
+		t.Errorf("Expected only Key-A to be pushed, got %d pushes", len(received))
+	}
+}
+
+// TestBarkSendRejected tests that an error reported by the server fails the send
+func TestBarkSendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(barkResponse{Code: 400, Message: "failed to get device token: device not found"})
+	}))
+	defer server.Close()
+
+	bark, err := NewBarkNotifier(&BarkConfig{ServerURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Bark notifier: %v", err)
+	}
+
+	result, err := bark.Send(context.Background(), &domain.Notification{
+		ID:         "bark-2",
+		Type:       domain.TypeBark,
+		Body:       "hello",
+		Recipients: []string{"unknown"},
+	})
+	if err == nil || result.Success {
+		t.Fatal("Expected the send to fail")
+	}
+	if !strings.Contains(err.Error(), "device not found") {
+		t.Errorf("Expected the server's message in the error, got %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// DingTalkConfig contains DingTalk custom robot configuration
+type DingTalkConfig struct {
+	WebhookURL   string            `mapstructure:"webhook_url"`   // Robot webhook (https://oapi.dingtalk.com/robot/send?access_token=...)
+	Secret       string            `mapstructure:"secret"`        // Signing secret ("SEC..."), if the robot's security setting uses signing
+	Webhooks     map[string]string `mapstructure:"webhooks"`      // Group-specific robot webhooks
+	Secrets      map[string]string `mapstructure:"secrets"`       // Signing secrets of group-specific robots
+	Default      bool              `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string          `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig     `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// DingTalkNotifier posts notifications to DingTalk groups through custom robots
+type DingTalkNotifier struct {
+	BaseNotifier
+	config     *DingTalkConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// dingTalkMessage represents the DingTalk robot markdown message format
+type dingTalkMessage struct {
+	MsgType  string           `json:"msgtype"`
+	Markdown dingTalkMarkdown `json:"markdown"`
+	At       *dingTalkAt      `json:"at,omitempty"`
+}
+
+// dingTalkMarkdown is the content of a markdown message
+type dingTalkMarkdown struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// dingTalkAt lists the group members a message mentions
+type dingTalkAt struct {
+	AtMobiles []string `json:"atMobiles,omitempty"`
+	AtUserIDs []string `json:"atUserIds,omitempty"`
+	IsAtAll   bool     `json:"isAtAll,omitempty"`
+}
+
+// dingTalkResponse represents the DingTalk robot response
+type dingTalkResponse struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// NewDingTalkNotifier creates a new DingTalk notifier
+func NewDingTalkNotifier(config *DingTalkConfig) (*DingTalkNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("DingTalk config is required")
+	}
+
+	if config.WebhookURL == "" && len(config.Webhooks) == 0 {
+		return nil, fmt.Errorf("DingTalk webhook URL or group webhooks are required")
+	}
+
+	for group := range config.Secrets {
+		if _, ok := config.Webhooks[group]; !ok {
+			return nil, fmt.Errorf("DingTalk secret given for group without a webhook: %s", group)
+		}
+	}
+
+	base, err := newBaseNotifier(domain.TypeDingTalk, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DingTalkNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Send posts a notification to each group among the recipients
+func (d *DingTalkNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := d.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = d.withSentFooter(notification)
+
+	msg := buildDingTalkMessage(notification)
+	for _, group := range notification.Recipients {
+		webhookURL, secret := d.getWebhook(group)
+		if webhookURL == "" {
+			err := fmt.Errorf("no DingTalk webhook configured for group: %s", group)
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+
+		if err := d.sendToDingTalk(ctx, webhookURL, secret, msg); err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("DingTalk notification sent to %d groups", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"groups": notification.Recipients,
+		},
+	}, nil
+}
+
+// buildDingTalkMessage constructs a markdown message. Metadata "at_mobiles" and "at_user_ids"
+// mention group members and "at_all" mentions everyone. DingTalk only notifies members
+// mentioned in the text as well, so the mentions are appended to it.
+func buildDingTalkMessage(notification *domain.Notification) *dingTalkMessage {
+	title := notification.Subject
+	if title == "" {
+		title = truncateRunes(notification.Body, 64)
+	}
+
+	text := notification.Body
+	if notification.Subject != "" {
+		text = "### " + notification.Subject + "\n\n" + text
+	}
+
+	at := &dingTalkAt{
+		AtMobiles: metadataStrings(notification.Metadata["at_mobiles"]),
+		AtUserIDs: metadataStrings(notification.Metadata["at_user_ids"]),
+	}
+	at.IsAtAll, _ = notification.Metadata["at_all"].(bool)
+
+	var mentions []string
+	for _, mobile := range at.AtMobiles {
+		mentions = append(mentions, "@"+mobile)
+	}
+	for _, userID := range at.AtUserIDs {
+		mentions = append(mentions, "@"+userID)
+	}
+	if len(mentions) > 0 {
+		text += "\n\n" + strings.Join(mentions, " ")
+	}
+	if len(mentions) == 0 && !at.IsAtAll {
+		at = nil
+	}
+
+	return &dingTalkMessage{
+		MsgType:  "markdown",
+		Markdown: dingTalkMarkdown{Title: title, Text: text},
+		At:       at,
+	}
+}
+
+// metadataStrings reads a metadata list of strings, which JSON decodes as []interface{}
+func metadataStrings(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		strs := make([]string, 0, len(list))
+		for _, item := range list {
+			if str, ok := item.(string); ok && str != "" {
+				strs = append(strs, str)
+			}
+		}
+		return strs
+	}
+	return nil
+}
+
+// getWebhook returns the webhook URL and signing secret for a group
+func (d *DingTalkNotifier) getWebhook(group string) (string, string) {
+	// Check for group-specific robot
+	if webhook, ok := d.config.Webhooks[group]; ok {
+		return webhook, d.config.Secrets[group]
+	}
+
+	// Fall back to default robot
+	return d.config.WebhookURL, d.config.Secret
+}
+
+// signedURL adds DingTalk's timestamp and signature parameters to a webhook URL. The signature
+// is the base64 HMAC-SHA256 of "<timestamp ms>\n<secret>", keyed with the secret.
+func (d *DingTalkNotifier) signedURL(webhookURL, secret string) (string, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid DingTalk webhook URL: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(d.now().UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// sendToDingTalk posts the message to a robot webhook
+func (d *DingTalkNotifier) sendToDingTalk(ctx context.Context, webhookURL, secret string, msg *dingTalkMessage) error {
+	if secret != "" {
+		signed, err := d.signedURL(webhookURL, secret)
+		if err != nil {
+			return err
+		}
+		webhookURL = signed
+	}
+
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DingTalk message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send DingTalk notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newStatusCodeError(resp.StatusCode, "DingTalk API returned status: %d", resp.StatusCode)
+	}
+
+	// DingTalk reports errors such as a bad signature or keyword mismatch with a 200
+	var result dingTalkResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode DingTalk response: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("DingTalk API error %d: %s", result.ErrCode, result.ErrMsg)
+	}
+
+	return nil
+}
+
+// MetadataKeys returns the metadata keys the DingTalk notifier reads
+func (d *DingTalkNotifier) MetadataKeys() []string {
+	return []string{"at_mobiles", "at_user_ids", "at_all"}
+}
+
+// Close closes the HTTP client
+func (d *DingTalkNotifier) Close() error {
+	d.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestDingTalkSend tests that a signed markdown message mentioning members is posted
+func TestDingTalkSend(t *testing.T) {
+	var received dingTalkMessage
+	var query map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{
+			"access_token": r.URL.Query().Get("access_token"),
+			"timestamp":    r.URL.Query().Get("timestamp"),
+			"sign":         r.URL.Query().Get("sign"),
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(dingTalkResponse{ErrCode: 0, ErrMsg: "ok"})
+	}))
+	defer server.Close()
+
+	dingTalk, err := NewDingTalkNotifier(&DingTalkConfig{
+		WebhookURL: server.URL + "/robot/send?access_token=abc",
+		Secret:     "SECtest",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create DingTalk notifier: %v", err)
+	}
+	dingTalk.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	result, err := dingTalk.Send(context.Background(), &domain.Notification{
+		ID:         "dingtalk-1",
+		Type:       domain.TypeDingTalk,
+		Subject:    "Deploy finished",
+		Body:       "**api** is at v1.2.3",
+		Recipients: []string{"ops"},
+		Metadata: map[string]interface{}{
+			"at_mobiles":  []interface{}{"13800000000"},
+			"at_user_ids": []interface{}{"user123"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	mac := hmac.New(sha256.New, []byte("SECtest"))
+	mac.Write([]byte("1700000000000\nSECtest"))
+	if query["access_token"] != "abc" || query["timestamp"] != "1700000000000" ||
+		query["sign"] != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("Unexpected signature parameters: %v", query)
+	}
+
+	if received.MsgType != "markdown" || received.Markdown.Title != "Deploy finished" {
+		t.Errorf("Unexpected message: %+v", received)
+	}
+	if !strings.HasPrefix(received.Markdown.Text, "### Deploy finished\n\n**api** is at v1.2.3") ||
+		!strings.HasSuffix(received.Markdown.Text, "@13800000000 @user123") {
+		t.Errorf("Expected the subject heading and mentions in the text, got %q", received.Markdown.Text)
+	}
+	if received.At == nil || len(received.At.AtMobiles) != 1 || len(received.At.AtUserIDs) != 1 || received.At.IsAtAll {
+		t.Errorf("Unexpected mentions: %+v", received.At)
+	}
+}
+
+// TestDingTalkSendRejected tests that an error code in a 200 response fails the send
+func TestDingTalkSendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sign") != "" {
+			t.Error("Expected an unsigned request for a robot without a secret")
+		}
+		json.NewEncoder(w).Encode(dingTalkResponse{ErrCode: 310000, ErrMsg: "keywords not in content"})
+	}))
+	defer server.Close()
+
+	dingTalk, err := NewDingTalkNotifier(&DingTalkConfig{
+		Webhooks: map[string]string{"ops": server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create DingTalk notifier: %v", err)
+	}
+
+	result, err := dingTalk.Send(context.Background(), &domain.Notification{
+		ID:         "dingtalk-2",
+		Type:       domain.TypeDingTalk,
+		Body:       "hello",
+		Recipients: []string{"ops"},
+	})
+	if err == nil || result.Success {
+		t.Fatal("Expected the send to fail")
+	}
+	if !strings.Contains(err.Error(), "310000") {
+		t.Errorf("Expected the DingTalk error code in the error, got %v", err)
+	}
+
+	if _, err := dingTalk.Send(context.Background(), &domain.Notification{
+		ID:         "dingtalk-3",
+		Type:       domain.TypeDingTalk,
+		Body:       "hello",
+		Recipients: []string{"unknown"},
+	}); err == nil {
+		t.Error("Expected an error for a group without a webhook")
+	}
+}
@@ -0,0 +1,243 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// DiscordConfig contains Discord webhook configuration
+type DiscordConfig struct {
+	WebhookURL   string            `mapstructure:"webhook_url"`
+	Username     string            `mapstructure:"username"`      // Overrides the webhook's default username
+	AvatarURL    string            `mapstructure:"avatar_url"`    // Overrides the webhook's default avatar
+	Webhooks     map[string]string `mapstructure:"webhooks"`      // Channel-specific webhooks
+	Display      DisplayConfig     `mapstructure:"display"`       // Timestamp rendering for recipients
+	Markup       MarkupConfig      `mapstructure:"markup"`        // Emoji shortcode and markdown handling
+	Default      bool              `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string          `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+}
+
+// DiscordNotifier sends notifications to Discord via webhooks
+type DiscordNotifier struct {
+	BaseNotifier
+	config     *DiscordConfig
+	httpClient *http.Client
+	markup     *markupNormalizer
+}
+
+// discordMessage represents the Discord webhook request format
+type discordMessage struct {
+	Username  string         `json:"username,omitempty"`
+	AvatarURL string         `json:"avatar_url,omitempty"`
+	Content   string         `json:"content,omitempty"`
+	Embeds    []discordEmbed `json:"embeds,omitempty"`
+}
+
+// discordEmbed represents a Discord rich embed
+type discordEmbed struct {
+	Title       string              `json:"title,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+}
+
+// discordEmbedFooter represents the footer of a Discord embed
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// Discord embed field limits
+const (
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+)
+
+// Embed colors by priority
+const (
+	discordColorLow      = 0x95A5A6 // grey
+	discordColorNormal   = 0x3498DB // blue
+	discordColorHigh     = 0xE67E22 // orange
+	discordColorCritical = 0xE74C3C // red
+)
+
+// NewDiscordNotifier creates a new Discord notifier
+func NewDiscordNotifier(config *DiscordConfig) (*DiscordNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Discord config is required")
+	}
+
+	if config.WebhookURL == "" && len(config.Webhooks) == 0 {
+		return nil, fmt.Errorf("Discord webhook URL or channel webhooks are required")
+	}
+
+	base, err := newBaseNotifier(domain.TypeDiscord, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	markup, err := newMarkupNormalizer(config.Markup, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupKeep}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Discord markup config: %w", err)
+	}
+
+	return &DiscordNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		markup: markup,
+	}, nil
+}
+
+// Send sends a notification to Discord
+func (d *DiscordNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := d.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = d.markup.normalize(notification)
+
+	// For Discord, recipients are channel names mapped to webhooks
+	msg := d.buildMessage(notification)
+	for _, recipient := range notification.Recipients {
+		webhookURL := d.getWebhookURL(recipient)
+		if webhookURL == "" {
+			err := fmt.Errorf("no Discord webhook configured for channel: %s", recipient)
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+
+		if err := d.sendToDiscord(ctx, webhookURL, msg); err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Discord notification sent to %d channels", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"channels": notification.Recipients,
+		},
+	}, nil
+}
+
+// buildMessage constructs a Discord message with a single embed
+func (d *DiscordNotifier) buildMessage(notification *domain.Notification) *discordMessage {
+	sentAt := time.Now()
+	if notification.ScheduledFor != nil && notification.ScheduledFor.After(sentAt) {
+		sentAt = *notification.ScheduledFor
+	}
+
+	embed := discordEmbed{
+		Title:       truncateRunes(notification.Subject, discordMaxTitle),
+		Description: truncateRunes(notification.Body, discordMaxDescription),
+		Color:       discordPriorityColor(notification.Priority),
+		Timestamp:   sentAt.UTC().Format(time.RFC3339),
+	}
+
+	// Add the sent timestamp footer in the account's display timezone
+	if footer := d.sentFooter(sentAt); footer != "" {
+		embed.Footer = &discordEmbedFooter{Text: footer}
+	}
+
+	return &discordMessage{
+		Username:  d.config.Username,
+		AvatarURL: d.config.AvatarURL,
+		Embeds:    []discordEmbed{embed},
+	}
+}
+
+// discordPriorityColor returns the embed color for a priority
+func discordPriorityColor(priority domain.Priority) int {
+	switch priority {
+	case domain.PriorityLow:
+		return discordColorLow
+	case domain.PriorityHigh:
+		return discordColorHigh
+	case domain.PriorityCritical:
+		return discordColorCritical
+	default:
+		return discordColorNormal
+	}
+}
+
+// truncateRunes shortens s to at most max runes, marking the cut with an ellipsis
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// getWebhookURL returns the webhook URL for a specific channel
+func (d *DiscordNotifier) getWebhookURL(channel string) string {
+	// Check for channel-specific webhook
+	if webhook, ok := d.config.Webhooks[channel]; ok {
+		return webhook
+	}
+
+	// Fall back to default webhook URL
+	return d.config.WebhookURL
+}
+
+// sendToDiscord posts the message to a Discord webhook
+func (d *DiscordNotifier) sendToDiscord(ctx context.Context, webhookURL string, msg *discordMessage) error {
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Discord message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Discord notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newStatusCodeError(resp.StatusCode, "Discord API returned status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// MetadataKeys reports that the Discord notifier reads no metadata
+func (d *DiscordNotifier) MetadataKeys() []string {
+	return nil
+}
+
+// Close closes the HTTP client
+func (d *DiscordNotifier) Close() error {
+	d.httpClient.CloseIdleConnections()
+	return nil
+}
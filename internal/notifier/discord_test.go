@@ -0,0 +1,150 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestDiscordSendEmbed tests that notifications are posted as an embed colored by priority
+func TestDiscordSendEmbed(t *testing.T) {
+	var received discordMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord, err := NewDiscordNotifier(&DiscordConfig{WebhookURL: server.URL, Username: "notifier"})
+	if err != nil {
+		t.Fatalf("Failed to create Discord notifier: %v", err)
+	}
+
+	result, err := discord.Send(context.Background(), &domain.Notification{
+		ID:         "discord-1",
+		Type:       domain.TypeDiscord,
+		Subject:    "Deploy failed",
+		Body:       "Rollback in progress",
+		Priority:   domain.PriorityCritical,
+		Recipients: []string{"default"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+	if received.Username != "notifier" {
+		t.Errorf("username = %q, want notifier", received.Username)
+	}
+	if len(received.Embeds) != 1 {
+		t.Fatalf("Expected 1 embed, got %d", len(received.Embeds))
+	}
+	embed := received.Embeds[0]
+	if embed.Title != "Deploy failed" || embed.Description != "Rollback in progress" {
+		t.Errorf("Unexpected embed content: %+v", embed)
+	}
+	if embed.Color != discordColorCritical {
+		t.Errorf("color = %#x, want %#x", embed.Color, discordColorCritical)
+	}
+	if embed.Timestamp == "" {
+		t.Error("Expected embed timestamp to be set")
+	}
+}
+
+// TestDiscordPriorityColor tests the embed color chosen for each priority
+func TestDiscordPriorityColor(t *testing.T) {
+	tests := []struct {
+		priority domain.Priority
+		want     int
+	}{
+		{domain.PriorityLow, discordColorLow},
+		{domain.PriorityNormal, discordColorNormal},
+		{domain.PriorityHigh, discordColorHigh},
+		{domain.PriorityCritical, discordColorCritical},
+	}
+
+	for _, tt := range tests {
+		if got := discordPriorityColor(tt.priority); got != tt.want {
+			t.Errorf("discordPriorityColor(%d) = %#x, want %#x", tt.priority, got, tt.want)
+		}
+	}
+}
+
+// TestDiscordChannelWebhooks tests that recipients are routed to their channel webhooks
+func TestDiscordChannelWebhooks(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	discord, err := NewDiscordNotifier(&DiscordConfig{
+		Webhooks: map[string]string{
+			"alerts":  server.URL + "/alerts",
+			"deploys": server.URL + "/deploys",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Discord notifier: %v", err)
+	}
+
+	_, err = discord.Send(context.Background(), &domain.Notification{
+		ID:         "discord-2",
+		Type:       domain.TypeDiscord,
+		Body:       "hello",
+		Recipients: []string{"alerts", "deploys"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if strings.Join(paths, ",") != "/alerts,/deploys" {
+		t.Errorf("Posted to %v, want /alerts and /deploys", paths)
+	}
+
+	// Unknown channels fail when there's no default webhook to fall back to
+	_, err = discord.Send(context.Background(), &domain.Notification{
+		ID:         "discord-3",
+		Type:       domain.TypeDiscord,
+		Body:       "hello",
+		Recipients: []string{"unknown"},
+	})
+	if err == nil {
+		t.Error("Expected error for channel without a webhook")
+	}
+}
+
+// TestDiscordErrorStatus tests that non-2xx webhook responses are reported as failures
+func TestDiscordErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	discord, err := NewDiscordNotifier(&DiscordConfig{WebhookURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Discord notifier: %v", err)
+	}
+
+	result, err := discord.Send(context.Background(), &domain.Notification{
+		ID:         "discord-4",
+		Type:       domain.TypeDiscord,
+		Body:       "hello",
+		Recipients: []string{"default"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for 404 response")
+	}
+	if result == nil || result.Success {
+		t.Error("Expected failed result")
+	}
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// DisplayConfig controls how timestamps are rendered for the recipients of an account
+type DisplayConfig struct {
+	// Timezone is an IANA timezone name (e.g., "Europe/Berlin"). Defaults to UTC.
+	Timezone string `mapstructure:"timezone"`
+
+	// Locale selects a regional timestamp layout (e.g., "en-US", "de-DE"). Ignored if TimeFormat is set.
+	Locale string `mapstructure:"locale"`
+
+	// TimeFormat is an explicit Go time layout that overrides the locale layout
+	TimeFormat string `mapstructure:"time_format"`
+
+	// SentFooter appends a "Sent <timestamp>" footer to outgoing messages
+	SentFooter bool `mapstructure:"sent_footer"`
+}
+
+// localeLayouts maps locales to numeric timestamp layouts, so no translated month names are needed
+var localeLayouts = map[string]string{
+	"en-us": "01/02/2006 3:04 PM MST",
+	"en-gb": "02/01/2006 15:04 MST",
+	"de-de": "02.01.2006 15:04 MST",
+	"fr-fr": "02/01/2006 15:04 MST",
+	"es-es": "02/01/2006 15:04 MST",
+	"nl-nl": "02-01-2006 15:04 MST",
+	"ja-jp": "2006/01/02 15:04 MST",
+	"iso":   "2006-01-02 15:04 MST",
+}
+
+// defaultTimeLayout is used when neither a locale nor an explicit layout is configured
+const defaultTimeLayout = "2006-01-02 15:04 MST"
+
+// TimestampFormatter renders timestamps in an account's configured timezone and layout
+type TimestampFormatter struct {
+	location *time.Location
+	layout   string
+}
+
+// NewTimestampFormatter creates a formatter from a display configuration
+func NewTimestampFormatter(config DisplayConfig) (*TimestampFormatter, error) {
+	location := time.UTC
+	if config.Timezone != "" {
+		loc, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+		}
+		location = loc
+	}
+
+	layout := defaultTimeLayout
+	if config.TimeFormat != "" {
+		layout = config.TimeFormat
+	} else if config.Locale != "" {
+		localeLayout, ok := localeLayouts[strings.ToLower(strings.ReplaceAll(config.Locale, "_", "-"))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported locale: %s", config.Locale)
+		}
+		layout = localeLayout
+	}
+
+	return &TimestampFormatter{
+		location: location,
+		layout:   layout,
+	}, nil
+}
+
+// Format renders a timestamp in the configured timezone and layout
+func (f *TimestampFormatter) Format(t time.Time) string {
+	return t.In(f.location).Format(f.layout)
+}
+
+// SentFooter returns the "Sent <timestamp>" footer line for a message sent at t
+func (f *TimestampFormatter) SentFooter(t time.Time) string {
+	return "Sent " + f.Format(t)
+}
+
+// newBaseNotifier creates the part of a notifier common to every channel, with the account's
+// display settings. Invalid settings are rejected even when the footer is off, so
+// misconfiguration is caught at startup.
+func newBaseNotifier(notificationType domain.NotificationType, config DisplayConfig) (BaseNotifier, error) {
+	display, err := NewTimestampFormatter(config)
+	if err != nil {
+		return BaseNotifier{}, fmt.Errorf("invalid %s display config: %w", notificationType, err)
+	}
+	return BaseNotifier{notificationType: notificationType, display: display, footer: config.SentFooter}, nil
+}
+
+// FormatTime renders a timestamp in the account's display timezone and layout, so templates
+// can show times the way the account's recipients expect
+func (b *BaseNotifier) FormatTime(t time.Time) string {
+	if b.display == nil {
+		return t.UTC().Format(defaultTimeLayout)
+	}
+	return b.display.Format(t)
+}
+
+// sentFooter returns the footer line for a message sent at t, or "" if the account has no footer
+func (b *BaseNotifier) sentFooter(t time.Time) string {
+	if !b.footer || b.display == nil {
+		return ""
+	}
+	return b.display.SentFooter(t)
+}
+
+// withSentFooter returns a copy of a notification with the account's sent footer appended to
+// its bodies, for channels without a footer element of their own
+func (b *BaseNotifier) withSentFooter(notification *domain.Notification) *domain.Notification {
+	footer := b.sentFooter(time.Now())
+	if footer == "" {
+		return notification
+	}
+
+	n := *notification
+	if isHTMLContent(&n) {
+		n.Body = appendHTMLFooter(n.Body, footer)
+	} else {
+		n.Body = appendTextFooter(n.Body, footer)
+	}
+	if n.HTMLBody != "" {
+		n.HTMLBody = appendHTMLFooter(n.HTMLBody, footer)
+	}
+	return &n
+}
+
+// appendTextFooter appends a footer line to a plain-text body
+func appendTextFooter(body, footer string) string {
+	if footer == "" {
+		return body
+	}
+	return body + "\n\n" + footer
+}
+
+// appendHTMLFooter appends a footer paragraph to an HTML body
+func appendHTMLFooter(body, footer string) string {
+	if footer == "" {
+		return body
+	}
+	return body + `<p style="color:#888888;font-size:12px">` + html.EscapeString(footer) + "</p>"
+}
@@ -0,0 +1,100 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+func TestTimestampFormatter(t *testing.T) {
+	sentAt := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		config   DisplayConfig
+		expected string
+	}{
+		{
+			name:     "defaults to UTC",
+			config:   DisplayConfig{},
+			expected: "Sent 2024-05-01 07:00 UTC",
+		},
+		{
+			name:     "timezone",
+			config:   DisplayConfig{Timezone: "Europe/Berlin"},
+			expected: "Sent 2024-05-01 09:00 CEST",
+		},
+		{
+			name:     "locale layout",
+			config:   DisplayConfig{Timezone: "America/New_York", Locale: "en_US"},
+			expected: "Sent 05/01/2024 3:00 AM EDT",
+		},
+		{
+			name:     "explicit layout overrides locale",
+			config:   DisplayConfig{Locale: "de-DE", TimeFormat: "15:04 on Jan 2"},
+			expected: "Sent 07:00 on May 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := NewTimestampFormatter(tt.config)
+			if err != nil {
+				t.Fatalf("NewTimestampFormatter() error = %v", err)
+			}
+			if got := formatter.SentFooter(sentAt); got != tt.expected {
+				t.Errorf("SentFooter() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimestampFormatterInvalidConfig(t *testing.T) {
+	if _, err := NewTimestampFormatter(DisplayConfig{Timezone: "Mars/Olympus_Mons"}); err == nil {
+		t.Error("Expected error for unknown timezone")
+	}
+	if _, err := NewTimestampFormatter(DisplayConfig{Locale: "xx-XX"}); err == nil {
+		t.Error("Expected error for unsupported locale")
+	}
+
+	// Invalid settings are rejected even when the footer is disabled
+	if _, err := NewSMTPNotifier(&SMTPConfig{Host: "smtp.example.com", From: "a@example.com", Display: DisplayConfig{Timezone: "bogus"}}); err == nil {
+		t.Error("Expected SMTP notifier creation to fail with invalid timezone")
+	}
+}
+
+// TestSentFooterOnEveryChannel tests that channels without a footer element of their own get
+// the sent footer appended to their bodies, as a paragraph in HTML
+func TestSentFooterOnEveryChannel(t *testing.T) {
+	base, err := newBaseNotifier(domain.TypeBark, DisplayConfig{Timezone: "Europe/Berlin", TimeFormat: "Jan 2 15:04", SentFooter: true})
+	if err != nil {
+		t.Fatalf("newBaseNotifier() error = %v", err)
+	}
+
+	original := &domain.Notification{Body: "Backup finished", HTMLBody: "<p>Backup finished</p>"}
+	footed := base.withSentFooter(original)
+	if !strings.HasPrefix(footed.Body, "Backup finished\n\nSent ") || !strings.Contains(footed.HTMLBody, "<p style=") {
+		t.Errorf("Expected footers on both bodies, got body=%q, html_body=%q", footed.Body, footed.HTMLBody)
+	}
+	if original.Body != "Backup finished" {
+		t.Error("Expected the notification itself to be left unchanged")
+	}
+
+	plain, err := newBaseNotifier(domain.TypeBark, DisplayConfig{Timezone: "Europe/Berlin"})
+	if err != nil {
+		t.Fatalf("newBaseNotifier() error = %v", err)
+	}
+	if plain.withSentFooter(original) != original {
+		t.Error("Expected no footer when sent_footer is off")
+	}
+	if got := plain.FormatTime(time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)); got != "2024-05-01 09:00 CEST" {
+		t.Errorf("FormatTime() = %q", got)
+	}
+
+	// Invalid settings are rejected by every channel
+	if _, err := NewBarkNotifier(&BarkConfig{Display: DisplayConfig{Locale: "xx-XX"}}); err == nil {
+		t.Error("Expected Bark notifier creation to fail with an unsupported locale")
+	}
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// defaultFailbackProbeInterval is how often a failed-over notifier retries its primary
+const defaultFailbackProbeInterval = 5 * time.Minute
+
+// statusCodeError is a provider HTTP response with a non-2xx status
+type statusCodeError struct {
+	message string
+	code    int
+}
+
+func (e *statusCodeError) Error() string {
+	return e.message
+}
+
+// newStatusCodeError creates an error for a non-2xx provider response
+func newStatusCodeError(code int, format string, args ...interface{}) error {
+	return &statusCodeError{message: fmt.Sprintf(format, args...), code: code}
+}
+
+// isFailoverError reports whether an error means the endpoint or its credentials are unusable
+// (connection failures and authentication rejections), as opposed to a problem with the message
+func isFailoverError(err error) bool {
+	var statusErr *statusCodeError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == 401 || statusErr.code == 403
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		// 530 authentication required, 534 mechanism too weak, 535 credentials invalid
+		return protoErr.Code == 530 || protoErr.Code == 534 || protoErr.Code == 535
+	}
+
+	// HTTP transport failures (refused connections, DNS, TLS, timeouts), unless we gave up ourselves
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return isConnectError(err)
+}
+
+// isConnectError reports whether err is a failure to reach the endpoint
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial"
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// failover switches a notifier between its primary and warm spare endpoint.
+// After failing over, the primary is probed again every probeInterval and used
+// as soon as it succeeds.
+type failover struct {
+	mu            sync.Mutex
+	probeInterval time.Duration
+	onSecondary   bool
+	lastProbe     time.Time
+}
+
+// newFailover creates failover state from a probe interval ("" uses the default)
+func newFailover(probeInterval string) (*failover, error) {
+	interval := defaultFailbackProbeInterval
+	if probeInterval != "" {
+		parsed, err := time.ParseDuration(probeInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid probe_interval: %w", err)
+		}
+		interval = parsed
+	}
+
+	return &failover{probeInterval: interval}, nil
+}
+
+// run sends through the primary unless failed over, falling back to the secondary when the
+// primary returns a connection or authentication error. It reports whether the secondary was used.
+func (f *failover) run(primary, secondary func() error) (bool, error) {
+	if f.shouldTryPrimary() {
+		err := primary()
+		if err == nil {
+			f.setOnSecondary(false)
+			return false, nil
+		}
+		if !isFailoverError(err) {
+			return false, err
+		}
+		f.setOnSecondary(true)
+	}
+
+	if err := secondary(); err != nil {
+		return true, fmt.Errorf("backup endpoint failed: %w", err)
+	}
+	return true, nil
+}
+
+// shouldTryPrimary reports whether the primary should be attempted, claiming a failback probe if due
+func (f *failover) shouldTryPrimary() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.onSecondary {
+		return true
+	}
+	if time.Since(f.lastProbe) >= f.probeInterval {
+		f.lastProbe = time.Now()
+		return true
+	}
+	return false
+}
+
+// setOnSecondary records whether the notifier is currently failed over
+func (f *failover) setOnSecondary(onSecondary bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if onSecondary && !f.onSecondary {
+		f.lastProbe = time.Now()
+	}
+	f.onSecondary = onSecondary
+}
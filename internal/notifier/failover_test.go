@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestNtfyFailover tests failing over to the backup server and probing the primary for failback
+func TestNtfyFailover(t *testing.T) {
+	var primaryCalls, backupCalls int32
+	var primaryStatus int32 = http.StatusUnauthorized
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&primaryStatus)))
+	}))
+	defer primary.Close()
+
+	backup := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&backupCalls, 1)
+		if r.Header.Get("Authorization") != "Bearer backup-token" {
+			t.Errorf("Expected backup credentials, got %q", r.Header.Get("Authorization"))
+		}
+	}))
+	defer backup.Close()
+
+	n, err := NewNtfyNotifier(&NtfyConfig{
+		ServerURL: primary.URL,
+		Token:     "revoked-token",
+		Backup: &NtfyBackupConfig{
+			ServerURL:     backup.URL,
+			Token:         "backup-token",
+			ProbeInterval: "50ms",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewNtfyNotifier() error = %v", err)
+	}
+
+	notification := &domain.Notification{ID: "n1", Type: domain.TypeNtfy, Body: "hi", Recipients: []string{"alerts"}}
+	send := func() *domain.NotificationResult {
+		result, err := n.Send(context.Background(), notification)
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return result
+	}
+
+	// Primary rejects credentials: fail over
+	if result := send(); result.ProviderResponse["server"] != backup.URL {
+		t.Errorf("Expected delivery through backup, got %v", result.ProviderResponse["server"])
+	}
+
+	// While failed over, go straight to the backup
+	send()
+	if got := atomic.LoadInt32(&primaryCalls); got != 1 {
+		t.Errorf("Expected primary to be skipped while failed over, got %d calls", got)
+	}
+
+	// After the probe interval the primary is tried again and used once healthy
+	atomic.StoreInt32(&primaryStatus, http.StatusOK)
+	time.Sleep(60 * time.Millisecond)
+	if result := send(); result.ProviderResponse["server"] != primary.URL {
+		t.Errorf("Expected failback to primary, got %v", result.ProviderResponse["server"])
+	}
+	if got := atomic.LoadInt32(&backupCalls); got != 2 {
+		t.Errorf("Expected 2 backup calls, got %d", got)
+	}
+}
+
+// TestIsFailoverError tests which errors trigger failover
+func TestIsFailoverError(t *testing.T) {
+	if !isFailoverError(newStatusCodeError(http.StatusForbidden, "forbidden")) {
+		t.Error("Expected 403 to trigger failover")
+	}
+	if isFailoverError(newStatusCodeError(http.StatusBadRequest, "bad request")) {
+		t.Error("Expected 400 not to trigger failover")
+	}
+
+	_, err := http.Get("http://127.0.0.1:1")
+	if !isFailoverError(err) {
+		t.Errorf("Expected connection error to trigger failover: %v", err)
+	}
+}
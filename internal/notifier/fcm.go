@@ -0,0 +1,324 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// fcmScope is the OAuth2 scope required to send messages
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// FCM recipient prefixes; recipients without a prefix are device registration tokens
+const (
+	fcmTopicPrefix     = "topic:"
+	fcmConditionPrefix = "condition:"
+)
+
+// FCMConfig contains Firebase Cloud Messaging configuration
+type FCMConfig struct {
+	CredentialsFile string        `mapstructure:"credentials_file"` // Path to the service account JSON key
+	CredentialsJSON string        `mapstructure:"credentials_json"` // Inline service account JSON key (alternative to credentials_file)
+	ProjectID       string        `mapstructure:"project_id"`       // Firebase project (default: the service account's project)
+	APIURL          string        `mapstructure:"api_url"`          // FCM endpoint (default: https://fcm.googleapis.com)
+	Default         bool          `mapstructure:"default"`          // Mark this instance as default
+	AllowedRoles    []string      `mapstructure:"allowed_roles"`    // Roles allowed to use this notifier (empty = all authenticated)
+	Display         DisplayConfig `mapstructure:"display"`          // Timestamp rendering for recipients (timezone, locale, sent footer)
+
+	// Encryption seals titles and bodies to per-device public keys so FCM can't read them (optional).
+	// Device keys are keyed by recipient: a registration token, "topic:<name>" or "condition:<expression>".
+	Encryption *PushEncryptionConfig `mapstructure:"encryption"`
+}
+
+// FCMNotifier sends push notifications through the FCM HTTP v1 API
+type FCMNotifier struct {
+	BaseNotifier
+	config     *FCMConfig
+	httpClient *http.Client
+	tokens     oauth2.TokenSource
+	encryption *pushEncryption
+}
+
+// fcmServiceAccount holds the fields of a service account key used to authenticate
+type fcmServiceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// fcmRequest represents the FCM v1 send request format
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+// fcmMessage is a message to a single token, topic or condition
+type fcmMessage struct {
+	Token        string            `json:"token,omitempty"`
+	Topic        string            `json:"topic,omitempty"`
+	Condition    string            `json:"condition,omitempty"`
+	Notification *fcmNotification  `json:"notification,omitempty"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroidConfig `json:"android,omitempty"`
+	APNS         *fcmAPNSConfig    `json:"apns,omitempty"`
+}
+
+// fcmNotification is the user-visible part of a message
+type fcmNotification struct {
+	Title string `json:"title,omitempty"`
+	Body  string `json:"body,omitempty"`
+}
+
+// fcmAndroidConfig sets Android delivery options
+type fcmAndroidConfig struct {
+	Priority string `json:"priority"` // NORMAL or HIGH
+}
+
+// fcmAPNSConfig sets APNs delivery options
+type fcmAPNSConfig struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// fcmResponse represents the FCM v1 send response
+type fcmResponse struct {
+	Name  string `json:"name"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
+}
+
+// NewFCMNotifier creates a new FCM notifier
+func NewFCMNotifier(config *FCMConfig) (*FCMNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("FCM config is required")
+	}
+
+	credentials := []byte(config.CredentialsJSON)
+	if len(credentials) == 0 {
+		if config.CredentialsFile == "" {
+			return nil, fmt.Errorf("FCM credentials_file or credentials_json is required")
+		}
+		data, err := os.ReadFile(config.CredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read FCM credentials file: %w", err)
+		}
+		credentials = data
+	}
+
+	var account fcmServiceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("FCM credentials must be a service account key with client_email and private_key")
+	}
+
+	if config.ProjectID == "" {
+		config.ProjectID = account.ProjectID
+	}
+	if config.ProjectID == "" {
+		return nil, fmt.Errorf("FCM project_id is required")
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://fcm.googleapis.com"
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	jwtConfig := &jwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		TokenURL:     account.TokenURI,
+		Scopes:       []string{fcmScope},
+	}
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+
+	base, err := newBaseNotifier(domain.TypeFCM, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := newPushEncryption("FCM", config.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FCMNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient:   httpClient,
+		tokens:       oauth2.ReuseTokenSource(nil, jwtConfig.TokenSource(tokenCtx)),
+		encryption:   encryption,
+	}, nil
+}
+
+// Send sends a push notification to every recipient. Recipients are device registration
+// tokens, "topic:<name>" or "condition:<expression>". Metadata is sent as the data payload.
+func (f *FCMNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := f.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = f.withSentFooter(notification)
+
+	messageIDs := make([]string, 0, len(notification.Recipients))
+	encrypted := 0
+	for _, recipient := range notification.Recipients {
+		msg := f.buildMessage(notification, recipient)
+		title, body, sealed, err := f.encryption.seal(notification, recipient, "", msg.Notification.Title, msg.Notification.Body)
+		var name string
+		if err == nil {
+			msg.Notification.Title, msg.Notification.Body = title, body
+			name, err = f.sendMessage(ctx, msg)
+		}
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		messageIDs = append(messageIDs, name)
+		if sealed {
+			encrypted++
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("FCM notification sent to %d recipients", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"project":           f.config.ProjectID,
+			"message_ids":       messageIDs,
+			"encrypted_devices": encrypted,
+		},
+	}, nil
+}
+
+// buildMessage constructs the FCM message for one recipient
+func (f *FCMNotifier) buildMessage(notification *domain.Notification, recipient string) *fcmMessage {
+	msg := &fcmMessage{
+		Notification: &fcmNotification{
+			Title: notification.Subject,
+			Body:  notification.Body,
+		},
+		Data: fcmData(notification.Metadata),
+	}
+
+	switch {
+	case strings.HasPrefix(recipient, fcmTopicPrefix):
+		msg.Topic = strings.TrimPrefix(recipient, fcmTopicPrefix)
+	case strings.HasPrefix(recipient, fcmConditionPrefix):
+		msg.Condition = strings.TrimPrefix(recipient, fcmConditionPrefix)
+	default:
+		msg.Token = recipient
+	}
+
+	// High and critical notifications wake the device; others may be batched by the OS
+	if notification.Priority >= domain.PriorityHigh {
+		msg.Android = &fcmAndroidConfig{Priority: "HIGH"}
+		msg.APNS = &fcmAPNSConfig{Headers: map[string]string{"apns-priority": "10"}}
+	} else {
+		msg.Android = &fcmAndroidConfig{Priority: "NORMAL"}
+		msg.APNS = &fcmAPNSConfig{Headers: map[string]string{"apns-priority": "5"}}
+	}
+
+	return msg
+}
+
+// fcmData converts metadata to the string-only FCM data payload
+func fcmData(metadata map[string]interface{}) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	data := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		switch v := value.(type) {
+		case string:
+			data[key] = v
+		case nil:
+			continue
+		default:
+			// Encode structured values as JSON so clients can decode them
+			encoded, err := json.Marshal(v)
+			if err != nil {
+				data[key] = fmt.Sprint(v)
+			} else {
+				data[key] = string(encoded)
+			}
+		}
+	}
+	return data
+}
+
+// sendMessage posts a message to FCM and returns its message name
+func (f *FCMNotifier) sendMessage(ctx context.Context, msg *fcmMessage) (string, error) {
+	jsonData, err := json.Marshal(fcmRequest{Message: *msg})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal FCM message: %w", err)
+	}
+
+	token, err := f.tokens.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain FCM access token: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimSuffix(f.config.APIURL, "/"), f.config.ProjectID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(req)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send FCM notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var fcmResp fcmResponse
+	_ = json.NewDecoder(resp.Body).Decode(&fcmResp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if fcmResp.Error != nil {
+			return "", newStatusCodeError(resp.StatusCode, "FCM API returned status: %d (%s: %s)", resp.StatusCode, fcmResp.Error.Status, fcmResp.Error.Message)
+		}
+		return "", newStatusCodeError(resp.StatusCode, "FCM API returned status: %d", resp.StatusCode)
+	}
+
+	return fcmResp.Name, nil
+}
+
+// Close closes the HTTP client
+func (f *FCMNotifier) Close() error {
+	f.httpClient.CloseIdleConnections()
+	return nil
+}
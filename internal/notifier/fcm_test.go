@@ -0,0 +1,231 @@
+package notifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/pkg/e2e"
+)
+
+// newFCMTestCredentials returns a service account key whose token endpoint is tokenURL
+func newFCMTestCredentials(t *testing.T, tokenURL string) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	credentials, _ := json.Marshal(fcmServiceAccount{
+		ProjectID:   "test-project",
+		PrivateKey:  string(keyPEM),
+		ClientEmail: "notifier@test-project.iam.gserviceaccount.com",
+		TokenURI:    tokenURL,
+	})
+	return string(credentials)
+}
+
+// TestFCMSend tests token exchange, recipient routing, priority mapping and the data payload
+func TestFCMSend(t *testing.T) {
+	tokenRequests := 0
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var received []fcmMessage
+	fcmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/test-project/messages:send" {
+			t.Errorf("Unexpected path: %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		var req fcmRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		received = append(received, req.Message)
+		w.Write([]byte(`{"name":"projects/test-project/messages/1"}`))
+	}))
+	defer fcmServer.Close()
+
+	fcm, err := NewFCMNotifier(&FCMConfig{
+		CredentialsJSON: newFCMTestCredentials(t, tokenServer.URL),
+		APIURL:          fcmServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCM notifier: %v", err)
+	}
+
+	result, err := fcm.Send(context.Background(), &domain.Notification{
+		ID:         "fcm-1",
+		Type:       domain.TypeFCM,
+		Subject:    "Order shipped",
+		Body:       "Your order is on its way",
+		Priority:   domain.PriorityHigh,
+		Recipients: []string{"device-token", "topic:orders"},
+		Metadata:   map[string]interface{}{"order_id": "42", "items": []interface{}{"a", "b"}},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(received))
+	}
+	if received[0].Token != "device-token" || received[1].Topic != "orders" {
+		t.Errorf("Unexpected targets: %+v, %+v", received[0], received[1])
+	}
+	msg := received[0]
+	if msg.Notification == nil || msg.Notification.Title != "Order shipped" || msg.Notification.Body != "Your order is on its way" {
+		t.Errorf("Unexpected notification: %+v", msg.Notification)
+	}
+	if msg.Android == nil || msg.Android.Priority != "HIGH" || msg.APNS.Headers["apns-priority"] != "10" {
+		t.Errorf("Expected high priority delivery, got %+v, %+v", msg.Android, msg.APNS)
+	}
+	if msg.Data["order_id"] != "42" || msg.Data["items"] != `["a","b"]` {
+		t.Errorf("Unexpected data payload: %v", msg.Data)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected the access token to be reused, got %d token requests", tokenRequests)
+	}
+}
+
+// TestFCMEncryptedSend tests that messages to devices with a public key, configured or given in
+// the notification's e2e_public_key metadata, are sealed so FCM only sees a placeholder
+func TestFCMEncryptedSend(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	var received []fcmMessage
+	fcmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req fcmRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		received = append(received, req.Message)
+		w.Write([]byte(`{"name":"projects/test-project/messages/1"}`))
+	}))
+	defer fcmServer.Close()
+
+	phoneKey, phonePrivate, _ := e2e.GenerateKey()
+	tabletKey, tabletPrivate, _ := e2e.GenerateKey()
+	fcm, err := NewFCMNotifier(&FCMConfig{
+		CredentialsJSON: newFCMTestCredentials(t, tokenServer.URL),
+		APIURL:          fcmServer.URL,
+		Encryption: &PushEncryptionConfig{
+			DeviceKeys:       map[string]string{"phone-token": phoneKey},
+			PlaceholderTitle: "New message",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCM notifier: %v", err)
+	}
+
+	result, err := fcm.Send(context.Background(), &domain.Notification{
+		ID:         "fcm-e2e-1",
+		Type:       domain.TypeFCM,
+		Subject:    "Payment received",
+		Body:       "$1,200 from ACME",
+		Recipients: []string{"phone-token", "topic:payments"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(received))
+	}
+	sealed := received[0].Notification
+	if sealed.Title != "New message" || strings.Contains(sealed.Body, "ACME") {
+		t.Errorf("Expected the phone's message to hide its content, got %+v", sealed)
+	}
+	key, _ := e2e.ParsePrivateKey(phonePrivate)
+	if payload, err := e2e.OpenPayload(key, sealed.Body); err != nil || payload.Message != "$1,200 from ACME" {
+		t.Errorf("Phone failed to decrypt: %+v, %v", payload, err)
+	}
+	if received[1].Notification.Body != "$1,200 from ACME" {
+		t.Errorf("Expected plaintext for a topic without a key, got %+v", received[1].Notification)
+	}
+	if result.ProviderResponse["encrypted_devices"] != 1 {
+		t.Errorf("encrypted_devices = %v", result.ProviderResponse["encrypted_devices"])
+	}
+
+	// A key in the metadata covers a device that isn't configured
+	received = nil
+	if _, err := fcm.Send(context.Background(), &domain.Notification{
+		ID:         "fcm-e2e-2",
+		Type:       domain.TypeFCM,
+		Subject:    "Payment received",
+		Body:       "$80 from Initech",
+		Recipients: []string{"tablet-token"},
+		Metadata:   map[string]interface{}{"e2e_public_key": tabletKey},
+	}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	key, _ = e2e.ParsePrivateKey(tabletPrivate)
+	if payload, err := e2e.OpenPayload(key, received[0].Notification.Body); err != nil || payload.Title != "Payment received" {
+		t.Errorf("Tablet failed to decrypt: %+v, %v", payload, err)
+	}
+}
+
+// TestFCMSendError tests that FCM errors are surfaced with their status
+func TestFCMSendError(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"test-token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokenServer.Close()
+
+	fcmServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"message":"Requested entity was not found.","status":"NOT_FOUND"}}`))
+	}))
+	defer fcmServer.Close()
+
+	fcm, err := NewFCMNotifier(&FCMConfig{
+		CredentialsJSON: newFCMTestCredentials(t, tokenServer.URL),
+		APIURL:          fcmServer.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create FCM notifier: %v", err)
+	}
+
+	result, err := fcm.Send(context.Background(), &domain.Notification{
+		ID:         "fcm-2",
+		Type:       domain.TypeFCM,
+		Body:       "Hello",
+		Recipients: []string{"stale-token"},
+	})
+	if err == nil || result == nil || result.Success {
+		t.Fatalf("Expected failure for unregistered token, got %+v, %v", result, err)
+	}
+}
+
+// TestNewFCMNotifierRequiresCredentials tests that a service account key is required
+func TestNewFCMNotifierRequiresCredentials(t *testing.T) {
+	if _, err := NewFCMNotifier(&FCMConfig{ProjectID: "test-project"}); err == nil {
+		t.Error("Expected error without credentials")
+	}
+	if _, err := NewFCMNotifier(&FCMConfig{CredentialsJSON: `{"type":"authorized_user"}`}); err == nil {
+		t.Error("Expected error for non-service-account credentials")
+	}
+}
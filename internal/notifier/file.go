@@ -0,0 +1,222 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// FileConfig contains file-append notifier configuration
+type FileConfig struct {
+	Path         string        `mapstructure:"path"`          // File notifications are appended to, one JSON object per line
+	MaxSizeMB    int           `mapstructure:"max_size_mb"`   // Rotate the file before it grows past this size (0 = never rotate)
+	MaxBackups   int           `mapstructure:"max_backups"`   // Rotated files kept as <path>.1 (newest) to <path>.N (default: 5)
+	Sync         bool          `mapstructure:"sync"`          // Flush every line to disk before reporting the send (slower, survives power loss)
+	Default      bool          `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string      `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// FileNotifier appends notifications to a file as JSON lines, rotating it by size. It needs
+// no network, so it suits air-gapped sites and tests that need a durable record of sends.
+type FileNotifier struct {
+	BaseNotifier
+	config  *FileConfig
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// fileRecord is one line of the file
+type fileRecord struct {
+	ID         string                 `json:"id"`
+	Account    string                 `json:"account,omitempty"`
+	Priority   domain.Priority        `json:"priority"`
+	Subject    string                 `json:"subject,omitempty"`
+	Body       string                 `json:"body"`
+	Recipients []string               `json:"recipients"`
+	Origin     *domain.Origin         `json:"origin,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	WrittenAt  time.Time              `json:"written_at"`
+}
+
+// NewFileNotifier creates a new file notifier, creating the file's directory if needed
+func NewFileNotifier(config *FileConfig) (*FileNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("file config is required")
+	}
+
+	if config.Path == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+
+	if config.MaxSizeMB < 0 || config.MaxBackups < 0 {
+		return nil, fmt.Errorf("file max_size_mb and max_backups must not be negative")
+	}
+
+	if config.MaxBackups == 0 {
+		config.MaxBackups = 5
+	}
+
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", config.Path, err)
+	}
+
+	base, err := newBaseNotifier(domain.TypeFile, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileNotifier{
+		BaseNotifier: base,
+		config:       config,
+		maxSize:      int64(config.MaxSizeMB) << 20,
+	}, nil
+}
+
+// Send appends the notification to the file as a single JSON line
+func (f *FileNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := f.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = f.withSentFooter(notification)
+
+	record := fileRecord{
+		ID:         notification.ID,
+		Account:    notification.Account,
+		Priority:   notification.Priority,
+		Subject:    notification.Subject,
+		Body:       notification.Body,
+		Recipients: notification.Recipients,
+		Metadata:   notification.Metadata,
+		CreatedAt:  notification.CreatedAt,
+		WrittenAt:  time.Now(),
+	}
+	if notification.Origin != (domain.Origin{}) {
+		record.Origin = &notification.Origin
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	line = append(line, '\n')
+
+	if err := f.write(line); err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Notification appended to %s", f.config.Path),
+		SentAt:         record.WrittenAt,
+		ProviderResponse: map[string]interface{}{
+			"path":  f.config.Path,
+			"bytes": len(line),
+		},
+	}, nil
+}
+
+// write appends a line, rotating first if it would take the file past its size limit
+func (f *FileNotifier) write(line []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	// A line bigger than the limit still goes in a file of its own
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", f.config.Path, err)
+	}
+
+	if f.config.Sync {
+		if err := f.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", f.config.Path, err)
+		}
+	}
+	return nil
+}
+
+// open opens the file for appending if it isn't already. Must be called with mu held.
+func (f *FileNotifier) open() error {
+	if f.file != nil {
+		return nil
+	}
+
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.config.Path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat %s: %w", f.config.Path, err)
+	}
+
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the backups along, dropping the oldest, moves the file to <path>.1 and starts
+// a new one. Must be called with mu held.
+func (f *FileNotifier) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", f.config.Path, err)
+	}
+	f.file = nil
+
+	for i := f.config.MaxBackups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", f.config.Path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", f.config.Path, i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate %s: %w", from, err)
+		}
+	}
+	if err := os.Rename(f.config.Path, f.config.Path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate %s: %w", f.config.Path, err)
+	}
+
+	return f.open()
+}
+
+// Close closes the file
+func (f *FileNotifier) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
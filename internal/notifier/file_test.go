@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// readFileRecords decodes every line of a file notifier's file
+func readFileRecords(t *testing.T, path string) []fileRecord {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	var records []fileRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record fileRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// TestFileAppend tests that notifications are appended as JSON lines, including across reopening
+func TestFileAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture", "sends.jsonl")
+	notification := &domain.Notification{
+		ID:         "n-1",
+		Type:       domain.TypeFile,
+		Subject:    "Deploy finished",
+		Body:       "v1.4.2 is live",
+		Recipients: []string{"audit"},
+		Origin:     domain.Origin{System: "deployer"},
+	}
+
+	for i := 0; i < 2; i++ {
+		f, err := NewFileNotifier(&FileConfig{Path: path, Sync: true})
+		if err != nil {
+			t.Fatalf("NewFileNotifier() error = %v", err)
+		}
+		result, err := f.Send(context.Background(), notification)
+		if err != nil || !result.Success {
+			t.Fatalf("Send() = %+v, %v", result, err)
+		}
+		f.Close()
+	}
+
+	records := readFileRecords(t, path)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(records))
+	}
+	record := records[0]
+	if record.ID != "n-1" || record.Subject != "Deploy finished" || record.Origin == nil || record.Origin.System != "deployer" || record.WrittenAt.IsZero() {
+		t.Errorf("Unexpected record: %+v", record)
+	}
+}
+
+// TestFileRotation tests that the file is rotated by size and old backups are dropped
+func TestFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sends.jsonl")
+	f, err := NewFileNotifier(&FileConfig{Path: path, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("NewFileNotifier() error = %v", err)
+	}
+	defer f.Close()
+	f.maxSize = 300 // Room for one line per file
+
+	for _, id := range []string{"n-1", "n-2", "n-3", "n-4"} {
+		notification := &domain.Notification{ID: id, Type: domain.TypeFile, Body: strings.Repeat("x", 100), Recipients: []string{"audit"}}
+		if _, err := f.Send(context.Background(), notification); err != nil {
+			t.Fatalf("Send(%s) error = %v", id, err)
+		}
+	}
+
+	for file, id := range map[string]string{path: "n-4", path + ".1": "n-3", path + ".2": "n-2"} {
+		if records := readFileRecords(t, file); len(records) != 1 || records[0].ID != id {
+			t.Errorf("Expected %s to hold only %s, got %+v", file, id, records)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected only 2 backups, stat .3: %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+// gmailSendScope allows sending mail and nothing else
+const gmailSendScope = "https://www.googleapis.com/auth/gmail.send"
+
+// GmailConfig sends an email account's mail through the Gmail API instead of SMTP, using a
+// service account with domain-wide delegation for the gmail.send scope
+type GmailConfig struct {
+	CredentialsFile string `mapstructure:"credentials_file"` // Service account JSON key file
+	Credentials     string `mapstructure:"credentials"`      // Service account JSON key, instead of credentials_file
+	User            string `mapstructure:"user"`             // Workspace user to send as (default: the account's from address)
+	APIURL          string `mapstructure:"api_url"`          // Gmail endpoint (default: https://gmail.googleapis.com/gmail/v1)
+}
+
+// gmailServiceAccount is the part of a service account JSON key used to sign token requests
+type gmailServiceAccount struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// gmailMessage is a message in the Gmail API's raw format
+type gmailMessage struct {
+	ID       string `json:"id,omitempty"`
+	ThreadID string `json:"threadId,omitempty"`
+	Raw      string `json:"raw,omitempty"`
+}
+
+// gmailErrorResponse represents a Google API error
+type gmailErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
+
+// gmailSender posts rendered messages to the Gmail API as the delegated user
+type gmailSender struct {
+	apiURL     string
+	user       string
+	httpClient *http.Client
+	tokens     oauth2.TokenSource
+}
+
+// newGmailSender loads the service account key and prepares delegated credentials for user
+func newGmailSender(config *GmailConfig, from string) (*gmailSender, error) {
+	key := []byte(config.Credentials)
+	if len(key) == 0 {
+		if config.CredentialsFile == "" {
+			return nil, fmt.Errorf("Gmail credentials_file or credentials is required")
+		}
+		var err error
+		if key, err = os.ReadFile(config.CredentialsFile); err != nil {
+			return nil, fmt.Errorf("failed to read Gmail credentials: %w", err)
+		}
+	}
+
+	var account gmailServiceAccount
+	if err := json.Unmarshal(key, &account); err != nil {
+		return nil, fmt.Errorf("invalid Gmail credentials: %w", err)
+	}
+	if account.Type != "service_account" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("Gmail credentials must be a service account key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	user := config.User
+	if user == "" {
+		user = from
+	}
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = "https://gmail.googleapis.com/gmail/v1"
+	}
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	// Domain-wide delegation: the service account signs a token request impersonating user
+	credentials := &jwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		Subject:      user,
+		Scopes:       []string{gmailSendScope},
+		TokenURL:     account.TokenURI,
+	}
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+
+	return &gmailSender{
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		user:       user,
+		httpClient: httpClient,
+		tokens:     credentials.TokenSource(tokenCtx),
+	}, nil
+}
+
+// send posts an RFC 5322 message and returns the Gmail message ID. Gmail delivers to the
+// addresses in the To, Cc and Bcc headers and strips Bcc before sending.
+func (g *gmailSender) send(ctx context.Context, message []byte) (string, error) {
+	token, err := g.tokens.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to obtain Gmail access token: %w", err)
+	}
+
+	jsonData, err := json.Marshal(gmailMessage{Raw: base64.URLEncoding.EncodeToString(message)})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Gmail message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/users/%s/messages/send", g.apiURL, url.PathEscape(g.user))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(req)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send Gmail message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp gmailErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Message != "" {
+			return "", newStatusCodeError(resp.StatusCode, "Gmail API returned status %d: %s: %s", resp.StatusCode, errResp.Error.Status, errResp.Error.Message)
+		}
+		return "", newStatusCodeError(resp.StatusCode, "Gmail API returned status: %d", resp.StatusCode)
+	}
+
+	var sent gmailMessage
+	if err := json.Unmarshal(respBody, &sent); err != nil {
+		return "", fmt.Errorf("failed to decode Gmail response: %w", err)
+	}
+	return sent.ID, nil
+}
+
+// sendGmail delivers a rendered message through the Gmail API. SMTP keeps BCC recipients out
+// of the headers and lists them in the envelope; Gmail takes them from a Bcc header instead.
+func (s *SMTPNotifier) sendGmail(ctx context.Context, notification *domain.Notification, message string) (*domain.NotificationResult, error) {
+	if len(notification.BCC) > 0 {
+		message = fmt.Sprintf("Bcc: %s\r\n", strings.Join(notification.BCC, ", ")) + message
+	}
+
+	messageID, err := s.gmail.send(ctx, []byte(message))
+	if err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Email sent via Gmail API to %d recipients", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"gmail_message_id": messageID,
+			"from":             s.config.From,
+			"to":               notification.Recipients,
+		},
+	}, nil
+}
@@ -0,0 +1,127 @@
+package notifier
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// testServiceAccountKey returns a service account JSON key whose token URI is tokenURL
+func testServiceAccountKey(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	account, _ := json.Marshal(gmailServiceAccount{
+		Type:        "service_account",
+		ClientEmail: "notifier@project.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    tokenURL,
+	})
+	return string(account)
+}
+
+// TestSMTPGmailSend tests that an account in Gmail API mode posts the rendered message,
+// including BCC recipients, as the delegated user
+func TestSMTPGmailSend(t *testing.T) {
+	var raw, path, assertion string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			r.ParseForm()
+			assertion = r.PostForm.Get("assertion")
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"gmail-token","token_type":"Bearer","expires_in":3600}`))
+		default:
+			if r.Header.Get("Authorization") != "Bearer gmail-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			path = r.URL.Path
+			var msg gmailMessage
+			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			decoded, err := base64.URLEncoding.DecodeString(msg.Raw)
+			if err != nil {
+				t.Errorf("Failed to decode raw message: %v", err)
+			}
+			raw = string(decoded)
+			json.NewEncoder(w).Encode(gmailMessage{ID: "18c0ffee", ThreadID: "18c0ffee"})
+		}
+	}))
+	defer server.Close()
+
+	smtpNotifier, err := NewSMTPNotifier(&SMTPConfig{
+		From: "alerts@example.com",
+		Gmail: &GmailConfig{
+			Credentials: testServiceAccountKey(t, server.URL+"/token"),
+			APIURL:      server.URL + "/gmail/v1",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create SMTP notifier: %v", err)
+	}
+
+	result, err := smtpNotifier.Send(context.Background(), &domain.Notification{
+		ID:         "gmail-1",
+		Type:       domain.TypeEmail,
+		Subject:    "Backup finished",
+		Body:       "All volumes backed up",
+		Recipients: []string{"ops@example.com"},
+		BCC:        []string{"audit@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success || result.ProviderResponse["gmail_message_id"] != "18c0ffee" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if path != "/gmail/v1/users/alerts@example.com/messages/send" {
+		t.Errorf("Unexpected send path: %s", path)
+	}
+	for _, header := range []string{"From: alerts@example.com\r\n", "To: ops@example.com\r\n", "Bcc: audit@example.com\r\n"} {
+		if !strings.Contains(raw, header) {
+			t.Errorf("Expected %q in message:\n%s", header, raw)
+		}
+	}
+
+	// The signed assertion impersonates the sending user
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected a JWT assertion, got %q", assertion)
+	}
+	claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if !strings.Contains(string(claims), `"sub":"alerts@example.com"`) || !strings.Contains(string(claims), gmailSendScope) {
+		t.Errorf("Unexpected assertion claims: %s", claims)
+	}
+}
+
+// TestNewGmailSenderRequiresServiceAccount tests that credentials must be a service account key
+func TestNewGmailSenderRequiresServiceAccount(t *testing.T) {
+	if _, err := NewSMTPNotifier(&SMTPConfig{From: "a@example.com", Gmail: &GmailConfig{}}); err == nil {
+		t.Error("Expected error without credentials")
+	}
+	if _, err := NewSMTPNotifier(&SMTPConfig{From: "a@example.com", Gmail: &GmailConfig{Credentials: `{"type": "authorized_user"}`}}); err == nil {
+		t.Error("Expected error for credentials that aren't a service account key")
+	}
+	if _, err := NewSMTPNotifier(&SMTPConfig{From: "a@example.com"}); err == nil {
+		t.Error("Expected error without a host or Gmail API mode")
+	}
+}
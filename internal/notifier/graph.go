@@ -0,0 +1,304 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// graphScope requests the application permissions granted to the app registration
+const graphScope = "https://graph.microsoft.com/.default"
+
+// GraphConfig contains Microsoft Graph email configuration. The app registration needs the
+// Mail.Send application permission, ideally limited to the sending mailbox with an
+// application access policy.
+type GraphConfig struct {
+	TenantID        string        `mapstructure:"tenant_id"`          // Entra ID (Azure AD) tenant ID or domain
+	ClientID        string        `mapstructure:"client_id"`          // App registration (client) ID
+	ClientSecret    string        `mapstructure:"client_secret"`      // App registration client secret
+	From            string        `mapstructure:"from"`               // Mailbox to send as (user principal name or ID)
+	SaveToSentItems bool          `mapstructure:"save_to_sent_items"` // Keep a copy in the mailbox's Sent Items
+	APIURL          string        `mapstructure:"api_url"`            // Graph endpoint (default: https://graph.microsoft.com/v1.0)
+	TokenURL        string        `mapstructure:"token_url"`          // Token endpoint (default: the tenant's login.microsoftonline.com endpoint)
+	Default         bool          `mapstructure:"default"`            // Mark this instance as default
+	AllowedRoles    []string      `mapstructure:"allowed_roles"`      // Roles allowed to use this notifier (empty = all authenticated)
+	Display         DisplayConfig `mapstructure:"display"`            // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// GraphNotifier sends email through the Microsoft Graph sendMail API, for tenants where SMTP
+// AUTH is disabled. Accounts are registered as the email type alongside SMTP accounts.
+type GraphNotifier struct {
+	BaseNotifier
+	config     *GraphConfig
+	httpClient *http.Client
+	tokens     oauth2.TokenSource
+}
+
+// graphSendMailRequest represents the Graph sendMail request
+type graphSendMailRequest struct {
+	Message         graphMessage `json:"message"`
+	SaveToSentItems bool         `json:"saveToSentItems"`
+}
+
+// graphMessage is the message in a sendMail request
+type graphMessage struct {
+	Subject                string           `json:"subject"`
+	Body                   graphItemBody    `json:"body"`
+	ToRecipients           []graphRecipient `json:"toRecipients,omitempty"`
+	CcRecipients           []graphRecipient `json:"ccRecipients,omitempty"`
+	BccRecipients          []graphRecipient `json:"bccRecipients,omitempty"`
+	ReplyTo                []graphRecipient `json:"replyTo,omitempty"`
+	InternetMessageHeaders []graphHeader    `json:"internetMessageHeaders,omitempty"`
+}
+
+// graphItemBody is a message body
+type graphItemBody struct {
+	ContentType string `json:"contentType"` // "Text" or "HTML"
+	Content     string `json:"content"`
+}
+
+// graphRecipient is a message recipient
+type graphRecipient struct {
+	EmailAddress graphEmailAddress `json:"emailAddress"`
+}
+
+// graphEmailAddress is a recipient's address
+type graphEmailAddress struct {
+	Address string `json:"address"`
+}
+
+// graphHeader is a custom internet message header
+type graphHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// graphErrorResponse represents a Graph API error
+type graphErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewGraphNotifier creates a new Microsoft Graph email notifier
+func NewGraphNotifier(config *GraphConfig) (*GraphNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Graph config is required")
+	}
+
+	if config.TenantID == "" || config.ClientID == "" || config.ClientSecret == "" {
+		return nil, fmt.Errorf("Graph tenant_id, client_id and client_secret are required")
+	}
+
+	if config.From == "" {
+		return nil, fmt.Errorf("Graph from mailbox is required")
+	}
+
+	if config.APIURL == "" {
+		config.APIURL = "https://graph.microsoft.com/v1.0"
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	if config.TokenURL == "" {
+		config.TokenURL = fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", url.PathEscape(config.TenantID))
+	}
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	credentials := &clientcredentials.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		TokenURL:     config.TokenURL,
+		Scopes:       []string{graphScope},
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+
+	base, err := newBaseNotifier(domain.TypeEmail, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GraphNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient:   httpClient,
+		tokens:       oauth2.ReuseTokenSource(nil, credentials.TokenSource(tokenCtx)),
+	}, nil
+}
+
+// Validate checks the notification can be sent as email, with the same rules as SMTP
+func (g *GraphNotifier) Validate(notification *domain.Notification) error {
+	if notification == nil {
+		return fmt.Errorf("notification is nil")
+	}
+
+	// For email, we need at least one recipient (To, CC, or BCC)
+	if len(notification.Recipients)+len(notification.CC)+len(notification.BCC) == 0 {
+		return fmt.Errorf("email has no recipients (To, CC, or BCC required)")
+	}
+
+	if notification.Type != g.Type() {
+		return fmt.Errorf("notification type mismatch: expected %s, got %s", g.Type(), notification.Type)
+	}
+
+	if notification.Subject == "" {
+		return fmt.Errorf("email subject is required")
+	}
+
+	if notification.Body == "" {
+		return fmt.Errorf("email body is required")
+	}
+
+	return nil
+}
+
+// Send sends the notification as one email from the configured mailbox
+func (g *GraphNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := g.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = g.withSentFooter(notification)
+
+	request, err := g.buildRequest(notification)
+	if err == nil {
+		err = g.sendMail(ctx, request)
+	}
+	if err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Email sent via Microsoft Graph to %d recipients", len(notification.Recipients)+len(notification.CC)+len(notification.BCC)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"from": g.config.From,
+		},
+	}, nil
+}
+
+// buildRequest constructs the sendMail request. Graph messages have a single body, so HTML
+// email is sent as HTML without the plain-text alternative SMTP includes.
+func (g *GraphNotifier) buildRequest(notification *domain.Notification) (*graphSendMailRequest, error) {
+	body := graphItemBody{ContentType: "Text", Content: notification.Body}
+	switch {
+	case notification.HTMLBody != "":
+		body = graphItemBody{ContentType: "HTML", Content: notification.HTMLBody}
+	case isHTMLContent(notification):
+		body.ContentType = "HTML"
+	}
+
+	message := graphMessage{
+		Subject:       notification.Subject,
+		Body:          body,
+		ToRecipients:  graphRecipients(notification.Recipients),
+		CcRecipients:  graphRecipients(notification.CC),
+		BccRecipients: graphRecipients(notification.BCC),
+	}
+	if notification.ReplyTo != "" {
+		message.ReplyTo = graphRecipients([]string{notification.ReplyTo})
+	}
+
+	// Graph only accepts custom headers, which must start with "X-"
+	if notification.Options != nil && notification.Options.Email != nil {
+		for name, value := range notification.Options.Email.Headers {
+			if !strings.HasPrefix(strings.ToLower(name), "x-") {
+				return nil, fmt.Errorf("Microsoft Graph only sends custom headers starting with X-: %s", name)
+			}
+			message.InternetMessageHeaders = append(message.InternetMessageHeaders, graphHeader{Name: name, Value: value})
+		}
+		sort.Slice(message.InternetMessageHeaders, func(i, j int) bool {
+			return message.InternetMessageHeaders[i].Name < message.InternetMessageHeaders[j].Name
+		})
+	}
+
+	return &graphSendMailRequest{Message: message, SaveToSentItems: g.config.SaveToSentItems}, nil
+}
+
+// graphRecipients converts addresses to Graph recipients
+func graphRecipients(addresses []string) []graphRecipient {
+	if len(addresses) == 0 {
+		return nil
+	}
+	recipients := make([]graphRecipient, 0, len(addresses))
+	for _, address := range addresses {
+		recipients = append(recipients, graphRecipient{EmailAddress: graphEmailAddress{Address: address}})
+	}
+	return recipients
+}
+
+// sendMail posts the request to the sending mailbox's sendMail action
+func (g *GraphNotifier) sendMail(ctx context.Context, request *graphSendMailRequest) error {
+	token, err := g.tokens.Token()
+	if err != nil {
+		return fmt.Errorf("failed to obtain Microsoft Graph access token: %w", err)
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Graph message: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/users/%s/sendMail", g.config.APIURL, url.PathEscape(g.config.From))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	token.SetAuthHeader(req)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send Graph email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// sendMail returns 202 Accepted with no body
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp graphErrorResponse
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Code != "" {
+			return newStatusCodeError(resp.StatusCode, "Microsoft Graph API returned status %d: %s: %s", resp.StatusCode, errResp.Error.Code, errResp.Error.Message)
+		}
+		return newStatusCodeError(resp.StatusCode, "Microsoft Graph API returned status: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// MetadataKeys reports that the Graph notifier reads no metadata
+func (g *GraphNotifier) MetadataKeys() []string {
+	return nil
+}
+
+// Close closes the HTTP client
+func (g *GraphNotifier) Close() error {
+	g.httpClient.CloseIdleConnections()
+	return nil
+}
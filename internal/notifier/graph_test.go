@@ -0,0 +1,182 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// newGraphTestServer serves the token endpoint and the sendMail action, recording the
+// sendMail request
+func newGraphTestServer(t *testing.T, received *graphSendMailRequest, path *string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			r.ParseForm()
+			if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("client_secret") != "secret" {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			if r.PostForm.Get("scope") != graphScope {
+				t.Errorf("Expected scope %s, got %s", graphScope, r.PostForm.Get("scope"))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"graph-token","token_type":"Bearer","expires_in":3600}`))
+		case strings.HasSuffix(r.URL.Path, "/sendMail"):
+			if r.Header.Get("Authorization") != "Bearer graph-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":{"code":"InvalidAuthenticationToken","message":"Access token is empty."}}`))
+				return
+			}
+			*path = r.URL.Path
+			if err := json.NewDecoder(r.Body).Decode(received); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// TestGraphSend tests that an email is sent from the configured mailbox with a bearer token
+func TestGraphSend(t *testing.T) {
+	var received graphSendMailRequest
+	var path string
+	server := newGraphTestServer(t, &received, &path)
+	defer server.Close()
+
+	graph, err := NewGraphNotifier(&GraphConfig{
+		TenantID:     "contoso",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		From:         "alerts@contoso.com",
+		APIURL:       server.URL + "/v1.0",
+		TokenURL:     server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Graph notifier: %v", err)
+	}
+
+	result, err := graph.Send(context.Background(), &domain.Notification{
+		ID:         "graph-1",
+		Type:       domain.TypeEmail,
+		Subject:    "Disk almost full",
+		Body:       "Disk is 95% full",
+		HTMLBody:   "<p>Disk is <b>95%</b> full</p>",
+		Recipients: []string{"ops@contoso.com"},
+		CC:         []string{"lead@contoso.com"},
+		ReplyTo:    "noc@contoso.com",
+		Options: &domain.SendOptions{Email: &domain.EmailOptions{
+			Headers: map[string]string{"X-Ticket": "42"},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if path != "/v1.0/users/alerts@contoso.com/sendMail" {
+		t.Errorf("Unexpected sendMail path: %s", path)
+	}
+	message := received.Message
+	if message.Subject != "Disk almost full" {
+		t.Errorf("Expected subject, got %q", message.Subject)
+	}
+	if message.Body.ContentType != "HTML" || !strings.Contains(message.Body.Content, "<b>95%</b>") {
+		t.Errorf("Expected HTML body, got %+v", message.Body)
+	}
+	if len(message.ToRecipients) != 1 || message.ToRecipients[0].EmailAddress.Address != "ops@contoso.com" {
+		t.Errorf("Unexpected to recipients: %+v", message.ToRecipients)
+	}
+	if len(message.CcRecipients) != 1 || message.CcRecipients[0].EmailAddress.Address != "lead@contoso.com" {
+		t.Errorf("Unexpected cc recipients: %+v", message.CcRecipients)
+	}
+	if len(message.ReplyTo) != 1 || message.ReplyTo[0].EmailAddress.Address != "noc@contoso.com" {
+		t.Errorf("Unexpected reply-to: %+v", message.ReplyTo)
+	}
+	if len(message.InternetMessageHeaders) != 1 || message.InternetMessageHeaders[0].Name != "X-Ticket" {
+		t.Errorf("Unexpected headers: %+v", message.InternetMessageHeaders)
+	}
+}
+
+// TestGraphSendErrors tests that bad credentials and non-custom headers fail the send
+func TestGraphSendErrors(t *testing.T) {
+	var received graphSendMailRequest
+	var path string
+	server := newGraphTestServer(t, &received, &path)
+	defer server.Close()
+
+	notification := &domain.Notification{
+		ID:         "graph-2",
+		Type:       domain.TypeEmail,
+		Subject:    "Test",
+		Body:       "Test",
+		Recipients: []string{"ops@contoso.com"},
+	}
+
+	badSecret, err := NewGraphNotifier(&GraphConfig{
+		TenantID:     "contoso",
+		ClientID:     "client",
+		ClientSecret: "wrong",
+		From:         "alerts@contoso.com",
+		APIURL:       server.URL,
+		TokenURL:     server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Graph notifier: %v", err)
+	}
+	if result, err := badSecret.Send(context.Background(), notification); err == nil || result.Success {
+		t.Error("Expected send with a bad client secret to fail")
+	}
+
+	graph, err := NewGraphNotifier(&GraphConfig{
+		TenantID:     "contoso",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		From:         "alerts@contoso.com",
+		APIURL:       server.URL,
+		TokenURL:     server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Graph notifier: %v", err)
+	}
+	notification.Options = &domain.SendOptions{Email: &domain.EmailOptions{
+		Headers: map[string]string{"List-Unsubscribe": "<mailto:unsubscribe@contoso.com>"},
+	}}
+	if _, err := graph.Send(context.Background(), notification); err == nil || !strings.Contains(err.Error(), "X-") {
+		t.Errorf("Expected error for a non-custom header, got %v", err)
+	}
+	if path != "" {
+		t.Error("Expected no sendMail request for a rejected header")
+	}
+}
+
+// TestNewGraphNotifierRequiresCredentials tests that tenant, client and mailbox are required
+func TestNewGraphNotifierRequiresCredentials(t *testing.T) {
+	if _, err := NewGraphNotifier(&GraphConfig{TenantID: "contoso", ClientID: "client", From: "a@contoso.com"}); err == nil {
+		t.Error("Expected error without a client secret")
+	}
+	if _, err := NewGraphNotifier(&GraphConfig{TenantID: "contoso", ClientID: "client", ClientSecret: "secret"}); err == nil {
+		t.Error("Expected error without a from mailbox")
+	}
+
+	graph, err := NewGraphNotifier(&GraphConfig{TenantID: "contoso", ClientID: "client", ClientSecret: "secret", From: "a@contoso.com"})
+	if err != nil {
+		t.Fatalf("Failed to create Graph notifier: %v", err)
+	}
+	if graph.config.TokenURL != "https://login.microsoftonline.com/contoso/oauth2/v2.0/token" {
+		t.Errorf("Unexpected default token URL: %s", graph.config.TokenURL)
+	}
+	if graph.Type() != domain.TypeEmail {
+		t.Errorf("Expected email type, got %s", graph.Type())
+	}
+}
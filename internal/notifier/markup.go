@@ -0,0 +1,340 @@
+package notifier
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// MarkupConfig controls how :emoji: shortcodes and basic markdown in a notification are
+// adapted to what an account's channel can display. Empty values use the channel's default.
+type MarkupConfig struct {
+	// Emoji handles :shortcode: emoji: keep, convert (to Unicode) or strip
+	Emoji string `mapstructure:"emoji"`
+
+	// Markdown handles **bold**, *italic*, ~~strike~~, `code`, [links](url), headings and
+	// bullets: keep, convert (to the channel's own formatting) or strip
+	Markdown string `mapstructure:"markdown"`
+}
+
+// Markup modes
+const (
+	MarkupKeep    = "keep"
+	MarkupConvert = "convert"
+	MarkupStrip   = "strip"
+)
+
+// markupNormalizer applies an account's markup modes to outgoing notifications
+type markupNormalizer struct {
+	emoji    string
+	markdown string
+	render   *markdownRenderer // Converts markdown to the channel's formatting; nil when the channel renders markdown itself
+}
+
+// newMarkupNormalizer validates an account's markup config, filling unset modes from the channel defaults
+func newMarkupNormalizer(config MarkupConfig, defaults MarkupConfig, render *markdownRenderer) (*markupNormalizer, error) {
+	m := &markupNormalizer{emoji: defaults.Emoji, markdown: defaults.Markdown, render: render}
+	if config.Emoji != "" {
+		m.emoji = strings.ToLower(config.Emoji)
+	}
+	if config.Markdown != "" {
+		m.markdown = strings.ToLower(config.Markdown)
+	}
+
+	for name, mode := range map[string]string{"emoji": m.emoji, "markdown": m.markdown} {
+		if mode != MarkupKeep && mode != MarkupConvert && mode != MarkupStrip {
+			return nil, fmt.Errorf("invalid markup %s mode: %s (must be keep, convert or strip)", name, mode)
+		}
+	}
+	return m, nil
+}
+
+// normalize returns a copy of the notification with its emoji and markdown adapted to the
+// channel. Subjects are plain text, so their markdown is stripped unless it is kept.
+// HTML bodies are left alone apart from emoji.
+func (m *markupNormalizer) normalize(notification *domain.Notification) *domain.Notification {
+	n := *notification
+	n.Subject = m.normalizeEmoji(n.Subject)
+	n.Body = m.normalizeEmoji(n.Body)
+	if n.HTMLBody != "" {
+		n.HTMLBody = m.normalizeEmoji(n.HTMLBody)
+	}
+
+	if m.markdown == MarkupKeep {
+		return &n
+	}
+	n.Subject = renderMarkdown(n.Subject, plainMarkdown)
+	if isHTMLContent(&n) {
+		return &n
+	}
+
+	switch {
+	case m.markdown == MarkupStrip:
+		n.Body = renderMarkdown(n.Body, plainMarkdown)
+	case m.render != nil:
+		n.Body = renderMarkdown(n.Body, m.render)
+	}
+	return &n
+}
+
+// normalizeEmoji converts or strips the known shortcodes in s
+func (m *markupNormalizer) normalizeEmoji(s string) string {
+	if m.emoji == MarkupKeep || !strings.Contains(s, ":") {
+		return s
+	}
+	return emojiShortcodePattern.ReplaceAllStringFunc(s, func(code string) string {
+		emoji, ok := emojiShortcodes[code[1:len(code)-1]]
+		if !ok {
+			return code
+		}
+		if m.emoji == MarkupStrip {
+			return ""
+		}
+		return emoji
+	})
+}
+
+// markdownRenderer renders each markdown element in a target format
+type markdownRenderer struct {
+	escape func(string) string // Applied to the whole text before rendering (optional)
+	bold   func(string) string
+	italic func(string) string
+	strike func(string) string
+	code   func(string) string
+	link   func(text, url string) string
+	bullet string              // Replaces "- " and "* " at the start of a line ("" leaves bullets alone)
+	lines  func(string) string // Applied to the rendered text, e.g. to wrap paragraphs (optional)
+}
+
+// plainMarkdown removes markdown syntax, keeping link targets
+var plainMarkdown = &markdownRenderer{
+	bold:   func(s string) string { return s },
+	italic: func(s string) string { return s },
+	strike: func(s string) string { return s },
+	code:   func(s string) string { return s },
+	link:   plainLink,
+}
+
+// slackMarkdown converts markdown to Slack mrkdwn
+var slackMarkdown = &markdownRenderer{
+	bold:   func(s string) string { return "*" + s + "*" },
+	italic: func(s string) string { return "_" + s + "_" },
+	strike: func(s string) string { return "~" + s + "~" },
+	code:   func(s string) string { return "`" + s + "`" },
+	link:   func(text, url string) string { return "<" + url + "|" + text + ">" },
+	bullet: "• ",
+}
+
+// htmlMarkdown converts markdown to HTML, for the HTML part of an email
+var htmlMarkdown = &markdownRenderer{
+	escape: html.EscapeString,
+	bold:   func(s string) string { return "<strong>" + s + "</strong>" },
+	italic: func(s string) string { return "<em>" + s + "</em>" },
+	strike: func(s string) string { return "<s>" + s + "</s>" },
+	code:   func(s string) string { return "<code>" + s + "</code>" },
+	link:   func(text, url string) string { return `<a href="` + url + `">` + text + "</a>" },
+	bullet: "• ",
+	lines: func(s string) string {
+		paragraphs := strings.Split(strings.TrimSpace(s), "\n\n")
+		for i, p := range paragraphs {
+			paragraphs[i] = "<p>" + strings.ReplaceAll(strings.TrimSpace(p), "\n", "<br>\n") + "</p>"
+		}
+		return strings.Join(paragraphs, "\n")
+	},
+}
+
+// plainLink renders a link as its text followed by its target
+func plainLink(text, url string) string {
+	if text == url {
+		return url
+	}
+	return text + " (" + url + ")"
+}
+
+var (
+	emojiShortcodePattern = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+	mdCodePattern         = regexp.MustCompile("`([^`\n]+)`")
+	mdLinkPattern         = regexp.MustCompile(`\[([^\]\n]+)\]\(([^)\s]+)\)`)
+	mdHeadingPattern      = regexp.MustCompile(`(?m)^#{1,6}[ \t]+(.+?)[ \t#]*$`)
+	mdBulletPattern       = regexp.MustCompile(`(?m)^([ \t]*)[-*][ \t]+`)
+	mdBoldPattern         = regexp.MustCompile(`\*\*([^*\n]+)\*\*|__([^_\n]+)__`)
+	mdStrikePattern       = regexp.MustCompile(`~~([^~\n]+)~~`)
+	mdItalicStarPattern   = regexp.MustCompile(`\*([^*\s][^*\n]*)\*`)
+	mdItalicUnderPattern  = regexp.MustCompile(`(^|[^\w])_([^_\s][^_\n]*)_($|[^\w])`)
+	mdPlaceholderPattern  = regexp.MustCompile("\x00([0-9]+)\x00")
+	mdBoldSpan            = regexp.MustCompile(mdBold + "([^" + mdBold + "]*)" + mdBold)
+	mdItalicSpan          = regexp.MustCompile(mdItalic + "([^" + mdItalic + "]*)" + mdItalic)
+	mdStrikeSpan          = regexp.MustCompile(mdStrike + "([^" + mdStrike + "]*)" + mdStrike)
+)
+
+// Sentinels mark rendered elements until nested markdown inside them has been handled
+const (
+	mdBold   = "\x01"
+	mdItalic = "\x02"
+	mdStrike = "\x03"
+)
+
+// renderMarkdown renders the basic markdown in s with the renderer. Code spans and links are
+// set aside first so nothing inside them is treated as markdown.
+func renderMarkdown(s string, r *markdownRenderer) string {
+	if r.escape != nil {
+		s = r.escape(s)
+	}
+
+	var held []string
+	hold := func(rendered string) string {
+		held = append(held, rendered)
+		return fmt.Sprintf("\x00%d\x00", len(held)-1)
+	}
+
+	s = mdCodePattern.ReplaceAllStringFunc(s, func(m string) string {
+		return hold(r.code(mdCodePattern.FindStringSubmatch(m)[1]))
+	})
+	s = mdLinkPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := mdLinkPattern.FindStringSubmatch(m)
+		return hold(r.link(parts[1], parts[2]))
+	})
+	s = mdHeadingPattern.ReplaceAllString(s, mdBold+"$1"+mdBold)
+	if r.bullet != "" {
+		s = mdBulletPattern.ReplaceAllString(s, "${1}"+r.bullet)
+	}
+	s = mdBoldPattern.ReplaceAllString(s, mdBold+"$1$2"+mdBold)
+	s = mdStrikePattern.ReplaceAllString(s, mdStrike+"$1"+mdStrike)
+	s = mdItalicStarPattern.ReplaceAllString(s, mdItalic+"$1"+mdItalic)
+	s = mdItalicUnderPattern.ReplaceAllString(s, "$1"+mdItalic+"$2"+mdItalic+"$3")
+
+	for _, span := range []struct {
+		pattern *regexp.Regexp
+		render  func(string) string
+	}{{mdBoldSpan, r.bold}, {mdItalicSpan, r.italic}, {mdStrikeSpan, r.strike}} {
+		s = span.pattern.ReplaceAllStringFunc(s, func(m string) string {
+			return span.render(m[1 : len(m)-1])
+		})
+	}
+
+	s = mdPlaceholderPattern.ReplaceAllStringFunc(s, func(m string) string {
+		i, _ := strconv.Atoi(m[1 : len(m)-1])
+		return held[i]
+	})
+
+	if r.lines != nil {
+		s = r.lines(s)
+	}
+	return s
+}
+
+// emojiShortcodes maps common :shortcode: names to their Unicode emoji
+var emojiShortcodes = map[string]string{
+	"+1":                         "👍",
+	"-1":                         "👎",
+	"thumbsup":                   "👍",
+	"thumbsdown":                 "👎",
+	"white_check_mark":           "✅",
+	"heavy_check_mark":           "✔️",
+	"x":                          "❌",
+	"warning":                    "⚠️",
+	"rotating_light":             "🚨",
+	"fire":                       "🔥",
+	"boom":                       "💥",
+	"bug":                        "🐛",
+	"rocket":                     "🚀",
+	"tada":                       "🎉",
+	"sparkles":                   "✨",
+	"bell":                       "🔔",
+	"no_bell":                    "🔕",
+	"mega":                       "📣",
+	"loudspeaker":                "📢",
+	"information_source":         "ℹ️",
+	"question":                   "❓",
+	"exclamation":                "❗",
+	"heavy_exclamation_mark":     "❗",
+	"bangbang":                   "‼️",
+	"no_entry":                   "⛔",
+	"stop_sign":                  "🛑",
+	"construction":               "🚧",
+	"hourglass":                  "⌛",
+	"hourglass_flowing_sand":     "⏳",
+	"alarm_clock":                "⏰",
+	"stopwatch":                  "⏱️",
+	"clock":                      "🕒",
+	"calendar":                   "📆",
+	"date":                       "📅",
+	"lock":                       "🔒",
+	"unlock":                     "🔓",
+	"key":                        "🔑",
+	"shield":                     "🛡️",
+	"wrench":                     "🔧",
+	"hammer":                     "🔨",
+	"gear":                       "⚙️",
+	"package":                    "📦",
+	"inbox_tray":                 "📥",
+	"outbox_tray":                "📤",
+	"email":                      "📧",
+	"envelope":                   "✉️",
+	"memo":                       "📝",
+	"pencil":                     "📝",
+	"page_facing_up":             "📄",
+	"clipboard":                  "📋",
+	"link":                       "🔗",
+	"mag":                        "🔍",
+	"chart_with_upwards_trend":   "📈",
+	"chart_with_downwards_trend": "📉",
+	"bar_chart":                  "📊",
+	"moneybag":                   "💰",
+	"dollar":                     "💵",
+	"credit_card":                "💳",
+	"shopping_cart":              "🛒",
+	"truck":                      "🚚",
+	"house":                      "🏠",
+	"computer":                   "💻",
+	"iphone":                     "📱",
+	"floppy_disk":                "💾",
+	"cd":                         "💿",
+	"cloud":                      "☁️",
+	"zap":                        "⚡",
+	"sunny":                      "☀️",
+	"umbrella":                   "☔",
+	"snowflake":                  "❄️",
+	"star":                       "⭐",
+	"heart":                      "❤️",
+	"broken_heart":               "💔",
+	"eyes":                       "👀",
+	"wave":                       "👋",
+	"clap":                       "👏",
+	"pray":                       "🙏",
+	"muscle":                     "💪",
+	"ok_hand":                    "👌",
+	"point_right":                "👉",
+	"smile":                      "😄",
+	"smiley":                     "😃",
+	"grinning":                   "😀",
+	"slightly_smiling_face":      "🙂",
+	"wink":                       "😉",
+	"thinking_face":              "🤔",
+	"confused":                   "😕",
+	"cry":                        "😢",
+	"scream":                     "😱",
+	"skull":                      "💀",
+	"robot_face":                 "🤖",
+	"ghost":                      "👻",
+	"green_circle":               "🟢",
+	"yellow_circle":              "🟡",
+	"orange_circle":              "🟠",
+	"red_circle":                 "🔴",
+	"large_blue_circle":          "🔵",
+	"white_circle":               "⚪",
+	"black_circle":               "⚫",
+	"arrow_up":                   "⬆️",
+	"arrow_down":                 "⬇️",
+	"arrow_right":                "➡️",
+	"arrow_left":                 "⬅️",
+	"arrows_counterclockwise":    "🔄",
+	"recycle":                    "♻️",
+	"100":                        "💯",
+	"new":                        "🆕",
+	"sos":                        "🆘",
+}
@@ -0,0 +1,85 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestRenderMarkdown tests converting and stripping basic markdown
+func TestRenderMarkdown(t *testing.T) {
+	input := "# Deploy finished\n**prod** is _live_, see [the dashboard](https://example.com/d?a=1&b=2).\n- ~~rollback~~ not needed\n- run `make *all*`"
+
+	tests := []struct {
+		name   string
+		render *markdownRenderer
+		want   string
+	}{
+		{"plain", plainMarkdown,
+			"Deploy finished\nprod is live, see the dashboard (https://example.com/d?a=1&b=2).\n- rollback not needed\n- run make *all*"},
+		{"slack", slackMarkdown,
+			"*Deploy finished*\n*prod* is _live_, see <https://example.com/d?a=1&b=2|the dashboard>.\n• ~rollback~ not needed\n• run `make *all*`"},
+		{"html", htmlMarkdown,
+			"<p><strong>Deploy finished</strong><br>\n<strong>prod</strong> is <em>live</em>, see <a href=\"https://example.com/d?a=1&amp;b=2\">the dashboard</a>.<br>\n" +
+				"• <s>rollback</s> not needed<br>\n• run <code>make *all*</code></p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderMarkdown(input, tt.render); got != tt.want {
+				t.Errorf("renderMarkdown() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	// Underscores inside words and lone asterisks aren't markdown
+	for _, text := range []string{"set max_retry_count to 5", "2 * 3 * 4", "user_id_"} {
+		if got := renderMarkdown(text, plainMarkdown); got != text {
+			t.Errorf("renderMarkdown(%q) = %q, want it unchanged", text, got)
+		}
+	}
+}
+
+// TestMarkupNormalize tests emoji and markdown modes and their channel defaults
+func TestMarkupNormalize(t *testing.T) {
+	notification := &domain.Notification{
+		Subject: ":rotating_light: **Disk** full",
+		Body:    "Backup at 10:30:00 failed :x: on *db-1* :custom_party:",
+	}
+
+	slack, err := newMarkupNormalizer(MarkupConfig{}, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupConvert}, slackMarkdown)
+	if err != nil {
+		t.Fatalf("newMarkupNormalizer() error = %v", err)
+	}
+	got := slack.normalize(notification)
+	if got.Subject != ":rotating_light: Disk full" || got.Body != "Backup at 10:30:00 failed :x: on _db-1_ :custom_party:" {
+		t.Errorf("Unexpected Slack normalization: %q / %q", got.Subject, got.Body)
+	}
+	if notification.Subject != ":rotating_light: **Disk** full" {
+		t.Error("normalize modified the original notification")
+	}
+
+	// An account can override the channel defaults
+	stripped, err := newMarkupNormalizer(MarkupConfig{Emoji: "strip", Markdown: "STRIP"}, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupConvert}, slackMarkdown)
+	if err != nil {
+		t.Fatalf("newMarkupNormalizer() error = %v", err)
+	}
+	got = stripped.normalize(notification)
+	if got.Subject != " Disk full" || got.Body != "Backup at 10:30:00 failed  on db-1 :custom_party:" {
+		t.Errorf("Unexpected stripped normalization: %q / %q", got.Subject, got.Body)
+	}
+
+	converted, err := newMarkupNormalizer(MarkupConfig{Emoji: "convert"}, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupKeep}, nil)
+	if err != nil {
+		t.Fatalf("newMarkupNormalizer() error = %v", err)
+	}
+	got = converted.normalize(notification)
+	if got.Subject != "🚨 **Disk** full" || !strings.Contains(got.Body, "failed ❌ on *db-1*") {
+		t.Errorf("Unexpected converted normalization: %q / %q", got.Subject, got.Body)
+	}
+
+	if _, err := newMarkupNormalizer(MarkupConfig{Markdown: "html"}, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupKeep}, nil); err == nil {
+		t.Error("Expected an invalid markdown mode to be rejected")
+	}
+}
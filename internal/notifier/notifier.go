@@ -123,6 +123,8 @@ func (f *Factory) GetAccounts(notificationType domain.NotificationType) []string
 // BaseNotifier provides common functionality for all notifiers
 type BaseNotifier struct {
 	notificationType domain.NotificationType
+	display          *TimestampFormatter // Renders timestamps for the account's recipients; nil uses UTC
+	footer           bool                // Append a "Sent <timestamp>" footer to messages
 }
 
 // Type returns the notification type
@@ -3,15 +3,20 @@ package notifier
 import (
 	"bytes"
 	"context"
+	"crypto/ecdh"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/signing"
+	"github.com/igodwin/notifier/pkg/e2e"
 )
 
 // NtfyConfig contains ntfy.sh configuration
@@ -37,6 +42,22 @@ type NtfyConfig struct {
 	// If not specified, system default CA certificates are used.
 	CACertPath string `mapstructure:"ca_cert_path"`
 
+	// Display controls timestamp rendering for recipients (timezone, locale, sent footer)
+	Display DisplayConfig `mapstructure:"display"`
+
+	// Markup controls emoji shortcode and markdown handling (default: convert both; markdown is rendered by ntfy clients)
+	Markup MarkupConfig `mapstructure:"markup"`
+
+	// Backup is a warm spare server used when the primary rejects our credentials or is unreachable (optional)
+	Backup *NtfyBackupConfig `mapstructure:"backup"`
+
+	// Encryption seals message content to per-topic public keys so the ntfy server can't read it (optional)
+	Encryption *NtfyEncryptionConfig `mapstructure:"encryption"`
+
+	// SignPayloads adds HMAC signature headers to published messages so a verifying
+	// proxy or self-hosted server can confirm the notifier sent them
+	SignPayloads bool `mapstructure:"sign_payloads"`
+
 	// Default marks this instance as default
 	Default bool `mapstructure:"default"`
 
@@ -44,11 +65,45 @@ type NtfyConfig struct {
 	AllowedRoles []string `mapstructure:"allowed_roles"`
 }
 
+// NtfyBackupConfig is a secondary ntfy server and credentials
+type NtfyBackupConfig struct {
+	ServerURL     string `mapstructure:"server_url"`
+	Token         string `mapstructure:"token"`
+	Username      string `mapstructure:"username"`
+	Password      string `mapstructure:"password"`
+	ProbeInterval string `mapstructure:"probe_interval"` // How often to retry the primary while failed over (default: 5m)
+}
+
+// NtfyEncryptionConfig configures end-to-end encryption of published messages.
+// Subscribers decrypt with their private key using pkg/e2e (or "client decrypt").
+type NtfyEncryptionConfig struct {
+	// TopicKeys maps topics to base64 X25519 public keys (generate with "client keygen")
+	TopicKeys map[string]string `mapstructure:"topic_keys"`
+
+	// PlaceholderTitle replaces the title on encrypted messages (default: "Encrypted notification")
+	PlaceholderTitle string `mapstructure:"placeholder_title"`
+
+	// Require refuses to publish to topics without a key instead of sending plaintext
+	Require bool `mapstructure:"require"`
+}
+
+// ntfyEndpoint is a server and the credentials used to publish to it
+type ntfyEndpoint struct {
+	serverURL string
+	token     string
+	username  string
+	password  string
+}
+
 // NtfyNotifier sends notifications via ntfy.sh
 type NtfyNotifier struct {
 	BaseNotifier
 	config     *NtfyConfig
 	httpClient *http.Client
+	markup     *markupNormalizer
+	failover   *failover
+	signer     *signing.Keyring
+	topicKeys  map[string]*ecdh.PublicKey
 }
 
 // ntfyRequest represents the ntfy API request format
@@ -64,6 +119,7 @@ type ntfyRequest struct {
 	Icon     string       `json:"icon,omitempty"`
 	Delay    string       `json:"delay,omitempty"`
 	Email    string       `json:"email,omitempty"`
+	Markdown bool         `json:"markdown,omitempty"`
 }
 
 // ntfyAction represents an action button in ntfy
@@ -96,12 +152,45 @@ func NewNtfyNotifier(config *NtfyConfig) (*NtfyNotifier, error) {
 		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
 	}
 
+	base, err := newBaseNotifier(domain.TypeNtfy, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	markup, err := newMarkupNormalizer(config.Markup, MarkupConfig{Emoji: MarkupConvert, Markdown: MarkupConvert}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ntfy markup config: %w", err)
+	}
+
+	var fo *failover
+	if config.Backup != nil {
+		if config.Backup.ServerURL == "" {
+			return nil, fmt.Errorf("ntfy backup server URL is required")
+		}
+		if fo, err = newFailover(config.Backup.ProbeInterval); err != nil {
+			return nil, fmt.Errorf("invalid ntfy backup config: %w", err)
+		}
+	}
+
+	var topicKeys map[string]*ecdh.PublicKey
+	if config.Encryption != nil {
+		topicKeys = make(map[string]*ecdh.PublicKey, len(config.Encryption.TopicKeys))
+		for topic, encoded := range config.Encryption.TopicKeys {
+			key, err := e2e.ParsePublicKey(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ntfy encryption key for topic %s: %w", topic, err)
+			}
+			topicKeys[topic] = key
+		}
+	}
+
 	return &NtfyNotifier{
-		BaseNotifier: BaseNotifier{
-			notificationType: domain.TypeNtfy,
-		},
-		config:     config,
-		httpClient: httpClient,
+		BaseNotifier: base,
+		config:       config,
+		httpClient:   httpClient,
+		markup:       markup,
+		failover:     fo,
+		topicKeys:    topicKeys,
 	}, nil
 }
 
@@ -205,18 +294,26 @@ func (n *NtfyNotifier) Send(ctx context.Context, notification *domain.Notificati
 		return nil, err
 	}
 
+	notification = n.markup.normalize(notification)
+
 	// For ntfy, recipients are topics
 	recipients := notification.Recipients
 	if len(recipients) == 0 && n.config.DefaultTopic != "" {
 		recipients = []string{n.config.DefaultTopic}
 	}
 
+	message := appendTextFooter(notification.Body, n.sentFooter(time.Now()))
+	server := n.config.ServerURL
+	var encryptedTopics []string
+	links := make(map[string]string, len(recipients))
+
 	for _, topic := range recipients {
 		req := ntfyRequest{
 			Topic:    topic,
-			Message:  notification.Body,
+			Message:  message,
 			Title:    notification.Subject,
 			Priority: n.mapPriority(notification.Priority),
+			Markdown: n.markup.markdown == MarkupConvert,
 		}
 
 		// Add custom tags from metadata
@@ -253,6 +350,20 @@ func (n *NtfyNotifier) Send(ctx context.Context, notification *domain.Notificati
 			req.Email = email
 		}
 
+		// Options take precedence over the equivalent metadata
+		if notification.Options != nil && notification.Options.Ntfy != nil {
+			options := notification.Options.Ntfy
+			if len(options.Tags) > 0 {
+				req.Tags = options.Tags
+			}
+			if options.Click != "" {
+				req.Click = options.Click
+			}
+			if options.Delay != "" {
+				req.Delay = options.Delay
+			}
+		}
+
 		// Add actions from metadata
 		if actions, ok := notification.Metadata["actions"].([]interface{}); ok {
 			for _, action := range actions {
@@ -278,7 +389,8 @@ func (n *NtfyNotifier) Send(ctx context.Context, notification *domain.Notificati
 			}
 		}
 
-		if err := n.sendToTopic(ctx, &req); err != nil {
+		encrypted, err := n.encrypt(&req, notification)
+		if err != nil {
 			return &domain.NotificationResult{
 				NotificationID: notification.ID,
 				Success:        false,
@@ -286,6 +398,23 @@ func (n *NtfyNotifier) Send(ctx context.Context, notification *domain.Notificati
 				SentAt:         time.Now(),
 			}, err
 		}
+		if encrypted {
+			encryptedTopics = append(encryptedTopics, topic)
+		}
+
+		usedBackup, err := n.publish(ctx, &req)
+		if usedBackup {
+			server = n.config.Backup.ServerURL
+		}
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		links[topic] = strings.TrimSuffix(server, "/") + "/" + url.PathEscape(topic)
 	}
 
 	return &domain.NotificationResult{
@@ -294,15 +423,88 @@ func (n *NtfyNotifier) Send(ctx context.Context, notification *domain.Notificati
 		Message:        fmt.Sprintf("Notification sent to %d topics", len(notification.Recipients)),
 		SentAt:         time.Now(),
 		ProviderResponse: map[string]interface{}{
-			"server": n.config.ServerURL,
-			"topics": notification.Recipients,
+			"server":           server,
+			"topics":           notification.Recipients,
+			"encrypted_topics": encryptedTopics,
 		},
+		Links: links,
 	}, nil
 }
 
+// encrypt seals the title and message to the topic's public key (or the notification's
+// e2e_public_key metadata) and reports whether the request was encrypted
+func (n *NtfyNotifier) encrypt(req *ntfyRequest, notification *domain.Notification) (bool, error) {
+	if n.config.Encryption == nil {
+		return false, nil
+	}
+
+	key := n.topicKey(req.Topic)
+	if encoded, ok := notification.Metadata[e2eKeyMetadata].(string); ok && encoded != "" {
+		metaKey, err := e2e.ParsePublicKey(encoded)
+		if err != nil {
+			return false, fmt.Errorf("invalid %s metadata: %w", e2eKeyMetadata, err)
+		}
+		key = metaKey
+	}
+
+	if key == nil {
+		if n.config.Encryption.Require {
+			return false, fmt.Errorf("no encryption key configured for ntfy topic: %s", req.Topic)
+		}
+		return false, nil
+	}
+
+	sealed, err := e2e.SealPayload(key, e2e.Payload{Title: req.Title, Message: req.Message})
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt ntfy message: %w", err)
+	}
+
+	req.Message = sealed
+	req.Title = n.config.Encryption.PlaceholderTitle
+	if req.Title == "" {
+		req.Title = defaultE2ETitle
+	}
+	req.Tags = append(req.Tags, "lock")
+	return true, nil
+}
+
+// topicKey returns the public key for a topic. Config map keys are lowercased when loaded,
+// so fall back to a case-insensitive match.
+func (n *NtfyNotifier) topicKey(topic string) *ecdh.PublicKey {
+	if key, ok := n.topicKeys[topic]; ok {
+		return key
+	}
+	return n.topicKeys[strings.ToLower(topic)]
+}
+
+// publish sends a notification to the primary server, failing over to the backup server
+// when the primary rejects our credentials or is unreachable. It reports whether the backup was used.
+func (n *NtfyNotifier) publish(ctx context.Context, req *ntfyRequest) (bool, error) {
+	primary := ntfyEndpoint{
+		serverURL: n.config.ServerURL,
+		token:     n.config.Token,
+		username:  n.config.Username,
+		password:  n.config.Password,
+	}
+	if n.failover == nil {
+		return false, n.sendToTopic(ctx, primary, req)
+	}
+
+	backup := ntfyEndpoint{
+		serverURL: n.config.Backup.ServerURL,
+		token:     n.config.Backup.Token,
+		username:  n.config.Backup.Username,
+		password:  n.config.Backup.Password,
+	}
+	return n.failover.run(
+		func() error { return n.sendToTopic(ctx, primary, req) },
+		func() error { return n.sendToTopic(ctx, backup, req) },
+	)
+}
+
 // sendToTopic sends a notification to a specific ntfy topic
-func (n *NtfyNotifier) sendToTopic(ctx context.Context, req *ntfyRequest) error {
-	url := fmt.Sprintf("%s", n.config.ServerURL)
+func (n *NtfyNotifier) sendToTopic(ctx context.Context, endpoint ntfyEndpoint, req *ntfyRequest) error {
+	url := fmt.Sprintf("%s", endpoint.serverURL)
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
@@ -316,11 +518,17 @@ func (n *NtfyNotifier) sendToTopic(ctx context.Context, req *ntfyRequest) error
 
 	httpReq.Header.Set("Content-Type", "application/json")
 
+	if n.signer != nil {
+		if err := n.signer.SignRequest(httpReq, jsonData); err != nil {
+			return fmt.Errorf("failed to sign ntfy payload: %w", err)
+		}
+	}
+
 	// Add authentication if configured
-	if n.config.Token != "" {
-		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", n.config.Token))
-	} else if n.config.Username != "" && n.config.Password != "" {
-		httpReq.SetBasicAuth(n.config.Username, n.config.Password)
+	if endpoint.token != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", endpoint.token))
+	} else if endpoint.username != "" && endpoint.password != "" {
+		httpReq.SetBasicAuth(endpoint.username, endpoint.password)
 	}
 
 	resp, err := n.httpClient.Do(httpReq)
@@ -330,7 +538,7 @@ func (n *NtfyNotifier) sendToTopic(ctx context.Context, req *ntfyRequest) error
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("ntfy server returned status: %d", resp.StatusCode)
+		return newStatusCodeError(resp.StatusCode, "ntfy server returned status: %d", resp.StatusCode)
 	}
 
 	return nil
@@ -352,6 +560,16 @@ func (n *NtfyNotifier) mapPriority(priority domain.Priority) int {
 	}
 }
 
+// SetSigner enables payload signing for published messages
+func (n *NtfyNotifier) SetSigner(signer *signing.Keyring) {
+	n.signer = signer
+}
+
+// MetadataKeys returns the metadata keys the ntfy notifier reads
+func (n *NtfyNotifier) MetadataKeys() []string {
+	return []string{"tags", "click", "attach", "icon", "delay", "email", "actions"}
+}
+
 // Close closes the HTTP client
 func (n *NtfyNotifier) Close() error {
 	n.httpClient.CloseIdleConnections()
@@ -0,0 +1,106 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/pkg/e2e"
+)
+
+// TestNtfyEncryptedPublish tests that messages to topics with a public key are sealed before publishing
+func TestNtfyEncryptedPublish(t *testing.T) {
+	publicKey, privateKey, err := e2e.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	var published []ntfyRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ntfyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		published = append(published, req)
+	}))
+	defer server.Close()
+
+	ntfy, err := NewNtfyNotifier(&NtfyConfig{
+		ServerURL: server.URL,
+		Encryption: &NtfyEncryptionConfig{
+			TopicKeys: map[string]string{"oncall-phone": publicKey},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ntfy notifier: %v", err)
+	}
+
+	result, err := ntfy.Send(context.Background(), &domain.Notification{
+		ID:         "e2e-1",
+		Type:       domain.TypeNtfy,
+		Subject:    "Customer data export",
+		Body:       "Export for acct 4411 is ready",
+		Recipients: []string{"oncall-phone", "public-status"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if len(published) != 2 {
+		t.Fatalf("Expected 2 published messages, got %d", len(published))
+	}
+
+	sealed := published[0]
+	if strings.Contains(sealed.Message, "4411") || sealed.Title == "Customer data export" {
+		t.Errorf("Expected encrypted topic to hide content, got %+v", sealed)
+	}
+	key, _ := e2e.ParsePrivateKey(privateKey)
+	payload, err := e2e.OpenPayload(key, sealed.Message)
+	if err != nil {
+		t.Fatalf("Subscriber failed to decrypt: %v", err)
+	}
+	if payload.Title != "Customer data export" || payload.Message != "Export for acct 4411 is ready" {
+		t.Errorf("Decrypted payload = %+v", payload)
+	}
+
+	// Topics without a key are published in plaintext unless encryption is required
+	if published[1].Message != "Export for acct 4411 is ready" {
+		t.Errorf("Expected plaintext for topic without key, got %q", published[1].Message)
+	}
+	if topics, _ := result.ProviderResponse["encrypted_topics"].([]string); len(topics) != 1 || topics[0] != "oncall-phone" {
+		t.Errorf("encrypted_topics = %v", result.ProviderResponse["encrypted_topics"])
+	}
+	if link := result.Links["oncall-phone"]; link != server.URL+"/oncall-phone" {
+		t.Errorf("Links[oncall-phone] = %q", link)
+	}
+}
+
+// TestNtfyEncryptionRequired tests that required encryption refuses topics without a key
+func TestNtfyEncryptionRequired(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected nothing to be published")
+	}))
+	defer server.Close()
+
+	ntfy, err := NewNtfyNotifier(&NtfyConfig{
+		ServerURL:  server.URL,
+		Encryption: &NtfyEncryptionConfig{Require: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create ntfy notifier: %v", err)
+	}
+
+	_, err = ntfy.Send(context.Background(), &domain.Notification{
+		ID:         "e2e-2",
+		Type:       domain.TypeNtfy,
+		Body:       "secret",
+		Recipients: []string{"no-key"},
+	})
+	if err == nil {
+		t.Error("Expected error for topic without an encryption key")
+	}
+}
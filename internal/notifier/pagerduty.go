@@ -0,0 +1,284 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// PagerDuty event actions
+const (
+	PagerDutyTrigger     = "trigger"
+	PagerDutyAcknowledge = "acknowledge"
+	PagerDutyResolve     = "resolve"
+)
+
+// PagerDutyConfig contains PagerDuty Events API v2 configuration
+type PagerDutyConfig struct {
+	RoutingKey   string            `mapstructure:"routing_key"`   // Integration key used when a recipient has no service mapping
+	Services     map[string]string `mapstructure:"services"`      // Service name -> routing key
+	APIURL       string            `mapstructure:"api_url"`       // Events API endpoint (default: https://events.pagerduty.com/v2/enqueue)
+	Source       string            `mapstructure:"source"`        // Affected system reported in the event (default: notifier)
+	Default      bool              `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string          `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig     `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// PagerDutyNotifier triggers, acknowledges and resolves PagerDuty incidents
+type PagerDutyNotifier struct {
+	BaseNotifier
+	config     *PagerDutyConfig
+	httpClient *http.Client
+}
+
+// pagerDutyEvent represents the Events API v2 request format
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // Only sent with trigger events
+}
+
+// pagerDutyPayload describes the incident for trigger events
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Group         string                 `json:"group,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutyResponse represents the Events API v2 response
+type pagerDutyResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message"`
+	DedupKey string   `json:"dedup_key"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// pagerDutyMaxSummary is the longest summary PagerDuty accepts
+const pagerDutyMaxSummary = 1024
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier
+func NewPagerDutyNotifier(config *PagerDutyConfig) (*PagerDutyNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("PagerDuty config is required")
+	}
+
+	if config.RoutingKey == "" && len(config.Services) == 0 {
+		return nil, fmt.Errorf("PagerDuty routing key or service routing keys are required")
+	}
+
+	if config.APIURL == "" {
+		config.APIURL = "https://events.pagerduty.com/v2/enqueue"
+	}
+	if config.Source == "" {
+		config.Source = "notifier"
+	}
+
+	base, err := newBaseNotifier(domain.TypePagerDuty, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PagerDutyNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Send sends an event to PagerDuty for every recipient service.
+// Metadata "event_action" selects trigger (default), acknowledge or resolve, and "dedup_key"
+// identifies the incident; acknowledge and resolve require a dedup_key.
+func (p *PagerDutyNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = p.withSentFooter(notification)
+
+	event, err := p.buildEvent(notification)
+	if err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// For PagerDuty, recipients are service names mapped to routing keys
+	dedupKeys := make(map[string]interface{}, len(notification.Recipients))
+	for _, recipient := range notification.Recipients {
+		routingKey := p.getRoutingKey(recipient)
+		if routingKey == "" {
+			err := fmt.Errorf("no PagerDuty routing key configured for service: %s", recipient)
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+
+		serviceEvent := *event
+		serviceEvent.RoutingKey = routingKey
+		resp, err := p.sendEvent(ctx, &serviceEvent)
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		dedupKeys[recipient] = resp.DedupKey
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("PagerDuty %s event sent to %d services", event.EventAction, len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"services":     notification.Recipients,
+			"event_action": event.EventAction,
+			"dedup_keys":   dedupKeys,
+		},
+	}, nil
+}
+
+// buildEvent constructs the event (without routing key) from the notification
+func (p *PagerDutyNotifier) buildEvent(notification *domain.Notification) (*pagerDutyEvent, error) {
+	action := PagerDutyTrigger
+	if value, ok := notification.Metadata["event_action"].(string); ok && value != "" {
+		action = value
+	}
+
+	event := &pagerDutyEvent{EventAction: action}
+	if dedupKey, ok := notification.Metadata["dedup_key"].(string); ok {
+		event.DedupKey = dedupKey
+	}
+
+	switch action {
+	case PagerDutyTrigger:
+	case PagerDutyAcknowledge, PagerDutyResolve:
+		if event.DedupKey == "" {
+			return nil, fmt.Errorf("PagerDuty %s requires a dedup_key", action)
+		}
+		return event, nil
+	default:
+		return nil, fmt.Errorf("invalid PagerDuty event_action: %s (must be trigger, acknowledge, or resolve)", action)
+	}
+
+	summary := notification.Subject
+	if summary == "" {
+		summary = notification.Body
+	}
+
+	payload := &pagerDutyPayload{
+		Summary:   truncateRunes(summary, pagerDutyMaxSummary),
+		Source:    p.config.Source,
+		Severity:  pagerDutySeverity(notification.Priority),
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if component, ok := notification.Metadata["component"].(string); ok {
+		payload.Component = component
+	}
+	if group, ok := notification.Metadata["group"].(string); ok {
+		payload.Group = group
+	}
+	if class, ok := notification.Metadata["class"].(string); ok {
+		payload.Class = class
+	}
+	if notification.Subject != "" && notification.Body != "" {
+		payload.CustomDetails = map[string]interface{}{"body": notification.Body}
+	}
+	event.Payload = payload
+
+	return event, nil
+}
+
+// pagerDutySeverity maps a priority to a PagerDuty severity
+func pagerDutySeverity(priority domain.Priority) string {
+	switch priority {
+	case domain.PriorityLow:
+		return "info"
+	case domain.PriorityHigh:
+		return "error"
+	case domain.PriorityCritical:
+		return "critical"
+	default:
+		return "warning"
+	}
+}
+
+// getRoutingKey returns the routing key for a service
+func (p *PagerDutyNotifier) getRoutingKey(service string) string {
+	// Check for service-specific routing key
+	if routingKey, ok := p.config.Services[service]; ok {
+		return routingKey
+	}
+
+	// Fall back to default routing key
+	return p.config.RoutingKey
+}
+
+// sendEvent posts an event to the Events API
+func (p *PagerDutyNotifier) sendEvent(ctx context.Context, event *pagerDutyEvent) (*pagerDutyResponse, error) {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.APIURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send PagerDuty event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var eventResp pagerDutyResponse
+	_ = json.NewDecoder(resp.Body).Decode(&eventResp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if eventResp.Message != "" {
+			return nil, newStatusCodeError(resp.StatusCode, "PagerDuty API returned status: %d (%s: %v)", resp.StatusCode, eventResp.Message, eventResp.Errors)
+		}
+		return nil, newStatusCodeError(resp.StatusCode, "PagerDuty API returned status: %d", resp.StatusCode)
+	}
+
+	return &eventResp, nil
+}
+
+// MetadataKeys returns the metadata keys the PagerDuty notifier reads
+func (p *PagerDutyNotifier) MetadataKeys() []string {
+	return []string{"event_action", "dedup_key", "component", "group", "class"}
+}
+
+// Close closes the HTTP client
+func (p *PagerDutyNotifier) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,160 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// newPagerDutyTestServer records received events and answers like the Events API
+func newPagerDutyTestServer(t *testing.T, received *[]pagerDutyEvent) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		*received = append(*received, event)
+
+		dedupKey := event.DedupKey
+		if dedupKey == "" {
+			dedupKey = "generated-" + event.RoutingKey
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(pagerDutyResponse{Status: "success", Message: "Event processed", DedupKey: dedupKey})
+	}))
+}
+
+// TestPagerDutyTrigger tests that trigger events carry the mapped severity and summary
+func TestPagerDutyTrigger(t *testing.T) {
+	var received []pagerDutyEvent
+	server := newPagerDutyTestServer(t, &received)
+	defer server.Close()
+
+	pd, err := NewPagerDutyNotifier(&PagerDutyConfig{
+		RoutingKey: "default-key",
+		Services:   map[string]string{"database": "db-key"},
+		APIURL:     server.URL,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create PagerDuty notifier: %v", err)
+	}
+
+	result, err := pd.Send(context.Background(), &domain.Notification{
+		ID:         "pd-1",
+		Type:       domain.TypePagerDuty,
+		Subject:    "Primary database unreachable",
+		Body:       "Connection refused on 10.0.0.5:5432",
+		Priority:   domain.PriorityCritical,
+		Recipients: []string{"database", "web"},
+		Metadata:   map[string]interface{}{"component": "postgres"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+
+	if len(received) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(received))
+	}
+	if received[0].RoutingKey != "db-key" || received[1].RoutingKey != "default-key" {
+		t.Errorf("Unexpected routing keys: %s, %s", received[0].RoutingKey, received[1].RoutingKey)
+	}
+
+	event := received[0]
+	if event.EventAction != PagerDutyTrigger {
+		t.Errorf("event_action = %q, want trigger", event.EventAction)
+	}
+	if event.Payload == nil {
+		t.Fatal("Expected trigger payload")
+	}
+	if event.Payload.Severity != "critical" {
+		t.Errorf("severity = %q, want critical", event.Payload.Severity)
+	}
+	if event.Payload.Summary != "Primary database unreachable" || event.Payload.Source != "notifier" {
+		t.Errorf("Unexpected payload: %+v", event.Payload)
+	}
+	if event.Payload.Component != "postgres" || event.Payload.CustomDetails["body"] != "Connection refused on 10.0.0.5:5432" {
+		t.Errorf("Unexpected payload details: %+v", event.Payload)
+	}
+
+	dedupKeys, _ := result.ProviderResponse["dedup_keys"].(map[string]interface{})
+	if dedupKeys["database"] != "generated-db-key" {
+		t.Errorf("dedup_keys = %v", dedupKeys)
+	}
+}
+
+// TestPagerDutyResolve tests that resolve events send only the dedup key and require one
+func TestPagerDutyResolve(t *testing.T) {
+	var received []pagerDutyEvent
+	server := newPagerDutyTestServer(t, &received)
+	defer server.Close()
+
+	pd, err := NewPagerDutyNotifier(&PagerDutyConfig{RoutingKey: "default-key", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create PagerDuty notifier: %v", err)
+	}
+
+	notification := &domain.Notification{
+		ID:         "pd-2",
+		Type:       domain.TypePagerDuty,
+		Body:       "Recovered",
+		Recipients: []string{"web"},
+		Metadata:   map[string]interface{}{"event_action": "acknowledge"},
+	}
+	if _, err := pd.Send(context.Background(), notification); err == nil {
+		t.Fatal("Expected acknowledge without dedup_key to fail")
+	}
+	if len(received) != 0 {
+		t.Fatalf("Expected no events to be sent, got %d", len(received))
+	}
+
+	notification.Metadata = map[string]interface{}{"event_action": "resolve", "dedup_key": "db-primary-down"}
+	if _, err := pd.Send(context.Background(), notification); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(received))
+	}
+	if received[0].EventAction != PagerDutyResolve || received[0].DedupKey != "db-primary-down" || received[0].Payload != nil {
+		t.Errorf("Unexpected resolve event: %+v", received[0])
+	}
+
+	notification.Metadata = map[string]interface{}{"event_action": "escalate"}
+	if _, err := pd.Send(context.Background(), notification); err == nil {
+		t.Error("Expected invalid event_action to fail")
+	}
+}
+
+// TestPagerDutyAPIError tests that non-2xx responses surface the status code
+func TestPagerDutyAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(pagerDutyResponse{Status: "invalid event", Message: "Event object is invalid", Errors: []string{"Length of 'routing_key' is incorrect"}})
+	}))
+	defer server.Close()
+
+	pd, err := NewPagerDutyNotifier(&PagerDutyConfig{RoutingKey: "bad", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create PagerDuty notifier: %v", err)
+	}
+
+	result, err := pd.Send(context.Background(), &domain.Notification{
+		ID:         "pd-3",
+		Type:       domain.TypePagerDuty,
+		Body:       "Disk full",
+		Recipients: []string{"web"},
+	})
+	if err == nil {
+		t.Fatal("Expected error for 400 response")
+	}
+	if result == nil || result.Success {
+		t.Errorf("Expected failed result, got %+v", result)
+	}
+}
@@ -0,0 +1,304 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/emailhtml"
+)
+
+// PostmarkConfig contains Postmark API configuration
+type PostmarkConfig struct {
+	ServerToken   string        `mapstructure:"server_token"`   // Server API token; each account sends through its own Postmark server
+	From          string        `mapstructure:"from"`           // Sender signature or address on a verified domain
+	FromName      string        `mapstructure:"from_name"`      // Optional display name for the From header
+	MessageStream string        `mapstructure:"message_stream"` // Default message stream (default: outbound)
+	APIURL        string        `mapstructure:"api_url"`        // Postmark API base URL (default: https://api.postmarkapp.com)
+	TrackOpens    bool          `mapstructure:"track_opens"`    // Enable open tracking
+	Default       bool          `mapstructure:"default"`        // Mark this instance as default
+	AllowedRoles  []string      `mapstructure:"allowed_roles"`  // Roles allowed to use this notifier (empty = all authenticated)
+	Display       DisplayConfig `mapstructure:"display"`        // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// PostmarkNotifier sends email through the Postmark API
+type PostmarkNotifier struct {
+	BaseNotifier
+	config     *PostmarkConfig
+	httpClient *http.Client
+}
+
+// postmarkEmail represents the Postmark send email request. Template fields are only
+// used by the send with template endpoint.
+type postmarkEmail struct {
+	From          string                 `json:"From"`
+	To            string                 `json:"To"`
+	Cc            string                 `json:"Cc,omitempty"`
+	Bcc           string                 `json:"Bcc,omitempty"`
+	ReplyTo       string                 `json:"ReplyTo,omitempty"`
+	Subject       string                 `json:"Subject,omitempty"`
+	TextBody      string                 `json:"TextBody,omitempty"`
+	HtmlBody      string                 `json:"HtmlBody,omitempty"`
+	Tag           string                 `json:"Tag,omitempty"`
+	MessageStream string                 `json:"MessageStream"`
+	TrackOpens    bool                   `json:"TrackOpens,omitempty"`
+	Metadata      map[string]string      `json:"Metadata,omitempty"`
+	Headers       []postmarkHeader       `json:"Headers,omitempty"`
+	TemplateAlias string                 `json:"TemplateAlias,omitempty"`
+	TemplateID    int64                  `json:"TemplateId,omitempty"`
+	TemplateModel map[string]interface{} `json:"TemplateModel,omitempty"`
+}
+
+// postmarkHeader is an extra header on a Postmark email
+type postmarkHeader struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// postmarkResponse represents the Postmark send response
+type postmarkResponse struct {
+	To          string `json:"To"`
+	SubmittedAt string `json:"SubmittedAt"`
+	MessageID   string `json:"MessageID"`
+	ErrorCode   int    `json:"ErrorCode"`
+	Message     string `json:"Message"`
+}
+
+// NewPostmarkNotifier creates a new Postmark notifier
+func NewPostmarkNotifier(config *PostmarkConfig) (*PostmarkNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Postmark config is required")
+	}
+
+	if config.ServerToken == "" {
+		return nil, fmt.Errorf("Postmark server token is required")
+	}
+
+	if config.From == "" {
+		return nil, fmt.Errorf("Postmark from address is required")
+	}
+
+	if config.MessageStream == "" {
+		config.MessageStream = "outbound"
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.postmarkapp.com"
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+
+	base, err := newBaseNotifier(domain.TypePostmark, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostmarkNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Validate checks the notification and that every recipient is an email address
+func (p *PostmarkNotifier) Validate(notification *domain.Notification) error {
+	if err := p.BaseNotifier.Validate(notification); err != nil {
+		return err
+	}
+
+	for _, list := range [][]string{notification.Recipients, notification.CC, notification.BCC} {
+		for _, recipient := range list {
+			if !strings.Contains(recipient, "@") {
+				return fmt.Errorf("invalid email address: %s", recipient)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Send sends an email through Postmark.
+// Metadata "template_alias" (or a numeric "template_id") sends a Postmark template instead of
+// the subject and body, filled from the "template_model" map. Metadata "message_stream"
+// overrides the account's stream and "tag" sets the Postmark tag.
+func (p *PostmarkNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = p.withSentFooter(notification)
+
+	email, endpoint, err := p.buildEmail(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.send(ctx, endpoint, email)
+	if err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	providerResponse := map[string]interface{}{
+		"message_id":     resp.MessageID,
+		"message_stream": email.MessageStream,
+		"submitted_at":   resp.SubmittedAt,
+	}
+	if email.TemplateAlias != "" {
+		providerResponse["template_alias"] = email.TemplateAlias
+	} else if email.TemplateID != 0 {
+		providerResponse["template_id"] = email.TemplateID
+	}
+
+	return &domain.NotificationResult{
+		NotificationID:   notification.ID,
+		Success:          true,
+		Message:          fmt.Sprintf("Email sent via Postmark to %d recipients", len(notification.Recipients)+len(notification.CC)+len(notification.BCC)),
+		SentAt:           time.Now(),
+		ProviderResponse: providerResponse,
+	}, nil
+}
+
+// buildEmail constructs the request and returns the endpoint it is sent to
+func (p *PostmarkNotifier) buildEmail(notification *domain.Notification) (*postmarkEmail, string, error) {
+	from := p.config.From
+	if p.config.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", p.config.FromName, p.config.From)
+	}
+
+	email := &postmarkEmail{
+		From:          from,
+		To:            strings.Join(notification.Recipients, ","),
+		Cc:            strings.Join(notification.CC, ","),
+		Bcc:           strings.Join(notification.BCC, ","),
+		ReplyTo:       notification.ReplyTo,
+		MessageStream: p.config.MessageStream,
+		TrackOpens:    p.config.TrackOpens,
+		Metadata:      map[string]string{"notification_id": notification.ID},
+	}
+	if stream, ok := notification.Metadata["message_stream"].(string); ok && stream != "" {
+		email.MessageStream = stream
+	}
+	if tag, ok := notification.Metadata["tag"].(string); ok {
+		email.Tag = tag
+	}
+	if notification.Options != nil && notification.Options.Email != nil {
+		for name, value := range notification.Options.Email.Headers {
+			email.Headers = append(email.Headers, postmarkHeader{Name: name, Value: value})
+		}
+		sort.Slice(email.Headers, func(i, j int) bool { return email.Headers[i].Name < email.Headers[j].Name })
+	}
+
+	alias, _ := notification.Metadata["template_alias"].(string)
+	templateID, err := postmarkTemplateID(notification.Metadata["template_id"])
+	if err != nil {
+		return nil, "", err
+	}
+	if alias != "" || templateID != 0 {
+		email.TemplateAlias = alias
+		if alias == "" {
+			email.TemplateID = templateID
+		}
+		email.TemplateModel = map[string]interface{}{}
+		if model, ok := notification.Metadata["template_model"].(map[string]interface{}); ok {
+			email.TemplateModel = model
+		}
+		return email, "/email/withTemplate", nil
+	}
+
+	email.Subject = notification.Subject
+	switch {
+	case notification.HTMLBody != "":
+		email.TextBody = notification.Body
+		email.HtmlBody = notification.HTMLBody
+	case isHTMLContent(notification):
+		email.TextBody = emailhtml.PlainText(notification.Body)
+		email.HtmlBody = notification.Body
+	default:
+		email.TextBody = notification.Body
+	}
+	return email, "/email", nil
+}
+
+// postmarkTemplateID reads the template_id metadata, which JSON decodes as a number
+func postmarkTemplateID(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case string:
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid Postmark template_id %q", v)
+		}
+		return id, nil
+	default:
+		return 0, fmt.Errorf("invalid Postmark template_id: %v", value)
+	}
+}
+
+// send posts the email to a Postmark endpoint
+func (p *PostmarkNotifier) send(ctx context.Context, endpoint string, email *postmarkEmail) (*postmarkResponse, error) {
+	jsonData, err := json.Marshal(email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Postmark email: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.APIURL+endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Postmark-Server-Token", p.config.ServerToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send Postmark email: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var pmResp postmarkResponse
+	_ = json.NewDecoder(resp.Body).Decode(&pmResp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || pmResp.ErrorCode != 0 {
+		if pmResp.Message != "" {
+			return nil, newStatusCodeError(resp.StatusCode, "Postmark API returned status: %d (%d: %s)", resp.StatusCode, pmResp.ErrorCode, pmResp.Message)
+		}
+		return nil, newStatusCodeError(resp.StatusCode, "Postmark API returned status: %d", resp.StatusCode)
+	}
+
+	return &pmResp, nil
+}
+
+// MetadataKeys returns the metadata keys the Postmark notifier reads
+func (p *PostmarkNotifier) MetadataKeys() []string {
+	return []string{"message_stream", "tag", "template_alias", "template_id", "template_model"}
+}
+
+// Close closes the HTTP client
+func (p *PostmarkNotifier) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
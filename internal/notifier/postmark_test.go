@@ -0,0 +1,122 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// newPostmarkTestServer records received emails and answers like the Postmark API
+func newPostmarkTestServer(t *testing.T, received *[]postmarkEmail, paths *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Postmark-Server-Token") != "server-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ErrorCode":10,"Message":"The Server Token you provided in the X-Postmark-Server-Token request header was invalid."}`))
+			return
+		}
+		var email postmarkEmail
+		if err := json.NewDecoder(r.Body).Decode(&email); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		*received = append(*received, email)
+		*paths = append(*paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{"To":"` + email.To + `","SubmittedAt":"2025-10-16T21:05:27Z","MessageID":"b7bc2f4a-e38e-4336-af7d-e6c392c2f817","ErrorCode":0,"Message":"OK"}`))
+	}))
+}
+
+// TestPostmarkSend tests plain and template sends
+func TestPostmarkSend(t *testing.T) {
+	var received []postmarkEmail
+	var paths []string
+	server := newPostmarkTestServer(t, &received, &paths)
+	defer server.Close()
+
+	pm, err := NewPostmarkNotifier(&PostmarkConfig{ServerToken: "server-token", From: "alerts@example.com", FromName: "Alerts", APIURL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create Postmark notifier: %v", err)
+	}
+
+	result, err := pm.Send(context.Background(), &domain.Notification{
+		ID:         "pm-1",
+		Type:       domain.TypePostmark,
+		Subject:    "Invoice ready",
+		Body:       "Your invoice is attached",
+		HTMLBody:   "<p>Your invoice is attached</p>",
+		Recipients: []string{"a@example.com", "b@example.com"},
+		CC:         []string{"c@example.com"},
+		Metadata:   map[string]interface{}{"message_stream": "billing", "tag": "invoice"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	email := received[0]
+	if paths[0] != "/email" || email.From != "Alerts <alerts@example.com>" || email.To != "a@example.com,b@example.com" || email.Cc != "c@example.com" ||
+		email.MessageStream != "billing" || email.Tag != "invoice" || email.HtmlBody != "<p>Your invoice is attached</p>" || email.Metadata["notification_id"] != "pm-1" {
+		t.Errorf("Unexpected email: %s %+v", paths[0], email)
+	}
+	if !result.Success || result.ProviderResponse["message_id"] != "b7bc2f4a-e38e-4336-af7d-e6c392c2f817" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	_, err = pm.Send(context.Background(), &domain.Notification{
+		ID:         "pm-2",
+		Type:       domain.TypePostmark,
+		Body:       "unused",
+		Recipients: []string{"a@example.com"},
+		Metadata: map[string]interface{}{
+			"template_alias": "password-reset",
+			"template_model": map[string]interface{}{"name": "Jo", "action_url": "https://example.com/reset"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Template send failed: %v", err)
+	}
+	email = received[1]
+	if paths[1] != "/email/withTemplate" || email.TemplateAlias != "password-reset" || email.TemplateModel["name"] != "Jo" ||
+		email.MessageStream != "outbound" || email.Subject != "" || email.TextBody != "" {
+		t.Errorf("Unexpected template email: %s %+v", paths[1], email)
+	}
+
+	_, err = pm.Send(context.Background(), &domain.Notification{
+		Type: domain.TypePostmark, Body: "x", Recipients: []string{"a@example.com"},
+		Metadata: map[string]interface{}{"template_id": float64(123456)},
+	})
+	if err != nil || received[2].TemplateID != 123456 {
+		t.Errorf("Expected a send by template ID, got %+v (err %v)", received[2], err)
+	}
+}
+
+// TestPostmarkErrors tests validation and API errors
+func TestPostmarkErrors(t *testing.T) {
+	var received []postmarkEmail
+	var paths []string
+	server := newPostmarkTestServer(t, &received, &paths)
+	defer server.Close()
+
+	if _, err := NewPostmarkNotifier(&PostmarkConfig{ServerToken: "server-token"}); err == nil {
+		t.Error("Expected an error without a from address")
+	}
+
+	pm, err := NewPostmarkNotifier(&PostmarkConfig{ServerToken: "wrong-token", From: "alerts@example.com", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Postmark notifier: %v", err)
+	}
+
+	if _, err := pm.Send(context.Background(), &domain.Notification{Type: domain.TypePostmark, Body: "x", Recipients: []string{"not-an-address"}}); err == nil {
+		t.Error("Expected an invalid address to be rejected")
+	}
+
+	result, err := pm.Send(context.Background(), &domain.Notification{Type: domain.TypePostmark, Body: "x", Recipients: []string{"a@example.com"}})
+	if err == nil || result.Success || !strings.Contains(err.Error(), "Server Token") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+	if len(received) != 0 {
+		t.Errorf("Expected nothing accepted, got %d emails", len(received))
+	}
+}
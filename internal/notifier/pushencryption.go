@@ -0,0 +1,107 @@
+package notifier
+
+import (
+	"crypto/ecdh"
+	"fmt"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/pkg/e2e"
+)
+
+// e2eKeyMetadata is the metadata key for a per-notification (e.g., per-device) public key
+const e2eKeyMetadata = "e2e_public_key"
+
+// defaultE2ETitle replaces the title of encrypted messages unless one is configured
+const defaultE2ETitle = "Encrypted notification"
+
+// PushEncryptionConfig configures end-to-end encryption of push payloads, so the push
+// provider relaying them can't read them. Apps decrypt with their private key using pkg/e2e.
+type PushEncryptionConfig struct {
+	// DeviceKeys maps devices to base64 X25519 public keys (generate with "client keygen")
+	DeviceKeys map[string]string `mapstructure:"device_keys"`
+
+	// PlaceholderTitle replaces the title on encrypted messages (default: "Encrypted notification")
+	PlaceholderTitle string `mapstructure:"placeholder_title"`
+
+	// Require refuses to send to devices without a key instead of sending plaintext
+	Require bool `mapstructure:"require"`
+}
+
+// pushEncryption seals push titles and bodies to each device's public key
+type pushEncryption struct {
+	config     *PushEncryptionConfig
+	deviceKeys map[string]*ecdh.PublicKey
+}
+
+// newPushEncryption parses the configured device keys. It returns nil when encryption isn't
+// configured, which sends everything in plaintext.
+func newPushEncryption(service string, config *PushEncryptionConfig) (*pushEncryption, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	deviceKeys := make(map[string]*ecdh.PublicKey, len(config.DeviceKeys))
+	for device, encoded := range config.DeviceKeys {
+		key, err := e2e.ParsePublicKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s encryption key for device %s: %w", service, device, err)
+		}
+		deviceKeys[device] = key
+	}
+
+	return &pushEncryption{config: config, deviceKeys: deviceKeys}, nil
+}
+
+// seal encrypts a title and body to the device's public key, returning the placeholder title
+// and sealed body to send instead. The notification's e2e_public_key metadata comes first,
+// then a key the recipient carries itself (empty if it can't), then the configured device key.
+// Devices without a key get the title and body back unchanged unless a key is required.
+func (e *pushEncryption) seal(notification *domain.Notification, device, carried, title, body string) (string, string, bool, error) {
+	if e == nil {
+		return title, body, false, nil
+	}
+
+	key := e.deviceKey(device)
+	if carried != "" {
+		carriedKey, err := e2e.ParsePublicKey(carried)
+		if err != nil {
+			return "", "", false, fmt.Errorf("invalid %s for device: %w", e2eKeyMetadata, err)
+		}
+		key = carriedKey
+	}
+	if encoded, ok := notification.Metadata[e2eKeyMetadata].(string); ok && encoded != "" {
+		metaKey, err := e2e.ParsePublicKey(encoded)
+		if err != nil {
+			return "", "", false, fmt.Errorf("invalid %s metadata: %w", e2eKeyMetadata, err)
+		}
+		key = metaKey
+	}
+
+	if key == nil {
+		if e.config.Require {
+			return "", "", false, fmt.Errorf("no encryption key configured for device: %s", device)
+		}
+		return title, body, false, nil
+	}
+
+	sealed, err := e2e.SealPayload(key, e2e.Payload{Title: title, Message: body})
+	if err != nil {
+		return "", "", false, fmt.Errorf("failed to encrypt push message: %w", err)
+	}
+
+	placeholder := e.config.PlaceholderTitle
+	if placeholder == "" {
+		placeholder = defaultE2ETitle
+	}
+	return placeholder, sealed, true, nil
+}
+
+// deviceKey returns the public key for a device. Config map keys are lowercased when loaded,
+// so fall back to a case-insensitive match.
+func (e *pushEncryption) deviceKey(device string) *ecdh.PublicKey {
+	if key, ok := e.deviceKeys[device]; ok {
+		return key
+	}
+	return e.deviceKeys[strings.ToLower(device)]
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/igodwin/notifier/internal/domain"
@@ -13,14 +16,26 @@ import (
 
 // SlackConfig contains Slack webhook configuration
 type SlackConfig struct {
-	WebhookURL   string            `mapstructure:"webhook_url"`
-	Token        string            `mapstructure:"token"`
-	Channel      string            `mapstructure:"channel"`
-	Username     string            `mapstructure:"username"`
-	IconEmoji    string            `mapstructure:"icon_emoji"`
-	Webhooks     map[string]string `mapstructure:"webhooks"`      // Channel-specific webhooks
-	Default      bool              `mapstructure:"default"`       // Mark this instance as default
-	AllowedRoles []string          `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	WebhookURL   string             `mapstructure:"webhook_url"`
+	Token        string             `mapstructure:"token"`
+	Channel      string             `mapstructure:"channel"`
+	Username     string             `mapstructure:"username"`
+	IconEmoji    string             `mapstructure:"icon_emoji"`
+	Webhooks     map[string]string  `mapstructure:"webhooks"`      // Channel-specific webhooks
+	APIURL       string             `mapstructure:"api_url"`       // Slack Web API base URL (default: https://slack.com/api)
+	Display      DisplayConfig      `mapstructure:"display"`       // Timestamp rendering for recipients
+	Markup       MarkupConfig       `mapstructure:"markup"`        // Emoji shortcode and markdown handling
+	Backup       *SlackBackupConfig `mapstructure:"backup"`        // Secondary webhook used when the primary fails (optional)
+	Default      bool               `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string           `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+}
+
+// SlackBackupConfig is a warm spare webhook the notifier fails over to when the
+// default webhook is revoked or unreachable
+type SlackBackupConfig struct {
+	WebhookURL    string `mapstructure:"webhook_url"`
+	Token         string `mapstructure:"token"`
+	ProbeInterval string `mapstructure:"probe_interval"` // How often to retry the primary while failed over (default: 5m)
 }
 
 // SlackNotifier sends notifications to Slack
@@ -28,6 +43,8 @@ type SlackNotifier struct {
 	BaseNotifier
 	config     *SlackConfig
 	httpClient *http.Client
+	markup     *markupNormalizer
+	failover   *failover
 }
 
 // slackMessage represents the Slack API request format
@@ -35,15 +52,33 @@ type slackMessage struct {
 	Channel   string       `json:"channel,omitempty"`
 	Username  string       `json:"username,omitempty"`
 	IconEmoji string       `json:"icon_emoji,omitempty"`
+	IconURL   string       `json:"icon_url,omitempty"`
+	ThreadTS  string       `json:"thread_ts,omitempty"` // Posts the message as a reply in this thread
 	Text      string       `json:"text,omitempty"`
 	Blocks    []slackBlock `json:"blocks,omitempty"`
 	Markdown  bool         `json:"mrkdwn,omitempty"`
+	PostAt    int64        `json:"post_at,omitempty"` // Unix timestamp, only used by chat.scheduleMessage
+}
+
+// slackAPIResponse represents the common fields of a Slack Web API response
+type slackAPIResponse struct {
+	OK                 bool   `json:"ok"`
+	Error              string `json:"error,omitempty"`
+	Channel            string `json:"channel,omitempty"`
+	ScheduledMessageID string `json:"scheduled_message_id,omitempty"`
+	PostAt             int64  `json:"post_at,omitempty"`
+	TS                 string `json:"ts,omitempty"`        // Timestamp ID of a posted message
+	Permalink          string `json:"permalink,omitempty"` // Returned by chat.getPermalink
 }
 
+// slackMaxScheduleAhead is the furthest in the future Slack accepts for chat.scheduleMessage
+const slackMaxScheduleAhead = 120 * 24 * time.Hour
+
 // slackBlock represents a Slack block element
 type slackBlock struct {
-	Type string          `json:"type"`
-	Text *slackTextBlock `json:"text,omitempty"`
+	Type     string            `json:"type"`
+	Text     *slackTextBlock   `json:"text,omitempty"`
+	Elements []*slackTextBlock `json:"elements,omitempty"`
 }
 
 // slackTextBlock represents a text element in a Slack block
@@ -63,14 +98,38 @@ func NewSlackNotifier(config *SlackConfig) (*SlackNotifier, error) {
 		return nil, fmt.Errorf("Slack webhook URL, token, or channel webhooks are required")
 	}
 
+	if config.APIURL == "" {
+		config.APIURL = "https://slack.com/api"
+	}
+
+	base, err := newBaseNotifier(domain.TypeSlack, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	markup, err := newMarkupNormalizer(config.Markup, MarkupConfig{Emoji: MarkupKeep, Markdown: MarkupConvert}, slackMarkdown)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Slack markup config: %w", err)
+	}
+
+	var fo *failover
+	if config.Backup != nil {
+		if config.Backup.WebhookURL == "" {
+			return nil, fmt.Errorf("Slack backup webhook URL is required")
+		}
+		if fo, err = newFailover(config.Backup.ProbeInterval); err != nil {
+			return nil, fmt.Errorf("invalid Slack backup config: %w", err)
+		}
+	}
+
 	return &SlackNotifier{
-		BaseNotifier: BaseNotifier{
-			notificationType: domain.TypeSlack,
-		},
-		config: config,
+		BaseNotifier: base,
+		config:       config,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		markup:   markup,
+		failover: fo,
 	}, nil
 }
 
@@ -84,12 +143,37 @@ func (s *SlackNotifier) Send(ctx context.Context, notification *domain.Notificat
 		return nil, err
 	}
 
+	notification = s.markup.normalize(notification)
+
+	// Hand future-dated notifications to Slack's scheduler when we have a bot token,
+	// so a restart of this service can't lose them
+	if s.SchedulesNatively(notification) {
+		return s.scheduleMessages(ctx, notification)
+	}
+
 	// For Slack, recipients are channel names or webhook URLs
+	usedBackup := false
+	links := make(map[string]string)
 	for _, recipient := range notification.Recipients {
 		msg := s.buildMessage(notification, recipient)
 		webhookURL := s.getWebhookURL(recipient)
 
-		if err := s.sendToSlack(ctx, webhookURL, msg); err != nil {
+		// Channels without a webhook are posted to with the bot token, which also
+		// tells us where the message landed so it can be linked to
+		var err error
+		if webhookURL == "" && s.config.Token != "" {
+			var resp *slackAPIResponse
+			if resp, err = s.callAPI(ctx, "chat.postMessage", msg); err == nil {
+				if link := s.permalink(ctx, resp.Channel, resp.TS); link != "" {
+					links[recipient] = link
+				}
+			}
+		} else {
+			var failedOver bool
+			failedOver, err = s.post(ctx, webhookURL, msg)
+			usedBackup = usedBackup || failedOver
+		}
+		if err != nil {
 			return &domain.NotificationResult{
 				NotificationID: notification.ID,
 				Success:        false,
@@ -106,10 +190,127 @@ func (s *SlackNotifier) Send(ctx context.Context, notification *domain.Notificat
 		SentAt:         time.Now(),
 		ProviderResponse: map[string]interface{}{
 			"channels": notification.Recipients,
+			"failover": usedBackup,
 		},
+		Links: links,
 	}, nil
 }
 
+// SchedulesNatively reports whether the notification can be scheduled with chat.scheduleMessage.
+// Webhooks have no scheduling support, so a bot token is required.
+func (s *SlackNotifier) SchedulesNatively(notification *domain.Notification) bool {
+	if s.config.Token == "" || notification.ScheduledFor == nil {
+		return false
+	}
+
+	until := time.Until(*notification.ScheduledFor)
+	return until > 0 && until <= slackMaxScheduleAhead
+}
+
+// scheduleMessages schedules the notification for every recipient channel via chat.scheduleMessage.
+// If a channel fails, the ones already scheduled are deleted again, so a retry can't schedule
+// the message twice in them.
+func (s *SlackNotifier) scheduleMessages(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	postAt := notification.ScheduledFor.Unix()
+	scheduledIDs := make(map[string]interface{}, len(notification.Recipients))
+	var scheduled []*slackAPIResponse
+
+	for _, channel := range notification.Recipients {
+		msg := s.buildMessage(notification, channel)
+		msg.PostAt = postAt
+
+		// chat.scheduleMessage requires text even when blocks are provided
+		if msg.Text == "" {
+			msg.Text = notification.Subject
+			if msg.Text == "" {
+				msg.Text = notification.Body
+			}
+		}
+
+		resp, err := s.callAPI(ctx, "chat.scheduleMessage", msg)
+		if err != nil {
+			if undoErr := s.unscheduleMessages(context.WithoutCancel(ctx), scheduled); undoErr != nil {
+				err = fmt.Errorf("%w; failed to unschedule earlier channels: %v", err, undoErr)
+			}
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		scheduledIDs[channel] = resp.ScheduledMessageID
+		scheduled = append(scheduled, resp)
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Slack notification scheduled for %d channels at %s", len(notification.Recipients), notification.ScheduledFor.UTC().Format(time.RFC3339)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"channels":              notification.Recipients,
+			"scheduled":             true,
+			"post_at":               postAt,
+			"scheduled_message_ids": scheduledIDs,
+		},
+	}, nil
+}
+
+// unscheduleMessages deletes messages scheduled by chat.scheduleMessage, trying every one
+// before reporting the failures
+func (s *SlackNotifier) unscheduleMessages(ctx context.Context, scheduled []*slackAPIResponse) error {
+	var errs []error
+	for _, resp := range scheduled {
+		_, err := s.callAPI(ctx, "chat.deleteScheduledMessage", map[string]string{
+			"channel":              resp.Channel,
+			"scheduled_message_id": resp.ScheduledMessageID,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", resp.Channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callAPI calls a Slack Web API method with the bot token and decodes the response
+func (s *SlackNotifier) callAPI(ctx context.Context, method string, payload interface{}) (*slackAPIResponse, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Slack request: %w", err)
+	}
+
+	url := strings.TrimSuffix(s.config.APIURL, "/") + "/" + method
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Token))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Slack %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("Slack API returned status: %d", resp.StatusCode)
+	}
+
+	var apiResp slackAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode Slack %s response: %w", method, err)
+	}
+
+	if !apiResp.OK {
+		return nil, fmt.Errorf("Slack %s failed: %s", method, apiResp.Error)
+	}
+
+	return &apiResp, nil
+}
+
 // buildMessage constructs a Slack message with rich formatting
 func (s *SlackNotifier) buildMessage(notification *domain.Notification, channel string) *slackMessage {
 	msg := &slackMessage{
@@ -119,6 +320,18 @@ func (s *SlackNotifier) buildMessage(notification *domain.Notification, channel
 		Markdown:  true,
 	}
 
+	// Apply thread and icon overrides from options
+	if notification.Options != nil && notification.Options.Slack != nil {
+		options := notification.Options.Slack
+		msg.ThreadTS = options.ThreadTS
+		if options.Icon != "" && options.IconIsEmoji() {
+			msg.IconEmoji = options.Icon
+		} else if options.Icon != "" {
+			msg.IconEmoji = ""
+			msg.IconURL = options.Icon
+		}
+	}
+
 	// Use blocks for rich formatting if both subject and body exist
 	if notification.Subject != "" && notification.Body != "" {
 		msg.Blocks = []slackBlock{
@@ -164,9 +377,52 @@ func (s *SlackNotifier) buildMessage(notification *domain.Notification, channel
 		}, msg.Blocks...)
 	}
 
+	// Add the sent timestamp footer in the account's display timezone
+	footerTime := time.Now()
+	if notification.ScheduledFor != nil && notification.ScheduledFor.After(footerTime) {
+		footerTime = *notification.ScheduledFor
+	}
+	if footer := s.sentFooter(footerTime); footer != "" {
+		if len(msg.Blocks) > 0 {
+			msg.Blocks = append(msg.Blocks, slackBlock{
+				Type:     "context",
+				Elements: []*slackTextBlock{{Type: "mrkdwn", Text: footer}},
+			})
+		} else {
+			msg.Text = appendTextFooter(msg.Text, "_"+footer+"_")
+		}
+	}
+
 	return msg
 }
 
+// permalink returns the URL of a posted message, or "" if Slack won't say. A message that was
+// posted shouldn't be reported as failed because its link couldn't be found.
+func (s *SlackNotifier) permalink(ctx context.Context, channel, ts string) string {
+	if channel == "" || ts == "" {
+		return ""
+	}
+
+	query := url.Values{"channel": {channel}, "message_ts": {ts}}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(s.config.APIURL, "/")+"/chat.getPermalink?"+query.Encode(), nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Token))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	var apiResp slackAPIResponse
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&apiResp) != nil || !apiResp.OK {
+		return ""
+	}
+	return apiResp.Permalink
+}
+
 // getWebhookURL returns the webhook URL for a specific channel
 func (s *SlackNotifier) getWebhookURL(channel string) string {
 	// Check for channel-specific webhook
@@ -178,8 +434,25 @@ func (s *SlackNotifier) getWebhookURL(channel string) string {
 	return s.config.WebhookURL
 }
 
+// post sends a message via webhook, failing over to the backup webhook when the default
+// webhook is rejected or unreachable. It reports whether the backup was used.
+func (s *SlackNotifier) post(ctx context.Context, webhookURL string, msg *slackMessage) (bool, error) {
+	if s.failover == nil || webhookURL != s.config.WebhookURL {
+		return false, s.sendToSlack(ctx, webhookURL, s.config.Token, msg)
+	}
+
+	return s.failover.run(
+		func() error {
+			return s.sendToSlack(ctx, webhookURL, s.config.Token, msg)
+		},
+		func() error {
+			return s.sendToSlack(ctx, s.config.Backup.WebhookURL, s.config.Backup.Token, msg)
+		},
+	)
+}
+
 // sendToSlack sends the message to Slack via webhook
-func (s *SlackNotifier) sendToSlack(ctx context.Context, webhookURL string, msg *slackMessage) error {
+func (s *SlackNotifier) sendToSlack(ctx context.Context, webhookURL, token string, msg *slackMessage) error {
 	jsonData, err := json.Marshal(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal Slack message: %w", err)
@@ -193,8 +466,8 @@ func (s *SlackNotifier) sendToSlack(ctx context.Context, webhookURL string, msg
 	req.Header.Set("Content-Type", "application/json")
 
 	// Add token authentication if configured
-	if s.config.Token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.config.Token))
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 
 	resp, err := s.httpClient.Do(req)
@@ -204,12 +477,17 @@ func (s *SlackNotifier) sendToSlack(ctx context.Context, webhookURL string, msg
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("Slack API returned status: %d", resp.StatusCode)
+		return newStatusCodeError(resp.StatusCode, "Slack API returned status: %d", resp.StatusCode)
 	}
 
 	return nil
 }
 
+// MetadataKeys reports that the Slack notifier reads no metadata
+func (s *SlackNotifier) MetadataKeys() []string {
+	return nil
+}
+
 // Close closes the HTTP client
 func (s *SlackNotifier) Close() error {
 	s.httpClient.CloseIdleConnections()
@@ -0,0 +1,253 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSlackScheduleMessage tests that future-dated notifications use chat.scheduleMessage when a bot token is set
+func TestSlackScheduleMessage(t *testing.T) {
+	var received slackMessage
+	var authHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat.scheduleMessage" {
+			t.Errorf("Unexpected API method: %s", r.URL.Path)
+		}
+		authHeader = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"ok":                   true,
+			"channel":              received.Channel,
+			"scheduled_message_id": "Q1298393284",
+			"post_at":              received.PostAt,
+		})
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackNotifier(&SlackConfig{Token: "xoxb-test", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Slack notifier: %v", err)
+	}
+
+	scheduledFor := time.Now().Add(time.Hour).Truncate(time.Second)
+	result, err := slack.Send(context.Background(), &domain.Notification{
+		ID:           "sched-1",
+		Type:         domain.TypeSlack,
+		Subject:      "Maintenance",
+		Body:         "Starting in one hour",
+		Recipients:   []string{"#ops"},
+		ScheduledFor: &scheduledFor,
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	if !result.Success {
+		t.Fatalf("Expected success, got error: %s", result.Error)
+	}
+	if authHeader != "Bearer xoxb-test" {
+		t.Errorf("Authorization = %q, want bot token", authHeader)
+	}
+	if received.PostAt != scheduledFor.Unix() {
+		t.Errorf("post_at = %d, want %d", received.PostAt, scheduledFor.Unix())
+	}
+	if received.Text == "" {
+		t.Error("Expected fallback text to be set for scheduled message")
+	}
+	if result.ProviderResponse["scheduled"] != true {
+		t.Error("Expected result to be marked as scheduled")
+	}
+}
+
+// TestSlackScheduleMessagePartialFailure tests that channels already scheduled are unscheduled
+// when a later channel fails, so retrying doesn't schedule the message twice
+func TestSlackScheduleMessagePartialFailure(t *testing.T) {
+	var deleted []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chat.scheduleMessage":
+			var msg slackMessage
+			if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			if msg.Channel == "#missing" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "channel_not_found"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":                   true,
+				"channel":              "C" + msg.Channel[1:],
+				"scheduled_message_id": "Q" + msg.Channel[1:],
+				"post_at":              msg.PostAt,
+			})
+		case "/chat.deleteScheduledMessage":
+			var req map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			deleted = append(deleted, req)
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true})
+		default:
+			t.Errorf("Unexpected API method: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackNotifier(&SlackConfig{Token: "xoxb-test", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Slack notifier: %v", err)
+	}
+
+	scheduledFor := time.Now().Add(time.Hour)
+	result, err := slack.Send(context.Background(), &domain.Notification{
+		ID:           "sched-2",
+		Type:         domain.TypeSlack,
+		Body:         "Starting in one hour",
+		Recipients:   []string{"#ops", "#dev", "#missing"},
+		ScheduledFor: &scheduledFor,
+	})
+	if err == nil || result.Success {
+		t.Fatal("Expected scheduling to fail for the missing channel")
+	}
+
+	if len(deleted) != 2 {
+		t.Fatalf("Expected both scheduled channels to be unscheduled, got %v", deleted)
+	}
+	for i, channel := range []string{"ops", "dev"} {
+		if deleted[i]["channel"] != "C"+channel || deleted[i]["scheduled_message_id"] != "Q"+channel {
+			t.Errorf("Unexpected unschedule request %d: %v", i, deleted[i])
+		}
+	}
+}
+
+// TestSlackScheduleFallback tests that notifications fall back to immediate delivery when Slack can't schedule them
+func TestSlackScheduleFallback(t *testing.T) {
+	slack, err := NewSlackNotifier(&SlackConfig{WebhookURL: "https://hooks.slack.com/services/test"})
+	if err != nil {
+		t.Fatalf("Failed to create Slack notifier: %v", err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	tooFar := time.Now().Add(slackMaxScheduleAhead + time.Hour)
+
+	tests := []struct {
+		name         string
+		token        string
+		scheduledFor *time.Time
+		expected     bool
+	}{
+		{"webhook only", "", &future, false},
+		{"not scheduled", "xoxb-test", nil, false},
+		{"in the past", "xoxb-test", &past, false},
+		{"beyond Slack limit", "xoxb-test", &tooFar, false},
+		{"bot token and future time", "xoxb-test", &future, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slack.config.Token = tt.token
+			notification := &domain.Notification{ScheduledFor: tt.scheduledFor}
+			if got := slack.SchedulesNatively(notification); got != tt.expected {
+				t.Errorf("SchedulesNatively() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSlackPostMessagePermalink tests that channels without a webhook are posted to with the
+// bot token and linked to with chat.getPermalink
+func TestSlackPostMessagePermalink(t *testing.T) {
+	var posted slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chat.postMessage":
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": "C024BE91L", "ts": "1700000000.000100"})
+		case "/chat.getPermalink":
+			if r.URL.Query().Get("channel") != "C024BE91L" || r.URL.Query().Get("message_ts") != "1700000000.000100" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error": "message_not_found"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"ok":        true,
+				"channel":   "C024BE91L",
+				"permalink": "https://example.slack.com/archives/C024BE91L/p1700000000000100",
+			})
+		default:
+			t.Errorf("Unexpected API method: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackNotifier(&SlackConfig{Token: "xoxb-test", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create Slack notifier: %v", err)
+	}
+
+	result, err := slack.Send(context.Background(), &domain.Notification{
+		ID:         "post-1",
+		Type:       domain.TypeSlack,
+		Body:       "Deploy finished",
+		Recipients: []string{"#deploys"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if posted.Channel != "#deploys" || posted.Text != "Deploy finished" {
+		t.Errorf("Unexpected posted message: %+v", posted)
+	}
+	if result.Links["#deploys"] != "https://example.slack.com/archives/C024BE91L/p1700000000000100" {
+		t.Errorf("Expected a permalink, got %v", result.Links)
+	}
+}
+
+// TestSlackThreadAndIconOptions tests that options post the message in a thread and replace the
+// configured icon emoji with an image URL
+func TestSlackThreadAndIconOptions(t *testing.T) {
+	var posted slackMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chat.postMessage" {
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				t.Errorf("Failed to decode request: %v", err)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": "C024BE91L", "ts": "1700000000.000200"})
+	}))
+	defer server.Close()
+
+	slack, err := NewSlackNotifier(&SlackConfig{Token: "xoxb-test", APIURL: server.URL, IconEmoji: ":robot_face:"})
+	if err != nil {
+		t.Fatalf("Failed to create Slack notifier: %v", err)
+	}
+
+	_, err = slack.Send(context.Background(), &domain.Notification{
+		ID:         "thread-1",
+		Type:       domain.TypeSlack,
+		Body:       "Rollback finished",
+		Recipients: []string{"#deploys"},
+		Options: &domain.SendOptions{Slack: &domain.SlackOptions{
+			ThreadTS: "1700000000.000100",
+			Icon:     "https://example.com/rollback.png",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if posted.ThreadTS != "1700000000.000100" {
+		t.Errorf("Expected the message in the thread, got thread_ts %q", posted.ThreadTS)
+	}
+	if posted.IconURL != "https://example.com/rollback.png" || posted.IconEmoji != "" {
+		t.Errorf("Expected the icon URL to replace the emoji, got %+v", posted)
+	}
+}
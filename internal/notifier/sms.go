@@ -0,0 +1,189 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Supported SMS providers
+const (
+	SMSProviderTwilio      = "twilio"
+	SMSProviderVonage      = "vonage"
+	SMSProviderMessageBird = "messagebird"
+)
+
+// smsMaxText is the longest text sent, about ten concatenated SMS segments
+const smsMaxText = 1600
+
+// SMSConfig contains SMS account configuration. The provider selects which of the
+// provider blocks holds the credentials.
+type SMSConfig struct {
+	Provider     string               `mapstructure:"provider"`      // twilio, vonage or messagebird
+	From         string               `mapstructure:"from"`          // Sender: an E.164 number or an alphanumeric sender ID where supported
+	APIURL       string               `mapstructure:"api_url"`       // Provider API base URL (default: the provider's public endpoint)
+	Twilio       SMSTwilioConfig      `mapstructure:"twilio"`        // Twilio credentials
+	Vonage       SMSVonageConfig      `mapstructure:"vonage"`        // Vonage credentials
+	MessageBird  SMSMessageBirdConfig `mapstructure:"messagebird"`   // MessageBird credentials
+	Default      bool                 `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string             `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig        `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// SMSTwilioConfig contains Twilio credentials
+type SMSTwilioConfig struct {
+	AccountSID          string `mapstructure:"account_sid"`
+	AuthToken           string `mapstructure:"auth_token"`
+	MessagingServiceSID string `mapstructure:"messaging_service_sid"` // Send through a messaging service instead of from (optional)
+}
+
+// SMSVonageConfig contains Vonage (Nexmo) SMS API credentials
+type SMSVonageConfig struct {
+	APIKey    string `mapstructure:"api_key"`
+	APISecret string `mapstructure:"api_secret"`
+}
+
+// SMSMessageBirdConfig contains MessageBird credentials
+type SMSMessageBirdConfig struct {
+	AccessKey string `mapstructure:"access_key"` // Live or test access key
+}
+
+// smsProvider sends one text message through an SMS vendor and returns the vendor's message ID
+type smsProvider interface {
+	send(ctx context.Context, to, text string) (string, error)
+}
+
+// SMSNotifier sends text messages through the account's SMS provider
+type SMSNotifier struct {
+	BaseNotifier
+	config     *SMSConfig
+	httpClient *http.Client
+	provider   smsProvider
+}
+
+// NewSMSNotifier creates a new SMS notifier for the configured provider
+func NewSMSNotifier(config *SMSConfig) (*SMSNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("SMS config is required")
+	}
+
+	if config.From == "" && config.Twilio.MessagingServiceSID == "" {
+		return nil, fmt.Errorf("SMS from is required")
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+
+	httpClient := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	var provider smsProvider
+	var err error
+	switch config.Provider {
+	case SMSProviderTwilio:
+		provider, err = newTwilioSMS(config, httpClient)
+	case SMSProviderVonage:
+		provider, err = newVonageSMS(config, httpClient)
+	case SMSProviderMessageBird:
+		provider, err = newMessageBirdSMS(config, httpClient)
+	default:
+		return nil, fmt.Errorf("unsupported SMS provider: %q (must be twilio, vonage or messagebird)", config.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := newBaseNotifier(domain.TypeSMS, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SMSNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient:   httpClient,
+		provider:     provider,
+	}, nil
+}
+
+// Validate checks the notification, that every recipient is an E.164 phone number and that
+// the text isn't too long
+func (s *SMSNotifier) Validate(notification *domain.Notification) error {
+	if err := s.BaseNotifier.Validate(notification); err != nil {
+		return err
+	}
+
+	for _, recipient := range notification.Recipients {
+		if !e164Pattern.MatchString(recipient) {
+			return fmt.Errorf("invalid SMS recipient %q: must be an E.164 phone number (e.g., +15551234567)", recipient)
+		}
+	}
+
+	if length := utf8.RuneCountInString(smsText(notification)); length > smsMaxText {
+		return fmt.Errorf("SMS text is %d characters, longer than the %d allowed", length, smsMaxText)
+	}
+
+	return nil
+}
+
+// Send sends the text to every recipient
+func (s *SMSNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := s.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = s.withSentFooter(notification)
+
+	text := smsText(notification)
+	messageIDs := make(map[string]interface{}, len(notification.Recipients))
+	for _, recipient := range notification.Recipients {
+		id, err := s.provider.send(ctx, recipient, text)
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		messageIDs[recipient] = id
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("SMS sent via %s to %d recipients", s.config.Provider, len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"provider":    s.config.Provider,
+			"message_ids": messageIDs,
+		},
+	}, nil
+}
+
+// smsText is the subject, if any, on the line above the body
+func smsText(notification *domain.Notification) string {
+	if notification.Subject == "" {
+		return notification.Body
+	}
+	return notification.Subject + "\n" + notification.Body
+}
+
+// MetadataKeys reports that the SMS notifier reads no metadata
+func (s *SMSNotifier) MetadataKeys() []string {
+	return nil
+}
+
+// Close closes the HTTP client
+func (s *SMSNotifier) Close() error {
+	s.httpClient.CloseIdleConnections()
+	return nil
+}
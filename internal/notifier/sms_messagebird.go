@@ -0,0 +1,95 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// messageBirdSMS sends messages with the MessageBird SMS API
+type messageBirdSMS struct {
+	endpoint   string
+	accessKey  string
+	from       string
+	httpClient *http.Client
+}
+
+// messageBirdMessage represents the create message request
+type messageBirdMessage struct {
+	Originator string   `json:"originator"`
+	Recipients []string `json:"recipients"`
+	Body       string   `json:"body"`
+}
+
+// messageBirdResponse is the subset of the message object and error response we use
+type messageBirdResponse struct {
+	ID     string `json:"id"`
+	Errors []struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"errors"`
+}
+
+// newMessageBirdSMS creates a MessageBird driver
+func newMessageBirdSMS(config *SMSConfig, httpClient *http.Client) (*messageBirdSMS, error) {
+	if config.MessageBird.AccessKey == "" {
+		return nil, fmt.Errorf("MessageBird access_key is required")
+	}
+
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = "https://rest.messagebird.com"
+	}
+
+	return &messageBirdSMS{
+		endpoint:   apiURL + "/messages",
+		accessKey:  config.MessageBird.AccessKey,
+		from:       config.From,
+		httpClient: httpClient,
+	}, nil
+}
+
+// send creates a message for one recipient
+func (m *messageBirdSMS) send(ctx context.Context, to, text string) (string, error) {
+	jsonData, err := json.Marshal(messageBirdMessage{
+		Originator: m.from,
+		Recipients: []string{strings.TrimPrefix(to, "+")},
+		Body:       text,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal MessageBird message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", m.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "AccessKey "+m.accessKey)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send MessageBird SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result messageBirdResponse
+	decodeErr := json.Unmarshal(body, &result)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil && len(result.Errors) > 0 {
+			return "", newStatusCodeError(resp.StatusCode, "MessageBird API returned status %d: error %d: %s", resp.StatusCode, result.Errors[0].Code, result.Errors[0].Description)
+		}
+		return "", newStatusCodeError(resp.StatusCode, "MessageBird API returned status: %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to decode MessageBird response: %w", decodeErr)
+	}
+
+	return result.ID, nil
+}
@@ -0,0 +1,146 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSMSProviders tests that each provider driver posts the text in its vendor's format
+func TestSMSProviders(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SMSConfig
+		handler func(t *testing.T, w http.ResponseWriter, r *http.Request)
+		wantID  string
+	}{
+		{
+			name:   "twilio",
+			config: SMSConfig{Provider: SMSProviderTwilio, From: "+15550000000", Twilio: SMSTwilioConfig{AccountSID: "AC123", AuthToken: "token"}},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+					t.Errorf("Unexpected path: %s", r.URL.Path)
+				}
+				if user, pass, ok := r.BasicAuth(); !ok || user != "AC123" || pass != "token" {
+					t.Errorf("Expected basic auth with the account SID and token")
+				}
+				r.ParseForm()
+				if r.PostForm.Get("To") != "+15551234567" || r.PostForm.Get("From") != "+15550000000" || r.PostForm.Get("Body") != "Disk full\nserver-1 is at 95%" {
+					t.Errorf("Unexpected form: %v", r.PostForm)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+			},
+			wantID: "SM123",
+		},
+		{
+			name:   "vonage",
+			config: SMSConfig{Provider: SMSProviderVonage, From: "Notifier", Vonage: SMSVonageConfig{APIKey: "key", APISecret: "secret"}},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/sms/json" {
+					t.Errorf("Unexpected path: %s", r.URL.Path)
+				}
+				r.ParseForm()
+				if r.PostForm.Get("api_key") != "key" || r.PostForm.Get("to") != "15551234567" || r.PostForm.Get("from") != "Notifier" {
+					t.Errorf("Unexpected form: %v", r.PostForm)
+				}
+				w.Write([]byte(`{"message-count": "1", "messages": [{"to": "15551234567", "message-id": "0A000001", "status": "0"}]}`))
+			},
+			wantID: "0A000001",
+		},
+		{
+			name:   "messagebird",
+			config: SMSConfig{Provider: SMSProviderMessageBird, From: "+15550000000", MessageBird: SMSMessageBirdConfig{AccessKey: "live_abc"}},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != "AccessKey live_abc" {
+					t.Errorf("Unexpected authorization: %s", r.Header.Get("Authorization"))
+				}
+				var msg messageBirdMessage
+				if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+					t.Errorf("Failed to decode request: %v", err)
+				}
+				if len(msg.Recipients) != 1 || msg.Recipients[0] != "15551234567" || !strings.HasPrefix(msg.Body, "Disk full") {
+					t.Errorf("Unexpected message: %+v", msg)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"id": "mb-1"}`))
+			},
+			wantID: "mb-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(t, w, r)
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.APIURL = server.URL
+			sms, err := NewSMSNotifier(&config)
+			if err != nil {
+				t.Fatalf("Failed to create SMS notifier: %v", err)
+			}
+
+			result, err := sms.Send(context.Background(), &domain.Notification{
+				ID:         "sms-1",
+				Type:       domain.TypeSMS,
+				Subject:    "Disk full",
+				Body:       "server-1 is at 95%",
+				Recipients: []string{"+15551234567"},
+			})
+			if err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			ids, _ := result.ProviderResponse["message_ids"].(map[string]interface{})
+			if !result.Success || ids["+15551234567"] != tt.wantID {
+				t.Errorf("Unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+// TestSMSVonageError tests that a rejected message reported with a 200 fails the send
+func TestSMSVonageError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message-count": "1", "messages": [{"status": "4", "error-text": "Bad Credentials"}]}`))
+	}))
+	defer server.Close()
+
+	sms, err := NewSMSNotifier(&SMSConfig{Provider: SMSProviderVonage, From: "Notifier", APIURL: server.URL, Vonage: SMSVonageConfig{APIKey: "key", APISecret: "wrong"}})
+	if err != nil {
+		t.Fatalf("Failed to create SMS notifier: %v", err)
+	}
+
+	result, err := sms.Send(context.Background(), &domain.Notification{ID: "sms-2", Type: domain.TypeSMS, Body: "Test", Recipients: []string{"+15551234567"}})
+	if err == nil || result.Success || !strings.Contains(err.Error(), "Bad Credentials") {
+		t.Errorf("Expected Vonage error, got %v", err)
+	}
+}
+
+// TestSMSValidate tests provider selection, recipient format and text length
+func TestSMSValidate(t *testing.T) {
+	if _, err := NewSMSNotifier(&SMSConfig{Provider: "sinch", From: "+15550000000"}); err == nil {
+		t.Error("Expected error for an unsupported provider")
+	}
+	if _, err := NewSMSNotifier(&SMSConfig{Provider: SMSProviderTwilio, From: "+15550000000"}); err == nil {
+		t.Error("Expected error without Twilio credentials")
+	}
+
+	sms, err := NewSMSNotifier(&SMSConfig{Provider: SMSProviderMessageBird, From: "+15550000000", MessageBird: SMSMessageBirdConfig{AccessKey: "key"}})
+	if err != nil {
+		t.Fatalf("Failed to create SMS notifier: %v", err)
+	}
+	if err := sms.Validate(&domain.Notification{Type: domain.TypeSMS, Body: "Test", Recipients: []string{"5551234567"}}); err == nil {
+		t.Error("Expected error for a recipient that isn't E.164")
+	}
+	if err := sms.Validate(&domain.Notification{Type: domain.TypeSMS, Body: strings.Repeat("x", smsMaxText+1), Recipients: []string{"+15551234567"}}); err == nil {
+		t.Error("Expected error for text over the limit")
+	}
+}
@@ -0,0 +1,90 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// twilioSMS sends messages with Twilio's Programmable Messaging API
+type twilioSMS struct {
+	endpoint            string
+	accountSID          string
+	authToken           string
+	from                string
+	messagingServiceSID string
+	httpClient          *http.Client
+}
+
+// twilioMessageResponse is the subset of Twilio's message resource and error response we use
+type twilioMessageResponse struct {
+	SID     string `json:"sid"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newTwilioSMS creates a Twilio driver
+func newTwilioSMS(config *SMSConfig, httpClient *http.Client) (*twilioSMS, error) {
+	if config.Twilio.AccountSID == "" || config.Twilio.AuthToken == "" {
+		return nil, fmt.Errorf("Twilio account_sid and auth_token are required")
+	}
+
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.twilio.com"
+	}
+
+	return &twilioSMS{
+		endpoint:            fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", apiURL, url.PathEscape(config.Twilio.AccountSID)),
+		accountSID:          config.Twilio.AccountSID,
+		authToken:           config.Twilio.AuthToken,
+		from:                config.From,
+		messagingServiceSID: config.Twilio.MessagingServiceSID,
+		httpClient:          httpClient,
+	}, nil
+}
+
+// send creates a message resource
+func (t *twilioSMS) send(ctx context.Context, to, text string) (string, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", text)
+	if t.messagingServiceSID != "" {
+		form.Set("MessagingServiceSid", t.messagingServiceSID)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", t.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.accountSID, t.authToken)
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send Twilio SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result twilioMessageResponse
+	decodeErr := json.Unmarshal(body, &result)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if decodeErr == nil && result.Message != "" {
+			return "", newStatusCodeError(resp.StatusCode, "Twilio API returned status %d: error %d: %s", resp.StatusCode, result.Code, result.Message)
+		}
+		return "", newStatusCodeError(resp.StatusCode, "Twilio API returned status: %d", resp.StatusCode)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to decode Twilio response: %w", decodeErr)
+	}
+
+	return result.SID, nil
+}
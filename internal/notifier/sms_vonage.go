@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// vonageSMS sends messages with the Vonage (Nexmo) SMS API
+type vonageSMS struct {
+	endpoint   string
+	apiKey     string
+	apiSecret  string
+	from       string
+	httpClient *http.Client
+}
+
+// vonageSMSResponse represents the SMS API response, which has one entry per message part
+type vonageSMSResponse struct {
+	Messages []struct {
+		MessageID string `json:"message-id"`
+		Status    string `json:"status"`
+		ErrorText string `json:"error-text"`
+	} `json:"messages"`
+}
+
+// newVonageSMS creates a Vonage driver
+func newVonageSMS(config *SMSConfig, httpClient *http.Client) (*vonageSMS, error) {
+	if config.Vonage.APIKey == "" || config.Vonage.APISecret == "" {
+		return nil, fmt.Errorf("Vonage api_key and api_secret are required")
+	}
+
+	apiURL := config.APIURL
+	if apiURL == "" {
+		apiURL = "https://rest.nexmo.com"
+	}
+
+	return &vonageSMS{
+		endpoint:   apiURL + "/sms/json",
+		apiKey:     config.Vonage.APIKey,
+		apiSecret:  config.Vonage.APISecret,
+		from:       strings.TrimPrefix(config.From, "+"),
+		httpClient: httpClient,
+	}, nil
+}
+
+// send submits a message. Vonage takes numbers without the leading "+" and reports errors
+// per message part with a 200 status.
+func (v *vonageSMS) send(ctx context.Context, to, text string) (string, error) {
+	form := url.Values{}
+	form.Set("api_key", v.apiKey)
+	form.Set("api_secret", v.apiSecret)
+	form.Set("from", v.from)
+	form.Set("to", strings.TrimPrefix(to, "+"))
+	form.Set("text", text)
+	form.Set("type", "unicode")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send Vonage SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", newStatusCodeError(resp.StatusCode, "Vonage API returned status: %d", resp.StatusCode)
+	}
+
+	var result vonageSMSResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode Vonage response: %w", err)
+	}
+	if len(result.Messages) == 0 {
+		return "", fmt.Errorf("Vonage API returned no messages")
+	}
+	for _, message := range result.Messages {
+		if message.Status != "0" {
+			return "", fmt.Errorf("Vonage API error %s: %s", message.Status, message.ErrorText)
+		}
+	}
+
+	return result.Messages[0].MessageID, nil
+}
@@ -5,32 +5,50 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
-	"html"
+	"mime"
 	"net/smtp"
-	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/emailhtml"
 )
 
 // SMTPConfig contains SMTP server configuration
 type SMTPConfig struct {
-	Host         string   `mapstructure:"host"`
-	Port         int      `mapstructure:"port"`
-	Username     string   `mapstructure:"username"`
-	Password     string   `mapstructure:"password"`
-	From         string   `mapstructure:"from"`
-	FromName     string   `mapstructure:"from_name"` // Optional display name for From header
-	UseTLS       bool     `mapstructure:"use_tls"`
-	Default      bool     `mapstructure:"default"`       // Mark this instance as default
-	AllowedRoles []string `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Host         string            `mapstructure:"host"`
+	Port         int               `mapstructure:"port"`
+	Username     string            `mapstructure:"username"`
+	Password     string            `mapstructure:"password"`
+	From         string            `mapstructure:"from"`
+	FromName     string            `mapstructure:"from_name"` // Optional display name for From header
+	UseTLS       bool              `mapstructure:"use_tls"`
+	Display      DisplayConfig     `mapstructure:"display"`       // Timestamp rendering for recipients
+	Markup       MarkupConfig      `mapstructure:"markup"`        // Emoji shortcode and markdown handling
+	Backup       *SMTPBackupConfig `mapstructure:"backup"`        // Secondary relay used when the primary fails (optional)
+	Gmail        *GmailConfig      `mapstructure:"gmail"`         // Send through the Gmail API instead of SMTP (optional)
+	Default      bool              `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string          `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+}
+
+// SMTPBackupConfig is a warm spare relay the notifier fails over to when the primary
+// rejects our credentials or can't be reached
+type SMTPBackupConfig struct {
+	Host          string `mapstructure:"host"`
+	Port          int    `mapstructure:"port"`
+	Username      string `mapstructure:"username"`
+	Password      string `mapstructure:"password"`
+	ProbeInterval string `mapstructure:"probe_interval"` // How often to retry the primary while failed over (default: 5m)
 }
 
 // SMTPNotifier sends notifications via email using SMTP
 type SMTPNotifier struct {
 	BaseNotifier
-	config *SMTPConfig
+	config   *SMTPConfig
+	markup   *markupNormalizer
+	failover *failover
+	gmail    *gmailSender
 }
 
 // NewSMTPNotifier creates a new SMTP notifier
@@ -39,7 +57,7 @@ func NewSMTPNotifier(config *SMTPConfig) (*SMTPNotifier, error) {
 		return nil, fmt.Errorf("SMTP config is required")
 	}
 
-	if config.Host == "" {
+	if config.Host == "" && config.Gmail == nil {
 		return nil, fmt.Errorf("SMTP host is required")
 	}
 
@@ -51,11 +69,45 @@ func NewSMTPNotifier(config *SMTPConfig) (*SMTPNotifier, error) {
 		return nil, fmt.Errorf("SMTP from address is required")
 	}
 
+	base, err := newBaseNotifier(domain.TypeEmail, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	markup, err := newMarkupNormalizer(config.Markup, MarkupConfig{Emoji: MarkupConvert, Markdown: MarkupConvert}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP markup config: %w", err)
+	}
+
+	var fo *failover
+	if config.Backup != nil {
+		if config.Backup.Host == "" {
+			return nil, fmt.Errorf("SMTP backup host is required")
+		}
+		if config.Backup.Port == 0 {
+			config.Backup.Port = config.Port
+		}
+		if fo, err = newFailover(config.Backup.ProbeInterval); err != nil {
+			return nil, fmt.Errorf("invalid SMTP backup config: %w", err)
+		}
+	}
+
+	var gmail *gmailSender
+	if config.Gmail != nil {
+		if config.Backup != nil {
+			return nil, fmt.Errorf("SMTP backup relay can't be used with the Gmail API")
+		}
+		if gmail, err = newGmailSender(config.Gmail, config.From); err != nil {
+			return nil, err
+		}
+	}
+
 	return &SMTPNotifier{
-		BaseNotifier: BaseNotifier{
-			notificationType: domain.TypeEmail,
-		},
-		config: config,
+		BaseNotifier: base,
+		config:       config,
+		markup:       markup,
+		failover:     fo,
+		gmail:        gmail,
 	}, nil
 }
 
@@ -69,6 +121,17 @@ func (s *SMTPNotifier) Send(ctx context.Context, notification *domain.Notificati
 		return nil, err
 	}
 
+	notification = s.markup.normalize(notification)
+
+	// Email clients don't render markdown, so a plain-text body with markdown gets an HTML
+	// alternative and loses the syntax from its text part
+	if s.markup.markdown == MarkupConvert && notification.HTMLBody == "" && !isHTMLContent(notification) {
+		if text := renderMarkdown(notification.Body, plainMarkdown); text != notification.Body {
+			notification.HTMLBody = renderMarkdown(notification.Body, htmlMarkdown)
+			notification.Body = text
+		}
+	}
+
 	// Collect all recipients (To, CC, BCC) for validation
 	allRecipients := make([]string, 0, len(notification.Recipients)+len(notification.CC)+len(notification.BCC))
 	allRecipients = append(allRecipients, notification.Recipients...)
@@ -90,12 +153,36 @@ func (s *SMTPNotifier) Send(ctx context.Context, notification *domain.Notificati
 	// Build email message
 	message := s.buildMessage(notification)
 
+	// Accounts in Gmail API mode post the message over HTTPS instead
+	if s.gmail != nil {
+		return s.sendGmail(ctx, notification, message)
+	}
+
 	// Send email
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 	auth := smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
 
 	// smtp.SendMail needs all recipients (To, CC, BCC) for actual delivery
-	err := smtp.SendMail(addr, auth, s.config.From, allRecipients, []byte(message))
+	var err error
+	usedBackup := false
+	if s.failover != nil {
+		backup := s.config.Backup
+		backupAddr := fmt.Sprintf("%s:%d", backup.Host, backup.Port)
+		usedBackup, err = s.failover.run(
+			func() error {
+				return smtp.SendMail(addr, auth, s.config.From, allRecipients, []byte(message))
+			},
+			func() error {
+				backupAuth := smtp.PlainAuth("", backup.Username, backup.Password, backup.Host)
+				return smtp.SendMail(backupAddr, backupAuth, s.config.From, allRecipients, []byte(message))
+			},
+		)
+		if usedBackup {
+			addr = backupAddr
+		}
+	} else {
+		err = smtp.SendMail(addr, auth, s.config.From, allRecipients, []byte(message))
+	}
 	if err != nil {
 		return &domain.NotificationResult{
 			NotificationID: notification.ID,
@@ -114,10 +201,16 @@ func (s *SMTPNotifier) Send(ctx context.Context, notification *domain.Notificati
 			"smtp_server": addr,
 			"from":        s.config.From,
 			"to":          notification.Recipients,
+			"failover":    usedBackup,
 		},
 	}, nil
 }
 
+// RenderMessage returns the RFC 5322 message that Send would deliver
+func (s *SMTPNotifier) RenderMessage(notification *domain.Notification) ([]byte, error) {
+	return []byte(s.buildMessage(notification)), nil
+}
+
 // buildMessage constructs the email message with headers
 func (s *SMTPNotifier) buildMessage(notification *domain.Notification) string {
 	var builder strings.Builder
@@ -142,21 +235,41 @@ func (s *SMTPNotifier) buildMessage(notification *domain.Notification) string {
 
 	// Note: BCC is intentionally NOT included in headers (that's the point of BCC!)
 
-	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", notification.Subject))
+	// Add Reply-To header (optional)
+	if notification.ReplyTo != "" {
+		builder.WriteString(fmt.Sprintf("Reply-To: %s\r\n", notification.ReplyTo))
+	}
+
+	// Add extra headers from options, in a stable order
+	if notification.Options != nil && notification.Options.Email != nil {
+		headers := notification.Options.Email.Headers
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			builder.WriteString(fmt.Sprintf("%s: %s\r\n", name, mime.QEncoding.Encode("utf-8", headers[name])))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject)))
 	builder.WriteString("MIME-Version: 1.0\r\n")
 
+	footer := s.sentFooter(time.Now())
+
 	switch {
 	case notification.HTMLBody != "":
 		// Caller provided distinct plain-text and HTML versions: send multipart/alternative
 		// using Body verbatim as text/plain and HTMLBody as text/html (no auto-strip).
-		s.buildMultipartMessage(&builder, notification.Body, notification.HTMLBody)
+		s.buildMultipartMessage(&builder, appendTextFooter(notification.Body, footer), appendHTMLFooter(notification.HTMLBody, footer))
 	case isHTMLContent(notification):
 		// Legacy path (deprecated): Body itself is HTML. Auto-derive a plain-text fallback.
-		s.buildMultipartMessage(&builder, htmlToPlainText(notification.Body), notification.Body)
+		s.buildMultipartMessage(&builder, appendTextFooter(emailhtml.PlainText(notification.Body), footer), appendHTMLFooter(notification.Body, footer))
 	default:
 		builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
 		builder.WriteString("\r\n")
-		builder.WriteString(notification.Body)
+		builder.WriteString(appendTextFooter(notification.Body, footer))
 	}
 
 	return builder.String()
@@ -219,23 +332,6 @@ func generateBoundary() string {
 	return "boundary_" + hex.EncodeToString(buf)
 }
 
-// htmlToPlainText converts HTML to plain text (simple implementation)
-func htmlToPlainText(htmlContent string) string {
-	// Remove HTML tags
-	re := regexp.MustCompile(`<[^>]*>`)
-	text := re.ReplaceAllString(htmlContent, "")
-
-	// Decode HTML entities
-	text = html.UnescapeString(text)
-
-	// Clean up whitespace
-	text = strings.ReplaceAll(text, "\r\n", "\n")
-	text = regexp.MustCompile(`\n{3,}`).ReplaceAllString(text, "\n\n")
-	text = strings.TrimSpace(text)
-
-	return text
-}
-
 // Validate checks if the notification is valid for SMTP
 func (s *SMTPNotifier) Validate(notification *domain.Notification) error {
 	if notification == nil {
@@ -262,3 +358,8 @@ func (s *SMTPNotifier) Validate(notification *domain.Notification) error {
 
 	return nil
 }
+
+// MetadataKeys reports that the SMTP notifier reads no metadata
+func (s *SMTPNotifier) MetadataKeys() []string {
+	return nil
+}
@@ -0,0 +1,418 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Web Push encryption parameters (RFC 8291, aes128gcm content coding)
+const (
+	webPushRecordSize = 4096
+	webPushSaltSize   = 16
+	webPushHeaderSize = webPushSaltSize + 4 + 1 + 65
+	// webPushMaxPayload leaves room for the header, GCM tag and padding delimiter in one record
+	webPushMaxPayload = webPushRecordSize - webPushHeaderSize - 16 - 1
+)
+
+// webPushTokenLifetime is how long VAPID tokens are valid; push services reject more than 24h
+const webPushTokenLifetime = 12 * time.Hour
+
+// WebPushConfig contains Web Push (VAPID) configuration
+type WebPushConfig struct {
+	VAPIDPublicKey  string        `mapstructure:"vapid_public_key"`  // Base64url uncompressed P-256 public key (derived from the private key if empty)
+	VAPIDPrivateKey string        `mapstructure:"vapid_private_key"` // Base64url P-256 private key
+	Subject         string        `mapstructure:"subject"`           // Contact for push services (mailto: or https: URL)
+	TTL             int           `mapstructure:"ttl"`               // Seconds push services keep undelivered messages (default: 86400)
+	Default         bool          `mapstructure:"default"`           // Mark this instance as default
+	AllowedRoles    []string      `mapstructure:"allowed_roles"`     // Roles allowed to use this notifier (empty = all authenticated)
+	Display         DisplayConfig `mapstructure:"display"`           // Timestamp rendering for recipients (timezone, locale, sent footer)
+
+	// Encryption seals titles and bodies to per-device public keys, on top of the Web Push
+	// encryption, so only the app can read them (optional). Subscriptions may carry their key in
+	// an e2e_public_key field; device_keys are keyed by the subscription's p256dh key.
+	Encryption *PushEncryptionConfig `mapstructure:"encryption"`
+}
+
+// WebPushNotifier sends browser notifications using the Web Push protocol
+type WebPushNotifier struct {
+	BaseNotifier
+	config     *WebPushConfig
+	httpClient *http.Client
+	signingKey *ecdsa.PrivateKey
+	publicKey  string
+	encryption *pushEncryption
+
+	mu     sync.Mutex
+	tokens map[string]webPushToken
+}
+
+// webPushToken is a cached VAPID token for one push service origin
+type webPushToken struct {
+	value   string
+	expires time.Time
+}
+
+// webPushSubscription is a browser PushSubscription as returned by PushSubscription.toJSON().
+// Apps using end-to-end encryption may add their device's public key to it.
+type webPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	E2EPublicKey string `json:"e2e_public_key,omitempty"`
+}
+
+// webPushPayload is the JSON delivered to the service worker's push event
+type webPushPayload struct {
+	ID    string                 `json:"id"`
+	Title string                 `json:"title,omitempty"`
+	Body  string                 `json:"body"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// GenerateVAPIDKeys returns a new base64url-encoded VAPID key pair
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate VAPID key: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// NewWebPushNotifier creates a new Web Push notifier
+func NewWebPushNotifier(config *WebPushConfig) (*WebPushNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Web Push config is required")
+	}
+	if config.VAPIDPrivateKey == "" {
+		return nil, fmt.Errorf("Web Push vapid_private_key is required")
+	}
+	if config.Subject == "" {
+		return nil, fmt.Errorf("Web Push subject is required (mailto: or https: contact)")
+	}
+	if config.TTL <= 0 {
+		config.TTL = 86400
+	}
+
+	raw, err := decodeBase64URL(config.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Web Push vapid_private_key: %w", err)
+	}
+	private, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Web Push vapid_private_key: %w", err)
+	}
+
+	publicKey := base64.RawURLEncoding.EncodeToString(private.PublicKey().Bytes())
+	if config.VAPIDPublicKey != "" && strings.TrimRight(config.VAPIDPublicKey, "=") != publicKey {
+		return nil, fmt.Errorf("Web Push vapid_public_key doesn't match vapid_private_key")
+	}
+
+	// Uncompressed point: 0x04 || X || Y
+	point := private.PublicKey().Bytes()
+	signingKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+
+	base, err := newBaseNotifier(domain.TypeWebPush, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	encryption, err := newPushEncryption("Web Push", config.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebPushNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		signingKey: signingKey,
+		publicKey:  publicKey,
+		encryption: encryption,
+		tokens:     make(map[string]webPushToken),
+	}, nil
+}
+
+// Send sends a push message to every recipient. Each recipient is a browser push
+// subscription in its JSON form: {"endpoint": ..., "keys": {"p256dh": ..., "auth": ...}}.
+func (p *WebPushNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := p.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = p.withSentFooter(notification)
+
+	payload := webPushPayload{
+		ID:    notification.ID,
+		Title: notification.Subject,
+		Body:  notification.Body,
+		Data:  notification.Metadata,
+	}
+	if _, err := marshalWebPush(payload); err != nil {
+		return nil, err
+	}
+
+	encrypted := 0
+	for _, recipient := range notification.Recipients {
+		sealed, err := p.sendMessage(ctx, notification, recipient, payload)
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		if sealed {
+			encrypted++
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("Web Push notification sent to %d subscriptions", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"encrypted_devices": encrypted,
+		},
+	}, nil
+}
+
+// marshalWebPush encodes a payload, checking it fits in a single Web Push record
+func marshalWebPush(payload webPushPayload) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Web Push payload: %w", err)
+	}
+	if len(data) > webPushMaxPayload {
+		return nil, fmt.Errorf("Web Push payload is %d bytes, exceeding the %d byte limit", len(data), webPushMaxPayload)
+	}
+	return data, nil
+}
+
+// sendMessage encrypts the payload for one subscription and posts it to its push service,
+// reporting whether its title and body were sealed to the device's public key
+func (p *WebPushNotifier) sendMessage(ctx context.Context, notification *domain.Notification, recipient string, payload webPushPayload) (bool, error) {
+	var sub webPushSubscription
+	if err := json.Unmarshal([]byte(recipient), &sub); err != nil {
+		return false, fmt.Errorf("invalid Web Push subscription: %w", err)
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return false, fmt.Errorf("invalid Web Push subscription endpoint: %q", sub.Endpoint)
+	}
+
+	title, text, sealed, err := p.encryption.seal(notification, sub.Keys.P256dh, sub.E2EPublicKey, payload.Title, payload.Body)
+	if err != nil {
+		return false, err
+	}
+	payload.Title, payload.Body = title, text
+	data, err := marshalWebPush(payload)
+	if err != nil {
+		return false, err
+	}
+
+	body, err := encryptWebPush(sub, data)
+	if err != nil {
+		return false, err
+	}
+
+	token, err := p.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(p.config.TTL))
+	req.Header.Set("Urgency", webPushUrgency(notification.Priority))
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, p.publicKey))
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send Web Push notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return false, newStatusCodeError(resp.StatusCode, "Web Push subscription has expired or been unsubscribed (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return false, newStatusCodeError(resp.StatusCode, "Web Push service returned status: %d", resp.StatusCode)
+	}
+
+	return sealed, nil
+}
+
+// webPushUrgency maps notification priority to the Web Push Urgency header
+func webPushUrgency(priority domain.Priority) string {
+	switch {
+	case priority >= domain.PriorityHigh:
+		return "high"
+	case priority == domain.PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// vapidToken returns a signed VAPID JWT for a push service origin, reusing it until it nears expiry
+func (p *WebPushNotifier) vapidToken(audience string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if cached, ok := p.tokens[audience]; ok && now.Add(time.Hour).Before(cached.expires) {
+		return cached.value, nil
+	}
+
+	expires := now.Add(webPushTokenLifetime)
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"exp": expires.Unix(),
+		"sub": p.config.Subject,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode VAPID claims: %w", err)
+	}
+	signingInput := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, p.signingKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+	p.tokens[audience] = webPushToken{value: token, expires: expires}
+	return token, nil
+}
+
+// encryptWebPush encrypts a payload for a subscription using the aes128gcm content coding
+func encryptWebPush(sub webPushSubscription, payload []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Web Push subscription p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Web Push subscription p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil || len(authSecret) == 0 {
+		return nil, fmt.Errorf("invalid Web Push subscription auth secret")
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Web Push key: %w", err)
+	}
+	salt := make([]byte, webPushSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate Web Push salt: %w", err)
+	}
+
+	return sealWebPush(uaPublic, authSecret, asPrivate, salt, payload)
+}
+
+// sealWebPush encrypts a payload with the given sender key and salt
+func sealWebPush(uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, payload []byte) ([]byte, error) {
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive Web Push secret: %w", err)
+	}
+
+	// RFC 8291 section 3.4: mix the auth secret and both public keys into the input keying material
+	keyInfo := "WebPush: info\x00" + string(uaPublic.Bytes()) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive Web Push key: %w", err)
+	}
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive Web Push key: %w", err)
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive Web Push nonce: %w", err)
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Web Push cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Web Push cipher: %w", err)
+	}
+
+	// Header: salt || record size || key ID length || key ID (the sender's public key)
+	body := make([]byte, 0, webPushHeaderSize+len(payload)+1+gcm.Overhead())
+	body = append(body, salt...)
+	body = binary.BigEndian.AppendUint32(body, webPushRecordSize)
+	body = append(body, byte(len(asPublic)))
+	body = append(body, asPublic...)
+
+	// A single record, terminated by the 0x02 last-record delimiter
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
+
+// decodeBase64URL decodes base64url with or without padding, as browsers and key tools vary
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// Close closes the HTTP client
+func (p *WebPushNotifier) Close() error {
+	p.httpClient.CloseIdleConnections()
+	return nil
+}
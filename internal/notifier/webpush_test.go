@@ -0,0 +1,324 @@
+package notifier
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/pkg/e2e"
+)
+
+// decryptWebPush decrypts an aes128gcm body the way a browser does (RFC 8291)
+func decryptWebPush(t *testing.T, uaPrivate *ecdh.PrivateKey, authSecret, body []byte) []byte {
+	t.Helper()
+
+	salt := body[:16]
+	if rs := binary.BigEndian.Uint32(body[16:20]); rs != webPushRecordSize {
+		t.Errorf("Record size = %d", rs)
+	}
+	keyIDLen := int(body[20])
+	asPublicRaw := body[21 : 21+keyIDLen]
+	ciphertext := body[21+keyIDLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	if err != nil {
+		t.Fatalf("Invalid sender key: %v", err)
+	}
+	shared, err := uaPrivate.ECDH(asPublic)
+	if err != nil {
+		t.Fatalf("ECDH failed: %v", err)
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPrivate.PublicKey().Bytes()) + string(asPublicRaw)
+	ikm, _ := hkdf.Key(sha256.New, shared, authSecret, keyInfo, 32)
+	cek, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	nonce, _ := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		t.Fatalf("Failed to decrypt payload: %v", err)
+	}
+	if plaintext[len(plaintext)-1] != 0x02 {
+		t.Fatalf("Missing last-record delimiter")
+	}
+	return plaintext[:len(plaintext)-1]
+}
+
+// verifyVAPID checks the Authorization header's JWT signature against its public key
+func verifyVAPID(t *testing.T, header string) map[string]interface{} {
+	t.Helper()
+
+	var token, key string
+	for _, part := range strings.Split(strings.TrimPrefix(header, "vapid "), ", ") {
+		if v, ok := strings.CutPrefix(part, "t="); ok {
+			token = v
+		}
+		if v, ok := strings.CutPrefix(part, "k="); ok {
+			key = v
+		}
+	}
+
+	point, _ := base64.RawURLEncoding.DecodeString(key)
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(point[1:33]), Y: new(big.Int).SetBytes(point[33:])}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Malformed VAPID token: %q", token)
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(publicKey, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		t.Fatal("VAPID signature doesn't verify")
+	}
+
+	var claims map[string]interface{}
+	data, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(data, &claims)
+	return claims
+}
+
+// TestWebPushSend tests payload encryption, VAPID authentication and delivery headers
+func TestWebPushSend(t *testing.T) {
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate subscription key: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	var payload webPushPayload
+	var claims map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || r.Header.Get("TTL") != "600" || r.Header.Get("Urgency") != "high" {
+			t.Errorf("Unexpected headers: %v", r.Header)
+		}
+		claims = verifyVAPID(t, r.Header.Get("Authorization"))
+
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(decryptWebPush(t, uaPrivate, authSecret, body), &payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys() error = %v", err)
+	}
+	webPush, err := NewWebPushNotifier(&WebPushConfig{
+		VAPIDPublicKey:  publicKey,
+		VAPIDPrivateKey: privateKey,
+		Subject:         "mailto:ops@example.com",
+		TTL:             600,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Web Push notifier: %v", err)
+	}
+
+	subscription, _ := json.Marshal(map[string]interface{}{
+		"endpoint": server.URL + "/push/abc",
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(authSecret),
+		},
+	})
+
+	result, err := webPush.Send(context.Background(), &domain.Notification{
+		ID:         "wp-1",
+		Type:       domain.TypeWebPush,
+		Subject:    "Deploy finished",
+		Body:       "api v2.3 is live",
+		Priority:   domain.PriorityCritical,
+		Recipients: []string{string(subscription)},
+		Metadata:   map[string]interface{}{"url": "https://example.com/deploys/42"},
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("Send failed: %+v, %v", result, err)
+	}
+
+	if payload.ID != "wp-1" || payload.Title != "Deploy finished" || payload.Body != "api v2.3 is live" || payload.Data["url"] != "https://example.com/deploys/42" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if claims["aud"] != server.URL || claims["sub"] != "mailto:ops@example.com" {
+		t.Errorf("Unexpected VAPID claims: %v", claims)
+	}
+}
+
+// TestWebPushEncryptedSend tests that a subscription carrying an e2e_public_key gets its title
+// and body sealed to it inside the Web Push encryption
+func TestWebPushEncryptedSend(t *testing.T) {
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate subscription key: %v", err)
+	}
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	var payload webPushPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(decryptWebPush(t, uaPrivate, authSecret, body), &payload); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	_, vapidPrivate, _ := GenerateVAPIDKeys()
+	webPush, err := NewWebPushNotifier(&WebPushConfig{
+		VAPIDPrivateKey: vapidPrivate,
+		Subject:         "mailto:ops@example.com",
+		Encryption:      &PushEncryptionConfig{Require: true},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create Web Push notifier: %v", err)
+	}
+
+	deviceKey, devicePrivate, _ := e2e.GenerateKey()
+	subscription := map[string]interface{}{
+		"endpoint": server.URL + "/push/abc",
+		"keys": map[string]string{
+			"p256dh": base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()),
+			"auth":   base64.RawURLEncoding.EncodeToString(authSecret),
+		},
+		"e2e_public_key": deviceKey,
+	}
+	recipient, _ := json.Marshal(subscription)
+
+	notification := &domain.Notification{
+		ID:         "wp-e2e",
+		Type:       domain.TypeWebPush,
+		Subject:    "Invoice ready",
+		Body:       "Invoice 2041 totals $310",
+		Recipients: []string{string(recipient)},
+	}
+	result, err := webPush.Send(context.Background(), notification)
+	if err != nil || !result.Success {
+		t.Fatalf("Send failed: %+v, %v", result, err)
+	}
+
+	if payload.ID != "wp-e2e" || payload.Title != "Encrypted notification" || strings.Contains(payload.Body, "2041") {
+		t.Errorf("Expected the push to hide its content, got %+v", payload)
+	}
+	key, _ := e2e.ParsePrivateKey(devicePrivate)
+	sealed, err := e2e.OpenPayload(key, payload.Body)
+	if err != nil {
+		t.Fatalf("App failed to decrypt: %v", err)
+	}
+	if sealed.Title != "Invoice ready" || sealed.Message != "Invoice 2041 totals $310" {
+		t.Errorf("Decrypted payload = %+v", sealed)
+	}
+
+	// Encryption is required, so a subscription without a key is refused
+	delete(subscription, "e2e_public_key")
+	recipient, _ = json.Marshal(subscription)
+	notification.Recipients = []string{string(recipient)}
+	if _, err := webPush.Send(context.Background(), notification); err == nil {
+		t.Error("Expected a subscription without a key to be refused")
+	}
+}
+
+// TestSealWebPushRFC8291 tests encryption against the example in RFC 8291 Appendix A
+func TestSealWebPushRFC8291(t *testing.T) {
+	decode := func(s string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("Bad test vector %q: %v", s, err)
+		}
+		return data
+	}
+
+	asPrivate, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatalf("Invalid sender key: %v", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(decode("BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"))
+	if err != nil {
+		t.Fatalf("Invalid receiver key: %v", err)
+	}
+
+	body, err := sealWebPush(uaPublic, decode("BTBZMqHH6r4Tts7J_aSIgg"), asPrivate, decode("DGv6ra1nlYgDCS1FRnbzlw"),
+		[]byte("When I grow up, I want to be a watermelon"))
+	if err != nil {
+		t.Fatalf("sealWebPush() error = %v", err)
+	}
+
+	want := "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN"
+	if got := base64.RawURLEncoding.EncodeToString(body); got != want {
+		t.Errorf("sealWebPush() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestWebPushExpiredSubscription tests that a gone subscription fails with its status
+func TestWebPushExpiredSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	_, privateKey, _ := GenerateVAPIDKeys()
+	webPush, err := NewWebPushNotifier(&WebPushConfig{VAPIDPrivateKey: privateKey, Subject: "https://example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create Web Push notifier: %v", err)
+	}
+
+	uaPrivate, _ := ecdh.P256().GenerateKey(rand.Reader)
+	subscription := `{"endpoint":"` + server.URL + `/push/gone","keys":{"p256dh":"` +
+		base64.RawURLEncoding.EncodeToString(uaPrivate.PublicKey().Bytes()) + `","auth":"c2VjcmV0c2VjcmV0MTIzNA"}}`
+
+	result, err := webPush.Send(context.Background(), &domain.Notification{
+		ID:         "wp-2",
+		Type:       domain.TypeWebPush,
+		Body:       "Hello",
+		Recipients: []string{subscription},
+	})
+	if err == nil || result == nil || result.Success || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("Expected expired subscription error, got %+v, %v", result, err)
+	}
+}
+
+// TestNewWebPushNotifierValidation tests VAPID key validation
+func TestNewWebPushNotifierValidation(t *testing.T) {
+	publicKey, privateKey, _ := GenerateVAPIDKeys()
+	otherPublic, _, _ := GenerateVAPIDKeys()
+
+	tests := []struct {
+		name   string
+		config *WebPushConfig
+	}{
+		{"missing private key", &WebPushConfig{Subject: "mailto:ops@example.com"}},
+		{"missing subject", &WebPushConfig{VAPIDPrivateKey: privateKey}},
+		{"invalid private key", &WebPushConfig{VAPIDPrivateKey: "not-a-key", Subject: "mailto:ops@example.com"}},
+		{"mismatched public key", &WebPushConfig{VAPIDPublicKey: otherPublic, VAPIDPrivateKey: privateKey, Subject: "mailto:ops@example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWebPushNotifier(tt.config); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := NewWebPushNotifier(&WebPushConfig{VAPIDPublicKey: publicKey, VAPIDPrivateKey: privateKey, Subject: "mailto:ops@example.com"}); err != nil {
+		t.Errorf("Expected matching key pair to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// WhatsAppConfig contains WhatsApp Business Cloud API configuration
+type WhatsAppConfig struct {
+	AccessToken     string        `mapstructure:"access_token"`     // System user access token with whatsapp_business_messaging permission
+	PhoneNumberID   string        `mapstructure:"phone_number_id"`  // ID of the business phone number messages are sent from
+	APIURL          string        `mapstructure:"api_url"`          // Graph API base URL (default: https://graph.facebook.com/v21.0)
+	DefaultLanguage string        `mapstructure:"default_language"` // Template language when metadata has none (default: en_US)
+	Default         bool          `mapstructure:"default"`          // Mark this instance as default
+	AllowedRoles    []string      `mapstructure:"allowed_roles"`    // Roles allowed to use this notifier (empty = all authenticated)
+	Display         DisplayConfig `mapstructure:"display"`          // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// WhatsAppNotifier sends WhatsApp messages through the Meta Cloud API
+type WhatsAppNotifier struct {
+	BaseNotifier
+	config     *WhatsAppConfig
+	httpClient *http.Client
+}
+
+// whatsAppMessage represents the Cloud API send message request
+type whatsAppMessage struct {
+	MessagingProduct string            `json:"messaging_product"`
+	RecipientType    string            `json:"recipient_type"`
+	To               string            `json:"to"`
+	Type             string            `json:"type"`
+	Text             *whatsAppText     `json:"text,omitempty"`
+	Template         *whatsAppTemplate `json:"template,omitempty"`
+}
+
+// whatsAppText is a free-form text message body
+type whatsAppText struct {
+	PreviewURL bool   `json:"preview_url"`
+	Body       string `json:"body"`
+}
+
+// whatsAppTemplate is a pre-approved message template
+type whatsAppTemplate struct {
+	Name       string              `json:"name"`
+	Language   whatsAppLanguage    `json:"language"`
+	Components []whatsAppComponent `json:"components,omitempty"`
+}
+
+// whatsAppLanguage selects a template's translation
+type whatsAppLanguage struct {
+	Code string `json:"code"`
+}
+
+// whatsAppComponent fills the parameters of one part of a template
+type whatsAppComponent struct {
+	Type       string              `json:"type"`
+	Parameters []whatsAppParameter `json:"parameters"`
+}
+
+// whatsAppParameter is one template parameter
+type whatsAppParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// whatsAppResponse represents the Cloud API response
+type whatsAppResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+	Error *struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error,omitempty"`
+}
+
+// whatsAppMaxText is the longest text message body WhatsApp accepts
+const whatsAppMaxText = 4096
+
+// e164Pattern matches an E.164 phone number
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NewWhatsAppNotifier creates a new WhatsApp notifier
+func NewWhatsAppNotifier(config *WhatsAppConfig) (*WhatsAppNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("WhatsApp config is required")
+	}
+
+	if config.AccessToken == "" {
+		return nil, fmt.Errorf("WhatsApp access token is required")
+	}
+
+	if config.PhoneNumberID == "" {
+		return nil, fmt.Errorf("WhatsApp phone number ID is required")
+	}
+
+	if config.APIURL == "" {
+		config.APIURL = "https://graph.facebook.com/v21.0"
+	}
+	config.APIURL = strings.TrimSuffix(config.APIURL, "/")
+	if config.DefaultLanguage == "" {
+		config.DefaultLanguage = "en_US"
+	}
+
+	base, err := newBaseNotifier(domain.TypeWhatsApp, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WhatsAppNotifier{
+		BaseNotifier: base,
+		config:       config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// Validate checks the notification and that every recipient is an E.164 phone number
+func (w *WhatsAppNotifier) Validate(notification *domain.Notification) error {
+	if err := w.BaseNotifier.Validate(notification); err != nil {
+		return err
+	}
+
+	for _, recipient := range notification.Recipients {
+		if !e164Pattern.MatchString(recipient) {
+			return fmt.Errorf("invalid WhatsApp recipient %q: must be an E.164 phone number (e.g., +15551234567)", recipient)
+		}
+	}
+
+	return nil
+}
+
+// Send sends a WhatsApp message to every recipient.
+// Metadata "template" sends an approved template instead of text, with "template_language"
+// and "template_params" (a list of body parameters). Text messages can only be sent to users
+// who messaged the business in the last 24 hours; templates can be sent at any time.
+func (w *WhatsAppNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := w.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = w.withSentFooter(notification)
+
+	messageIDs := make(map[string]interface{}, len(notification.Recipients))
+	for _, recipient := range notification.Recipients {
+		msg := w.buildMessage(notification, recipient)
+		id, err := w.sendMessage(ctx, msg)
+		if err != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+		messageIDs[recipient] = id
+	}
+
+	kind := "text"
+	if _, ok := notification.Metadata["template"].(string); ok {
+		kind = "template"
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("WhatsApp %s message sent to %d recipients", kind, len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"phone_number_id": w.config.PhoneNumberID,
+			"message_ids":     messageIDs,
+		},
+	}, nil
+}
+
+// buildMessage constructs the message for one recipient
+func (w *WhatsAppNotifier) buildMessage(notification *domain.Notification, recipient string) *whatsAppMessage {
+	msg := &whatsAppMessage{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               strings.TrimPrefix(recipient, "+"),
+	}
+
+	if name, ok := notification.Metadata["template"].(string); ok && name != "" {
+		language := w.config.DefaultLanguage
+		if code, ok := notification.Metadata["template_language"].(string); ok && code != "" {
+			language = code
+		}
+		msg.Type = "template"
+		msg.Template = &whatsAppTemplate{Name: name, Language: whatsAppLanguage{Code: language}}
+		if params := templateParams(notification.Metadata["template_params"]); len(params) > 0 {
+			msg.Template.Components = []whatsAppComponent{{Type: "body", Parameters: params}}
+		}
+		return msg
+	}
+
+	body := notification.Body
+	if notification.Subject != "" {
+		body = "*" + notification.Subject + "*\n\n" + body
+	}
+	msg.Type = "text"
+	msg.Text = &whatsAppText{Body: truncateRunes(body, whatsAppMaxText)}
+	return msg
+}
+
+// templateParams converts the template_params metadata list to text parameters
+func templateParams(value interface{}) []whatsAppParameter {
+	var values []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		values = v
+	case []string:
+		for _, s := range v {
+			values = append(values, s)
+		}
+	default:
+		return nil
+	}
+
+	params := make([]whatsAppParameter, 0, len(values))
+	for _, v := range values {
+		params = append(params, whatsAppParameter{Type: "text", Text: fmt.Sprint(v)})
+	}
+	return params
+}
+
+// sendMessage posts a message to the Cloud API and returns its message ID
+func (w *WhatsAppNotifier) sendMessage(ctx context.Context, msg *whatsAppMessage) (string, error) {
+	jsonData, err := json.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal WhatsApp message: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/%s/messages", w.config.APIURL, w.config.PhoneNumberID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+w.config.AccessToken)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var msgResp whatsAppResponse
+	_ = json.NewDecoder(resp.Body).Decode(&msgResp)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if msgResp.Error != nil {
+			return "", newStatusCodeError(resp.StatusCode, "WhatsApp API returned status: %d (%d: %s)", resp.StatusCode, msgResp.Error.Code, msgResp.Error.Message)
+		}
+		return "", newStatusCodeError(resp.StatusCode, "WhatsApp API returned status: %d", resp.StatusCode)
+	}
+
+	if len(msgResp.Messages) == 0 {
+		return "", nil
+	}
+	return msgResp.Messages[0].ID, nil
+}
+
+// MetadataKeys returns the metadata keys the WhatsApp notifier reads
+func (w *WhatsAppNotifier) MetadataKeys() []string {
+	return []string{"template", "template_language", "template_params"}
+}
+
+// Close closes the HTTP client
+func (w *WhatsAppNotifier) Close() error {
+	w.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,118 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// newWhatsAppTestServer records received messages and answers like the Cloud API
+func newWhatsAppTestServer(t *testing.T, received *[]whatsAppMessage) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/12345/messages" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"Invalid OAuth access token","code":190}}`))
+			return
+		}
+		var msg whatsAppMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		*received = append(*received, msg)
+		_, _ = w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.` + msg.To + `"}]}`))
+	}))
+}
+
+// TestWhatsAppSend tests text and template messages
+func TestWhatsAppSend(t *testing.T) {
+	var received []whatsAppMessage
+	server := newWhatsAppTestServer(t, &received)
+	defer server.Close()
+
+	wa, err := NewWhatsAppNotifier(&WhatsAppConfig{AccessToken: "token", PhoneNumberID: "12345", APIURL: server.URL + "/"})
+	if err != nil {
+		t.Fatalf("Failed to create WhatsApp notifier: %v", err)
+	}
+
+	result, err := wa.Send(context.Background(), &domain.Notification{
+		ID:         "wa-1",
+		Type:       domain.TypeWhatsApp,
+		Subject:    "Order shipped",
+		Body:       "Your order is on its way",
+		Recipients: []string{"+15551234567", "+447700900123"},
+	})
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if !result.Success || len(received) != 2 {
+		t.Fatalf("Expected two messages, got %d: %+v", len(received), result)
+	}
+	if received[0].To != "15551234567" || received[0].Type != "text" || received[0].Text.Body != "*Order shipped*\n\nYour order is on its way" {
+		t.Errorf("Unexpected text message: %+v", received[0])
+	}
+	if ids := result.ProviderResponse["message_ids"].(map[string]interface{}); ids["+447700900123"] != "wamid.447700900123" {
+		t.Errorf("Unexpected message IDs: %v", ids)
+	}
+
+	received = nil
+	_, err = wa.Send(context.Background(), &domain.Notification{
+		ID:         "wa-2",
+		Type:       domain.TypeWhatsApp,
+		Body:       "fallback",
+		Recipients: []string{"+15551234567"},
+		Metadata: map[string]interface{}{
+			"template":          "order_update",
+			"template_language": "en_GB",
+			"template_params":   []interface{}{"Jo", 42},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Template send failed: %v", err)
+	}
+	tmpl := received[0].Template
+	if received[0].Type != "template" || tmpl == nil || tmpl.Name != "order_update" || tmpl.Language.Code != "en_GB" ||
+		len(tmpl.Components) != 1 || tmpl.Components[0].Parameters[1].Text != "42" {
+		t.Errorf("Unexpected template message: %+v", received[0])
+	}
+}
+
+// TestWhatsAppValidation tests recipient validation and API errors
+func TestWhatsAppValidation(t *testing.T) {
+	var received []whatsAppMessage
+	server := newWhatsAppTestServer(t, &received)
+	defer server.Close()
+
+	if _, err := NewWhatsAppNotifier(&WhatsAppConfig{AccessToken: "token"}); err == nil {
+		t.Error("Expected an error without a phone number ID")
+	}
+
+	wa, err := NewWhatsAppNotifier(&WhatsAppConfig{AccessToken: "token", PhoneNumberID: "12345", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("Failed to create WhatsApp notifier: %v", err)
+	}
+	for _, recipient := range []string{"15551234567", "+0555123", "+1 555 123 4567", "+1555"} {
+		_, err := wa.Send(context.Background(), &domain.Notification{
+			Type: domain.TypeWhatsApp, Body: "hi", Recipients: []string{"+15551234567", recipient},
+		})
+		if err == nil || !strings.Contains(err.Error(), "E.164") {
+			t.Errorf("Expected %q to be rejected, got %v", recipient, err)
+		}
+	}
+	if len(received) != 0 {
+		t.Errorf("Expected nothing sent when a recipient is invalid, got %d messages", len(received))
+	}
+
+	wa.config.AccessToken = "expired"
+	result, err := wa.Send(context.Background(), &domain.Notification{
+		Type: domain.TypeWhatsApp, Body: "hi", Recipients: []string{"+15551234567"},
+	})
+	if err == nil || result.Success || !strings.Contains(err.Error(), "Invalid OAuth access token") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
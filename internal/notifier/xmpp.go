@@ -0,0 +1,642 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// XMPPConfig contains XMPP (Jabber) client configuration
+type XMPPConfig struct {
+	JID          string        `mapstructure:"jid"`           // Account to send as (e.g., alerts@example.com/notifier)
+	Password     string        `mapstructure:"password"`      // Account password, sent with SASL PLAIN over TLS
+	Server       string        `mapstructure:"server"`        // Server host:port (default: the JID's domain on port 5222, or 5223 with direct_tls)
+	DirectTLS    bool          `mapstructure:"direct_tls"`    // Connect with TLS from the start instead of STARTTLS
+	CACertPath   string        `mapstructure:"ca_cert_path"`  // Custom CA certificate (PEM) for self-hosted servers
+	KeepAlive    int           `mapstructure:"keepalive"`     // Seconds between whitespace keepalives on the idle connection (default: 60, -1 disables)
+	Timeout      int           `mapstructure:"timeout"`       // Seconds allowed to connect or write (default: 30)
+	Default      bool          `mapstructure:"default"`       // Mark this instance as default
+	AllowedRoles []string      `mapstructure:"allowed_roles"` // Roles allowed to use this notifier (empty = all authenticated)
+	Display      DisplayConfig `mapstructure:"display"`       // Timestamp rendering for recipients (timezone, locale, sent footer)
+}
+
+// XMPP namespaces
+const (
+	xmppNSClient  = "jabber:client"
+	xmppNSStream  = "http://etherx.jabber.org/streams"
+	xmppNSTLS     = "urn:ietf:params:xml:ns:xmpp-tls"
+	xmppNSSASL    = "urn:ietf:params:xml:ns:xmpp-sasl"
+	xmppNSBind    = "urn:ietf:params:xml:ns:xmpp-bind"
+	xmppNSSession = "urn:ietf:params:xml:ns:xmpp-session"
+	xmppNSPing    = "urn:xmpp:ping"
+)
+
+// XMPPNotifier sends chat messages to XMPP addresses over one long-lived client connection.
+// The connection is opened on first use, re-established if the server drops it, and closed
+// by Close.
+type XMPPNotifier struct {
+	BaseNotifier
+	config    *XMPPConfig
+	jid       xmppJID
+	server    string
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	keepAlive time.Duration
+
+	mu      sync.Mutex
+	session *xmppSession
+	closed  bool
+}
+
+// xmppJID is a parsed XMPP address: local@domain/resource
+type xmppJID struct {
+	local, domain, resource string
+}
+
+// parseXMPPJID splits a JID into its parts
+func parseXMPPJID(jid string) (xmppJID, error) {
+	var parsed xmppJID
+	rest := jid
+	if i := strings.Index(rest, "/"); i >= 0 {
+		parsed.resource = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "@"); i >= 0 {
+		parsed.local = rest[:i]
+		rest = rest[i+1:]
+	}
+	parsed.domain = rest
+	if parsed.domain == "" || strings.ContainsAny(jid, " \t\r\n") {
+		return xmppJID{}, fmt.Errorf("invalid JID: %q", jid)
+	}
+	return parsed, nil
+}
+
+// xmppFeatures is the stream features element advertised by the server
+type xmppFeatures struct {
+	XMLName  xml.Name `xml:"http://etherx.jabber.org/streams features"`
+	StartTLS *struct {
+		Required *struct{} `xml:"required"`
+	} `xml:"urn:ietf:params:xml:ns:xmpp-tls starttls"`
+	Mechanisms *struct {
+		Mechanism []string `xml:"mechanism"`
+	} `xml:"urn:ietf:params:xml:ns:xmpp-sasl mechanisms"`
+	Bind    *struct{} `xml:"urn:ietf:params:xml:ns:xmpp-bind bind"`
+	Session *struct {
+		Optional *struct{} `xml:"optional"`
+	} `xml:"urn:ietf:params:xml:ns:xmpp-session session"`
+}
+
+// xmppMessage is an outgoing chat message stanza
+type xmppMessage struct {
+	XMLName xml.Name `xml:"jabber:client message"`
+	To      string   `xml:"to,attr"`
+	Type    string   `xml:"type,attr"`
+	ID      string   `xml:"id,attr"`
+	Subject string   `xml:"subject,omitempty"`
+	Body    string   `xml:"body"`
+}
+
+// xmppIQ is an IQ stanza with its payload kept as raw XML
+type xmppIQ struct {
+	XMLName xml.Name `xml:"jabber:client iq"`
+	ID      string   `xml:"id,attr"`
+	Type    string   `xml:"type,attr"`
+	From    string   `xml:"from,attr,omitempty"`
+	To      string   `xml:"to,attr,omitempty"`
+	Inner   []byte   `xml:",innerxml"`
+}
+
+// NewXMPPNotifier creates a new XMPP notifier. It doesn't connect until the first send.
+func NewXMPPNotifier(config *XMPPConfig) (*XMPPNotifier, error) {
+	if config == nil {
+		return nil, fmt.Errorf("XMPP config is required")
+	}
+	if config.JID == "" || config.Password == "" {
+		return nil, fmt.Errorf("XMPP jid and password are required")
+	}
+
+	jid, err := parseXMPPJID(config.JID)
+	if err != nil {
+		return nil, err
+	}
+	if jid.local == "" {
+		return nil, fmt.Errorf("XMPP jid must include a username: %q", config.JID)
+	}
+	if jid.resource == "" {
+		jid.resource = "notifier"
+	}
+
+	server := config.Server
+	if server == "" {
+		port := "5222"
+		if config.DirectTLS {
+			port = "5223"
+		}
+		server = net.JoinHostPort(jid.domain, port)
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: jid.domain,
+		MinVersion: tls.VersionTLS12,
+	}
+	if config.CACertPath != "" {
+		certData, err := os.ReadFile(config.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read custom CA certificate: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(certData) {
+			return nil, fmt.Errorf("failed to parse custom CA certificate as PEM")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	timeout := 30 * time.Second
+	if config.Timeout > 0 {
+		timeout = time.Duration(config.Timeout) * time.Second
+	}
+	keepAlive := 60 * time.Second
+	if config.KeepAlive > 0 {
+		keepAlive = time.Duration(config.KeepAlive) * time.Second
+	} else if config.KeepAlive < 0 {
+		keepAlive = 0
+	}
+
+	base, err := newBaseNotifier(domain.TypeXMPP, config.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return &XMPPNotifier{
+		BaseNotifier: base,
+		config:       config,
+		jid:          jid,
+		server:       server,
+		tlsConfig:    tlsConfig,
+		timeout:      timeout,
+		keepAlive:    keepAlive,
+	}, nil
+}
+
+// Validate checks that every recipient is a JID
+func (x *XMPPNotifier) Validate(notification *domain.Notification) error {
+	if err := x.BaseNotifier.Validate(notification); err != nil {
+		return err
+	}
+	for _, recipient := range notification.Recipients {
+		if _, err := parseXMPPJID(recipient); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send sends the notification as a chat message to each recipient JID
+func (x *XMPPNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := ValidateContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := x.Validate(notification); err != nil {
+		return nil, err
+	}
+
+	notification = x.withSentFooter(notification)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	sent := 0
+	for attempt := 0; ; attempt++ {
+		session, reused, err := x.connect(ctx)
+		if err == nil {
+			for ; sent < len(notification.Recipients); sent++ {
+				msg := xmppMessage{
+					To:      notification.Recipients[sent],
+					Type:    "chat",
+					ID:      uuid.New().String(),
+					Subject: notification.Subject,
+					Body:    notification.Body,
+				}
+				if err = session.writeStanza(msg, x.timeout); err != nil {
+					x.dropSession()
+					break
+				}
+			}
+		}
+		if err == nil {
+			break
+		}
+
+		// A connection that was already open may have gone stale, so retry once on a new one
+		if !reused || attempt > 0 || ctx.Err() != nil {
+			return &domain.NotificationResult{
+				NotificationID: notification.ID,
+				Success:        false,
+				Error:          err.Error(),
+				SentAt:         time.Now(),
+			}, err
+		}
+	}
+
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        fmt.Sprintf("XMPP message sent to %d recipients", len(notification.Recipients)),
+		SentAt:         time.Now(),
+		ProviderResponse: map[string]interface{}{
+			"recipients": notification.Recipients,
+		},
+	}, nil
+}
+
+// connect returns the open session, reporting whether it was reused, or establishes a new
+// one if there is none or the server has closed it. The caller must hold mu.
+func (x *XMPPNotifier) connect(ctx context.Context) (*xmppSession, bool, error) {
+	if x.closed {
+		return nil, false, fmt.Errorf("XMPP notifier is closed")
+	}
+	if x.session != nil && !x.session.isClosed() {
+		return x.session, true, nil
+	}
+
+	session, err := dialXMPP(ctx, x.server, x.jid, x.config.Password, x.config.DirectTLS, x.tlsConfig, x.timeout)
+	if err != nil {
+		return nil, false, err
+	}
+	session.run(x.keepAlive, x.timeout)
+	x.session = session
+	return session, false, nil
+}
+
+// dropSession closes the current session, if any. The caller must hold mu.
+func (x *XMPPNotifier) dropSession() {
+	if x.session != nil {
+		x.session.close()
+		x.session = nil
+	}
+}
+
+// MetadataKeys reports that the XMPP notifier reads no metadata
+func (x *XMPPNotifier) MetadataKeys() []string {
+	return nil
+}
+
+// Close ends the XMPP stream and closes the connection
+func (x *XMPPNotifier) Close() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.closed = true
+	if x.session != nil {
+		x.session.end(x.timeout)
+		x.session = nil
+	}
+	return nil
+}
+
+// xmppSession is an authenticated, bound client stream
+type xmppSession struct {
+	conn    net.Conn
+	dec     *xml.Decoder
+	writeMu sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+}
+
+// dialXMPP connects to the server and negotiates TLS, SASL PLAIN authentication and a resource
+func dialXMPP(ctx context.Context, server string, jid xmppJID, password string, directTLS bool, tlsConfig *tls.Config, timeout time.Duration) (*xmppSession, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to XMPP server: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	if directTLS {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("XMPP TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	session := &xmppSession{conn: conn, done: make(chan struct{})}
+	if err := session.negotiate(ctx, jid, password, directTLS, tlsConfig); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	return session, nil
+}
+
+// negotiate runs stream negotiation up to a bound resource
+func (s *xmppSession) negotiate(ctx context.Context, jid xmppJID, password string, secure bool, tlsConfig *tls.Config) error {
+	features, err := s.openStream(jid.domain)
+	if err != nil {
+		return err
+	}
+
+	if !secure {
+		if features.StartTLS == nil {
+			return fmt.Errorf("XMPP server does not offer STARTTLS")
+		}
+		if _, err := fmt.Fprintf(s.conn, "<starttls xmlns='%s'/>", xmppNSTLS); err != nil {
+			return fmt.Errorf("failed to start XMPP TLS: %w", err)
+		}
+		start, _, err := s.nextElement()
+		if err != nil {
+			return fmt.Errorf("failed to start XMPP TLS: %w", err)
+		}
+		if start.Name.Local != "proceed" {
+			return fmt.Errorf("XMPP server refused STARTTLS")
+		}
+
+		tlsConn := tls.Client(s.conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("XMPP TLS handshake failed: %w", err)
+		}
+		s.conn = tlsConn
+		if features, err = s.openStream(jid.domain); err != nil {
+			return err
+		}
+	}
+
+	// Authenticate with SASL PLAIN, which is safe now the stream is encrypted
+	if features.Mechanisms == nil || !slices.Contains(features.Mechanisms.Mechanism, "PLAIN") {
+		return fmt.Errorf("XMPP server does not offer SASL PLAIN authentication")
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte("\x00" + jid.local + "\x00" + password))
+	if _, err := fmt.Fprintf(s.conn, "<auth xmlns='%s' mechanism='PLAIN'>%s</auth>", xmppNSSASL, credentials); err != nil {
+		return fmt.Errorf("failed to authenticate with XMPP server: %w", err)
+	}
+	start, inner, err := s.nextElement()
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with XMPP server: %w", err)
+	}
+	if start.Name.Local != "success" {
+		return fmt.Errorf("XMPP authentication failed: %s", errorCondition(inner))
+	}
+
+	if features, err = s.openStream(jid.domain); err != nil {
+		return err
+	}
+	if features.Bind == nil {
+		return fmt.Errorf("XMPP server does not offer resource binding")
+	}
+	bind := fmt.Sprintf("<bind xmlns='%s'><resource>%s</resource></bind>", xmppNSBind, xmlEscape(jid.resource))
+	if err := s.request("bind", bind); err != nil {
+		return fmt.Errorf("XMPP resource binding failed: %w", err)
+	}
+
+	// Legacy servers require a session to be established before sending
+	if features.Session != nil && features.Session.Optional == nil {
+		if err := s.request("session", fmt.Sprintf("<session xmlns='%s'/>", xmppNSSession)); err != nil {
+			return fmt.Errorf("XMPP session establishment failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// openStream sends a stream header and reads the server's stream header and features
+func (s *xmppSession) openStream(domainName string) (*xmppFeatures, error) {
+	header := fmt.Sprintf("<?xml version='1.0'?><stream:stream to='%s' xmlns='%s' xmlns:stream='%s' version='1.0'>",
+		xmlEscape(domainName), xmppNSClient, xmppNSStream)
+	if _, err := io.WriteString(s.conn, header); err != nil {
+		return nil, fmt.Errorf("failed to open XMPP stream: %w", err)
+	}
+
+	s.dec = xml.NewDecoder(s.conn)
+	for {
+		token, err := s.dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XMPP stream: %w", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Space != xmppNSStream || start.Name.Local != "stream" {
+				return nil, fmt.Errorf("unexpected XMPP stream element: %s", start.Name.Local)
+			}
+			break
+		}
+	}
+
+	start, inner, err := s.nextElement()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XMPP stream features: %w", err)
+	}
+	if start.Name.Space != xmppNSStream || start.Name.Local != "features" {
+		return nil, fmt.Errorf("unexpected XMPP element: %s", start.Name.Local)
+	}
+	var features xmppFeatures
+	document := "<features xmlns='" + xmppNSStream + "'>" + string(inner) + "</features>"
+	if err := xml.Unmarshal([]byte(document), &features); err != nil {
+		return nil, fmt.Errorf("failed to read XMPP stream features: %w", err)
+	}
+	return &features, nil
+}
+
+// nextElement reads the next top-level element, returning its start tag and inner XML.
+// Stream errors and the end of the stream are returned as errors.
+func (s *xmppSession) nextElement() (xml.StartElement, []byte, error) {
+	for {
+		token, err := s.dec.Token()
+		if err != nil {
+			return xml.StartElement{}, nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var element struct {
+				Inner []byte `xml:",innerxml"`
+			}
+			if err := s.dec.DecodeElement(&element, &t); err != nil {
+				return xml.StartElement{}, nil, err
+			}
+			if t.Name.Space == xmppNSStream && t.Name.Local == "error" {
+				return xml.StartElement{}, nil, fmt.Errorf("XMPP stream error: %s", errorCondition(element.Inner))
+			}
+			return t, element.Inner, nil
+		case xml.EndElement:
+			return xml.StartElement{}, nil, fmt.Errorf("XMPP server closed the stream")
+		}
+	}
+}
+
+// request sends an IQ set during negotiation and waits for its result
+func (s *xmppSession) request(id, payload string) error {
+	if _, err := fmt.Fprintf(s.conn, "<iq type='set' id='%s'>%s</iq>", id, payload); err != nil {
+		return err
+	}
+	for {
+		start, inner, err := s.nextElement()
+		if err != nil {
+			return err
+		}
+		if start.Name.Local != "iq" || attrValue(start, "id") != id {
+			continue
+		}
+		if attrValue(start, "type") != "result" {
+			var iq struct {
+				Error struct {
+					Inner []byte `xml:",innerxml"`
+				} `xml:"error"`
+			}
+			xml.Unmarshal([]byte("<iq>"+string(inner)+"</iq>"), &iq)
+			return fmt.Errorf("server returned %s", errorCondition(iq.Error.Inner))
+		}
+		return nil
+	}
+}
+
+// attrValue returns the value of an element's attribute
+func attrValue(start xml.StartElement, name string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// errorCondition returns the defined condition of a stream, SASL or stanza error: the name of
+// its first child element other than the human-readable text
+func errorCondition(inner []byte) string {
+	dec := xml.NewDecoder(strings.NewReader(string(inner)))
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return "unknown"
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local != "text" {
+			return start.Name.Local
+		}
+	}
+}
+
+// xmlEscape escapes s for use in XML text or a quoted attribute
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// run starts reading server stanzas, answering pings and noticing when the stream ends,
+// and sends whitespace keepalives so idle connections aren't dropped by the server or NATs
+func (s *xmppSession) run(keepAlive, timeout time.Duration) {
+	go func() {
+		defer s.close()
+		for {
+			token, err := s.dec.Token()
+			if err != nil {
+				return
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				if t.Name.Local == "iq" {
+					var iq xmppIQ
+					if err := s.dec.DecodeElement(&iq, &t); err != nil {
+						return
+					}
+					if iq.Type == "get" && strings.Contains(string(iq.Inner), xmppNSPing) {
+						s.writeStanza(xmppIQ{ID: iq.ID, Type: "result", To: iq.From}, timeout)
+					}
+				} else if err := s.dec.Skip(); err != nil {
+					return
+				}
+			case xml.EndElement:
+				// The server closed the stream
+				return
+			}
+		}
+	}()
+
+	if keepAlive <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				if err := s.write([]byte(" "), timeout); err != nil {
+					s.close()
+					return
+				}
+			}
+		}
+	}()
+}
+
+// writeStanza marshals and writes a stanza
+func (s *xmppSession) writeStanza(stanza interface{}, timeout time.Duration) error {
+	data, err := xml.Marshal(stanza)
+	if err != nil {
+		return fmt.Errorf("failed to encode XMPP stanza: %w", err)
+	}
+	if err := s.write(data, timeout); err != nil {
+		return fmt.Errorf("failed to send XMPP message: %w", err)
+	}
+	return nil
+}
+
+// write writes raw data to the stream
+func (s *xmppSession) write(data []byte, timeout time.Duration) error {
+	if s.isClosed() {
+		return errors.New("XMPP connection closed")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(timeout))
+	_, err := s.conn.Write(data)
+	return err
+}
+
+// isClosed reports whether the session has ended
+func (s *xmppSession) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// end closes the stream politely before closing the connection
+func (s *xmppSession) end(timeout time.Duration) {
+	s.write([]byte("</stream:stream>"), timeout)
+	s.close()
+}
+
+// close closes the connection and stops the session's goroutines
+func (s *xmppSession) close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.conn.Close()
+	})
+}
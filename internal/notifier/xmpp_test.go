@@ -0,0 +1,286 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// fakeXMPPServer accepts client connections and negotiates STARTTLS, SASL PLAIN and binding
+type fakeXMPPServer struct {
+	t        *testing.T
+	listener net.Listener
+	tls      *tls.Config
+	password string
+	messages chan xmppMessage
+	pongs    chan string
+	conns    chan net.Conn
+}
+
+// newFakeXMPPServer starts a server whose certificate is trusted through the returned CA file
+func newFakeXMPPServer(t *testing.T, password string) (*fakeXMPPServer, string) {
+	t.Helper()
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(certServer.Close)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	if err := os.WriteFile(caPath, pemData, 0600); err != nil {
+		t.Fatalf("Failed to write CA: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeXMPPServer{
+		t:        t,
+		listener: listener,
+		tls:      certServer.TLS.Clone(),
+		password: password,
+		messages: make(chan xmppMessage, 10),
+		pongs:    make(chan string, 10),
+		conns:    make(chan net.Conn, 10),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.conns <- conn
+			go server.serve(conn)
+		}
+	}()
+	return server, caPath
+}
+
+// openStream reads the client's stream header and answers with the given features
+func (s *fakeXMPPServer) openStream(conn net.Conn, features string) *xml.Decoder {
+	dec := xml.NewDecoder(conn)
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "stream" {
+			break
+		}
+	}
+	fmt.Fprintf(conn, "<?xml version='1.0'?><stream:stream xmlns='jabber:client' xmlns:stream='%s' id='s1' from='example.com' version='1.0'><stream:features>%s</stream:features>",
+		xmppNSStream, features)
+	return dec
+}
+
+// next reads the next element from the client
+func next(dec *xml.Decoder) (xml.StartElement, string, bool) {
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, "", false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			var element struct {
+				Inner string `xml:",innerxml"`
+			}
+			if err := dec.DecodeElement(&element, &start); err != nil {
+				return xml.StartElement{}, "", false
+			}
+			return start, element.Inner, true
+		}
+	}
+}
+
+func (s *fakeXMPPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	dec := s.openStream(conn, "<starttls xmlns='"+xmppNSTLS+"'><required/></starttls>")
+	if start, _, ok := next(dec); !ok || start.Name.Local != "starttls" {
+		return
+	}
+	fmt.Fprintf(conn, "<proceed xmlns='%s'/>", xmppNSTLS)
+	tlsConn := tls.Server(conn, s.tls)
+	if err := tlsConn.Handshake(); err != nil {
+		return
+	}
+
+	dec = s.openStream(tlsConn, "<mechanisms xmlns='"+xmppNSSASL+"'><mechanism>SCRAM-SHA-1</mechanism><mechanism>PLAIN</mechanism></mechanisms>")
+	_, credentials, ok := next(dec)
+	if !ok {
+		return
+	}
+	if decoded, _ := base64.StdEncoding.DecodeString(credentials); string(decoded) != "\x00alerts\x00"+s.password {
+		fmt.Fprintf(tlsConn, "<failure xmlns='%s'><not-authorized/></failure></stream:stream>", xmppNSSASL)
+		return
+	}
+	fmt.Fprintf(tlsConn, "<success xmlns='%s'/>", xmppNSSASL)
+
+	dec = s.openStream(tlsConn, "<bind xmlns='"+xmppNSBind+"'/>")
+	start, inner, ok := next(dec)
+	if !ok || !strings.Contains(inner, "<resource>notifier</resource>") {
+		s.t.Errorf("Unexpected bind request: %s", inner)
+		return
+	}
+	fmt.Fprintf(tlsConn, "<iq type='result' id='%s'><bind xmlns='%s'><jid>alerts@example.com/notifier</jid></bind></iq>", attrValue(start, "id"), xmppNSBind)
+
+	for {
+		start, inner, ok := next(dec)
+		if !ok {
+			return
+		}
+		switch start.Name.Local {
+		case "message":
+			var msg xmppMessage
+			xml.Unmarshal([]byte("<message xmlns='jabber:client'>"+inner+"</message>"), &msg)
+			msg.To = attrValue(start, "to")
+			s.messages <- msg
+
+			// Check the client answers pings, then drop the connection
+			if strings.Contains(msg.Body, "disconnect") {
+				fmt.Fprintf(tlsConn, "<iq type='get' id='ping1' from='example.com'><ping xmlns='%s'/></iq>", xmppNSPing)
+				if pong, _, ok := next(dec); ok {
+					s.pongs <- attrValue(pong, "type") + ":" + attrValue(pong, "id")
+				}
+				fmt.Fprint(tlsConn, "</stream:stream>")
+				return
+			}
+		}
+	}
+}
+
+// TestXMPPSend tests delivery over a negotiated stream, ping replies and reconnecting
+func TestXMPPSend(t *testing.T) {
+	server, caPath := newFakeXMPPServer(t, "s3cret")
+	x, err := NewXMPPNotifier(&XMPPConfig{
+		JID:        "alerts@example.com",
+		Password:   "s3cret",
+		Server:     server.listener.Addr().String(),
+		CACertPath: caPath,
+		Timeout:    5,
+	})
+	if err != nil {
+		t.Fatalf("NewXMPPNotifier() error = %v", err)
+	}
+	defer x.Close()
+
+	send := func(body string, recipients ...string) {
+		t.Helper()
+		result, err := x.Send(context.Background(), &domain.Notification{
+			ID: "xmpp-1", Type: domain.TypeXMPP, Subject: "Deploy", Body: body, Recipients: recipients,
+		})
+		if err != nil || !result.Success {
+			t.Fatalf("Send() error = %v, result = %+v", err, result)
+		}
+	}
+	receive := func() xmppMessage {
+		t.Helper()
+		select {
+		case msg := <-server.messages:
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("Message not received")
+			return xmppMessage{}
+		}
+	}
+
+	send("Deploy <finished> & verified", "ops@example.com", "oncall@example.com/phone")
+	if msg := receive(); msg.To != "ops@example.com" || msg.Subject != "Deploy" || msg.Body != "Deploy <finished> & verified" {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+	if msg := receive(); msg.To != "oncall@example.com/phone" {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+
+	// The connection is reused, then the server pings and drops it
+	send("disconnect", "ops@example.com")
+	receive()
+	select {
+	case pong := <-server.pongs:
+		if pong != "result:ping1" {
+			t.Errorf("Unexpected ping reply: %s", pong)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Ping not answered")
+	}
+	if len(server.conns) != 1 {
+		t.Fatalf("Expected one connection, got %d", len(server.conns))
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		x.mu.Lock()
+		dropped := x.session == nil || x.session.isClosed()
+		x.mu.Unlock()
+		if dropped {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Dropped connection not noticed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	send("after reconnect", "ops@example.com")
+	if msg := receive(); msg.Body != "after reconnect" {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+	if len(server.conns) != 2 {
+		t.Errorf("Expected a second connection, got %d", len(server.conns))
+	}
+}
+
+// TestXMPPAuthFailure tests that rejected credentials are reported
+func TestXMPPAuthFailure(t *testing.T) {
+	server, caPath := newFakeXMPPServer(t, "s3cret")
+	x, err := NewXMPPNotifier(&XMPPConfig{
+		JID:        "alerts@example.com",
+		Password:   "wrong",
+		Server:     server.listener.Addr().String(),
+		CACertPath: caPath,
+	})
+	if err != nil {
+		t.Fatalf("NewXMPPNotifier() error = %v", err)
+	}
+	defer x.Close()
+
+	_, err = x.Send(context.Background(), &domain.Notification{
+		ID: "xmpp-2", Type: domain.TypeXMPP, Body: "hello", Recipients: []string{"ops@example.com"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "not-authorized") {
+		t.Errorf("Expected authentication failure, got %v", err)
+	}
+}
+
+// TestXMPPValidate tests JID validation of config and recipients
+func TestXMPPValidate(t *testing.T) {
+	if _, err := NewXMPPNotifier(&XMPPConfig{JID: "example.com", Password: "x"}); err == nil {
+		t.Error("Expected error for a JID without a username")
+	}
+
+	x, err := NewXMPPNotifier(&XMPPConfig{JID: "alerts@example.com", Password: "x"})
+	if err != nil {
+		t.Fatalf("NewXMPPNotifier() error = %v", err)
+	}
+	if x.server != "example.com:5222" {
+		t.Errorf("Unexpected default server: %s", x.server)
+	}
+	err = x.Validate(&domain.Notification{Type: domain.TypeXMPP, Body: "hi", Recipients: []string{"not a jid"}})
+	if err == nil {
+		t.Error("Expected error for an invalid recipient")
+	}
+}
@@ -0,0 +1,260 @@
+// Package objectstore reads and writes objects in S3 or an S3-compatible store, such as
+// Google Cloud Storage's interoperability API or MinIO, with Signature Version 4 requests.
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// Config contains S3 (or S3-compatible) bucket configuration
+type Config struct {
+	Bucket          string `mapstructure:"bucket"`            // Bucket to use (empty = disabled)
+	Region          string `mapstructure:"region"`            // Bucket region (e.g., us-east-1; "auto" for GCS)
+	Endpoint        string `mapstructure:"endpoint"`          // Custom endpoint for S3-compatible stores (default: https://s3.<region>.amazonaws.com)
+	Prefix          string `mapstructure:"prefix"`            // Key prefix (e.g., notifier/prod/)
+	AccessKeyID     string `mapstructure:"access_key_id"`     // Access key ID
+	SecretAccessKey string `mapstructure:"secret_access_key"` // Secret access key
+	SessionToken    string `mapstructure:"session_token"`     // Session token for temporary credentials (optional)
+}
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Client reads and writes objects in one bucket using path-style URLs
+type Client struct {
+	config     Config
+	endpoint   string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// New creates a client for the configured bucket
+func New(config Config) (*Client, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("s3 region is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 access_key_id and secret_access_key are required")
+	}
+
+	endpoint := strings.TrimSuffix(config.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+
+	return &Client{
+		config:     config,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		now:        time.Now,
+	}, nil
+}
+
+// Bucket returns the bucket the client uses
+func (c *Client) Bucket() string {
+	return c.config.Bucket
+}
+
+// Put uploads an object under the configured prefix, returning its full key
+func (c *Client) Put(ctx context.Context, name string, data []byte, contentType string) (string, error) {
+	key := c.config.Prefix + name
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.do(req, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError("upload", resp)
+	}
+	return key, nil
+}
+
+// Get downloads an object by its full key, wrapping ErrNotFound when it doesn't exist
+func (c *Client) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.do(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("download", resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", key, err)
+	}
+	return data, nil
+}
+
+// List returns the objects under the configured prefix whose names start with namePrefix,
+// in key order
+func (c *Client) List(ctx context.Context, namePrefix string) ([]Object, error) {
+	var (
+		objects           []Object
+		continuationToken string
+	)
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {c.config.Prefix + namePrefix}}
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+"/"+c.config.Bucket+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := c.do(req, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := statusError("list", resp)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object listing: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// objectURL returns the path-style URL of an object
+func (c *Client) objectURL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", c.endpoint, c.config.Bucket, key)
+}
+
+// do signs and sends a request with the given payload
+func (c *Client) do(req *http.Request, payload []byte) (*http.Response, error) {
+	if c.config.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.config.SessionToken)
+	}
+	sum := sha256.Sum256(payload)
+	signV4(req, hex.EncodeToString(sum[:]), c.config.AccessKeyID, c.config.SecretAccessKey, c.config.Region, "s3", c.now())
+	return c.httpClient.Do(req)
+}
+
+// statusError describes a failed response, including the start of its body
+func statusError(operation string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("S3 %s failed with status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// signV4 adds AWS Signature Version 4 headers to a request. Every header already set on the
+// request is signed along with host, x-amz-date and x-amz-content-sha256.
+func signV4(req *http.Request, payloadHash, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalPath := req.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		// Signature Version 4 encodes spaces as %20, where url.Values uses +
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,96 @@
+// Package preview issues and verifies the signed tokens in links that show or act on a
+// notification without an API key. A token grants access to one notification for one purpose
+// (a staff preview, a recipient's web copy or an acknowledge button) until it expires.
+package preview
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Token purposes, so a link handed to a recipient can't open the staff preview
+const (
+	PurposePreview     = "preview"
+	PurposeWebCopy     = "view"
+	PurposeAcknowledge = "ack"
+)
+
+// Errors from verifying a token
+var (
+	ErrInvalid = errors.New("invalid link")
+	ErrExpired = errors.New("link has expired")
+)
+
+// Signer issues and verifies tokens
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigner creates a signer. Changing the secret invalidates every token it issued.
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret, now: time.Now}
+}
+
+// Sign returns a token for a notification. A zero expiresAt issues a token that never expires.
+// The token is base64url("<id>.<expiry unix seconds>") "." base64url(HMAC-SHA256 of the
+// purpose and the same).
+func (s *Signer) Sign(purpose, id string, expiresAt time.Time) string {
+	var expiry int64
+	if !expiresAt.IsZero() {
+		expiry = expiresAt.Unix()
+	}
+	payload := id + "." + strconv.FormatInt(expiry, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(s.mac(purpose, payload))
+}
+
+// Verify checks a token's signature, purpose and expiry and returns the notification ID it was
+// issued for and when it expires (zero if never)
+func (s *Signer) Verify(purpose, token string) (string, time.Time, error) {
+	encodedPayload, encodedMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", time.Time{}, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", time.Time{}, ErrInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMAC)
+	if err != nil || !hmac.Equal(mac, s.mac(purpose, string(payload))) {
+		return "", time.Time{}, ErrInvalid
+	}
+
+	// IDs may contain dots, so the expiry is after the last one
+	i := strings.LastIndexByte(string(payload), '.')
+	if i < 0 {
+		return "", time.Time{}, ErrInvalid
+	}
+	expiry, err := strconv.ParseInt(string(payload[i+1:]), 10, 64)
+	if err != nil {
+		return "", time.Time{}, ErrInvalid
+	}
+
+	var expiresAt time.Time
+	if expiry != 0 {
+		expiresAt = time.Unix(expiry, 0)
+		if !s.now().Before(expiresAt) {
+			return "", time.Time{}, ErrExpired
+		}
+	}
+	return string(payload[:i]), expiresAt, nil
+}
+
+// mac returns the HMAC-SHA256 of a token's purpose and payload
+func (s *Signer) mac(purpose, payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(purpose))
+	h.Write([]byte{0})
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
@@ -0,0 +1,42 @@
+package preview
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSignerExpiry tests that tokens stop verifying once they expire
+func TestSignerExpiry(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	token := s.Sign(PurposePreview, "n.with.dots", now.Add(time.Minute))
+	if id, _, err := s.Verify(PurposePreview, token); err != nil || id != "n.with.dots" {
+		t.Fatalf("Verify() = %q, %v", id, err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, _, err := s.Verify(PurposePreview, token); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected an expired token, got %v", err)
+	}
+
+	other := NewSigner([]byte("rotated"))
+	if _, _, err := other.Verify(PurposePreview, token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected a token signed with another secret to be invalid, got %v", err)
+	}
+}
+
+// TestSignerPurpose tests that a token only opens links of the purpose it was issued for
+func TestSignerPurpose(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+
+	token := s.Sign(PurposeWebCopy, "n1", time.Time{})
+	if id, expiresAt, err := s.Verify(PurposeWebCopy, token); err != nil || id != "n1" || !expiresAt.IsZero() {
+		t.Fatalf("Verify() = %q, %v, %v", id, expiresAt, err)
+	}
+	if _, _, err := s.Verify(PurposePreview, token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected a web copy token to be rejected for a preview, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package preview
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Markers around an injected web copy link, so it's injected once and can be stripped from
+// the copy itself
+const (
+	webCopyMarkerStart = "<!--notifier:web-copy-->"
+	webCopyMarkerEnd   = "<!--/notifier:web-copy-->"
+)
+
+// bodyTag finds the opening body tag a link is injected after
+var bodyTag = regexp.MustCompile(`(?i)<body[^>]*>`)
+
+// HasWebCopyLink reports whether a web copy link has already been injected into an HTML body
+func HasWebCopyLink(body string) bool {
+	return strings.Contains(body, webCopyMarkerStart)
+}
+
+// InjectWebCopyLink adds a link to the web copy at the top of an HTML body
+func InjectWebCopyLink(body, url, text string) string {
+	link := webCopyMarkerStart +
+		`<div style="text-align:center;font-size:12px;padding:8px 0;">` +
+		`<a href="` + html.EscapeString(url) + `">` + html.EscapeString(text) + `</a></div>` +
+		webCopyMarkerEnd
+
+	if loc := bodyTag.FindStringIndex(body); loc != nil {
+		return body[:loc[1]] + link + body[loc[1]:]
+	}
+	return link + body
+}
+
+// StripWebCopyLink removes an injected web copy link from an HTML body
+func StripWebCopyLink(body string) string {
+	start := strings.Index(body, webCopyMarkerStart)
+	if start < 0 {
+		return body
+	}
+	end := strings.Index(body[start:], webCopyMarkerEnd)
+	if end < 0 {
+		return body
+	}
+	return body[:start] + body[start+end+len(webCopyMarkerEnd):]
+}
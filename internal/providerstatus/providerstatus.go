@@ -0,0 +1,265 @@
+// Package providerstatus polls notification provider status feeds so the service
+// can back off and stay quiet while a provider has a declared outage.
+package providerstatus
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// Supported status feed formats
+const (
+	FormatSlack      = "slack"      // Slack status API (https://slack-status.com/api/v2.0.0/current)
+	FormatStatuspage = "statuspage" // Atlassian Statuspage status.json
+	FormatAWSRSS     = "aws_rss"    // AWS Health Dashboard RSS feed (e.g. SES per region)
+)
+
+// Config contains provider status polling configuration
+type Config struct {
+	Enabled      bool         `mapstructure:"enabled"`       // Enable provider status polling
+	PollInterval string       `mapstructure:"poll_interval"` // How often to poll feeds (e.g., "2m")
+	RetryDelay   string       `mapstructure:"retry_delay"`   // Backoff before retrying a failed send during an outage (e.g., "5m")
+	Feeds        []FeedConfig `mapstructure:"feeds"`
+}
+
+// FeedConfig describes a single provider status feed and the accounts it affects
+type FeedConfig struct {
+	Name     string   `mapstructure:"name"`     // Name used in logs
+	Format   string   `mapstructure:"format"`   // Feed format (slack, statuspage, aws_rss)
+	URL      string   `mapstructure:"url"`      // Feed URL
+	Types    []string `mapstructure:"types"`    // Notifier types affected by an outage (e.g., slack, email)
+	Accounts []string `mapstructure:"accounts"` // Accounts affected (empty means all accounts of the types)
+}
+
+// IsValidFormat reports whether a feed format is supported
+func IsValidFormat(format string) bool {
+	return format == FormatSlack || format == FormatStatuspage || format == FormatAWSRSS
+}
+
+// Incident is an active provider outage
+type Incident struct {
+	Feed  string    `json:"feed"`
+	Title string    `json:"title"`
+	Since time.Time `json:"since"`
+}
+
+// Monitor polls provider status feeds and tracks active outages
+type Monitor struct {
+	feeds        []FeedConfig
+	pollInterval time.Duration
+	client       *http.Client
+	logger       *logging.Logger
+
+	mu        sync.RWMutex
+	incidents map[string]*Incident // keyed by feed name
+}
+
+// NewMonitor creates a provider status monitor
+func NewMonitor(cfg Config, logger *logging.Logger) (*Monitor, error) {
+	pollInterval, err := time.ParseDuration(cfg.PollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll interval duration: %w", err)
+	}
+
+	for _, feed := range cfg.Feeds {
+		if !IsValidFormat(feed.Format) {
+			return nil, fmt.Errorf("invalid format for feed %s: %s", feed.Name, feed.Format)
+		}
+	}
+
+	return &Monitor{
+		feeds:        cfg.Feeds,
+		pollInterval: pollInterval,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		logger:       logger,
+		incidents:    make(map[string]*Incident),
+	}, nil
+}
+
+// Run polls the feeds until the context is cancelled or stop is closed
+func (m *Monitor) Run(ctx context.Context, stop <-chan struct{}) {
+	m.Poll(ctx)
+
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Poll(ctx)
+		}
+	}
+}
+
+// Poll fetches every feed once and updates the active incidents.
+// A feed that can't be fetched keeps its previous state.
+func (m *Monitor) Poll(ctx context.Context) {
+	for _, feed := range m.feeds {
+		title, outage, err := m.fetch(ctx, feed)
+		if err != nil {
+			m.logger.Warnf("Failed to poll provider status - feed=%s, error=%v", feed.Name, err)
+			continue
+		}
+
+		m.mu.Lock()
+		current, active := m.incidents[feed.Name]
+		switch {
+		case outage && !active:
+			m.incidents[feed.Name] = &Incident{Feed: feed.Name, Title: title, Since: time.Now()}
+			m.logger.Warnf("Provider outage declared - feed=%s, incident=%s", feed.Name, title)
+		case outage && active:
+			current.Title = title
+		case !outage && active:
+			delete(m.incidents, feed.Name)
+			m.logger.Infof("Provider outage resolved - feed=%s, duration=%s", feed.Name, time.Since(current.Since).Round(time.Second))
+		}
+		m.mu.Unlock()
+	}
+}
+
+// ActiveOutage returns the active incident affecting a notifier account, if any
+func (m *Monitor) ActiveOutage(notifType domain.NotificationType, account string) (*Incident, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, feed := range m.feeds {
+		incident, ok := m.incidents[feed.Name]
+		if !ok || !feed.affects(notifType, account) {
+			continue
+		}
+		copied := *incident
+		return &copied, true
+	}
+
+	return nil, false
+}
+
+// affects reports whether an outage on this feed affects the notifier account
+func (f FeedConfig) affects(notifType domain.NotificationType, account string) bool {
+	if !containsString(f.Types, string(notifType)) {
+		return false
+	}
+	return len(f.Accounts) == 0 || containsString(f.Accounts, account)
+}
+
+// fetch retrieves a feed and reports whether it declares an outage
+func (m *Monitor) fetch(ctx context.Context, feed FeedConfig) (string, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feed.URL, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	switch feed.Format {
+	case FormatSlack:
+		return parseSlackStatus(body)
+	case FormatStatuspage:
+		return parseStatuspage(body)
+	case FormatAWSRSS:
+		return parseAWSRSS(body)
+	default:
+		return "", false, fmt.Errorf("unsupported feed format: %s", feed.Format)
+	}
+}
+
+// parseSlackStatus parses the Slack status API; active incidents and outages count, notices don't
+func parseSlackStatus(body []byte) (string, bool, error) {
+	var status struct {
+		Status          string `json:"status"`
+		ActiveIncidents []struct {
+			Title string `json:"title"`
+			Type  string `json:"type"`
+		} `json:"active_incidents"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", false, fmt.Errorf("failed to parse slack status: %w", err)
+	}
+
+	for _, incident := range status.ActiveIncidents {
+		if incident.Type == "outage" || incident.Type == "incident" {
+			return incident.Title, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// parseStatuspage parses a Statuspage status.json; major and critical indicators count as outages
+func parseStatuspage(body []byte) (string, bool, error) {
+	var status struct {
+		Status struct {
+			Indicator   string `json:"indicator"`
+			Description string `json:"description"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", false, fmt.Errorf("failed to parse statuspage status: %w", err)
+	}
+
+	switch status.Status.Indicator {
+	case "major", "critical":
+		return status.Status.Description, true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// parseAWSRSS parses an AWS Health Dashboard RSS feed. The newest item describes the
+// current state; the service is healthy once it reports normal operation or a resolution.
+func parseAWSRSS(body []byte) (string, bool, error) {
+	var feed struct {
+		Items []struct {
+			Title string `xml:"title"`
+		} `xml:"channel>item"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return "", false, fmt.Errorf("failed to parse aws rss feed: %w", err)
+	}
+
+	if len(feed.Items) == 0 {
+		return "", false, nil
+	}
+
+	title := strings.TrimSpace(feed.Items[0].Title)
+	if strings.HasPrefix(title, "Service is operating normally") || strings.Contains(title, "[RESOLVED]") {
+		return "", false, nil
+	}
+	return title, true, nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package providerstatus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+)
+
+// TestParseFeeds tests outage detection for each feed format
+func TestParseFeeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		parse  func([]byte) (string, bool, error)
+		body   string
+		outage bool
+		title  string
+	}{
+		{
+			name:  "slack ok",
+			parse: parseSlackStatus,
+			body:  `{"status":"ok","active_incidents":[]}`,
+		},
+		{
+			name:  "slack notice",
+			parse: parseSlackStatus,
+			body:  `{"status":"active","active_incidents":[{"title":"Scheduled maintenance","type":"notice"}]}`,
+		},
+		{
+			name:   "slack incident",
+			parse:  parseSlackStatus,
+			body:   `{"status":"active","active_incidents":[{"title":"Messages failing to send","type":"incident"}]}`,
+			outage: true,
+			title:  "Messages failing to send",
+		},
+		{
+			name:  "statuspage minor",
+			parse: parseStatuspage,
+			body:  `{"status":{"indicator":"minor","description":"Minor Service Outage"}}`,
+		},
+		{
+			name:   "statuspage major",
+			parse:  parseStatuspage,
+			body:   `{"status":{"indicator":"major","description":"Partial System Outage"}}`,
+			outage: true,
+			title:  "Partial System Outage",
+		},
+		{
+			name:   "aws rss outage",
+			parse:  parseAWSRSS,
+			body:   `<rss><channel><item><title>Increased Error Rates</title></item><item><title>Service is operating normally</title></item></channel></rss>`,
+			outage: true,
+			title:  "Increased Error Rates",
+		},
+		{
+			name:  "aws rss resolved",
+			parse: parseAWSRSS,
+			body:  `<rss><channel><item><title>[RESOLVED] Increased Error Rates</title></item></channel></rss>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, outage, err := tt.parse([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("parse error = %v", err)
+			}
+			if outage != tt.outage || title != tt.title {
+				t.Errorf("got (%q, %v), want (%q, %v)", title, outage, tt.title, tt.outage)
+			}
+		})
+	}
+}
+
+// TestMonitorOutageLifecycle tests outages are scoped to matching accounts and clear on resolution
+func TestMonitorOutageLifecycle(t *testing.T) {
+	body := `{"status":{"indicator":"critical","description":"Major Outage"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	monitor, err := NewMonitor(Config{
+		PollInterval: "1m",
+		Feeds: []FeedConfig{
+			{Name: "ses", Format: FormatStatuspage, URL: server.URL, Types: []string{"email"}, Accounts: []string{"ses"}},
+		},
+	}, logger)
+	if err != nil {
+		t.Fatalf("NewMonitor() error = %v", err)
+	}
+
+	monitor.Poll(context.Background())
+
+	if incident, ok := monitor.ActiveOutage(domain.TypeEmail, "ses"); !ok || incident.Title != "Major Outage" {
+		t.Errorf("Expected active outage for email/ses, got %v", incident)
+	}
+	if _, ok := monitor.ActiveOutage(domain.TypeEmail, "relay"); ok {
+		t.Error("Expected no outage for an unaffected account")
+	}
+	if _, ok := monitor.ActiveOutage(domain.TypeSlack, "ses"); ok {
+		t.Error("Expected no outage for an unaffected type")
+	}
+
+	body = `{"status":{"indicator":"none","description":"All Systems Operational"}}`
+	monitor.Poll(context.Background())
+
+	if _, ok := monitor.ActiveOutage(domain.TypeEmail, "ses"); ok {
+		t.Error("Expected outage to be resolved")
+	}
+}
@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltMessagesBucket holds one JSON-encoded queue message per message ID
+var boltMessagesBucket = []byte("messages")
+
+// boltStore keeps queue messages in an embedded bbolt database. Each change is written in a
+// single transaction that only touches the affected messages, and bbolt's copy-on-write
+// pages keep the file consistent if the process dies mid-write.
+type boltStore struct {
+	db *bolt.DB
+}
+
+// openBoltStore opens (or creates) the bbolt database at path. The file is locked while open,
+// so a second server pointed at the same path fails instead of corrupting it.
+func openBoltStore(path string) (*boltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open queue database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltMessagesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize queue database: %w", err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) load() ([]*domain.QueueMessage, error) {
+	var loaded []*domain.QueueMessage
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltMessagesBucket).ForEach(func(k, v []byte) error {
+			var msg domain.QueueMessage
+			if err := json.Unmarshal(v, &msg); err != nil {
+				return fmt.Errorf("failed to unmarshal queue message %s: %w", k, err)
+			}
+			loaded = append(loaded, &msg)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue state: %w", err)
+	}
+	return loaded, nil
+}
+
+func (s *boltStore) save(messages map[string]*domain.QueueMessage, updated []*domain.QueueMessage, removed []string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltMessagesBucket)
+		for _, msg := range updated {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return fmt.Errorf("failed to marshal queue message %s: %w", msg.ID, err)
+			}
+			if err := bucket.Put([]byte(msg.ID), data); err != nil {
+				return err
+			}
+		}
+		for _, id := range removed {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write queue state: %w", err)
+	}
+	return nil
+}
+
+func (s *boltStore) close() error {
+	return s.db.Close()
+}
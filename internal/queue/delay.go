@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// delayHeap orders delayed messages by delivery time, earliest first
+type delayHeap []*domain.QueueMessage
+
+func (h delayHeap) Len() int           { return len(h) }
+func (h delayHeap) Less(i, j int) bool { return h[i].DeliverAt.Before(h[j].DeliverAt) }
+func (h delayHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *delayHeap) Push(x any)        { *h = append(*h, x.(*domain.QueueMessage)) }
+
+func (h *delayHeap) Pop() any {
+	old := *h
+	msg := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return msg
+}
+
+// delay holds a message until its DeliverAt, waking the release loop if it's now the
+// earliest (must be called with lock held)
+func (lq *LocalQueue) delay(msg *domain.QueueMessage) {
+	heap.Push(&lq.delayed, msg)
+	if lq.delayed[0] == msg {
+		select {
+		case lq.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// releaseDelayed moves delayed messages to the queue as they come due. A single timer
+// is armed for the earliest delivery time, so idle delays cost nothing.
+func (lq *LocalQueue) releaseDelayed() {
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		next, ok := lq.releaseDue()
+		if ok {
+			timer.Reset(time.Until(next))
+		}
+
+		select {
+		case <-timer.C:
+		case <-lq.wake:
+			timer.Stop()
+		case <-lq.closeChan:
+			return
+		}
+	}
+}
+
+// releaseDue puts every message whose delivery time has passed and returns the next
+// delivery time, if any
+func (lq *LocalQueue) releaseDue() (time.Time, bool) {
+	for {
+		lq.mu.Lock()
+		next, due := lq.nextDelayed()
+		lq.mu.Unlock()
+		if !due {
+			return next, !next.IsZero()
+		}
+
+		// Blocks while the buffer is full, like Enqueue; fails only once the queue is closed
+		if _, err := lq.reserve(context.Background(), 1); err != nil {
+			return time.Time{}, false
+		}
+
+		lq.mu.Lock()
+		if _, due := lq.nextDelayed(); due && !lq.closed {
+			lq.put(heap.Pop(&lq.delayed).(*domain.QueueMessage))
+		} else {
+			// Purged or closed while we were waiting for a slot
+			lq.release(1)
+		}
+		lq.mu.Unlock()
+	}
+}
+
+// nextDelayed returns the earliest delivery time and whether it has passed, or a zero
+// time when nothing is delayed (must be called with lock held)
+func (lq *LocalQueue) nextDelayed() (time.Time, bool) {
+	if lq.delayed.Len() == 0 {
+		return time.Time{}, false
+	}
+	next := lq.delayed[0].DeliverAt
+	return next, !next.After(time.Now())
+}
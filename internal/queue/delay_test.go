@@ -0,0 +1,120 @@
+package queue
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestEnqueueDelayed tests that a delayed message is held until its delivery time and
+// doesn't block messages behind it
+func TestEnqueueDelayed(t *testing.T) {
+	q, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deliverAt := time.Now().Add(200 * time.Millisecond)
+	if err := q.EnqueueDelayed(ctx, &domain.Notification{ID: "later"}, deliverAt); err != nil {
+		t.Fatalf("EnqueueDelayed() error = %v", err)
+	}
+	if err := q.EnqueueDelayed(ctx, &domain.Notification{ID: "now"}, time.Time{}); err != nil {
+		t.Fatalf("EnqueueDelayed() error = %v", err)
+	}
+
+	if m := q.QueueMetrics(); m.Depth != 1 || m.Delayed != 1 || m.InFlight != 0 {
+		t.Fatalf("Unexpected metrics with a delayed message: %+v", m)
+	}
+
+	for _, want := range []string{"now", "later"} {
+		msg, err := q.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("Dequeue() error = %v", err)
+		}
+		if msg.Notification.ID != want {
+			t.Fatalf("Dequeued %s, want %s", msg.Notification.ID, want)
+		}
+	}
+	if time.Now().Before(deliverAt) {
+		t.Error("Delayed message was dequeued before its delivery time")
+	}
+}
+
+// TestNackDelayed tests that a message nacked with a delay is redelivered once the delay passes
+func TestNackDelayed(t *testing.T) {
+	q, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10, PriorityScheduling: true})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := q.Enqueue(ctx, &domain.Notification{ID: "retry"}); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	msg, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+
+	retryAt := time.Now().Add(200 * time.Millisecond)
+	if err := q.NackDelayed(ctx, msg.ID, retryAt); err != nil {
+		t.Fatalf("NackDelayed() error = %v", err)
+	}
+	if m := q.QueueMetrics(); m.Delayed != 1 || m.Requeued != 1 || msg.Notification.Status != domain.StatusRetrying {
+		t.Fatalf("Unexpected state after a delayed nack: %+v, status %s", m, msg.Notification.Status)
+	}
+
+	msg, err = q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+	if msg.Attempt != 2 || time.Now().Before(retryAt) {
+		t.Errorf("Expected the second attempt after the delay, got attempt %d", msg.Attempt)
+	}
+	if err := q.NackDelayed(ctx, "missing", retryAt); err == nil {
+		t.Error("Expected error for an unknown message")
+	}
+}
+
+// TestDelayedMessageSurvivesRestart tests that a persisted delayed message is still held after a reload
+func TestDelayedMessageSurvivesRestart(t *testing.T) {
+	config := &domain.LocalQueueConfig{
+		BufferSize:     10,
+		PersistToDisk:  true,
+		PersistPath:    filepath.Join(t.TempDir(), "queue.db"),
+		PersistBackend: PersistBBolt,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	q, err := NewLocalQueue(config)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	if err := q.EnqueueDelayed(ctx, &domain.Notification{ID: "scheduled"}, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("EnqueueDelayed() error = %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	q, err = NewLocalQueue(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen queue: %v", err)
+	}
+	defer q.Close()
+
+	if m := q.QueueMetrics(); m.Depth != 0 || m.Delayed != 1 {
+		t.Errorf("Expected the message to still be delayed after restart, got %+v", m)
+	}
+}
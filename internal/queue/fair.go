@@ -0,0 +1,144 @@
+package queue
+
+import (
+	"sort"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// fairScheduler buffers messages per tenant and hands them out using smooth
+// weighted round-robin, so a large batch from one tenant can't starve the others.
+// Within a tenant, higher priority messages are served first.
+type fairScheduler struct {
+	rr    *WeightedRoundRobin
+	lanes map[string][]*domain.QueueMessage
+	size  int
+}
+
+// newFairScheduler creates a fair scheduler with optional per-tenant weights
+func newFairScheduler(weights map[string]int) *fairScheduler {
+	return &fairScheduler{
+		rr:    NewWeightedRoundRobin(weights),
+		lanes: make(map[string][]*domain.QueueMessage),
+	}
+}
+
+// WeightedRoundRobin chooses between keys using smooth weighted round-robin, so each key
+// gets a share of turns proportional to its weight without long runs of the same key.
+// It isn't safe for concurrent use.
+type WeightedRoundRobin struct {
+	weights map[string]int
+	current map[string]int
+}
+
+// NewWeightedRoundRobin creates a round-robin with optional per-key weights (default 1)
+func NewWeightedRoundRobin(weights map[string]int) *WeightedRoundRobin {
+	return &WeightedRoundRobin{
+		weights: weights,
+		current: make(map[string]int),
+	}
+}
+
+// Next picks one of keys, which must be non-empty and hold only keys with work pending
+func (w *WeightedRoundRobin) Next(keys []string) string {
+	sort.Strings(keys)
+
+	total := 0
+	selected := ""
+	for _, key := range keys {
+		weight := w.weight(key)
+		total += weight
+		w.current[key] += weight
+		if selected == "" || w.current[key] > w.current[selected] {
+			selected = key
+		}
+	}
+	w.current[selected] -= total
+	return selected
+}
+
+// Forget drops the credit of a key that has gone idle, so it doesn't bank turns while it
+// has nothing pending
+func (w *WeightedRoundRobin) Forget(key string) {
+	delete(w.current, key)
+}
+
+// Reset drops the credit of every key
+func (w *WeightedRoundRobin) Reset() {
+	w.current = make(map[string]int)
+}
+
+// weight returns the configured weight of a key (default 1)
+func (w *WeightedRoundRobin) weight(key string) int {
+	if weight, ok := w.weights[key]; ok && weight > 0 {
+		return weight
+	}
+	return 1
+}
+
+// tenantKey returns the fairness key of a notification. The tenant of the client that sent
+// it takes precedence, then a "tenant" metadata value; otherwise the notifier type and
+// account identify the sender.
+func tenantKey(notification *domain.Notification) string {
+	if notification.Tenant != "" {
+		return notification.Tenant
+	}
+	if tenant, ok := notification.Metadata["tenant"].(string); ok && tenant != "" {
+		return tenant
+	}
+	return string(notification.Type) + "/" + notification.Account
+}
+
+// push adds a message behind any queued messages of the same or higher priority
+func (f *fairScheduler) push(msg *domain.QueueMessage) {
+	key := tenantKey(msg.Notification)
+	lane := f.lanes[key]
+
+	idx := sort.Search(len(lane), func(i int) bool {
+		return lane[i].Notification.Priority < msg.Notification.Priority
+	})
+	lane = append(lane, nil)
+	copy(lane[idx+1:], lane[idx:])
+	lane[idx] = msg
+
+	f.lanes[key] = lane
+	f.size++
+}
+
+// pop removes the next message, or returns nil when empty
+func (f *fairScheduler) pop() *domain.QueueMessage {
+	if f.size == 0 {
+		return nil
+	}
+
+	tenants := make([]string, 0, len(f.lanes))
+	for tenant := range f.lanes {
+		tenants = append(tenants, tenant)
+	}
+	selected := f.rr.Next(tenants)
+
+	lane := f.lanes[selected]
+	msg := lane[0]
+	if len(lane) == 1 {
+		// Drop idle tenants so they don't bank credit while they have nothing queued
+		delete(f.lanes, selected)
+		f.rr.Forget(selected)
+	} else {
+		lane[0] = nil
+		f.lanes[selected] = lane[1:]
+	}
+	f.size--
+	return msg
+}
+
+// len returns the number of buffered messages
+func (f *fairScheduler) len() int {
+	return f.size
+}
+
+// reset drops all buffered messages
+func (f *fairScheduler) reset() {
+	f.lanes = make(map[string][]*domain.QueueMessage)
+	f.rr.Reset()
+	f.size = 0
+}
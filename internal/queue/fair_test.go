@@ -0,0 +1,115 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestFairSchedulingPreventsStarvation tests that a small sender isn't stuck behind a large batch
+func TestFairSchedulingPreventsStarvation(t *testing.T) {
+	q, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 200, FairScheduling: true})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	batch := make([]*domain.Notification, 100)
+	for i := range batch {
+		batch[i] = &domain.Notification{Type: domain.TypeEmail, Account: "marketing"}
+	}
+	if err := q.EnqueueBatch(ctx, batch); err != nil {
+		t.Fatalf("EnqueueBatch() error = %v", err)
+	}
+
+	alert := &domain.Notification{ID: "alert", Type: domain.TypeSlack, Account: "ops"}
+	if err := q.Enqueue(ctx, alert); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		msg, err := q.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("Dequeue() error = %v", err)
+		}
+		if msg.Notification.ID == "alert" {
+			return
+		}
+	}
+	t.Error("Expected alert within the first two dequeues")
+}
+
+// TestFairSchedulerOrder tests tenant weights and priority ordering within a tenant
+func TestFairSchedulerOrder(t *testing.T) {
+	message := func(id, tenant string, priority domain.Priority) *domain.QueueMessage {
+		return &domain.QueueMessage{
+			ID: id,
+			Notification: &domain.Notification{
+				Priority: priority,
+				Metadata: map[string]interface{}{"tenant": tenant},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		weights  map[string]int
+		messages []*domain.QueueMessage
+		expected []string
+	}{
+		{
+			name: "round-robin",
+			messages: []*domain.QueueMessage{
+				message("a1", "a", domain.PriorityNormal),
+				message("a2", "a", domain.PriorityNormal),
+				message("b1", "b", domain.PriorityNormal),
+				message("b2", "b", domain.PriorityNormal),
+			},
+			expected: []string{"a1", "b1", "a2", "b2"},
+		},
+		{
+			name:    "weighted",
+			weights: map[string]int{"a": 2},
+			messages: []*domain.QueueMessage{
+				message("a1", "a", domain.PriorityNormal),
+				message("a2", "a", domain.PriorityNormal),
+				message("a3", "a", domain.PriorityNormal),
+				message("b1", "b", domain.PriorityNormal),
+				message("b2", "b", domain.PriorityNormal),
+			},
+			expected: []string{"a1", "b1", "a2", "a3", "b2"},
+		},
+		{
+			name: "priority within tenant",
+			messages: []*domain.QueueMessage{
+				message("low", "a", domain.PriorityLow),
+				message("normal", "a", domain.PriorityNormal),
+				message("critical", "a", domain.PriorityCritical),
+				message("normal2", "a", domain.PriorityNormal),
+			},
+			expected: []string{"critical", "normal", "normal2", "low"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFairScheduler(tt.weights)
+			for _, msg := range tt.messages {
+				f.push(msg)
+			}
+			for i, want := range tt.expected {
+				if got := f.pop(); got == nil || got.ID != want {
+					t.Errorf("pop %d = %v, want %s", i, got, want)
+				}
+			}
+			if f.len() != 0 {
+				t.Errorf("len() = %d, want 0", f.len())
+			}
+		})
+	}
+}
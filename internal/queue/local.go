@@ -2,9 +2,8 @@ package queue
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,14 +13,38 @@ import (
 
 // LocalQueue is an in-memory queue implementation
 type LocalQueue struct {
-	queue         chan *domain.QueueMessage
-	messages      map[string]*domain.QueueMessage
-	mu            sync.RWMutex
-	config        *domain.LocalQueueConfig
-	persistToDisk bool
-	persistPath   string
-	closed        bool
-	closeChan     chan struct{}
+	queue     chan *domain.QueueMessage
+	messages  map[string]*domain.QueueMessage
+	mu        sync.RWMutex
+	config    *domain.LocalQueueConfig
+	store     queueStore // nil unless persist_to_disk is enabled
+	closed    bool
+	closeChan chan struct{}
+
+	// Counters reported by QueueMetrics
+	enqueued, acked, nacked, requeued uint64
+
+	// slots bounds the buffer. A slot is reserved before the lock is taken and freed before
+	// a consumer takes it, so a full buffer never blocks while holding the lock.
+	slots chan struct{}
+
+	// sched and ready replace the channel when fair or priority scheduling is enabled;
+	// ready counts messages available to dequeue
+	sched scheduler
+	ready chan struct{}
+
+	// delayed holds messages until their DeliverAt; wake interrupts the release loop's
+	// timer when an earlier message is added
+	delayed delayHeap
+	wake    chan struct{}
+}
+
+// scheduler buffers messages and decides which is dequeued next
+type scheduler interface {
+	push(msg *domain.QueueMessage)
+	pop() *domain.QueueMessage
+	len() int
+	reset()
 }
 
 // NewLocalQueue creates a new local queue instance
@@ -34,30 +57,71 @@ func NewLocalQueue(config *domain.LocalQueueConfig) (*LocalQueue, error) {
 	}
 
 	lq := &LocalQueue{
-		queue:         make(chan *domain.QueueMessage, config.BufferSize),
-		messages:      make(map[string]*domain.QueueMessage),
-		config:        config,
-		persistToDisk: config.PersistToDisk,
-		persistPath:   config.PersistPath,
-		closeChan:     make(chan struct{}),
+		queue:     make(chan *domain.QueueMessage, config.BufferSize),
+		messages:  make(map[string]*domain.QueueMessage),
+		config:    config,
+		slots:     make(chan struct{}, config.BufferSize),
+		closeChan: make(chan struct{}),
+		wake:      make(chan struct{}, 1),
+	}
+
+	if config.FairScheduling && config.PriorityScheduling {
+		return nil, fmt.Errorf("fair_scheduling and priority_scheduling can't both be enabled")
+	}
+
+	if config.FairScheduling {
+		for tenant, weight := range config.TenantWeights {
+			if weight < 1 {
+				return nil, fmt.Errorf("invalid weight for tenant %s: %d (must be at least 1)", tenant, weight)
+			}
+		}
+		lq.sched = newFairScheduler(config.TenantWeights)
+	}
+
+	if config.PriorityScheduling {
+		maxWait := defaultPriorityMaxWait
+		if config.PriorityMaxWait != "" {
+			var err error
+			if maxWait, err = time.ParseDuration(config.PriorityMaxWait); err != nil || maxWait <= 0 {
+				return nil, fmt.Errorf("invalid priority max wait: %q (must be a positive duration)", config.PriorityMaxWait)
+			}
+		}
+		lq.sched = newPriorityScheduler(maxWait)
+	}
+
+	if lq.sched != nil {
+		lq.ready = make(chan struct{}, config.BufferSize)
 	}
 
 	// Load persisted messages if enabled
-	if lq.persistToDisk && lq.persistPath != "" {
+	if config.PersistToDisk && config.PersistPath != "" {
+		store, err := newQueueStore(config.PersistBackend, config.PersistPath)
+		if err != nil {
+			return nil, err
+		}
+		lq.store = store
 		if err := lq.loadFromDisk(); err != nil {
+			store.close()
 			return nil, fmt.Errorf("failed to load persisted queue: %w", err)
 		}
 	}
 
+	go lq.releaseDelayed()
+
 	return lq, nil
 }
 
 // Enqueue adds a notification to the queue
 func (lq *LocalQueue) Enqueue(ctx context.Context, notification *domain.Notification) error {
+	if _, err := lq.reserve(ctx, 1); err != nil {
+		return err
+	}
+
 	lq.mu.Lock()
 	defer lq.mu.Unlock()
 
 	if lq.closed {
+		lq.release(1)
 		return fmt.Errorf("queue is closed")
 	}
 
@@ -68,31 +132,71 @@ func (lq *LocalQueue) Enqueue(ctx context.Context, notification *domain.Notifica
 		EnqueuedAt:   time.Now().Unix(),
 	}
 
-	select {
-	case lq.queue <- msg:
-		lq.messages[msg.ID] = msg
-		notification.Status = domain.StatusQueued
+	lq.put(msg)
+	lq.messages[msg.ID] = msg
+	lq.enqueued++
+	notification.Status = domain.StatusQueued
 
-		if lq.persistToDisk {
-			return lq.persistToDiskSync()
-		}
-		return nil
-	case <-ctx.Done():
-		return ctx.Err()
-	case <-lq.closeChan:
+	return lq.persist([]*domain.QueueMessage{msg}, nil)
+}
+
+// EnqueueDelayed adds a notification that is held until deliverAt before it can be dequeued
+func (lq *LocalQueue) EnqueueDelayed(ctx context.Context, notification *domain.Notification, deliverAt time.Time) error {
+	if !deliverAt.After(time.Now()) {
+		return lq.Enqueue(ctx, notification)
+	}
+
+	lq.mu.Lock()
+	defer lq.mu.Unlock()
+
+	if lq.closed {
 		return fmt.Errorf("queue is closed")
 	}
+
+	msg := &domain.QueueMessage{
+		ID:           uuid.New().String(),
+		Notification: notification,
+		Attempt:      0,
+		EnqueuedAt:   time.Now().Unix(),
+		DeliverAt:    deliverAt,
+	}
+
+	lq.delay(msg)
+	lq.messages[msg.ID] = msg
+	lq.enqueued++
+	notification.Status = domain.StatusScheduled
+
+	return lq.persist([]*domain.QueueMessage{msg}, nil)
 }
 
-// EnqueueBatch adds multiple notifications to the queue
+// EnqueueBatch adds multiple notifications to the queue. A batch larger than the free
+// buffer is added in chunks as consumers make room, so it may be partly enqueued when
+// the context ends.
 func (lq *LocalQueue) EnqueueBatch(ctx context.Context, notifications []*domain.Notification) error {
+	for len(notifications) > 0 {
+		n, err := lq.reserve(ctx, len(notifications))
+		if err != nil {
+			return err
+		}
+		if err := lq.enqueueChunk(notifications[:n]); err != nil {
+			return err
+		}
+		notifications = notifications[n:]
+	}
+	return nil
+}
+
+// enqueueChunk adds notifications whose buffer slots have already been reserved
+func (lq *LocalQueue) enqueueChunk(notifications []*domain.Notification) error {
 	lq.mu.Lock()
 	defer lq.mu.Unlock()
 
 	if lq.closed {
+		lq.release(len(notifications))
 		return fmt.Errorf("queue is closed")
 	}
 
+	enqueued := make([]*domain.QueueMessage, 0, len(notifications))
 	for _, notification := range notifications {
 		msg := &domain.QueueMessage{
 			ID:           uuid.New().String(),
@@ -101,21 +205,14 @@ func (lq *LocalQueue) EnqueueBatch(ctx context.Context, notifications []*domain.
 			EnqueuedAt:   time.Now().Unix(),
 		}
 
-		select {
-		case lq.queue <- msg:
-			lq.messages[msg.ID] = msg
-			notification.Status = domain.StatusQueued
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-lq.closeChan:
-			return fmt.Errorf("queue is closed")
-		}
+		lq.put(msg)
+		lq.messages[msg.ID] = msg
+		lq.enqueued++
+		notification.Status = domain.StatusQueued
+		enqueued = append(enqueued, msg)
 	}
 
-	if lq.persistToDisk {
-		return lq.persistToDiskSync()
-	}
-	return nil
+	return lq.persist(enqueued, nil)
 }
 
 // Dequeue retrieves the next notification from the queue
@@ -124,8 +221,13 @@ func (lq *LocalQueue) Dequeue(ctx context.Context) (*domain.QueueMessage, error)
 		return nil, fmt.Errorf("queue is closed")
 	}
 
+	if lq.sched != nil {
+		return lq.dequeueScheduled(ctx)
+	}
+
 	select {
 	case msg := <-lq.queue:
+		<-lq.slots
 		lq.mu.Lock()
 		msg.Attempt++
 		msg.Notification.Status = domain.StatusProcessing
@@ -138,6 +240,74 @@ func (lq *LocalQueue) Dequeue(ctx context.Context) (*domain.QueueMessage, error)
 	}
 }
 
+// dequeueScheduled retrieves the next notification chosen by the scheduler
+func (lq *LocalQueue) dequeueScheduled(ctx context.Context) (*domain.QueueMessage, error) {
+	select {
+	case <-lq.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-lq.closeChan:
+		return nil, fmt.Errorf("queue is closed")
+	}
+
+	<-lq.slots
+
+	lq.mu.Lock()
+	defer lq.mu.Unlock()
+
+	msg := lq.sched.pop()
+	if msg == nil {
+		// Purged while we were waiting
+		return nil, nil
+	}
+	msg.Attempt++
+	msg.Notification.Status = domain.StatusProcessing
+	return msg, nil
+}
+
+// reserve waits for at least one free buffer slot and reserves up to n, returning how many
+// it got. It must be called without the lock held, since consumers free slots before
+// taking it.
+func (lq *LocalQueue) reserve(ctx context.Context, n int) (int, error) {
+	select {
+	case lq.slots <- struct{}{}:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-lq.closeChan:
+		return 0, fmt.Errorf("queue is closed")
+	}
+
+	reserved := 1
+	for reserved < n {
+		select {
+		case lq.slots <- struct{}{}:
+			reserved++
+		default:
+			return reserved, nil
+		}
+	}
+	return reserved, nil
+}
+
+// release frees n reserved slots that weren't used
+func (lq *LocalQueue) release(n int) {
+	for i := 0; i < n; i++ {
+		<-lq.slots
+	}
+}
+
+// put adds a message to the channel or the scheduler into a slot reserved by the caller
+// (must be called with lock held)
+func (lq *LocalQueue) put(msg *domain.QueueMessage) {
+	if lq.sched == nil {
+		lq.queue <- msg
+		return
+	}
+
+	lq.sched.push(msg)
+	lq.ready <- struct{}{}
+}
+
 // Ack acknowledges successful processing of a message
 func (lq *LocalQueue) Ack(ctx context.Context, messageID string) error {
 	lq.mu.Lock()
@@ -146,10 +316,9 @@ func (lq *LocalQueue) Ack(ctx context.Context, messageID string) error {
 	if msg, exists := lq.messages[messageID]; exists {
 		msg.Notification.Status = domain.StatusSent
 		delete(lq.messages, messageID)
+		lq.acked++
 
-		if lq.persistToDisk {
-			return lq.persistToDiskSync()
-		}
+		return lq.persist(nil, []string{messageID})
 	}
 
 	return nil
@@ -157,63 +326,164 @@ func (lq *LocalQueue) Ack(ctx context.Context, messageID string) error {
 
 // Nack indicates processing failure and may requeue the message
 func (lq *LocalQueue) Nack(ctx context.Context, messageID string, requeue bool) error {
+	if requeue {
+		if _, err := lq.reserve(ctx, 1); err != nil {
+			return err
+		}
+	}
+
 	lq.mu.Lock()
 	defer lq.mu.Unlock()
 
+	if requeue && lq.closed {
+		lq.release(1)
+		return fmt.Errorf("queue is closed")
+	}
+
 	msg, exists := lq.messages[messageID]
 	if !exists {
+		if requeue {
+			lq.release(1)
+		}
 		return fmt.Errorf("message not found: %s", messageID)
 	}
 
+	lq.nacked++
 	if requeue {
 		msg.Notification.Status = domain.StatusRetrying
-		select {
-		case lq.queue <- msg:
-			if lq.persistToDisk {
-				return lq.persistToDiskSync()
-			}
-			return nil
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-lq.closeChan:
-			return fmt.Errorf("queue is closed")
-		}
+		lq.put(msg)
+		lq.requeued++
+		return lq.persist([]*domain.QueueMessage{msg}, nil)
 	} else {
 		msg.Notification.Status = domain.StatusFailed
 		delete(lq.messages, messageID)
 
-		if lq.persistToDisk {
-			return lq.persistToDiskSync()
-		}
+		return lq.persist(nil, []string{messageID})
 	}
+}
 
-	return nil
+// NackDelayed indicates processing failure and requeues the message once deliverAt has passed
+func (lq *LocalQueue) NackDelayed(ctx context.Context, messageID string, deliverAt time.Time) error {
+	if !deliverAt.After(time.Now()) {
+		return lq.Nack(ctx, messageID, true)
+	}
+
+	lq.mu.Lock()
+	defer lq.mu.Unlock()
+
+	msg, exists := lq.messages[messageID]
+	if !exists {
+		return fmt.Errorf("message not found: %s", messageID)
+	}
+
+	lq.nacked++
+	msg.Notification.Status = domain.StatusRetrying
+	msg.DeliverAt = deliverAt
+	lq.delay(msg)
+	lq.requeued++
+
+	return lq.persist([]*domain.QueueMessage{msg}, nil)
 }
 
 // Size returns the current number of messages in the queue
 func (lq *LocalQueue) Size(ctx context.Context) (int64, error) {
 	lq.mu.RLock()
 	defer lq.mu.RUnlock()
+	if lq.sched != nil {
+		return int64(lq.sched.len()), nil
+	}
 	return int64(len(lq.queue)), nil
 }
 
+// QueueMetrics reports the queue's backlog and throughput
+func (lq *LocalQueue) QueueMetrics() domain.QueueMetrics {
+	lq.mu.RLock()
+	defer lq.mu.RUnlock()
+
+	depth := int64(len(lq.queue))
+	if lq.sched != nil {
+		depth = int64(lq.sched.len())
+	}
+
+	delayed := int64(lq.delayed.Len())
+
+	metrics := domain.QueueMetrics{
+		Backend:  "local",
+		Depth:    depth,
+		InFlight: max(int64(len(lq.messages))-depth-delayed, 0),
+		Delayed:  delayed,
+		Enqueued: lq.enqueued,
+		Acked:    lq.acked,
+		Nacked:   lq.nacked,
+		Requeued: lq.requeued,
+	}
+
+	// A delayed message's age counts from its delivery time, so scheduled work doesn't look stuck
+	var oldest int64
+	for _, msg := range lq.messages {
+		since := max(msg.EnqueuedAt, msg.DeliverAt.Unix())
+		if oldest == 0 || since < oldest {
+			oldest = since
+		}
+	}
+	if oldest > 0 {
+		metrics.OldestAgeSeconds = max(time.Since(time.Unix(oldest, 0)).Seconds(), 0)
+	}
+
+	return metrics
+}
+
+// PendingMessages returns the messages not yet acked, whether waiting or in flight
+func (lq *LocalQueue) PendingMessages() []*domain.QueueMessage {
+	lq.mu.RLock()
+	defer lq.mu.RUnlock()
+
+	pending := make([]*domain.QueueMessage, 0, len(lq.messages))
+	for _, msg := range lq.messages {
+		pending = append(pending, msg)
+	}
+	return pending
+}
+
+// Durable reports whether pending messages are persisted to disk and reloaded on restart
+func (lq *LocalQueue) Durable() bool {
+	return lq.store != nil
+}
+
 // Purge removes all messages from the queue
 func (lq *LocalQueue) Purge(ctx context.Context) error {
 	lq.mu.Lock()
 	defer lq.mu.Unlock()
 
-	// Drain the channel
+	// Drain the channel and release its buffer slots
 	for len(lq.queue) > 0 {
 		<-lq.queue
+		<-lq.slots
 	}
 
-	lq.messages = make(map[string]*domain.QueueMessage)
+	// Drain the scheduler and release its buffer slots
+	if lq.sched != nil {
+		for i := lq.sched.len(); i > 0; i-- {
+			select {
+			case <-lq.ready:
+			default:
+			}
+			select {
+			case <-lq.slots:
+			default:
+			}
+		}
+		lq.sched.reset()
+	}
+	lq.delayed = nil
 
-	if lq.persistToDisk {
-		return lq.persistToDiskSync()
+	removed := make([]string, 0, len(lq.messages))
+	for id := range lq.messages {
+		removed = append(removed, id)
 	}
+	lq.messages = make(map[string]*domain.QueueMessage)
 
-	return nil
+	return lq.persist(nil, removed)
 }
 
 // Close cleanly shuts down the queue
@@ -228,8 +498,17 @@ func (lq *LocalQueue) Close() error {
 	lq.closed = true
 	close(lq.closeChan)
 
-	if lq.persistToDisk {
-		if err := lq.persistToDiskSync(); err != nil {
+	// Save attempt counts and statuses that changed since the messages were last written
+	if lq.store != nil {
+		pending := make([]*domain.QueueMessage, 0, len(lq.messages))
+		for _, msg := range lq.messages {
+			pending = append(pending, msg)
+		}
+		err := lq.persist(pending, nil)
+		if closeErr := lq.store.close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
 			return err
 		}
 	}
@@ -250,46 +529,40 @@ func (lq *LocalQueue) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
-// persistToDiskSync persists the queue state to disk (must be called with lock held)
-func (lq *LocalQueue) persistToDiskSync() error {
-	if !lq.persistToDisk || lq.persistPath == "" {
+// persist writes a change to the queue store, if persistence is enabled (must be called with lock held)
+func (lq *LocalQueue) persist(updated []*domain.QueueMessage, removed []string) error {
+	if lq.store == nil {
 		return nil
 	}
-
-	data, err := json.Marshal(lq.messages)
-	if err != nil {
-		return fmt.Errorf("failed to marshal queue state: %w", err)
-	}
-
-	if err := os.WriteFile(lq.persistPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write queue state: %w", err)
-	}
-
-	return nil
+	return lq.store.save(lq.messages, updated, removed)
 }
 
-// loadFromDisk loads the queue state from disk
+// loadFromDisk re-enqueues persisted messages in the order they were enqueued, holding
+// back those whose delivery time hasn't come yet
 func (lq *LocalQueue) loadFromDisk() error {
-	if lq.persistPath == "" {
-		return nil
-	}
-
-	data, err := os.ReadFile(lq.persistPath)
+	messages, err := lq.store.load()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No persisted state yet
-		}
-		return fmt.Errorf("failed to read queue state: %w", err)
+		return err
 	}
 
-	var messages map[string]*domain.QueueMessage
-	if err := json.Unmarshal(data, &messages); err != nil {
-		return fmt.Errorf("failed to unmarshal queue state: %w", err)
-	}
-
-	// Re-enqueue persisted messages
+	// EnqueuedAt only has second precision, so break ties on the notification's creation time
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].EnqueuedAt != messages[j].EnqueuedAt {
+			return messages[i].EnqueuedAt < messages[j].EnqueuedAt
+		}
+		return messages[i].Notification.CreatedAt.Before(messages[j].Notification.CreatedAt)
+	})
+	now := time.Now()
 	for _, msg := range messages {
-		lq.queue <- msg
+		if msg.DeliverAt.After(now) {
+			lq.delay(msg)
+			lq.messages[msg.ID] = msg
+			continue
+		}
+		if _, err := lq.reserve(context.Background(), 1); err != nil {
+			return err
+		}
+		lq.put(msg)
 		lq.messages[msg.ID] = msg
 	}
 
@@ -0,0 +1,113 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestQueueMetrics tests depth, in-flight and counter reporting through a message's lifecycle
+func TestQueueMetrics(t *testing.T) {
+	q, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := q.Enqueue(ctx, &domain.Notification{Type: domain.TypeStdout}); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	first, _ := q.Dequeue(ctx)
+	second, _ := q.Dequeue(ctx)
+	if m := q.QueueMetrics(); m.Backend != "local" || m.Depth != 1 || m.InFlight != 2 || m.Enqueued != 3 {
+		t.Fatalf("Unexpected metrics after dequeue: %+v", m)
+	}
+
+	if err := q.Ack(ctx, first.ID); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := q.Nack(ctx, second.ID, true); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+
+	m := q.QueueMetrics()
+	if m.Depth != 2 || m.InFlight != 0 || m.Acked != 1 || m.Nacked != 1 || m.Requeued != 1 {
+		t.Errorf("Unexpected metrics after ack and nack: %+v", m)
+	}
+	if m.OldestAgeSeconds < 0 || m.OldestAgeSeconds > 5 {
+		t.Errorf("Unexpected oldest message age: %v", m.OldestAgeSeconds)
+	}
+
+	if err := q.Purge(ctx); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if m := q.QueueMetrics(); m.Depth != 0 || m.OldestAgeSeconds != 0 {
+		t.Errorf("Expected an empty queue after purge, got %+v", m)
+	}
+}
+
+// TestEnqueueBatchLargerThanBuffer tests that a batch bigger than the buffer is enqueued
+// while a consumer drains it, instead of deadlocking on the full buffer
+func TestEnqueueBatchLargerThanBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *domain.LocalQueueConfig
+	}{
+		{name: "channel", config: &domain.LocalQueueConfig{BufferSize: 4}},
+		{name: "fair scheduling", config: &domain.LocalQueueConfig{BufferSize: 4, FairScheduling: true}},
+		{name: "priority scheduling", config: &domain.LocalQueueConfig{BufferSize: 4, PriorityScheduling: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewLocalQueue(tt.config)
+			if err != nil {
+				t.Fatalf("Failed to create queue: %v", err)
+			}
+			defer q.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			const total = 20
+			consumed := make(chan error, 1)
+			go func() {
+				for i := 0; i < total; i++ {
+					msg, err := q.Dequeue(ctx)
+					if err != nil {
+						consumed <- err
+						return
+					}
+					if err := q.Ack(ctx, msg.ID); err != nil {
+						consumed <- err
+						return
+					}
+				}
+				consumed <- nil
+			}()
+
+			batch := make([]*domain.Notification, total)
+			for i := range batch {
+				batch[i] = &domain.Notification{Type: domain.TypeStdout, Tenant: "bulk"}
+			}
+			if err := q.EnqueueBatch(ctx, batch); err != nil {
+				t.Fatalf("EnqueueBatch() error = %v", err)
+			}
+			if err := <-consumed; err != nil {
+				t.Fatalf("Consumer error = %v", err)
+			}
+
+			if m := q.QueueMetrics(); m.Depth != 0 || m.Acked != total {
+				t.Errorf("Unexpected metrics after draining the batch: %+v", m)
+			}
+		})
+	}
+}
@@ -0,0 +1,429 @@
+//go:build !lite
+
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/migrate"
+	_ "github.com/lib/pq" // PostgreSQL driver
+)
+
+// Defaults for the PostgreSQL queue when the durations aren't configured
+const (
+	defaultPostgresPollInterval = 500 * time.Millisecond
+	defaultPostgresLeaseTimeout = 5 * time.Minute
+)
+
+// postgresQueryTimeout bounds the metrics and listing queries, which take no context
+const postgresQueryTimeout = 5 * time.Second
+
+// PostgresQueue is a durable queue stored in a PostgreSQL table. A dequeued message is
+// leased to its consumer until it's acked or nacked; a lease that runs out, e.g. because
+// the server crashed, makes the message available again.
+type PostgresQueue struct {
+	db           *sql.DB
+	pollInterval time.Duration
+	leaseTimeout time.Duration
+	closeOnce    sync.Once
+	closeChan    chan struct{}
+
+	// Counters reported by QueueMetrics, for this instance only
+	enqueued, acked, nacked, requeued atomic.Uint64
+}
+
+// NewPostgresQueue connects to the database and checks the queue table's schema.
+// When AutoMigrate is set, pending migrations are applied first.
+func NewPostgresQueue(config *domain.PostgresQueueConfig) (*PostgresQueue, error) {
+	if config == nil || config.URL == "" {
+		return nil, fmt.Errorf("postgres queue url is required")
+	}
+
+	pollInterval, err := durationOrDefault(config.PollInterval, defaultPostgresPollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid poll interval: %w", err)
+	}
+	leaseTimeout, err := durationOrDefault(config.LeaseTimeout, defaultPostgresLeaseTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lease timeout: %w", err)
+	}
+
+	db, err := sql.Open("postgres", config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := preparePostgresSchema(db, config.AutoMigrate); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &PostgresQueue{
+		db:           db,
+		pollInterval: pollInterval,
+		leaseTimeout: leaseTimeout,
+		closeChan:    make(chan struct{}),
+	}, nil
+}
+
+// durationOrDefault parses a positive duration, using the default when it's empty
+func durationOrDefault(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%s must be positive", value)
+	}
+	return d, nil
+}
+
+// preparePostgresSchema pings the database and applies or checks schema migrations
+func preparePostgresSchema(db *sql.DB, autoMigrate bool) error {
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	ctx := context.Background()
+	if autoMigrate {
+		if _, err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+		return nil
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema has %d pending migration(s); run the migrate subcommand or enable auto_migrate", pending)
+	}
+
+	return nil
+}
+
+// Enqueue adds a notification to the queue
+func (pq *PostgresQueue) Enqueue(ctx context.Context, notification *domain.Notification) error {
+	return pq.EnqueueBatch(ctx, []*domain.Notification{notification})
+}
+
+// EnqueueBatch adds multiple notifications to the queue in one transaction
+func (pq *PostgresQueue) EnqueueBatch(ctx context.Context, notifications []*domain.Notification) error {
+	return pq.insert(ctx, notifications, time.Time{})
+}
+
+// EnqueueDelayed adds a notification that can't be claimed before deliverAt
+func (pq *PostgresQueue) EnqueueDelayed(ctx context.Context, notification *domain.Notification, deliverAt time.Time) error {
+	return pq.insert(ctx, []*domain.Notification{notification}, deliverAt)
+}
+
+// insert adds notifications in one transaction, held until deliverAt unless it's zero
+func (pq *PostgresQueue) insert(ctx context.Context, notifications []*domain.Notification, deliverAt time.Time) error {
+	if pq.isClosed() {
+		return fmt.Errorf("queue is closed")
+	}
+
+	tx, err := pq.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	status := domain.StatusQueued
+	if deliverAt.After(time.Now()) {
+		status = domain.StatusScheduled
+	}
+	for _, notification := range notifications {
+		notification.Status = status
+		payload, err := json.Marshal(notification)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification %s: %w", notification.ID, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO notification_queue (message_id, notification, deliver_at) VALUES ($1, $2, $3)`,
+			uuid.New().String(), payload, nullTime(deliverAt)); err != nil {
+			return fmt.Errorf("failed to enqueue notification %s: %w", notification.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit enqueue: %w", err)
+	}
+	pq.enqueued.Add(uint64(len(notifications)))
+
+	return nil
+}
+
+// Dequeue leases the oldest available message, polling until one arrives or the context ends
+func (pq *PostgresQueue) Dequeue(ctx context.Context) (*domain.QueueMessage, error) {
+	for {
+		if pq.isClosed() {
+			return nil, fmt.Errorf("queue is closed")
+		}
+
+		msg, err := pq.claim(ctx)
+		if err != nil || msg != nil {
+			return msg, err
+		}
+
+		timer := time.NewTimer(pq.pollInterval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-pq.closeChan:
+			timer.Stop()
+			return nil, fmt.Errorf("queue is closed")
+		}
+	}
+}
+
+// claim leases the oldest message that is due and isn't leased to another consumer,
+// returning nil when there is none. SKIP LOCKED lets concurrent consumers claim different rows.
+func (pq *PostgresQueue) claim(ctx context.Context) (*domain.QueueMessage, error) {
+	row := pq.db.QueryRowContext(ctx, `
+		UPDATE notification_queue
+		SET attempt = attempt + 1, leased_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		WHERE id = (
+			SELECT id FROM notification_queue
+			WHERE (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
+				AND (deliver_at IS NULL OR deliver_at <= CURRENT_TIMESTAMP)
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING message_id, notification, attempt, enqueued_at, deliver_at`,
+		pq.leaseTimeout.Seconds())
+
+	msg, err := scanQueueMessage(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to dequeue: %w", err)
+	}
+
+	msg.Notification.Status = domain.StatusProcessing
+	return msg, nil
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanQueueMessage reads a message from message_id, notification, attempt, enqueued_at
+// and deliver_at columns
+func scanQueueMessage(row rowScanner) (*domain.QueueMessage, error) {
+	var (
+		msg        domain.QueueMessage
+		payload    []byte
+		enqueuedAt time.Time
+		deliverAt  sql.NullTime
+	)
+	if err := row.Scan(&msg.ID, &payload, &msg.Attempt, &enqueuedAt, &deliverAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(payload, &msg.Notification); err != nil {
+		return nil, fmt.Errorf("failed to decode message %s: %w", msg.ID, err)
+	}
+	msg.EnqueuedAt = enqueuedAt.Unix()
+	if deliverAt.Valid {
+		msg.DeliverAt = deliverAt.Time
+	}
+	return &msg, nil
+}
+
+// nullTime maps the zero time to NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// Ack acknowledges successful processing of a message, removing it from the queue
+func (pq *PostgresQueue) Ack(ctx context.Context, messageID string) error {
+	if _, err := pq.db.ExecContext(ctx, `DELETE FROM notification_queue WHERE message_id = $1`, messageID); err != nil {
+		return fmt.Errorf("failed to ack message %s: %w", messageID, err)
+	}
+	pq.acked.Add(1)
+	return nil
+}
+
+// Nack indicates processing failure. A requeued message moves to the back of the queue;
+// otherwise it's removed.
+func (pq *PostgresQueue) Nack(ctx context.Context, messageID string, requeue bool) error {
+	if requeue {
+		return pq.requeue(ctx, messageID, time.Time{})
+	}
+
+	result, err := pq.db.ExecContext(ctx, `DELETE FROM notification_queue WHERE message_id = $1`, messageID)
+	if err != nil {
+		return fmt.Errorf("failed to nack message %s: %w", messageID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("message not found: %s", messageID)
+	}
+
+	pq.nacked.Add(1)
+	return nil
+}
+
+// NackDelayed indicates processing failure and requeues the message, which can't be
+// claimed again before deliverAt
+func (pq *PostgresQueue) NackDelayed(ctx context.Context, messageID string, deliverAt time.Time) error {
+	return pq.requeue(ctx, messageID, deliverAt)
+}
+
+// requeue releases a message's lease and moves it to the back of the queue, held until
+// deliverAt unless it's zero
+func (pq *PostgresQueue) requeue(ctx context.Context, messageID string, deliverAt time.Time) error {
+	result, err := pq.db.ExecContext(ctx, `
+		UPDATE notification_queue
+		SET leased_until = NULL, deliver_at = $2, id = nextval(pg_get_serial_sequence('notification_queue', 'id'))
+		WHERE message_id = $1`, messageID, nullTime(deliverAt))
+	if err != nil {
+		return fmt.Errorf("failed to nack message %s: %w", messageID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("message not found: %s", messageID)
+	}
+
+	pq.nacked.Add(1)
+	pq.requeued.Add(1)
+	return nil
+}
+
+// Size returns the number of messages waiting to be dequeued, not counting delayed ones
+func (pq *PostgresQueue) Size(ctx context.Context) (int64, error) {
+	var size int64
+	err := pq.db.QueryRowContext(ctx, `
+		SELECT count(*) FROM notification_queue
+		WHERE (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
+			AND (deliver_at IS NULL OR deliver_at <= CURRENT_TIMESTAMP)`).Scan(&size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count messages: %w", err)
+	}
+	return size, nil
+}
+
+// QueueMetrics reports the shared backlog and this instance's throughput. The backlog is
+// reported as empty if the database can't be queried.
+func (pq *PostgresQueue) QueueMetrics() domain.QueueMetrics {
+	metrics := domain.QueueMetrics{
+		Backend:  "postgres",
+		Enqueued: pq.enqueued.Load(),
+		Acked:    pq.acked.Load(),
+		Nacked:   pq.nacked.Load(),
+		Requeued: pq.requeued.Load(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+
+	var oldest sql.NullTime
+	err := pq.db.QueryRowContext(ctx, `
+		SELECT
+			count(*) FILTER (WHERE (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
+				AND (deliver_at IS NULL OR deliver_at <= CURRENT_TIMESTAMP)),
+			count(*) FILTER (WHERE leased_until >= CURRENT_TIMESTAMP),
+			count(*) FILTER (WHERE (leased_until IS NULL OR leased_until < CURRENT_TIMESTAMP)
+				AND deliver_at > CURRENT_TIMESTAMP),
+			min(GREATEST(enqueued_at, deliver_at))
+		FROM notification_queue`).Scan(&metrics.Depth, &metrics.InFlight, &metrics.Delayed, &oldest)
+	if err != nil {
+		return metrics
+	}
+	if oldest.Valid {
+		metrics.OldestAgeSeconds = max(time.Since(oldest.Time).Seconds(), 0)
+	}
+
+	return metrics
+}
+
+// PendingMessages returns every message in the table, including those leased by other
+// instances. Nothing is returned if the database can't be queried.
+func (pq *PostgresQueue) PendingMessages() []*domain.QueueMessage {
+	ctx, cancel := context.WithTimeout(context.Background(), postgresQueryTimeout)
+	defer cancel()
+
+	rows, err := pq.db.QueryContext(ctx, `SELECT message_id, notification, attempt, enqueued_at, deliver_at FROM notification_queue ORDER BY id`)
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var pending []*domain.QueueMessage
+	for rows.Next() {
+		msg, err := scanQueueMessage(rows)
+		if err != nil {
+			continue
+		}
+		pending = append(pending, msg)
+	}
+	return pending
+}
+
+// Durable reports that messages are kept in the database across restarts
+func (pq *PostgresQueue) Durable() bool {
+	return true
+}
+
+// Purge removes all messages from the queue, including those leased by other instances
+func (pq *PostgresQueue) Purge(ctx context.Context) error {
+	if _, err := pq.db.ExecContext(ctx, `DELETE FROM notification_queue`); err != nil {
+		return fmt.Errorf("failed to purge queue: %w", err)
+	}
+	return nil
+}
+
+// Close stops consumers and closes the database connection. Messages stay in the table;
+// any still leased become available again when their lease runs out.
+func (pq *PostgresQueue) Close() error {
+	var err error
+	pq.closeOnce.Do(func() {
+		close(pq.closeChan)
+		err = pq.db.Close()
+	})
+	return err
+}
+
+// HealthCheck verifies the database is reachable
+func (pq *PostgresQueue) HealthCheck(ctx context.Context) error {
+	if pq.isClosed() {
+		return fmt.Errorf("queue is closed")
+	}
+	if err := pq.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database unreachable: %w", err)
+	}
+	return nil
+}
+
+// isClosed reports whether Close has been called
+func (pq *PostgresQueue) isClosed() bool {
+	select {
+	case <-pq.closeChan:
+		return true
+	default:
+		return false
+	}
+}
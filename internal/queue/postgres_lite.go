@@ -0,0 +1,19 @@
+//go:build lite
+
+package queue
+
+import (
+	"errors"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// PostgresQueue is unavailable in lite builds, which leave out the PostgreSQL driver
+type PostgresQueue struct {
+	domain.Queue
+}
+
+// NewPostgresQueue reports that lite builds have no PostgreSQL queue
+func NewPostgresQueue(config *domain.PostgresQueueConfig) (*PostgresQueue, error) {
+	return nil, errors.New("postgres queue is not included in lite builds")
+}
@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// defaultPriorityMaxWait is how long a message may be passed over by higher priorities
+// before it's served anyway
+const defaultPriorityMaxWait = time.Minute
+
+// priorityScheduler serves higher priority messages first, oldest first within a priority.
+// A message that has waited longer than maxWait is served ahead of newer higher-priority
+// messages, so a steady stream of urgent notifications can't starve low-priority ones.
+type priorityScheduler struct {
+	maxWait time.Duration
+	lanes   [domain.PriorityCritical + 1][]priorityEntry
+	size    int
+	now     func() time.Time
+}
+
+// priorityEntry is a buffered message and when it was added
+type priorityEntry struct {
+	msg      *domain.QueueMessage
+	pushedAt time.Time
+}
+
+// newPriorityScheduler creates a priority scheduler with the given starvation limit
+func newPriorityScheduler(maxWait time.Duration) *priorityScheduler {
+	return &priorityScheduler{
+		maxWait: maxWait,
+		now:     time.Now,
+	}
+}
+
+// lane returns the lane index of a priority, clamping values outside the known range
+func (p *priorityScheduler) lane(priority domain.Priority) int {
+	return int(min(max(priority, domain.PriorityLow), domain.PriorityCritical))
+}
+
+// push adds a message behind any queued messages of the same priority
+func (p *priorityScheduler) push(msg *domain.QueueMessage) {
+	lane := p.lane(msg.Notification.Priority)
+	p.lanes[lane] = append(p.lanes[lane], priorityEntry{msg: msg, pushedAt: p.now()})
+	p.size++
+}
+
+// pop removes the next message, or returns nil when empty
+func (p *priorityScheduler) pop() *domain.QueueMessage {
+	if p.size == 0 {
+		return nil
+	}
+
+	// The longest waiting message past the limit goes first, whatever its priority
+	now := p.now()
+	overdue := -1
+	for lane, entries := range p.lanes {
+		if len(entries) == 0 || now.Sub(entries[0].pushedAt) < p.maxWait {
+			continue
+		}
+		if overdue == -1 || entries[0].pushedAt.Before(p.lanes[overdue][0].pushedAt) {
+			overdue = lane
+		}
+	}
+	if overdue >= 0 {
+		return p.take(overdue)
+	}
+
+	for lane := len(p.lanes) - 1; lane >= 0; lane-- {
+		if len(p.lanes[lane]) > 0 {
+			return p.take(lane)
+		}
+	}
+	return nil
+}
+
+// take removes the oldest message of a lane
+func (p *priorityScheduler) take(lane int) *domain.QueueMessage {
+	entries := p.lanes[lane]
+	msg := entries[0].msg
+	entries[0] = priorityEntry{}
+	p.lanes[lane] = entries[1:]
+	p.size--
+	return msg
+}
+
+// len returns the number of buffered messages
+func (p *priorityScheduler) len() int {
+	return p.size
+}
+
+// reset drops all buffered messages
+func (p *priorityScheduler) reset() {
+	for lane := range p.lanes {
+		p.lanes[lane] = nil
+	}
+	p.size = 0
+}
@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestPrioritySchedulingOrder tests that urgent notifications are dequeued ahead of older routine ones
+func TestPrioritySchedulingOrder(t *testing.T) {
+	q, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10, PriorityScheduling: true})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notifications := []*domain.Notification{
+		{ID: "low", Priority: domain.PriorityLow},
+		{ID: "normal-1", Priority: domain.PriorityNormal},
+		{ID: "critical", Priority: domain.PriorityCritical},
+		{ID: "normal-2", Priority: domain.PriorityNormal},
+		{ID: "high", Priority: domain.PriorityHigh},
+	}
+	if err := q.EnqueueBatch(ctx, notifications); err != nil {
+		t.Fatalf("EnqueueBatch() error = %v", err)
+	}
+
+	for _, want := range []string{"critical", "high", "normal-1", "normal-2", "low"} {
+		msg, err := q.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("Dequeue() error = %v", err)
+		}
+		if msg.Notification.ID != want {
+			t.Fatalf("Dequeued %s, want %s", msg.Notification.ID, want)
+		}
+	}
+}
+
+// TestPrioritySchedulerStarvation tests that a message waiting past the limit is served ahead of higher priorities
+func TestPrioritySchedulerStarvation(t *testing.T) {
+	now := time.Now()
+	p := newPriorityScheduler(time.Minute)
+	p.now = func() time.Time { return now }
+
+	message := func(id string, priority domain.Priority) *domain.QueueMessage {
+		return &domain.QueueMessage{ID: id, Notification: &domain.Notification{Priority: priority}}
+	}
+
+	p.push(message("low", domain.PriorityLow))
+	now = now.Add(30 * time.Second)
+	p.push(message("high-1", domain.PriorityHigh))
+	p.push(message("high-2", domain.PriorityHigh))
+
+	if msg := p.pop(); msg.ID != "high-1" {
+		t.Fatalf("Expected high-1 before the limit, got %s", msg.ID)
+	}
+
+	now = now.Add(45 * time.Second)
+	if msg := p.pop(); msg.ID != "low" {
+		t.Fatalf("Expected low once it waited past the limit, got %s", msg.ID)
+	}
+	if msg := p.pop(); msg.ID != "high-2" {
+		t.Fatalf("Expected high-2, got %s", msg.ID)
+	}
+	if p.pop() != nil || p.len() != 0 {
+		t.Error("Expected the scheduler to be empty")
+	}
+}
+
+// TestPrioritySchedulingConfig tests that priority and fair scheduling are mutually exclusive
+func TestPrioritySchedulingConfig(t *testing.T) {
+	if _, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10, PriorityScheduling: true, FairScheduling: true}); err == nil {
+		t.Error("Expected error when both schedulers are enabled")
+	}
+	if _, err := NewLocalQueue(&domain.LocalQueueConfig{BufferSize: 10, PriorityScheduling: true, PriorityMaxWait: "never"}); err == nil {
+		t.Error("Expected error for an invalid max wait")
+	}
+}
@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Constructor creates a queue from the queue configuration
+type Constructor func(config *domain.QueueConfig) (domain.Queue, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Constructor)
+)
+
+func init() {
+	Register("local", func(config *domain.QueueConfig) (domain.Queue, error) {
+		return NewLocalQueue(config.Local)
+	})
+	Register("postgres", func(config *domain.QueueConfig) (domain.Queue, error) {
+		return NewPostgresQueue(config.Postgres)
+	})
+}
+
+// Register makes a queue backend available as queue.type name. It panics if the name is
+// already registered or the constructor is nil, so it's meant to be called from init.
+func Register(name string, constructor Constructor) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if constructor == nil {
+		panic("queue: Register constructor is nil for " + name)
+	}
+	if _, exists := drivers[name]; exists {
+		panic("queue: Register called twice for " + name)
+	}
+	drivers[name] = constructor
+}
+
+// IsRegistered reports whether a queue backend is registered under name
+func IsRegistered(name string) bool {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	_, exists := drivers[name]
+	return exists
+}
+
+// Drivers returns the registered queue backend names, sorted
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the queue backend selected by config.Type
+func New(config *domain.QueueConfig) (domain.Queue, error) {
+	driversMu.RLock()
+	constructor, exists := drivers[config.Type]
+	driversMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown queue type: %s", config.Type)
+	}
+
+	q, err := constructor(config)
+	if err != nil {
+		return nil, err
+	}
+	return q, nil
+}
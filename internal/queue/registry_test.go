@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestRegistry tests that registered backends are selected by queue type
+func TestRegistry(t *testing.T) {
+	var got *domain.QueueConfig
+	Register("test-registry", func(config *domain.QueueConfig) (domain.Queue, error) {
+		got = config
+		return NewLocalQueue(nil)
+	})
+
+	config := &domain.QueueConfig{Type: "test-registry"}
+	q, err := New(config)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer q.Close()
+	if got != config {
+		t.Error("Expected the constructor to receive the queue config")
+	}
+
+	if !IsRegistered("local") || !IsRegistered("postgres") || !slices.Contains(Drivers(), "test-registry") {
+		t.Errorf("Unexpected drivers: %v", Drivers())
+	}
+
+	if _, err := New(&domain.QueueConfig{Type: "missing"}); err == nil {
+		t.Error("Expected error for an unregistered queue type")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected a duplicate registration to panic")
+		}
+	}()
+	Register("local", func(config *domain.QueueConfig) (domain.Queue, error) { return nil, nil })
+}
+
+// TestRegistryConstructorError tests that a failing constructor returns a nil queue
+func TestRegistryConstructorError(t *testing.T) {
+	q, err := New(&domain.QueueConfig{Type: "local", Local: &domain.LocalQueueConfig{BufferSize: 1, FairScheduling: true, PriorityScheduling: true}})
+	if err == nil || q != nil {
+		t.Errorf("Expected a nil queue and an error, got %v, %v", q, err)
+	}
+}
@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Persistence backends for the local queue
+const (
+	PersistJSON  = "json"  // Rewrite a JSON file on every change
+	PersistBBolt = "bbolt" // Transactional writes to an embedded bbolt database
+)
+
+// queueStore persists the local queue's messages so they survive a restart
+type queueStore interface {
+	// load returns the persisted messages
+	load() ([]*domain.QueueMessage, error)
+
+	// save records a change to the queue. messages is the full set after the change;
+	// updated and removed are the messages that were written and the IDs that were dropped.
+	save(messages map[string]*domain.QueueMessage, updated []*domain.QueueMessage, removed []string) error
+
+	close() error
+}
+
+// newQueueStore opens the store for the configured backend, creating its directory if needed
+func newQueueStore(backend, path string) (queueStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create queue directory: %w", err)
+	}
+
+	switch backend {
+	case "", PersistJSON:
+		return &jsonStore{path: path}, nil
+	case PersistBBolt:
+		return openBoltStore(path)
+	default:
+		return nil, fmt.Errorf("unknown persist backend: %s (must be json or bbolt)", backend)
+	}
+}
+
+// jsonStore writes the whole queue to a JSON file on every change
+type jsonStore struct {
+	path string
+}
+
+func (s *jsonStore) load() ([]*domain.QueueMessage, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // No persisted state yet
+		}
+		return nil, fmt.Errorf("failed to read queue state: %w", err)
+	}
+
+	var messages map[string]*domain.QueueMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal queue state: %w", err)
+	}
+
+	loaded := make([]*domain.QueueMessage, 0, len(messages))
+	for _, msg := range messages {
+		loaded = append(loaded, msg)
+	}
+	return loaded, nil
+}
+
+func (s *jsonStore) save(messages map[string]*domain.QueueMessage, updated []*domain.QueueMessage, removed []string) error {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("failed to marshal queue state: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old state, so a crash or a concurrent
+	// backup never sees a partially written file
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write queue state: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to write queue state: %w", err)
+	}
+
+	return nil
+}
+
+func (s *jsonStore) close() error {
+	return nil
+}
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestPersistedQueueSurvivesRestart tests that unacknowledged messages are reloaded in order
+// by both persistence backends
+func TestPersistedQueueSurvivesRestart(t *testing.T) {
+	for _, backend := range []string{PersistJSON, PersistBBolt} {
+		t.Run(backend, func(t *testing.T) {
+			config := &domain.LocalQueueConfig{
+				BufferSize:     10,
+				PersistToDisk:  true,
+				PersistPath:    filepath.Join(t.TempDir(), "state", "queue."+backend),
+				PersistBackend: backend,
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			q, err := NewLocalQueue(config)
+			if err != nil {
+				t.Fatalf("Failed to create queue: %v", err)
+			}
+			created := time.Now()
+			for i, id := range []string{"first", "second", "third"} {
+				notification := &domain.Notification{ID: id, Type: domain.TypeStdout, CreatedAt: created.Add(time.Duration(i) * time.Millisecond)}
+				if err := q.Enqueue(ctx, notification); err != nil {
+					t.Fatalf("Enqueue() error = %v", err)
+				}
+			}
+
+			// Deliver the first one; the others are still in the queue when it stops
+			msg, err := q.Dequeue(ctx)
+			if err != nil {
+				t.Fatalf("Dequeue() error = %v", err)
+			}
+			if err := q.Ack(ctx, msg.ID); err != nil {
+				t.Fatalf("Ack() error = %v", err)
+			}
+			if err := q.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			q, err = NewLocalQueue(config)
+			if err != nil {
+				t.Fatalf("Failed to reopen queue: %v", err)
+			}
+			defer q.Close()
+
+			if size, _ := q.Size(ctx); size != 2 {
+				t.Fatalf("Expected 2 messages after restart, got %d", size)
+			}
+			for _, want := range []string{"second", "third"} {
+				msg, err := q.Dequeue(ctx)
+				if err != nil {
+					t.Fatalf("Dequeue() error = %v", err)
+				}
+				if msg.Notification.ID != want {
+					t.Errorf("Dequeued %s, want %s", msg.Notification.ID, want)
+				}
+			}
+		})
+	}
+}
+
+// TestBoltStoreIsLocked tests that a second queue can't open a bbolt store that is in use
+func TestBoltStoreIsLocked(t *testing.T) {
+	config := &domain.LocalQueueConfig{
+		BufferSize:     10,
+		PersistToDisk:  true,
+		PersistPath:    filepath.Join(t.TempDir(), "queue.db"),
+		PersistBackend: PersistBBolt,
+	}
+
+	q, err := NewLocalQueue(config)
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	defer q.Close()
+
+	if _, err := NewLocalQueue(config); err == nil {
+		t.Error("Expected opening a locked store to fail")
+	}
+}
@@ -0,0 +1,205 @@
+package recurrence
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors are the @ shorthands for common cron expressions
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the range and names of one cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names of the values from min, if the field has them
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	dowField    = cronField{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// cronSchedule is a parsed cron expression. Each field is a bit set of the values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool // The day fields were *, so only the other one restricts the day
+	loc                           *time.Location
+}
+
+// parseCron parses a five-field cron expression or descriptor, optionally prefixed with
+// CRON_TZ=<zone>
+func parseCron(expr string) (*cronSchedule, error) {
+	loc := time.UTC
+	if rest, ok := cutPrefixFold(expr, "CRON_TZ="); ok {
+		zone, spec, _ := strings.Cut(rest, " ")
+		var err error
+		if loc, err = loadLocation(zone); err != nil {
+			return nil, err
+		}
+		expr = strings.TrimSpace(spec)
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		spec, ok := cronDescriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron descriptor %q", expr)
+		}
+		expr = spec
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d", len(fields))
+	}
+
+	c := &cronSchedule{loc: loc}
+	var err error
+	if c.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if c.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+
+	// 7 is another name for Sunday
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*" || fields[2] == "?"
+	c.dowAny = fields[4] == "*" || fields[4] == "?"
+	return c, nil
+}
+
+// parse parses a comma-separated list of values, ranges and steps into a bit set
+func (f cronField) parse(spec string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(spec, ",") {
+		rangeSpec, stepSpec, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepSpec); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepSpec)
+			}
+		}
+
+		var low, high int
+		switch {
+		case rangeSpec == "*" || rangeSpec == "?":
+			low, high = f.min, f.max
+			if f.name == dowField.name {
+				high = 6 // Don't count Sunday twice
+			}
+		case strings.Contains(rangeSpec, "-"):
+			from, to, _ := strings.Cut(rangeSpec, "-")
+			var err error
+			if low, err = f.value(from); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(to); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rangeSpec)
+			}
+		default:
+			var err error
+			if low, err = f.value(rangeSpec); err != nil {
+				return 0, err
+			}
+			high = low
+			if hasStep {
+				high = f.max // a/n means every nth value from a
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name
+func (f cronField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q (must be %d-%d)", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next returns the first minute after t matching the expression
+func (c *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + searchYears
+
+	for t.Year() <= limit {
+		prev := t
+		switch {
+		case c.month&(1<<int(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+
+		// Daylight saving changes can map a local time back to an instant already checked
+		if !t.After(prev) {
+			t = prev.Add(time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's day rule: when both day fields are restricted, a day matching
+// either one matches
+func (c *cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
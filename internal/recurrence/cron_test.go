@@ -0,0 +1,86 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCronNext tests the next occurrence of cron expressions
+func TestCronNext(t *testing.T) {
+	from := time.Date(2026, 1, 30, 10, 17, 42, 0, time.UTC) // A Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 30, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * *", time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)},
+		{"30 8 * * mon-fri", time.Date(2026, 2, 2, 8, 30, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 * *", time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan,jul *", time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{"0 0 13 * 5", time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC)}, // 13th or any Friday
+		{"0 6 * * 7", time.Date(2026, 2, 1, 6, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 30, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"CRON_TZ=America/New_York 0 9 * * *", time.Date(2026, 1, 30, 14, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr, from)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// TestCronNextAcrossDaylightSaving tests that local times skipped by a daylight saving change
+// are moved past the gap
+func TestCronNextAcrossDaylightSaving(t *testing.T) {
+	schedule, err := Parse("CRON_TZ=Europe/London 30 1 * * *", time.Time{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	// Clocks go forward from 01:00 to 02:00 on 29 March 2026, so 01:30 doesn't exist that day
+	got := schedule.Next(time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 30, 0, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+// TestCronNeverMatches tests that an impossible expression has no next occurrence
+func TestCronNeverMatches(t *testing.T) {
+	schedule, err := Parse("0 0 30 2 *", time.Time{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := schedule.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected no occurrence, got %v", got)
+	}
+}
+
+// TestParseCronErrors tests that malformed cron expressions are rejected
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@fortnightly",
+		"CRON_TZ=Nowhere/Special * * * * *",
+	} {
+		if _, err := Parse(expr, time.Now()); err == nil {
+			t.Errorf("Parse(%q) expected an error", expr)
+		}
+	}
+}
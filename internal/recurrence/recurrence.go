@@ -0,0 +1,46 @@
+// Package recurrence parses the schedules of recurring notifications. A schedule is either a
+// cron expression or an iCalendar (RFC 5545) recurrence rule, and yields the times at which
+// the notification is sent.
+package recurrence
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// searchYears bounds how far ahead a schedule is searched for its next occurrence, so a rule
+// that can never match (e.g. February 30th) ends instead of looping forever
+const searchYears = 5
+
+// Schedule yields the occurrences of a recurring notification
+type Schedule interface {
+	// Next returns the first occurrence after t, or the zero time when there are no more
+	Next(t time.Time) time.Time
+}
+
+// Parse parses a schedule. A cron expression has five fields (minute, hour, day of month,
+// month and day of week) or is a descriptor such as @daily, and is evaluated in UTC unless
+// prefixed with CRON_TZ=<zone>. An RRULE (e.g. "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=9") may be
+// preceded by a DTSTART; without one the rule starts at start, truncated to the minute.
+func Parse(expr string, start time.Time) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+
+	upper := strings.ToUpper(expr)
+	if strings.HasPrefix(upper, "DTSTART") || strings.HasPrefix(upper, "RRULE:") || strings.Contains(upper, "FREQ=") {
+		return parseRRule(expr, start)
+	}
+	return parseCron(expr)
+}
+
+// loadLocation loads a time zone by IANA name
+func loadLocation(name string) (*time.Location, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return loc, nil
+}
@@ -0,0 +1,461 @@
+package recurrence
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds how many periods of a rule are expanded looking for an occurrence
+const maxPeriods = 100000
+
+// frequency is an RRULE FREQ
+type frequency int
+
+const (
+	minutely frequency = iota
+	hourly
+	daily
+	weekly
+	monthly
+	yearly
+)
+
+var frequencies = map[string]frequency{
+	"MINUTELY": minutely,
+	"HOURLY":   hourly,
+	"DAILY":    daily,
+	"WEEKLY":   weekly,
+	"MONTHLY":  monthly,
+	"YEARLY":   yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// byDay is a BYDAY entry: a weekday, optionally the nth one of the month or year
+// (negative counts from the end)
+type byDay struct {
+	weekday time.Weekday
+	n       int
+}
+
+// rrule is a parsed recurrence rule. It supports FREQ, INTERVAL, COUNT, UNTIL, BYMONTH,
+// BYMONTHDAY, BYDAY, BYHOUR and BYMINUTE, with weeks starting on Monday.
+type rrule struct {
+	freq       frequency
+	interval   int
+	count      int
+	until      time.Time
+	start      time.Time // DTSTART, in the rule's time zone
+	byMonth    []int
+	byMonthDay []int
+	byDay      []byDay
+	byHour     []int
+	byMinute   []int
+}
+
+// parseRRule parses an RRULE, optionally preceded by a DTSTART line
+func parseRRule(expr string, start time.Time) (*rrule, error) {
+	r := &rrule{interval: 1, start: start.UTC().Truncate(time.Minute)}
+	var rule string
+	for _, line := range strings.Fields(expr) {
+		upper := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(upper, "DTSTART"):
+			dtstart, err := parseDTStart(line)
+			if err != nil {
+				return nil, err
+			}
+			r.start = dtstart
+		case strings.HasPrefix(upper, "RRULE:"):
+			rule = line[len("RRULE:"):]
+		default:
+			rule = line
+		}
+	}
+	if rule == "" {
+		return nil, fmt.Errorf("RRULE is missing")
+	}
+
+	freqSet := false
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid RRULE part %q", part)
+		}
+		key, value = strings.ToUpper(key), strings.ToUpper(value)
+
+		var err error
+		switch key {
+		case "FREQ":
+			if r.freq, ok = frequencies[value]; !ok {
+				return nil, fmt.Errorf("unsupported FREQ %q", value)
+			}
+			freqSet = true
+		case "INTERVAL":
+			if r.interval, err = strconv.Atoi(value); err != nil || r.interval < 1 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+		case "COUNT":
+			if r.count, err = strconv.Atoi(value); err != nil || r.count < 1 {
+				return nil, fmt.Errorf("invalid COUNT %q", value)
+			}
+		case "UNTIL":
+			if r.until, err = parseICalTime(value, r.start.Location()); err != nil {
+				return nil, fmt.Errorf("invalid UNTIL %q", value)
+			}
+		case "BYMONTH":
+			r.byMonth, err = parseInts(key, value, 1, 12, false)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseInts(key, value, 1, 31, true)
+		case "BYHOUR":
+			r.byHour, err = parseInts(key, value, 0, 23, false)
+		case "BYMINUTE":
+			r.byMinute, err = parseInts(key, value, 0, 59, false)
+		case "BYDAY":
+			r.byDay, err = parseByDay(value)
+		case "WKST":
+			if value != "MO" {
+				err = fmt.Errorf("unsupported WKST %q (weeks start on Monday)", value)
+			}
+		default:
+			err = fmt.Errorf("unsupported RRULE part %s", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !freqSet {
+		return nil, fmt.Errorf("RRULE requires FREQ")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, fmt.Errorf("RRULE can't have both COUNT and UNTIL")
+	}
+	for _, day := range r.byDay {
+		if day.n != 0 && r.freq != monthly && r.freq != yearly {
+			return nil, fmt.Errorf("numbered BYDAY is only supported with FREQ=MONTHLY or YEARLY")
+		}
+	}
+	return r, nil
+}
+
+// parseDTStart parses a DTSTART line: DTSTART:20260105T090000Z, or a local time with
+// DTSTART;TZID=Europe/London:20260105T090000 (UTC if no TZID is given)
+func parseDTStart(line string) (time.Time, error) {
+	params, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid DTSTART %q", line)
+	}
+
+	loc := time.UTC
+	for _, param := range strings.Split(params, ";")[1:] {
+		key, zone, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(key, "TZID") {
+			return time.Time{}, fmt.Errorf("unsupported DTSTART parameter %q", param)
+		}
+		var err error
+		if loc, err = loadLocation(zone); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	t, err := parseICalTime(strings.ToUpper(value), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTSTART %q", value)
+	}
+	return t, nil
+}
+
+// parseICalTime parses an iCalendar date or date-time, in UTC when it ends in Z and
+// otherwise in loc
+func parseICalTime(value string, loc *time.Location) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+	if len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t.AddDate(0, 0, 1).Add(-time.Second), err // A date UNTIL includes the whole day
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+// parseInts parses a comma-separated list of numbers within min and max, or their negatives
+// counting from the end if allowed
+func parseInts(key, value string, min, max int, negative bool) ([]int, error) {
+	var values []int
+	for _, s := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(s)
+		abs := v
+		if negative && v < 0 {
+			abs = -v
+		}
+		if err != nil || abs < min || abs > max {
+			return nil, fmt.Errorf("invalid %s value %q", key, s)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseByDay parses BYDAY entries such as MO, 1MO or -1FR
+func parseByDay(value string) ([]byDay, error) {
+	var days []byDay
+	for _, s := range strings.Split(value, ",") {
+		if len(s) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY value %q", s)
+		}
+		weekday, ok := weekdays[s[len(s)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY value %q", s)
+		}
+		day := byDay{weekday: weekday}
+		if prefix := s[:len(s)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n > 53 || n < -53 {
+				return nil, fmt.Errorf("invalid BYDAY value %q", s)
+			}
+			day.n = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Next returns the first occurrence after t. Rules with a COUNT are expanded from their
+// start, so occurrences before t are counted.
+func (r *rrule) Next(t time.Time) time.Time {
+	k := 0
+	if r.count == 0 && t.After(r.start) {
+		k = r.periodsBetween(t.In(r.start.Location())) / r.interval
+	}
+
+	seen := 0
+	limit := t.In(r.start.Location()).AddDate(searchYears, 0, 0)
+	for i := 0; i < maxPeriods; i, k = i+1, k+1 {
+		periodStart := r.period(k)
+		if periodStart.After(limit) || (!r.until.IsZero() && periodStart.After(r.until)) {
+			return time.Time{}
+		}
+
+		for _, occurrence := range r.expand(periodStart) {
+			if occurrence.Before(r.start) {
+				continue
+			}
+			if !r.until.IsZero() && occurrence.After(r.until) {
+				return time.Time{}
+			}
+			seen++
+			if r.count > 0 && seen > r.count {
+				return time.Time{}
+			}
+			if occurrence.After(t) {
+				return occurrence
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// periodsBetween returns how many whole frequency units lie between the start's period and t's
+func (r *rrule) periodsBetween(t time.Time) int {
+	s := r.start
+	switch r.freq {
+	case minutely:
+		return int(t.Sub(s.Truncate(time.Minute)) / time.Minute)
+	case hourly:
+		return int(t.Sub(r.period(0)) / time.Hour)
+	case daily:
+		return daysBetween(s, t)
+	case weekly:
+		return daysBetween(weekStart(s), t) / 7
+	case monthly:
+		return (t.Year()-s.Year())*12 + int(t.Month()-s.Month())
+	default:
+		return t.Year() - s.Year()
+	}
+}
+
+// period returns the start of the kth period of the rule, counting in intervals from the start
+func (r *rrule) period(k int) time.Time {
+	s, n := r.start, k*r.interval
+	switch r.freq {
+	case minutely:
+		return s.Truncate(time.Minute).Add(time.Duration(n) * time.Minute)
+	case hourly:
+		return time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), 0, 0, 0, s.Location()).Add(time.Duration(n) * time.Hour)
+	case daily:
+		return time.Date(s.Year(), s.Month(), s.Day()+n, 0, 0, 0, 0, s.Location())
+	case weekly:
+		w := weekStart(s)
+		return time.Date(w.Year(), w.Month(), w.Day()+7*n, 0, 0, 0, 0, s.Location())
+	case monthly:
+		return time.Date(s.Year(), s.Month()+time.Month(n), 1, 0, 0, 0, 0, s.Location())
+	default:
+		return time.Date(s.Year()+n, 1, 1, 0, 0, 0, 0, s.Location())
+	}
+}
+
+// expand returns the occurrences within the period starting at periodStart, in order
+func (r *rrule) expand(periodStart time.Time) []time.Time {
+	loc := r.start.Location()
+
+	var days []time.Time
+	switch r.freq {
+	case minutely, hourly, daily:
+		days = []time.Time{dateOf(periodStart)}
+	case weekly:
+		for i := 0; i < 7; i++ {
+			days = append(days, time.Date(periodStart.Year(), periodStart.Month(), periodStart.Day()+i, 0, 0, 0, 0, loc))
+		}
+	case monthly:
+		for d := periodStart; d.Month() == periodStart.Month(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	case yearly:
+		for d := periodStart; d.Year() == periodStart.Year(); d = d.AddDate(0, 0, 1) {
+			days = append(days, d)
+		}
+	}
+
+	hours := r.byHour
+	if r.freq == minutely || r.freq == hourly {
+		hours = filterInts([]int{periodStart.Hour()}, r.byHour)
+	} else if len(hours) == 0 {
+		hours = []int{r.start.Hour()}
+	}
+	minutes := r.byMinute
+	if r.freq == minutely {
+		minutes = filterInts([]int{periodStart.Minute()}, r.byMinute)
+	} else if len(minutes) == 0 {
+		minutes = []int{r.start.Minute()}
+	}
+
+	var occurrences []time.Time
+	for _, day := range days {
+		if !r.dayMatches(day) {
+			continue
+		}
+		for _, hour := range hours {
+			for _, minute := range minutes {
+				occurrences = append(occurrences, time.Date(day.Year(), day.Month(), day.Day(), hour, minute, r.start.Second(), 0, loc))
+			}
+		}
+	}
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Before(occurrences[j]) })
+	return occurrences
+}
+
+// dayMatches reports whether the rule's day parts select a day. Without any, weekly rules
+// fall on the start's weekday, monthly ones on its day of the month and yearly ones on its date.
+func (r *rrule) dayMatches(day time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, int(day.Month())) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 && !r.monthDayMatches(day) {
+		return false
+	}
+	if len(r.byDay) > 0 && !r.weekdayMatches(day) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 || len(r.byDay) > 0 {
+		return true
+	}
+
+	switch r.freq {
+	case weekly:
+		return day.Weekday() == r.start.Weekday()
+	case monthly:
+		return day.Day() == r.start.Day()
+	case yearly:
+		return day.Day() == r.start.Day() && (len(r.byMonth) > 0 || day.Month() == r.start.Month())
+	default:
+		return true
+	}
+}
+
+// monthDayMatches checks BYMONTHDAY, where negative days count back from the month's end
+func (r *rrule) monthDayMatches(day time.Time) bool {
+	last := daysIn(day.Year(), day.Month())
+	for _, d := range r.byMonthDay {
+		if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdayMatches checks BYDAY. A numbered weekday counts within the month, or within the
+// year for yearly rules without BYMONTH.
+func (r *rrule) weekdayMatches(day time.Time) bool {
+	for _, d := range r.byDay {
+		if d.weekday != day.Weekday() {
+			continue
+		}
+		if d.n == 0 {
+			return true
+		}
+
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		next := first.AddDate(0, 1, 0)
+		if r.freq == yearly && len(r.byMonth) == 0 {
+			first = time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+			next = first.AddDate(1, 0, 0)
+		}
+		index, last := daysBetween(first, day), daysBetween(first, next) // Day within the month or year, and its length
+		if d.n > 0 && index/7+1 == d.n {
+			return true
+		}
+		if d.n < 0 && (last-1-index)/7+1 == -d.n {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOf returns midnight of t's day in its location
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekStart returns midnight of the Monday starting t's week
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from a's date to b's, ignoring time of day and
+// daylight saving changes
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da) / (24 * time.Hour))
+}
+
+// daysIn returns the number of days in a month
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// filterInts returns the values that are in allowed, or all of them if allowed is empty
+func filterInts(values, allowed []int) []int {
+	if len(allowed) == 0 {
+		return values
+	}
+	var filtered []int
+	for _, v := range values {
+		if slices.Contains(allowed, v) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
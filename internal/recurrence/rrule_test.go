@@ -0,0 +1,91 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRRuleNext tests the next occurrence of recurrence rules
+func TestRRuleNext(t *testing.T) {
+	created := time.Date(2026, 1, 5, 9, 12, 30, 0, time.UTC) // A Monday
+	from := time.Date(2026, 1, 30, 10, 17, 0, 0, time.UTC)   // A Friday
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"FREQ=DAILY", time.Date(2026, 1, 31, 9, 12, 0, 0, time.UTC)},
+		{"FREQ=DAILY;BYHOUR=8,18;BYMINUTE=0", time.Date(2026, 1, 30, 18, 0, 0, 0, time.UTC)},
+		{"FREQ=DAILY;INTERVAL=3;BYHOUR=9;BYMINUTE=0", time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"RRULE:FREQ=WEEKLY", time.Date(2026, 2, 2, 9, 12, 0, 0, time.UTC)},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;BYHOUR=9;BYMINUTE=0", time.Date(2026, 2, 2, 9, 0, 0, 0, time.UTC)},
+		{"FREQ=HOURLY;INTERVAL=4;BYMINUTE=0", time.Date(2026, 1, 30, 13, 0, 0, 0, time.UTC)},
+		{"FREQ=MINUTELY;INTERVAL=15", time.Date(2026, 1, 30, 10, 27, 0, 0, time.UTC)},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1;BYHOUR=17;BYMINUTE=0", time.Date(2026, 1, 31, 17, 0, 0, 0, time.UTC)},
+		{"FREQ=MONTHLY;BYDAY=1MO;BYHOUR=10;BYMINUTE=0", time.Date(2026, 2, 2, 10, 0, 0, 0, time.UTC)},
+		{"FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=16;BYMINUTE=0", time.Date(2026, 1, 30, 16, 0, 0, 0, time.UTC)},
+		{"FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=1;BYHOUR=0;BYMINUTE=0", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"FREQ=DAILY;COUNT=3", time.Time{}},
+		{"FREQ=DAILY;UNTIL=20260130", time.Time{}},
+		{"FREQ=DAILY;UNTIL=20260131", time.Date(2026, 1, 31, 9, 12, 0, 0, time.UTC)},
+		{"DTSTART:20260201T070000Z RRULE:FREQ=DAILY", time.Date(2026, 2, 1, 7, 0, 0, 0, time.UTC)},
+		{"DTSTART;TZID=America/New_York:20260105T090000\nRRULE:FREQ=WEEKLY;BYDAY=FR", time.Date(2026, 1, 30, 14, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := Parse(tt.expr, created)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.expr, err)
+			continue
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// TestRRuleCount tests that a rule with a COUNT yields exactly that many occurrences
+func TestRRuleCount(t *testing.T) {
+	schedule, err := Parse("DTSTART:20260105T090000Z RRULE:FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3", time.Time{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var got []time.Time
+	for next := schedule.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !next.IsZero(); next = schedule.Next(next) {
+		got = append(got, next)
+	}
+	want := []time.Time{
+		time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 12, 9, 0, 0, 0, time.UTC),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Got %d occurrences, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Equal(want[i]) {
+			t.Errorf("Occurrence %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestParseRRuleErrors tests that malformed and unsupported rules are rejected
+func TestParseRRuleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"RRULE:INTERVAL=2",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20261231",
+		"FREQ=DAILY;BYHOUR=24",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYSETPOS=1",
+		"FREQ=WEEKLY;WKST=SU",
+		"DTSTART;TZID=Nowhere/Special:20260105T090000 RRULE:FREQ=DAILY",
+		"DTSTART:tomorrow RRULE:FREQ=DAILY",
+	} {
+		if _, err := Parse(expr, time.Now()); err == nil {
+			t.Errorf("Parse(%q) expected an error", expr)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// AccountSpec describes a configured notifier account. Credentials are never exposed.
+type AccountSpec struct {
+	Type    domain.NotificationType `json:"type"`
+	Account string                  `json:"account"`
+	Default bool                    `json:"default"`
+}
+
+// AccountsKind exposes the notifier accounts from the server configuration as read-only
+// resources with IDs of the form "<type>.<account>", so declarative tooling can reference
+// them as data sources
+type AccountsKind struct {
+	service domain.NotificationService
+	loaded  time.Time
+}
+
+// NewAccountsKind creates the accounts resource kind
+func NewAccountsKind(service domain.NotificationService) *AccountsKind {
+	return &AccountsKind{
+		service: service,
+		loaded:  time.Now().UTC(),
+	}
+}
+
+// Name implements Kind
+func (k *AccountsKind) Name() string {
+	return "accounts"
+}
+
+// List implements Kind, returning the accounts visible to the caller
+func (k *AccountsKind) List(ctx context.Context) ([]*Resource, error) {
+	notifiers, err := k.service.GetNotifiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*Resource
+	for _, info := range notifiers.Notifiers {
+		for _, account := range info.Accounts {
+			spec := AccountSpec{Type: info.Type, Account: account, Default: account == info.DefaultAccount}
+			resource, err := k.resource(spec)
+			if err != nil {
+				return nil, err
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+	return resources, nil
+}
+
+// Get implements Kind
+func (k *AccountsKind) Get(ctx context.Context, id string) (*Resource, error) {
+	resources, err := k.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		if resource.ID == id {
+			return resource, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: accounts/%s", ErrNotFound, id)
+}
+
+// Put implements Kind. Accounts are defined in the server configuration.
+func (k *AccountsKind) Put(ctx context.Context, id string, spec json.RawMessage) (*Resource, bool, error) {
+	return nil, false, fmt.Errorf("%w: accounts are defined in the server configuration", ErrReadOnly)
+}
+
+// Delete implements Kind. Accounts are defined in the server configuration.
+func (k *AccountsKind) Delete(ctx context.Context, id string) error {
+	return fmt.Errorf("%w: accounts are defined in the server configuration", ErrReadOnly)
+}
+
+// resource wraps an account spec. The version is fixed because accounts only change on restart.
+func (k *AccountsKind) resource(spec AccountSpec) (*Resource, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account: %w", err)
+	}
+
+	id := string(spec.Type)
+	if spec.Account != "" {
+		id += "." + spec.Account
+	}
+
+	return &Resource{
+		Kind:      k.Name(),
+		ID:        strings.ToLower(id),
+		Version:   1,
+		Spec:      data,
+		ReadOnly:  true,
+		CreatedAt: k.loaded,
+		UpdatedAt: k.loaded,
+	}, nil
+}
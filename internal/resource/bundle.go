@@ -0,0 +1,220 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// BundleFormatVersion is the version of the export bundle format written by Export
+const BundleFormatVersion = 1
+
+// ErrUnsupportedBundle is returned when importing a bundle in an unknown format
+var ErrUnsupportedBundle = errors.New("unsupported bundle format")
+
+// Import actions reported per resource
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionUnchanged = "unchanged"
+	ActionDeleted   = "deleted"
+	ActionFailed    = "failed"
+)
+
+// Bundle is a snapshot of runtime-managed configuration that can be imported into
+// another instance. Read-only resources are left out since they come from server config.
+type Bundle struct {
+	FormatVersion int              `json:"format_version"`
+	ExportedAt    time.Time        `json:"exported_at"`
+	Kinds         []string         `json:"kinds"` // Kinds the bundle is complete for; pruning is limited to these
+	Resources     []BundleResource `json:"resources"`
+}
+
+// BundleResource is one resource in a bundle
+type BundleResource struct {
+	Kind string          `json:"kind"`
+	ID   string          `json:"id"`
+	Spec json.RawMessage `json:"spec"`
+}
+
+// ImportOptions controls how a bundle is applied
+type ImportOptions struct {
+	DryRun bool // Report what would change without applying it
+	Prune  bool // Delete writable resources of the bundle's kinds that aren't in the bundle
+}
+
+// ImportResult is the outcome for one resource
+type ImportResult struct {
+	Kind   string `json:"kind"`
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ImportReport summarises an import
+type ImportReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Deleted   int            `json:"deleted"`
+	Failed    int            `json:"failed"`
+	Results   []ImportResult `json:"results"`
+}
+
+// record adds a result and updates the counts
+func (r *ImportReport) record(result ImportResult) {
+	switch result.Action {
+	case ActionCreated:
+		r.Created++
+	case ActionUpdated:
+		r.Updated++
+	case ActionUnchanged:
+		r.Unchanged++
+	case ActionDeleted:
+		r.Deleted++
+	case ActionFailed:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// Export returns the writable resources of the given kinds, or of every kind if none are given
+func (r *Registry) Export(ctx context.Context, kindNames ...string) (*Bundle, error) {
+	if len(kindNames) == 0 {
+		kindNames = r.Kinds()
+	}
+
+	bundle := &Bundle{
+		FormatVersion: BundleFormatVersion,
+		ExportedAt:    r.now().UTC(),
+		Kinds:         []string{},
+		Resources:     []BundleResource{},
+	}
+	for _, name := range kindNames {
+		kind, err := r.kind(name)
+		if err != nil {
+			return nil, err
+		}
+		resources, err := kind.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", name, err)
+		}
+
+		bundle.Kinds = append(bundle.Kinds, name)
+		for _, res := range resources {
+			if !res.ReadOnly {
+				bundle.Resources = append(bundle.Resources, BundleResource{Kind: name, ID: res.ID, Spec: res.Spec})
+			}
+		}
+	}
+	return bundle, nil
+}
+
+// Import applies a bundle. The whole bundle is validated before anything is written; after
+// that, each resource is applied independently and failures are reported per resource.
+func (r *Registry) Import(ctx context.Context, bundle *Bundle, opts ImportOptions) (*ImportReport, error) {
+	if bundle.FormatVersion != BundleFormatVersion {
+		return nil, fmt.Errorf("%w: version %d (expected %d)", ErrUnsupportedBundle, bundle.FormatVersion, BundleFormatVersion)
+	}
+
+	kinds := make(map[string]Kind)
+	for _, name := range bundle.Kinds {
+		kind, err := r.kind(name)
+		if err != nil {
+			return nil, err
+		}
+		kinds[name] = kind
+	}
+
+	seen := make(map[string]bool)
+	for _, res := range bundle.Resources {
+		if _, ok := kinds[res.Kind]; !ok {
+			return nil, fmt.Errorf("%w: %s/%s is not one of the bundle's kinds", ErrInvalidSpec, res.Kind, res.ID)
+		}
+		if !validID.MatchString(res.ID) {
+			return nil, fmt.Errorf("%w: invalid id %q", ErrInvalidSpec, res.ID)
+		}
+		if !json.Valid(res.Spec) {
+			return nil, fmt.Errorf("%w: %s/%s spec must be valid JSON", ErrInvalidSpec, res.Kind, res.ID)
+		}
+		key := res.Kind + "/" + res.ID
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s appears more than once", ErrInvalidSpec, key)
+		}
+		seen[key] = true
+	}
+
+	// Hold the write lock so the import doesn't interleave with individual upserts
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &ImportReport{DryRun: opts.DryRun, Results: []ImportResult{}}
+	for _, res := range bundle.Resources {
+		report.record(applyBundleResource(ctx, kinds[res.Kind], res, opts.DryRun))
+	}
+
+	if opts.Prune {
+		for _, name := range bundle.Kinds {
+			existing, err := kinds[name].List(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s: %w", name, err)
+			}
+			for _, res := range existing {
+				if res.ReadOnly || seen[name+"/"+res.ID] {
+					continue
+				}
+				result := ImportResult{Kind: name, ID: res.ID, Action: ActionDeleted}
+				if !opts.DryRun {
+					if err := kinds[name].Delete(ctx, res.ID); err != nil {
+						result.Action, result.Error = ActionFailed, err.Error()
+					}
+				}
+				report.record(result)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// applyBundleResource upserts one bundle resource, classifying the change against the current state
+func applyBundleResource(ctx context.Context, kind Kind, res BundleResource, dryRun bool) ImportResult {
+	result := ImportResult{Kind: res.Kind, ID: res.ID}
+
+	current, err := kind.Get(ctx, res.ID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		result.Action = ActionCreated
+	case err != nil:
+		result.Action, result.Error = ActionFailed, err.Error()
+		return result
+	case current.ReadOnly:
+		result.Action, result.Error = ActionFailed, ErrReadOnly.Error()
+		return result
+	case sameSpec(current.Spec, res.Spec):
+		result.Action = ActionUnchanged
+		return result
+	default:
+		result.Action = ActionUpdated
+	}
+
+	if !dryRun {
+		if _, _, err := kind.Put(ctx, res.ID, res.Spec); err != nil {
+			result.Action, result.Error = ActionFailed, err.Error()
+		}
+	}
+	return result
+}
+
+// sameSpec compares two specs ignoring whitespace
+func sameSpec(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
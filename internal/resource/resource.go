@@ -0,0 +1,252 @@
+// Package resource exposes service configuration as declarative resources so it can be
+// managed as code (e.g. by a Terraform or Pulumi provider). Every resource has a stable,
+// caller-chosen ID and is written with an idempotent upsert; a client token makes retried
+// writes safe even when the first attempt's response was lost.
+package resource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnknownKind is returned for a resource kind that isn't registered
+	ErrUnknownKind = errors.New("unknown resource kind")
+	// ErrNotFound is returned when a resource doesn't exist
+	ErrNotFound = errors.New("resource not found")
+	// ErrReadOnly is returned when writing a resource that is managed elsewhere
+	ErrReadOnly = errors.New("resource is read-only")
+	// ErrInvalidSpec is returned when a resource spec fails validation
+	ErrInvalidSpec = errors.New("invalid resource spec")
+	// ErrVersionConflict is returned when the expected version doesn't match the current one
+	ErrVersionConflict = errors.New("resource version conflict")
+	// ErrTokenReused is returned when a client token is reused for a different request
+	ErrTokenReused = errors.New("client token was used for a different request")
+)
+
+// clientTokenTTL is how long a client token is remembered
+const clientTokenTTL = 24 * time.Hour
+
+// validID matches resource IDs: lowercase letters, digits, '.', '_' and '-', starting with a letter or digit
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,127}$`)
+
+// Resource is a declarative configuration object
+type Resource struct {
+	Kind      string          `json:"kind"`
+	ID        string          `json:"id"`
+	Version   int64           `json:"version"`             // Incremented on every change
+	Spec      json.RawMessage `json:"spec"`                // Kind-specific desired state
+	ReadOnly  bool            `json:"read_only,omitempty"` // Managed by server configuration, not the API
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// Kind implements storage and validation for one type of resource
+type Kind interface {
+	// Name returns the kind's plural name used in URLs (e.g. "accounts")
+	Name() string
+	// List returns all resources of this kind, ordered by ID
+	List(ctx context.Context) ([]*Resource, error)
+	// Get returns a resource, or ErrNotFound
+	Get(ctx context.Context, id string) (*Resource, error)
+	// Put creates or replaces a resource, reporting whether it was created. An unchanged
+	// spec must not bump the version.
+	Put(ctx context.Context, id string, spec json.RawMessage) (*Resource, bool, error)
+	// Delete removes a resource, or returns ErrNotFound
+	Delete(ctx context.Context, id string) error
+}
+
+// PutRequest is an upsert of one resource
+type PutRequest struct {
+	Kind            string
+	ID              string
+	Spec            json.RawMessage
+	ClientToken     string // Optional: makes retries of this request return the original result
+	ExpectedVersion int64  // Optional: reject the write unless the current version matches (0 = any)
+}
+
+// PutResult is the outcome of an upsert
+type PutResult struct {
+	Resource *Resource
+	Created  bool
+}
+
+// Registry routes resource operations to their kinds and remembers client tokens
+type Registry struct {
+	mu     sync.Mutex
+	kinds  map[string]Kind
+	tokens map[string]*tokenEntry
+	now    func() time.Time
+}
+
+// tokenEntry records the request and result for a client token
+type tokenEntry struct {
+	request [sha256.Size]byte
+	result  PutResult
+	expires time.Time
+}
+
+// NewRegistry creates an empty resource registry
+func NewRegistry() *Registry {
+	return &Registry{
+		kinds:  make(map[string]Kind),
+		tokens: make(map[string]*tokenEntry),
+		now:    time.Now,
+	}
+}
+
+// Register adds a resource kind
+func (r *Registry) Register(kind Kind) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.kinds[kind.Name()]; exists {
+		return fmt.Errorf("resource kind %s is already registered", kind.Name())
+	}
+	r.kinds[kind.Name()] = kind
+	return nil
+}
+
+// Kinds returns the names of the registered kinds
+func (r *Registry) Kinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.kinds))
+	for name := range r.kinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// kind looks up a registered kind
+func (r *Registry) kind(name string) (Kind, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kind, ok := r.kinds[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, name)
+	}
+	return kind, nil
+}
+
+// List returns all resources of a kind
+func (r *Registry) List(ctx context.Context, kindName string) ([]*Resource, error) {
+	kind, err := r.kind(kindName)
+	if err != nil {
+		return nil, err
+	}
+	return kind.List(ctx)
+}
+
+// Get returns one resource
+func (r *Registry) Get(ctx context.Context, kindName, id string) (*Resource, error) {
+	kind, err := r.kind(kindName)
+	if err != nil {
+		return nil, err
+	}
+	return kind.Get(ctx, id)
+}
+
+// Put creates or replaces a resource. Repeating a request with the same client token
+// returns the original result without applying it again.
+func (r *Registry) Put(ctx context.Context, req PutRequest) (*PutResult, error) {
+	kind, err := r.kind(req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if !validID.MatchString(req.ID) {
+		return nil, fmt.Errorf("%w: id must be 1-128 lowercase letters, digits, '.', '_' or '-'", ErrInvalidSpec)
+	}
+	if !json.Valid(req.Spec) {
+		return nil, fmt.Errorf("%w: spec must be valid JSON", ErrInvalidSpec)
+	}
+
+	// Serialize writes so a token's replay check and its write can't interleave with a retry
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	requestHash := hashRequest(req)
+	if req.ClientToken != "" {
+		r.expireTokens()
+		if entry, ok := r.tokens[req.ClientToken]; ok {
+			if entry.request != requestHash {
+				return nil, ErrTokenReused
+			}
+			result := entry.result
+			return &result, nil
+		}
+	}
+
+	if req.ExpectedVersion > 0 {
+		current, err := kind.Get(ctx, req.ID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		if current == nil || current.Version != req.ExpectedVersion {
+			return nil, fmt.Errorf("%w: expected version %d", ErrVersionConflict, req.ExpectedVersion)
+		}
+	}
+
+	resource, created, err := kind.Put(ctx, req.ID, req.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	result := PutResult{Resource: resource, Created: created}
+	if req.ClientToken != "" {
+		r.tokens[req.ClientToken] = &tokenEntry{request: requestHash, result: result, expires: r.now().Add(clientTokenTTL)}
+	}
+	return &result, nil
+}
+
+// Delete removes a resource. If expectedVersion is set the current version must match.
+func (r *Registry) Delete(ctx context.Context, kindName, id string, expectedVersion int64) error {
+	kind, err := r.kind(kindName)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if expectedVersion > 0 {
+		current, err := kind.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if current.Version != expectedVersion {
+			return fmt.Errorf("%w: expected version %d", ErrVersionConflict, expectedVersion)
+		}
+	}
+	return kind.Delete(ctx, id)
+}
+
+// expireTokens forgets client tokens past their TTL. Callers must hold r.mu.
+func (r *Registry) expireTokens() {
+	now := r.now()
+	for token, entry := range r.tokens {
+		if now.After(entry.expires) {
+			delete(r.tokens, token)
+		}
+	}
+}
+
+// hashRequest identifies a put request independent of spec whitespace
+func hashRequest(req PutRequest) [sha256.Size]byte {
+	var spec bytes.Buffer
+	if err := json.Compact(&spec, req.Spec); err != nil {
+		spec.Write(req.Spec)
+	}
+	return sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d\x00%s", req.Kind, req.ID, req.ExpectedVersion, spec.Bytes())))
+}
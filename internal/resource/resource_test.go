@@ -0,0 +1,219 @@
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/service"
+)
+
+// memoryKind is a writable kind for exercising the registry
+type memoryKind struct {
+	resources map[string]*Resource
+	puts      int
+}
+
+func (k *memoryKind) Name() string { return "widgets" }
+
+func (k *memoryKind) List(ctx context.Context) ([]*Resource, error) {
+	var resources []*Resource
+	for _, resource := range k.resources {
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func (k *memoryKind) Get(ctx context.Context, id string) (*Resource, error) {
+	if resource, ok := k.resources[id]; ok {
+		return resource, nil
+	}
+	return nil, fmt.Errorf("%w: widgets/%s", ErrNotFound, id)
+}
+
+func (k *memoryKind) Put(ctx context.Context, id string, spec json.RawMessage) (*Resource, bool, error) {
+	k.puts++
+	current, exists := k.resources[id]
+	if exists && string(current.Spec) == string(spec) {
+		return current, false, nil
+	}
+	resource := &Resource{Kind: k.Name(), ID: id, Version: 1, Spec: spec}
+	if exists {
+		resource.Version = current.Version + 1
+	}
+	k.resources[id] = resource
+	return resource, !exists, nil
+}
+
+func (k *memoryKind) Delete(ctx context.Context, id string) error {
+	if _, ok := k.resources[id]; !ok {
+		return fmt.Errorf("%w: widgets/%s", ErrNotFound, id)
+	}
+	delete(k.resources, id)
+	return nil
+}
+
+// TestRegistryPut tests client token replay and reuse, version preconditions and ID validation
+func TestRegistryPut(t *testing.T) {
+	ctx := context.Background()
+	kind := &memoryKind{resources: make(map[string]*Resource)}
+	registry := NewRegistry()
+	if err := registry.Register(kind); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register(kind); err == nil {
+		t.Error("Expected error registering a kind twice")
+	}
+
+	req := PutRequest{Kind: "widgets", ID: "blue", Spec: json.RawMessage(`{"size": 1}`), ClientToken: "token-1"}
+	first, err := registry.Put(ctx, req)
+	if err != nil || !first.Created || first.Resource.Version != 1 {
+		t.Fatalf("First put = %+v, %v", first, err)
+	}
+
+	// A retry with the same token replays the original result, even with different spacing
+	req.Spec = json.RawMessage(`{"size":1}`)
+	retry, err := registry.Put(ctx, req)
+	if err != nil || !retry.Created || kind.puts != 1 {
+		t.Fatalf("Retry = %+v, %v (puts=%d)", retry, err, kind.puts)
+	}
+
+	req.Spec = json.RawMessage(`{"size":2}`)
+	if _, err := registry.Put(ctx, req); !errors.Is(err, ErrTokenReused) {
+		t.Errorf("Expected ErrTokenReused, got %v", err)
+	}
+
+	// Expired tokens are forgotten
+	registry.now = func() time.Time { return time.Now().Add(clientTokenTTL + time.Minute) }
+	if result, err := registry.Put(ctx, req); err != nil || result.Created || result.Resource.Version != 2 {
+		t.Errorf("Put after token expiry = %+v, %v", result, err)
+	}
+	registry.now = time.Now
+
+	if _, err := registry.Put(ctx, PutRequest{Kind: "widgets", ID: "blue", Spec: json.RawMessage(`{"size":3}`), ExpectedVersion: 1}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict, got %v", err)
+	}
+	if result, err := registry.Put(ctx, PutRequest{Kind: "widgets", ID: "blue", Spec: json.RawMessage(`{"size":3}`), ExpectedVersion: 2}); err != nil || result.Resource.Version != 3 {
+		t.Errorf("Put with matching version = %+v, %v", result, err)
+	}
+
+	if _, err := registry.Put(ctx, PutRequest{Kind: "widgets", ID: "Not Valid", Spec: json.RawMessage(`{}`)}); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("Expected ErrInvalidSpec for bad ID, got %v", err)
+	}
+	if _, err := registry.Put(ctx, PutRequest{Kind: "gadgets", ID: "blue", Spec: json.RawMessage(`{}`)}); !errors.Is(err, ErrUnknownKind) {
+		t.Errorf("Expected ErrUnknownKind, got %v", err)
+	}
+
+	if err := registry.Delete(ctx, "widgets", "blue", 1); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict on delete, got %v", err)
+	}
+	if err := registry.Delete(ctx, "widgets", "blue", 3); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
+
+// TestAccountsKind tests that configured accounts are listed read-only without credentials
+func TestAccountsKind(t *testing.T) {
+	factory := notifier.NewFactory()
+	if err := factory.RegisterNotifier(domain.TypeStdout, "console", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	svc := service.NewNotificationService(factory, nil, 1, nil, nil, logging.New(logging.ErrorLevel, os.Stderr))
+
+	registry := NewRegistry()
+	if err := registry.Register(NewAccountsKind(svc)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	ctx := context.Background()
+	resources, err := registry.List(ctx, "accounts")
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("List() = %+v, %v", resources, err)
+	}
+	if resources[0].ID != "stdout.console" || !resources[0].ReadOnly {
+		t.Errorf("Unexpected account resource: %+v", resources[0])
+	}
+
+	if _, err := registry.Get(ctx, "accounts", "email.missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := registry.Put(ctx, PutRequest{Kind: "accounts", ID: "stdout.console", Spec: json.RawMessage(`{}`)}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
+
+// TestRegistryExportImport tests promoting a bundle between registries with dry run and prune
+func TestRegistryExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	source := NewRegistry()
+	source.Register(&memoryKind{resources: map[string]*Resource{
+		"blue":  {Kind: "widgets", ID: "blue", Version: 1, Spec: json.RawMessage(`{"size":1}`)},
+		"green": {Kind: "widgets", ID: "green", Version: 4, Spec: json.RawMessage(`{"size":2}`)},
+		"fixed": {Kind: "widgets", ID: "fixed", Version: 1, Spec: json.RawMessage(`{}`), ReadOnly: true},
+	}})
+
+	bundle, err := source.Export(ctx)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if bundle.FormatVersion != BundleFormatVersion || len(bundle.Resources) != 2 {
+		t.Fatalf("Unexpected bundle: %+v", bundle)
+	}
+
+	target := &memoryKind{resources: map[string]*Resource{
+		"blue": {Kind: "widgets", ID: "blue", Version: 7, Spec: json.RawMessage(`{ "size": 1 }`)},
+		"red":  {Kind: "widgets", ID: "red", Version: 1, Spec: json.RawMessage(`{"size":3}`)},
+	}}
+	registry := NewRegistry()
+	registry.Register(target)
+
+	// Round-trip through JSON as the REST API would
+	data, _ := json.Marshal(bundle)
+	var imported Bundle
+	if err := json.Unmarshal(data, &imported); err != nil {
+		t.Fatalf("Failed to decode bundle: %v", err)
+	}
+
+	report, err := registry.Import(ctx, &imported, ImportOptions{DryRun: true, Prune: true})
+	if err != nil {
+		t.Fatalf("Import(dry run) error = %v", err)
+	}
+	if report.Created != 1 || report.Unchanged != 1 || report.Deleted != 1 || target.puts != 0 || len(target.resources) != 2 {
+		t.Errorf("Dry run applied changes or miscounted: %+v (puts=%d)", report, target.puts)
+	}
+
+	report, err = registry.Import(ctx, &imported, ImportOptions{Prune: true})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if report.Created != 1 || report.Unchanged != 1 || report.Deleted != 1 || report.Failed != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if _, ok := target.resources["green"]; !ok {
+		t.Error("Expected green to be created")
+	}
+	if _, ok := target.resources["red"]; ok {
+		t.Error("Expected red to be pruned")
+	}
+	if target.resources["blue"].Version != 7 {
+		t.Error("Unchanged resource should keep its version")
+	}
+
+	imported.FormatVersion = 99
+	if _, err := registry.Import(ctx, &imported, ImportOptions{}); !errors.Is(err, ErrUnsupportedBundle) {
+		t.Errorf("Expected ErrUnsupportedBundle, got %v", err)
+	}
+	imported.FormatVersion = BundleFormatVersion
+	imported.Resources = append(imported.Resources, imported.Resources[0])
+	if _, err := registry.Import(ctx, &imported, ImportOptions{}); !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("Expected ErrInvalidSpec for duplicate resource, got %v", err)
+	}
+}
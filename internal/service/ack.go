@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/preview"
+)
+
+// ackActionMetadata is the metadata key that asks for an acknowledge button. It's true for the
+// configured label, or a string to use as the label.
+const ackActionMetadata = "ack_action"
+
+// ackActions adds acknowledge buttons that post to a signed URL
+type ackActions struct {
+	signer  *preview.Signer
+	baseURL string
+	label   string
+}
+
+// WithActionsConfig adds an acknowledge button to ntfy notifications that ask for one. The
+// button posts to a signed URL under the preview base URL that works for as long as the
+// notification is retained.
+func (s *NotificationService) WithActionsConfig(cfg config.PreviewConfig) error {
+	if !cfg.Enabled || !cfg.Actions.Enabled {
+		s.ackActions = nil
+		return nil
+	}
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("acknowledge buttons require a preview base_url")
+	}
+
+	label := cfg.Actions.Label
+	if label == "" {
+		label = "Acknowledge"
+	}
+	s.ackActions = &ackActions{
+		signer:  preview.NewSigner([]byte(cfg.Secret)),
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/"),
+		label:   label,
+	}
+	return nil
+}
+
+// addAckActions adds an acknowledge button to each ntfy notification that asks for one. The
+// button is an ntfy http action, alongside any actions the caller gave in metadata "actions".
+func (s *NotificationService) addAckActions(notifications ...*domain.Notification) {
+	if s.ackActions == nil {
+		return
+	}
+
+	for _, notification := range notifications {
+		if notification.Type != domain.TypeNtfy || notification.ID == "" {
+			continue
+		}
+
+		label := s.ackActions.label
+		switch value := notification.Metadata[ackActionMetadata].(type) {
+		case bool:
+			if !value {
+				continue
+			}
+		case string:
+			if value == "" {
+				continue
+			}
+			label = value
+		default:
+			continue
+		}
+
+		token := s.ackActions.signer.Sign(preview.PurposeAcknowledge, notification.ID, s.linkExpiry(notification))
+		actions, _ := notification.Metadata["actions"].([]interface{})
+		notification.Metadata["actions"] = append(actions, map[string]interface{}{
+			"action": "http",
+			"label":  label,
+			"url":    s.ackActions.baseURL + "/actions/" + token,
+			"clear":  true,
+		})
+		delete(notification.Metadata, ackActionMetadata)
+	}
+}
+
+// AcknowledgeNotification acknowledges a notification and cancels the escalations still
+// waiting to be sent for it
+func (s *NotificationService) AcknowledgeNotification(ctx context.Context, id, by string) (*domain.Acknowledgement, error) {
+	s.mu.Lock()
+	ack, cancelled, err := s.acknowledge(ctx, id, by)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, escalation := range cancelled {
+		ack.CancelledEscalations = append(ack.CancelledEscalations, escalation.ID)
+		s.publishStatus(escalation)
+	}
+	s.logger.Infof("Notification acknowledged - id=%s, by=%s, cancelled_escalations=%d", id, ack.AcknowledgedBy, len(cancelled))
+	return ack, nil
+}
+
+// acknowledge records the acknowledgement and fails the notification's waiting escalations,
+// returning those it cancelled (must be called with lock held)
+func (s *NotificationService) acknowledge(ctx context.Context, id, by string) (*domain.Acknowledgement, []*domain.Notification, error) {
+	notification, err := s.getNotification(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if notification.AcknowledgedAt == nil {
+		now := time.Now()
+		notification.AcknowledgedAt = &now
+		notification.AcknowledgedBy = by
+		if err := s.store.Update(ctx, notification); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	waiting, err := s.store.List(ctx, &domain.NotificationFilter{
+		Statuses: []domain.NotificationStatus{domain.StatusPending, domain.StatusScheduled, domain.StatusQueued, domain.StatusRetrying, domain.StatusHeld, domain.StatusPaused},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cancelled []*domain.Notification
+	for _, escalation := range waiting {
+		if escalationOf, _ := escalation.Metadata[domain.EscalationMetadata].(string); escalationOf != id {
+			continue
+		}
+		escalation.Status = domain.StatusFailed
+		escalation.LastError = fmt.Sprintf("cancelled: %s was acknowledged", id)
+		if err := s.store.Update(ctx, escalation); err != nil {
+			return nil, nil, err
+		}
+		cancelled = append(cancelled, escalation)
+	}
+
+	return &domain.Acknowledgement{
+		NotificationID: id,
+		AcknowledgedBy: notification.AcknowledgedBy,
+		AcknowledgedAt: *notification.AcknowledgedAt,
+	}, cancelled, nil
+}
+
+// cancelledBeforeDispatch reports whether a dequeued notification was cancelled after it was
+// queued. Notifications that fail in a worker aren't requeued, so a failed one was cancelled.
+func (s *NotificationService) cancelledBeforeDispatch(notification *domain.Notification) bool {
+	stored, err := s.store.Get(context.Background(), notification.ID)
+	return err == nil && stored.Status == domain.StatusFailed
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestAddAckActions tests adding acknowledge buttons to ntfy notifications that ask for one
+func TestAddAckActions(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithActionsConfig(config.PreviewConfig{
+		Enabled: true,
+		Secret:  "0123456789abcdef0123456789abcdef",
+		BaseURL: "https://notifier.example.com/",
+		Actions: config.ActionsConfig{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("WithActionsConfig() error = %v", err)
+	}
+
+	existing := map[string]interface{}{"action": "view", "label": "Dashboard", "url": "https://grafana.example.com"}
+	alert := &domain.Notification{ID: "n1", Type: domain.TypeNtfy, Metadata: map[string]interface{}{
+		"ack_action": "Got it",
+		"actions":    []interface{}{existing},
+	}}
+	plain := &domain.Notification{ID: "n2", Type: domain.TypeNtfy, Metadata: map[string]interface{}{"ack_action": false}}
+	email := &domain.Notification{ID: "n3", Type: domain.TypeEmail, Metadata: map[string]interface{}{"ack_action": true}}
+	svc.addAckActions(alert, plain, email)
+
+	actions, _ := alert.Metadata["actions"].([]interface{})
+	if len(actions) != 2 {
+		t.Fatalf("Expected the button after the existing action, got %v", alert.Metadata["actions"])
+	}
+	button := actions[1].(map[string]interface{})
+	if button["action"] != "http" || button["label"] != "Got it" || !strings.HasPrefix(button["url"].(string), "https://notifier.example.com/actions/") {
+		t.Errorf("Unexpected button: %v", button)
+	}
+	if _, ok := alert.Metadata["ack_action"]; ok {
+		t.Error("Expected ack_action to be consumed")
+	}
+	if plain.Metadata["actions"] != nil || email.Metadata["actions"] != nil {
+		t.Error("Expected buttons only on ntfy notifications that ask for one")
+	}
+}
+
+// TestAcknowledgeNotification tests acknowledging a notification cancels its pending escalations
+func TestAcknowledgeNotification(t *testing.T) {
+	svc := createTestService(t)
+
+	alert := &domain.Notification{ID: "alert", Type: domain.TypeNtfy, Status: domain.StatusSent}
+	pending := &domain.Notification{ID: "page", Type: domain.TypeNtfy, Status: domain.StatusQueued,
+		Metadata: map[string]interface{}{domain.EscalationMetadata: "alert"}}
+	sent := &domain.Notification{ID: "email", Type: domain.TypeEmail, Status: domain.StatusSent,
+		Metadata: map[string]interface{}{domain.EscalationMetadata: "alert"}}
+	other := &domain.Notification{ID: "other", Type: domain.TypeNtfy, Status: domain.StatusQueued,
+		Metadata: map[string]interface{}{domain.EscalationMetadata: "different"}}
+	for _, n := range []*domain.Notification{alert, pending, sent, other} {
+		svc.storeNotification(n)
+	}
+
+	ack, err := svc.AcknowledgeNotification(context.Background(), "alert", "alice")
+	if err != nil {
+		t.Fatalf("AcknowledgeNotification() error = %v", err)
+	}
+	if ack.AcknowledgedBy != "alice" || len(ack.CancelledEscalations) != 1 || ack.CancelledEscalations[0] != "page" {
+		t.Errorf("Unexpected acknowledgement: %+v", ack)
+	}
+	status := func(id string) domain.NotificationStatus {
+		n, _ := svc.GetNotification(context.Background(), id)
+		return n.Status
+	}
+	if status("page") != domain.StatusFailed || status("email") != domain.StatusSent || status("other") != domain.StatusQueued {
+		t.Errorf("Unexpected statuses: page=%s, email=%s, other=%s", status("page"), status("email"), status("other"))
+	}
+	if !svc.cancelledBeforeDispatch(pending) || svc.cancelledBeforeDispatch(other) {
+		t.Error("Expected only the cancelled escalation to be skipped by workers")
+	}
+
+	// The first acknowledgement stands
+	again, err := svc.AcknowledgeNotification(context.Background(), "alert", "bob")
+	if err != nil || again.AcknowledgedBy != "alice" || !again.AcknowledgedAt.Equal(ack.AcknowledgedAt) {
+		t.Errorf("Expected the first acknowledgement to be kept, got %+v, %v", again, err)
+	}
+
+	if _, err := svc.AcknowledgeNotification(context.Background(), "missing", "alice"); err == nil {
+		t.Error("Expected an error acknowledging an unknown notification")
+	}
+}
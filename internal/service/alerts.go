@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// operationalAlertSource tags notifications the service sends about itself,
+// so they are excluded from delivery tracking
+const operationalAlertSource = "notifier-operational-alert"
+
+// isOperationalAlert reports whether a notification was raised by the service itself,
+// as an alert or a canary
+func isOperationalAlert(notification *domain.Notification) bool {
+	source, _ := notification.Metadata["source"].(string)
+	return source == operationalAlertSource || source == canarySource
+}
+
+// sendOperationalAlert queues a self-notification to the configured admin channel
+func (s *NotificationService) sendOperationalAlert(ctx context.Context, target config.AlertTargetConfig, subject, body string, metadata map[string]interface{}) error {
+	if !target.Enabled() {
+		return nil
+	}
+
+	if _, err := s.Send(ctx, newOperationalAlert(target, subject, body, metadata)); err != nil {
+		return fmt.Errorf("failed to send operational alert: %w", err)
+	}
+
+	return nil
+}
+
+// newOperationalAlert builds a self-notification to an admin channel
+func newOperationalAlert(target config.AlertTargetConfig, subject, body string, metadata map[string]interface{}) *domain.Notification {
+	alertMetadata := map[string]interface{}{"source": operationalAlertSource}
+	for key, value := range metadata {
+		alertMetadata[key] = value
+	}
+
+	return &domain.Notification{
+		ID:         uuid.New().String(),
+		Type:       domain.NotificationType(target.Type),
+		Account:    target.Account,
+		Priority:   domain.PriorityHigh,
+		Subject:    subject,
+		Body:       body,
+		Recipients: target.Recipients,
+		Metadata:   alertMetadata,
+		CreatedAt:  time.Now(),
+		MaxRetries: 3,
+	}
+}
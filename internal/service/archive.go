@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/archive"
+)
+
+// WithArchiveConfig enables archiving completed notifications to object storage
+func (s *NotificationService) WithArchiveConfig(cfg archive.Config) error {
+	if !cfg.Enabled {
+		s.archiver = nil
+		return nil
+	}
+
+	archiver, err := archive.New(cfg)
+	if err != nil {
+		return err
+	}
+	s.archiver = archiver
+	return nil
+}
+
+// archiveLoop periodically archives completed notifications
+func (s *NotificationService) archiveLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := s.archiver.NewTicker()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.archiveNotifications(ctx)
+		}
+	}
+}
+
+// archiveNotifications runs the archiver once and logs what it moved
+func (s *NotificationService) archiveNotifications(ctx context.Context) {
+	result, err := s.archiver.Archive(ctx, s.store)
+	if result.Notifications > 0 {
+		s.logger.Infof("Archived notifications - count=%d, bucket=%s, objects=%s",
+			result.Notifications, s.archiver.Bucket(), strings.Join(result.Objects, ","))
+	}
+	if err != nil {
+		s.logger.Errorf("Archive failed - error=%v", err)
+	}
+}
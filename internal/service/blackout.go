@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// blackoutWindows holds the maintenance windows and the queue messages set aside by them
+type blackoutWindows struct {
+	mu      sync.Mutex
+	windows map[string]*domain.BlackoutWindow
+	held    map[string]heldMessage // Queue message ID -> the notification held back
+	timer   *time.Timer            // Fires when the next held message may be released
+	timerAt time.Time
+	path    string // File windows created through the API are saved to ("" keeps them in memory)
+}
+
+// heldMessage is a notification set aside by a blackout window
+type heldMessage struct {
+	notification *domain.Notification
+	account      string
+}
+
+// newBlackoutWindows creates an empty set of windows
+func newBlackoutWindows() *blackoutWindows {
+	return &blackoutWindows{
+		windows: make(map[string]*domain.BlackoutWindow),
+		held:    make(map[string]heldMessage),
+	}
+}
+
+// blackoutCovers reports whether a window applies to a type and account
+func blackoutCovers(window *domain.BlackoutWindow, notifType domain.NotificationType, account string) bool {
+	return (window.Type == "" || window.Type == notifType) && (window.Account == "" || window.Account == account)
+}
+
+// releaseAtLocked returns when the windows covering a type and account at t end, following on
+// through windows that overlap or adjoin. Must be called with mu held.
+func (b *blackoutWindows) releaseAtLocked(notifType domain.NotificationType, account string, t time.Time) (time.Time, *domain.BlackoutWindow, bool) {
+	var first *domain.BlackoutWindow
+	releaseAt := t
+	for extended := true; extended; {
+		extended = false
+		for _, window := range b.windows {
+			if !blackoutCovers(window, notifType, account) || window.Start.After(releaseAt) || !window.End.After(releaseAt) {
+				continue
+			}
+			if first == nil {
+				first = window
+			}
+			releaseAt, extended = window.End, true
+		}
+	}
+	return releaseAt, first, first != nil
+}
+
+// armLocked makes sure the release timer fires by at. Must be called with mu held.
+func (b *blackoutWindows) armLocked(at time.Time, release func()) {
+	if b.timer != nil && !b.timerAt.After(at) {
+		return // Due to fire first; it re-arms for anything still held
+	}
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer, b.timerAt = time.AfterFunc(time.Until(at), release), at
+}
+
+// load reads the saved windows, if there are any
+func (b *blackoutWindows) load() error {
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing created yet
+		}
+		return fmt.Errorf("failed to read blackout windows: %w", err)
+	}
+
+	var windows []*domain.BlackoutWindow
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return fmt.Errorf("failed to unmarshal blackout windows: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, window := range windows {
+		b.windows[window.ID] = window
+	}
+	return nil
+}
+
+// saveLocked writes the windows created through the API that haven't ended to their file, if
+// they're persisted. Must be called with mu held.
+func (b *blackoutWindows) saveLocked() error {
+	if b.path == "" {
+		return nil
+	}
+
+	now := time.Now()
+	windows := make([]*domain.BlackoutWindow, 0, len(b.windows))
+	for _, window := range b.windows {
+		if !window.Configured && window.End.After(now) {
+			windows = append(windows, window)
+		}
+	}
+	data, err := json.Marshal(windows)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blackout windows: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old one, so a crash never leaves a
+	// partially written file
+	tmpPath := b.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write blackout windows: %w", err)
+	}
+	if err := os.Rename(tmpPath, b.path); err != nil {
+		return fmt.Errorf("failed to write blackout windows: %w", err)
+	}
+	return nil
+}
+
+// WithBlackoutsConfig adds the configured blackout windows and saves windows created through
+// the API to a file so they survive restarts, restoring any already saved there
+func (s *NotificationService) WithBlackoutsConfig(cfg config.BlackoutsConfig) error {
+	s.blackouts.mu.Lock()
+	for i, windowCfg := range cfg.Windows {
+		start, err := time.Parse(time.RFC3339, windowCfg.Start)
+		if err != nil {
+			s.blackouts.mu.Unlock()
+			return fmt.Errorf("invalid blackout start %q: %w", windowCfg.Start, err)
+		}
+		end, err := time.Parse(time.RFC3339, windowCfg.End)
+		if err != nil {
+			s.blackouts.mu.Unlock()
+			return fmt.Errorf("invalid blackout end %q: %w", windowCfg.End, err)
+		}
+
+		id := fmt.Sprintf("config-%d", i+1)
+		s.blackouts.windows[id] = &domain.BlackoutWindow{
+			ID:         id,
+			Type:       domain.NotificationType(windowCfg.Type),
+			Account:    windowCfg.Account,
+			Start:      start,
+			End:        end,
+			Reason:     windowCfg.Reason,
+			Configured: true,
+		}
+	}
+	s.blackouts.path = cfg.PersistPath
+	s.blackouts.mu.Unlock()
+
+	if cfg.PersistPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.PersistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create blackout windows directory: %w", err)
+	}
+	return s.blackouts.load()
+}
+
+// CreateBlackoutWindow adds a maintenance window. A window without a start starts now.
+func (s *NotificationService) CreateBlackoutWindow(ctx context.Context, window domain.BlackoutWindow) (*domain.BlackoutWindow, error) {
+	if window.Account != "" && window.Type == "" {
+		return nil, fmt.Errorf("an account blackout window requires a type")
+	}
+	if window.Type != "" && !s.supportsType(window.Type) {
+		return nil, fmt.Errorf("unsupported notification type: %s", window.Type)
+	}
+	now := time.Now()
+	if window.Start.IsZero() {
+		window.Start = now
+	}
+	if !window.End.After(window.Start) || !window.End.After(now) {
+		return nil, fmt.Errorf("a blackout window must end after it starts and in the future")
+	}
+
+	window.ID = uuid.New().String()
+	window.Configured = false
+
+	// The window takes effect even if it can't be saved
+	s.blackouts.mu.Lock()
+	for id, existing := range s.blackouts.windows {
+		if !existing.End.After(now) {
+			delete(s.blackouts.windows, id) // Ended
+		}
+	}
+	s.blackouts.windows[window.ID] = &window
+	err := s.blackouts.saveLocked()
+	s.blackouts.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Blackout window will not survive a restart - error=%v", err)
+	}
+
+	s.logger.Warnf("Blackout window created - id=%s, type=%s, account=%s, start=%s, end=%s, by=%s, reason=%s",
+		window.ID, scopeLabel(string(window.Type)), scopeLabel(window.Account),
+		window.Start.UTC().Format(time.RFC3339), window.End.UTC().Format(time.RFC3339), window.CreatedBy, window.Reason)
+
+	info := window
+	return &info, nil
+}
+
+// ListBlackoutWindows returns the windows that haven't ended, soonest first
+func (s *NotificationService) ListBlackoutWindows(ctx context.Context) ([]*domain.BlackoutWindow, error) {
+	now := time.Now()
+	s.blackouts.mu.Lock()
+	windows := make([]*domain.BlackoutWindow, 0, len(s.blackouts.windows))
+	for _, window := range s.blackouts.windows {
+		if window.End.After(now) {
+			info := *window
+			windows = append(windows, &info)
+		}
+	}
+	s.blackouts.mu.Unlock()
+
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].Start.Equal(windows[j].Start) {
+			return windows[i].Start.Before(windows[j].Start)
+		}
+		return windows[i].ID < windows[j].ID
+	})
+	return windows, nil
+}
+
+// DeleteBlackoutWindow removes a window created through the API, ending it early. Notifications
+// it was holding that no other window covers are requeued.
+func (s *NotificationService) DeleteBlackoutWindow(ctx context.Context, id string) error {
+	s.blackouts.mu.Lock()
+	window, ok := s.blackouts.windows[id]
+	if !ok {
+		s.blackouts.mu.Unlock()
+		return domain.ErrBlackoutNotFound
+	}
+	if window.Configured {
+		s.blackouts.mu.Unlock()
+		return domain.ErrBlackoutConfigured
+	}
+	delete(s.blackouts.windows, id)
+	err := s.blackouts.saveLocked()
+	s.blackouts.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Blackout window deletion will not survive a restart - error=%v", err)
+	}
+
+	s.logger.Infof("Blackout window deleted - id=%s, type=%s, account=%s",
+		id, scopeLabel(string(window.Type)), scopeLabel(window.Account))
+	s.releaseBlackoutHeld()
+	return nil
+}
+
+// holdForBlackout sets a dequeued message aside, in flight in the queue, if a blackout window
+// covers its account. It's requeued when the window ends.
+func (s *NotificationService) holdForBlackout(msg *domain.QueueMessage, account string) bool {
+	notification := msg.Notification
+
+	// The status is stored before the message is set aside, with the lock held, since the
+	// window may end and the message be requeued at any moment after
+	s.blackouts.mu.Lock()
+	defer s.blackouts.mu.Unlock()
+	releaseAt, window, ok := s.blackouts.releaseAtLocked(notification.Type, account, time.Now())
+	if !ok {
+		return false
+	}
+
+	s.logger.Debugf("Blackout window, holding notification - id=%s, type=%s, account=%s, window=%s, until=%s",
+		notification.ID, notification.Type, account, window.ID, releaseAt.UTC().Format(time.RFC3339))
+	notification.Status = domain.StatusScheduled
+	notification.NextAttemptAt = &releaseAt
+	s.updateNotification(notification)
+	s.blackouts.held[msg.ID] = heldMessage{notification: notification, account: account}
+	s.blackouts.armLocked(releaseAt, s.releaseBlackoutHeld)
+	return true
+}
+
+// releaseBlackoutHeld requeues the held messages no blackout window covers any more, and arms
+// the timer for when the rest are due out
+func (s *NotificationService) releaseBlackoutHeld() {
+	now := time.Now()
+	var released []string
+	var notifications []*domain.Notification
+
+	s.blackouts.mu.Lock()
+	s.blackouts.timer = nil
+	var next time.Time
+	for messageID, held := range s.blackouts.held {
+		releaseAt, _, ok := s.blackouts.releaseAtLocked(held.notification.Type, held.account, now)
+		if ok {
+			if next.IsZero() || releaseAt.Before(next) {
+				next = releaseAt
+			}
+			continue
+		}
+		delete(s.blackouts.held, messageID)
+		released = append(released, messageID)
+		notifications = append(notifications, held.notification)
+	}
+	if !next.IsZero() {
+		s.blackouts.armLocked(next, s.releaseBlackoutHeld)
+	}
+	s.blackouts.mu.Unlock()
+
+	for _, notification := range notifications {
+		notification.Status = domain.StatusQueued
+		if notification.RetryCount > 0 {
+			notification.Status = domain.StatusRetrying
+		}
+		notification.NextAttemptAt = nil
+		s.updateNotification(notification)
+	}
+	for _, messageID := range released {
+		if err := s.queue.Nack(context.Background(), messageID, true); err != nil {
+			s.logger.Debugf("Failed to requeue message held for a blackout window - message_id=%s, error=%v", messageID, err)
+		}
+	}
+	if len(released) > 0 {
+		s.logger.Infof("Blackout window over, requeued held notifications - count=%d", len(released))
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestBlackoutWindowHoldsUntilEnd tests that a notification due during a blackout window is set
+// aside without using a retry and sent once the window ends
+func TestBlackoutWindowHoldsUntilEnd(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	end := time.Now().Add(500 * time.Millisecond)
+	if _, err := svc.CreateBlackoutWindow(ctx, domain.BlackoutWindow{Type: domain.TypeStdout, End: end, Reason: "maintenance"}); err != nil {
+		t.Fatalf("CreateBlackoutWindow() error = %v", err)
+	}
+
+	notification := &domain.Notification{ID: "n-1", Type: domain.TypeStdout, Body: "hi", Recipients: []string{"console"}, MaxRetries: 3, Status: domain.StatusQueued}
+	svc.processNotification(ctx, &domain.QueueMessage{ID: "msg-1", Notification: notification})
+	if notification.Status != domain.StatusScheduled || notification.RetryCount != 0 {
+		t.Errorf("Expected the notification to be held, got status %s after %d retries", notification.Status, notification.RetryCount)
+	}
+	if notification.NextAttemptAt == nil || !notification.NextAttemptAt.Equal(end) {
+		t.Errorf("Expected the next attempt at the end of the window %v, got %v", end, notification.NextAttemptAt)
+	}
+
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	result, err := svc.Send(ctx, &domain.Notification{ID: "n-2", Type: domain.TypeStdout, Body: "later", Recipients: []string{"console"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	waitForStatus(t, svc, result.NotificationID, domain.StatusSent)
+	if time.Now().Before(end) {
+		t.Error("Notification was sent during the blackout window")
+	}
+}
+
+// TestBlackoutWindowDeleteReleases tests that deleting a window requeues the notifications it
+// was holding, unless another window still covers them
+func TestBlackoutWindowDeleteReleases(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	window, err := svc.CreateBlackoutWindow(ctx, domain.BlackoutWindow{End: time.Now().Add(time.Hour), CreatedBy: "ops"})
+	if err != nil {
+		t.Fatalf("CreateBlackoutWindow() error = %v", err)
+	}
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	result, err := svc.Send(ctx, &domain.Notification{ID: "n-held", Type: domain.TypeStdout, Body: "held", Recipients: []string{"console"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	waitForStatus(t, svc, result.NotificationID, domain.StatusScheduled)
+
+	if err := svc.DeleteBlackoutWindow(ctx, window.ID); err != nil {
+		t.Fatalf("DeleteBlackoutWindow() error = %v", err)
+	}
+	waitForStatus(t, svc, result.NotificationID, domain.StatusSent)
+	if n, _ := svc.GetNotification(ctx, result.NotificationID); n.RetryCount != 0 {
+		t.Errorf("Expected no retries to be used, got %d", n.RetryCount)
+	}
+
+	if err := svc.DeleteBlackoutWindow(ctx, window.ID); !errors.Is(err, domain.ErrBlackoutNotFound) {
+		t.Errorf("DeleteBlackoutWindow() error = %v, want ErrBlackoutNotFound", err)
+	}
+}
+
+// TestBlackoutWindowScope tests that a window only holds the type and account it covers, and
+// that windows overlapping or following on hold until the last ends
+func TestBlackoutWindowScope(t *testing.T) {
+	now := time.Now()
+	windows := newBlackoutWindows()
+	windows.windows["a"] = &domain.BlackoutWindow{ID: "a", Type: domain.TypeEmail, Account: "smtp", Start: now.Add(-time.Hour), End: now.Add(time.Hour)}
+	windows.windows["b"] = &domain.BlackoutWindow{ID: "b", Type: domain.TypeEmail, Start: now.Add(time.Hour), End: now.Add(2 * time.Hour)}
+	windows.windows["c"] = &domain.BlackoutWindow{ID: "c", Start: now.Add(3 * time.Hour), End: now.Add(4 * time.Hour)}
+
+	tests := []struct {
+		name      string
+		notifType domain.NotificationType
+		account   string
+		want      time.Time
+		held      bool
+	}{
+		{"covered account", domain.TypeEmail, "smtp", now.Add(2 * time.Hour), true},
+		{"other account", domain.TypeEmail, "postmark", time.Time{}, false},
+		{"other type", domain.TypeSlack, "smtp", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, held := windows.releaseAtLocked(tt.notifType, tt.account, now)
+			if held != tt.held || (held && !got.Equal(tt.want)) {
+				t.Errorf("releaseAtLocked() = %v, %v, want %v, %v", got, held, tt.want, tt.held)
+			}
+		})
+	}
+}
+
+// TestBlackoutWindowsConfigAndPersist tests that configured windows can't be deleted, and that
+// windows created through the API are restored after a restart
+func TestBlackoutWindowsConfigAndPersist(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.BlackoutsConfig{
+		Windows:     []config.BlackoutWindowConfig{{Type: "stdout", Start: "2026-01-01T00:00:00Z", End: "2099-01-01T00:00:00Z", Reason: "frozen"}},
+		PersistPath: filepath.Join(t.TempDir(), "blackouts.json"),
+	}
+
+	svc := createTestService(t)
+	if err := svc.WithBlackoutsConfig(cfg); err != nil {
+		t.Fatalf("WithBlackoutsConfig() error = %v", err)
+	}
+	created, err := svc.CreateBlackoutWindow(ctx, domain.BlackoutWindow{Type: domain.TypeStdout, End: time.Now().Add(time.Hour), Reason: "deploy"})
+	if err != nil {
+		t.Fatalf("CreateBlackoutWindow() error = %v", err)
+	}
+	if err := svc.DeleteBlackoutWindow(ctx, "config-1"); !errors.Is(err, domain.ErrBlackoutConfigured) {
+		t.Errorf("DeleteBlackoutWindow() error = %v, want ErrBlackoutConfigured", err)
+	}
+
+	restarted := createTestService(t)
+	if err := restarted.WithBlackoutsConfig(cfg); err != nil {
+		t.Fatalf("WithBlackoutsConfig() error = %v", err)
+	}
+	windows, _ := restarted.ListBlackoutWindows(ctx)
+	if len(windows) != 2 || windows[0].ID != "config-1" || !windows[0].Configured || windows[1].ID != created.ID || windows[1].Reason != "deploy" {
+		t.Errorf("Unexpected restored windows: %+v", windows)
+	}
+}
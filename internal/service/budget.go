@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// budgetWildcard is the rule origin that applies to origins without their own rules
+const budgetWildcard = "*"
+
+// budgetTracker counts notifications per origin for the current UTC day
+type budgetTracker struct {
+	mu       sync.Mutex
+	rules    []budgetRule
+	explicit map[string]bool
+	day      string
+	used     map[budgetKey]int64
+	alerted  map[budgetKey]bool
+}
+
+// budgetRule is a parsed budget rule
+type budgetRule struct {
+	origin    string
+	notifType domain.NotificationType
+	limit     int64
+	action    string
+}
+
+// budgetKey identifies one rule's counter for one origin
+type budgetKey struct {
+	rule   int
+	origin string
+}
+
+// budgetOverrun describes a notification that took an origin past its budget
+type budgetOverrun struct {
+	key  budgetKey
+	rule budgetRule
+	used int64
+}
+
+// newBudgetTracker creates a budget tracker from the budget configuration
+func newBudgetTracker(cfg config.BudgetConfig) *budgetTracker {
+	tracker := &budgetTracker{
+		explicit: make(map[string]bool),
+		used:     make(map[budgetKey]int64),
+		alerted:  make(map[budgetKey]bool),
+	}
+
+	for _, ruleCfg := range cfg.Rules {
+		action := ruleCfg.Action
+		if action == "" {
+			action = cfg.Action
+		}
+		tracker.rules = append(tracker.rules, budgetRule{
+			origin:    ruleCfg.Origin,
+			notifType: domain.NotificationType(ruleCfg.Type),
+			limit:     int64(ruleCfg.Limit),
+			action:    action,
+		})
+		if ruleCfg.Origin != budgetWildcard {
+			tracker.explicit[ruleCfg.Origin] = true
+		}
+	}
+
+	return tracker
+}
+
+// matching returns the indexes of the rules that count a notification
+func (t *budgetTracker) matching(notification *domain.Notification) []int {
+	origin := notification.Origin.StatsKey()
+	ruleOrigin := origin
+	if !t.explicit[origin] {
+		ruleOrigin = budgetWildcard
+	}
+
+	var matches []int
+	for i, rule := range t.rules {
+		if rule.origin != ruleOrigin {
+			continue
+		}
+		if rule.notifType != "" && rule.notifType != notification.Type {
+			continue
+		}
+		matches = append(matches, i)
+	}
+	return matches
+}
+
+// admit counts notifications against their origin budgets. If any would exceed a blocking
+// budget nothing is counted and ErrBudgetExceeded is returned; otherwise the counts are
+// committed and overruns of alerting budgets that haven't been alerted today are returned.
+func (t *budgetTracker) admit(notifications []*domain.Notification, now time.Time) ([]budgetOverrun, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover(now)
+
+	pending := make(map[budgetKey]int64)
+	for _, notification := range notifications {
+		if isOperationalAlert(notification) {
+			continue
+		}
+		for _, i := range t.matching(notification) {
+			key := budgetKey{rule: i, origin: notification.Origin.StatsKey()}
+			pending[key]++
+
+			rule := t.rules[i]
+			if rule.action == config.BudgetActionBlock && t.used[key]+pending[key] > rule.limit {
+				return nil, fmt.Errorf("%w: %s has used its daily budget of %d %s", domain.ErrBudgetExceeded, key.origin, rule.limit, rule.describe())
+			}
+		}
+	}
+
+	var overruns []budgetOverrun
+	for key, count := range pending {
+		t.used[key] += count
+		rule := t.rules[key.rule]
+		if t.used[key] > rule.limit && !t.alerted[key] {
+			t.alerted[key] = true
+			overruns = append(overruns, budgetOverrun{key: key, rule: rule, used: t.used[key]})
+		}
+	}
+
+	sort.Slice(overruns, func(i, j int) bool {
+		if overruns[i].key.origin != overruns[j].key.origin {
+			return overruns[i].key.origin < overruns[j].key.origin
+		}
+		return overruns[i].key.rule < overruns[j].key.rule
+	})
+	return overruns, nil
+}
+
+// markBlocked records that a blocking budget was hit, reporting whether it is the first time today
+func (t *budgetTracker) markBlocked(notification *domain.Notification, now time.Time) []budgetOverrun {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover(now)
+
+	var overruns []budgetOverrun
+	for _, i := range t.matching(notification) {
+		key := budgetKey{rule: i, origin: notification.Origin.StatsKey()}
+		rule := t.rules[i]
+		if rule.action != config.BudgetActionBlock || t.used[key] < rule.limit || t.alerted[key] {
+			continue
+		}
+		t.alerted[key] = true
+		overruns = append(overruns, budgetOverrun{key: key, rule: rule, used: t.used[key]})
+	}
+	return overruns
+}
+
+// rollover resets the counters when the UTC day changes
+func (t *budgetTracker) rollover(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day == t.day {
+		return
+	}
+	t.day = day
+	t.used = make(map[budgetKey]int64)
+	t.alerted = make(map[budgetKey]bool)
+}
+
+// statuses reports today's usage of every budget
+func (t *budgetTracker) statuses(now time.Time) []domain.BudgetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover(now)
+
+	statuses := make([]domain.BudgetStatus, 0, len(t.rules))
+	for i, rule := range t.rules {
+		if rule.origin != budgetWildcard {
+			statuses = append(statuses, t.status(budgetKey{rule: i, origin: rule.origin}))
+		}
+	}
+
+	// Wildcard rules are reported per origin they've counted today
+	var wildcard []domain.BudgetStatus
+	for key := range t.used {
+		if t.rules[key.rule].origin == budgetWildcard {
+			wildcard = append(wildcard, t.status(key))
+		}
+	}
+	sort.Slice(wildcard, func(i, j int) bool {
+		if wildcard[i].Origin != wildcard[j].Origin {
+			return wildcard[i].Origin < wildcard[j].Origin
+		}
+		return wildcard[i].Type < wildcard[j].Type
+	})
+
+	return append(statuses, wildcard...)
+}
+
+// status reports the usage of one budget counter
+func (t *budgetTracker) status(key budgetKey) domain.BudgetStatus {
+	rule := t.rules[key.rule]
+	return domain.BudgetStatus{
+		Origin:   key.origin,
+		Type:     string(rule.notifType),
+		Limit:    rule.limit,
+		Used:     t.used[key],
+		Action:   rule.action,
+		Exceeded: t.used[key] >= rule.limit,
+	}
+}
+
+// describe names what a rule counts, for messages
+func (r budgetRule) describe() string {
+	if r.notifType == "" {
+		return "notifications"
+	}
+	return fmt.Sprintf("%s notifications", r.notifType)
+}
+
+// WithBudgetConfig enables per-origin daily budgets
+func (s *NotificationService) WithBudgetConfig(cfg config.BudgetConfig) error {
+	s.budgetConfig = cfg
+	if !cfg.Enabled {
+		s.budgets = nil
+		return nil
+	}
+
+	s.budgets = newBudgetTracker(cfg)
+
+	return nil
+}
+
+// checkBudgets counts notifications against their origin budgets, returning ErrBudgetExceeded
+// if a blocking budget would be exceeded and alerting operators the first time each budget
+// is overrun in a day
+func (s *NotificationService) checkBudgets(ctx context.Context, notifications ...*domain.Notification) error {
+	if s.budgets == nil {
+		return nil
+	}
+
+	now := time.Now()
+	overruns, err := s.budgets.admit(notifications, now)
+	if err != nil {
+		for _, notification := range notifications {
+			overruns = append(overruns, s.budgets.markBlocked(notification, now)...)
+		}
+	}
+
+	for _, overrun := range overruns {
+		s.alertBudgetOverrun(ctx, overrun)
+	}
+
+	return err
+}
+
+// alertBudgetOverrun logs and alerts operators that an origin has used up its budget
+func (s *NotificationService) alertBudgetOverrun(ctx context.Context, overrun budgetOverrun) {
+	s.logger.Warnf("Origin budget exceeded - origin=%s, type=%s, limit=%d, used=%d, action=%s",
+		overrun.key.origin, overrun.rule.notifType, overrun.rule.limit, overrun.used, overrun.rule.action)
+
+	subject := fmt.Sprintf("Notification budget exceeded by %s", overrun.key.origin)
+	body := fmt.Sprintf("%s has sent %d %s today, over its daily budget of %d.",
+		overrun.key.origin, overrun.used, overrun.rule.describe(), overrun.rule.limit)
+	if overrun.rule.action == config.BudgetActionBlock {
+		body = fmt.Sprintf("%s has used its daily budget of %d %s. Further notifications are rejected until midnight UTC.",
+			overrun.key.origin, overrun.rule.limit, overrun.rule.describe())
+	}
+
+	metadata := map[string]interface{}{"origin": overrun.key.origin}
+	if err := s.sendOperationalAlert(ctx, s.budgetConfig.Alert, subject, body, metadata); err != nil {
+		s.logger.Errorf("Failed to send budget alert - origin=%s, error=%v", overrun.key.origin, err)
+	}
+}
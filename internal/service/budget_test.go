@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestBudgetTrackerAdmit tests blocking and alerting budgets, wildcard rules and the daily reset
+func TestBudgetTrackerAdmit(t *testing.T) {
+	tracker := newBudgetTracker(config.BudgetConfig{
+		Action: config.BudgetActionAlert,
+		Rules: []config.BudgetRuleConfig{
+			{Origin: "cron", Type: "email", Limit: 2, Action: config.BudgetActionBlock},
+			{Origin: "*", Limit: 1},
+		},
+	})
+
+	notification := func(origin string, notifType domain.NotificationType) *domain.Notification {
+		return &domain.Notification{Type: notifType, Origin: domain.Origin{System: origin}}
+	}
+
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	// A batch that would overrun a blocking budget is rejected without counting
+	batch := []*domain.Notification{notification("cron", domain.TypeEmail), notification("cron", domain.TypeEmail), notification("cron", domain.TypeEmail)}
+	if _, err := tracker.admit(batch, now); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := tracker.admit([]*domain.Notification{notification("cron", domain.TypeEmail)}, now); err != nil {
+			t.Fatalf("admit() %d error = %v", i, err)
+		}
+	}
+	if _, err := tracker.admit([]*domain.Notification{notification("cron", domain.TypeEmail)}, now); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("Expected third cron email to be blocked, got %v", err)
+	}
+
+	// Other types from cron aren't counted, and cron has its own rules so the wildcard doesn't apply
+	if _, err := tracker.admit([]*domain.Notification{notification("cron", domain.TypeSlack), notification("cron", domain.TypeSlack)}, now); err != nil {
+		t.Fatalf("Expected cron slack to be unbudgeted, got %v", err)
+	}
+
+	// Wildcard alert budgets are per origin and alert once per day
+	if overruns, err := tracker.admit([]*domain.Notification{notification("web", domain.TypeSlack)}, now); err != nil || len(overruns) != 0 {
+		t.Fatalf("Expected first web notification to be within budget, got %v, %v", overruns, err)
+	}
+	overruns, err := tracker.admit([]*domain.Notification{notification("web", domain.TypeSlack)}, now)
+	if err != nil || len(overruns) != 1 || overruns[0].key.origin != "web" {
+		t.Fatalf("Expected a web overrun alert, got %+v, %v", overruns, err)
+	}
+	if overruns, _ := tracker.admit([]*domain.Notification{notification("web", domain.TypeSlack)}, now); len(overruns) != 0 {
+		t.Errorf("Expected repeated overrun not to alert again, got %+v", overruns)
+	}
+	if overruns, _ := tracker.admit([]*domain.Notification{notification("", domain.TypeSlack)}, now); len(overruns) != 0 {
+		t.Errorf("Expected unknown origin to have its own wildcard budget, got %+v", overruns)
+	}
+
+	statuses := tracker.statuses(now)
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 budget statuses, got %+v", statuses)
+	}
+	if statuses[0].Origin != "cron" || statuses[0].Used != 2 || !statuses[0].Exceeded || statuses[0].Action != config.BudgetActionBlock {
+		t.Errorf("Unexpected cron status: %+v", statuses[0])
+	}
+	if statuses[1].Origin != domain.UnknownOrigin || statuses[2].Origin != "web" || statuses[2].Used != 3 {
+		t.Errorf("Unexpected wildcard statuses: %+v", statuses[1:])
+	}
+
+	// Budgets reset at midnight UTC
+	tomorrow := now.Add(12 * time.Hour)
+	if _, err := tracker.admit([]*domain.Notification{notification("cron", domain.TypeEmail)}, tomorrow); err != nil {
+		t.Errorf("Expected budget to reset the next day, got %v", err)
+	}
+}
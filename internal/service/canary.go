@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// canarySource tags canary notifications, so they are excluded from delivery tracking
+const canarySource = "notifier-canary"
+
+// canaryMonitor sends canary notifications through each target and tracks whether they arrive
+type canaryMonitor struct {
+	mu              sync.Mutex
+	interval        time.Duration
+	timeout         time.Duration
+	failHealthCheck bool
+	alert           config.AlertTargetConfig
+	targets         []*canaryTarget
+}
+
+// canaryTarget is the canary state of one channel
+type canaryTarget struct {
+	name        string
+	target      config.AlertTargetConfig
+	pendingID   string // Canary in flight, if any
+	lastSent    time.Time
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastError   string
+	lastLatency time.Duration
+	failures    int
+}
+
+// canaryOutcome is a canary that finished, successfully or not
+type canaryOutcome struct {
+	name      string
+	err       string
+	failures  int
+	since     time.Time // When the target started failing, for recoveries
+	alertable bool      // The target changed between healthy and failing
+}
+
+// snapshot returns the target's status
+func (t *canaryTarget) snapshot() domain.CanaryStatus {
+	status := domain.CanaryStatus{
+		Target:              t.name,
+		Healthy:             t.failures == 0,
+		LastError:           t.lastError,
+		LastLatencyMs:       t.lastLatency.Milliseconds(),
+		ConsecutiveFailures: t.failures,
+	}
+	for _, ts := range []struct {
+		at  time.Time
+		dst **time.Time
+	}{{t.lastSent, &status.LastSent}, {t.lastSuccess, &status.LastSuccess}, {t.lastFailure, &status.LastFailure}} {
+		if !ts.at.IsZero() {
+			at := ts.at
+			*ts.dst = &at
+		}
+	}
+	return status
+}
+
+// newCanaryMonitor creates a monitor from the canary configuration
+func newCanaryMonitor(cfg config.CanaryConfig) (*canaryMonitor, error) {
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval duration: %w", err)
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeout duration: %w", err)
+	}
+
+	monitor := &canaryMonitor{
+		interval:        interval,
+		timeout:         timeout,
+		failHealthCheck: cfg.FailHealthCheck,
+		alert:           cfg.Alert,
+	}
+	for _, target := range cfg.Targets {
+		name := target.Type
+		if target.Account != "" {
+			name += "/" + target.Account
+		}
+		monitor.targets = append(monitor.targets, &canaryTarget{name: name, target: target})
+	}
+	return monitor, nil
+}
+
+// WithCanaryConfig enables canary notifications
+func (s *NotificationService) WithCanaryConfig(cfg config.CanaryConfig) error {
+	if !cfg.Enabled {
+		s.canary = nil
+		return nil
+	}
+
+	checkFreq, err := time.ParseDuration(cfg.CheckFrequency)
+	if err != nil {
+		return fmt.Errorf("invalid check frequency duration: %w", err)
+	}
+
+	monitor, err := newCanaryMonitor(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.canary = monitor
+	s.canaryCheckFrequency = checkFreq
+
+	return nil
+}
+
+// canaryLoop periodically sends canaries and checks their delivery
+func (s *NotificationService) canaryLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.canaryCheckFrequency)
+	defer ticker.Stop()
+
+	s.checkCanaries(ctx, time.Now())
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkCanaries(ctx, time.Now())
+		}
+	}
+}
+
+// checkCanaries resolves canaries in flight, sends new ones that are due, and alerts when
+// a target starts failing or recovers
+func (s *NotificationService) checkCanaries(ctx context.Context, now time.Time) {
+	var outcomes []canaryOutcome
+
+	s.canary.mu.Lock()
+	for _, target := range s.canary.targets {
+		if target.pendingID != "" {
+			outcome, done := s.resolveCanary(target, now)
+			if !done {
+				continue
+			}
+			outcomes = append(outcomes, outcome)
+		}
+
+		if !target.lastSent.IsZero() && now.Sub(target.lastSent) < s.canary.interval {
+			continue
+		}
+		target.lastSent = now
+		id, err := s.sendCanary(ctx, target, now)
+		if err != nil {
+			outcomes = append(outcomes, target.fail(fmt.Sprintf("failed to queue canary: %v", err), now))
+			continue
+		}
+		target.pendingID = id
+	}
+	s.canary.mu.Unlock()
+
+	for _, outcome := range outcomes {
+		s.alertCanary(ctx, outcome, now)
+	}
+}
+
+// resolveCanary checks the target's canary in flight. It reports false while the canary is
+// still within its timeout.
+func (s *NotificationService) resolveCanary(target *canaryTarget, now time.Time) (canaryOutcome, bool) {
+	var status domain.NotificationStatus
+	var sentAt *time.Time
+	var lastError string
+	if notification, err := s.store.Get(context.Background(), target.pendingID); err == nil {
+		status = notification.Status
+		sentAt = notification.SentAt
+		lastError = notification.LastError
+	}
+
+	switch {
+	case status == domain.StatusSent:
+		target.pendingID = ""
+		since := target.lastFailure
+		recovered := target.failures > 0
+		target.failures = 0
+		target.lastError = ""
+		target.lastSuccess = now
+		if sentAt != nil {
+			target.lastSuccess = *sentAt
+			target.lastLatency = sentAt.Sub(target.lastSent)
+		}
+		return canaryOutcome{name: target.name, since: since, alertable: recovered}, true
+	case status == domain.StatusFailed:
+		if lastError == "" {
+			lastError = "delivery failed"
+		}
+		return target.fail(lastError, now), true
+	case status == "":
+		return target.fail("canary notification was removed before delivery", now), true
+	case now.Sub(target.lastSent) > s.canary.timeout:
+		return target.fail(fmt.Sprintf("not delivered within %s (status %s)", s.canary.timeout, status), now), true
+	}
+	return canaryOutcome{}, false
+}
+
+// fail records a failed canary
+func (t *canaryTarget) fail(reason string, now time.Time) canaryOutcome {
+	t.pendingID = ""
+	t.failures++
+	t.lastError = reason
+	t.lastFailure = now
+	return canaryOutcome{name: t.name, err: reason, failures: t.failures, alertable: t.failures == 1}
+}
+
+// sendCanary queues a canary notification to the target's sink
+func (s *NotificationService) sendCanary(ctx context.Context, target *canaryTarget, now time.Time) (string, error) {
+	notification := &domain.Notification{
+		ID:         uuid.New().String(),
+		Type:       domain.NotificationType(target.target.Type),
+		Account:    target.target.Account,
+		Priority:   domain.PriorityNormal,
+		Subject:    "Notifier canary",
+		Body:       fmt.Sprintf("End-to-end delivery check sent at %s. No action is needed.", now.UTC().Format(time.RFC3339)),
+		Recipients: target.target.Recipients,
+		Metadata:   map[string]interface{}{"source": canarySource, "canary": target.name},
+		CreatedAt:  now,
+	}
+
+	if _, err := s.Send(ctx, notification); err != nil {
+		return "", err
+	}
+	return notification.ID, nil
+}
+
+// alertCanary sends an alert when a target starts failing or recovers
+func (s *NotificationService) alertCanary(ctx context.Context, outcome canaryOutcome, now time.Time) {
+	if outcome.err != "" {
+		s.logger.Errorf("Canary failed - target=%s, consecutive=%d, error=%s", outcome.name, outcome.failures, outcome.err)
+	} else if outcome.alertable {
+		s.logger.Infof("Canary recovered - target=%s", outcome.name)
+	}
+	if !outcome.alertable {
+		return
+	}
+
+	subject := fmt.Sprintf("Canary failed: %s", outcome.name)
+	body := fmt.Sprintf("A canary notification through %s was not delivered: %s.", outcome.name, outcome.err)
+	if outcome.err == "" {
+		subject = fmt.Sprintf("Canary recovered: %s", outcome.name)
+		body = fmt.Sprintf("Canary notifications through %s are being delivered again after failing for %s.",
+			outcome.name, now.Sub(outcome.since).Round(time.Second))
+	}
+	if err := s.sendOperationalAlert(ctx, s.canary.alert, subject, body, map[string]interface{}{"canary": outcome.name}); err != nil {
+		s.logger.Errorf("Failed to send canary alert - target=%s, error=%v", outcome.name, err)
+	}
+}
+
+// canaryStatuses returns the status of every canary target
+func (s *NotificationService) canaryStatuses() []domain.CanaryStatus {
+	s.canary.mu.Lock()
+	defer s.canary.mu.Unlock()
+
+	statuses := make([]domain.CanaryStatus, 0, len(s.canary.targets))
+	for _, target := range s.canary.targets {
+		statuses = append(statuses, target.snapshot())
+	}
+	return statuses
+}
+
+// HealthProblems returns the reasons the service should report itself unhealthy.
+// Failing canaries are only included when canary.fail_health_check is set.
+func (s *NotificationService) HealthProblems() []string {
+	if s.canary == nil || !s.canary.failHealthCheck {
+		return nil
+	}
+
+	var problems []string
+	for _, status := range s.canaryStatuses() {
+		if !status.Healthy {
+			problems = append(problems, fmt.Sprintf("canary %s failing: %s", status.Target, status.LastError))
+		}
+	}
+	return problems
+}
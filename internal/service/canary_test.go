@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestCanaryFailureAndRecovery tests canaries alert once when delivery stops and again on recovery
+func TestCanaryFailureAndRecovery(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithCanaryConfig(config.CanaryConfig{
+		Enabled:         true,
+		Interval:        "5m",
+		Timeout:         "2m",
+		CheckFrequency:  "15s",
+		FailHealthCheck: true,
+		Alert:           config.AlertTargetConfig{Type: "stdout", Account: "ops", Recipients: []string{"ops"}},
+		Targets:         []config.AlertTargetConfig{{Type: "stdout", Recipients: []string{"canary-sink"}}},
+	})
+	if err != nil {
+		t.Fatalf("WithCanaryConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	canaries := func() []*domain.Notification {
+		var found []*domain.Notification
+		page, _ := svc.ListNotifications(ctx, &domain.NotificationFilter{})
+		for _, notification := range page.Notifications {
+			if source, _ := notification.Metadata["source"].(string); source == canarySource {
+				found = append(found, notification)
+			}
+		}
+		return found
+	}
+	alerts := func() int {
+		count := 0
+		page, _ := svc.ListNotifications(ctx, &domain.NotificationFilter{})
+		for _, notification := range page.Notifications {
+			if source, _ := notification.Metadata["source"].(string); source == operationalAlertSource {
+				count++
+			}
+		}
+		return count
+	}
+	setStatus := func(id string, status domain.NotificationStatus) {
+		notification, err := svc.GetNotification(ctx, id)
+		if err != nil {
+			t.Fatalf("GetNotification() error = %v", err)
+		}
+		notification.Status = status
+		if status == domain.StatusSent {
+			sentAt := time.Now()
+			notification.SentAt = &sentAt
+		}
+		svc.updateNotification(notification)
+	}
+
+	now := time.Now()
+	svc.checkCanaries(ctx, now)
+	sent := canaries()
+	if len(sent) != 1 || sent[0].Recipients[0] != "canary-sink" {
+		t.Fatalf("Expected a canary to the sink, got %+v", sent)
+	}
+
+	// Delivered: healthy, and nothing new is sent until the interval passes
+	setStatus(sent[0].ID, domain.StatusSent)
+	svc.checkCanaries(ctx, now.Add(time.Minute))
+	if len(canaries()) != 1 || alerts() != 0 || len(svc.HealthProblems()) != 0 {
+		t.Fatalf("Expected a healthy canary and no new sends")
+	}
+
+	// Not delivered within the timeout: one alert, and health fails
+	now = now.Add(5 * time.Minute)
+	svc.checkCanaries(ctx, now)
+	svc.checkCanaries(ctx, now.Add(3*time.Minute))
+	svc.checkCanaries(ctx, now.Add(5*time.Minute))
+	if n := alerts(); n != 1 {
+		t.Fatalf("Expected one failure alert, got %d", n)
+	}
+	if problems := svc.HealthProblems(); len(problems) != 1 {
+		t.Errorf("Expected a health problem, got %v", problems)
+	}
+	stats, err := svc.GetStats(ctx)
+	if err != nil || len(stats.Canaries) != 1 || stats.Canaries[0].Healthy || stats.Canaries[0].ConsecutiveFailures != 1 {
+		t.Fatalf("Unexpected canary stats: %+v (err %v)", stats.Canaries, err)
+	}
+
+	// Delivered again: a recovery alert
+	sent = canaries()
+	for _, notification := range sent {
+		if notification.Status != domain.StatusSent {
+			setStatus(notification.ID, domain.StatusSent)
+		}
+	}
+	svc.checkCanaries(ctx, now.Add(6*time.Minute))
+	if n := alerts(); n != 2 {
+		t.Errorf("Expected a recovery alert, got %d alerts", n)
+	}
+	if problems := svc.HealthProblems(); len(problems) != 0 {
+		t.Errorf("Expected no health problems after recovery, got %v", problems)
+	}
+}
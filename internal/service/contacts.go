@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// WithContactsConfig adds the configured contacts, so notifications can be sent to them by
+// name
+func (s *NotificationService) WithContactsConfig(cfg config.ContactsConfig) error {
+	contacts := make(map[string]domain.Contact, len(cfg.Definitions))
+	for _, contactCfg := range cfg.Definitions {
+		contact := contactCfg.Contact()
+		if err := contact.Validate(); err != nil {
+			return fmt.Errorf("failed to add contact: %w", err)
+		}
+		contact.Configured = true
+		contacts[contact.Name] = contact
+	}
+
+	s.contacts = contacts
+	return nil
+}
+
+// contactStore returns the store contacts created through the API are kept in
+func (s *NotificationService) contactStore() (domain.ContactStore, error) {
+	store, ok := s.store.(domain.ContactStore)
+	if !ok {
+		return nil, fmt.Errorf("the notification store doesn't support contacts")
+	}
+	return store, nil
+}
+
+// lookupContact returns a contact from the config file, or one of a tenant's from the store.
+// Contacts the instance's operators created without a tenant are shared by every tenant, unless
+// the tenant has its own by that name.
+func (s *NotificationService) lookupContact(ctx context.Context, tenant, name string) (*domain.Contact, error) {
+	if contact, ok := s.contacts[name]; ok {
+		return &contact, nil
+	}
+	store, err := s.contactStore()
+	if err != nil {
+		return nil, err
+	}
+	contact, err := store.GetContact(ctx, tenant, name)
+	if errors.Is(err, domain.ErrContactNotFound) && tenant != "" {
+		return store.GetContact(ctx, "", name)
+	}
+	return contact, err
+}
+
+// checkContact validates a contact before it's saved: its addresses must be for supported
+// types and its members must exist in its tenant
+func (s *NotificationService) checkContact(ctx context.Context, contact domain.Contact) error {
+	if err := contact.Validate(); err != nil {
+		return err
+	}
+	for notifType := range contact.Addresses {
+		if !s.supportsType(notifType) {
+			return fmt.Errorf("%w: unsupported notification type: %s", domain.ErrInvalidContact, notifType)
+		}
+	}
+	for _, member := range contact.Members {
+		if _, err := s.lookupContact(ctx, contact.Tenant, member); errors.Is(err, domain.ErrContactNotFound) {
+			return fmt.Errorf("%w: %s has unknown member: %s", domain.ErrInvalidContact, contact.Name, member)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateContact validates a contact and saves it to the store, as one of the caller's tenant
+func (s *NotificationService) CreateContact(ctx context.Context, contact domain.Contact) (*domain.Contact, error) {
+	contact.Tenant = auth.TenantFromContext(ctx)
+	if err := s.checkContact(ctx, contact); err != nil {
+		return nil, err
+	}
+	store, err := s.contactStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.contacts[contact.Name]; ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrContactExists, contact.Name)
+	}
+	if _, err := store.GetContact(ctx, contact.Tenant, contact.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrContactExists, contact.Name)
+	} else if !errors.Is(err, domain.ErrContactNotFound) {
+		return nil, err
+	}
+
+	contact.Configured = false
+	contact.CreatedAt = time.Now().UTC()
+	contact.UpdatedAt = contact.CreatedAt
+	if err := store.SaveContact(ctx, &contact); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Contact created - name=%s, channels=%d, members=%d", contact.Name, len(contact.Addresses), len(contact.Members))
+	return &contact, nil
+}
+
+// UpdateContact validates a contact and replaces the caller's tenant's stored contact of the
+// same name
+func (s *NotificationService) UpdateContact(ctx context.Context, contact domain.Contact) (*domain.Contact, error) {
+	if _, ok := s.contacts[contact.Name]; ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrContactConfigured, contact.Name)
+	}
+	contact.Tenant = auth.TenantFromContext(ctx)
+	if err := s.checkContact(ctx, contact); err != nil {
+		return nil, err
+	}
+	store, err := s.contactStore()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := store.GetContact(ctx, contact.Tenant, contact.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	contact.Configured = false
+	contact.CreatedAt = existing.CreatedAt
+	contact.UpdatedAt = time.Now().UTC()
+	if err := store.SaveContact(ctx, &contact); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Contact updated - name=%s, channels=%d, members=%d", contact.Name, len(contact.Addresses), len(contact.Members))
+	return &contact, nil
+}
+
+// GetContact returns a contact from the config file, or one of the caller's tenant's from the
+// store
+func (s *NotificationService) GetContact(ctx context.Context, name string) (*domain.Contact, error) {
+	return s.lookupContact(ctx, auth.TenantFromContext(ctx), name)
+}
+
+// ListContacts returns the configured contacts, the caller's tenant's stored ones and the
+// shared ones it doesn't shadow, sorted by name
+func (s *NotificationService) ListContacts(ctx context.Context) ([]*domain.Contact, error) {
+	list := make([]*domain.Contact, 0, len(s.contacts))
+	for _, contact := range s.contacts {
+		list = append(list, &contact)
+	}
+
+	store, err := s.contactStore()
+	if err != nil {
+		return nil, err
+	}
+	tenant := auth.TenantFromContext(ctx)
+	stored, err := store.ListContacts(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != "" {
+		shared, err := store.ListContacts(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, shared...)
+	}
+	seen := make(map[string]bool, len(stored))
+	for _, contact := range stored {
+		if _, ok := s.contacts[contact.Name]; ok || seen[contact.Name] {
+			continue // Configured contacts shadow stored ones, and a tenant's shadow shared ones
+		}
+		seen[contact.Name] = true
+		list = append(list, contact)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// DeleteContact removes a contact the caller's tenant created through the API. Groups it was a
+// member of skip it from then on.
+func (s *NotificationService) DeleteContact(ctx context.Context, name string) error {
+	if _, ok := s.contacts[name]; ok {
+		return fmt.Errorf("%w: %s", domain.ErrContactConfigured, name)
+	}
+	store, err := s.contactStore()
+	if err != nil {
+		return err
+	}
+	tenant := auth.TenantFromContext(ctx)
+	if err := store.DeleteContact(ctx, tenant, name); err != nil {
+		return err
+	}
+	if prefs, ok := s.store.(domain.PreferenceStore); ok {
+		if err := prefs.DeletePreferences(ctx, tenant, name); err != nil && !errors.Is(err, domain.ErrPreferencesNotFound) {
+			s.logger.Warnf("Failed to delete preferences of deleted contact - name=%s, error=%v", name, err)
+		}
+	}
+
+	s.logger.Infof("Contact deleted - name=%s", name)
+	return nil
+}
+
+// contactAddresses returns a contact's addresses on a notification's channel, along with those
+// of every member if it's a group, and the quiet hours of the contacts they belong to. Contacts
+// are looked up in the notification's tenant, and those whose preferences exclude the
+// notification are left out. Members that no longer exist, and groups already visited, are
+// skipped.
+func (s *NotificationService) contactAddresses(ctx context.Context, name string, notification *domain.Notification) ([]string, []quietWindow, error) {
+	var addresses []string
+	var quiet []quietWindow
+	found := false
+	visited := make(map[string]bool)
+
+	var walk func(name string, member bool) error
+	walk = func(name string, member bool) error {
+		if visited[name] {
+			return nil
+		}
+		visited[name] = true
+
+		contact, err := s.lookupContact(ctx, notification.Tenant, name)
+		if member && errors.Is(err, domain.ErrContactNotFound) {
+			s.logger.Warnf("Skipping unknown group member - member=%s", name)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if own := contact.Addresses[notification.Type]; len(own) > 0 {
+			found = true
+			windows, excluded, err := s.applyPreferences(ctx, contact.Name, notification)
+			if err != nil {
+				return err
+			}
+			if excluded == "" {
+				for _, address := range own {
+					if !slices.Contains(addresses, address) {
+						addresses = append(addresses, address)
+					}
+				}
+				quiet = append(quiet, windows...)
+			} else {
+				s.logger.Debugf("Contact excluded by preferences - contact=%s, type=%s, reason=%s", contact.Name, notification.Type, excluded)
+			}
+		}
+		for _, member := range contact.Members {
+			if err := walk(member, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(name, false); err != nil {
+		return nil, nil, err
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("%w: %s has no %s address", domain.ErrInvalidContact, name, notification.Type)
+	}
+	return addresses, quiet, nil
+}
+
+// resolveContacts adds the addresses the notifications' contacts have on their channels to
+// their recipients. Addresses that are already recipients aren't added again. A notification
+// that's due during the quiet hours of a contact it's sent to waits until they end.
+func (s *NotificationService) resolveContacts(ctx context.Context, notifications ...*domain.Notification) error {
+	for _, notification := range notifications {
+		if len(notification.Contacts) == 0 {
+			continue
+		}
+
+		var quiet []quietWindow
+		for _, name := range notification.Contacts {
+			addresses, windows, err := s.contactAddresses(ctx, name, notification)
+			if err != nil {
+				return err
+			}
+			for _, address := range addresses {
+				if !slices.Contains(notification.Recipients, address) {
+					notification.Recipients = append(notification.Recipients, address)
+				}
+			}
+			quiet = append(quiet, windows...)
+		}
+
+		if len(notification.Recipients)+len(notification.CC)+len(notification.BCC) == 0 {
+			return fmt.Errorf("%w: the preferences of %s exclude this notification", domain.ErrInvalidContact, strings.Join(notification.Contacts, ", "))
+		}
+		if until, ok := quietUntil(quiet, dueTime(notification)); ok {
+			notification.ScheduledFor = &until
+			s.logger.Infof("Notification deferred for contact quiet hours - id=%s, type=%s, until=%s",
+				notification.ID, notification.Type, until.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSendToContacts tests that a notification's contacts, and the members of its groups, add
+// their addresses on its channel to the recipients
+func TestSendToContacts(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithContactsConfig(config.ContactsConfig{Definitions: []config.ContactConfig{
+		{Name: "oncall-db", Addresses: map[string][]string{"stdout": {"dba"}, "email": {"dba@example.com"}}},
+	}})
+	if err != nil {
+		t.Fatalf("WithContactsConfig() error = %v", err)
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{
+		Name:      "team-payments",
+		Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {"payments", "dba"}},
+		Members:   []string{"oncall-db"},
+	}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+
+	notification := &domain.Notification{Type: domain.TypeStdout, Body: "Replica lag", Recipients: []string{"ops"}, Contacts: []string{"team-payments"}}
+	if _, err := svc.Send(ctx, notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if want := []string{"ops", "payments", "dba"}; !slices.Equal(notification.Recipients, want) {
+		t.Errorf("Expected recipients %v, got %v", want, notification.Recipients)
+	}
+
+	_, err = svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Body: "x", Contacts: []string{"nobody"}})
+	if !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected ErrContactNotFound, got %v", err)
+	}
+
+	// A group member deleted since is skipped, leaving the group's own addresses
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "intern", Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {"intern"}}}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "interns", Members: []string{"intern"}}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	if err := svc.DeleteContact(ctx, "intern"); err != nil {
+		t.Fatalf("DeleteContact() error = %v", err)
+	}
+	_, err = svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Body: "x", Contacts: []string{"interns"}})
+	if !errors.Is(err, domain.ErrInvalidContact) {
+		t.Errorf("Expected a group without stdout addresses to be rejected, got %v", err)
+	}
+}
+
+// TestContactLifecycle tests creating, updating and deleting contacts through the API, and
+// that configured contacts can't be changed
+func TestContactLifecycle(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithContactsConfig(config.ContactsConfig{Definitions: []config.ContactConfig{
+		{Name: "oncall-db", Addresses: map[string][]string{"stdout": {"dba"}}},
+	}})
+	if err != nil {
+		t.Fatalf("WithContactsConfig() error = %v", err)
+	}
+
+	stdout := map[domain.NotificationType][]string{domain.TypeStdout: {"ops"}}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "ops", Addresses: map[domain.NotificationType][]string{domain.TypeSlack: {"#ops"}}}); !errors.Is(err, domain.ErrInvalidContact) {
+		t.Errorf("Expected an address for an unsupported type to be rejected, got %v", err)
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "ops", Members: []string{"nobody"}}); !errors.Is(err, domain.ErrInvalidContact) {
+		t.Errorf("Expected an unknown member to be rejected, got %v", err)
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "oncall-db", Addresses: stdout}); !errors.Is(err, domain.ErrContactExists) {
+		t.Errorf("Expected a configured name to be taken, got %v", err)
+	}
+
+	created, err := svc.CreateContact(ctx, domain.Contact{Name: "ops", Addresses: stdout})
+	if err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	if created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Unexpected timestamps: created=%v, updated=%v", created.CreatedAt, created.UpdatedAt)
+	}
+
+	updated, err := svc.UpdateContact(ctx, domain.Contact{Name: "ops", Members: []string{"oncall-db"}})
+	if err != nil {
+		t.Fatalf("UpdateContact() error = %v", err)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) || len(updated.Addresses) != 0 {
+		t.Errorf("Expected the contact to be replaced, got %+v", updated)
+	}
+
+	list, err := svc.ListContacts(ctx)
+	if err != nil || len(list) != 2 || list[0].Name != "oncall-db" || !list[0].Configured || list[1].Name != "ops" {
+		t.Fatalf("Expected both contacts sorted by name, got %v, %v", list, err)
+	}
+
+	if _, err := svc.UpdateContact(ctx, domain.Contact{Name: "oncall-db", Addresses: stdout}); !errors.Is(err, domain.ErrContactConfigured) {
+		t.Errorf("Expected ErrContactConfigured, got %v", err)
+	}
+	if err := svc.DeleteContact(ctx, "ops"); err != nil {
+		t.Fatalf("DeleteContact() error = %v", err)
+	}
+	if _, err := svc.GetContact(ctx, "ops"); !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected a deleted contact to be unknown, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// dedupEntry is the notification that claimed a dedup key and the duplicates it has suppressed
+type dedupEntry struct {
+	id         string
+	expires    time.Time
+	suppressed int64
+}
+
+// dedupWindow remembers the dedup keys sent within the window. Expired keys are pruned whenever
+// the number of keys doubles, so a steady stream of distinct keys doesn't grow it forever.
+type dedupWindow struct {
+	mu         sync.Mutex
+	window     time.Duration
+	entries    map[string]*dedupEntry
+	pruneAt    int   // Number of keys the entries are next pruned at
+	suppressed int64 // Duplicates suppressed since the service started
+}
+
+// dedupMinPrune is the fewest keys the entries are pruned at
+const dedupMinPrune = 1024
+
+// claim records that notification id was sent with key. If the key was already claimed within
+// the window by another notification, the duplicate is counted instead and the original's ID
+// returned.
+func (d *dedupWindow) claim(key, id string, now time.Time) (original string, suppressed int64, duplicate bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.entries[key]
+	if ok && entry.id == id {
+		return "", 0, false // The same notification sent again, e.g. retried
+	}
+	if ok && now.Before(entry.expires) {
+		entry.suppressed++
+		d.suppressed++
+		return entry.id, entry.suppressed, true
+	}
+
+	if len(d.entries) >= d.pruneAt {
+		for k, entry := range d.entries {
+			if !now.Before(entry.expires) {
+				delete(d.entries, k)
+			}
+		}
+		d.pruneAt = max(2*len(d.entries), dedupMinPrune)
+	}
+	d.entries[key] = &dedupEntry{id: id, expires: now.Add(d.window)}
+	return "", 0, false
+}
+
+// release forgets a key claimed by a notification that was then rejected, so it doesn't
+// suppress the next attempt
+func (d *dedupWindow) release(key, id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[key]; ok && entry.id == id {
+		delete(d.entries, key)
+	}
+}
+
+// total returns how many duplicates have been suppressed
+func (d *dedupWindow) total() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.suppressed
+}
+
+// WithDedupConfig suppresses notifications whose dedup key was already sent within the window
+func (s *NotificationService) WithDedupConfig(cfg config.DedupConfig) error {
+	if !cfg.Enabled {
+		s.dedup = nil
+		return nil
+	}
+
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil {
+		return fmt.Errorf("invalid dedup window: %w", err)
+	}
+	if window <= 0 {
+		return fmt.Errorf("invalid dedup window: %s (must be positive)", cfg.Window)
+	}
+
+	s.dedup = &dedupWindow{
+		window:  window,
+		entries: make(map[string]*dedupEntry),
+		pruneAt: dedupMinPrune,
+	}
+	return nil
+}
+
+// suppressDuplicate claims a notification's dedup key, returning the result to report instead
+// of sending it if the key was sent within the window
+func (s *NotificationService) suppressDuplicate(notification *domain.Notification) *domain.NotificationResult {
+	if s.dedup == nil || notification.DedupKey == "" || notification.FanoutID != "" {
+		return nil // A fanned-out notification's targets share the key it claimed
+	}
+
+	original, suppressed, duplicate := s.dedup.claim(dedupKey(notification), notification.ID, time.Now())
+	if !duplicate {
+		return nil
+	}
+
+	s.logger.Infof("Duplicate notification suppressed - key=%s, original=%s, suppressed=%d, origin=%s",
+		notification.DedupKey, original, suppressed, notification.Origin.StatsKey())
+	return &domain.NotificationResult{
+		NotificationID: original,
+		Success:        true,
+		Suppressed:     true,
+		Message:        fmt.Sprintf("duplicate of %s suppressed (%d so far)", original, suppressed),
+		SentAt:         time.Now(),
+	}
+}
+
+// dedupKey is the key a notification claims. Tenants' keys are kept apart, so one tenant
+// can't suppress another's notifications.
+func dedupKey(notification *domain.Notification) string {
+	if notification.Tenant == "" {
+		return notification.DedupKey
+	}
+	return notification.Tenant + "\x00" + notification.DedupKey
+}
+
+// releaseDedup forgets the dedup keys of notifications that were rejected after claiming them
+func (s *NotificationService) releaseDedup(notifications ...*domain.Notification) {
+	if s.dedup == nil {
+		return
+	}
+	for _, notification := range notifications {
+		if notification.DedupKey != "" {
+			s.dedup.release(dedupKey(notification), notification.ID)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestDedupSuppressesDuplicates tests that a notification repeating a dedup key within the
+// window is counted instead of sent, and that the key is free again once the window ends
+func TestDedupSuppressesDuplicates(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.WithDedupConfig(config.DedupConfig{Enabled: true, Window: "100ms"}); err != nil {
+		t.Fatalf("WithDedupConfig() error = %v", err)
+	}
+
+	send := func(id, key string) *domain.NotificationResult {
+		t.Helper()
+		result, err := svc.Send(ctx, &domain.Notification{ID: id, Type: domain.TypeStdout, Body: "db-1 down", Recipients: []string{"ops"}, DedupKey: key})
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return result
+	}
+
+	if result := send("first", "db-1-down"); result.Suppressed {
+		t.Fatal("Expected the first notification to be sent")
+	}
+	for _, id := range []string{"second", "third"} {
+		result := send(id, "db-1-down")
+		if !result.Suppressed || result.NotificationID != "first" {
+			t.Errorf("Expected %s to be suppressed as a duplicate of first, got %+v", id, result)
+		}
+		if _, err := svc.GetNotification(ctx, id); err == nil {
+			t.Errorf("Expected suppressed notification %s not to be stored", id)
+		}
+	}
+	if result := send("other", "db-2-down"); result.Suppressed {
+		t.Error("Expected a different key to be sent")
+	}
+	if result := send("untagged", ""); result.Suppressed {
+		t.Error("Expected a notification without a key to be sent")
+	}
+
+	stats, err := svc.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.TotalSuppressed != 2 {
+		t.Errorf("Expected 2 suppressed, got %d", stats.TotalSuppressed)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if result := send("later", "db-1-down"); result.Suppressed {
+		t.Error("Expected the key to be free once the window ended")
+	}
+}
+
+// TestDedupBatch tests that duplicates within a batch are suppressed while the rest are sent
+func TestDedupBatch(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.WithDedupConfig(config.DedupConfig{Enabled: true, Window: "1m"}); err != nil {
+		t.Fatalf("WithDedupConfig() error = %v", err)
+	}
+
+	results, err := svc.SendBatch(ctx, []*domain.Notification{
+		{ID: "a", Type: domain.TypeStdout, Body: "x", Recipients: []string{"ops"}, DedupKey: "disk-full"},
+		{ID: "b", Type: domain.TypeStdout, Body: "x", Recipients: []string{"ops"}, DedupKey: "disk-full"},
+		{ID: "c", Type: domain.TypeStdout, Body: "x", Recipients: []string{"ops"}},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if len(results) != 3 || results[0].Suppressed || !results[1].Suppressed || results[1].NotificationID != "a" || results[2].Suppressed {
+		t.Fatalf("Unexpected results: %+v, %+v, %+v", results[0], results[1], results[2])
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// deliveryLatencySamples is how many recent deliveries latency percentiles are computed over
+const deliveryLatencySamples = 1000
+
+// deliveryTracker counts delivery outcomes and deadline misses, and keeps the latencies of
+// recent deliveries
+type deliveryTracker struct {
+	mu             sync.Mutex
+	latencies      []time.Duration // Ring of the most recent delivery latencies
+	next           int             // Where the next latency goes once the ring is full
+	latencySum     time.Duration   // Of every delivery, for the average
+	delivered      int64
+	failed         int64
+	deadlineMet    int64
+	deadlineMissed int64
+}
+
+// newDeliveryTracker creates an empty delivery tracker
+func newDeliveryTracker() *deliveryTracker {
+	return &deliveryTracker{latencies: make([]time.Duration, 0, deliveryLatencySamples)}
+}
+
+// record adds the final outcome of a notification. Only delivered notifications have a latency.
+func (t *deliveryTracker) record(latency time.Duration, delivered bool, deadline *time.Time, missed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if deadline != nil {
+		if missed {
+			t.deadlineMissed++
+		} else {
+			t.deadlineMet++
+		}
+	}
+	if !delivered {
+		t.failed++
+		return
+	}
+
+	t.delivered++
+	t.latencySum += latency
+	if len(t.latencies) < deliveryLatencySamples {
+		t.latencies = append(t.latencies, latency)
+		return
+	}
+	t.latencies[t.next] = latency
+	t.next = (t.next + 1) % deliveryLatencySamples
+}
+
+// stats returns the delivery statistics and the average latency of every delivery in milliseconds
+func (t *deliveryTracker) stats() (domain.DeliveryStats, float64) {
+	t.mu.Lock()
+	stats := domain.DeliveryStats{
+		Delivered:      t.delivered,
+		Failed:         t.failed,
+		DeadlineMet:    t.deadlineMet,
+		DeadlineMissed: t.deadlineMissed,
+		LatencySumMs:   durationMs(t.latencySum),
+	}
+	var average float64
+	if t.delivered > 0 {
+		average = durationMs(t.latencySum / time.Duration(t.delivered))
+	}
+	latencies := slices.Clone(t.latencies)
+	t.mu.Unlock()
+
+	slices.Sort(latencies)
+	stats.LatencyP50Ms = durationMs(percentile(latencies, 0.5))
+	stats.LatencyP95Ms = durationMs(percentile(latencies, 0.95))
+	return stats, average
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies, or 0 if there are none
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// durationMs converts a duration to fractional milliseconds
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// dueSince returns when a notification became due: when it was created (or enqueued), or its
+// scheduled time
+func dueSince(msg *domain.QueueMessage) time.Time {
+	notification := msg.Notification
+	start := notification.CreatedAt
+	if start.IsZero() {
+		start = time.Unix(msg.EnqueuedAt, 0)
+	}
+	if notification.ScheduledFor != nil && notification.ScheduledFor.After(start) {
+		start = *notification.ScheduledFor
+	}
+	return start
+}
+
+// recordDelivery records the final outcome of a notification for delivery statistics and SLO
+// tracking, and marks it if it missed its deadline
+func (s *NotificationService) recordDelivery(msg *domain.QueueMessage, delivered bool) {
+	notification := msg.Notification
+	if isOperationalAlert(notification) {
+		return
+	}
+
+	now := time.Now()
+	if notification.Deadline != nil && (!delivered || now.After(*notification.Deadline)) {
+		notification.DeadlineMissed = true
+		s.logger.Warnf("Notification missed its deadline - id=%s, type=%s, deadline=%s, delivered=%t",
+			notification.ID, notification.Type, notification.Deadline.UTC().Format(time.RFC3339), delivered)
+	}
+	s.deliveries.record(now.Sub(dueSince(msg)), delivered, notification.Deadline, notification.DeadlineMissed)
+
+	if s.slo != nil {
+		s.slo.record(msg, delivered, now)
+	}
+}
+
+// DeliveryMetrics returns delivery latency and deadline statistics since the service started
+func (s *NotificationService) DeliveryMetrics() domain.DeliveryStats {
+	stats, _ := s.deliveries.stats()
+	return stats
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestDeliveryTrackerPercentiles tests latency percentiles over recent deliveries and the
+// average over every delivery
+func TestDeliveryTrackerPercentiles(t *testing.T) {
+	tracker := newDeliveryTracker()
+	for i := 1; i <= 100; i++ {
+		tracker.record(time.Duration(i)*time.Millisecond, true, nil, false)
+	}
+	tracker.record(0, false, nil, false)
+
+	stats, average := tracker.stats()
+	if stats.LatencyP50Ms != 50 || stats.LatencyP95Ms != 95 {
+		t.Errorf("Expected p50 50ms and p95 95ms, got %v and %v", stats.LatencyP50Ms, stats.LatencyP95Ms)
+	}
+	if average != 50.5 || stats.Delivered != 100 || stats.Failed != 1 {
+		t.Errorf("Unexpected totals: average=%v, %+v", average, stats)
+	}
+
+	// Once the ring is full, the oldest latencies make way for new ones
+	for range deliveryLatencySamples {
+		tracker.record(time.Second, true, nil, false)
+	}
+	if stats, _ := tracker.stats(); stats.LatencyP50Ms != 1000 {
+		t.Errorf("Expected p50 1000ms after the ring wrapped, got %v", stats.LatencyP50Ms)
+	}
+}
+
+// TestDeliveryDeadlines tests that notifications delivered after their deadline are marked,
+// and that deadlines met and missed are counted in the stats
+func TestDeliveryDeadlines(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	for _, notification := range []*domain.Notification{
+		{ID: "n-late", Type: domain.TypeStdout, Body: "late", Recipients: []string{"console"}, Deadline: &past},
+		{ID: "n-on-time", Type: domain.TypeStdout, Body: "on time", Recipients: []string{"console"}, Deadline: &future},
+		{ID: "n-no-deadline", Type: domain.TypeStdout, Body: "whenever", Recipients: []string{"console"}},
+	} {
+		if _, err := svc.Send(ctx, notification); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		waitForStatus(t, svc, notification.ID, domain.StatusSent)
+	}
+
+	for id, missed := range map[string]bool{"n-late": true, "n-on-time": false, "n-no-deadline": false} {
+		if n, _ := svc.GetNotification(ctx, id); n.DeadlineMissed != missed {
+			t.Errorf("Expected %s deadline_missed=%t, got %t", id, missed, n.DeadlineMissed)
+		}
+	}
+
+	stats, err := svc.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.Delivery.Delivered != 3 || stats.Delivery.DeadlineMet != 1 || stats.Delivery.DeadlineMissed != 1 {
+		t.Errorf("Unexpected delivery stats: %+v", stats.Delivery)
+	}
+	if stats.Delivery.LatencyP95Ms <= 0 || stats.AverageLatency <= 0 {
+		t.Errorf("Expected delivery latencies to be measured, got %+v, average %v", stats.Delivery, stats.AverageLatency)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// digestKey identifies the digest a notification is combined into: one per tenant, account
+// and recipients
+type digestKey struct {
+	tenant     string
+	notifType  domain.NotificationType
+	account    string
+	recipients string
+}
+
+// digestBuffer holds low-priority notifications until their digest is sent
+type digestBuffer struct {
+	mu          sync.Mutex
+	interval    time.Duration
+	maxPriority domain.Priority
+	types       map[domain.NotificationType]bool // nil digests every type
+	maxItems    int
+	pending     map[digestKey][]*domain.Notification
+}
+
+// WithDigestConfig combines low-priority notifications into digests sent on an interval
+func (s *NotificationService) WithDigestConfig(cfg config.DigestConfig) error {
+	if !cfg.Enabled {
+		s.digest = nil
+		return nil
+	}
+
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid digest interval: %w", err)
+	}
+	if interval <= 0 {
+		return fmt.Errorf("invalid digest interval: %s (must be positive)", cfg.Interval)
+	}
+	maxPriority := domain.PriorityLow
+	if cfg.MaxPriority != "" {
+		if maxPriority, err = domain.ParsePriority(cfg.MaxPriority); err != nil {
+			return fmt.Errorf("invalid digest max_priority: %w", err)
+		}
+	}
+
+	digest := &digestBuffer{
+		interval:    interval,
+		maxPriority: maxPriority,
+		maxItems:    cfg.MaxItems,
+		pending:     make(map[digestKey][]*domain.Notification),
+	}
+	if len(cfg.Types) > 0 {
+		digest.types = make(map[domain.NotificationType]bool, len(cfg.Types))
+		for _, notifType := range cfg.Types {
+			digest.types[domain.NotificationType(notifType)] = true
+		}
+	}
+	s.digest = digest
+
+	return nil
+}
+
+// digestible reports whether a notification can wait for a digest. Critical notifications,
+// and ones that are scheduled, have a deadline or fallbacks, are copied or blind copied, or are
+// held for approval, are sent on their own.
+func (s *NotificationService) digestible(notification *domain.Notification) bool {
+	if s.digest == nil || notification.Priority > s.digest.maxPriority || notification.Priority >= domain.PriorityCritical {
+		return false
+	}
+	if s.digest.types != nil && !s.digest.types[notification.Type] {
+		return false
+	}
+	if notification.Status == domain.StatusHeld || notification.DryRun || isOperationalAlert(notification) {
+		return false
+	}
+	return notification.ScheduledFor == nil && notification.Deadline == nil && len(notification.Fallbacks) == 0 &&
+		len(notification.CC) == 0 && len(notification.BCC) == 0 && len(notification.Recipients) > 0
+}
+
+// addToDigest adds a notification digestible accepts to its pending digest instead of queueing
+// it. A digest that reaches the maximum size is sent straight away.
+func (s *NotificationService) addToDigest(ctx context.Context, notification *domain.Notification) {
+	recipients := slices.Clone(notification.Recipients)
+	slices.Sort(recipients)
+	key := digestKey{tenant: notification.Tenant, notifType: notification.Type, account: notification.Account, recipients: strings.Join(recipients, "\x00")}
+
+	// Stored before it's added, since the digest may be sent and its items updated at any moment
+	notification.Status = domain.StatusDigested
+	s.updateNotification(notification)
+	s.digest.mu.Lock()
+	items := append(s.digest.pending[key], notification)
+	full := s.digest.maxItems > 0 && len(items) >= s.digest.maxItems
+	if full {
+		delete(s.digest.pending, key)
+	} else {
+		s.digest.pending[key] = items
+	}
+	s.digest.mu.Unlock()
+
+	if full {
+		s.sendDigest(ctx, items)
+	}
+}
+
+// digestLoop sends the pending digests every interval, and once more when the service stops
+// so they're queued rather than lost
+func (s *NotificationService) digestLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.digest.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			s.flushDigests(context.Background())
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.flushDigests(ctx)
+		}
+	}
+}
+
+// flushDigests sends every pending digest
+func (s *NotificationService) flushDigests(ctx context.Context) {
+	s.digest.mu.Lock()
+	pending := s.digest.pending
+	s.digest.pending = make(map[digestKey][]*domain.Notification)
+	s.digest.mu.Unlock()
+
+	for _, items := range pending {
+		s.sendDigest(ctx, items)
+	}
+}
+
+// sendDigest queues one notification combining items, and records it on each of them. Items
+// cancelled while they waited are left out.
+func (s *NotificationService) sendDigest(ctx context.Context, items []*domain.Notification) {
+	items = slices.DeleteFunc(items, func(item *domain.Notification) bool {
+		stored, err := s.store.Get(ctx, item.ID)
+		return err != nil || stored.Status == domain.StatusFailed
+	})
+	if len(items) == 0 {
+		return
+	}
+
+	digest := newDigest(items)
+	if err := s.storeNotification(digest); err != nil {
+		s.logger.Errorf("Failed to store digest - type=%s, count=%d, error=%v", digest.Type, len(items), err)
+		return
+	}
+	s.markQueued(digest)
+	if err := s.enqueue(ctx, digest); err != nil {
+		digest.Status = domain.StatusFailed
+		digest.LastError = fmt.Sprintf("failed to enqueue: %v", err)
+		s.updateNotification(digest)
+		s.logger.Errorf("Failed to enqueue digest - id=%s, type=%s, count=%d, error=%v", digest.ID, digest.Type, len(items), err)
+		return
+	}
+
+	for _, item := range items {
+		item.DigestID = digest.ID
+		s.updateNotification(item)
+	}
+	s.logger.Infof("Digest queued - id=%s, type=%s, account=%s, recipients=%d, count=%d",
+		digest.ID, digest.Type, digest.Account, len(digest.Recipients), len(items))
+}
+
+// newDigest builds the notification combining items, which share a channel and recipients, as
+// a plain text list of their subjects and bodies, tagged with the tags they all carry. A digest
+// of one notification is sent with its content unchanged.
+func newDigest(items []*domain.Notification) *domain.Notification {
+	first := items[0]
+	digest := &domain.Notification{
+		ID:         uuid.New().String(),
+		Type:       first.Type,
+		Account:    first.Account,
+		Priority:   first.Priority,
+		Status:     domain.StatusPending,
+		Subject:    first.Subject,
+		Body:       first.Body,
+		HTMLBody:   first.HTMLBody,
+		Recipients: first.Recipients,
+		Metadata:   first.Metadata,
+		Tags:       maps.Clone(first.Tags),
+		Origin:     first.Origin,
+		Tenant:     first.Tenant,
+		CreatedAt:  time.Now(),
+		MaxRetries: first.MaxRetries,
+		DigestOf:   make([]string, 0, len(items)),
+	}
+
+	var body strings.Builder
+	for i, item := range items {
+		digest.DigestOf = append(digest.DigestOf, item.ID)
+		digest.Priority = max(digest.Priority, item.Priority)
+		if item.Origin != digest.Origin {
+			digest.Origin = domain.Origin{}
+		}
+		maps.DeleteFunc(digest.Tags, func(key, value string) bool {
+			return item.Tags[key] != value
+		})
+
+		if i > 0 {
+			body.WriteString("\n\n")
+		}
+		fmt.Fprintf(&body, "[%s]", item.CreatedAt.UTC().Format("15:04"))
+		if item.Subject != "" {
+			body.WriteString(" " + item.Subject)
+		}
+		body.WriteString("\n" + item.Body)
+	}
+
+	if len(items) > 1 {
+		digest.Subject = fmt.Sprintf("Digest: %d notifications", len(items))
+		digest.Body = body.String()
+		digest.HTMLBody = ""
+		digest.Metadata = nil
+	}
+	return digest
+}
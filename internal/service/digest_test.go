@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestDigestCombinesNotifications tests that low-priority notifications wait for a digest per
+// recipient, that others are queued as usual, and that cancelled ones are left out
+func TestDigestCombinesNotifications(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.WithDigestConfig(config.DigestConfig{Enabled: true, Interval: "1h", MaxPriority: "low"}); err != nil {
+		t.Fatalf("WithDigestConfig() error = %v", err)
+	}
+
+	send := func(id string, priority domain.Priority, recipient string) *domain.Notification {
+		t.Helper()
+		notification := &domain.Notification{ID: id, Type: domain.TypeStdout, Priority: priority, Subject: "Build " + id, Body: "passed", Recipients: []string{recipient}}
+		if _, err := svc.Send(ctx, notification); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return notification
+	}
+
+	a := send("a", domain.PriorityLow, "ops")
+	b := send("b", domain.PriorityLow, "ops")
+	cancelled := send("c", domain.PriorityLow, "ops")
+	dev := send("d", domain.PriorityLow, "dev")
+	urgent := send("e", domain.PriorityNormal, "ops")
+
+	for _, notification := range []*domain.Notification{a, b, cancelled, dev} {
+		if notification.Status != domain.StatusDigested {
+			t.Errorf("Expected %s to be digested, got %s", notification.ID, notification.Status)
+		}
+	}
+	if urgent.Status == domain.StatusDigested {
+		t.Error("Expected a normal priority notification to be queued")
+	}
+	if err := svc.CancelNotification(ctx, "c"); err != nil {
+		t.Fatalf("CancelNotification() error = %v", err)
+	}
+
+	svc.flushDigests(ctx)
+
+	if a.DigestID == "" || a.DigestID != b.DigestID || a.DigestID == dev.DigestID || cancelled.DigestID != "" {
+		t.Fatalf("Unexpected digests: a=%q, b=%q, c=%q, d=%q", a.DigestID, b.DigestID, cancelled.DigestID, dev.DigestID)
+	}
+	digest, err := svc.GetNotification(ctx, a.DigestID)
+	if err != nil {
+		t.Fatalf("GetNotification() error = %v", err)
+	}
+	if !slices.Equal(digest.DigestOf, []string{"a", "b"}) || digest.Subject != "Digest: 2 notifications" ||
+		!strings.Contains(digest.Body, "Build a") || !strings.Contains(digest.Body, "Build b") {
+		t.Errorf("Unexpected digest: %+v", digest)
+	}
+
+	// A digest of one notification keeps its content
+	single, err := svc.GetNotification(ctx, dev.DigestID)
+	if err != nil {
+		t.Fatalf("GetNotification() error = %v", err)
+	}
+	if single.Subject != "Build d" || single.Body != "passed" {
+		t.Errorf("Expected a single notification's content unchanged, got %q, %q", single.Subject, single.Body)
+	}
+}
+
+// TestDigestMaxItems tests that a digest is sent as soon as it's full
+func TestDigestMaxItems(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.WithDigestConfig(config.DigestConfig{Enabled: true, Interval: "1h", MaxPriority: "normal", MaxItems: 2}); err != nil {
+		t.Fatalf("WithDigestConfig() error = %v", err)
+	}
+
+	results, err := svc.SendBatch(ctx, []*domain.Notification{
+		{ID: "a", Type: domain.TypeStdout, Priority: domain.PriorityNormal, Body: "x", Recipients: []string{"ops"}},
+		{ID: "b", Type: domain.TypeStdout, Priority: domain.PriorityNormal, Body: "y", Recipients: []string{"ops"}},
+		{ID: "c", Type: domain.TypeStdout, Priority: domain.PriorityHigh, Body: "z", Recipients: []string{"ops"}},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if results[0].Message != "notification added to the next digest" {
+		t.Errorf("Unexpected result message: %q", results[0].Message)
+	}
+
+	a, _ := svc.GetNotification(ctx, "a")
+	c, _ := svc.GetNotification(ctx, "c")
+	if a.DigestID == "" {
+		t.Error("Expected the full digest to be sent straight away")
+	}
+	if c.Status == domain.StatusDigested {
+		t.Error("Expected a high priority notification to be queued")
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// Queue disciplines supported by the dispatcher
+const (
+	DisciplineFIFO = "fifo"
+	DisciplineLIFO = "lifo"
+	DisciplineWFQ  = "wfq"
+)
+
+// dispatcher sits between the queue and the workers and reorders dequeued messages
+// according to per-category queue disciplines
+type dispatcher struct {
+	mu                sync.Mutex
+	lanes             map[string]*dispatchLane
+	laneOrder         []string
+	nextLane          int
+	defaultDiscipline string
+	categories        map[string]config.CategoryDispatchConfig
+
+	// bufferSize bounds how many fifo and wfq messages are buffered; lifo lanes aren't
+	// bounded, so the dispatcher keeps pulling their messages from the queue and the newest
+	// is served first even under a backlog
+	bufferSize int
+	bounded    int
+	size       int
+
+	// changed is closed and replaced whenever a message is added or taken
+	changed chan struct{}
+}
+
+// dispatchLane holds the buffered messages of a single category
+type dispatchLane struct {
+	discipline string
+
+	// messages is used by fifo and lifo lanes
+	messages []*domain.QueueMessage
+
+	// byAccount and rr are used by wfq lanes
+	byAccount map[string][]*domain.QueueMessage
+	rr        *queue.WeightedRoundRobin
+	size      int
+}
+
+// newDispatcher creates a dispatcher from the dispatch configuration
+func newDispatcher(cfg config.DispatchConfig) (*dispatcher, error) {
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+
+	defaultDiscipline := cfg.DefaultDiscipline
+	if defaultDiscipline == "" {
+		defaultDiscipline = DisciplineFIFO
+	}
+	if !isValidDiscipline(defaultDiscipline) {
+		return nil, fmt.Errorf("invalid default discipline: %s", defaultDiscipline)
+	}
+
+	for name, category := range cfg.Categories {
+		if category.Discipline != "" && !isValidDiscipline(category.Discipline) {
+			return nil, fmt.Errorf("invalid discipline for category %s: %s", name, category.Discipline)
+		}
+	}
+
+	return &dispatcher{
+		lanes:             make(map[string]*dispatchLane),
+		defaultDiscipline: defaultDiscipline,
+		categories:        cfg.Categories,
+		bufferSize:        bufferSize,
+		changed:           make(chan struct{}),
+	}, nil
+}
+
+// isValidDiscipline reports whether a discipline name is supported
+func isValidDiscipline(discipline string) bool {
+	return discipline == DisciplineFIFO || discipline == DisciplineLIFO || discipline == DisciplineWFQ
+}
+
+// notificationCategory returns the category of a notification from its metadata
+func notificationCategory(notification *domain.Notification) string {
+	if category, ok := notification.Metadata["category"].(string); ok {
+		return category
+	}
+	return ""
+}
+
+// push buffers a message, blocking while the buffer is full unless it belongs to a lifo lane
+func (d *dispatcher) push(ctx context.Context, msg *domain.QueueMessage) error {
+	d.mu.Lock()
+	lane := d.laneFor(notificationCategory(msg.Notification))
+	for lane.bounded() && d.bounded >= d.bufferSize {
+		if err := d.wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	lane.add(msg)
+	if lane.bounded() {
+		d.bounded++
+	}
+	d.size++
+	d.notify()
+	d.mu.Unlock()
+	return nil
+}
+
+// next returns the next message to process, blocking until one is available
+func (d *dispatcher) next(ctx context.Context) (*domain.QueueMessage, error) {
+	d.mu.Lock()
+	for d.size == 0 {
+		if err := d.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := d.pop()
+	d.size--
+	d.notify()
+	d.mu.Unlock()
+	return msg, nil
+}
+
+// drain removes and returns every buffered message, so they can be handed back to the
+// queue when the service stops
+func (d *dispatcher) drain() []*domain.QueueMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	drained := make([]*domain.QueueMessage, 0, d.size)
+	for d.size > 0 {
+		drained = append(drained, d.pop())
+		d.size--
+	}
+	d.notify()
+	return drained
+}
+
+// wait releases the lock until the buffer changes or the context ends, returning with the
+// lock held on a change and without it on error (must be called with lock held)
+func (d *dispatcher) wait(ctx context.Context) error {
+	changed := d.changed
+	d.mu.Unlock()
+
+	select {
+	case <-changed:
+		d.mu.Lock()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notify wakes every goroutine waiting for the buffer to change (must be called with lock held)
+func (d *dispatcher) notify() {
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// laneFor returns the lane for a category, creating it on first use (must be called with lock held)
+func (d *dispatcher) laneFor(category string) *dispatchLane {
+	if lane, ok := d.lanes[category]; ok {
+		return lane
+	}
+
+	discipline := d.defaultDiscipline
+	var weights map[string]int
+	if categoryCfg, ok := d.categories[category]; ok {
+		if categoryCfg.Discipline != "" {
+			discipline = categoryCfg.Discipline
+		}
+		weights = categoryCfg.AccountWeights
+	}
+
+	lane := &dispatchLane{
+		discipline: discipline,
+		byAccount:  make(map[string][]*domain.QueueMessage),
+		rr:         queue.NewWeightedRoundRobin(weights),
+	}
+	d.lanes[category] = lane
+	d.laneOrder = append(d.laneOrder, category)
+	sort.Strings(d.laneOrder)
+	return lane
+}
+
+// pop takes the next message, rotating between categories so one busy category
+// can't monopolize the workers (must be called with lock held)
+func (d *dispatcher) pop() *domain.QueueMessage {
+	for i := 0; i < len(d.laneOrder); i++ {
+		idx := (d.nextLane + i) % len(d.laneOrder)
+		lane := d.lanes[d.laneOrder[idx]]
+		if lane.len() == 0 {
+			continue
+		}
+		d.nextLane = (idx + 1) % len(d.laneOrder)
+		if lane.bounded() {
+			d.bounded--
+		}
+		return lane.take()
+	}
+	return nil
+}
+
+// bounded reports whether the lane's messages count against the buffer size
+func (l *dispatchLane) bounded() bool {
+	return l.discipline != DisciplineLIFO
+}
+
+// add appends a message to the lane
+func (l *dispatchLane) add(msg *domain.QueueMessage) {
+	if l.discipline == DisciplineWFQ {
+		account := msg.Notification.Account
+		l.byAccount[account] = append(l.byAccount[account], msg)
+		l.size++
+		return
+	}
+	l.messages = append(l.messages, msg)
+}
+
+// len returns the number of buffered messages in the lane
+func (l *dispatchLane) len() int {
+	if l.discipline == DisciplineWFQ {
+		return l.size
+	}
+	return len(l.messages)
+}
+
+// take removes the next message according to the lane's discipline
+func (l *dispatchLane) take() *domain.QueueMessage {
+	switch l.discipline {
+	case DisciplineLIFO:
+		last := len(l.messages) - 1
+		msg := l.messages[last]
+		l.messages[last] = nil
+		l.messages = l.messages[:last]
+		return msg
+	case DisciplineWFQ:
+		return l.takeWeighted()
+	default:
+		msg := l.messages[0]
+		l.messages[0] = nil
+		l.messages = l.messages[1:]
+		return msg
+	}
+}
+
+// takeWeighted picks an account using weighted round-robin and returns its oldest message
+func (l *dispatchLane) takeWeighted() *domain.QueueMessage {
+	accounts := make([]string, 0, len(l.byAccount))
+	for account := range l.byAccount {
+		accounts = append(accounts, account)
+	}
+	selected := l.rr.Next(accounts)
+
+	pending := l.byAccount[selected]
+	msg := pending[0]
+	if len(pending) == 1 {
+		// Drop idle accounts so they don't accumulate credit while they have nothing to send
+		delete(l.byAccount, selected)
+		l.rr.Forget(selected)
+	} else {
+		l.byAccount[selected] = pending[1:]
+	}
+	l.size--
+	return msg
+}
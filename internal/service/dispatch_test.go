@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+func dispatchMessage(id, category, account string) *domain.QueueMessage {
+	return &domain.QueueMessage{
+		ID: id,
+		Notification: &domain.Notification{
+			ID:       id,
+			Account:  account,
+			Metadata: map[string]interface{}{"category": category},
+		},
+	}
+}
+
+// TestDispatcherDisciplines tests the processing order of each queue discipline
+func TestDispatcherDisciplines(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.DispatchConfig
+		messages []*domain.QueueMessage
+		expected []string
+	}{
+		{
+			name:   "fifo",
+			config: config.DispatchConfig{DefaultDiscipline: DisciplineFIFO},
+			messages: []*domain.QueueMessage{
+				dispatchMessage("1", "", "a"),
+				dispatchMessage("2", "", "a"),
+				dispatchMessage("3", "", "a"),
+			},
+			expected: []string{"1", "2", "3"},
+		},
+		{
+			name: "lifo category",
+			config: config.DispatchConfig{
+				Categories: map[string]config.CategoryDispatchConfig{
+					"status": {Discipline: DisciplineLIFO},
+				},
+			},
+			messages: []*domain.QueueMessage{
+				dispatchMessage("1", "status", "a"),
+				dispatchMessage("2", "status", "a"),
+				dispatchMessage("3", "status", "a"),
+			},
+			expected: []string{"3", "2", "1"},
+		},
+		{
+			name: "weighted fair across accounts",
+			config: config.DispatchConfig{
+				DefaultDiscipline: DisciplineWFQ,
+				Categories: map[string]config.CategoryDispatchConfig{
+					"": {AccountWeights: map[string]int{"big": 2}},
+				},
+			},
+			messages: []*domain.QueueMessage{
+				dispatchMessage("b1", "", "big"),
+				dispatchMessage("b2", "", "big"),
+				dispatchMessage("b3", "", "big"),
+				dispatchMessage("b4", "", "big"),
+				dispatchMessage("s1", "", "small"),
+				dispatchMessage("s2", "", "small"),
+			},
+			expected: []string{"b1", "s1", "b2", "b3", "s2", "b4"},
+		},
+		{
+			name:   "round-robin across categories",
+			config: config.DispatchConfig{DefaultDiscipline: DisciplineLIFO},
+			messages: []*domain.QueueMessage{
+				dispatchMessage("a1", "alerts", "x"),
+				dispatchMessage("a2", "alerts", "x"),
+				dispatchMessage("d1", "digest", "x"),
+			},
+			expected: []string{"a2", "d1", "a1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDispatcher(tt.config)
+			if err != nil {
+				t.Fatalf("newDispatcher() error = %v", err)
+			}
+
+			ctx := context.Background()
+			for _, msg := range tt.messages {
+				if err := d.push(ctx, msg); err != nil {
+					t.Fatalf("push() error = %v", err)
+				}
+			}
+
+			for i, want := range tt.expected {
+				msg, err := d.next(ctx)
+				if err != nil {
+					t.Fatalf("next() error = %v", err)
+				}
+				if msg.ID != want {
+					t.Errorf("message %d = %s, want %s", i, msg.ID, want)
+				}
+			}
+		})
+	}
+}
+
+// TestDispatcherBackpressure tests that push blocks once the buffer is full
+func TestDispatcherBackpressure(t *testing.T) {
+	d, err := newDispatcher(config.DispatchConfig{BufferSize: 1, DefaultDiscipline: DisciplineWFQ})
+	if err != nil {
+		t.Fatalf("newDispatcher() error = %v", err)
+	}
+
+	if err := d.push(context.Background(), dispatchMessage("1", "", "a")); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.push(ctx, dispatchMessage("2", "", "a")); err == nil {
+		t.Error("Expected push to fail on a full buffer with cancelled context")
+	}
+}
+
+// TestDispatcherLIFOUnderBacklog tests that lifo lanes keep pulling past the buffer size,
+// so the newest message of a backlog is served first
+func TestDispatcherLIFOUnderBacklog(t *testing.T) {
+	d, err := newDispatcher(config.DispatchConfig{
+		BufferSize: 2,
+		Categories: map[string]config.CategoryDispatchConfig{
+			"status": {Discipline: DisciplineLIFO},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newDispatcher() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for i := 1; i <= 5; i++ {
+		if err := d.push(ctx, dispatchMessage(fmt.Sprint(i), "status", "a")); err != nil {
+			t.Fatalf("push() %d error = %v", i, err)
+		}
+	}
+
+	msg, err := d.next(ctx)
+	if err != nil {
+		t.Fatalf("next() error = %v", err)
+	}
+	if msg.ID != "5" {
+		t.Errorf("first message = %s, want the newest (5)", msg.ID)
+	}
+}
+
+// TestStopReturnsDispatchedMessages tests that messages buffered by the dispatcher go
+// back to the queue when the service stops
+func TestStopReturnsDispatchedMessages(t *testing.T) {
+	svc := createTestService(t)
+	if err := svc.WithDispatchConfig(config.DispatchConfig{DefaultDiscipline: DisciplineLIFO}); err != nil {
+		t.Fatalf("WithDispatchConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := svc.queue.Enqueue(ctx, &domain.Notification{ID: fmt.Sprint(i), Type: domain.TypeStdout}); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		msg, err := svc.queue.Dequeue(ctx)
+		if err != nil {
+			t.Fatalf("Dequeue() error = %v", err)
+		}
+		if err := svc.dispatcher.push(ctx, msg); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+
+	if err := svc.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	metrics := svc.QueueMetrics()
+	if metrics.Depth != 3 || metrics.InFlight != 0 {
+		t.Errorf("queue depth = %d, in flight = %d, want 3 waiting and none in flight", metrics.Depth, metrics.InFlight)
+	}
+}
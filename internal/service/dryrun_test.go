@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// countingNotifier is a stdout notifier that counts deliveries
+type countingNotifier struct {
+	*notifier.StdoutNotifier
+	sends int
+}
+
+func (c *countingNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	c.sends++
+	return c.StdoutNotifier.Send(ctx, notification)
+}
+
+// TestDryRunValidatesWithoutDelivering tests that dry-run notifications are validated but never sent
+func TestDryRunValidatesWithoutDelivering(t *testing.T) {
+	counter := &countingNotifier{StdoutNotifier: notifier.NewStdoutNotifier()}
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeStdout, "", counter)
+	q, _ := queue.NewLocalQueue(nil)
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+
+	valid := &domain.Notification{ID: "valid", Type: domain.TypeStdout, Body: "hello", Recipients: []string{"console"}, DryRun: true}
+	svc.processNotification(context.Background(), &domain.QueueMessage{ID: "msg-1", Notification: valid})
+	if valid.Status != domain.StatusSent || counter.sends != 0 {
+		t.Errorf("Expected dry run to succeed without sending, got status=%s sends=%d", valid.Status, counter.sends)
+	}
+
+	invalid := &domain.Notification{ID: "invalid", Type: domain.TypeStdout, Body: "hello", DryRun: true}
+	svc.processNotification(context.Background(), &domain.QueueMessage{ID: "msg-2", Notification: invalid})
+	if invalid.Status == domain.StatusSent || invalid.LastError == "" || counter.sends != 0 {
+		t.Errorf("Expected dry run without recipients to fail validation, got status=%s error=%q", invalid.Status, invalid.LastError)
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// statusBroadcaster fans status events out to subscribers without blocking delivery
+type statusBroadcaster struct {
+	mu          sync.RWMutex
+	subscribers map[chan domain.StatusEvent]struct{}
+}
+
+// newStatusBroadcaster creates a status broadcaster with no subscribers
+func newStatusBroadcaster() *statusBroadcaster {
+	return &statusBroadcaster{
+		subscribers: make(map[chan domain.StatusEvent]struct{}),
+	}
+}
+
+// subscribe registers a subscriber with the given buffer size
+func (b *statusBroadcaster) subscribe(buffer int) (<-chan domain.StatusEvent, func()) {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	ch := make(chan domain.StatusEvent, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// publish sends an event to every subscriber, dropping it for subscribers whose buffer is full
+func (b *statusBroadcaster) publish(event domain.StatusEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscribeStatus implements domain.StatusEventSource
+func (s *NotificationService) SubscribeStatus(buffer int) (<-chan domain.StatusEvent, func()) {
+	return s.events.subscribe(buffer)
+}
+
+// publishStatus announces a notification's current status to subscribers
+func (s *NotificationService) publishStatus(notification *domain.Notification) {
+	s.observeJob(notification)
+	s.events.publish(domain.StatusEvent{
+		NotificationID: notification.ID,
+		Type:           notification.Type,
+		Account:        notification.Account,
+		Origin:         notification.Origin,
+		Tenant:         notification.Tenant,
+		Status:         notification.Status,
+		RetryCount:     notification.RetryCount,
+		Error:          notification.LastError,
+		At:             time.Now(),
+	})
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// failoverRule is a parsed failover rule
+type failoverRule struct {
+	notifType   domain.NotificationType
+	account     string // Empty matches every account of the type
+	minPriority domain.Priority
+	tags        map[string]string // Empty matches every notification
+	fallbacks   []domain.Fallback
+}
+
+// WithFailoverConfig gives notifications that don't set fallbacks of their own the chain of
+// the first failover rule matching their type, account, priority and tags
+func (s *NotificationService) WithFailoverConfig(cfg config.FailoverConfig) error {
+	rules := make([]failoverRule, 0, len(cfg.Rules))
+	for _, ruleCfg := range cfg.Rules {
+		minPriority := domain.PriorityLow
+		if ruleCfg.MinPriority != "" {
+			priority, err := domain.ParsePriority(ruleCfg.MinPriority)
+			if err != nil {
+				return err
+			}
+			minPriority = priority
+		}
+
+		chain := ruleCfg.Chain()
+		if err := domain.ValidateFallbacks(chain); err != nil {
+			return fmt.Errorf("invalid failover rule for %s: %w", ruleCfg.Type, err)
+		}
+		rules = append(rules, failoverRule{
+			notifType:   domain.NotificationType(ruleCfg.Type),
+			account:     ruleCfg.Account,
+			minPriority: minPriority,
+			tags:        maps.Clone(ruleCfg.Tags),
+			fallbacks:   chain,
+		})
+	}
+
+	s.failoverRules = rules
+	return nil
+}
+
+// applyFailoverRules gives notifications without fallbacks of their own the chain of the
+// first failover rule that matches them
+func (s *NotificationService) applyFailoverRules(notifications ...*domain.Notification) {
+	if len(s.failoverRules) == 0 {
+		return
+	}
+
+	for _, notification := range notifications {
+		if len(notification.Fallbacks) > 0 {
+			continue
+		}
+		account := s.resolveAccount(notification)
+		for _, rule := range s.failoverRules {
+			if rule.notifType == notification.Type && (rule.account == "" || rule.account == account) && notification.Priority >= rule.minPriority &&
+				notification.HasTags(rule.tags) {
+				notification.Fallbacks = slices.Clone(rule.fallbacks)
+				break
+			}
+		}
+	}
+}
+
+// sendFallback sends a notification that has failed permanently through the next channel of
+// its fallback chain, as a new notification linked to the failed one
+func (s *NotificationService) sendFallback(ctx context.Context, notification *domain.Notification) {
+	if len(notification.Fallbacks) == 0 {
+		return
+	}
+
+	fallback := newFallback(notification, time.Now())
+	if fallback.Account == "" {
+		fallback.Account = s.tenantAccount(fallback.Tenant, fallback.Type)
+	}
+	if fallback.Account == "" {
+		fallback.Account = s.priorityAccount(fallback.Type, fallback.Priority)
+	}
+
+	// Render the template again, so the fallback gets its channel's variant
+	if err := s.renderTemplates(ctx, fallback); err != nil {
+		s.logger.Errorf("Failed to render fallback notification - id=%s, type=%s, fallback_type=%s, error=%v",
+			notification.ID, notification.Type, fallback.Type, err)
+		return
+	}
+	if err := s.resolveContacts(ctx, fallback); err != nil {
+		s.logger.Errorf("Failed to resolve fallback contacts - id=%s, type=%s, fallback_type=%s, error=%v",
+			notification.ID, notification.Type, fallback.Type, err)
+		return
+	}
+	if err := s.storeNotification(fallback); err != nil {
+		s.logger.Errorf("Failed to store fallback notification - id=%s, type=%s, fallback_type=%s, error=%v",
+			notification.ID, notification.Type, fallback.Type, err)
+		return
+	}
+	s.markQueued(fallback)
+	if err := s.enqueue(ctx, fallback); err != nil {
+		fallback.Status = domain.StatusFailed
+		fallback.LastError = fmt.Sprintf("failed to enqueue: %v", err)
+		s.updateNotification(fallback)
+		s.logger.Errorf("Failed to enqueue fallback notification - id=%s, fallback=%s, error=%v",
+			notification.ID, fallback.ID, err)
+		return
+	}
+
+	notification.FallbackID = fallback.ID
+	s.logger.Infof("Notification failed, falling back - id=%s, type=%s, fallback=%s, fallback_type=%s, fallbacks_left=%d",
+		notification.ID, notification.Type, fallback.ID, fallback.Type, len(fallback.Fallbacks))
+}
+
+// newFallback copies a failed notification into a new notification for the next channel of
+// its fallback chain. Options, CC, BCC and the reply-to address only carry over to another
+// account of the same type, as other channels don't understand them.
+func newFallback(notification *domain.Notification, now time.Time) *domain.Notification {
+	next := notification.Fallbacks[0]
+
+	fallback := *notification
+	fallback.ID = uuid.New().String()
+	fallback.Type = next.Type
+	fallback.Account = next.Account
+	fallback.Recipients = slices.Clone(next.Recipients)
+	fallback.Contacts = slices.Clone(next.Contacts)
+	fallback.Fallbacks = slices.Clone(notification.Fallbacks[1:])
+	fallback.FallbackOf = notification.ID
+	fallback.FallbackID = ""
+	fallback.Status = domain.StatusPending
+	fallback.CreatedAt = now
+	fallback.ScheduledFor = nil
+	fallback.NextAttemptAt = nil
+	fallback.SentAt = nil
+	fallback.RetryCount = 0
+	fallback.LastError = ""
+	fallback.DeadlineMissed = false
+	fallback.Links = nil
+	fallback.Metadata = maps.Clone(notification.Metadata) // Sending may add to it
+
+	if next.Type != notification.Type {
+		fallback.Options = nil
+		fallback.CC = nil
+		fallback.BCC = nil
+		fallback.ReplyTo = ""
+	}
+	return &fallback
+}
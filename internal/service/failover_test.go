@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// TestFailoverChain tests that a notification failing permanently is sent through the next
+// channel of the chain its failover rule gives it, and that the chain ends after the last one
+func TestFailoverChain(t *testing.T) {
+	slack := &fakeNotifier{fail: true}
+	ntfy := &fakeNotifier{fail: true}
+	email := &fakeNotifier{}
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeSlack, "", slack)
+	factory.RegisterNotifier(domain.TypeNtfy, "", ntfy)
+	factory.RegisterNotifier(domain.TypeEmail, "", email)
+
+	q, _ := queue.NewLocalQueue(nil)
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+	err := svc.WithFailoverConfig(config.FailoverConfig{Rules: []config.FailoverRuleConfig{
+		{Type: "slack", MinPriority: "high", Fallbacks: []config.FallbackConfig{
+			{Type: "ntfy", Recipients: []string{"alerts"}},
+			{Type: "email", Recipients: []string{"oncall@example.com"}},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("WithFailoverConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	low := &domain.Notification{ID: "low", Type: domain.TypeSlack, Priority: domain.PriorityLow, Body: "disk at 80%", Recipients: []string{"#ops"}, MaxRetries: 1}
+	if _, err := svc.Send(ctx, low); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(low.Fallbacks) != 0 {
+		t.Errorf("Expected no fallbacks below the rule's priority, got %v", low.Fallbacks)
+	}
+
+	alert := &domain.Notification{
+		ID:         "alert",
+		Type:       domain.TypeSlack,
+		Priority:   domain.PriorityCritical,
+		Subject:    "Disk full",
+		Body:       "db-1 is out of disk",
+		Recipients: []string{"#ops"},
+		Options:    &domain.SendOptions{Slack: &domain.SlackOptions{Icon: ":fire:"}},
+		MaxRetries: 1,
+	}
+	if _, err := svc.Send(ctx, alert); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(alert.Fallbacks) != 2 {
+		t.Fatalf("Expected the rule's two fallbacks, got %v", alert.Fallbacks)
+	}
+
+	// Each failure moves the notification on to the next channel
+	current := alert
+	for _, want := range []domain.NotificationType{domain.TypeNtfy, domain.TypeEmail} {
+		svc.processNotification(ctx, &domain.QueueMessage{ID: current.ID, Notification: current})
+		if current.Status != domain.StatusFailed || current.FallbackID == "" {
+			t.Fatalf("Expected %s to fail and fall back, got status=%s fallback=%q", current.Type, current.Status, current.FallbackID)
+		}
+
+		fallback, err := svc.GetNotification(ctx, current.FallbackID)
+		if err != nil {
+			t.Fatalf("GetNotification() error = %v", err)
+		}
+		if fallback.Type != want || fallback.FallbackOf != current.ID || fallback.Body != alert.Body || fallback.RetryCount != 0 {
+			t.Fatalf("Expected a fresh %s copy of %s, got %+v", want, current.ID, fallback)
+		}
+		if fallback.Options != nil {
+			t.Errorf("Expected the Slack options to be dropped on %s, got %+v", want, fallback.Options)
+		}
+		current = fallback
+	}
+
+	if current.Recipients[0] != "oncall@example.com" || len(current.Fallbacks) != 0 {
+		t.Errorf("Expected the last fallback to email on-call with nothing left to try, got %v %v", current.Recipients, current.Fallbacks)
+	}
+	svc.processNotification(ctx, &domain.QueueMessage{ID: current.ID, Notification: current})
+	if current.Status != domain.StatusSent {
+		t.Errorf("Expected the email fallback to be sent, got status=%s", current.Status)
+	}
+	if calls := atomic.LoadInt32(&slack.calls) + atomic.LoadInt32(&ntfy.calls) + atomic.LoadInt32(&email.calls); calls != 3 {
+		t.Errorf("Expected one attempt per channel, got %d", calls)
+	}
+}
+
+// TestNewFallbackKeepsSameTypeOptions tests that options and email addressing carry over to
+// another account of the same type, and that the caller's own chain wins over the rules
+func TestNewFallbackKeepsSameTypeOptions(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithFailoverConfig(config.FailoverConfig{Rules: []config.FailoverRuleConfig{
+		{Type: "email", Fallbacks: []config.FallbackConfig{{Type: "ntfy", Recipients: []string{"alerts"}}}},
+	}})
+	if err != nil {
+		t.Fatalf("WithFailoverConfig() error = %v", err)
+	}
+
+	notification := &domain.Notification{
+		ID:         "n1",
+		Type:       domain.TypeEmail,
+		Account:    "primary",
+		CC:         []string{"team@example.com"},
+		Options:    &domain.SendOptions{Email: &domain.EmailOptions{ReplyTo: "support@example.com"}},
+		RetryCount: 3,
+		Fallbacks:  []domain.Fallback{{Type: domain.TypeEmail, Account: "backup", Recipients: []string{"user@example.com"}}},
+	}
+	svc.applyFailoverRules(notification)
+	if len(notification.Fallbacks) != 1 || notification.Fallbacks[0].Account != "backup" {
+		t.Fatalf("Expected the notification's own fallbacks to be kept, got %v", notification.Fallbacks)
+	}
+
+	fallback := newFallback(notification, time.Now())
+	if fallback.Account != "backup" || fallback.Options == nil || len(fallback.CC) != 1 || fallback.RetryCount != 0 {
+		t.Errorf("Expected a fresh copy for the backup account with its options and CC, got %+v", fallback)
+	}
+}
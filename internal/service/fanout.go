@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// sendFanout sends a notification through each of its targets, as one notification per target
+// sharing the fanned-out notification's ID as their FanoutID. Every target goes through the
+// whole send pipeline, so one rejected target doesn't stop the others; an error is only
+// returned when none of them were accepted.
+func (s *NotificationService) sendFanout(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+
+	err := domain.ValidateTargets(notification.Targets, len(notification.Recipients)+len(notification.Contacts)+len(notification.CC)+len(notification.BCC) > 0)
+	if err == nil && notification.Recurrence != "" {
+		err = fmt.Errorf("%w: recurring notifications can't be fanned out", domain.ErrInvalidTargets)
+	}
+	if err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// The targets share the fanned-out notification's dedup key, so it's claimed once for all of them
+	if result := s.suppressDuplicate(notification); result != nil {
+		return result, nil
+	}
+
+	result := &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Targets:        make([]domain.TargetResult, 0, len(notification.Targets)),
+	}
+	var firstErr error
+	accepted := 0
+	for _, target := range notification.Targets {
+		targetNotification := newTargetNotification(notification, target)
+		targetResult, err := s.Send(ctx, targetNotification)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if err == nil {
+			accepted++
+		}
+
+		outcome := domain.TargetResult{
+			Type:           targetNotification.Type,
+			Account:        targetNotification.Account,
+			NotificationID: targetNotification.ID,
+		}
+		if targetResult != nil {
+			outcome.Success = targetResult.Success
+			outcome.Message = targetResult.Message
+			outcome.Error = targetResult.Error
+		}
+		result.Targets = append(result.Targets, outcome)
+	}
+
+	result.SentAt = time.Now()
+	result.Success = accepted == len(notification.Targets)
+	result.Message = fmt.Sprintf("accepted for %d of %d targets", accepted, len(notification.Targets))
+	if accepted == 0 {
+		s.releaseDedup(notification)
+		result.Error = firstErr.Error()
+		return result, firstErr
+	}
+	if !result.Success {
+		result.Error = fmt.Sprintf("%d of %d targets were rejected", len(notification.Targets)-accepted, len(notification.Targets))
+	}
+
+	s.logger.Infof("Notification fanned out - id=%s, targets=%d, accepted=%d, origin=%s",
+		notification.ID, len(notification.Targets), accepted, notification.Origin.StatsKey())
+	return result, nil
+}
+
+// newTargetNotification copies a fanned-out notification for one of its targets. Only the
+// options block for the target's channel carries over, and CC, BCC and the reply-to address
+// only carry over to email.
+func newTargetNotification(notification *domain.Notification, target domain.Target) *domain.Notification {
+	targetNotification := *notification
+	targetNotification.ID = uuid.New().String()
+	targetNotification.Type = target.Type
+	targetNotification.Account = target.Account
+	targetNotification.Targets = nil
+	targetNotification.FanoutID = notification.ID
+	targetNotification.Status = domain.StatusPending
+	targetNotification.Recipients = slices.Clone(notification.Recipients)
+	targetNotification.Contacts = slices.Clone(notification.Contacts)
+	targetNotification.Options = notification.Options.ForType(target.Type)
+	targetNotification.Metadata = maps.Clone(notification.Metadata) // Sending may add to it
+	targetNotification.Fallbacks = slices.Clone(notification.Fallbacks)
+
+	if len(target.Recipients) > 0 || len(target.Contacts) > 0 {
+		targetNotification.Recipients = slices.Clone(target.Recipients)
+		targetNotification.Contacts = slices.Clone(target.Contacts)
+	}
+	if target.Type != domain.TypeEmail && target.Type != domain.TypePostmark {
+		targetNotification.CC = nil
+		targetNotification.BCC = nil
+		targetNotification.ReplyTo = ""
+	}
+	return &targetNotification
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSendFanout tests that a notification with targets is sent as one notification per
+// target sharing its ID, with a result for each target, and that one rejected target doesn't
+// stop the rest
+func TestSendFanout(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.WithDedupConfig(config.DedupConfig{Enabled: true, Window: "1m"}); err != nil {
+		t.Fatalf("WithDedupConfig() error = %v", err)
+	}
+
+	notification := &domain.Notification{
+		ID:         "outage",
+		Subject:    "Outage",
+		Body:       "API is down",
+		Recipients: []string{"ops"},
+		CC:         []string{"cto@example.com"},
+		DedupKey:   "api-down",
+		Options:    &domain.SendOptions{Ntfy: &domain.NtfyOptions{Tags: []string{"warning"}}},
+		Targets: []domain.Target{
+			{Type: domain.TypeStdout},
+			{Type: domain.TypeStdout, Account: "audit", Recipients: []string{"audit-log"}},
+			{Type: domain.TypeNtfy, Contacts: []string{"nobody"}},
+		},
+	}
+	result, err := svc.Send(ctx, notification)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.NotificationID != "outage" || result.Success || len(result.Targets) != 3 {
+		t.Fatalf("Expected a partial success with three target results, got %+v", result)
+	}
+	if !result.Targets[0].Success || !result.Targets[1].Success || result.Targets[2].Success || result.Targets[2].Error == "" {
+		t.Errorf("Expected only the ntfy target to be rejected, got %+v", result.Targets)
+	}
+
+	page, err := svc.ListNotifications(ctx, &domain.NotificationFilter{FanoutID: "outage"})
+	if err != nil {
+		t.Fatalf("ListNotifications() error = %v", err)
+	}
+	if len(page.Notifications) != 2 {
+		t.Fatalf("Expected the two accepted targets to be stored, got %d", len(page.Notifications))
+	}
+	audit, err := svc.GetNotification(ctx, result.Targets[1].NotificationID)
+	if err != nil {
+		t.Fatalf("GetNotification() error = %v", err)
+	}
+	if audit.Account != "audit" || len(audit.Recipients) != 1 || audit.Recipients[0] != "audit-log" ||
+		audit.Options != nil || audit.CC != nil || audit.Subject != "Outage" {
+		t.Errorf("Unexpected target notification: %+v", audit)
+	}
+
+	// The targets share the dedup key, so a repeat is suppressed as a whole
+	repeat, err := svc.Send(ctx, &domain.Notification{ID: "repeat", Body: "API is down", Recipients: []string{"ops"}, DedupKey: "api-down",
+		Targets: []domain.Target{{Type: domain.TypeStdout}}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if !repeat.Suppressed || repeat.NotificationID != "outage" {
+		t.Errorf("Expected the repeat to be suppressed, got %+v", repeat)
+	}
+}
+
+// TestSendFanoutRejected tests that a fan-out fails when no target is accepted or its targets
+// are invalid
+func TestSendFanoutRejected(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Send(ctx, &domain.Notification{Body: "x", Targets: []domain.Target{{Type: domain.TypeStdout, Contacts: []string{"nobody"}}}})
+	if !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected the target's error, got %v", err)
+	}
+
+	_, err = svc.Send(ctx, &domain.Notification{Body: "x", Recipients: []string{"ops"}, Targets: []domain.Target{{Type: domain.TypeStdout}, {Type: domain.TypeStdout}}})
+	if !errors.Is(err, domain.ErrInvalidTargets) {
+		t.Errorf("Expected ErrInvalidTargets for a repeated target, got %v", err)
+	}
+
+	_, err = svc.SendBatch(ctx, []*domain.Notification{{Body: "x", Recipients: []string{"ops"}, Targets: []domain.Target{{Type: domain.TypeStdout}}}})
+	if !errors.Is(err, domain.ErrInvalidTargets) {
+		t.Errorf("Expected ErrInvalidTargets in a batch, got %v", err)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// heartbeatMonitor tracks the pings of the configured heartbeats
+type heartbeatMonitor struct {
+	mu    sync.Mutex
+	beats map[string]*heartbeat
+}
+
+// heartbeat is the state of one monitored producer
+type heartbeat struct {
+	name      string
+	period    time.Duration
+	grace     time.Duration
+	alert     config.AlertTargetConfig
+	since     time.Time // When monitoring started, the deadline base until the first ping
+	lastPing  time.Time
+	pings     int
+	down      bool
+	downSince time.Time
+}
+
+// deadline returns when the heartbeat is missed without another ping
+func (h *heartbeat) deadline() time.Time {
+	base := h.since
+	if !h.lastPing.IsZero() {
+		base = h.lastPing
+	}
+	return base.Add(h.period + h.grace)
+}
+
+// snapshot returns the heartbeat's status
+func (h *heartbeat) snapshot() *domain.Heartbeat {
+	info := &domain.Heartbeat{
+		Name:     h.name,
+		Status:   domain.HeartbeatNew,
+		Period:   h.period.String(),
+		Grace:    h.grace.String(),
+		Pings:    h.pings,
+		Deadline: h.deadline(),
+	}
+	if !h.lastPing.IsZero() {
+		lastPing := h.lastPing
+		info.LastPing = &lastPing
+		info.Status = domain.HeartbeatUp
+	}
+	if h.down {
+		downSince := h.downSince
+		info.DownSince = &downSince
+		info.Status = domain.HeartbeatDown
+	}
+	return info
+}
+
+// newHeartbeatMonitor creates a monitor for the configured heartbeats, starting their first period now
+func newHeartbeatMonitor(cfg config.HeartbeatsConfig, now time.Time) (*heartbeatMonitor, error) {
+	monitor := &heartbeatMonitor{beats: make(map[string]*heartbeat, len(cfg.Checks))}
+	for _, check := range cfg.Checks {
+		period, err := time.ParseDuration(check.Period)
+		if err != nil {
+			return nil, fmt.Errorf("heartbeat %s: invalid period: %w", check.Name, err)
+		}
+		var grace time.Duration
+		if check.Grace != "" {
+			if grace, err = time.ParseDuration(check.Grace); err != nil {
+				return nil, fmt.Errorf("heartbeat %s: invalid grace: %w", check.Name, err)
+			}
+		}
+
+		alert := check.Alert
+		if !alert.Enabled() {
+			alert = cfg.Alert
+		}
+		monitor.beats[check.Name] = &heartbeat{
+			name:   check.Name,
+			period: period,
+			grace:  grace,
+			alert:  alert,
+			since:  now,
+		}
+	}
+	return monitor, nil
+}
+
+// WithHeartbeatsConfig enables heartbeat monitoring
+func (s *NotificationService) WithHeartbeatsConfig(cfg config.HeartbeatsConfig) error {
+	if !cfg.Enabled {
+		s.heartbeats = nil
+		return nil
+	}
+
+	checkFreq, err := time.ParseDuration(cfg.CheckFrequency)
+	if err != nil {
+		return fmt.Errorf("invalid check frequency duration: %w", err)
+	}
+
+	monitor, err := newHeartbeatMonitor(cfg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	s.heartbeats = monitor
+	s.heartbeatCheckFrequency = checkFreq
+
+	return nil
+}
+
+// heartbeatLoop periodically checks heartbeats for missed pings
+func (s *NotificationService) heartbeatLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.heartbeatCheckFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkHeartbeats(ctx, time.Now())
+		}
+	}
+}
+
+// checkHeartbeats marks heartbeats past their deadline as down and alerts once for each
+func (s *NotificationService) checkHeartbeats(ctx context.Context, now time.Time) {
+	var missed []heartbeat
+	s.heartbeats.mu.Lock()
+	for _, beat := range s.heartbeats.beats {
+		if beat.down || !now.After(beat.deadline()) {
+			continue
+		}
+		beat.down = true
+		beat.downSince = now
+		missed = append(missed, *beat)
+	}
+	s.heartbeats.mu.Unlock()
+
+	sort.Slice(missed, func(i, j int) bool { return missed[i].name < missed[j].name })
+	for _, beat := range missed {
+		s.logger.Warnf("Heartbeat missed - name=%s, deadline=%s", beat.name, beat.deadline().Format(time.RFC3339))
+
+		subject := fmt.Sprintf("Heartbeat missed: %s", beat.name)
+		body := fmt.Sprintf("%s has not pinged since monitoring started at %s (expected every %s, grace %s).",
+			beat.name, beat.since.Format(time.RFC3339), beat.period, beat.grace)
+		if !beat.lastPing.IsZero() {
+			body = fmt.Sprintf("%s last pinged at %s (expected every %s, grace %s).",
+				beat.name, beat.lastPing.Format(time.RFC3339), beat.period, beat.grace)
+		}
+		if err := s.sendOperationalAlert(ctx, beat.alert, subject, body, map[string]interface{}{"heartbeat": beat.name}); err != nil {
+			s.logger.Errorf("Failed to send heartbeat alert - name=%s, error=%v", beat.name, err)
+		}
+	}
+}
+
+// PingHeartbeat implements domain.HeartbeatMonitor. A ping to a heartbeat that was down
+// sends a recovery alert.
+func (s *NotificationService) PingHeartbeat(ctx context.Context, name string) (*domain.Heartbeat, error) {
+	if s.heartbeats == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrHeartbeatNotFound, name)
+	}
+
+	now := time.Now()
+	s.heartbeats.mu.Lock()
+	beat, ok := s.heartbeats.beats[name]
+	if !ok {
+		s.heartbeats.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", domain.ErrHeartbeatNotFound, name)
+	}
+	recovered := beat.down
+	downSince := beat.downSince
+	beat.lastPing = now
+	beat.pings++
+	beat.down = false
+	info := beat.snapshot()
+	alert := beat.alert
+	s.heartbeats.mu.Unlock()
+
+	if recovered {
+		s.logger.Infof("Heartbeat recovered - name=%s, down_for=%s", name, now.Sub(downSince).Round(time.Second))
+
+		subject := fmt.Sprintf("Heartbeat recovered: %s", name)
+		body := fmt.Sprintf("%s pinged again after being down for %s.", name, now.Sub(downSince).Round(time.Second))
+		if err := s.sendOperationalAlert(context.WithoutCancel(ctx), alert, subject, body, map[string]interface{}{"heartbeat": name}); err != nil {
+			s.logger.Errorf("Failed to send heartbeat recovery alert - name=%s, error=%v", name, err)
+		}
+	}
+
+	return info, nil
+}
+
+// GetHeartbeat implements domain.HeartbeatMonitor
+func (s *NotificationService) GetHeartbeat(ctx context.Context, name string) (*domain.Heartbeat, error) {
+	if s.heartbeats == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrHeartbeatNotFound, name)
+	}
+
+	s.heartbeats.mu.Lock()
+	defer s.heartbeats.mu.Unlock()
+
+	beat, ok := s.heartbeats.beats[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrHeartbeatNotFound, name)
+	}
+	return beat.snapshot(), nil
+}
+
+// ListHeartbeats implements domain.HeartbeatMonitor
+func (s *NotificationService) ListHeartbeats(ctx context.Context) ([]*domain.Heartbeat, error) {
+	if s.heartbeats == nil {
+		return []*domain.Heartbeat{}, nil
+	}
+
+	s.heartbeats.mu.Lock()
+	defer s.heartbeats.mu.Unlock()
+
+	results := make([]*domain.Heartbeat, 0, len(s.heartbeats.beats))
+	for _, beat := range s.heartbeats.beats {
+		results = append(results, beat.snapshot())
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestHeartbeatMissedAndRecovered tests a missed heartbeat alerts once and a ping recovers it
+func TestHeartbeatMissedAndRecovered(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithHeartbeatsConfig(config.HeartbeatsConfig{
+		Enabled:        true,
+		CheckFrequency: "30s",
+		Alert:          config.AlertTargetConfig{Type: "stdout", Recipients: []string{"ops"}},
+		Checks: []config.HeartbeatCheckConfig{
+			{Name: "nightly-backup", Period: "1h", Grace: "10m"},
+			{Name: "cron.cleanup", Period: "24h"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithHeartbeatsConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	alerts := func() int {
+		page, err := svc.ListNotifications(ctx, &domain.NotificationFilter{Types: []domain.NotificationType{domain.TypeStdout}})
+		if err != nil {
+			t.Fatalf("ListNotifications() error = %v", err)
+		}
+		return len(page.Notifications)
+	}
+
+	beat, err := svc.GetHeartbeat(ctx, "nightly-backup")
+	if err != nil || beat.Status != domain.HeartbeatNew {
+		t.Fatalf("Expected a new heartbeat, got %+v (err %v)", beat, err)
+	}
+
+	// Within the period and grace nothing is missed
+	svc.checkHeartbeats(ctx, time.Now().Add(65*time.Minute))
+	if n := alerts(); n != 0 {
+		t.Fatalf("Expected no alerts within grace, got %d", n)
+	}
+
+	later := time.Now().Add(2 * time.Hour)
+	svc.checkHeartbeats(ctx, later)
+	svc.checkHeartbeats(ctx, later.Add(time.Minute))
+	if n := alerts(); n != 1 {
+		t.Fatalf("Expected one alert for the missed heartbeat, got %d", n)
+	}
+	if beat, _ = svc.GetHeartbeat(ctx, "nightly-backup"); beat.Status != domain.HeartbeatDown || beat.DownSince == nil {
+		t.Errorf("Expected nightly-backup to be down, got %+v", beat)
+	}
+
+	beat, err = svc.PingHeartbeat(ctx, "nightly-backup")
+	if err != nil {
+		t.Fatalf("PingHeartbeat() error = %v", err)
+	}
+	if beat.Status != domain.HeartbeatUp || beat.Pings != 1 || beat.LastPing == nil {
+		t.Errorf("Expected nightly-backup to be up after a ping, got %+v", beat)
+	}
+	if n := alerts(); n != 2 {
+		t.Errorf("Expected a recovery alert, got %d alerts", n)
+	}
+
+	beats, err := svc.ListHeartbeats(ctx)
+	if err != nil || len(beats) != 2 || beats[0].Name != "cron.cleanup" {
+		t.Errorf("Unexpected heartbeats: %+v (err %v)", beats, err)
+	}
+
+	if _, err := svc.PingHeartbeat(ctx, "unknown"); !errors.Is(err, domain.ErrHeartbeatNotFound) {
+		t.Errorf("Expected ErrHeartbeatNotFound, got %v", err)
+	}
+}
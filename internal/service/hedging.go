@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// hedgeRule is a parsed hedging rule
+type hedgeRule struct {
+	secondary string
+	delay     time.Duration
+}
+
+// hedgeAttempt is the outcome of a send through one account
+type hedgeAttempt struct {
+	account string
+	result  *domain.NotificationResult
+	err     error
+}
+
+// succeeded reports whether the attempt delivered the notification
+func (a hedgeAttempt) succeeded() bool {
+	return a.err == nil && a.result != nil && a.result.Success
+}
+
+// WithHedgingConfig enables request hedging between accounts of the same type
+func (s *NotificationService) WithHedgingConfig(cfg config.HedgingConfig) error {
+	rules, minPriority, err := parseHedgeRules(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.hedgeRules = rules
+	s.hedgeMinPriority = minPriority
+	s.hedgingConfig = cfg
+
+	return nil
+}
+
+// parseHedgeRules compiles the hedging configuration into rules keyed by type/account, returning
+// nil rules if hedging is disabled
+func parseHedgeRules(cfg config.HedgingConfig) (map[string]hedgeRule, domain.Priority, error) {
+	if !cfg.Enabled {
+		return nil, 0, nil
+	}
+
+	minPriority := domain.PriorityCritical
+	if cfg.MinPriority != "" {
+		priority, err := domain.ParsePriority(cfg.MinPriority)
+		if err != nil {
+			return nil, 0, err
+		}
+		minPriority = priority
+	}
+
+	rules := make(map[string]hedgeRule, len(cfg.Rules))
+	for _, ruleCfg := range cfg.Rules {
+		delay, err := time.ParseDuration(ruleCfg.Delay)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid delay for %s/%s: %w", ruleCfg.Type, ruleCfg.Account, err)
+		}
+		rules[ruleCfg.Type+"/"+ruleCfg.Account] = hedgeRule{secondary: ruleCfg.Secondary, delay: delay}
+	}
+
+	return rules, minPriority, nil
+}
+
+// hedgeRuleFor returns the hedging rule that applies to a notification sent through account
+func (s *NotificationService) hedgeRuleFor(notification *domain.Notification, account string) (hedgeRule, bool) {
+	return matchHedgeRule(s.hedgeRules, s.hedgeMinPriority, notification, account)
+}
+
+// matchHedgeRule returns the rule among rules that applies to a notification sent through account
+func matchHedgeRule(rules map[string]hedgeRule, minPriority domain.Priority, notification *domain.Notification, account string) (hedgeRule, bool) {
+	if rules == nil || notification.Priority < minPriority {
+		return hedgeRule{}, false
+	}
+	rule, ok := rules[string(notification.Type)+"/"+account]
+	return rule, ok
+}
+
+// sendHedged sends through the primary notifier and, if it hasn't succeeded within the rule's
+// delay (or has already failed), also through the secondary account. The first success wins and
+// the other attempt is cancelled; if both fail, the primary's failure is returned.
+func (s *NotificationService) sendHedged(ctx context.Context, primary domain.Notifier, notification *domain.Notification, account string, rule hedgeRule) (*domain.NotificationResult, error) {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	attempts := make(chan hedgeAttempt, 2)
+	go func() {
+		result, err := primary.Send(hedgeCtx, notification)
+		attempts <- hedgeAttempt{account: account, result: result, err: err}
+	}()
+
+	timer := time.NewTimer(rule.delay)
+	defer timer.Stop()
+
+	var primaryAttempt *hedgeAttempt
+	select {
+	case attempt := <-attempts:
+		if attempt.succeeded() {
+			return attempt.result, attempt.err
+		}
+		primaryAttempt = &attempt
+	case <-timer.C:
+	}
+
+	secondary, err := s.factory.Create(notification.Type, rule.secondary)
+	if err != nil {
+		s.logger.Warnf("Hedging unavailable, secondary account not found - id=%s, type=%s, secondary=%s, error=%v",
+			notification.ID, notification.Type, rule.secondary, err)
+		if primaryAttempt != nil {
+			return primaryAttempt.result, primaryAttempt.err
+		}
+		attempt := <-attempts
+		return attempt.result, attempt.err
+	}
+
+	s.logger.Infof("Hedging notification through secondary account - id=%s, type=%s, primary=%s, secondary=%s",
+		notification.ID, notification.Type, account, rule.secondary)
+
+	// Send a copy so the two notifiers never share mutable state
+	hedged := *notification
+	hedged.Account = rule.secondary
+	go func() {
+		result, err := secondary.Send(hedgeCtx, &hedged)
+		attempts <- hedgeAttempt{account: rule.secondary, result: result, err: err}
+	}()
+
+	pending := 2
+	if primaryAttempt != nil {
+		pending = 1
+	}
+
+	for ; pending > 0; pending-- {
+		attempt := <-attempts
+		if attempt.succeeded() {
+			return annotateHedge(attempt, account), nil
+		}
+		if attempt.account == account {
+			primaryAttempt = &attempt
+		}
+	}
+
+	return primaryAttempt.result, primaryAttempt.err
+}
+
+// annotateHedge records which account delivered a hedged notification
+func annotateHedge(attempt hedgeAttempt, primary string) *domain.NotificationResult {
+	result := *attempt.result
+	response := make(map[string]interface{}, len(result.ProviderResponse)+3)
+	for key, value := range result.ProviderResponse {
+		response[key] = value
+	}
+	response["hedged"] = true
+	response["hedge_primary"] = primary
+	response["delivered_by"] = attempt.account
+	result.ProviderResponse = response
+	return &result
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// fakeNotifier is a notifier with a configurable delay and outcome
+type fakeNotifier struct {
+	notifier.BaseNotifier
+	delay time.Duration
+	fail  bool
+	calls int32
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	atomic.AddInt32(&f.calls, 1)
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if f.fail {
+		return &domain.NotificationResult{NotificationID: notification.ID, Error: "send failed"}, errors.New("send failed")
+	}
+	return &domain.NotificationResult{NotificationID: notification.ID, Success: true}, nil
+}
+
+// TestSendHedged tests hedging between a primary and secondary account
+func TestSendHedged(t *testing.T) {
+	tests := []struct {
+		name           string
+		primary        *fakeNotifier
+		secondary      *fakeNotifier
+		success        bool
+		deliveredBy    string
+		secondaryCalls int32
+	}{
+		{
+			name:           "fast primary is not hedged",
+			primary:        &fakeNotifier{delay: time.Millisecond},
+			secondary:      &fakeNotifier{},
+			success:        true,
+			secondaryCalls: 0,
+		},
+		{
+			name:           "slow primary is hedged",
+			primary:        &fakeNotifier{delay: time.Second},
+			secondary:      &fakeNotifier{delay: time.Millisecond},
+			success:        true,
+			deliveredBy:    "backup",
+			secondaryCalls: 1,
+		},
+		{
+			name:           "failed primary falls over to secondary",
+			primary:        &fakeNotifier{fail: true},
+			secondary:      &fakeNotifier{},
+			success:        true,
+			deliveredBy:    "backup",
+			secondaryCalls: 1,
+		},
+		{
+			name:           "both fail",
+			primary:        &fakeNotifier{delay: 100 * time.Millisecond, fail: true},
+			secondary:      &fakeNotifier{fail: true},
+			success:        false,
+			secondaryCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := notifier.NewFactory()
+			factory.RegisterNotifier(domain.TypeEmail, "primary", tt.primary)
+			factory.RegisterNotifier(domain.TypeEmail, "backup", tt.secondary)
+
+			q, _ := queue.NewLocalQueue(nil)
+			logger, _ := logging.NewFromConfig("error", "stdout")
+			svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+
+			err := svc.WithHedgingConfig(config.HedgingConfig{
+				Enabled: true,
+				Rules: []config.HedgeRuleConfig{
+					{Type: "email", Account: "primary", Secondary: "backup", Delay: "20ms"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("WithHedgingConfig() error = %v", err)
+			}
+
+			notification := &domain.Notification{ID: "n1", Type: domain.TypeEmail, Priority: domain.PriorityCritical}
+			rule, ok := svc.hedgeRuleFor(notification, "primary")
+			if !ok {
+				t.Fatal("Expected hedging rule for critical notification")
+			}
+
+			result, err := svc.sendHedged(context.Background(), tt.primary, notification, "primary", rule)
+			success := err == nil && result != nil && result.Success
+			if success != tt.success {
+				t.Fatalf("success = %v, want %v (err=%v)", success, tt.success, err)
+			}
+			if tt.deliveredBy != "" && result.ProviderResponse["delivered_by"] != tt.deliveredBy {
+				t.Errorf("delivered_by = %v, want %s", result.ProviderResponse["delivered_by"], tt.deliveredBy)
+			}
+			if calls := atomic.LoadInt32(&tt.secondary.calls); calls != tt.secondaryCalls {
+				t.Errorf("secondary calls = %d, want %d", calls, tt.secondaryCalls)
+			}
+		})
+	}
+}
+
+// TestHedgeRuleForPriority tests only urgent notifications are hedged
+func TestHedgeRuleForPriority(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithHedgingConfig(config.HedgingConfig{
+		Enabled:     true,
+		MinPriority: "high",
+		Rules: []config.HedgeRuleConfig{
+			{Type: "email", Account: "primary", Secondary: "backup", Delay: "1s"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithHedgingConfig() error = %v", err)
+	}
+
+	if _, ok := svc.hedgeRuleFor(&domain.Notification{Type: domain.TypeEmail, Priority: domain.PriorityNormal}, "primary"); ok {
+		t.Error("Expected normal priority notification not to be hedged")
+	}
+	if _, ok := svc.hedgeRuleFor(&domain.Notification{Type: domain.TypeEmail, Priority: domain.PriorityHigh}, "primary"); !ok {
+		t.Error("Expected high priority notification to be hedged")
+	}
+	if _, ok := svc.hedgeRuleFor(&domain.Notification{Type: domain.TypeEmail, Priority: domain.PriorityHigh}, "other"); ok {
+		t.Error("Expected notification for an account without a rule not to be hedged")
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Send job defaults and limits
+const (
+	defaultJobMaxInFlight = 1000
+	maxFinishedJobs       = 100 // Finished jobs kept for polling before the oldest are forgotten
+)
+
+// jobState is where one of a job's notifications stands, for progress counting
+type jobState int
+
+const (
+	jobStateQueued jobState = iota + 1
+	jobStateHeld
+	jobStateFailed
+	jobStateSent
+)
+
+// sendJobs tracks the service's send jobs
+type sendJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*sendJob
+}
+
+// newSendJobs creates an empty job registry
+func newSendJobs() *sendJobs {
+	return &sendJobs{jobs: make(map[string]*sendJob)}
+}
+
+// sendJob is a running or finished send job. Its mutex guards every field; cond is signalled
+// whenever the job is paused, resumed, cancelled or one of its notifications finishes.
+type sendJob struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	info       domain.SendJob
+	template   domain.Notification
+	recipients []string
+	next       int
+	states     map[string]jobState // Notifications not yet sent; sent ones are only counted
+	startedAt  time.Time
+	cancel     context.CancelFunc
+}
+
+// inFlight returns how many of the job's notifications are queued but not finished
+func (j *sendJob) inFlight() int {
+	return j.info.Enqueued - j.info.Sent - j.info.Failed - j.info.Held
+}
+
+// finished reports whether the job has stopped for good
+func (j *sendJob) finished() bool {
+	return j.info.Status == domain.JobCompleted || j.info.Status == domain.JobCancelled
+}
+
+// move records a notification's transition to a new state, keeping the counters in step
+func (j *sendJob) move(id string, to jobState) {
+	switch j.states[id] {
+	case jobStateHeld:
+		j.info.Held--
+	case jobStateFailed:
+		j.info.Failed--
+	}
+	switch to {
+	case jobStateHeld:
+		j.info.Held++
+	case jobStateFailed:
+		j.info.Failed++
+	case jobStateSent:
+		j.info.Sent++
+	}
+
+	if to == jobStateSent {
+		delete(j.states, id)
+	} else {
+		j.states[id] = to
+	}
+}
+
+// snapshot returns a copy of the job's progress with a projected completion time
+func (j *sendJob) snapshot(now time.Time) *domain.SendJob {
+	info := j.info
+	done := info.Sent + info.Failed
+	if info.Status == domain.JobRunning && done > 0 {
+		remaining := info.Total - done - info.Held - info.Rejected
+		elapsed := now.Sub(j.startedAt)
+		eta := now.Add(time.Duration(float64(elapsed) * float64(remaining) / float64(done)))
+		info.EstimatedAt = &eta
+	}
+	return &info
+}
+
+// SubmitSendJob implements domain.SendJobRunner. The job enqueues its notifications through
+// Send, so authorization, content policy, spam checks and budgets apply to each of them.
+func (s *NotificationService) SubmitSendJob(ctx context.Context, template *domain.Notification, recipients []string, opts domain.SendJobOptions) (*domain.SendJob, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	if opts.RecipientsPerNotification <= 0 {
+		opts.RecipientsPerNotification = 1
+	}
+	if opts.MaxInFlight <= 0 {
+		opts.MaxInFlight = defaultJobMaxInFlight
+	}
+
+	// Fail fast on a template the caller isn't allowed to send, rather than rejecting every notification
+	stampTenant(ctx, template)
+	s.stampOrigin(ctx, template)
+	if err := s.checkAuthorization(ctx, template); err != nil {
+		return nil, err
+	}
+	if err := s.checkLimits(template); err != nil {
+		return nil, err
+	}
+	if err := checkLimit(domain.LimitRecipients, s.limits.MaxRecipients, min(opts.RecipientsPerNotification, len(recipients))+len(template.CC)+len(template.BCC)); err != nil {
+		return nil, err
+	}
+	if err := s.checkStrict(ctx, template); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &sendJob{
+		info: domain.SendJob{
+			ID:         uuid.New().String(),
+			Status:     domain.JobRunning,
+			Type:       template.Type,
+			Account:    template.Account,
+			Origin:     template.Origin,
+			Tenant:     template.Tenant,
+			Options:    opts,
+			Recipients: len(recipients),
+			Total:      (len(recipients) + opts.RecipientsPerNotification - 1) / opts.RecipientsPerNotification,
+			CreatedAt:  now,
+		},
+		template:   *template,
+		recipients: recipients,
+		states:     make(map[string]jobState),
+		startedAt:  now,
+	}
+	job.cond = sync.NewCond(&job.mu)
+
+	// The job outlives the request that submitted it but keeps its auth context
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job.cancel = cancel
+
+	s.jobs.mu.Lock()
+	s.jobs.jobs[job.info.ID] = job
+	s.jobs.prune()
+	s.jobs.mu.Unlock()
+
+	s.logger.Infof("Send job submitted - id=%s, type=%s, account=%s, origin=%s, recipients=%d, notifications=%d",
+		job.info.ID, job.info.Type, job.info.Account, job.info.Origin.StatsKey(), job.info.Recipients, job.info.Total)
+
+	s.wg.Add(1)
+	go s.runSendJob(jobCtx, job)
+
+	return job.snapshot(now), nil
+}
+
+// prune forgets the oldest finished jobs beyond maxFinishedJobs. The caller must hold mu.
+func (r *sendJobs) prune() {
+	var finished []*sendJob
+	for _, job := range r.jobs {
+		job.mu.Lock()
+		if job.finished() {
+			finished = append(finished, job)
+		}
+		job.mu.Unlock()
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].info.CreatedAt.Before(finished[j].info.CreatedAt)
+	})
+	for _, job := range finished[:len(finished)-maxFinishedJobs] {
+		delete(r.jobs, job.info.ID)
+	}
+}
+
+// runSendJob enqueues a job's notifications, keeping at most MaxInFlight of them unfinished,
+// then waits for the last of them to finish
+func (s *NotificationService) runSendJob(ctx context.Context, job *sendJob) {
+	defer s.wg.Done()
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	for job.next < len(job.recipients) {
+		for (job.info.Status == domain.JobPaused || job.inFlight() >= job.info.Options.MaxInFlight) && ctx.Err() == nil {
+			job.cond.Wait()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		end := min(job.next+job.info.Options.RecipientsPerNotification, len(job.recipients))
+		notification := job.template
+		notification.ID = uuid.New().String()
+		notification.JobID = job.info.ID
+		notification.Recipients = job.recipients[job.next:end]
+		notification.Status = domain.StatusPending
+		notification.CreatedAt = time.Now()
+		job.next = end
+		id := notification.ID
+
+		// Count the notification before it's queued, since a worker may finish it before Send returns
+		job.info.Enqueued++
+		job.states[id] = jobStateQueued
+		job.mu.Unlock()
+		_, err := s.Send(ctx, &notification)
+		job.mu.Lock()
+
+		if err != nil {
+			if state, ok := job.states[id]; ok && state == jobStateQueued {
+				delete(job.states, id)
+				job.info.Enqueued--
+			}
+			job.info.Rejected++
+			job.info.LastError = err.Error()
+			s.logger.Warnf("Send job notification rejected - job=%s, id=%s, error=%v", job.info.ID, id, err)
+
+			// A blocking budget refuses the rest of today's notifications too
+			if (errors.Is(err, domain.ErrBudgetExceeded) || errors.Is(err, domain.ErrQuotaExceeded)) && job.info.Status == domain.JobRunning {
+				job.info.Status = domain.JobPaused
+				s.logger.Warnf("Send job paused, origin budget or daily quota exhausted - job=%s", job.info.ID)
+			}
+		}
+	}
+	job.recipients = nil
+
+	for job.inFlight() > 0 && ctx.Err() == nil {
+		job.cond.Wait()
+	}
+	if ctx.Err() != nil || job.finished() {
+		return
+	}
+
+	now := time.Now()
+	job.info.Status = domain.JobCompleted
+	job.info.FinishedAt = &now
+	job.cancel()
+	s.logger.Infof("Send job completed - id=%s, sent=%d, failed=%d, held=%d, rejected=%d",
+		job.info.ID, job.info.Sent, job.info.Failed, job.info.Held, job.info.Rejected)
+}
+
+// observeJob updates the progress of the job that created a notification, if any
+func (s *NotificationService) observeJob(notification *domain.Notification) {
+	if notification.JobID == "" {
+		return
+	}
+	job := s.jobs.get(notification.JobID)
+	if job == nil {
+		return
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	state, tracked := job.states[notification.ID]
+	if !tracked {
+		return
+	}
+	switch notification.Status {
+	case domain.StatusSent:
+		job.move(notification.ID, jobStateSent)
+	case domain.StatusFailed:
+		job.move(notification.ID, jobStateFailed)
+	case domain.StatusHeld:
+		job.move(notification.ID, jobStateHeld)
+	case domain.StatusQueued:
+		// Approved or retried notifications are back in flight
+		if state == jobStateHeld || state == jobStateFailed {
+			job.move(notification.ID, jobStateQueued)
+		}
+	default:
+		return
+	}
+	job.cond.Broadcast()
+}
+
+// get returns a job by ID, or nil if it doesn't exist
+func (r *sendJobs) get(id string) *sendJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id]
+}
+
+// GetSendJob implements domain.SendJobRunner
+func (s *NotificationService) GetSendJob(ctx context.Context, id string) (*domain.SendJob, error) {
+	job := s.sendJob(ctx, id)
+	if job == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrJobNotFound, id)
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.snapshot(time.Now()), nil
+}
+
+// ListSendJobs implements domain.SendJobRunner
+func (s *NotificationService) ListSendJobs(ctx context.Context) ([]*domain.SendJob, error) {
+	s.jobs.mu.Lock()
+	jobs := make([]*sendJob, 0, len(s.jobs.jobs))
+	for _, job := range s.jobs.jobs {
+		if visibleTo(ctx, job.info.Tenant) {
+			jobs = append(jobs, job)
+		}
+	}
+	s.jobs.mu.Unlock()
+
+	now := time.Now()
+	results := make([]*domain.SendJob, 0, len(jobs))
+	for _, job := range jobs {
+		job.mu.Lock()
+		results = append(results, job.snapshot(now))
+		job.mu.Unlock()
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+	return results, nil
+}
+
+// PauseSendJob implements domain.SendJobRunner
+func (s *NotificationService) PauseSendJob(ctx context.Context, id string) error {
+	return s.updateSendJob(ctx, id, "paused", func(job *sendJob) {
+		job.info.Status = domain.JobPaused
+	})
+}
+
+// ResumeSendJob implements domain.SendJobRunner
+func (s *NotificationService) ResumeSendJob(ctx context.Context, id string) error {
+	return s.updateSendJob(ctx, id, "resumed", func(job *sendJob) {
+		job.info.Status = domain.JobRunning
+	})
+}
+
+// CancelSendJob implements domain.SendJobRunner
+func (s *NotificationService) CancelSendJob(ctx context.Context, id string) error {
+	return s.updateSendJob(ctx, id, "cancelled", func(job *sendJob) {
+		now := time.Now()
+		job.info.Status = domain.JobCancelled
+		job.info.FinishedAt = &now
+		job.recipients = nil
+		job.cancel()
+	})
+}
+
+// sendJob returns a job the caller can see, or nil. Other tenants' jobs are reported as not found.
+func (s *NotificationService) sendJob(ctx context.Context, id string) *sendJob {
+	job := s.jobs.get(id)
+	if job == nil || !visibleTo(ctx, job.info.Tenant) {
+		return nil
+	}
+	return job
+}
+
+// updateSendJob applies a change to an unfinished job and wakes its runner
+func (s *NotificationService) updateSendJob(ctx context.Context, id, action string, update func(*sendJob)) error {
+	job := s.sendJob(ctx, id)
+	if job == nil {
+		return fmt.Errorf("%w: %s", domain.ErrJobNotFound, id)
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	if job.finished() {
+		return fmt.Errorf("%w: %s is %s", domain.ErrJobFinished, id, job.info.Status)
+	}
+	update(job)
+	job.cond.Broadcast()
+
+	s.logger.Infof("Send job %s - id=%s, enqueued=%d, sent=%d, failed=%d",
+		action, id, job.info.Enqueued, job.info.Sent, job.info.Failed)
+	return nil
+}
+
+// stopSendJobs stops every job's runner when the service shuts down
+func (s *NotificationService) stopSendJobs() {
+	s.jobs.mu.Lock()
+	defer s.jobs.mu.Unlock()
+
+	for _, job := range s.jobs.jobs {
+		job.mu.Lock()
+		job.cancel()
+		job.cond.Broadcast()
+		job.mu.Unlock()
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// waitForJob polls a send job until done reports true
+func waitForJob(t *testing.T, svc *NotificationService, id string, done func(*domain.SendJob) bool) *domain.SendJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := svc.GetSendJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetSendJob() error = %v", err)
+		}
+		if done(job) {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for job, last progress %+v", job)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestSendJob tests that a send job fans out, respects its in-flight cap and can be paused and resumed
+func TestSendJob(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	recipients := make([]string, 25)
+	for i := range recipients {
+		recipients[i] = "console"
+	}
+	template := &domain.Notification{Type: domain.TypeStdout, Body: "Quarterly update", MaxRetries: 1}
+	job, err := svc.SubmitSendJob(ctx, template, recipients, domain.SendJobOptions{RecipientsPerNotification: 2, MaxInFlight: 3})
+	if err != nil {
+		t.Fatalf("SubmitSendJob() error = %v", err)
+	}
+	if job.Total != 13 || job.Status != domain.JobRunning {
+		t.Fatalf("Unexpected job: %+v", job)
+	}
+
+	// Without workers nothing finishes, so the job stops at its in-flight cap
+	job = waitForJob(t, svc, job.ID, func(j *domain.SendJob) bool { return j.Enqueued == 3 })
+	time.Sleep(50 * time.Millisecond)
+	if job, _ = svc.GetSendJob(ctx, job.ID); job.Enqueued != 3 {
+		t.Fatalf("Expected the job to stop at 3 in flight, got %d enqueued", job.Enqueued)
+	}
+
+	if err := svc.PauseSendJob(ctx, job.ID); err != nil {
+		t.Fatalf("PauseSendJob() error = %v", err)
+	}
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	job = waitForJob(t, svc, job.ID, func(j *domain.SendJob) bool { return j.Sent == 3 })
+	if job.Status != domain.JobPaused || job.Enqueued != 3 {
+		t.Fatalf("Expected paused job to enqueue nothing more, got %+v", job)
+	}
+
+	if err := svc.ResumeSendJob(ctx, job.ID); err != nil {
+		t.Fatalf("ResumeSendJob() error = %v", err)
+	}
+	job = waitForJob(t, svc, job.ID, func(j *domain.SendJob) bool { return j.Status == domain.JobCompleted })
+	if job.Sent != 13 || job.Failed != 0 || job.Rejected != 0 || job.FinishedAt == nil {
+		t.Errorf("Unexpected completed job: %+v", job)
+	}
+
+	page, _ := svc.ListNotifications(ctx, &domain.NotificationFilter{})
+	notifications := page.Notifications
+	if len(notifications) != 13 || notifications[0].JobID != job.ID {
+		t.Errorf("Expected 13 notifications linked to the job, got %d", len(notifications))
+	}
+
+	if err := svc.CancelSendJob(ctx, job.ID); !errors.Is(err, domain.ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished cancelling a completed job, got %v", err)
+	}
+	if _, err := svc.GetSendJob(ctx, "missing"); !errors.Is(err, domain.ErrJobNotFound) {
+		t.Errorf("Expected ErrJobNotFound, got %v", err)
+	}
+}
+
+// TestCancelSendJob tests that a cancelled job stops enqueueing
+func TestCancelSendJob(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	template := &domain.Notification{Type: domain.TypeStdout, Body: "Quarterly update"}
+	job, err := svc.SubmitSendJob(ctx, template, []string{"a", "b", "c", "d"}, domain.SendJobOptions{MaxInFlight: 1})
+	if err != nil {
+		t.Fatalf("SubmitSendJob() error = %v", err)
+	}
+	waitForJob(t, svc, job.ID, func(j *domain.SendJob) bool { return j.Enqueued == 1 })
+
+	if err := svc.CancelSendJob(ctx, job.ID); err != nil {
+		t.Fatalf("CancelSendJob() error = %v", err)
+	}
+	if err := svc.PauseSendJob(ctx, job.ID); !errors.Is(err, domain.ErrJobFinished) {
+		t.Errorf("Expected ErrJobFinished pausing a cancelled job, got %v", err)
+	}
+
+	svc.Start(ctx)
+	defer svc.Stop()
+	job = waitForJob(t, svc, job.ID, func(j *domain.SendJob) bool { return j.Sent == 1 })
+	if job.Status != domain.JobCancelled || job.Enqueued != 1 {
+		t.Errorf("Expected cancelled job with one notification, got %+v", job)
+	}
+}
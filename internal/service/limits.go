@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// WithLimitsConfig caps the recipients, body size and metadata of notifications
+func (s *NotificationService) WithLimitsConfig(cfg config.LimitsConfig) {
+	s.limits = cfg
+}
+
+// checkLimits returns a *domain.LimitError for the first notification exceeding a configured
+// limit. It runs straight after authorization, so an oversized request costs as little as
+// possible. Notifications raised by the service itself aren't checked.
+func (s *NotificationService) checkLimits(notifications ...*domain.Notification) error {
+	for _, notification := range notifications {
+		if isOperationalAlert(notification) {
+			continue
+		}
+		if err := checkLimit(domain.LimitRecipients, s.limits.MaxRecipients, len(notification.Recipients)+len(notification.CC)+len(notification.BCC)); err != nil {
+			return err
+		}
+		if err := checkLimit(domain.LimitBodyBytes, s.limits.MaxBodyBytes, len(notification.Body)+len(notification.HTMLBody)); err != nil {
+			return err
+		}
+		if err := checkLimit(domain.LimitMetadataEntries, s.limits.MaxMetadataEntries, len(notification.Metadata)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLimit returns a *domain.LimitError if actual exceeds a non-zero limit
+func checkLimit(code string, limit, actual int) error {
+	if limit > 0 && actual > limit {
+		return &domain.LimitError{Code: code, Limit: limit, Actual: actual}
+	}
+	return nil
+}
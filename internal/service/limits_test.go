@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestCheckLimits tests that notifications over a limit are rejected with the limit's code
+func TestCheckLimits(t *testing.T) {
+	svc := createTestService(t)
+	svc.WithLimitsConfig(config.LimitsConfig{MaxRecipients: 2, MaxBodyBytes: 10, MaxMetadataEntries: 1})
+
+	small := &domain.Notification{ID: "n1", Type: domain.TypeStdout, Recipients: []string{"a"}, CC: []string{"b"}, Body: "hello"}
+	if err := svc.checkLimits(small); err != nil {
+		t.Fatalf("Expected notification within limits to pass, got %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		notification *domain.Notification
+		code         string
+	}{
+		{"recipients", &domain.Notification{Recipients: []string{"a", "b"}, BCC: []string{"c"}}, domain.LimitRecipients},
+		{"body", &domain.Notification{Body: "hello", HTMLBody: "<p>hi</p>"}, domain.LimitBodyBytes},
+		{"metadata", &domain.Notification{Metadata: map[string]interface{}{"a": 1, "b": 2}}, domain.LimitMetadataEntries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.checkLimits(small, tt.notification)
+			var limitErr *domain.LimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, domain.ErrLimitExceeded) {
+				t.Fatalf("Expected a LimitError, got %v", err)
+			}
+			if limitErr.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, limitErr.Code)
+			}
+		})
+	}
+
+	big := &domain.Notification{ID: "n2", Type: domain.TypeStdout, Recipients: []string{"a"}, Body: strings.Repeat("x", 11)}
+	result, err := svc.Send(context.Background(), big)
+	if !errors.Is(err, domain.ErrLimitExceeded) || result.Success {
+		t.Errorf("Expected Send to reject the oversized body, got %v", err)
+	}
+	if _, err := svc.GetNotification(context.Background(), "n2"); err == nil {
+		t.Error("Expected rejected notification not to be stored")
+	}
+
+	svc.WithLimitsConfig(config.LimitsConfig{})
+	if err := svc.checkLimits(big); err != nil {
+		t.Errorf("Expected no limits when unconfigured, got %v", err)
+	}
+}
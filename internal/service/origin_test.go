@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestOriginStampingFilteringAndStats tests that origins default to the caller, filter listings and group stats
+func TestOriginStampingFilteringAndStats(t *testing.T) {
+	svc := createTestService(t)
+	ctx := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "billing-service"})
+
+	send := func(ctx context.Context, notifType domain.NotificationType, origin domain.Origin) {
+		t.Helper()
+		notification := &domain.Notification{
+			ID:         uuid.New().String(),
+			Type:       notifType,
+			Body:       "test",
+			Recipients: []string{"test"},
+			Origin:     origin,
+			CreatedAt:  time.Now(),
+			MaxRetries: 3,
+		}
+		if _, err := svc.Send(ctx, notification); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+
+	send(ctx, domain.TypeEmail, domain.Origin{})                              // defaults to billing-service
+	send(ctx, domain.TypeEmail, domain.Origin{User: "alice"})                 // billing-service, user-triggered
+	send(ctx, domain.TypeSlack, domain.Origin{System: "deploy-bot"})          // explicit system wins
+	send(context.Background(), domain.TypeEmail, domain.Origin{})             // unauthenticated, no origin
+	send(context.Background(), domain.TypeStdout, domain.Origin{User: "bob"}) // user but no system
+
+	billing, err := svc.ListNotifications(ctx, &domain.NotificationFilter{OriginSystems: []string{"billing-service"}})
+	if err != nil {
+		t.Fatalf("ListNotifications failed: %v", err)
+	}
+	if len(billing.Notifications) != 2 || billing.Total != 2 {
+		t.Errorf("Expected 2 billing-service notifications, got %d", len(billing.Notifications))
+	}
+
+	alice, _ := svc.ListNotifications(ctx, &domain.NotificationFilter{OriginUsers: []string{"alice"}})
+	if len(alice.Notifications) != 1 || alice.Notifications[0].Origin.System != "billing-service" {
+		t.Errorf("Expected 1 notification triggered by alice from billing-service, got %+v", alice.Notifications)
+	}
+
+	stats, err := svc.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+
+	origin := stats.ByOrigin["billing-service"]
+	if origin == nil || origin.Total != 2 || origin.UserTriggered != 1 || origin.ByType["email"] != 2 {
+		t.Errorf("Unexpected billing-service stats: %+v", origin)
+	}
+	if origin := stats.ByOrigin["deploy-bot"]; origin == nil || origin.ByType["slack"] != 1 {
+		t.Errorf("Unexpected deploy-bot stats: %+v", origin)
+	}
+	if origin := stats.ByOrigin[domain.UnknownOrigin]; origin == nil || origin.Total != 2 || origin.UserTriggered != 1 {
+		t.Errorf("Unexpected unknown origin stats: %+v", origin)
+	}
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// pausedPollInterval is how often idle workers check whether a global pause has been lifted
+const pausedPollInterval = 250 * time.Millisecond
+
+// dispatchPauses holds the active operator pauses and the queue messages set aside by them
+type dispatchPauses struct {
+	mu     sync.RWMutex
+	pauses map[pauseKey]*domain.DispatchPause
+	parked map[string]pauseKey // Queue message ID -> the type and account it was going to
+	path   string              // File the pauses are saved to ("" keeps them in memory)
+}
+
+// pauseKey identifies a pause by its scope; empty fields are wildcards
+type pauseKey struct {
+	notifType domain.NotificationType
+	account   string
+}
+
+// newDispatchPauses creates an empty pause set
+func newDispatchPauses() *dispatchPauses {
+	return &dispatchPauses{
+		pauses: make(map[pauseKey]*domain.DispatchPause),
+		parked: make(map[string]pauseKey),
+	}
+}
+
+// global reports whether every notifier is paused
+func (p *dispatchPauses) global() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	_, ok := p.pauses[pauseKey{}]
+	return ok
+}
+
+// match returns the broadest pause covering an account
+func (p *dispatchPauses) match(notifType domain.NotificationType, account string) (*domain.DispatchPause, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.matchLocked(notifType, account)
+}
+
+// matchLocked is match with mu held
+func (p *dispatchPauses) matchLocked(notifType domain.NotificationType, account string) (*domain.DispatchPause, bool) {
+	if len(p.pauses) == 0 {
+		return nil, false
+	}
+	for _, key := range []pauseKey{{}, {notifType: notifType}, {notifType: notifType, account: account}} {
+		if pause, ok := p.pauses[key]; ok {
+			return pause, true
+		}
+	}
+	return nil, false
+}
+
+// park sets a dequeued message aside if its account is paused, calling hold with the pause
+// first. Parked messages stay in flight in the queue until the pause is lifted; hold runs with
+// the lock held, so the message can't be requeued before it's done.
+func (p *dispatchPauses) park(messageID string, notifType domain.NotificationType, account string, hold func(*domain.DispatchPause)) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pause, ok := p.matchLocked(notifType, account)
+	if ok {
+		hold(pause)
+		p.parked[messageID] = pauseKey{notifType: notifType, account: account}
+	}
+	return ok
+}
+
+// unpark removes and returns the parked messages no pause covers any more. Must be called with mu held.
+func (p *dispatchPauses) unpark() []string {
+	var released []string
+	for messageID, key := range p.parked {
+		if _, ok := p.matchLocked(key.notifType, key.account); !ok {
+			released = append(released, messageID)
+			delete(p.parked, messageID)
+		}
+	}
+	return released
+}
+
+// load reads the saved pauses, if there are any
+func (p *dispatchPauses) load() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing paused yet
+		}
+		return fmt.Errorf("failed to read pauses: %w", err)
+	}
+
+	var pauses []*domain.DispatchPause
+	if err := json.Unmarshal(data, &pauses); err != nil {
+		return fmt.Errorf("failed to unmarshal pauses: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, pause := range pauses {
+		p.pauses[pauseKey{notifType: pause.Type, account: pause.Account}] = pause
+	}
+	return nil
+}
+
+// save writes the pauses to their file, if they're persisted. Must be called with mu held.
+func (p *dispatchPauses) save() error {
+	if p.path == "" {
+		return nil
+	}
+
+	pauses := make([]*domain.DispatchPause, 0, len(p.pauses))
+	for _, pause := range p.pauses {
+		pauses = append(pauses, pause)
+	}
+	data, err := json.Marshal(pauses)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pauses: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old one, so a crash never leaves a
+	// partially written file
+	tmpPath := p.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write pauses: %w", err)
+	}
+	if err := os.Rename(tmpPath, p.path); err != nil {
+		return fmt.Errorf("failed to write pauses: %w", err)
+	}
+	return nil
+}
+
+// WithPausesConfig saves dispatch pauses to a file so they survive restarts, restoring any
+// pauses already saved there
+func (s *NotificationService) WithPausesConfig(cfg config.PausesConfig) error {
+	s.pauses.path = cfg.PersistPath
+	if cfg.PersistPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.PersistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create pauses directory: %w", err)
+	}
+	return s.pauses.load()
+}
+
+// PauseDispatch stops sending notifications covered by the pause. Workers stop taking messages
+// during a global pause; notifications for a paused type or account are set aside as they come
+// up, without using a retry. Sends already in progress finish. Notifications waiting for a
+// paused account are reported with status paused.
+func (s *NotificationService) PauseDispatch(ctx context.Context, pause domain.DispatchPause) (*domain.DispatchPause, error) {
+	if pause.Account != "" && pause.Type == "" {
+		return nil, fmt.Errorf("an account pause requires a type")
+	}
+	if pause.Type != "" && !s.supportsType(pause.Type) {
+		return nil, fmt.Errorf("unsupported notification type: %s", pause.Type)
+	}
+
+	pause.PausedAt = time.Now()
+	key := pauseKey{notifType: pause.Type, account: pause.Account}
+
+	// The pause takes effect even if it can't be saved
+	s.pauses.mu.Lock()
+	s.pauses.pauses[key] = &pause
+	err := s.pauses.save()
+	s.pauses.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Dispatch pause will not survive a restart - error=%v", err)
+	}
+
+	s.logger.Warnf("Dispatch paused - type=%s, account=%s, by=%s, reason=%s",
+		scopeLabel(string(pause.Type)), scopeLabel(pause.Account), pause.PausedBy, pause.Reason)
+	s.refreshPausedStatuses()
+
+	info := pause
+	return &info, nil
+}
+
+// ResumeDispatch lifts a pause. Notifications set aside for the account are requeued.
+func (s *NotificationService) ResumeDispatch(ctx context.Context, notifType domain.NotificationType, account string) error {
+	key := pauseKey{notifType: notifType, account: account}
+
+	s.pauses.mu.Lock()
+	if _, ok := s.pauses.pauses[key]; !ok {
+		s.pauses.mu.Unlock()
+		return domain.ErrNotPaused
+	}
+	delete(s.pauses.pauses, key)
+	err := s.pauses.save()
+	released := s.pauses.unpark()
+	s.pauses.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Dispatch resume will not survive a restart - error=%v", err)
+	}
+
+	s.logger.Infof("Dispatch resumed - type=%s, account=%s, requeued=%d",
+		scopeLabel(string(notifType)), scopeLabel(account), len(released))
+	s.refreshPausedStatuses()
+
+	for _, messageID := range released {
+		if err := s.queue.Nack(context.Background(), messageID, true); err != nil {
+			s.logger.Debugf("Failed to requeue paused message - message_id=%s, error=%v", messageID, err)
+		}
+	}
+	return nil
+}
+
+// ListDispatchPauses returns the active pauses, broadest first
+func (s *NotificationService) ListDispatchPauses(ctx context.Context) ([]*domain.DispatchPause, error) {
+	s.pauses.mu.RLock()
+	pauses := make([]*domain.DispatchPause, 0, len(s.pauses.pauses))
+	for _, pause := range s.pauses.pauses {
+		info := *pause
+		pauses = append(pauses, &info)
+	}
+	s.pauses.mu.RUnlock()
+
+	sort.Slice(pauses, func(i, j int) bool {
+		if pauses[i].Type != pauses[j].Type {
+			return pauses[i].Type < pauses[j].Type
+		}
+		return pauses[i].Account < pauses[j].Account
+	})
+	return pauses, nil
+}
+
+// refreshPausedStatuses marks waiting notifications paused when a pause covers their account,
+// and returns paused notifications no pause covers any more to queued
+func (s *NotificationService) refreshPausedStatuses() {
+	var changed []*domain.Notification
+
+	s.mu.Lock()
+	waiting, err := s.store.List(context.Background(), &domain.NotificationFilter{
+		Statuses: []domain.NotificationStatus{domain.StatusQueued, domain.StatusRetrying, domain.StatusPaused},
+	})
+	if err != nil {
+		s.mu.Unlock()
+		s.logger.Errorf("Failed to list notifications to refresh paused statuses - error=%v", err)
+		return
+	}
+	for _, notification := range waiting {
+		switch notification.Status {
+		case domain.StatusQueued, domain.StatusRetrying:
+			if _, ok := s.pauses.match(notification.Type, s.resolveAccount(notification)); ok {
+				notification.Status = domain.StatusPaused
+				changed = append(changed, notification)
+			}
+		case domain.StatusPaused:
+			if _, ok := s.pauses.match(notification.Type, s.resolveAccount(notification)); !ok {
+				notification.Status = domain.StatusQueued
+				if notification.RetryCount > 0 {
+					notification.Status = domain.StatusRetrying
+				}
+				changed = append(changed, notification)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, notification := range changed {
+		s.updateNotification(notification)
+	}
+}
+
+// resolveAccount returns the account a notification is sent with
+func (s *NotificationService) resolveAccount(notification *domain.Notification) string {
+	if notification.Account == "" && s.accountResolver != nil {
+		return s.accountResolver.GetDefaultAccount(notification.Type)
+	}
+	return notification.Account
+}
+
+// supportsType reports whether a notifier of the type is registered
+func (s *NotificationService) supportsType(notifType domain.NotificationType) bool {
+	for _, supported := range s.factory.SupportedTypes() {
+		if supported == notifType {
+			return true
+		}
+	}
+	return false
+}
+
+// waitWhilePaused blocks a worker while every notifier is paused. It returns false once the
+// service is stopping.
+func (s *NotificationService) waitWhilePaused(ctx context.Context) bool {
+	for s.pauses.global() {
+		select {
+		case <-s.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-time.After(pausedPollInterval):
+		}
+	}
+	return true
+}
+
+// scopeLabel names a pause scope for logs, where an empty scope means all
+func scopeLabel(scope string) string {
+	if scope == "" {
+		return "*"
+	}
+	return scope
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/notifier"
+)
+
+// TestDispatchPauseGlobal tests that a global pause holds queued notifications until it's lifted
+func TestDispatchPauseGlobal(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.PauseDispatch(ctx, domain.DispatchPause{Reason: "provider incident", PausedBy: "ops"}); err != nil {
+		t.Fatalf("PauseDispatch() error = %v", err)
+	}
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	result, err := svc.Send(ctx, &domain.Notification{ID: "n-held", Type: domain.TypeStdout, Body: "held back", Recipients: []string{"console"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	time.Sleep(3 * pausedPollInterval)
+	if n, _ := svc.GetNotification(ctx, result.NotificationID); n.Status != domain.StatusQueued {
+		t.Fatalf("Expected the notification to stay queued while paused, got %s", n.Status)
+	}
+
+	if err := svc.ResumeDispatch(ctx, "", ""); err != nil {
+		t.Fatalf("ResumeDispatch() error = %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, _ := svc.GetNotification(ctx, result.NotificationID)
+		if n.Status == domain.StatusSent {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Notification not sent after resuming, status %s", n.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// TestDispatchPauseAccount tests that notifications for a paused type are parked as paused
+// without using a retry, and go back to the queue when it's resumed
+func TestDispatchPauseAccount(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	waiting, err := svc.Send(ctx, &domain.Notification{ID: "n-waiting", Type: domain.TypeStdout, Body: "queued", Recipients: []string{"console"}})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if _, err := svc.PauseDispatch(ctx, domain.DispatchPause{Type: domain.TypeStdout}); err != nil {
+		t.Fatalf("PauseDispatch() error = %v", err)
+	}
+	if n, _ := svc.GetNotification(ctx, waiting.NotificationID); n.Status != domain.StatusPaused {
+		t.Errorf("Expected the queued notification to be marked paused, got %s", n.Status)
+	}
+
+	notification := &domain.Notification{ID: "n-1", Type: domain.TypeStdout, Body: "hi", Recipients: []string{"console"}, MaxRetries: 3, Status: domain.StatusQueued}
+	svc.processNotification(ctx, &domain.QueueMessage{ID: "msg-1", Notification: notification})
+	if notification.Status != domain.StatusPaused || notification.RetryCount != 0 {
+		t.Errorf("Expected the notification to be parked, got status %s after %d retries", notification.Status, notification.RetryCount)
+	}
+
+	pauses, _ := svc.ListDispatchPauses(ctx)
+	if len(pauses) != 1 || pauses[0].Type != domain.TypeStdout || pauses[0].PausedAt.IsZero() {
+		t.Errorf("Unexpected pauses: %+v", pauses)
+	}
+	if err := svc.factory.RegisterNotifier(domain.TypeStdout, "ops", notifier.NewStdoutNotifier()); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	notifiers, _ := svc.GetNotifiers(ctx)
+	if len(notifiers.Notifiers) != 1 || strings.Join(notifiers.Notifiers[0].PausedAccounts, ",") != "ops" {
+		t.Errorf("Expected the stdout account to be reported paused, got %+v", notifiers.Notifiers)
+	}
+
+	if err := svc.ResumeDispatch(ctx, domain.TypeStdout, ""); err != nil {
+		t.Fatalf("ResumeDispatch() error = %v", err)
+	}
+	if n, _ := svc.GetNotification(ctx, waiting.NotificationID); n.Status != domain.StatusQueued {
+		t.Errorf("Expected the notification to be queued again, got %s", n.Status)
+	}
+	svc.processNotification(ctx, &domain.QueueMessage{ID: "msg-1", Notification: notification})
+	if notification.Status != domain.StatusSent {
+		t.Errorf("Expected the notification to be sent after resuming, got %s", notification.Status)
+	}
+
+	if err := svc.ResumeDispatch(ctx, domain.TypeStdout, ""); !errors.Is(err, domain.ErrNotPaused) {
+		t.Errorf("Expected ErrNotPaused, got %v", err)
+	}
+	if _, err := svc.PauseDispatch(ctx, domain.DispatchPause{Account: "primary"}); err == nil {
+		t.Error("Expected an account pause without a type to fail")
+	}
+	if _, err := svc.PauseDispatch(ctx, domain.DispatchPause{Type: "pager"}); err == nil {
+		t.Error("Expected a pause for an unsupported type to fail")
+	}
+}
+
+// TestDispatchPausePersistence tests that pauses are restored after a restart
+func TestDispatchPausePersistence(t *testing.T) {
+	cfg := config.PausesConfig{PersistPath: filepath.Join(t.TempDir(), "state", "pauses.json")}
+	ctx := context.Background()
+
+	svc := createTestService(t)
+	if err := svc.WithPausesConfig(cfg); err != nil {
+		t.Fatalf("WithPausesConfig() error = %v", err)
+	}
+	for _, pause := range []domain.DispatchPause{
+		{Type: domain.TypeStdout, Account: "primary", Reason: "provider incident"},
+		{Type: domain.TypeStdout, Account: "backup"},
+	} {
+		if _, err := svc.PauseDispatch(ctx, pause); err != nil {
+			t.Fatalf("PauseDispatch() error = %v", err)
+		}
+	}
+	if err := svc.ResumeDispatch(ctx, domain.TypeStdout, "backup"); err != nil {
+		t.Fatalf("ResumeDispatch() error = %v", err)
+	}
+
+	restarted := createTestService(t)
+	if err := restarted.WithPausesConfig(cfg); err != nil {
+		t.Fatalf("WithPausesConfig() error = %v", err)
+	}
+	pauses, _ := restarted.ListDispatchPauses(ctx)
+	if len(pauses) != 1 || pauses[0].Account != "primary" || pauses[0].Reason != "provider incident" {
+		t.Errorf("Unexpected restored pauses: %+v", pauses)
+	}
+	if _, ok := restarted.pauses.match(domain.TypeStdout, "primary"); !ok {
+		t.Error("Expected the restored pause to apply")
+	}
+}
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// contentMatcher finds candidate matches, optionally confirmed by a validator
+type contentMatcher struct {
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// secretMatchers recognise common credential formats
+var secretMatchers = []contentMatcher{
+	{pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},                                   // AWS access key ID
+	{pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},                                  // GitHub token
+	{pattern: regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}\b`)},                                // GitHub fine-grained token
+	{pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},                                // Slack token
+	{pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{16,}\b`)},                                 // Stripe secret key
+	{pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},                                       // Google API key
+	{pattern: regexp.MustCompile(`\bnk_[0-9a-f]{64}\b`)},                                             // Notifier API key
+	{pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},                              // PEM private key
+	{pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)}, // JWT
+	{pattern: regexp.MustCompile(`(?i)\b(?:api[_-]?key|secret|token|passw(?:or)?d)\s*[:=]\s*["']?[^\s"']{8,}`)},
+}
+
+// piiMatchers recognise payment card and US social security numbers
+var piiMatchers = []contentMatcher{
+	{pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), valid: luhnValid},
+	{pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), valid: ssnValid},
+}
+
+// entropyCandidate matches tokens long enough to be generated credentials
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+=_-]{24,}`)
+
+// contentActionRank orders actions from least to most severe
+var contentActionRank = map[string]int{
+	config.ContentActionWarn:  1,
+	config.ContentActionHold:  2,
+	config.ContentActionBlock: 3,
+}
+
+// contentPolicy checks notification content against configured rules
+type contentPolicy struct {
+	rules []contentRule
+	alert config.AlertTargetConfig
+}
+
+// contentRule is a compiled content policy rule
+type contentRule struct {
+	name             string
+	detector         string
+	action           string
+	types            map[domain.NotificationType]bool
+	matchers         []contentMatcher
+	entropyThreshold float64
+}
+
+// newContentPolicy compiles the content policy configuration
+func newContentPolicy(cfg config.ContentPolicyConfig) (*contentPolicy, error) {
+	policy := &contentPolicy{alert: cfg.Alert}
+
+	for _, ruleCfg := range cfg.Rules {
+		rule := contentRule{
+			name:             ruleCfg.Name,
+			detector:         ruleCfg.Detector,
+			action:           ruleCfg.Action,
+			entropyThreshold: ruleCfg.EntropyThreshold,
+		}
+		if rule.name == "" {
+			rule.name = rule.detector
+		}
+		if rule.action == "" {
+			rule.action = cfg.Action
+		}
+		if len(ruleCfg.Types) > 0 {
+			rule.types = make(map[domain.NotificationType]bool)
+			for _, t := range ruleCfg.Types {
+				rule.types[domain.NotificationType(t)] = true
+			}
+		}
+
+		switch ruleCfg.Detector {
+		case config.ContentDetectorSecrets:
+			rule.matchers = secretMatchers
+		case config.ContentDetectorPII:
+			rule.matchers = piiMatchers
+		case config.ContentDetectorPhrases:
+			quoted := make([]string, len(ruleCfg.Phrases))
+			for i, phrase := range ruleCfg.Phrases {
+				quoted[i] = regexp.QuoteMeta(strings.TrimSpace(phrase))
+			}
+			rule.matchers = []contentMatcher{{pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)}}
+		case config.ContentDetectorRegex:
+			pattern, err := regexp.Compile(ruleCfg.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for content rule %s: %w", rule.name, err)
+			}
+			rule.matchers = []contentMatcher{{pattern: pattern}}
+		default:
+			return nil, fmt.Errorf("invalid detector for content rule %s: %s", rule.name, ruleCfg.Detector)
+		}
+
+		policy.rules = append(policy.rules, rule)
+	}
+
+	return policy, nil
+}
+
+// check returns the rules a notification's content matches, at most one per rule and field
+func (p *contentPolicy) check(notification *domain.Notification) []domain.PolicyViolation {
+	fields := contentFields(notification)
+
+	var violations []domain.PolicyViolation
+	for _, rule := range p.rules {
+		if rule.types != nil && !rule.types[notification.Type] {
+			continue
+		}
+		for _, field := range fields {
+			if match, ok := rule.find(field.text); ok {
+				violations = append(violations, domain.PolicyViolation{
+					Rule:     rule.name,
+					Detector: rule.detector,
+					Field:    field.name,
+					Match:    maskMatch(match),
+					Action:   rule.action,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// find returns the first match of the rule in text
+func (r contentRule) find(text string) (string, bool) {
+	for _, matcher := range r.matchers {
+		for _, match := range matcher.pattern.FindAllString(text, -1) {
+			if matcher.valid == nil || matcher.valid(match) {
+				return match, true
+			}
+		}
+	}
+
+	if r.entropyThreshold > 0 {
+		for _, token := range entropyCandidate.FindAllString(text, -1) {
+			if strings.ContainsAny(token, "0123456789") && strings.IndexFunc(token, isLetter) >= 0 && shannonEntropy(token) >= r.entropyThreshold {
+				return token, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// contentField is a named piece of notification text
+type contentField struct {
+	name string
+	text string
+}
+
+// contentFields returns the text fields of a notification that are checked
+func contentFields(notification *domain.Notification) []contentField {
+	fields := []contentField{
+		{"subject", notification.Subject},
+		{"body", notification.Body},
+		{"html_body", notification.HTMLBody},
+	}
+
+	keys := make([]string, 0, len(notification.Metadata))
+	for key := range notification.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if value, ok := notification.Metadata[key].(string); ok {
+			fields = append(fields, contentField{"metadata." + key, value})
+		}
+	}
+
+	return fields
+}
+
+// strongestAction returns the most severe action among violations
+func strongestAction(violations []domain.PolicyViolation) string {
+	action := ""
+	for _, violation := range violations {
+		if contentActionRank[violation.Action] > contentActionRank[action] {
+			action = violation.Action
+		}
+	}
+	return action
+}
+
+// maskMatch keeps the first few characters of a match so it can be recognised without being disclosed
+func maskMatch(match string) string {
+	n := utf8.RuneCountInString(match)
+	keep := 4
+	if n <= 8 {
+		keep = 1
+	}
+	return string([]rune(match)[:keep]) + strings.Repeat("*", min(n-keep, 8)) + fmt.Sprintf(" (%d chars)", n)
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+
+	n := float64(utf8.RuneCountInString(s))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// isLetter reports whether r is an ASCII letter
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// luhnValid reports whether a digit string (with optional spaces or dashes) passes the Luhn check
+func luhnValid(match string) bool {
+	sum, digits := 0, 0
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
+
+// ssnValid rejects SSN-shaped numbers that can't be issued (area 000, 666 or 9xx, group 00, serial 0000)
+func ssnValid(match string) bool {
+	area, group, serial := match[0:3], match[4:6], match[7:11]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// WithContentPolicyConfig enables content policy checks before notifications are queued
+func (s *NotificationService) WithContentPolicyConfig(cfg config.ContentPolicyConfig) error {
+	s.contentPolicyConfig = cfg
+	if !cfg.Enabled {
+		s.contentPolicy = nil
+		return nil
+	}
+
+	policy, err := newContentPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	s.contentPolicy = policy
+
+	return nil
+}
+
+// checkContent runs the content policy over notifications, recording violations on each one.
+// If any notification matches a blocking rule, ErrContentBlocked is returned and none should
+// be queued; notifications matching a hold rule are marked held.
+func (s *NotificationService) checkContent(notifications ...*domain.Notification) error {
+	if s.contentPolicy == nil {
+		return nil
+	}
+
+	for _, notification := range notifications {
+		if isOperationalAlert(notification) {
+			continue
+		}
+
+		violations := s.contentPolicy.check(notification)
+		notification.PolicyViolations = violations
+		if len(violations) == 0 {
+			continue
+		}
+
+		for _, violation := range violations {
+			s.logger.Warnf("Content policy matched - id=%s, origin=%s, rule=%s, field=%s, match=%s, action=%s",
+				notification.ID, notification.Origin.StatsKey(), violation.Rule, violation.Field, violation.Match, violation.Action)
+		}
+
+		switch strongestAction(violations) {
+		case config.ContentActionBlock:
+			return fmt.Errorf("%w: %s", domain.ErrContentBlocked, describeViolations(violations))
+		case config.ContentActionHold:
+			notification.Status = domain.StatusHeld
+		}
+	}
+
+	return nil
+}
+
+// describeViolations summarises violations for error messages and alerts
+func describeViolations(violations []domain.PolicyViolation) string {
+	parts := make([]string, len(violations))
+	for i, violation := range violations {
+		parts[i] = fmt.Sprintf("%s in %s", violation.Rule, violation.Field)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// announceHeld alerts approvers that a notification is waiting for them
+func (s *NotificationService) announceHeld(ctx context.Context, notification *domain.Notification) {
+	s.logger.Infof("Notification held for approval - id=%s, type=%s, origin=%s",
+		notification.ID, notification.Type, notification.Origin.StatsKey())
+	if notification.DryRun {
+		return
+	}
+
+	subject := fmt.Sprintf("Notification from %s held for approval", notification.Origin.StatsKey())
+	body := fmt.Sprintf("A %s notification (%s) matched policy rules: %s.\n\n"+
+		"Approve: POST /api/v1/notifications/%s/approve\nReject:  POST /api/v1/notifications/%s/reject",
+		notification.Type, notification.ID, describeViolations(notification.PolicyViolations), notification.ID, notification.ID)
+
+	metadata := map[string]interface{}{"notification_id": notification.ID, "origin": notification.Origin.StatsKey()}
+	if err := s.sendOperationalAlert(ctx, s.heldAlertTarget(notification), subject, body, metadata); err != nil {
+		s.logger.Errorf("Failed to send approval alert - id=%s, error=%v", notification.ID, err)
+	}
+}
+
+// heldAlertTarget returns the alert target of the check that held a notification
+func (s *NotificationService) heldAlertTarget(notification *domain.Notification) config.AlertTargetConfig {
+	for _, violation := range notification.PolicyViolations {
+		if violation.Action != config.ContentActionHold {
+			continue
+		}
+		if violation.Rule == spamViolationRule && s.spamCheck != nil {
+			return s.spamCheck.alert
+		}
+		if violation.Rule != spamViolationRule && s.contentPolicy != nil {
+			return s.contentPolicy.alert
+		}
+	}
+	return config.AlertTargetConfig{}
+}
+
+// ApproveNotification queues a held notification for delivery
+func (s *NotificationService) ApproveNotification(ctx context.Context, id, approvedBy string) error {
+	s.mu.Lock()
+	notification, err := s.getNotification(ctx, id)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if notification.Status != domain.StatusHeld {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s is %s", domain.ErrNotHeld, id, notification.Status)
+	}
+	s.markQueued(notification)
+	s.mu.Unlock()
+
+	if err := s.enqueue(ctx, notification); err != nil {
+		s.mu.Lock()
+		notification.Status = domain.StatusHeld
+		s.updateNotification(notification)
+		s.mu.Unlock()
+		return fmt.Errorf("failed to enqueue: %w", err)
+	}
+
+	s.logger.Infof("Held notification approved - id=%s, approved_by=%s", id, approvedBy)
+	return nil
+}
+
+// RejectNotification fails a held notification without sending it
+func (s *NotificationService) RejectNotification(ctx context.Context, id, rejectedBy, reason string) error {
+	s.mu.Lock()
+	notification, err := s.getNotification(ctx, id)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if notification.Status != domain.StatusHeld {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s is %s", domain.ErrNotHeld, id, notification.Status)
+	}
+	notification.Status = domain.StatusFailed
+	notification.LastError = "rejected by content policy review"
+	if reason != "" {
+		notification.LastError += ": " + reason
+	}
+	err = s.store.Update(ctx, notification)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.logger.Infof("Held notification rejected - id=%s, rejected_by=%s, reason=%s", id, rejectedBy, reason)
+	s.publishStatus(notification)
+	return nil
+}
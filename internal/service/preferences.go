@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// preferenceStore returns the store contacts' preferences are kept in
+func (s *NotificationService) preferenceStore() (domain.PreferenceStore, error) {
+	store, ok := s.store.(domain.PreferenceStore)
+	if !ok {
+		return nil, fmt.Errorf("the notification store doesn't support preferences")
+	}
+	return store, nil
+}
+
+// SetPreferences validates a contact's preferences and replaces any the caller's tenant had set.
+// Each tenant sets its own preferences for the configured contacts.
+func (s *NotificationService) SetPreferences(ctx context.Context, prefs domain.Preferences) (*domain.Preferences, error) {
+	prefs.Tenant = auth.TenantFromContext(ctx)
+	if _, err := s.lookupContact(ctx, prefs.Tenant, prefs.Contact); err != nil {
+		return nil, err
+	}
+	for _, notifType := range prefs.Channels {
+		if !s.supportsType(notifType) {
+			return nil, fmt.Errorf("%w: unsupported notification type: %s", domain.ErrInvalidPreferences, notifType)
+		}
+	}
+	for _, category := range prefs.MutedCategories {
+		if category == "" {
+			return nil, fmt.Errorf("%w: muted categories can't be empty", domain.ErrInvalidPreferences)
+		}
+	}
+	for _, hours := range prefs.QuietHours {
+		if _, err := parseQuietWindow(hours); err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPreferences, err)
+		}
+	}
+
+	store, err := s.preferenceStore()
+	if err != nil {
+		return nil, err
+	}
+	prefs.UpdatedAt = time.Now().UTC()
+	if err := store.SavePreferences(ctx, &prefs); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Preferences set - contact=%s, channels=%d, muted=%d, quiet_hours=%d",
+		prefs.Contact, len(prefs.Channels), len(prefs.MutedCategories), len(prefs.QuietHours))
+	return &prefs, nil
+}
+
+// GetPreferences returns the preferences the caller's tenant set for a contact
+func (s *NotificationService) GetPreferences(ctx context.Context, contact string) (*domain.Preferences, error) {
+	store, err := s.preferenceStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.GetPreferences(ctx, auth.TenantFromContext(ctx), contact)
+}
+
+// DeletePreferences removes the preferences the caller's tenant set for a contact
+func (s *NotificationService) DeletePreferences(ctx context.Context, contact string) error {
+	store, err := s.preferenceStore()
+	if err != nil {
+		return err
+	}
+	if err := store.DeletePreferences(ctx, auth.TenantFromContext(ctx), contact); err != nil {
+		return err
+	}
+
+	s.logger.Infof("Preferences deleted - contact=%s", contact)
+	return nil
+}
+
+// applyPreferences checks the preferences the notification's tenant set for a contact against
+// the notification. It returns why the contact doesn't want it, or else the contact's quiet
+// hours. Critical notifications, and contacts without preferences, are always accepted.
+func (s *NotificationService) applyPreferences(ctx context.Context, contact string, notification *domain.Notification) ([]quietWindow, string, error) {
+	if notification.Priority >= domain.PriorityCritical {
+		return nil, "", nil
+	}
+	store, ok := s.store.(domain.PreferenceStore)
+	if !ok {
+		return nil, "", nil
+	}
+	prefs, err := store.GetPreferences(ctx, notification.Tenant, contact)
+	if errors.Is(err, domain.ErrPreferencesNotFound) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(prefs.Channels) > 0 && !slices.Contains(prefs.Channels, notification.Type) {
+		return nil, "channel", nil
+	}
+	if category, _ := notification.Metadata["category"].(string); category != "" && slices.Contains(prefs.MutedCategories, category) {
+		return nil, "muted category " + category, nil
+	}
+
+	windows := make([]quietWindow, 0, len(prefs.QuietHours))
+	for _, hours := range prefs.QuietHours {
+		window, err := parseQuietWindow(hours)
+		if err != nil {
+			s.logger.Warnf("Ignoring invalid quiet hours - contact=%s, error=%v", contact, err)
+			continue
+		}
+		windows = append(windows, window)
+	}
+	return windows, "", nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestPreferencesFilterContacts tests that contacts' preferences decide which of them a
+// notification reaches, and when
+func TestPreferencesFilterContacts(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	for _, name := range []string{"alice", "bob"} {
+		if _, err := svc.CreateContact(ctx, domain.Contact{Name: name, Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {name}}}); err != nil {
+			t.Fatalf("CreateContact() error = %v", err)
+		}
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "team", Members: []string{"alice", "bob"}}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+
+	now := time.Now().UTC()
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "alice", MutedCategories: []string{"marketing"}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "bob", QuietHours: []domain.QuietHours{
+		{Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")},
+	}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		priority   domain.Priority
+		category   string
+		recipients []string
+		deferred   bool
+	}{
+		{"muted category", domain.PriorityNormal, "marketing", []string{"bob"}, true},
+		{"other category", domain.PriorityNormal, "billing", []string{"alice", "bob"}, true},
+		{"critical", domain.PriorityCritical, "marketing", []string{"alice", "bob"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &domain.Notification{
+				Type:     domain.TypeStdout,
+				Priority: tt.priority,
+				Body:     "x",
+				Contacts: []string{"team"},
+				Metadata: map[string]interface{}{"category": tt.category},
+			}
+			if _, err := svc.Send(ctx, notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if !slices.Equal(notification.Recipients, tt.recipients) {
+				t.Errorf("Expected recipients %v, got %v", tt.recipients, notification.Recipients)
+			}
+			if deferred := notification.ScheduledFor != nil; deferred != tt.deferred {
+				t.Errorf("Expected deferred = %v, got %v", tt.deferred, notification.ScheduledFor)
+			}
+		})
+	}
+
+	// Excluding every contact leaves nothing to send
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "bob", MutedCategories: []string{"marketing"}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	_, err := svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Body: "x", Contacts: []string{"team"}, Metadata: map[string]interface{}{"category": "marketing"}})
+	if !errors.Is(err, domain.ErrInvalidContact) {
+		t.Errorf("Expected ErrInvalidContact, got %v", err)
+	}
+}
+
+// TestPreferencesLifecycle tests setting, validating and deleting preferences
+func TestPreferencesLifecycle(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "alice"}); !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected ErrContactNotFound, got %v", err)
+	}
+	if _, err := svc.CreateContact(ctx, domain.Contact{Name: "alice", Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {"alice"}}}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "alice", Channels: []domain.NotificationType{domain.TypeSlack}}); !errors.Is(err, domain.ErrInvalidPreferences) {
+		t.Errorf("Expected an unsupported channel to be rejected, got %v", err)
+	}
+	if _, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "alice", QuietHours: []domain.QuietHours{{Start: "25:00", End: "07:00"}}}); !errors.Is(err, domain.ErrInvalidPreferences) {
+		t.Errorf("Expected invalid quiet hours to be rejected, got %v", err)
+	}
+
+	prefs, err := svc.SetPreferences(ctx, domain.Preferences{Contact: "alice", Channels: []domain.NotificationType{domain.TypeStdout}})
+	if err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+	if prefs.UpdatedAt.IsZero() {
+		t.Error("Expected UpdatedAt to be set")
+	}
+
+	// Deleting the contact deletes its preferences
+	if err := svc.DeleteContact(ctx, "alice"); err != nil {
+		t.Fatalf("DeleteContact() error = %v", err)
+	}
+	if _, err := svc.GetPreferences(ctx, "alice"); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Errorf("Expected ErrPreferencesNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/providerstatus"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// TestOutageDefersRetryInQueue tests that a send failing during a provider outage is held in
+// the queue until the outage retry delay passes, without using up a retry
+func TestOutageDefersRetryInQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":{"indicator":"critical","description":"Major Outage"}}`))
+	}))
+	defer server.Close()
+
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeEmail, "", &fakeNotifier{fail: true})
+
+	q, _ := queue.NewLocalQueue(nil)
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+	err := svc.WithProviderStatusConfig(providerstatus.Config{
+		Enabled:      true,
+		PollInterval: "1m",
+		RetryDelay:   "1h",
+		Feeds: []providerstatus.FeedConfig{
+			{Name: "ses", Format: providerstatus.FormatStatuspage, URL: server.URL, Types: []string{"email"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithProviderStatusConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	svc.providerStatus.Poll(ctx)
+
+	notification := &domain.Notification{Type: domain.TypeEmail, Body: "hello", Recipients: []string{"ops@example.com"}, MaxRetries: 3}
+	if _, err := svc.Send(ctx, notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	msg, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+
+	before := time.Now()
+	svc.processNotification(ctx, msg)
+
+	if notification.Status != domain.StatusRetrying || notification.RetryCount != 0 {
+		t.Errorf("Expected a retry without using up an attempt, got status=%s retries=%d", notification.Status, notification.RetryCount)
+	}
+	if notification.NextAttemptAt == nil || notification.NextAttemptAt.Before(before.Add(time.Hour)) {
+		t.Errorf("Expected the next attempt after the outage retry delay, got %v", notification.NextAttemptAt)
+	}
+	if metrics := q.QueueMetrics(); metrics.Delayed != 1 || metrics.Depth != 0 {
+		t.Errorf("Expected the message to be held in the queue, got delayed=%d depth=%d", metrics.Delayed, metrics.Depth)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// quietWindow is a parsed quiet hours window
+type quietWindow struct {
+	notifType  domain.NotificationType
+	account    string        // Empty covers every account of the type
+	recipients []string      // Empty covers every recipient
+	start      time.Duration // Wall clock time of day the window starts
+	end        time.Duration // Wall clock time of day it ends; not after start if it spans midnight
+	loc        *time.Location
+	days       map[time.Weekday]bool // Days the window starts on; nil is every day
+}
+
+// WithQuietHoursConfig defers notifications arriving during quiet hours to the end of the window
+func (s *NotificationService) WithQuietHoursConfig(cfg config.QuietHoursConfig) error {
+	windows, err := parseQuietWindows(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.quietWindows = windows
+	return nil
+}
+
+// parseQuietWindows compiles the quiet hours configuration, returning nil if it's disabled
+func parseQuietWindows(cfg config.QuietHoursConfig) ([]quietWindow, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	windows := make([]quietWindow, 0, len(cfg.Windows))
+	for _, windowCfg := range cfg.Windows {
+		window, err := parseQuietWindow(domain.QuietHours{
+			Start:    windowCfg.Start,
+			End:      windowCfg.End,
+			Timezone: windowCfg.Timezone,
+			Days:     windowCfg.Days,
+		})
+		if err != nil {
+			return nil, err
+		}
+		window.notifType = domain.NotificationType(windowCfg.Type)
+		window.account = windowCfg.Account
+		window.recipients = windowCfg.Recipients
+		windows = append(windows, window)
+	}
+
+	return windows, nil
+}
+
+// parseQuietWindow compiles the times and days of a quiet hours window
+func parseQuietWindow(hours domain.QuietHours) (quietWindow, error) {
+	start, err := time.Parse("15:04", hours.Start)
+	if err != nil {
+		return quietWindow{}, fmt.Errorf("invalid quiet hours start %q: %w", hours.Start, err)
+	}
+	end, err := time.Parse("15:04", hours.End)
+	if err != nil {
+		return quietWindow{}, fmt.Errorf("invalid quiet hours end %q: %w", hours.End, err)
+	}
+
+	loc := time.UTC
+	if hours.Timezone != "" {
+		if loc, err = time.LoadLocation(hours.Timezone); err != nil {
+			return quietWindow{}, fmt.Errorf("invalid quiet hours timezone %q: %w", hours.Timezone, err)
+		}
+	}
+
+	window := quietWindow{
+		start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		end:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+		loc:   loc,
+	}
+	for _, day := range hours.Days {
+		weekday, ok := config.ParseWeekday(day)
+		if !ok {
+			return quietWindow{}, fmt.Errorf("invalid quiet hours day %q", day)
+		}
+		if window.days == nil {
+			window.days = make(map[time.Weekday]bool)
+		}
+		window.days[weekday] = true
+	}
+	return window, nil
+}
+
+// covers reports whether the window applies to a notification sent through account
+func (w quietWindow) covers(notification *domain.Notification, account string) bool {
+	if w.notifType != notification.Type || (w.account != "" && w.account != account) {
+		return false
+	}
+	if len(w.recipients) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(notification.Recipients, func(recipient string) bool {
+		return slices.Contains(w.recipients, recipient)
+	})
+}
+
+// endAfter returns when the window ends if t falls inside it. The window started either on t's
+// day or, if it spans midnight, the day before.
+func (w quietWindow) endAfter(t time.Time) (time.Time, bool) {
+	local := t.In(w.loc)
+	for _, offset := range []int{0, -1} {
+		day := local.AddDate(0, 0, offset)
+		if w.days != nil && !w.days[day.Weekday()] {
+			continue
+		}
+		start := w.at(day, w.start)
+		end := w.at(day, w.end)
+		if w.end <= w.start {
+			end = w.at(day.AddDate(0, 0, 1), w.end)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// at returns the wall clock time of day on a day in the window's time zone, so a window keeps
+// its hours across DST changes
+func (w quietWindow) at(day time.Time, timeOfDay time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(timeOfDay/time.Hour), int(timeOfDay%time.Hour/time.Minute), 0, 0, w.loc)
+}
+
+// deferForQuietHours schedules a notification that would be sent during quiet hours for the end
+// of the window, or of back-to-back windows. Critical notifications aren't deferred, and one
+// already scheduled is only deferred if it's due during quiet hours.
+func (s *NotificationService) deferForQuietHours(notification *domain.Notification) {
+	if len(s.quietWindows) == 0 || notification.Priority >= domain.PriorityCritical {
+		return
+	}
+
+	account := s.resolveAccount(notification)
+	var windows []quietWindow
+	for _, window := range s.quietWindows {
+		if window.covers(notification, account) {
+			windows = append(windows, window)
+		}
+	}
+	if len(windows) == 0 {
+		return
+	}
+
+	deliverAt, ok := quietUntil(windows, dueTime(notification))
+	if !ok {
+		return
+	}
+
+	notification.ScheduledFor = &deliverAt
+	s.logger.Infof("Notification deferred for quiet hours - id=%s, type=%s, account=%s, until=%s",
+		notification.ID, notification.Type, account, deliverAt.UTC().Format(time.RFC3339))
+}
+
+// dueTime returns when a notification is due: now, or later if it's scheduled
+func dueTime(notification *domain.Notification) time.Time {
+	deliverAt := time.Now()
+	if notification.ScheduledFor != nil && notification.ScheduledFor.After(deliverAt) {
+		deliverAt = *notification.ScheduledFor
+	}
+	return deliverAt
+}
+
+// quietUntil returns when the windows end if t falls inside one, following back-to-back
+// windows to the end of the last
+func quietUntil(windows []quietWindow, t time.Time) (time.Time, bool) {
+	deferred := false
+	for range len(windows) + 1 {
+		latest, inside := t, false
+		for _, window := range windows {
+			if end, ok := window.endAfter(t); ok && end.After(latest) {
+				latest, inside = end, true
+			}
+		}
+		if !inside {
+			break
+		}
+		t, deferred = latest, true
+	}
+	return t, deferred
+}
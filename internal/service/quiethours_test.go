@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestQuietWindowEndAfter tests finding the end of the quiet hours window a time falls in
+func TestQuietWindowEndAfter(t *testing.T) {
+	windows, err := parseQuietWindows(config.QuietHoursConfig{Enabled: true, Windows: []config.QuietHoursWindowConfig{
+		{Type: "slack", Start: "22:00", End: "07:00", Timezone: "America/New_York", Days: []string{"fri", "sat"}},
+	}})
+	if err != nil {
+		t.Fatalf("parseQuietWindows() error = %v", err)
+	}
+	window := windows[0]
+	ny, _ := time.LoadLocation("America/New_York")
+
+	tests := []struct {
+		name  string
+		at    time.Time
+		want  time.Time
+		quiet bool
+	}{
+		{"friday evening", time.Date(2026, 3, 6, 23, 0, 0, 0, ny), time.Date(2026, 3, 7, 7, 0, 0, 0, ny), true},
+		{"saturday morning", time.Date(2026, 3, 7, 6, 59, 0, 0, ny), time.Date(2026, 3, 7, 7, 0, 0, 0, ny), true},
+		{"saturday daytime", time.Date(2026, 3, 7, 7, 0, 0, 0, ny), time.Time{}, false},
+		{"across DST change", time.Date(2026, 3, 7, 22, 30, 0, 0, ny), time.Date(2026, 3, 8, 7, 0, 0, 0, ny), true},
+		{"sunday evening", time.Date(2026, 3, 8, 23, 0, 0, 0, ny), time.Time{}, false},
+		{"thursday night", time.Date(2026, 3, 6, 1, 0, 0, 0, ny), time.Time{}, false},
+		{"in UTC", time.Date(2026, 3, 7, 4, 0, 0, 0, time.UTC), time.Date(2026, 3, 7, 7, 0, 0, 0, ny), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, quiet := window.endAfter(tt.at)
+			if quiet != tt.quiet || !got.Equal(tt.want) {
+				t.Errorf("endAfter(%v) = %v, %v, want %v, %v", tt.at, got, quiet, tt.want, tt.quiet)
+			}
+		})
+	}
+}
+
+// TestQuietHoursDeferNotifications tests that notifications covered by a window are scheduled for
+// its end unless they're critical or for other recipients
+func TestQuietHoursDeferNotifications(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	err := svc.WithQuietHoursConfig(config.QuietHoursConfig{Enabled: true, Windows: []config.QuietHoursWindowConfig{
+		{Type: "stdout", Recipients: []string{"night-shift"}, Start: now.Add(-time.Hour).Format("15:04"), End: now.Add(time.Hour).Format("15:04")},
+		{Type: "stdout", Recipients: []string{"night-shift"}, Start: now.Add(time.Hour).Format("15:04"), End: now.Add(2 * time.Hour).Format("15:04")},
+	}})
+	if err != nil {
+		t.Fatalf("WithQuietHoursConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		priority   domain.Priority
+		recipients []string
+		deferred   bool
+	}{
+		{"covered", domain.PriorityNormal, []string{"console", "night-shift"}, true},
+		{"critical", domain.PriorityCritical, []string{"night-shift"}, false},
+		{"other recipients", domain.PriorityNormal, []string{"console"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &domain.Notification{ID: "q-" + tt.name, Type: domain.TypeStdout, Priority: tt.priority, Body: "x", Recipients: tt.recipients}
+			if _, err := svc.Send(ctx, notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			stored, _ := svc.GetNotification(ctx, notification.ID)
+			if !tt.deferred {
+				if stored.Status != domain.StatusQueued || stored.ScheduledFor != nil {
+					t.Errorf("Expected the notification to be queued now, got %s %v", stored.Status, stored.ScheduledFor)
+				}
+				return
+			}
+			// Back-to-back windows defer it to the end of the second
+			want := now.Truncate(time.Minute).Add(2 * time.Hour)
+			if stored.Status != domain.StatusScheduled || stored.ScheduledFor == nil || !stored.ScheduledFor.Equal(want) {
+				t.Errorf("Expected the notification scheduled for %v, got %s %v", want, stored.Status, stored.ScheduledFor)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// WithQuotas counts the notifications each client and tenant sends against its daily quotas.
+// Request-rate quotas are enforced by the API middleware sharing the same quotas.
+func (s *NotificationService) WithQuotas(quotas *auth.Quotas) {
+	s.quotas = quotas
+}
+
+// checkQuotas counts notifications against the daily-volume quotas of the client sending them
+// and its tenant. Notifications the service sends itself, without a client, aren't counted.
+func (s *NotificationService) checkQuotas(ctx context.Context, notifications ...*domain.Notification) error {
+	if s.quotas == nil || len(notifications) == 0 {
+		return nil
+	}
+	authCtx, ok := auth.GetAuthContext(ctx)
+	if !ok {
+		return nil
+	}
+	return s.quotas.AdmitNotifications(authCtx, len(notifications), time.Now())
+}
+
+// refundQuotas uncounts notifications checkQuotas admitted that are rejected or fail before
+// they're queued, so they don't use up the client's quota
+func (s *NotificationService) refundQuotas(ctx context.Context, notifications ...*domain.Notification) {
+	if s.quotas == nil || len(notifications) == 0 {
+		return
+	}
+	if authCtx, ok := auth.GetAuthContext(ctx); ok {
+		s.quotas.RefundNotifications(authCtx, len(notifications), time.Now())
+	}
+}
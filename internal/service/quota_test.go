@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestQuotasLimitSends tests that a client's notifications are rejected once its daily quota
+// is used up, and that notifications sent without a client aren't counted
+func TestQuotasLimitSends(t *testing.T) {
+	svc := createTestService(t)
+	svc.WithQuotas(auth.NewQuotas(auth.QuotaConfig{Default: auth.QuotaLimits{NotificationsPerDay: 2}}))
+	ctx := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "indexer"})
+
+	send := func(ctx context.Context) error {
+		_, err := svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Body: "Reindex done", Recipients: []string{"ops"}})
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		if err := send(ctx); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := send(ctx); !errors.Is(err, domain.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if err := send(context.Background()); err != nil {
+		t.Errorf("Expected notifications without a client not to be counted, got %v", err)
+	}
+}
+
+// TestQuotasRefundRejectedSends tests that notifications rejected after they're counted, here by
+// an origin budget, don't use up the client's quota
+func TestQuotasRefundRejectedSends(t *testing.T) {
+	svc := createTestService(t)
+	quotas := auth.NewQuotas(auth.QuotaConfig{Default: auth.QuotaLimits{NotificationsPerDay: 5}})
+	svc.WithQuotas(quotas)
+	err := svc.WithBudgetConfig(config.BudgetConfig{
+		Enabled: true,
+		Action:  config.BudgetActionBlock,
+		Rules:   []config.BudgetRuleConfig{{Origin: "*", Limit: 1}},
+	})
+	if err != nil {
+		t.Fatalf("WithBudgetConfig() error = %v", err)
+	}
+	client := &auth.AuthContext{ClientID: "indexer"}
+	ctx := auth.ContextWithAuth(context.Background(), client)
+
+	send := func(notifications ...*domain.Notification) error {
+		_, err := svc.SendBatch(ctx, notifications)
+		return err
+	}
+	notification := func() *domain.Notification {
+		return &domain.Notification{Type: domain.TypeStdout, Body: "Reindex done", Recipients: []string{"ops"}}
+	}
+	if err := send(notification()); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if err := send(notification(), notification()); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	if _, err := svc.Send(ctx, notification()); !errors.Is(err, domain.ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+
+	usage := quotas.Usage(client, time.Now())
+	if len(usage) != 1 || usage[0].NotificationsPerDay.Used != 1 {
+		t.Errorf("Expected only the sent notification to be counted, got %+v", usage)
+	}
+}
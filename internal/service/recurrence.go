@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/recurrence"
+)
+
+// recurringNotifications holds the recurring notifications and the schedules they follow
+type recurringNotifications struct {
+	mu          sync.Mutex
+	definitions map[string]*domain.RecurringNotification
+	schedules   map[string]recurrence.Schedule
+	path        string        // File the definitions are saved to ("" keeps them in memory)
+	wake        chan struct{} // Wakes the recurrence loop when a definition changes
+}
+
+// newRecurringNotifications creates an empty set of recurring notifications
+func newRecurringNotifications() *recurringNotifications {
+	return &recurringNotifications{
+		definitions: make(map[string]*domain.RecurringNotification),
+		schedules:   make(map[string]recurrence.Schedule),
+		wake:        make(chan struct{}, 1),
+	}
+}
+
+// notify wakes the recurrence loop to look at the schedules again
+func (r *recurringNotifications) notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// load reads the saved definitions, if there are any
+func (r *recurringNotifications) load() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing recurring yet
+		}
+		return fmt.Errorf("failed to read recurring notifications: %w", err)
+	}
+
+	var definitions []*domain.RecurringNotification
+	if err := json.Unmarshal(data, &definitions); err != nil {
+		return fmt.Errorf("failed to unmarshal recurring notifications: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, definition := range definitions {
+		schedule, err := recurrence.Parse(definition.Schedule, definition.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("invalid schedule for recurring notification %s: %w", definition.ID, err)
+		}
+		r.definitions[definition.ID] = definition
+		r.schedules[definition.ID] = schedule
+	}
+	return nil
+}
+
+// save writes the definitions to their file, if they're persisted. Must be called with mu held.
+func (r *recurringNotifications) save() error {
+	if r.path == "" {
+		return nil
+	}
+
+	definitions := make([]*domain.RecurringNotification, 0, len(r.definitions))
+	for _, definition := range r.definitions {
+		definitions = append(definitions, definition)
+	}
+	data, err := json.Marshal(definitions)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recurring notifications: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old one, so a crash never leaves a
+	// partially written file
+	tmpPath := r.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write recurring notifications: %w", err)
+	}
+	if err := os.Rename(tmpPath, r.path); err != nil {
+		return fmt.Errorf("failed to write recurring notifications: %w", err)
+	}
+	return nil
+}
+
+// WithRecurrenceConfig saves recurring notifications to a file so they survive restarts,
+// restoring any already saved there. Occurrences that came due while the server was down
+// are caught up with a single send.
+func (s *NotificationService) WithRecurrenceConfig(cfg config.RecurrenceConfig) error {
+	s.recurring.path = cfg.PersistPath
+	if cfg.PersistPath == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.PersistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create recurrence directory: %w", err)
+	}
+	return s.recurring.load()
+}
+
+// createRecurrence keeps a notification with a recurrence schedule as the template of a new
+// recurring notification, which has the notification's ID
+func (s *NotificationService) createRecurrence(notification *domain.Notification) (*domain.NotificationResult, error) {
+	now := time.Now()
+	schedule, err := recurrence.Parse(notification.Recurrence, now)
+	if err == nil && schedule.Next(now).IsZero() {
+		err = fmt.Errorf("schedule has no occurrences after now")
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %v", domain.ErrInvalidRecurrence, err)
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         now,
+		}, err
+	}
+
+	template := *notification
+	template.Recurrence = ""
+	template.ScheduledFor = nil
+	next := schedule.Next(now)
+	definition := &domain.RecurringNotification{
+		ID:           notification.ID,
+		Schedule:     notification.Recurrence,
+		Status:       domain.RecurrenceActive,
+		Notification: &template,
+		NextAt:       &next,
+		CreatedAt:    now,
+	}
+
+	// The recurring notification takes effect even if it can't be saved
+	s.recurring.mu.Lock()
+	s.recurring.definitions[definition.ID] = definition
+	s.recurring.schedules[definition.ID] = schedule
+	err = s.recurring.save()
+	s.recurring.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Recurring notification will not survive a restart - id=%s, error=%v", definition.ID, err)
+	}
+	s.recurring.notify()
+
+	s.logger.Infof("Recurring notification created - id=%s, type=%s, schedule=%q, next=%s",
+		definition.ID, template.Type, definition.Schedule, next.UTC().Format(time.RFC3339))
+	return &domain.NotificationResult{
+		NotificationID: definition.ID,
+		Success:        true,
+		Message:        "recurring notification created, first occurrence at " + next.UTC().Format(time.RFC3339),
+		SentAt:         now,
+	}, nil
+}
+
+// recurrenceLoop sends recurring notifications as their occurrences come due. A single timer
+// is armed for the earliest one, and definition changes wake the loop to rearm it.
+func (s *NotificationService) recurrenceLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(0)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		if next, ok := s.sendDueRecurrences(ctx); ok {
+			timer.Reset(time.Until(next))
+		}
+
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.recurring.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// sendDueRecurrences sends an occurrence of every active recurring notification that's due,
+// returning when the next one is due, if any is
+func (s *NotificationService) sendDueRecurrences(ctx context.Context) (time.Time, bool) {
+	type occurrence struct {
+		definition   *domain.RecurringNotification
+		notification *domain.Notification
+	}
+
+	now := time.Now()
+	var due []occurrence
+	s.recurring.mu.Lock()
+	for id, definition := range s.recurring.definitions {
+		if definition.Status != domain.RecurrenceActive || definition.NextAt == nil || definition.NextAt.After(now) {
+			continue
+		}
+
+		notification := newOccurrence(definition, now)
+		due = append(due, occurrence{definition: definition, notification: notification})
+
+		// Occurrences missed while the server was down aren't sent one by one
+		sentAt := now
+		definition.Occurrences++
+		definition.LastAt = &sentAt
+		definition.LastNotificationID = notification.ID
+		if next := s.recurring.schedules[id].Next(now); next.IsZero() {
+			definition.NextAt = nil
+			definition.Status = domain.RecurrenceCompleted
+		} else {
+			definition.NextAt = &next
+		}
+	}
+	s.recurring.mu.Unlock()
+
+	for _, o := range due {
+		_, err := s.Send(ctx, o.notification)
+		if err != nil {
+			s.logger.Warnf("Recurring notification occurrence rejected - id=%s, occurrence=%s, error=%v",
+				o.definition.ID, o.notification.ID, err)
+		}
+
+		s.recurring.mu.Lock()
+		o.definition.LastError = ""
+		if err != nil {
+			o.definition.LastError = err.Error()
+		}
+		s.recurring.mu.Unlock()
+	}
+
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+	if len(due) > 0 {
+		if err := s.recurring.save(); err != nil {
+			s.logger.Errorf("Failed to save recurring notifications - error=%v", err)
+		}
+	}
+
+	var next time.Time
+	for _, definition := range s.recurring.definitions {
+		if definition.Status == domain.RecurrenceActive && definition.NextAt != nil && (next.IsZero() || definition.NextAt.Before(next)) {
+			next = *definition.NextAt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// newOccurrence copies a recurring notification's template into a new notification
+func newOccurrence(definition *domain.RecurringNotification, now time.Time) *domain.Notification {
+	notification := *definition.Notification
+	notification.ID = uuid.New().String()
+	notification.RecurrenceID = definition.ID
+	notification.Status = domain.StatusPending
+	notification.CreatedAt = now
+	notification.Recipients = slices.Clone(notification.Recipients)
+	notification.Metadata = maps.Clone(notification.Metadata) // Sending may add to it
+	return &notification
+}
+
+// ListRecurringNotifications returns every recurring notification, oldest first
+func (s *NotificationService) ListRecurringNotifications(ctx context.Context) ([]*domain.RecurringNotification, error) {
+	s.recurring.mu.Lock()
+	definitions := make([]*domain.RecurringNotification, 0, len(s.recurring.definitions))
+	for _, definition := range s.recurring.definitions {
+		if !visibleTo(ctx, definition.Notification.Tenant) {
+			continue
+		}
+		info := *definition
+		definitions = append(definitions, &info)
+	}
+	s.recurring.mu.Unlock()
+
+	sort.Slice(definitions, func(i, j int) bool {
+		if !definitions[i].CreatedAt.Equal(definitions[j].CreatedAt) {
+			return definitions[i].CreatedAt.Before(definitions[j].CreatedAt)
+		}
+		return definitions[i].ID < definitions[j].ID
+	})
+	return definitions, nil
+}
+
+// GetRecurringNotification returns a recurring notification
+func (s *NotificationService) GetRecurringNotification(ctx context.Context, id string) (*domain.RecurringNotification, error) {
+	s.recurring.mu.Lock()
+	defer s.recurring.mu.Unlock()
+
+	definition, ok := s.recurring.definitions[id]
+	if !ok || !visibleTo(ctx, definition.Notification.Tenant) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRecurrenceNotFound, id)
+	}
+	info := *definition
+	return &info, nil
+}
+
+// PauseRecurringNotification skips a recurring notification's occurrences until it's resumed
+func (s *NotificationService) PauseRecurringNotification(ctx context.Context, id string) error {
+	err := s.updateRecurrence(ctx, id, func(definition *domain.RecurringNotification, schedule recurrence.Schedule) error {
+		definition.Status = domain.RecurrencePaused
+		definition.NextAt = nil
+		return nil
+	})
+	if err == nil {
+		s.logger.Infof("Recurring notification paused - id=%s", id)
+	}
+	return err
+}
+
+// ResumeRecurringNotification sends a paused recurring notification's occurrences again,
+// starting with the next one due. Occurrences skipped while it was paused aren't sent.
+func (s *NotificationService) ResumeRecurringNotification(ctx context.Context, id string) error {
+	err := s.updateRecurrence(ctx, id, func(definition *domain.RecurringNotification, schedule recurrence.Schedule) error {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			definition.Status = domain.RecurrenceCompleted
+			definition.NextAt = nil
+			return fmt.Errorf("%w: %s has no occurrences left", domain.ErrRecurrenceFinished, id)
+		}
+		definition.Status = domain.RecurrenceActive
+		definition.NextAt = &next
+		return nil
+	})
+	if err == nil {
+		s.logger.Infof("Recurring notification resumed - id=%s", id)
+	}
+	return err
+}
+
+// DeleteRecurringNotification stops a recurring notification for good
+func (s *NotificationService) DeleteRecurringNotification(ctx context.Context, id string) error {
+	s.recurring.mu.Lock()
+	if definition, ok := s.recurring.definitions[id]; !ok || !visibleTo(ctx, definition.Notification.Tenant) {
+		s.recurring.mu.Unlock()
+		return fmt.Errorf("%w: %s", domain.ErrRecurrenceNotFound, id)
+	}
+	delete(s.recurring.definitions, id)
+	delete(s.recurring.schedules, id)
+	err := s.recurring.save()
+	s.recurring.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Recurring notification deletion will not survive a restart - id=%s, error=%v", id, err)
+	}
+
+	s.recurring.notify()
+	s.logger.Infof("Recurring notification deleted - id=%s", id)
+	return nil
+}
+
+// updateRecurrence applies a change to a recurring notification that hasn't completed, saves
+// it and rearms the recurrence loop. The change is saved even if it returns an error.
+func (s *NotificationService) updateRecurrence(ctx context.Context, id string, update func(*domain.RecurringNotification, recurrence.Schedule) error) error {
+	s.recurring.mu.Lock()
+	definition, ok := s.recurring.definitions[id]
+	if !ok || !visibleTo(ctx, definition.Notification.Tenant) {
+		s.recurring.mu.Unlock()
+		return fmt.Errorf("%w: %s", domain.ErrRecurrenceNotFound, id)
+	}
+	if definition.Status == domain.RecurrenceCompleted {
+		s.recurring.mu.Unlock()
+		return fmt.Errorf("%w: %s", domain.ErrRecurrenceFinished, id)
+	}
+
+	updateErr := update(definition, s.recurring.schedules[id])
+	err := s.recurring.save()
+	s.recurring.mu.Unlock()
+	if err != nil {
+		s.logger.Errorf("Recurring notification change will not survive a restart - id=%s, error=%v", id, err)
+	}
+
+	s.recurring.notify()
+	return updateErr
+}
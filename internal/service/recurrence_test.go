@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestRecurringNotificationSendsOccurrences tests that a notification with a recurrence is kept
+// as a template, and a copy of it is sent when an occurrence comes due
+func TestRecurringNotificationSendsOccurrences(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	result, err := svc.Send(ctx, &domain.Notification{ID: "r-1", Type: domain.TypeStdout, Body: "heartbeat", Recipients: []string{"console"}, Recurrence: "@hourly"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.NotificationID != "r-1" || !result.Success {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if _, err := svc.GetNotification(ctx, "r-1"); err == nil {
+		t.Error("Expected the recurring notification's template not to be stored as a notification")
+	}
+
+	// Bring the first occurrence forward rather than waiting for it
+	svc.recurring.mu.Lock()
+	due := time.Now().Add(-time.Second)
+	svc.recurring.definitions["r-1"].NextAt = &due
+	svc.recurring.mu.Unlock()
+	svc.recurring.notify()
+
+	var definition *domain.RecurringNotification
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		definition, err = svc.GetRecurringNotification(ctx, "r-1")
+		if err != nil {
+			t.Fatalf("GetRecurringNotification() error = %v", err)
+		}
+		if definition.Occurrences == 1 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if definition.Occurrences != 1 {
+		t.Fatalf("Expected one occurrence, got %d", definition.Occurrences)
+	}
+	if definition.NextAt == nil || !definition.NextAt.After(time.Now()) {
+		t.Errorf("Expected the next occurrence in the future, got %v", definition.NextAt)
+	}
+
+	waitForStatus(t, svc, definition.LastNotificationID, domain.StatusSent)
+	occurrence, _ := svc.GetNotification(ctx, definition.LastNotificationID)
+	if occurrence.RecurrenceID != "r-1" || occurrence.Body != "heartbeat" {
+		t.Errorf("Unexpected occurrence: %+v", occurrence)
+	}
+}
+
+// TestRecurringNotificationRejectsInvalidSchedule tests that an unparseable schedule is rejected
+func TestRecurringNotificationRejectsInvalidSchedule(t *testing.T) {
+	svc := createTestService(t)
+
+	for _, schedule := range []string{"61 * * * *", "FREQ=FORTNIGHTLY", "DTSTART:20200101T000000Z RRULE:FREQ=DAILY;COUNT=1"} {
+		_, err := svc.Send(context.Background(), &domain.Notification{ID: "r-bad", Type: domain.TypeStdout, Body: "x", Recipients: []string{"console"}, Recurrence: schedule})
+		if !errors.Is(err, domain.ErrInvalidRecurrence) {
+			t.Errorf("Send(%q) error = %v, want ErrInvalidRecurrence", schedule, err)
+		}
+	}
+	if recurring, _ := svc.ListRecurringNotifications(context.Background()); len(recurring) != 0 {
+		t.Errorf("Expected no recurring notifications, got %d", len(recurring))
+	}
+}
+
+// TestRecurringNotificationPauseResumeDelete tests pausing, resuming and deleting a recurring notification
+func TestRecurringNotificationPauseResumeDelete(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Send(ctx, &domain.Notification{ID: "r-1", Type: domain.TypeStdout, Body: "x", Recipients: []string{"console"}, Recurrence: "0 9 * * MON"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if err := svc.PauseRecurringNotification(ctx, "r-1"); err != nil {
+		t.Fatalf("PauseRecurringNotification() error = %v", err)
+	}
+	definition, _ := svc.GetRecurringNotification(ctx, "r-1")
+	if definition.Status != domain.RecurrencePaused || definition.NextAt != nil {
+		t.Errorf("Expected paused with no next occurrence, got %s %v", definition.Status, definition.NextAt)
+	}
+
+	if err := svc.ResumeRecurringNotification(ctx, "r-1"); err != nil {
+		t.Fatalf("ResumeRecurringNotification() error = %v", err)
+	}
+	definition, _ = svc.GetRecurringNotification(ctx, "r-1")
+	if definition.Status != domain.RecurrenceActive || definition.NextAt == nil || definition.NextAt.Weekday() != time.Monday {
+		t.Errorf("Expected active with the next occurrence on a Monday, got %s %v", definition.Status, definition.NextAt)
+	}
+
+	if err := svc.DeleteRecurringNotification(ctx, "r-1"); err != nil {
+		t.Fatalf("DeleteRecurringNotification() error = %v", err)
+	}
+	if _, err := svc.GetRecurringNotification(ctx, "r-1"); !errors.Is(err, domain.ErrRecurrenceNotFound) {
+		t.Errorf("GetRecurringNotification() error = %v, want ErrRecurrenceNotFound", err)
+	}
+	if err := svc.PauseRecurringNotification(ctx, "r-1"); !errors.Is(err, domain.ErrRecurrenceNotFound) {
+		t.Errorf("PauseRecurringNotification() error = %v, want ErrRecurrenceNotFound", err)
+	}
+}
+
+// TestRecurringNotificationsPersist tests that recurring notifications are restored after a restart
+func TestRecurringNotificationsPersist(t *testing.T) {
+	cfg := config.RecurrenceConfig{PersistPath: filepath.Join(t.TempDir(), "recurring.json")}
+	ctx := context.Background()
+
+	svc := createTestService(t)
+	if err := svc.WithRecurrenceConfig(cfg); err != nil {
+		t.Fatalf("WithRecurrenceConfig() error = %v", err)
+	}
+	if _, err := svc.Send(ctx, &domain.Notification{ID: "r-1", Type: domain.TypeStdout, Body: "x", Recipients: []string{"console"}, Recurrence: "FREQ=DAILY;BYHOUR=9;BYMINUTE=0"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := svc.PauseRecurringNotification(ctx, "r-1"); err != nil {
+		t.Fatalf("PauseRecurringNotification() error = %v", err)
+	}
+
+	restarted := createTestService(t)
+	if err := restarted.WithRecurrenceConfig(cfg); err != nil {
+		t.Fatalf("WithRecurrenceConfig() error = %v", err)
+	}
+	definition, err := restarted.GetRecurringNotification(ctx, "r-1")
+	if err != nil {
+		t.Fatalf("GetRecurringNotification() error = %v", err)
+	}
+	if definition.Status != domain.RecurrencePaused || definition.Notification.Body != "x" {
+		t.Errorf("Unexpected restored recurring notification: %+v", definition)
+	}
+	if err := restarted.ResumeRecurringNotification(ctx, "r-1"); err != nil {
+		t.Errorf("ResumeRecurringNotification() error = %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/inbound"
+	"github.com/igodwin/notifier/internal/signing"
+)
+
+// replyCallbackMetadata is the metadata key that picks a named reply callback
+const replyCallbackMetadata = "reply_callback"
+
+// replyRouting gives email a reply-to address and delivers replies to a callback
+type replyRouting struct {
+	addresser   *inbound.Addresser
+	callbackURL string
+	callbacks   map[string]string
+	httpClient  *http.Client
+	signer      *signing.Keyring
+}
+
+// replyDelivery is the payload posted to a reply callback
+type replyDelivery struct {
+	NotificationID string                 `json:"notification_id"`
+	Type           string                 `json:"type"`
+	Account        string                 `json:"account,omitempty"`
+	Subject        string                 `json:"subject,omitempty"`
+	Recipients     []string               `json:"recipients"`
+	Origin         domain.Origin          `json:"origin"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Reply          *domain.InboundReply   `json:"reply"`
+}
+
+// WithRepliesConfig gives email a reply-to address unique to the notification and delivers
+// replies to the configured callbacks. Callbacks are signed with signer if it isn't nil.
+func (s *NotificationService) WithRepliesConfig(cfg config.RepliesConfig, signer *signing.Keyring) error {
+	if !cfg.Enabled {
+		s.replies = nil
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid replies timeout: %w", err)
+	}
+	if cfg.CallbackURL == "" && len(cfg.Callbacks) == 0 {
+		return fmt.Errorf("replies requires a callback_url or callbacks")
+	}
+
+	s.replies = &replyRouting{
+		addresser:   inbound.NewAddresser([]byte(cfg.Secret), cfg.LocalPart, cfg.Domain),
+		callbackURL: cfg.CallbackURL,
+		callbacks:   cfg.Callbacks,
+		httpClient:  &http.Client{Timeout: timeout},
+		signer:      signer,
+	}
+	return nil
+}
+
+// addReplyAddresses sets the reply-to address of each email that doesn't already have one
+func (s *NotificationService) addReplyAddresses(notifications ...*domain.Notification) {
+	if s.replies == nil {
+		return
+	}
+
+	for _, notification := range notifications {
+		if notification.Type != domain.TypeEmail && notification.Type != domain.TypePostmark {
+			continue
+		}
+		if notification.ReplyTo != "" {
+			continue
+		}
+		address, ok := s.replies.addresser.Address(notification.ID)
+		if !ok {
+			s.logger.Debugf("Notification ID can't be used in a reply address - id=%s", notification.ID)
+			continue
+		}
+		notification.ReplyTo = address
+	}
+}
+
+// ReceiveReply delivers an email reply to the callback for the notification it answers
+func (s *NotificationService) ReceiveReply(ctx context.Context, reply *domain.InboundReply) (string, error) {
+	if s.replies == nil {
+		return "", fmt.Errorf("reply routing is not enabled")
+	}
+
+	var notification *domain.Notification
+	for _, address := range reply.To {
+		id, ok := s.replies.addresser.Match(address)
+		if !ok {
+			continue
+		}
+		if n, err := s.getNotification(ctx, id); err == nil {
+			notification = n
+			break
+		}
+		s.logger.Infof("Reply received for a notification no longer held - id=%s", id)
+	}
+	if notification == nil {
+		return "", domain.ErrReplyUnmatched
+	}
+
+	callbackURL := s.replies.callbackURL
+	if name, ok := notification.Metadata[replyCallbackMetadata].(string); ok && name != "" {
+		url, ok := s.replies.callbacks[name]
+		if !ok {
+			return "", fmt.Errorf("notification %s asks for unknown reply callback: %s", notification.ID, name)
+		}
+		callbackURL = url
+	}
+	if callbackURL == "" {
+		return "", fmt.Errorf("no reply callback for notification %s", notification.ID)
+	}
+
+	delivery := replyDelivery{
+		NotificationID: notification.ID,
+		Type:           string(notification.Type),
+		Account:        notification.Account,
+		Subject:        notification.Subject,
+		Recipients:     notification.Recipients,
+		Origin:         notification.Origin,
+		Metadata:       notification.Metadata,
+		Reply:          reply,
+	}
+	if err := s.replies.deliver(ctx, callbackURL, &delivery); err != nil {
+		return notification.ID, err
+	}
+
+	s.logger.Infof("Reply delivered - id=%s, from=%s", notification.ID, reply.From)
+	return notification.ID, nil
+}
+
+// deliver posts a reply to a callback
+func (r *replyRouting) deliver(ctx context.Context, callbackURL string, delivery *replyDelivery) error {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reply: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.signer != nil {
+		if err := r.signer.SignRequest(req, body); err != nil {
+			return fmt.Errorf("failed to sign reply: %w", err)
+		}
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver reply: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("reply callback returned status: %d", resp.StatusCode)
+	}
+	return nil
+}
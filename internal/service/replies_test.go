@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestReplyRouting tests giving email a reply address and delivering replies to its callback
+func TestReplyRouting(t *testing.T) {
+	deliveries := make(map[string]replyDelivery)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var delivery replyDelivery
+		if err := json.NewDecoder(r.Body).Decode(&delivery); err != nil {
+			t.Errorf("Failed to decode delivery: %v", err)
+		}
+		deliveries[r.URL.Path] = delivery
+	}))
+	defer callback.Close()
+
+	svc := createTestService(t)
+	err := svc.WithRepliesConfig(config.RepliesConfig{
+		Enabled:     true,
+		Domain:      "replies.example.com",
+		LocalPart:   "reply",
+		Secret:      "0123456789abcdef0123456789abcdef",
+		CallbackURL: callback.URL + "/default",
+		Callbacks:   map[string]string{"support": callback.URL + "/support"},
+		Timeout:     "5s",
+	}, nil)
+	if err != nil {
+		t.Fatalf("WithRepliesConfig() error = %v", err)
+	}
+
+	order := &domain.Notification{ID: "order-1", Type: domain.TypeEmail, Subject: "Your order", Recipients: []string{"alice@example.com"}}
+	ticket := &domain.Notification{ID: "ticket-1", Type: domain.TypePostmark, Recipients: []string{"bob@example.com"},
+		Metadata: map[string]interface{}{"reply_callback": "support"}}
+	custom := &domain.Notification{ID: "custom-1", Type: domain.TypeEmail, ReplyTo: "help@example.com"}
+	chat := &domain.Notification{ID: "chat-1", Type: domain.TypeSlack}
+	svc.addReplyAddresses(order, ticket, custom, chat)
+	for _, n := range []*domain.Notification{order, ticket, custom, chat} {
+		svc.storeNotification(n)
+	}
+
+	if order.ReplyTo == "" || ticket.ReplyTo == "" || custom.ReplyTo != "help@example.com" || chat.ReplyTo != "" {
+		t.Fatalf("Unexpected reply addresses: %q, %q, %q, %q", order.ReplyTo, ticket.ReplyTo, custom.ReplyTo, chat.ReplyTo)
+	}
+
+	id, err := svc.ReceiveReply(context.Background(), &domain.InboundReply{From: "alice@example.com", To: []string{"support@example.com", order.ReplyTo}, Text: "Thanks"})
+	if err != nil || id != "order-1" {
+		t.Fatalf("ReceiveReply() = %q, %v", id, err)
+	}
+	if delivery := deliveries["/default"]; delivery.NotificationID != "order-1" || delivery.Subject != "Your order" || delivery.Reply.Text != "Thanks" {
+		t.Errorf("Unexpected delivery: %+v", delivery)
+	}
+
+	if _, err := svc.ReceiveReply(context.Background(), &domain.InboundReply{To: []string{ticket.ReplyTo}}); err != nil {
+		t.Fatalf("ReceiveReply() error = %v", err)
+	}
+	if deliveries["/support"].NotificationID != "ticket-1" {
+		t.Errorf("Expected the reply at the notification's named callback, got %+v", deliveries)
+	}
+
+	if _, err := svc.ReceiveReply(context.Background(), &domain.InboundReply{To: []string{"reply+order-2.0000000000000000@replies.example.com"}}); !errors.Is(err, domain.ErrReplyUnmatched) {
+		t.Errorf("Expected a forged address not to match, got %v", err)
+	}
+}
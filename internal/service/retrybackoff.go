@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Retry backoff strategies
+const (
+	backoffExponential = "exponential"
+	backoffLinear      = "linear"
+	backoffFixed       = "fixed"
+)
+
+// retryBackoff spaces out the retries of a failed notification
+type retryBackoff struct {
+	strategy string
+	base     time.Duration // Wait before the first retry
+	max      time.Duration // Longest wait between retries
+}
+
+// WithRetryBackoffConfig waits between retries of a failed notification according to the
+// queue's backoff strategy, instead of retrying immediately
+func (s *NotificationService) WithRetryBackoffConfig(cfg domain.QueueConfig) error {
+	backoff := &retryBackoff{strategy: cfg.RetryBackoff}
+	switch backoff.strategy {
+	case "":
+		backoff.strategy = backoffExponential
+	case backoffExponential, backoffLinear, backoffFixed:
+	default:
+		return fmt.Errorf("invalid retry backoff: %s (must be exponential, linear or fixed)", cfg.RetryBackoff)
+	}
+
+	if cfg.RetryBaseDelay != "" {
+		base, err := time.ParseDuration(cfg.RetryBaseDelay)
+		if err != nil || base < 0 {
+			return fmt.Errorf("invalid retry base delay: %q", cfg.RetryBaseDelay)
+		}
+		backoff.base = base
+	}
+	if cfg.RetryMaxDelay != "" {
+		limit, err := time.ParseDuration(cfg.RetryMaxDelay)
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("invalid retry max delay: %q", cfg.RetryMaxDelay)
+		}
+		backoff.max = limit
+	}
+
+	s.retryBackoff = backoff
+	return nil
+}
+
+// delay returns how long to wait before a retry after attempt failed attempts. Half the delay
+// is randomised so notifications that failed together don't all retry at the same moment.
+func (b *retryBackoff) delay(attempt int) time.Duration {
+	if b == nil || b.base <= 0 || attempt < 1 {
+		return 0
+	}
+
+	var d time.Duration
+	switch b.strategy {
+	case backoffLinear:
+		d = b.base * time.Duration(attempt)
+	case backoffFixed:
+		d = b.base
+	default:
+		d = b.base
+		for i := 1; i < attempt && (b.max <= 0 || d < b.max); i++ {
+			d *= 2
+		}
+	}
+	if b.max > 0 && d > b.max {
+		d = b.max
+	}
+
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// scheduleRetry requeues a failed notification after its backoff, plus any wait for the retry
+// budget, and records when it will next be attempted. Its status is stored first, since a worker
+// may take it off the queue again at any moment after.
+func (s *NotificationService) scheduleRetry(ctx context.Context, msg *domain.QueueMessage) {
+	notification := msg.Notification
+	now := time.Now()
+	backoff := s.retryBackoff.delay(notification.RetryCount)
+	delay := backoff + s.retryDelay(now.Add(backoff))
+	if delay <= 0 {
+		notification.NextAttemptAt = nil
+		s.updateNotification(notification)
+		s.queue.Nack(ctx, msg.ID, true) // Requeue
+		return
+	}
+
+	nextAttempt := now.Add(delay)
+	notification.NextAttemptAt = &nextAttempt
+	s.updateNotification(notification)
+	if err := s.queue.NackDelayed(ctx, msg.ID, nextAttempt); err != nil {
+		s.logger.Errorf("Failed to requeue deferred retry - message_id=%s, error=%v", msg.ID, err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// TestRetryBackoffDelay tests that each strategy grows the delay between retries up to the cap,
+// with up to half of it randomised
+func TestRetryBackoffDelay(t *testing.T) {
+	tests := []struct {
+		strategy string
+		attempt  int
+		want     time.Duration // Delay before jitter
+	}{
+		{"exponential", 1, time.Second},
+		{"exponential", 2, 2 * time.Second},
+		{"exponential", 4, 8 * time.Second},
+		{"exponential", 10, 30 * time.Second},
+		{"exponential", 500, 30 * time.Second},
+		{"linear", 1, time.Second},
+		{"linear", 3, 3 * time.Second},
+		{"linear", 100, 30 * time.Second},
+		{"fixed", 1, time.Second},
+		{"fixed", 7, time.Second},
+	}
+
+	for _, tt := range tests {
+		svc := createTestService(t)
+		if err := svc.WithRetryBackoffConfig(domain.QueueConfig{RetryBackoff: tt.strategy, RetryBaseDelay: "1s", RetryMaxDelay: "30s"}); err != nil {
+			t.Fatalf("WithRetryBackoffConfig() error = %v", err)
+		}
+		for range 20 {
+			if got := svc.retryBackoff.delay(tt.attempt); got < tt.want/2 || got > tt.want {
+				t.Errorf("%s delay(%d) = %s, want between %s and %s", tt.strategy, tt.attempt, got, tt.want/2, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// TestRetryBackoffDisabled tests that retries are immediate without a backoff or base delay
+func TestRetryBackoffDisabled(t *testing.T) {
+	var unset *retryBackoff
+	if got := unset.delay(3); got != 0 {
+		t.Errorf("Expected no delay without a backoff, got %s", got)
+	}
+
+	svc := createTestService(t)
+	if err := svc.WithRetryBackoffConfig(domain.QueueConfig{RetryBackoff: "exponential", RetryBaseDelay: "0s"}); err != nil {
+		t.Fatalf("WithRetryBackoffConfig() error = %v", err)
+	}
+	if got := svc.retryBackoff.delay(3); got != 0 {
+		t.Errorf("Expected no delay with a zero base delay, got %s", got)
+	}
+
+	if err := svc.WithRetryBackoffConfig(domain.QueueConfig{RetryBackoff: "random"}); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
+
+// TestRetryBackoffHoldsMessageInQueue tests that a failed send waits out its backoff as a
+// delayed message in the queue rather than on an in-process timer
+func TestRetryBackoffHoldsMessageInQueue(t *testing.T) {
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeEmail, "", &fakeNotifier{fail: true})
+
+	q, _ := queue.NewLocalQueue(nil)
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+	if err := svc.WithRetryBackoffConfig(domain.QueueConfig{RetryBackoff: "fixed", RetryBaseDelay: "1h"}); err != nil {
+		t.Fatalf("WithRetryBackoffConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	notification := &domain.Notification{Type: domain.TypeEmail, Body: "hello", Recipients: []string{"ops@example.com"}, MaxRetries: 3}
+	if _, err := svc.Send(ctx, notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	msg, err := q.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue() error = %v", err)
+	}
+
+	before := time.Now()
+	svc.processNotification(ctx, msg)
+
+	if notification.Status != domain.StatusRetrying || notification.RetryCount != 1 {
+		t.Errorf("Expected a first retry, got status=%s retries=%d", notification.Status, notification.RetryCount)
+	}
+	if notification.NextAttemptAt == nil || notification.NextAttemptAt.Before(before.Add(30*time.Minute)) {
+		t.Errorf("Expected the next attempt after the backoff, got %v", notification.NextAttemptAt)
+	}
+	if metrics := q.QueueMetrics(); metrics.Delayed != 1 || metrics.Depth != 0 {
+		t.Errorf("Expected the message to be held in the queue, got delayed=%d depth=%d", metrics.Delayed, metrics.Depth)
+	}
+}
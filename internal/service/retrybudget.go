@@ -0,0 +1,71 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+)
+
+// retryBudget caps the retries started per minute across every notifier. Retries over the
+// budget are booked into the next minute with room, so a burst of failures is spread out
+// instead of hammering a provider that's already struggling.
+type retryBudget struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Time // Earliest minute with retries left, possibly in the future
+	used   int       // Retries booked in window
+	warned time.Time // Minute the budget was last reported exhausted
+}
+
+// reserve books a retry and returns how long it must wait for its minute. exhausted is true
+// the first time in a minute that a retry has to wait.
+func (b *retryBudget) reserve(now time.Time) (delay time.Duration, exhausted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := now.Truncate(time.Minute)
+	if current.After(b.window) {
+		b.window, b.used = current, 0
+	}
+	if b.used >= b.limit {
+		b.window, b.used = b.window.Add(time.Minute), 0
+	}
+	b.used++
+
+	if !b.window.After(now) {
+		return 0, false
+	}
+	if exhausted = !b.warned.Equal(current); exhausted {
+		b.warned = current
+	}
+	return b.window.Sub(now), exhausted
+}
+
+// WithRetryBudgetConfig caps the retries started per minute across every notifier
+func (s *NotificationService) WithRetryBudgetConfig(cfg config.RetryBudgetConfig) error {
+	if !cfg.Enabled {
+		s.retryBudget = nil
+		return nil
+	}
+	if cfg.MaxPerMinute < 1 {
+		return fmt.Errorf("invalid retry budget max_per_minute: %d (must be at least 1)", cfg.MaxPerMinute)
+	}
+
+	s.retryBudget = &retryBudget{limit: cfg.MaxPerMinute}
+	return nil
+}
+
+// retryDelay books a retry against the retry budget and returns how long it has to wait
+func (s *NotificationService) retryDelay(now time.Time) time.Duration {
+	if s.retryBudget == nil {
+		return 0
+	}
+
+	delay, exhausted := s.retryBudget.reserve(now)
+	if exhausted {
+		s.logger.Warnf("Retry budget exhausted, delaying retries - max_per_minute=%d", s.retryBudget.limit)
+	}
+	return delay
+}
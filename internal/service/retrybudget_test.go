@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRetryBudgetReserve tests that retries over the budget are booked into later minutes
+func TestRetryBudgetReserve(t *testing.T) {
+	budget := &retryBudget{limit: 2}
+	now := time.Date(2026, 3, 1, 12, 0, 10, 0, time.UTC)
+
+	tests := []struct {
+		delay     time.Duration
+		exhausted bool
+	}{
+		{0, false},
+		{0, false},
+		{50 * time.Second, true},
+		{50 * time.Second, false},
+		{110 * time.Second, false},
+	}
+	for i, tt := range tests {
+		delay, exhausted := budget.reserve(now)
+		if delay != tt.delay || exhausted != tt.exhausted {
+			t.Errorf("reserve() #%d = %s, %v, want %s, %v", i+1, delay, exhausted, tt.delay, tt.exhausted)
+		}
+	}
+
+	// Once the booked minutes have passed the budget is available again
+	if delay, _ := budget.reserve(now.Add(3 * time.Minute)); delay != 0 {
+		t.Errorf("Expected no delay in a fresh minute, got %s", delay)
+	}
+}
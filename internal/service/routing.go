@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// routingRule is a parsed tag routing rule
+type routingRule struct {
+	tags      map[string]string
+	notifType domain.NotificationType // Empty matches every type
+	account   string
+	contacts  []string
+}
+
+// WithRoutingConfig routes notifications through the account and to the contacts of the first
+// routing rule whose tags they all carry, and otherwise through the account configured for
+// their type and priority
+func (s *NotificationService) WithRoutingConfig(cfg config.RoutingConfig) error {
+	rules := make([]routingRule, 0, len(cfg.Rules))
+	for _, ruleCfg := range cfg.Rules {
+		rules = append(rules, routingRule{
+			tags:      maps.Clone(ruleCfg.Tags),
+			notifType: domain.NotificationType(ruleCfg.Type),
+			account:   ruleCfg.Account,
+			contacts:  slices.Clone(ruleCfg.Contacts),
+		})
+	}
+
+	priorityAccounts := make(map[domain.NotificationType]map[domain.Priority]string, len(cfg.Priorities))
+	for notifType, accounts := range cfg.Priorities {
+		byPriority := make(map[domain.Priority]string, len(accounts))
+		for name, account := range accounts {
+			priority, err := domain.ParsePriority(name)
+			if err != nil {
+				return fmt.Errorf("invalid routing priority for %s: %w", notifType, err)
+			}
+			byPriority[priority] = account
+		}
+		priorityAccounts[domain.NotificationType(notifType)] = byPriority
+	}
+
+	s.routingRules = rules
+	s.priorityAccounts = priorityAccounts
+	return nil
+}
+
+// route applies the first routing rule matching each notification's tags and type. The rule's
+// account is only used when the notification doesn't name one, and its contacts are added to
+// the notification's. Notifications still without an account get their tenant's default
+// account, then their priority's.
+func (s *NotificationService) route(notifications ...*domain.Notification) {
+	for _, notification := range notifications {
+		if len(notification.Tags) > 0 {
+			s.routeByTags(notification)
+		}
+		if notification.Account == "" {
+			notification.Account = s.tenantAccount(notification.Tenant, notification.Type)
+		}
+		if notification.Account == "" {
+			notification.Account = s.priorityAccount(notification.Type, notification.Priority)
+		}
+	}
+}
+
+// routeByTags applies the first routing rule matching a notification's tags and type
+func (s *NotificationService) routeByTags(notification *domain.Notification) {
+	for _, rule := range s.routingRules {
+		if (rule.notifType != "" && rule.notifType != notification.Type) || !notification.HasTags(rule.tags) {
+			continue
+		}
+		if notification.Account == "" && rule.account != "" {
+			notification.Account = rule.account
+		}
+		for _, contact := range rule.contacts {
+			if !slices.Contains(notification.Contacts, contact) {
+				notification.Contacts = append(notification.Contacts, contact)
+			}
+		}
+		return
+	}
+}
+
+// priorityAccount returns the account configured for a type and priority, or empty for the
+// type's default account
+func (s *NotificationService) priorityAccount(notifType domain.NotificationType, priority domain.Priority) string {
+	return s.priorityAccounts[notifType][priority]
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestRouteByTags tests that the first routing rule matching a notification's tags picks its
+// account and adds its contacts, and that notifications can be listed by tag
+func TestRouteByTags(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.CreateContact(ctx, domain.Contact{
+		Name:      "payments-oncall",
+		Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {"payments"}},
+	}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+	err := svc.WithRoutingConfig(config.RoutingConfig{Rules: []config.RoutingRuleConfig{
+		{Tags: map[string]string{"team": "payments", "env": "prod"}, Type: "stdout", Account: "payments", Contacts: []string{"payments-oncall"}},
+		{Tags: map[string]string{"team": "payments"}, Type: "stdout", Account: "staging"},
+	}})
+	if err != nil {
+		t.Fatalf("WithRoutingConfig() error = %v", err)
+	}
+
+	send := func(id, account string, tags map[string]string) *domain.Notification {
+		t.Helper()
+		notification := &domain.Notification{ID: id, Type: domain.TypeStdout, Account: account, Body: "Payout failed", Recipients: []string{"ops"}, Tags: tags}
+		if _, err := svc.Send(ctx, notification); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		return notification
+	}
+
+	prod := send("prod", "", map[string]string{"team": "payments", "env": "prod", "service": "payouts"})
+	if prod.Account != "payments" || !slices.Equal(prod.Recipients, []string{"ops", "payments"}) {
+		t.Errorf("Expected the payments account and on-call, got account=%q, recipients=%v", prod.Account, prod.Recipients)
+	}
+	staging := send("staging", "", map[string]string{"team": "payments", "env": "staging"})
+	if staging.Account != "staging" || len(staging.Contacts) != 0 {
+		t.Errorf("Expected only the second rule to apply, got account=%q, contacts=%v", staging.Account, staging.Contacts)
+	}
+	named := send("named", "billing", map[string]string{"team": "payments", "env": "prod"})
+	if named.Account != "billing" || !slices.Contains(named.Contacts, "payments-oncall") {
+		t.Errorf("Expected the named account to be kept and the contacts added, got account=%q, contacts=%v", named.Account, named.Contacts)
+	}
+	untagged := send("untagged", "", nil)
+	if untagged.Account != "" || len(untagged.Contacts) != 0 {
+		t.Errorf("Expected an untagged notification not to be routed, got account=%q, contacts=%v", untagged.Account, untagged.Contacts)
+	}
+
+	page, err := svc.ListNotifications(ctx, &domain.NotificationFilter{Tags: map[string]string{"env": "prod"}})
+	if err != nil {
+		t.Fatalf("ListNotifications() error = %v", err)
+	}
+	listed := make([]string, 0, len(page.Notifications))
+	for _, notification := range page.Notifications {
+		listed = append(listed, notification.ID)
+	}
+	slices.Sort(listed)
+	if !slices.Equal(listed, []string{"named", "prod"}) {
+		t.Errorf("Expected the two prod notifications, got %v", listed)
+	}
+}
+
+// TestFailoverRuleTags tests that a failover rule with tags only gives its chain to
+// notifications carrying them
+func TestFailoverRuleTags(t *testing.T) {
+	svc := createTestService(t)
+	err := svc.WithFailoverConfig(config.FailoverConfig{Rules: []config.FailoverRuleConfig{
+		{Type: "stdout", Tags: map[string]string{"env": "prod"}, Fallbacks: []config.FallbackConfig{{Type: "ntfy", Recipients: []string{"alerts"}}}},
+	}})
+	if err != nil {
+		t.Fatalf("WithFailoverConfig() error = %v", err)
+	}
+
+	prod := &domain.Notification{Type: domain.TypeStdout, Tags: map[string]string{"env": "prod"}}
+	dev := &domain.Notification{Type: domain.TypeStdout, Tags: map[string]string{"env": "dev"}}
+	svc.applyFailoverRules(prod, dev)
+	if len(prod.Fallbacks) != 1 || len(dev.Fallbacks) != 0 {
+		t.Errorf("Expected only the prod notification to get fallbacks, got prod=%v, dev=%v", prod.Fallbacks, dev.Fallbacks)
+	}
+}
+
+// TestPriorityAccounts tests that notifications without an account are sent through the account
+// configured for their priority, after tag rules and before their type's default
+func TestPriorityAccounts(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	err := svc.WithRoutingConfig(config.RoutingConfig{
+		Rules: []config.RoutingRuleConfig{{Tags: map[string]string{"team": "payments"}, Type: "stdout", Account: "payments"}},
+		Priorities: map[string]map[string]string{
+			"stdout": {"critical": "transactional", "high": "transactional", "low": "bulk"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("WithRoutingConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		priority domain.Priority
+		account  string
+		tags     map[string]string
+		want     string
+	}{
+		{"critical", domain.PriorityCritical, "", nil, "transactional"},
+		{"low", domain.PriorityLow, "", nil, "bulk"},
+		{"unmapped priority", domain.PriorityNormal, "", nil, ""},
+		{"named account", domain.PriorityCritical, "personal", nil, "personal"},
+		{"tag rule first", domain.PriorityCritical, "", map[string]string{"team": "payments"}, "payments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &domain.Notification{Type: domain.TypeStdout, Account: tt.account, Priority: tt.priority, Body: "x", Recipients: []string{"ops"}, Tags: tt.tags}
+			if _, err := svc.Send(ctx, notification); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if notification.Account != tt.want {
+				t.Errorf("Expected account %q, got %q", tt.want, notification.Account)
+			}
+		})
+	}
+
+	if err := svc.WithRoutingConfig(config.RoutingConfig{Priorities: map[string]map[string]string{"email": {"urgent": "transactional"}}}); err == nil {
+		t.Error("Expected an invalid priority to be rejected")
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// dueAt returns when a notification scheduled for later than now is due
+func dueAt(notification *domain.Notification, now time.Time) (time.Time, bool) {
+	if notification.ScheduledFor == nil || !notification.ScheduledFor.After(now) {
+		return time.Time{}, false
+	}
+	return *notification.ScheduledFor, true
+}
+
+// schedulesNatively reports whether the notifier a notification is sent through can have its
+// provider deliver it at its ScheduledFor time, such as Slack with a bot token. Escalations are
+// always held here, so acknowledging the original can still cancel them.
+func (s *NotificationService) schedulesNatively(notification *domain.Notification) bool {
+	if _, ok := notification.Metadata[domain.EscalationMetadata]; ok {
+		return false
+	}
+	n, err := s.factory.Create(notification.Type, s.resolveAccount(notification))
+	if err != nil {
+		return false
+	}
+	scheduler, ok := n.(domain.NativeScheduler)
+	return ok && scheduler.SchedulesNatively(notification)
+}
+
+// held returns when a notification scheduled for later than now has to be held in the queue
+// until. One its provider can schedule natively isn't held.
+func (s *NotificationService) held(notification *domain.Notification, now time.Time) (time.Time, bool) {
+	deliverAt, ok := dueAt(notification, now)
+	if !ok || s.schedulesNatively(notification) {
+		return time.Time{}, false
+	}
+	return deliverAt, true
+}
+
+// markQueued sets the status a notification is stored with before it's queued: scheduled if
+// it's held in the queue until it's due, or deferred for quiet hours, and queued otherwise. One
+// its provider can schedule natively is queued to be sent straight away for the provider to
+// deliver when it's due.
+func (s *NotificationService) markQueued(notification *domain.Notification) {
+	s.deferForQuietHours(notification)
+	if _, ok := s.held(notification, time.Now()); ok {
+		notification.Status = domain.StatusScheduled
+	} else {
+		notification.Status = domain.StatusQueued
+	}
+}
+
+// enqueue stores the status markQueued set and queues the notification for delivery, holding a
+// scheduled one in the queue until it's due, so a queue that persists its messages keeps it
+// across restarts. The status is stored first because a worker may take the notification off
+// the queue and update it at any moment; it mustn't be touched once it's been queued.
+func (s *NotificationService) enqueue(ctx context.Context, notification *domain.Notification) error {
+	s.updateNotification(notification)
+	if notification.Status != domain.StatusScheduled {
+		return s.queue.Enqueue(ctx, notification)
+	}
+	return s.queue.EnqueueDelayed(ctx, notification, *notification.ScheduledFor)
+}
+
+// enqueueBatch stores the status markQueued set on each notification and queues them for
+// delivery, the ones due now in one batch and each scheduled one held until it's due
+func (s *NotificationService) enqueueBatch(ctx context.Context, notifications []*domain.Notification) error {
+	due := make([]*domain.Notification, 0, len(notifications))
+	var scheduled []*domain.Notification
+	for _, notification := range notifications {
+		if notification.Status == domain.StatusScheduled {
+			scheduled = append(scheduled, notification)
+		} else {
+			s.updateNotification(notification)
+			due = append(due, notification)
+		}
+	}
+
+	if len(due) > 0 {
+		if err := s.queue.EnqueueBatch(ctx, due); err != nil {
+			return err
+		}
+	}
+	for _, notification := range scheduled {
+		if err := s.enqueue(ctx, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queuedMessage describes the outcome of queueing a notification for a send result
+func queuedMessage(notification *domain.Notification) string {
+	if notification.Status == domain.StatusScheduled {
+		return "notification scheduled for " + notification.ScheduledFor.UTC().Format(time.RFC3339)
+	}
+	if notification.Status == domain.StatusDigested {
+		return "notification added to the next digest"
+	}
+	return "notification queued successfully"
+}
+
+// RescheduleNotification delays a notification waiting to be sent until at. The notification
+// stays where it is in the queue; when it's taken off the queue early it's held again until at.
+func (s *NotificationService) RescheduleNotification(ctx context.Context, id string, at time.Time) (*domain.Notification, error) {
+	s.mu.Lock()
+	notification, err := s.getNotification(ctx, id)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+
+	switch notification.Status {
+	case domain.StatusPending, domain.StatusQueued, domain.StatusScheduled:
+	default:
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is %s", domain.ErrNotReschedulable, id, notification.Status)
+	}
+
+	due := time.Now()
+	if notification.ScheduledFor != nil && notification.ScheduledFor.After(due) {
+		due = *notification.ScheduledFor
+	}
+	if !at.After(due) {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s is due at %s", domain.ErrRescheduleEarlier, id, due.UTC().Format(time.RFC3339))
+	}
+
+	notification.ScheduledFor = &at
+	notification.Status = domain.StatusScheduled
+	err = s.store.Update(ctx, notification)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Notification rescheduled - id=%s, scheduled_for=%s", id, at.UTC().Format(time.RFC3339))
+	s.publishStatus(notification)
+	return notification, nil
+}
+
+// rescheduledBeforeDispatch holds a dequeued notification again if it was rescheduled for later
+// while it was queued, replacing its queue message with one due at the new time
+func (s *NotificationService) rescheduledBeforeDispatch(ctx context.Context, msg *domain.QueueMessage) bool {
+	stored, err := s.store.Get(ctx, msg.Notification.ID)
+	if err != nil {
+		return false
+	}
+	deliverAt, ok := s.held(stored, time.Now())
+	if !ok {
+		// A notification its provider schedules natively is sent now for the time it's due
+		msg.Notification.ScheduledFor = stored.ScheduledFor
+		return false
+	}
+
+	// Stored before it's queued again, since a worker may take it off the queue at any moment
+	id := stored.ID
+	stored.Status = domain.StatusScheduled
+	s.store.Update(ctx, stored)
+	if err := s.queue.EnqueueDelayed(ctx, stored, deliverAt); err != nil {
+		s.logger.Errorf("Failed to hold rescheduled notification, sending now - id=%s, error=%v", id, err)
+		return false
+	}
+	s.queue.Ack(ctx, msg.ID)
+	s.logger.Debugf("Holding rescheduled notification - id=%s, scheduled_for=%s", id, deliverAt.UTC().Format(time.RFC3339))
+	return true
+}
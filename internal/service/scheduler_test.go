@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// TestScheduledNotificationsWaitUntilDue tests that a notification scheduled for the future is
+// reported scheduled and only sent once it's due, while the rest of its batch goes out at once
+func TestScheduledNotificationsWaitUntilDue(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	scheduledFor := time.Now().Add(500 * time.Millisecond)
+	results, err := svc.SendBatch(ctx, []*domain.Notification{
+		{ID: "n-later", Type: domain.TypeStdout, Body: "later", Recipients: []string{"console"}, ScheduledFor: &scheduledFor},
+		{ID: "n-now", Type: domain.TypeStdout, Body: "now", Recipients: []string{"console"}},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if !strings.HasPrefix(results[0].Message, "notification scheduled for ") {
+		t.Errorf("Unexpected result message for the scheduled notification: %q", results[0].Message)
+	}
+	if n, _ := svc.GetNotification(ctx, "n-later"); n.Status != domain.StatusScheduled {
+		t.Errorf("Expected status scheduled, got %s", n.Status)
+	}
+
+	waitForStatus(t, svc, "n-now", domain.StatusSent)
+	if n, _ := svc.GetNotification(ctx, "n-later"); n.Status != domain.StatusScheduled {
+		t.Errorf("Expected the scheduled notification to wait, got %s", n.Status)
+	}
+
+	waitForStatus(t, svc, "n-later", domain.StatusSent)
+	if time.Now().Before(scheduledFor) {
+		t.Error("Scheduled notification was sent before it was due")
+	}
+}
+
+// TestPastScheduledForSendsImmediately tests that a notification scheduled for the past is
+// queued like any other
+func TestPastScheduledForSendsImmediately(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	scheduledFor := time.Now().Add(-time.Minute)
+	result, err := svc.Send(ctx, &domain.Notification{ID: "n-past", Type: domain.TypeStdout, Body: "late", Recipients: []string{"console"}, ScheduledFor: &scheduledFor})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if result.Message != "notification queued successfully" {
+		t.Errorf("Unexpected result message: %q", result.Message)
+	}
+	if n, _ := svc.GetNotification(ctx, "n-past"); n.Status != domain.StatusQueued {
+		t.Errorf("Expected status queued, got %s", n.Status)
+	}
+}
+
+// TestSlackSchedulesNatively tests that a notification Slack can schedule with its bot token is
+// handed to chat.scheduleMessage straight away instead of being held in the queue, while one
+// further ahead than Slack accepts is still held
+func TestSlackSchedulesNatively(t *testing.T) {
+	var scheduled []int64
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg struct {
+			Channel string `json:"channel"`
+			PostAt  int64  `json:"post_at"`
+		}
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		scheduled = append(scheduled, msg.PostAt)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "channel": msg.Channel, "post_at": msg.PostAt})
+	}))
+	defer server.Close()
+
+	slack, err := notifier.NewSlackNotifier(&notifier.SlackConfig{Token: "xoxb-test", APIURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewSlackNotifier() error = %v", err)
+	}
+	factory := notifier.NewFactory()
+	factory.RegisterNotifier(domain.TypeSlack, "", slack)
+	q, _ := queue.NewLocalQueue(nil)
+	logger, _ := logging.NewFromConfig("error", "stdout")
+	svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+
+	ctx := context.Background()
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	soon := time.Now().Add(time.Hour).Truncate(time.Second)
+	tooFar := time.Now().Add(200 * 24 * time.Hour)
+	results, err := svc.SendBatch(ctx, []*domain.Notification{
+		{ID: "n-soon", Type: domain.TypeSlack, Body: "soon", Recipients: []string{"#ops"}, ScheduledFor: &soon},
+		{ID: "n-far", Type: domain.TypeSlack, Body: "far", Recipients: []string{"#ops"}, ScheduledFor: &tooFar},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if results[0].Message != "notification queued successfully" {
+		t.Errorf("Expected the natively scheduled notification to be queued, got %q", results[0].Message)
+	}
+
+	waitForStatus(t, svc, "n-soon", domain.StatusSent)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(scheduled) != 1 || scheduled[0] != soon.Unix() {
+		t.Errorf("Expected one chat.scheduleMessage call for %d, got %v", soon.Unix(), scheduled)
+	}
+	if n, _ := svc.GetNotification(ctx, "n-far"); n.Status != domain.StatusScheduled {
+		t.Errorf("Expected the notification beyond Slack's limit to be held, got %s", n.Status)
+	}
+	if metrics := q.QueueMetrics(); metrics.Delayed != 1 {
+		t.Errorf("Expected one notification held in the queue, got %d", metrics.Delayed)
+	}
+}
+
+// TestRescheduleQueuedNotification tests that a notification rescheduled while it's queued is
+// held until its new time instead of being sent when it's taken off the queue
+func TestRescheduleQueuedNotification(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.Send(ctx, &domain.Notification{ID: "n-snooze", Type: domain.TypeStdout, Body: "snooze", Recipients: []string{"console"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	at := time.Now().Add(500 * time.Millisecond)
+	rescheduled, err := svc.RescheduleNotification(ctx, "n-snooze", at)
+	if err != nil {
+		t.Fatalf("RescheduleNotification() error = %v", err)
+	}
+	if rescheduled.Status != domain.StatusScheduled || !rescheduled.ScheduledFor.Equal(at) {
+		t.Errorf("Expected scheduled for %v, got %s %v", at, rescheduled.Status, rescheduled.ScheduledFor)
+	}
+
+	if err := svc.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer svc.Stop()
+
+	waitForStatus(t, svc, "n-snooze", domain.StatusSent)
+	if time.Now().Before(at) {
+		t.Error("Rescheduled notification was sent before its new time")
+	}
+}
+
+// TestRescheduleNotificationErrors tests that only notifications waiting to be sent can be
+// rescheduled, and only for later than they're due
+func TestRescheduleNotificationErrors(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	scheduledFor := time.Now().Add(time.Hour)
+	if _, err := svc.Send(ctx, &domain.Notification{ID: "n-later", Type: domain.TypeStdout, Body: "later", Recipients: []string{"console"}, ScheduledFor: &scheduledFor}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if _, err := svc.Send(ctx, &domain.Notification{ID: "n-cancelled", Type: domain.TypeStdout, Body: "x", Recipients: []string{"console"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := svc.CancelNotification(ctx, "n-cancelled"); err != nil {
+		t.Fatalf("CancelNotification() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		id   string
+		at   time.Time
+		want error
+	}{
+		{"earlier than scheduled", "n-later", scheduledFor.Add(-time.Minute), domain.ErrRescheduleEarlier},
+		{"not waiting to be sent", "n-cancelled", scheduledFor, domain.ErrNotReschedulable},
+		{"unknown", "n-missing", scheduledFor, domain.ErrNotificationNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.RescheduleNotification(ctx, tt.id, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("RescheduleNotification() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// waitForStatus waits up to five seconds for a notification to reach a status
+func waitForStatus(t *testing.T, svc *NotificationService, id string, status domain.NotificationStatus) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		n, err := svc.GetNotification(context.Background(), id)
+		if err == nil && n.Status == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Notification %s didn't reach status %s, got %+v", id, status, n)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
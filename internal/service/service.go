@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/igodwin/notifier/internal/archive"
 	"github.com/igodwin/notifier/internal/auth"
 	"github.com/igodwin/notifier/internal/config"
 	"github.com/igodwin/notifier/internal/domain"
 	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/providerstatus"
+	"github.com/igodwin/notifier/internal/store"
+	"github.com/igodwin/notifier/internal/templates"
 )
 
 // AccountResolver is an interface for resolving default accounts
@@ -19,20 +25,68 @@ type AccountResolver interface {
 
 // NotificationService implements the domain.NotificationService interface
 type NotificationService struct {
-	factory                domain.NotifierFactory
-	queue                  domain.Queue
-	accountResolver        AccountResolver
-	authz                  *auth.NotifierAuthz
-	notifications          map[string]*domain.Notification
-	mu                     sync.RWMutex
-	workerCount            int
-	stopChan               chan struct{}
-	wg                     sync.WaitGroup
-	logger                 *logging.Logger
-	retentionConfig        config.NotificationRetentionConfig
-	cleanupStopChan        chan struct{}
-	ttlDuration            time.Duration
-	checkFrequencyDuration time.Duration
+	factory                 domain.NotifierFactory
+	queue                   domain.Queue
+	accountResolver         AccountResolver
+	authz                   *auth.NotifierAuthz
+	store                   domain.NotificationStore
+	mu                      sync.Mutex // Serializes changes that read a stored notification first
+	workerCount             int
+	stopChan                chan struct{}
+	wg                      sync.WaitGroup
+	logger                  *logging.Logger
+	retentionConfig         config.NotificationRetentionConfig
+	cleanupStopChan         chan struct{}
+	ttlDuration             time.Duration
+	checkFrequencyDuration  time.Duration
+	retentionMu             sync.Mutex
+	retentionMetrics        domain.RetentionMetrics
+	dispatcher              *dispatcher
+	slo                     *sloTracker
+	sloConfig               config.SLOConfig
+	sloCheckFrequency       time.Duration
+	watchdog                *failureWatchdog
+	watchdogConfig          config.WatchdogConfig
+	watchdogCheckFrequency  time.Duration
+	heartbeats              *heartbeatMonitor
+	heartbeatCheckFrequency time.Duration
+	canary                  *canaryMonitor
+	canaryCheckFrequency    time.Duration
+	archiver                *archive.Archiver
+	providerStatus          *providerstatus.Monitor
+	outageRetryDelay        time.Duration
+	hedgeRules              map[string]hedgeRule
+	hedgeMinPriority        domain.Priority
+	hedgingConfig           config.HedgingConfig
+	failoverRules           []failoverRule
+	routingRules            []routingRule
+	priorityAccounts        map[domain.NotificationType]map[domain.Priority]string
+	tenancy                 *tenancy
+	quotas                  *auth.Quotas
+	quietWindows            []quietWindow
+	budgets                 *budgetTracker
+	budgetConfig            config.BudgetConfig
+	contentPolicy           *contentPolicy
+	contentPolicyConfig     config.ContentPolicyConfig
+	spamCheck               *spamCheck
+	events                  *statusBroadcaster
+	jobs                    *sendJobs
+	pauses                  *dispatchPauses
+	blackouts               *blackoutWindows
+	deliveries              *deliveryTracker
+	templates               *templates.Engine
+	contacts                map[string]domain.Contact
+	recurring               *recurringNotifications
+	retryBudget             *retryBudget
+	retryBackoff            *retryBackoff
+	webCopy                 *webCopy
+	replies                 *replyRouting
+	ackActions              *ackActions
+	strict                  *strictMode
+	limits                  config.LimitsConfig
+	dedup                   *dedupWindow
+	digest                  *digestBuffer
+	shutdownReportConfig    config.ShutdownReportConfig
 }
 
 // NewNotificationService creates a new notification service
@@ -46,14 +100,26 @@ func NewNotificationService(factory domain.NotifierFactory, queue domain.Queue,
 		queue:           queue,
 		accountResolver: accountResolver,
 		authz:           authz,
-		notifications:   make(map[string]*domain.Notification),
+		store:           store.NewMemoryStore(),
 		workerCount:     workerCount,
 		stopChan:        make(chan struct{}),
 		logger:          logger,
 		cleanupStopChan: make(chan struct{}),
+		events:          newStatusBroadcaster(),
+		jobs:            newSendJobs(),
+		pauses:          newDispatchPauses(),
+		blackouts:       newBlackoutWindows(),
+		deliveries:      newDeliveryTracker(),
+		templates:       templates.NewEngine(),
+		recurring:       newRecurringNotifications(),
 	}
 }
 
+// WithNotificationStore replaces the default in-memory notification store
+func (s *NotificationService) WithNotificationStore(store domain.NotificationStore) {
+	s.store = store
+}
+
 // WithRetentionConfig sets the notification retention configuration
 func (s *NotificationService) WithRetentionConfig(cfg config.NotificationRetentionConfig) error {
 	s.retentionConfig = cfg
@@ -75,8 +141,64 @@ func (s *NotificationService) WithRetentionConfig(cfg config.NotificationRetenti
 	return nil
 }
 
+// WithDispatchConfig configures per-category queue disciplines.
+// The dispatcher is only used when a non-FIFO discipline is configured.
+func (s *NotificationService) WithDispatchConfig(cfg config.DispatchConfig) error {
+	if !cfg.Enabled() {
+		s.dispatcher = nil
+		return nil
+	}
+
+	d, err := newDispatcher(cfg)
+	if err != nil {
+		return fmt.Errorf("invalid dispatch config: %w", err)
+	}
+	s.dispatcher = d
+
+	return nil
+}
+
+// WithProviderStatusConfig enables provider status polling. While a feed declares an outage,
+// failed sends to the affected accounts are retried after RetryDelay without counting against
+// their retry budget or failure alerts.
+func (s *NotificationService) WithProviderStatusConfig(cfg providerstatus.Config) error {
+	if !cfg.Enabled {
+		s.providerStatus = nil
+		return nil
+	}
+
+	retryDelay, err := time.ParseDuration(cfg.RetryDelay)
+	if err != nil {
+		return fmt.Errorf("invalid retry delay duration: %w", err)
+	}
+
+	monitor, err := providerstatus.NewMonitor(cfg, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.providerStatus = monitor
+	s.outageRetryDelay = retryDelay
+
+	return nil
+}
+
+// activeOutage returns the provider incident affecting an account, if status polling is enabled
+func (s *NotificationService) activeOutage(notifType domain.NotificationType, account string) (*providerstatus.Incident, bool) {
+	if s.providerStatus == nil {
+		return nil, false
+	}
+	return s.providerStatus.ActiveOutage(notifType, account)
+}
+
 // Start starts the worker pool and cleanup goroutine
 func (s *NotificationService) Start(ctx context.Context) error {
+	// Start the dispatcher feeding workers in discipline order
+	if s.dispatcher != nil {
+		s.wg.Add(1)
+		go s.dispatchLoop(ctx)
+	}
+
 	for i := 0; i < s.workerCount; i++ {
 		s.wg.Add(1)
 		go s.worker(ctx, i)
@@ -88,17 +210,87 @@ func (s *NotificationService) Start(ctx context.Context) error {
 		go s.cleanupLoop(ctx)
 	}
 
+	// Start SLO evaluation if tracking is enabled
+	if s.slo != nil && s.sloCheckFrequency > 0 {
+		s.wg.Add(1)
+		go s.sloLoop(ctx)
+	}
+
+	// Start the failure watchdog if enabled
+	if s.watchdog != nil && s.watchdogCheckFrequency > 0 {
+		s.wg.Add(1)
+		go s.watchdogLoop(ctx)
+	}
+
+	// Start checking heartbeats if enabled
+	if s.heartbeats != nil && s.heartbeatCheckFrequency > 0 {
+		s.wg.Add(1)
+		go s.heartbeatLoop(ctx)
+	}
+
+	// Start sending canaries if enabled
+	if s.canary != nil && s.canaryCheckFrequency > 0 {
+		s.wg.Add(1)
+		go s.canaryLoop(ctx)
+	}
+
+	// Start sending digests if enabled
+	if s.digest != nil {
+		s.wg.Add(1)
+		go s.digestLoop(ctx)
+	}
+
+	// Start sending recurring notifications as they come due
+	s.wg.Add(1)
+	go s.recurrenceLoop(ctx)
+
+	// Start archiving completed notifications if enabled
+	if s.archiver != nil {
+		s.wg.Add(1)
+		go s.archiveLoop(ctx)
+	}
+
+	// Start polling provider status feeds if configured
+	if s.providerStatus != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.providerStatus.Run(ctx, s.stopChan)
+		}()
+	}
+
 	return nil
 }
 
-// Stop stops the service gracefully
+// Stop stops the service gracefully, reporting what the queue still holds before it's closed
 func (s *NotificationService) Stop() error {
+	before := s.QueueMetrics()
 	close(s.stopChan)
 	close(s.cleanupStopChan)
+	s.stopSendJobs()
 	s.wg.Wait()
+	s.returnDispatched()
+	s.reportShutdown(s.buildShutdownReport(before))
 	return s.queue.Close()
 }
 
+// returnDispatched hands the messages still buffered by the dispatcher back to the queue,
+// so they're redelivered instead of lost when the queue is closed
+func (s *NotificationService) returnDispatched() {
+	if s.dispatcher == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, msg := range s.dispatcher.drain() {
+		if err := s.queue.Nack(ctx, msg.ID, true); err != nil {
+			s.logger.Errorf("Failed to return dispatched message to the queue - id=%s, error=%v", msg.ID, err)
+		}
+	}
+}
+
 // cleanupLoop runs at regular intervals to clean up old or excessive notifications
 func (s *NotificationService) cleanupLoop(ctx context.Context) {
 	defer s.wg.Done()
@@ -120,65 +312,131 @@ func (s *NotificationService) cleanupLoop(ctx context.Context) {
 	}
 }
 
-// performCleanup removes expired notifications and enforces maximum size limit
+// performCleanup removes sent and failed notifications that have expired, then the oldest
+// of them while the store holds more than the maximum size. Notifications still waiting to
+// be delivered are never removed.
 func (s *NotificationService) performCleanup() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	ctx := context.Background()
 
-	now := time.Now()
-	expiredBefore := now.Add(-s.ttlDuration)
+	// The store lists oldest first
+	notifications, err := s.store.List(ctx, nil)
+	if err != nil {
+		s.recordCleanup(0, 0, -1)
+		s.logger.Errorf("Cleanup failed to list notifications - error=%v", err)
+		return
+	}
 
-	// Track which notifications to delete
-	var toDelete []string
-	var allNotifications []*domain.Notification
+	expiredBefore := time.Now().Add(-s.ttlDuration)
 
-	// First pass: identify expired notifications and collect all for sorting
-	for id, notification := range s.notifications {
+	var expired, completed []string
+	for _, notification := range notifications {
+		done := notification.Status == domain.StatusSent || notification.Status == domain.StatusFailed ||
+			(notification.Status == domain.StatusDigested && notification.DigestID != "")
+		if !done {
+			continue
+		}
 		if notification.CreatedAt.Before(expiredBefore) {
-			toDelete = append(toDelete, id)
+			expired = append(expired, notification.ID)
+		} else {
+			completed = append(completed, notification.ID)
+		}
+	}
+
+	// Then enforce the size limit with the oldest of the unexpired ones
+	var excess []string
+	if over := len(notifications) - len(expired) - s.retentionConfig.MaxSize; s.retentionConfig.MaxSize > 0 && over > 0 {
+		excess = completed[:min(over, len(completed))]
+	}
+
+	if toDelete := append(expired, excess...); len(toDelete) > 0 {
+		if err := s.store.Delete(ctx, toDelete...); err != nil {
+			s.recordCleanup(0, 0, -1)
+			s.logger.Errorf("Cleanup failed to delete notifications - error=%v", err)
+			return
 		}
-		allNotifications = append(allNotifications, notification)
 	}
 
-	// Delete expired notifications
-	for _, id := range toDelete {
-		delete(s.notifications, id)
+	currentSize := len(notifications) - len(expired) - len(excess)
+	s.recordCleanup(len(expired), len(excess), currentSize)
+
+	// Log cleanup statistics
+	if len(expired) > 0 || currentSize > s.retentionConfig.MaxSize {
+		s.logger.Infof("Cleanup completed - expired=%d, excess=%d, current_size=%d, max_size=%d",
+			len(expired), len(excess), currentSize, s.retentionConfig.MaxSize)
 	}
+}
 
-	expiredCount := len(toDelete)
+// recordCleanup adds a cleanup run to the retention metrics. A negative size marks a failed run.
+func (s *NotificationService) recordCleanup(expired, excess, size int) {
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
 
-	// Second pass: enforce max size limit by removing oldest notifications
-	if s.retentionConfig.MaxSize > 0 && len(s.notifications) > s.retentionConfig.MaxSize {
-		excessCount := len(s.notifications) - s.retentionConfig.MaxSize
+	s.retentionMetrics.Runs++
+	s.retentionMetrics.LastRunAt = time.Now()
+	if size < 0 {
+		s.retentionMetrics.Failures++
+		return
+	}
+	s.retentionMetrics.PrunedExpired += uint64(expired)
+	s.retentionMetrics.PrunedExcess += uint64(excess)
+	s.retentionMetrics.Stored = int64(size)
+}
 
-		// Sort remaining notifications by creation time (oldest first)
-		remaining := make([]*domain.Notification, 0, len(s.notifications))
-		for _, notification := range s.notifications {
-			remaining = append(remaining, notification)
+// RetentionMetrics reports how many notifications the retention cleanup has pruned
+func (s *NotificationService) RetentionMetrics() domain.RetentionMetrics {
+	s.retentionMu.Lock()
+	defer s.retentionMu.Unlock()
+	return s.retentionMetrics
+}
+
+// dispatchLoop moves messages from the queue into the dispatcher buffer
+func (s *NotificationService) dispatchLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	// Stop blocking on a full dispatcher buffer once the service is stopping
+	loopCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopChan:
+			cancel()
+		case <-loopCtx.Done():
 		}
+	}()
 
-		// Simple bubble sort to find oldest notifications (more efficient alternatives available)
-		for i := 0; i < len(remaining)-1; i++ {
-			for j := 0; j < len(remaining)-i-1; j++ {
-				if remaining[j].CreatedAt.After(remaining[j+1].CreatedAt) {
-					remaining[j], remaining[j+1] = remaining[j+1], remaining[j]
-				}
+	for {
+		select {
+		case <-loopCtx.Done():
+			return
+		default:
+		}
+
+		dequeueCtx, dequeueCancel := context.WithTimeout(loopCtx, 5*time.Second)
+		msg, err := s.queue.Dequeue(dequeueCtx)
+		dequeueCancel()
+
+		if err != nil || msg == nil {
+			if err == context.DeadlineExceeded {
+				continue
 			}
+			time.Sleep(100 * time.Millisecond)
+			continue
 		}
 
-		// Delete the oldest excessCount notifications
-		for i := 0; i < excessCount && i < len(remaining); i++ {
-			delete(s.notifications, remaining[i].ID)
+		if err := s.dispatcher.push(loopCtx, msg); err != nil {
+			// Shutting down: return the message to the queue so it isn't lost
+			s.queue.Nack(context.Background(), msg.ID, true)
+			return
 		}
 	}
+}
 
-	currentSize := len(s.notifications)
-
-	// Log cleanup statistics
-	if expiredCount > 0 || currentSize > s.retentionConfig.MaxSize {
-		s.logger.Infof("Cleanup completed - expired=%d, current_size=%d, max_size=%d",
-			expiredCount, currentSize, s.retentionConfig.MaxSize)
+// dequeue returns the next message for a worker, honoring the dispatcher order when configured
+func (s *NotificationService) dequeue(ctx context.Context) (*domain.QueueMessage, error) {
+	if s.dispatcher != nil {
+		return s.dispatcher.next(ctx)
 	}
+	return s.queue.Dequeue(ctx)
 }
 
 // worker processes notifications from the queue
@@ -192,9 +450,14 @@ func (s *NotificationService) worker(ctx context.Context, id int) {
 		case <-ctx.Done():
 			return
 		default:
+			// Take nothing while every notifier is paused
+			if !s.waitWhilePaused(ctx) {
+				return
+			}
+
 			// Try to dequeue with timeout
 			workerCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-			msg, err := s.queue.Dequeue(workerCtx)
+			msg, err := s.dequeue(workerCtx)
 			cancel()
 
 			if err != nil {
@@ -220,13 +483,39 @@ func (s *NotificationService) worker(ctx context.Context, id int) {
 func (s *NotificationService) processNotification(ctx context.Context, msg *domain.QueueMessage) {
 	notification := msg.Notification
 
+	// Drop notifications cancelled while they were queued, such as acknowledged escalations
+	if s.cancelledBeforeDispatch(notification) {
+		s.logger.Debugf("Skipping cancelled notification - id=%s", notification.ID)
+		s.queue.Ack(ctx, msg.ID)
+		return
+	}
+
+	// Hold notifications rescheduled for later while they were queued
+	if s.rescheduledBeforeDispatch(ctx, msg) {
+		return
+	}
+
 	s.logger.Debugf("Processing notification - id=%s, type=%s, recipients=%d",
 		notification.ID, notification.Type, len(notification.Recipients))
+	s.publishStatus(notification)
 
 	// Resolve account if not specified
-	account := notification.Account
-	if account == "" && s.accountResolver != nil {
-		account = s.accountResolver.GetDefaultAccount(notification.Type)
+	account := s.resolveAccount(notification)
+
+	// Set notifications for a paused account aside, in flight in the queue, without using a retry
+	parked := s.pauses.park(msg.ID, notification.Type, account, func(pause *domain.DispatchPause) {
+		s.logger.Debugf("Dispatch paused, parking notification - id=%s, type=%s, account=%s, reason=%s",
+			notification.ID, notification.Type, account, pause.Reason)
+		notification.Status = domain.StatusPaused
+		s.updateNotification(notification)
+	})
+	if parked {
+		return
+	}
+
+	// Set notifications aside during a maintenance window until it ends
+	if s.holdForBlackout(msg, account) {
+		return
 	}
 
 	// Get the appropriate notifier
@@ -237,14 +526,23 @@ func (s *NotificationService) processNotification(ctx context.Context, msg *doma
 		notification.Status = domain.StatusFailed
 		notification.LastError = fmt.Sprintf("failed to create notifier: %v", err)
 		s.queue.Nack(ctx, msg.ID, false)
+		s.recordDelivery(msg, false)
+		s.sendFallback(ctx, notification)
 		s.updateNotification(notification)
 		return
 	}
 
 	// Send the notification
-	result, err := notifier.Send(ctx, notification)
+	notification.NextAttemptAt = nil
+	var result *domain.NotificationResult
+	if notification.DryRun {
+		result, err = dryRunSend(notifier, notification)
+	} else if rule, ok := s.hedgeRuleFor(notification, account); ok {
+		result, err = s.sendHedged(ctx, notifier, notification, account, rule)
+	} else {
+		result, err = notifier.Send(ctx, notification)
+	}
 	if err != nil || result == nil || !result.Success {
-		notification.RetryCount++
 		if result != nil {
 			notification.LastError = result.Error
 		}
@@ -252,22 +550,45 @@ func (s *NotificationService) processNotification(ctx context.Context, msg *doma
 			notification.LastError = err.Error()
 		}
 
+		// During a declared provider outage, back off without using up retries or raising alarms
+		if incident, ok := s.activeOutage(notification.Type, account); ok {
+			notification.Status = domain.StatusRetrying
+			s.logger.Debugf("Notification send failed during provider outage, deferring retry - id=%s, type=%s, account=%s, incident=%s, retry_in=%s",
+				notification.ID, notification.Type, account, incident.Title, s.outageRetryDelay)
+			nextAttempt := time.Now().Add(s.outageRetryDelay)
+			notification.NextAttemptAt = &nextAttempt
+			s.updateNotification(notification)
+			if err := s.queue.NackDelayed(ctx, msg.ID, nextAttempt); err != nil {
+				s.logger.Errorf("Failed to requeue deferred retry - message_id=%s, error=%v", msg.ID, err)
+			}
+			return
+		}
+
+		s.recordAttempt(notification, account, true)
+		notification.RetryCount++
+
 		// Check if we should retry
 		if notification.RetryCount < notification.MaxRetries {
 			notification.Status = domain.StatusRetrying
 			s.logger.Warnf("Notification send failed, will retry - id=%s, type=%s, account=%s, attempt=%d/%d, error=%s",
 				notification.ID, notification.Type, account, notification.RetryCount, notification.MaxRetries, notification.LastError)
-			s.queue.Nack(ctx, msg.ID, true) // Requeue
-		} else {
-			notification.Status = domain.StatusFailed
-			s.logger.Errorf("Notification send failed permanently - id=%s, type=%s, account=%s, recipients=%v, attempts=%d, error=%s",
-				notification.ID, notification.Type, account, notification.Recipients, notification.RetryCount, notification.LastError)
-			s.queue.Nack(ctx, msg.ID, false) // Don't requeue
+			s.scheduleRetry(ctx, msg)
+			return
 		}
+
+		notification.Status = domain.StatusFailed
+		s.logger.Errorf("Notification send failed permanently - id=%s, type=%s, account=%s, recipients=%v, attempts=%d, error=%s",
+			notification.ID, notification.Type, account, notification.Recipients, notification.RetryCount, notification.LastError)
+		s.queue.Nack(ctx, msg.ID, false) // Don't requeue
+		s.recordDelivery(msg, false)
+		s.sendFallback(ctx, notification)
 	} else {
+		s.recordAttempt(notification, account, false)
 		notification.Status = domain.StatusSent
 		now := time.Now()
 		notification.SentAt = &now
+		notification.Links = result.Links
+		s.recordDelivery(msg, true)
 		s.queue.Ack(ctx, msg.ID)
 		s.logger.Infof("Notification sent successfully - id=%s, type=%s, account=%s, recipients=%v",
 			notification.ID, notification.Type, account, notification.Recipients)
@@ -276,8 +597,31 @@ func (s *NotificationService) processNotification(ctx context.Context, msg *doma
 	s.updateNotification(notification)
 }
 
+// dryRunSend validates a dry-run notification with its notifier in place of sending it
+func dryRunSend(notifier domain.Notifier, notification *domain.Notification) (*domain.NotificationResult, error) {
+	if err := notifier.Validate(notification); err != nil {
+		return nil, err
+	}
+	return &domain.NotificationResult{
+		NotificationID: notification.ID,
+		Success:        true,
+		Message:        "dry run: validated but not delivered",
+		SentAt:         time.Now(),
+	}, nil
+}
+
 // Send queues a notification for delivery
 func (s *NotificationService) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	stampTenant(ctx, notification)
+
+	// A notification with targets is sent as one notification per target
+	if len(notification.Targets) > 0 {
+		return s.sendFanout(ctx, notification)
+	}
+
+	// Route by tags and priority first, so authorization sees the account it's sent through
+	s.route(notification)
+
 	// Enforce RBAC authorization if configured
 	if err := s.checkAuthorization(ctx, notification); err != nil {
 		return &domain.NotificationResult{
@@ -288,30 +632,170 @@ func (s *NotificationService) Send(ctx context.Context, notification *domain.Not
 		}, err
 	}
 
-	// Store the notification
-	s.storeNotification(notification)
+	// Render the notification's template, if it has one, so the checks below see what's sent
+	if err := s.renderTemplates(ctx, notification); err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
 
-	// Enqueue for processing
-	if err := s.queue.Enqueue(ctx, notification); err != nil {
+	// Add the addresses of the contacts it's sent to, so the limits below count them
+	if err := s.resolveContacts(ctx, notification); err != nil {
 		return &domain.NotificationResult{
 			NotificationID: notification.ID,
 			Success:        false,
-			Error:          fmt.Sprintf("failed to enqueue: %v", err),
+			Error:          err.Error(),
 			SentAt:         time.Now(),
 		}, err
 	}
 
-	return &domain.NotificationResult{
+	// Reject notifications over the configured size limits
+	if err := s.checkLimits(notification); err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	s.stampOrigin(ctx, notification)
+	s.applyFailoverRules(notification)
+
+	// Reject unrecognised metadata and options keys in strict mode
+	if err := s.checkStrict(ctx, notification); err != nil {
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// A recurring notification is kept as the template of its occurrences instead of being sent
+	if notification.Recurrence != "" {
+		return s.createRecurrence(notification)
+	}
+
+	// Count a duplicate of a notification sent within the dedup window instead of sending it
+	if result := s.suppressDuplicate(notification); result != nil {
+		return result, nil
+	}
+
+	// Check content against policy rules and score email for spam before it counts against any budget
+	err := s.checkContent(notification)
+	if err == nil {
+		err = s.checkSpam(ctx, notification)
+	}
+	if err != nil {
+		s.releaseDedup(notification)
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// Enforce the client's and tenant's daily quotas, then per-origin daily budgets, if configured.
+	// A notification rejected or failing after it's counted is refunded to the quotas.
+	err = s.checkQuotas(ctx, notification)
+	if err == nil {
+		if err = s.checkBudgets(ctx, notification); err != nil {
+			s.refundQuotas(ctx, notification)
+		}
+	}
+	if err != nil {
+		s.releaseDedup(notification)
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// Link HTML email to its hosted web copy, give email its reply address and add acknowledge buttons
+	s.addWebCopyLinks(notification)
+	s.addReplyAddresses(notification)
+	s.addAckActions(notification)
+
+	// Store the notification
+	if err := s.storeNotification(notification); err != nil {
+		s.releaseDedup(notification)
+		s.refundQuotas(ctx, notification)
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        false,
+			Error:          err.Error(),
+			SentAt:         time.Now(),
+		}, err
+	}
+
+	// Held notifications wait for approval instead of being queued
+	if notification.Status == domain.StatusHeld {
+		s.publishStatus(notification)
+		s.announceHeld(ctx, notification)
+		return &domain.NotificationResult{
+			NotificationID: notification.ID,
+			Success:        true,
+			Message:        "notification held for approval",
+			SentAt:         time.Now(),
+		}, nil
+	}
+
+	// Mark it queued, scheduled or added to the next digest, and describe the outcome, before
+	// handing it over: a worker or the digest loop may update it as soon as it's handed over
+	digest := s.digestible(notification)
+	if digest {
+		notification.Status = domain.StatusDigested
+	} else {
+		s.markQueued(notification)
+	}
+	result := &domain.NotificationResult{
 		NotificationID: notification.ID,
 		Success:        true,
-		Message:        "notification queued successfully",
+		Message:        queuedMessage(notification),
 		SentAt:         time.Now(),
-	}, nil
+	}
+
+	// Enqueue for processing, holding scheduled notifications until they're due, unless it
+	// waits for the next digest
+	if digest {
+		s.addToDigest(ctx, notification)
+	} else if err := s.enqueue(ctx, notification); err != nil {
+		s.releaseDedup(notification)
+		s.refundQuotas(ctx, notification)
+		return &domain.NotificationResult{
+			NotificationID: result.NotificationID,
+			Success:        false,
+			Error:          fmt.Sprintf("failed to enqueue: %v", err),
+			SentAt:         time.Now(),
+		}, err
+	}
+	return result, nil
 }
 
 // SendBatch queues multiple notifications for delivery
 func (s *NotificationService) SendBatch(ctx context.Context, notifications []*domain.Notification) ([]*domain.NotificationResult, error) {
 	results := make([]*domain.NotificationResult, 0, len(notifications))
+	stampTenant(ctx, notifications...)
+
+	// Recurring and fanned-out notifications are sent one at a time
+	for _, notification := range notifications {
+		if notification.Recurrence != "" {
+			return nil, fmt.Errorf("%w: recurring notifications can't be sent in a batch", domain.ErrInvalidRecurrence)
+		}
+		if len(notification.Targets) > 0 {
+			return nil, fmt.Errorf("%w: notifications with targets can't be sent in a batch", domain.ErrInvalidTargets)
+		}
+	}
+
+	// Route by tags and priority first, so authorization sees the accounts they're sent through
+	s.route(notifications...)
 
 	// Enforce RBAC authorization for each notification
 	for _, notification := range notifications {
@@ -320,91 +804,216 @@ func (s *NotificationService) SendBatch(ctx context.Context, notifications []*do
 		}
 	}
 
-	// Store all notifications
+	// Reject the batch if any notification's template doesn't render
+	if err := s.renderTemplates(ctx, notifications...); err != nil {
+		return nil, err
+	}
+
+	// Reject the batch if any notification is sent to a contact without an address on its channel
+	if err := s.resolveContacts(ctx, notifications...); err != nil {
+		return nil, err
+	}
+
+	// Reject the batch if any notification is over the configured size limits
+	if err := s.checkLimits(notifications...); err != nil {
+		return nil, err
+	}
+
+	for _, notification := range notifications {
+		s.stampOrigin(ctx, notification)
+	}
+	s.applyFailoverRules(notifications...)
+
+	// Reject the batch if any notification has unrecognised keys in strict mode
+	if err := s.checkStrict(ctx, notifications...); err != nil {
+		return nil, err
+	}
+
+	// Set aside duplicates of notifications sent within the dedup window, including earlier
+	// ones in the batch
+	suppressed := make(map[*domain.Notification]*domain.NotificationResult)
+	fresh := make([]*domain.Notification, 0, len(notifications))
 	for _, notification := range notifications {
-		s.storeNotification(notification)
+		if result := s.suppressDuplicate(notification); result != nil {
+			suppressed[notification] = result
+		} else {
+			fresh = append(fresh, notification)
+		}
+	}
+
+	// Check content for the batch as a whole; one blocked notification rejects the batch
+	if err := s.checkContent(fresh...); err != nil {
+		s.releaseDedup(fresh...)
+		return nil, err
+	}
+
+	// Spam score the batch's distinct email messages; a blocked campaign rejects the batch
+	if err := s.checkSpam(ctx, fresh...); err != nil {
+		s.releaseDedup(fresh...)
+		return nil, err
+	}
+
+	// Enforce daily quotas and per-origin daily budgets for the batch as a whole. A batch rejected
+	// or failing after it's counted is refunded to the quotas.
+	if err := s.checkQuotas(ctx, fresh...); err != nil {
+		s.releaseDedup(fresh...)
+		return nil, err
+	}
+	if err := s.checkBudgets(ctx, fresh...); err != nil {
+		s.releaseDedup(fresh...)
+		s.refundQuotas(ctx, fresh...)
+		return nil, err
 	}
 
-	// Enqueue batch
-	if err := s.queue.EnqueueBatch(ctx, notifications); err != nil {
-		return nil, fmt.Errorf("failed to enqueue batch: %w", err)
+	// Link HTML email to its hosted web copies, give email its reply addresses and add acknowledge buttons
+	s.addWebCopyLinks(fresh...)
+	s.addReplyAddresses(fresh...)
+	s.addAckActions(fresh...)
+
+	// Store all notifications, setting aside those held for approval
+	queued := make([]*domain.Notification, 0, len(fresh))
+	var held []*domain.Notification
+	for _, notification := range fresh {
+		if err := s.storeNotification(notification); err != nil {
+			s.releaseDedup(fresh...)
+			s.refundQuotas(ctx, fresh...)
+			return nil, err
+		}
+		if notification.Status == domain.StatusHeld {
+			held = append(held, notification)
+		} else {
+			queued = append(queued, notification)
+		}
 	}
 
-	// Create results
+	// Low-priority notifications wait for the next digest instead, once the rest are queued
+	var digested []*domain.Notification
+	queued = slices.DeleteFunc(queued, func(notification *domain.Notification) bool {
+		if s.digestible(notification) {
+			digested = append(digested, notification)
+			return true
+		}
+		return false
+	})
+
+	// Mark the rest queued, scheduled or added to the next digest, and describe the outcomes,
+	// before handing them over: a worker or the digest loop may update them as soon as they're
+	// handed over
+	for _, notification := range queued {
+		s.markQueued(notification)
+	}
+	for _, notification := range digested {
+		notification.Status = domain.StatusDigested
+	}
 	for _, notification := range notifications {
+		if result, ok := suppressed[notification]; ok {
+			results = append(results, result)
+			continue
+		}
+		message := queuedMessage(notification)
+		if notification.Status == domain.StatusHeld {
+			message = "notification held for approval"
+		}
 		results = append(results, &domain.NotificationResult{
 			NotificationID: notification.ID,
 			Success:        true,
-			Message:        "notification queued successfully",
+			Message:        message,
 			SentAt:         time.Now(),
 		})
 	}
 
+	// Enqueue batch, holding scheduled notifications until they're due
+	if len(queued) > 0 {
+		if err := s.enqueueBatch(ctx, queued); err != nil {
+			s.releaseDedup(fresh...)
+			s.refundQuotas(ctx, fresh...)
+			return nil, fmt.Errorf("failed to enqueue batch: %w", err)
+		}
+	}
+	for _, notification := range digested {
+		s.addToDigest(ctx, notification)
+	}
+	for _, notification := range held {
+		s.publishStatus(notification)
+		s.announceHeld(ctx, notification)
+	}
+
 	return results, nil
 }
 
-// GetNotification retrieves a notification by ID
+// GetNotification retrieves a notification by ID, from the store's read replica if it has one
 func (s *NotificationService) GetNotification(ctx context.Context, id string) (*domain.Notification, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	notification, exists := s.notifications[id]
-	if !exists {
-		return nil, fmt.Errorf("notification not found: %s", id)
-	}
-
-	return notification, nil
+	return s.visibleNotification(ctx, s.reader(), id)
 }
 
-// ListNotifications retrieves notifications matching the filter
-func (s *NotificationService) ListNotifications(ctx context.Context, filter *domain.NotificationFilter) ([]*domain.Notification, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	// Simple in-memory filtering
-	var results []*domain.Notification
-
-	for _, notification := range s.notifications {
-		if s.matchesFilter(notification, filter) {
-			results = append(results, notification)
+// ListNotifications retrieves a page of notifications matching the filter, newest first
+func (s *NotificationService) ListNotifications(ctx context.Context, filter *domain.NotificationFilter) (*domain.NotificationPage, error) {
+	// A tenant's clients only list its own notifications
+	if tenant := auth.TenantFromContext(ctx); tenant != "" {
+		scoped := domain.NotificationFilter{}
+		if filter != nil {
+			scoped = *filter
 		}
+		scoped.Tenant = tenant
+		filter = &scoped
 	}
+	return s.reader().ListPage(ctx, filter)
+}
 
-	// Apply limit and offset
-	if filter.Offset > 0 && filter.Offset < len(results) {
-		results = results[filter.Offset:]
+// reader returns the store's reads for API lookups, listings and stats, which may come from a
+// read replica. Anything about to change a notification reads s.store instead.
+func (s *NotificationService) reader() domain.NotificationReader {
+	if replica, ok := s.store.(domain.ReplicaStore); ok {
+		return replica.Reader()
 	}
+	return s.store
+}
 
-	if filter.Limit > 0 && filter.Limit < len(results) {
-		results = results[:filter.Limit]
+// ReadStaleness reports how stale API reads may be, or nil when the store has no read
+// replica or its lag can't be determined
+func (s *NotificationService) ReadStaleness(ctx context.Context) *domain.ReadStaleness {
+	replica, ok := s.store.(domain.ReplicaStore)
+	if !ok {
+		return nil
 	}
-
-	return results, nil
+	staleness, err := replica.ReadStaleness(ctx)
+	if err != nil {
+		s.logger.Warnf("Failed to determine read staleness - error=%v", err)
+		return nil
+	}
+	return staleness
 }
 
 // CancelNotification cancels a pending notification
 func (s *NotificationService) CancelNotification(ctx context.Context, id string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 
-	notification, exists := s.notifications[id]
-	if !exists {
-		return fmt.Errorf("notification not found: %s", id)
+	notification, err := s.getNotification(ctx, id)
+	if err != nil {
+		s.mu.Unlock()
+		return err
 	}
 
 	if notification.Status == domain.StatusSent {
+		s.mu.Unlock()
 		return fmt.Errorf("notification already sent")
 	}
 
 	notification.Status = domain.StatusFailed
 	notification.LastError = "cancelled by user"
+	err = s.store.Update(ctx, notification)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
 
+	s.publishStatus(notification)
 	return nil
 }
 
 // RetryNotification retries a failed notification
 func (s *NotificationService) RetryNotification(ctx context.Context, id string) (*domain.NotificationResult, error) {
-	notification, err := s.GetNotification(ctx, id)
+	notification, err := s.getNotification(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -417,6 +1026,9 @@ func (s *NotificationService) RetryNotification(ctx context.Context, id string)
 			SentAt:         time.Now(),
 		}, fmt.Errorf("notification already sent")
 	}
+	if notification.Status == domain.StatusHeld {
+		return nil, fmt.Errorf("%w: approve or reject %s instead of retrying it", domain.ErrNotHeld, id)
+	}
 
 	// Reset retry count and status
 	notification.RetryCount = 0
@@ -426,17 +1038,48 @@ func (s *NotificationService) RetryNotification(ctx context.Context, id string)
 	return s.Send(ctx, notification)
 }
 
+// QueueMetrics reports the queue's backlog and throughput. Queues that don't report metrics
+// only have their depth filled in.
+func (s *NotificationService) QueueMetrics() domain.QueueMetrics {
+	if reporter, ok := s.queue.(domain.QueueMetricsReporter); ok {
+		return reporter.QueueMetrics()
+	}
+
+	depth, _ := s.queue.Size(context.Background())
+	return domain.QueueMetrics{Depth: depth}
+}
+
 // GetStats returns notification statistics
 func (s *NotificationService) GetStats(ctx context.Context) (*domain.NotificationStats, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	stats := &domain.NotificationStats{
 		ByType:   make(map[string]int64),
 		ByStatus: make(map[string]int64),
+		ByOrigin: make(map[string]*domain.OriginStats),
+	}
+
+	// A tenant's clients only see counts of its own notifications, without the instance-wide
+	// delivery, SLO, budget and canary figures
+	tenant := auth.TenantFromContext(ctx)
+	if tenant == "" {
+		if s.canary != nil {
+			stats.Canaries = s.canaryStatuses()
+		}
+		if s.dedup != nil {
+			stats.TotalSuppressed = s.dedup.total()
+		}
+	}
+
+	var filter *domain.NotificationFilter
+	if tenant != "" {
+		filter = &domain.NotificationFilter{Tenant: tenant}
+	}
+	notifications, err := s.reader().List(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
+	stats.Staleness = s.ReadStaleness(ctx)
 
-	for _, notification := range s.notifications {
+	for _, notification := range notifications {
 		switch notification.Status {
 		case domain.StatusSent:
 			stats.TotalSent++
@@ -450,6 +1093,31 @@ func (s *NotificationService) GetStats(ctx context.Context) (*domain.Notificatio
 
 		stats.ByType[string(notification.Type)]++
 		stats.ByStatus[string(notification.Status)]++
+
+		origin, ok := stats.ByOrigin[notification.Origin.StatsKey()]
+		if !ok {
+			origin = &domain.OriginStats{ByType: make(map[string]int64)}
+			stats.ByOrigin[notification.Origin.StatsKey()] = origin
+		}
+		origin.Total++
+		origin.ByType[string(notification.Type)]++
+		if notification.Origin.UserTriggered() {
+			origin.UserTriggered++
+		}
+	}
+
+	if tenant != "" {
+		return stats, nil
+	}
+
+	stats.Delivery, stats.AverageLatency = s.deliveries.stats()
+
+	if s.slo != nil {
+		stats.SLOs = s.slo.statuses(time.Now())
+	}
+
+	if s.budgets != nil {
+		stats.Budgets = s.budgets.statuses(time.Now())
 	}
 
 	return stats, nil
@@ -459,6 +1127,7 @@ func (s *NotificationService) GetStats(ctx context.Context) (*domain.Notificatio
 func (s *NotificationService) GetNotifiers(ctx context.Context) (*domain.NotifiersResponse, error) {
 	// Extract auth context from request context if available
 	authCtx, _ := auth.GetAuthContext(ctx)
+	tenant := auth.TenantFromContext(ctx)
 
 	supportedTypes := s.factory.SupportedTypes()
 	notifiers := make([]domain.NotifierInfo, 0, len(supportedTypes))
@@ -477,6 +1146,13 @@ func (s *NotificationService) GetNotifiers(ctx context.Context) (*domain.Notifie
 			accounts = authorizedAccounts
 		}
 
+		// Hide accounts reserved for other tenants
+		if tenant != "" {
+			accounts = slices.DeleteFunc(slices.Clone(accounts), func(account string) bool {
+				return s.reservedElsewhere(tenant, notifType, account) != nil
+			})
+		}
+
 		// Skip notifier type if no authorized accounts
 		if len(accounts) == 0 && authCtx != nil {
 			continue
@@ -487,6 +1163,16 @@ func (s *NotificationService) GetNotifiers(ctx context.Context) (*domain.Notifie
 			defaultAccount = s.accountResolver.GetDefaultAccount(notifType)
 		}
 
+		// A tenant's default account replaces the type's, which may be reserved for another tenant
+		if account := s.tenantAccount(tenant, notifType); account != "" {
+			defaultAccount = account
+		} else if s.reservedElsewhere(tenant, notifType, defaultAccount) != nil {
+			defaultAccount = ""
+			if len(accounts) > 0 {
+				defaultAccount = accounts[0]
+			}
+		}
+
 		// If default account was filtered out, clear it
 		if authCtx != nil && s.authz != nil && defaultAccount != "" {
 			if !s.authz.IsAuthorized(authCtx, notifType, defaultAccount) {
@@ -498,10 +1184,18 @@ func (s *NotificationService) GetNotifiers(ctx context.Context) (*domain.Notifie
 			}
 		}
 
+		var paused []string
+		for _, account := range accounts {
+			if _, ok := s.pauses.match(notifType, account); ok {
+				paused = append(paused, account)
+			}
+		}
+
 		notifiers = append(notifiers, domain.NotifierInfo{
 			Type:           notifType,
 			Accounts:       accounts,
 			DefaultAccount: defaultAccount,
+			PausedAccounts: paused,
 		})
 	}
 
@@ -510,25 +1204,47 @@ func (s *NotificationService) GetNotifiers(ctx context.Context) (*domain.Notifie
 	}, nil
 }
 
-// storeNotification stores a notification in memory
-func (s *NotificationService) storeNotification(notification *domain.Notification) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.notifications[notification.ID] = notification
+// storeNotification saves a newly accepted notification
+func (s *NotificationService) storeNotification(notification *domain.Notification) error {
+	if err := s.store.Save(context.Background(), notification); err != nil {
+		return fmt.Errorf("failed to store notification: %w", err)
+	}
+	return nil
 }
 
-// updateNotification updates a notification in memory
+// updateNotification stores a notification's new status and announces it. A notification
+// the store doesn't know, such as one reloaded by a persistent queue after a restart,
+// is saved again.
 func (s *NotificationService) updateNotification(notification *domain.Notification) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.notifications[notification.ID] = notification
+	ctx := context.Background()
+	err := s.store.Update(ctx, notification)
+	if errors.Is(err, domain.ErrNotificationNotFound) {
+		err = s.store.Save(ctx, notification)
+	}
+	if err != nil {
+		s.logger.Errorf("Failed to store notification status - id=%s, status=%s, error=%v", notification.ID, notification.Status, err)
+	}
+
+	s.publishStatus(notification)
+}
+
+// stampOrigin defaults the origin system to the authenticated client so every notification
+// can be attributed, even when the caller doesn't identify itself
+func (s *NotificationService) stampOrigin(ctx context.Context, notification *domain.Notification) {
+	if notification.Origin.System != "" {
+		return
+	}
+	if authCtx, ok := auth.GetAuthContext(ctx); ok {
+		notification.Origin.System = authCtx.ClientID
+	}
 }
 
 // checkAuthorization verifies that the caller is authorized to send to the given notifier/account.
 // Returns nil if authorized or if RBAC is not configured.
 func (s *NotificationService) checkAuthorization(ctx context.Context, notification *domain.Notification) error {
-	if s.authz == nil || !s.authz.HasRules() {
-		return nil // RBAC not configured
+	// A tenant's notifications can't go through accounts reserved for other tenants
+	if err := s.checkTenantAccounts(notification); err != nil {
+		return err
 	}
 
 	authCtx, ok := auth.GetAuthContext(ctx)
@@ -536,93 +1252,32 @@ func (s *NotificationService) checkAuthorization(ctx context.Context, notificati
 		return nil // No auth context (auth may be disabled)
 	}
 
-	account := notification.Account
-	if account == "" && s.accountResolver != nil {
-		account = s.accountResolver.GetDefaultAccount(notification.Type)
-	}
-
-	if !s.authz.IsAuthorized(authCtx, notification.Type, account) {
-		return fmt.Errorf("not authorized to send %s notifications to account %s", notification.Type, account)
+	// A client scoped by its token's claims may only send the types it names
+	if !authCtx.AllowsType(notification.Type) {
+		return fmt.Errorf("not authorized to send %s notifications", notification.Type)
 	}
-
-	return nil
-}
-
-// matchesFilter checks if a notification matches the filter
-func (s *NotificationService) matchesFilter(notification *domain.Notification, filter *domain.NotificationFilter) bool {
-	if filter == nil {
-		return true
-	}
-
-	// Check IDs
-	if len(filter.IDs) > 0 {
-		found := false
-		for _, id := range filter.IDs {
-			if notification.ID == id {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
+	for _, fallback := range notification.Fallbacks {
+		if !authCtx.AllowsType(fallback.Type) {
+			return fmt.Errorf("not authorized to fall back to %s notifications", fallback.Type)
 		}
 	}
 
-	// Check types
-	if len(filter.Types) > 0 {
-		found := false
-		for _, t := range filter.Types {
-			if notification.Type == t {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
+	if s.authz == nil || !s.authz.HasRules() {
+		return nil // RBAC not configured
 	}
 
-	// Check statuses
-	if len(filter.Statuses) > 0 {
-		found := false
-		for _, s := range filter.Statuses {
-			if notification.Status == s {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
-		}
+	account := s.resolveAccount(notification)
+	if !s.authz.IsAuthorized(authCtx, notification.Type, account) {
+		return fmt.Errorf("not authorized to send %s notifications to account %s", notification.Type, account)
 	}
 
-	// Check recipients
-	if len(filter.Recipients) > 0 {
-		found := false
-		for _, fr := range filter.Recipients {
-			for _, nr := range notification.Recipients {
-				if fr == nr {
-					found = true
-					break
-				}
-			}
-			if found {
-				break
-			}
-		}
-		if !found {
-			return false
+	// The caller must also be allowed to send through each channel it falls back to
+	for _, fallback := range notification.Fallbacks {
+		account := s.resolveAccount(&domain.Notification{Type: fallback.Type, Account: fallback.Account})
+		if !s.authz.IsAuthorized(authCtx, fallback.Type, account) {
+			return fmt.Errorf("not authorized to fall back to %s notifications to account %s", fallback.Type, account)
 		}
 	}
 
-	// Check time ranges
-	if filter.CreatedAfter != nil && notification.CreatedAt.Before(*filter.CreatedAfter) {
-		return false
-	}
-
-	if filter.CreatedBefore != nil && notification.CreatedAt.After(*filter.CreatedBefore) {
-		return false
-	}
-
-	return true
+	return nil
 }
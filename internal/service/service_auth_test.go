@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestAllowedTypes tests that a client scoped to some types by its token can't send, or fall
+// back to, any other
+func TestAllowedTypes(t *testing.T) {
+	svc := createTestService(t)
+	ctx := auth.ContextWithAuth(context.Background(), &auth.AuthContext{
+		ClientID:     "billing-service",
+		AllowedTypes: []domain.NotificationType{domain.TypeStdout},
+	})
+
+	if _, err := svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Body: "ok", Recipients: []string{"ops"}}); err != nil {
+		t.Errorf("Expected an allowed type to be sent, got %v", err)
+	}
+	if _, err := svc.Send(ctx, &domain.Notification{Type: domain.TypeSlack, Body: "no", Recipients: []string{"#ops"}}); err == nil {
+		t.Error("Expected a type outside the client's scope to be rejected")
+	}
+	fallback := &domain.Notification{
+		Type:       domain.TypeStdout,
+		Body:       "no",
+		Recipients: []string{"ops"},
+		Fallbacks:  []domain.Fallback{{Type: domain.TypeSlack, Recipients: []string{"#ops"}}},
+	}
+	if _, err := svc.Send(ctx, fallback); err == nil {
+		t.Error("Expected a fallback outside the client's scope to be rejected")
+	}
+}
@@ -478,6 +478,57 @@ func TestCleanupWithMixedNotificationStatuses(t *testing.T) {
 	}
 }
 
+// TestCleanupKeepsUndeliveredNotifications tests that only sent and failed notifications are
+// pruned, and that each run is counted in the retention metrics
+func TestCleanupKeepsUndeliveredNotifications(t *testing.T) {
+	svc := createTestService(t)
+	if err := svc.WithRetentionConfig(config.NotificationRetentionConfig{
+		Enabled:        true,
+		TTL:            "1h",
+		CheckFrequency: "1h",
+		MaxSize:        3,
+	}); err != nil {
+		t.Fatalf("Failed to set retention config: %v", err)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	statuses := []domain.NotificationStatus{domain.StatusSent, domain.StatusFailed, domain.StatusQueued, domain.StatusRetrying}
+	for i, status := range statuses {
+		svc.storeNotification(&domain.Notification{ID: "old-" + string(status), Type: domain.TypeStdout, Status: status, CreatedAt: old.Add(time.Duration(i) * time.Second)})
+	}
+	for i, status := range []domain.NotificationStatus{domain.StatusSent, domain.StatusSent, domain.StatusPending} {
+		svc.storeNotification(&domain.Notification{ID: uuid.New().String(), Type: domain.TypeStdout, Status: status, CreatedAt: time.Now().Add(time.Duration(i) * time.Second)})
+	}
+
+	svc.performCleanup()
+
+	remaining, err := svc.store.List(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	counts := make(map[domain.NotificationStatus]int)
+	for _, notification := range remaining {
+		counts[notification.Status]++
+	}
+	if counts[domain.StatusQueued] != 1 || counts[domain.StatusRetrying] != 1 || counts[domain.StatusPending] != 1 {
+		t.Errorf("Expected undelivered notifications to be kept, got %v", counts)
+	}
+	if counts[domain.StatusSent] != 0 || counts[domain.StatusFailed] != 0 {
+		t.Errorf("Expected expired and excess completed notifications to be pruned, got %v", counts)
+	}
+
+	metrics := svc.RetentionMetrics()
+	if metrics.Runs != 1 || metrics.Failures != 0 {
+		t.Errorf("Expected one successful run, got %+v", metrics)
+	}
+	if metrics.PrunedExpired != 2 || metrics.PrunedExcess != 2 || metrics.Stored != 3 {
+		t.Errorf("Expected 2 expired and 2 excess pruned with 3 stored, got %+v", metrics)
+	}
+	if metrics.LastRunAt.IsZero() {
+		t.Error("Expected the last run time to be recorded")
+	}
+}
+
 // TestCleanupPerformance tests cleanup performance with large notification sets
 func TestCleanupPerformance(t *testing.T) {
 	svc := createTestService(t)
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// shutdownAlertTimeout bounds how long Stop waits to deliver the shutdown report
+const shutdownAlertTimeout = 10 * time.Second
+
+// shutdownReport summarizes what the queue held when the service stopped
+type shutdownReport struct {
+	Drained   uint64         // Messages workers finished while stopping
+	Pending   int            // Messages left in the queue, waiting or in flight
+	Persisted int            // Pending messages saved to disk for the next start
+	Abandoned int64          // Messages dequeued but not finished, e.g. parked or awaiting a retry
+	Lost      int            // Pending messages dropped because the queue isn't durable, abandoned ones included
+	ByAccount map[string]int // Pending messages by "<type>/<account>"
+}
+
+// stranded reports whether the shutdown left work that won't complete after a restart.
+// Persisted messages, abandoned ones included, are delivered once the service starts again.
+func (r *shutdownReport) stranded() bool {
+	return r.Lost > 0
+}
+
+// accounts returns the accounts with pending messages in sorted order
+func (r *shutdownReport) accounts() []string {
+	accounts := make([]string, 0, len(r.ByAccount))
+	for account := range r.ByAccount {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+// WithShutdownReportConfig sets where the shutdown report is sent besides the log
+func (s *NotificationService) WithShutdownReportConfig(cfg config.ShutdownReportConfig) {
+	s.shutdownReportConfig = cfg
+}
+
+// completedMessages counts queue messages that are finished: acked, or nacked without a requeue
+func completedMessages(metrics domain.QueueMetrics) uint64 {
+	return metrics.Acked + metrics.Nacked - metrics.Requeued
+}
+
+// buildShutdownReport takes a snapshot of the queue once the workers have stopped.
+// before is the queue's metrics from when the service began stopping.
+func (s *NotificationService) buildShutdownReport(before domain.QueueMetrics) *shutdownReport {
+	after := s.QueueMetrics()
+	report := &shutdownReport{
+		Drained:   completedMessages(after) - completedMessages(before),
+		Abandoned: after.InFlight,
+		ByAccount: make(map[string]int),
+	}
+
+	inspector, ok := s.queue.(domain.QueueInspector)
+	if !ok {
+		// Without a listing only the totals are known, and nothing is known to be durable
+		report.Pending = int(after.Depth + after.InFlight + after.Delayed)
+		report.Lost = report.Pending
+		return report
+	}
+
+	for _, msg := range inspector.PendingMessages() {
+		if msg.Notification == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s", msg.Notification.Type, s.resolveAccount(msg.Notification))
+		report.ByAccount[key]++
+		report.Pending++
+	}
+
+	if inspector.Durable() {
+		report.Persisted = report.Pending
+	} else {
+		report.Lost = report.Pending
+	}
+
+	return report
+}
+
+// reportShutdown logs the shutdown report and sends it to the admin channel, if configured.
+// The workers have stopped, so the alert is sent directly rather than through the queue.
+func (s *NotificationService) reportShutdown(report *shutdownReport) {
+	s.logger.Infof("Shutdown report - drained=%d, pending=%d, persisted=%d, abandoned=%d, lost=%d",
+		report.Drained, report.Pending, report.Persisted, report.Abandoned, report.Lost)
+	for _, account := range report.accounts() {
+		s.logger.Infof("Shutdown report - account=%s, pending=%d", account, report.ByAccount[account])
+	}
+	if report.stranded() {
+		s.logger.Warnf("Shutdown lost queued notifications - lost=%d (the queue isn't persisted to disk)", report.Lost)
+	}
+
+	target := s.shutdownReportConfig.Alert
+	if !target.Enabled() || (s.shutdownReportConfig.OnlyIfStranded && !report.stranded()) {
+		return
+	}
+
+	notification := newOperationalAlert(target, "Notifier shutdown report", report.body(), map[string]interface{}{
+		"pending":   report.Pending,
+		"abandoned": report.Abandoned,
+		"lost":      report.Lost,
+	})
+	account := s.resolveAccount(notification)
+
+	notifier, err := s.factory.Create(notification.Type, account)
+	if err != nil {
+		s.logger.Errorf("Failed to send shutdown report - type=%s, account=%s, error=%v", notification.Type, account, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownAlertTimeout)
+	defer cancel()
+	if _, err := notifier.Send(ctx, notification); err != nil {
+		s.logger.Errorf("Failed to send shutdown report - type=%s, account=%s, error=%v", notification.Type, account, err)
+	}
+}
+
+// body renders the report for the admin notification
+func (r *shutdownReport) body() string {
+	var b strings.Builder
+	if r.stranded() {
+		b.WriteString("The notifier stopped and lost queued notifications.\n\n")
+	} else {
+		b.WriteString("The notifier stopped without losing queued notifications.\n\n")
+	}
+	fmt.Fprintf(&b, "Drained while stopping: %d\n", r.Drained)
+	fmt.Fprintf(&b, "Pending: %d\n", r.Pending)
+	fmt.Fprintf(&b, "Persisted for restart: %d\n", r.Persisted)
+	fmt.Fprintf(&b, "In flight, abandoned: %d\n", r.Abandoned)
+	fmt.Fprintf(&b, "Lost: %d\n", r.Lost)
+
+	if accounts := r.accounts(); len(accounts) > 0 {
+		b.WriteString("\nPending by account:\n")
+		for _, account := range accounts {
+			fmt.Fprintf(&b, "  %s: %d\n", account, r.ByAccount[account])
+		}
+	}
+
+	return b.String()
+}
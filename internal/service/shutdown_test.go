@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/logging"
+	"github.com/igodwin/notifier/internal/notifier"
+	"github.com/igodwin/notifier/internal/queue"
+)
+
+// recordingNotifier keeps the notifications it's asked to send
+type recordingNotifier struct {
+	notifier.BaseNotifier
+	sent []*domain.Notification
+}
+
+func (r *recordingNotifier) Send(ctx context.Context, notification *domain.Notification) (*domain.NotificationResult, error) {
+	r.sent = append(r.sent, notification)
+	return &domain.NotificationResult{NotificationID: notification.ID, Success: true}, nil
+}
+
+// TestShutdownReport tests the report of pending work and when it's sent to the admin channel
+func TestShutdownReport(t *testing.T) {
+	tests := []struct {
+		name           string
+		persist        bool
+		onlyIfStranded bool
+		wantPersisted  int
+		wantLost       int
+		wantAlert      bool
+	}{
+		{name: "memory queue loses pending work", wantLost: 3, wantAlert: true},
+		{name: "persisted queue keeps pending work", persist: true, wantPersisted: 3, wantAlert: true},
+		{name: "only if stranded skips a safe shutdown", persist: true, onlyIfStranded: true, wantPersisted: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &recordingNotifier{}
+			factory := notifier.NewFactory()
+			factory.RegisterNotifier(domain.TypeSlack, "ops", admin)
+
+			queueConfig := &domain.LocalQueueConfig{BufferSize: 10}
+			if tt.persist {
+				queueConfig.PersistToDisk = true
+				queueConfig.PersistPath = filepath.Join(t.TempDir(), "queue.json")
+			}
+			q, err := queue.NewLocalQueue(queueConfig)
+			if err != nil {
+				t.Fatalf("Failed to create queue: %v", err)
+			}
+
+			logger, _ := logging.NewFromConfig("error", "stdout")
+			svc := NewNotificationService(factory, q, 1, nil, nil, logger)
+			svc.WithShutdownReportConfig(config.ShutdownReportConfig{
+				Alert:          config.AlertTargetConfig{Type: "slack", Account: "ops", Recipients: []string{"#ops"}},
+				OnlyIfStranded: tt.onlyIfStranded,
+			})
+
+			// The service isn't started, so everything queued is still pending at shutdown
+			for _, n := range []*domain.Notification{
+				{ID: "n1", Type: domain.TypeEmail, Account: "billing"},
+				{ID: "n2", Type: domain.TypeEmail, Account: "billing"},
+				{ID: "n3", Type: domain.TypeNtfy, Account: "alerts"},
+			} {
+				if err := q.Enqueue(context.Background(), n); err != nil {
+					t.Fatalf("Enqueue() error = %v", err)
+				}
+			}
+
+			report := svc.buildShutdownReport(svc.QueueMetrics())
+			if report.Pending != 3 || report.Persisted != tt.wantPersisted || report.Lost != tt.wantLost {
+				t.Errorf("Unexpected report: %+v", report)
+			}
+			if report.ByAccount["email/billing"] != 2 || report.ByAccount["ntfy/alerts"] != 1 {
+				t.Errorf("Unexpected pending counts: %v", report.ByAccount)
+			}
+
+			if err := svc.Stop(); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+
+			if !tt.wantAlert {
+				if len(admin.sent) != 0 {
+					t.Errorf("Expected no shutdown alert, got %d", len(admin.sent))
+				}
+				return
+			}
+			if len(admin.sent) != 1 {
+				t.Fatalf("Expected one shutdown alert, got %d", len(admin.sent))
+			}
+			body := admin.sent[0].Body
+			if !strings.Contains(body, "email/billing: 2") || !strings.Contains(body, "Pending: 3") {
+				t.Errorf("Unexpected alert body:\n%s", body)
+			}
+			if !isOperationalAlert(admin.sent[0]) {
+				t.Error("Expected the shutdown alert to be marked operational")
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// sloTracker records delivery outcomes and computes SLO compliance over a rolling window
+type sloTracker struct {
+	mu         sync.Mutex
+	objectives []sloObjective
+	window     time.Duration
+	minSamples int
+	samples    []deliverySample
+	breached   map[string]bool
+}
+
+// sloObjective is a parsed SLO objective
+type sloObjective struct {
+	name      string
+	priority  *domain.Priority
+	notifType domain.NotificationType
+	target    float64
+	threshold time.Duration
+}
+
+// deliverySample is the outcome of a single notification
+type deliverySample struct {
+	completedAt time.Time
+	notifType   domain.NotificationType
+	priority    domain.Priority
+	latency     time.Duration
+	delivered   bool
+}
+
+// newSLOTracker creates an SLO tracker from the SLO configuration
+func newSLOTracker(cfg config.SLOConfig) (*sloTracker, error) {
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window duration: %w", err)
+	}
+
+	objectives := make([]sloObjective, 0, len(cfg.Objectives))
+	for _, objectiveCfg := range cfg.Objectives {
+		threshold, err := time.ParseDuration(objectiveCfg.Threshold)
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold for slo %s: %w", objectiveCfg.Name, err)
+		}
+
+		objective := sloObjective{
+			name:      objectiveCfg.Name,
+			notifType: domain.NotificationType(objectiveCfg.Type),
+			target:    objectiveCfg.Target,
+			threshold: threshold,
+		}
+		if objectiveCfg.Priority != "" {
+			priority, err := domain.ParsePriority(objectiveCfg.Priority)
+			if err != nil {
+				return nil, fmt.Errorf("invalid priority for slo %s: %w", objectiveCfg.Name, err)
+			}
+			objective.priority = &priority
+		}
+		objectives = append(objectives, objective)
+	}
+
+	return &sloTracker{
+		objectives: objectives,
+		window:     window,
+		minSamples: cfg.MinSamples,
+		breached:   make(map[string]bool),
+	}, nil
+}
+
+// record adds the final outcome of a notification
+func (t *sloTracker) record(msg *domain.QueueMessage, delivered bool, completedAt time.Time) {
+	notification := msg.Notification
+
+	// Measure from when the notification was due
+	start := dueSince(msg)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.samples = append(t.samples, deliverySample{
+		completedAt: completedAt,
+		notifType:   notification.Type,
+		priority:    notification.Priority,
+		latency:     completedAt.Sub(start),
+		delivered:   delivered,
+	})
+}
+
+// statuses computes the compliance of every objective over the window ending at now
+func (t *sloTracker) statuses(now time.Time) []domain.SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Samples are appended in completion order, so expired ones are at the front
+	cutoff := now.Add(-t.window)
+	expired := 0
+	for expired < len(t.samples) && t.samples[expired].completedAt.Before(cutoff) {
+		expired++
+	}
+	t.samples = t.samples[expired:]
+
+	statuses := make([]domain.SLOStatus, 0, len(t.objectives))
+	for _, objective := range t.objectives {
+		status := domain.SLOStatus{
+			Name:        objective.name,
+			Target:      objective.target,
+			ThresholdMs: objective.threshold.Milliseconds(),
+			Compliance:  1,
+		}
+
+		for _, sample := range t.samples {
+			if !objective.matches(sample) {
+				continue
+			}
+			status.Total++
+			if sample.delivered && sample.latency <= objective.threshold {
+				status.WithinThreshold++
+			}
+		}
+
+		if status.Total > 0 {
+			status.Compliance = float64(status.WithinThreshold) / float64(status.Total)
+		}
+		status.Breached = status.Total >= int64(t.minSamples) && status.Compliance < objective.target
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// transitions returns the objectives that newly breached and newly recovered since the last call
+func (t *sloTracker) transitions(statuses []domain.SLOStatus) (breached, recovered []domain.SLOStatus) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, status := range statuses {
+		if status.Breached && !t.breached[status.Name] {
+			breached = append(breached, status)
+		} else if !status.Breached && t.breached[status.Name] {
+			recovered = append(recovered, status)
+		}
+		t.breached[status.Name] = status.Breached
+	}
+
+	return breached, recovered
+}
+
+// matches reports whether a sample counts towards the objective
+func (o sloObjective) matches(sample deliverySample) bool {
+	if o.priority != nil && sample.priority != *o.priority {
+		return false
+	}
+	if o.notifType != "" && sample.notifType != o.notifType {
+		return false
+	}
+	return true
+}
+
+// WithSLOConfig enables delivery SLO tracking and breach alerts
+func (s *NotificationService) WithSLOConfig(cfg config.SLOConfig) error {
+	if !cfg.Enabled {
+		s.slo = nil
+		return nil
+	}
+
+	checkFreq, err := time.ParseDuration(cfg.CheckFrequency)
+	if err != nil {
+		return fmt.Errorf("invalid check frequency duration: %w", err)
+	}
+
+	tracker, err := newSLOTracker(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.slo = tracker
+	s.sloConfig = cfg
+	s.sloCheckFrequency = checkFreq
+
+	return nil
+}
+
+// SLOMetrics returns the current compliance of every SLO, or nil when tracking is disabled
+func (s *NotificationService) SLOMetrics() []domain.SLOStatus {
+	if s.slo == nil {
+		return nil
+	}
+	return s.slo.statuses(time.Now())
+}
+
+// sloLoop periodically evaluates SLOs and alerts on breaches
+func (s *NotificationService) sloLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.sloCheckFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkSLOs(ctx)
+		}
+	}
+}
+
+// checkSLOs evaluates SLO compliance and sends a self-notification for each new breach
+func (s *NotificationService) checkSLOs(ctx context.Context) {
+	breached, recovered := s.slo.transitions(s.slo.statuses(time.Now()))
+
+	for _, status := range breached {
+		s.logger.Warnf("SLO breached - name=%s, compliance=%.4f, target=%.4f, total=%d",
+			status.Name, status.Compliance, status.Target, status.Total)
+
+		subject := fmt.Sprintf("SLO breached: %s", status.Name)
+		body := fmt.Sprintf("%.2f%% of notifications were delivered within %dms over the last %s (target %.2f%%, %d of %d).",
+			status.Compliance*100, status.ThresholdMs, s.sloConfig.Window, status.Target*100, status.WithinThreshold, status.Total)
+		if err := s.sendOperationalAlert(ctx, s.sloConfig.Alert, subject, body, map[string]interface{}{"slo": status.Name}); err != nil {
+			s.logger.Errorf("Failed to send SLO breach alert - name=%s, error=%v", status.Name, err)
+		}
+	}
+
+	for _, status := range recovered {
+		s.logger.Infof("SLO recovered - name=%s, compliance=%.4f, target=%.4f",
+			status.Name, status.Compliance, status.Target)
+	}
+}
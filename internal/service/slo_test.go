@@ -0,0 +1,72 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSLOCompliance tests SLO compliance, filtering and breach detection
+func TestSLOCompliance(t *testing.T) {
+	tracker, err := newSLOTracker(config.SLOConfig{
+		Window:     "1h",
+		MinSamples: 4,
+		Objectives: []config.SLOObjectiveConfig{
+			{Name: "critical", Priority: "critical", Target: 0.75, Threshold: "60s"},
+			{Name: "slack", Type: "slack", Target: 0.5, Threshold: "10s"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newSLOTracker() error = %v", err)
+	}
+
+	now := time.Now()
+	record := func(notifType domain.NotificationType, priority domain.Priority, latency time.Duration, delivered bool) {
+		msg := &domain.QueueMessage{Notification: &domain.Notification{
+			Type:      notifType,
+			Priority:  priority,
+			CreatedAt: now.Add(-latency),
+		}}
+		tracker.record(msg, delivered, now)
+	}
+
+	record(domain.TypeEmail, domain.PriorityCritical, 5*time.Second, true)
+	record(domain.TypeEmail, domain.PriorityCritical, 90*time.Second, true)
+	record(domain.TypeSlack, domain.PriorityCritical, time.Second, true)
+	record(domain.TypeSlack, domain.PriorityCritical, time.Second, false)
+	record(domain.TypeSlack, domain.PriorityLow, time.Second, true)
+
+	statuses := tracker.statuses(now)
+	if len(statuses) != 2 {
+		t.Fatalf("Expected 2 statuses, got %d", len(statuses))
+	}
+
+	critical := statuses[0]
+	if critical.Total != 4 || critical.WithinThreshold != 2 || critical.Compliance != 0.5 || !critical.Breached {
+		t.Errorf("Unexpected critical status: %+v", critical)
+	}
+
+	slack := statuses[1]
+	if slack.Total != 3 || slack.WithinThreshold != 2 || slack.Breached {
+		t.Errorf("Unexpected slack status: %+v (too few samples to breach)", slack)
+	}
+
+	breached, recovered := tracker.transitions(statuses)
+	if len(breached) != 1 || breached[0].Name != "critical" || len(recovered) != 0 {
+		t.Errorf("Expected critical to newly breach, got breached=%v recovered=%v", breached, recovered)
+	}
+
+	// A breach is only reported once
+	breached, _ = tracker.transitions(tracker.statuses(now))
+	if len(breached) != 0 {
+		t.Errorf("Expected no new breaches, got %v", breached)
+	}
+
+	// Samples outside the window no longer count, so the SLO recovers
+	_, recovered = tracker.transitions(tracker.statuses(now.Add(2 * time.Hour)))
+	if len(recovered) != 1 || recovered[0].Name != "critical" {
+		t.Errorf("Expected critical to recover, got %v", recovered)
+	}
+}
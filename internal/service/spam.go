@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/spamcheck"
+)
+
+// spamViolationRule is the rule name recorded when a message scores above the threshold
+const spamViolationRule = "spam_score"
+
+// spamReportSymbols is how many of the engine's symbols are included in a violation
+const spamReportSymbols = 5
+
+// spamCheck scores outgoing email with a spam filter
+type spamCheck struct {
+	checker   spamcheck.Checker
+	threshold float64
+	action    string
+	accounts  map[string]bool
+	failOpen  bool
+	alert     config.AlertTargetConfig
+}
+
+// WithSpamCheckConfig enables spam scoring of email before it's queued
+func (s *NotificationService) WithSpamCheckConfig(cfg config.SpamCheckConfig) error {
+	if !cfg.Enabled {
+		s.spamCheck = nil
+		return nil
+	}
+
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid spam check timeout: %w", err)
+	}
+	checker, err := spamcheck.New(spamcheck.Options{
+		Engine:   cfg.Engine,
+		Address:  cfg.Address,
+		Password: cfg.Password,
+		User:     cfg.User,
+		Timeout:  timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	check := &spamCheck{
+		checker:   checker,
+		threshold: cfg.Threshold,
+		action:    cfg.Action,
+		failOpen:  cfg.FailOpen,
+		alert:     cfg.Alert,
+	}
+	if len(cfg.Accounts) > 0 {
+		check.accounts = make(map[string]bool)
+		for _, account := range cfg.Accounts {
+			check.accounts[account] = true
+		}
+	}
+	s.spamCheck = check
+
+	return nil
+}
+
+// checkSpam scores the email among notifications, recording a violation on each one that
+// scores above the threshold. Identical messages in a batch (a campaign) are scored once.
+// If the action is block, ErrContentBlocked is returned and none should be queued; with
+// hold, the notifications are marked held.
+func (s *NotificationService) checkSpam(ctx context.Context, notifications ...*domain.Notification) error {
+	if s.spamCheck == nil {
+		return nil
+	}
+
+	verdicts := make(map[string]*domain.PolicyViolation)
+	for _, notification := range notifications {
+		if notification.Type != domain.TypeEmail || isOperationalAlert(notification) {
+			continue
+		}
+		if s.spamCheck.accounts != nil && !s.spamCheck.accounts[notification.Account] {
+			continue
+		}
+
+		key := strings.Join([]string{notification.Account, notification.Subject, notification.Body, notification.HTMLBody, string(notification.ContentType)}, "\x00")
+		violation, scored := verdicts[key]
+		if !scored {
+			var err error
+			if violation, err = s.scoreSpam(ctx, notification); err != nil {
+				return err
+			}
+			verdicts[key] = violation
+		}
+		if violation == nil {
+			continue
+		}
+
+		notification.PolicyViolations = append(notification.PolicyViolations, *violation)
+		switch violation.Action {
+		case config.ContentActionBlock:
+			return fmt.Errorf("%w: %s", domain.ErrContentBlocked, violation.Match)
+		case config.ContentActionHold:
+			notification.Status = domain.StatusHeld
+		}
+	}
+
+	return nil
+}
+
+// scoreSpam scores one rendered message, returning a violation if it's above the threshold
+func (s *NotificationService) scoreSpam(ctx context.Context, notification *domain.Notification) (*domain.PolicyViolation, error) {
+	message, err := s.renderMessage(notification)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render message for spam check: %w", err)
+	}
+
+	result, err := s.spamCheck.checker.Check(ctx, message)
+	if err != nil {
+		if s.spamCheck.failOpen {
+			s.logger.Warnf("Spam check unavailable, sending unscored - id=%s, account=%s, error=%v",
+				notification.ID, notification.Account, err)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("spam check failed: %w", err)
+	}
+
+	threshold := s.spamCheck.threshold
+	if threshold == 0 {
+		threshold = result.Threshold
+	}
+	if result.Score <= threshold {
+		return nil, nil
+	}
+
+	symbols := result.Symbols
+	if len(symbols) > spamReportSymbols {
+		symbols = symbols[:spamReportSymbols]
+	}
+	s.logger.Warnf("Message scored as spam - id=%s, account=%s, origin=%s, score=%.1f, threshold=%.1f, symbols=%v, action=%s",
+		notification.ID, notification.Account, notification.Origin.StatsKey(), result.Score, threshold, result.Symbols, s.spamCheck.action)
+
+	return &domain.PolicyViolation{
+		Rule:     spamViolationRule,
+		Detector: "spam",
+		Field:    "message",
+		Match:    fmt.Sprintf("spam score %.1f exceeds %.1f (%s)", result.Score, threshold, strings.Join(symbols, ", ")),
+		Action:   s.spamCheck.action,
+	}, nil
+}
+
+// renderMessage returns the message the notification's notifier would send, falling back to
+// a plain rendering of the subject and body if the notifier can't render it
+func (s *NotificationService) renderMessage(notification *domain.Notification) ([]byte, error) {
+	if n, err := s.factory.Create(notification.Type, notification.Account); err == nil {
+		if renderer, ok := n.(domain.MessageRenderer); ok {
+			return renderer.RenderMessage(notification)
+		}
+	}
+
+	var builder strings.Builder
+	if len(notification.Recipients) > 0 {
+		builder.WriteString("To: " + strings.Join(notification.Recipients, ", ") + "\r\n")
+	}
+	builder.WriteString("Subject: " + notification.Subject + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	if notification.HTMLBody != "" {
+		builder.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+		builder.WriteString(notification.HTMLBody)
+	} else {
+		builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+		builder.WriteString(notification.Body)
+	}
+	return []byte(builder.String()), nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/spamcheck"
+)
+
+// fakeSpamChecker scores messages containing "FREE MONEY" as spam
+type fakeSpamChecker struct {
+	checks int
+	err    error
+}
+
+func (c *fakeSpamChecker) Check(ctx context.Context, message []byte) (*spamcheck.Result, error) {
+	c.checks++
+	if c.err != nil {
+		return nil, c.err
+	}
+	if strings.Contains(string(message), "FREE MONEY") {
+		return &spamcheck.Result{Score: 9.5, Threshold: 5, Symbols: []string{"BAYES_99", "MONEY"}}, nil
+	}
+	return &spamcheck.Result{Score: 1.2, Threshold: 5}, nil
+}
+
+// TestCheckSpam tests campaign scoring, thresholds, account scoping, actions and fail-open
+func TestCheckSpam(t *testing.T) {
+	svc := createTestService(t)
+	checker := &fakeSpamChecker{}
+
+	campaign := func(account, body string, n int) []*domain.Notification {
+		notifications := make([]*domain.Notification, n)
+		for i := range notifications {
+			notifications[i] = &domain.Notification{
+				ID:         uuid.New().String(),
+				Type:       domain.TypeEmail,
+				Account:    account,
+				Subject:    "October newsletter",
+				Body:       body,
+				Recipients: []string{"user@example.com"},
+				CreatedAt:  time.Now(),
+				MaxRetries: 3,
+			}
+		}
+		return notifications
+	}
+
+	// A campaign of identical messages is scored once and held
+	svc.spamCheck = &spamCheck{checker: checker, action: config.ContentActionHold, accounts: map[string]bool{"marketing": true}}
+	held := campaign("marketing", "FREE MONEY inside", 3)
+	results, err := svc.SendBatch(context.Background(), held)
+	if err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if checker.checks != 1 {
+		t.Errorf("Expected campaign to be scored once, got %d checks", checker.checks)
+	}
+	for i, notification := range held {
+		if notification.Status != domain.StatusHeld || len(notification.PolicyViolations) != 1 || results[i].Message != "notification held for approval" {
+			t.Fatalf("Expected held notification with a spam violation, got %s %+v", notification.Status, notification.PolicyViolations)
+		}
+	}
+	if match := held[0].PolicyViolations[0].Match; !strings.Contains(match, "9.5 exceeds 5.0") || !strings.Contains(match, "BAYES_99") {
+		t.Errorf("Unexpected violation: %q", match)
+	}
+
+	// Clean messages and unchecked accounts go through unscored or without violations
+	checker.checks = 0
+	clean := campaign("marketing", "Our product update", 2)
+	transactional := campaign("billing", "FREE MONEY refund", 1)
+	if _, err := svc.SendBatch(context.Background(), append(clean, transactional...)); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if checker.checks != 1 || len(clean[0].PolicyViolations) != 0 || len(transactional[0].PolicyViolations) != 0 {
+		t.Errorf("Expected only the marketing message to be scored and pass, got %d checks", checker.checks)
+	}
+
+	// A configured threshold overrides the engine's own
+	svc.spamCheck = &spamCheck{checker: checker, action: config.ContentActionWarn, threshold: 1}
+	warned := campaign("", "Our product update", 1)[0]
+	if _, err := svc.Send(context.Background(), warned); err != nil || len(warned.PolicyViolations) != 1 || warned.Status == domain.StatusHeld {
+		t.Errorf("Expected warning above custom threshold, got %v %+v", err, warned.PolicyViolations)
+	}
+
+	// Blocking rejects the whole campaign
+	svc.spamCheck = &spamCheck{checker: checker, action: config.ContentActionBlock}
+	blocked := append(campaign("", "Our product update", 1), campaign("", "FREE MONEY", 1)...)
+	if _, err := svc.SendBatch(context.Background(), blocked); !errors.Is(err, domain.ErrContentBlocked) {
+		t.Errorf("Expected ErrContentBlocked, got %v", err)
+	}
+	if _, err := svc.GetNotification(context.Background(), blocked[0].ID); err == nil {
+		t.Error("Expected no notification from a blocked campaign to be stored")
+	}
+
+	// An unavailable filter fails open or closed as configured
+	checker.err = errors.New("connection refused")
+	if _, err := svc.Send(context.Background(), campaign("", "FREE MONEY", 1)[0]); err == nil {
+		t.Error("Expected fail-closed spam check to reject the send")
+	}
+	svc.spamCheck.failOpen = true
+	if _, err := svc.Send(context.Background(), campaign("", "FREE MONEY", 1)[0]); err != nil {
+		t.Errorf("Expected fail-open spam check to send, got %v", err)
+	}
+}
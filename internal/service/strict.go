@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// serviceMetadataKeys are metadata keys the service itself reads, whatever the notifier
+var serviceMetadataKeys = []string{
+	"category",                // Dispatch weighting
+	"tenant",                  // Fair queueing
+	domain.EscalationMetadata, // Cancelled by acknowledgement
+	ackActionMetadata,         // Acknowledge button
+	replyCallbackMetadata,     // Reply routing
+	"apprise_type", "format",  // Set by the Apprise endpoint
+}
+
+// strictMode decides which callers have unknown keys rejected
+type strictMode struct {
+	all     bool
+	clients map[string]bool
+}
+
+// WithStrictConfig rejects notifications with metadata or options keys their notifier doesn't
+// read, for every caller or for the listed API key client IDs
+func (s *NotificationService) WithStrictConfig(cfg config.StrictConfig) {
+	if !cfg.Enabled && len(cfg.Clients) == 0 {
+		s.strict = nil
+		return
+	}
+
+	s.strict = &strictMode{all: cfg.Enabled, clients: make(map[string]bool, len(cfg.Clients))}
+	for _, client := range cfg.Clients {
+		s.strict.clients[client] = true
+	}
+}
+
+// appliesTo reports whether strict mode applies to the caller
+func (m *strictMode) appliesTo(ctx context.Context) bool {
+	if m.all {
+		return true
+	}
+	authCtx, ok := auth.GetAuthContext(ctx)
+	return ok && m.clients[authCtx.ClientID]
+}
+
+// checkStrict returns ErrUnknownKeys listing the notification's unrecognised options and
+// metadata keys if strict mode applies to the caller. Metadata is only checked for notifiers
+// that declare the keys they read. Notifications raised by the service itself and retries of
+// notifications already accepted aren't checked.
+func (s *NotificationService) checkStrict(ctx context.Context, notifications ...*domain.Notification) error {
+	if s.strict == nil || !s.strict.appliesTo(ctx) {
+		return nil
+	}
+
+	var unknown []string
+	for _, notification := range notifications {
+		if isOperationalAlert(notification) || s.isStored(notification.ID) {
+			continue
+		}
+
+		unknown = append(unknown, notification.Options.UnknownKeys()...)
+
+		notifier, err := s.factory.Create(notification.Type, s.resolveAccount(notification))
+		if err != nil {
+			continue // Fails when it's dispatched
+		}
+		reader, ok := notifier.(domain.MetadataReader)
+		if !ok {
+			continue
+		}
+		known := make(map[string]bool)
+		for _, key := range append(reader.MetadataKeys(), serviceMetadataKeys...) {
+			known[key] = true
+		}
+		for key := range notification.Metadata {
+			if !known[key] {
+				unknown = append(unknown, "metadata."+key)
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	unknown = slices.Compact(unknown)
+	return fmt.Errorf("%w: %s", domain.ErrUnknownKeys, strings.Join(unknown, ", "))
+}
+
+// isStored reports whether a notification has already been accepted
+func (s *NotificationService) isStored(id string) bool {
+	_, err := s.store.Get(context.Background(), id)
+	return err == nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/notifier"
+)
+
+// TestCheckStrict tests that strict mode lists unrecognised metadata and options keys, only for
+// the callers it applies to
+func TestCheckStrict(t *testing.T) {
+	svc := createTestService(t)
+	ntfy, err := notifier.NewNtfyNotifier(&notifier.NtfyConfig{ServerURL: "https://ntfy.example.com"})
+	if err != nil {
+		t.Fatalf("Failed to create ntfy notifier: %v", err)
+	}
+	if err := svc.factory.RegisterNotifier(domain.TypeNtfy, "", ntfy); err != nil {
+		t.Fatalf("Failed to register notifier: %v", err)
+	}
+	svc.WithStrictConfig(config.StrictConfig{Clients: []string{"billing-service"}})
+
+	var options domain.SendOptions
+	if err := json.Unmarshal([]byte(`{"ntfy": {"tags": ["warning"], "clik": "https://example.com"}, "sms": {}}`), &options); err != nil {
+		t.Fatalf("Failed to decode options: %v", err)
+	}
+	notification := &domain.Notification{
+		ID:         "n1",
+		Type:       domain.TypeNtfy,
+		Recipients: []string{"alerts"},
+		Metadata:   map[string]interface{}{"tgas": "warning", "category": "billing", "ack_action": true},
+		Options:    &options,
+	}
+	stdout := &domain.Notification{ID: "n2", Type: domain.TypeStdout, Metadata: map[string]interface{}{"anything": 1}}
+
+	strictCtx := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "billing-service"})
+	err = svc.checkStrict(strictCtx, notification, stdout)
+	if !errors.Is(err, domain.ErrUnknownKeys) {
+		t.Fatalf("Expected ErrUnknownKeys, got %v", err)
+	}
+	if !strings.HasSuffix(err.Error(), ": metadata.tgas, options.ntfy.clik, options.sms") {
+		t.Errorf("Expected the unrecognised keys listed, got %q", err)
+	}
+
+	otherCtx := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "web"})
+	if err := svc.checkStrict(otherCtx, notification); err != nil {
+		t.Errorf("Expected strict mode to apply only to listed clients, got %v", err)
+	}
+
+	svc.WithStrictConfig(config.StrictConfig{Enabled: true})
+	if _, err := svc.Send(otherCtx, notification); !errors.Is(err, domain.ErrUnknownKeys) {
+		t.Errorf("Expected Send to reject unknown keys for every caller, got %v", err)
+	}
+}
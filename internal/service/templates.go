@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/templates"
+)
+
+// WithTemplatesConfig adds the configured templates, so notifications can be rendered from
+// them by name, and sets the locales translations are picked by and the look of the email
+// layout
+func (s *NotificationService) WithTemplatesConfig(cfg config.TemplatesConfig) error {
+	if err := s.templates.SetLocales(cfg.DefaultLocale, cfg.LocaleFallbacks); err != nil {
+		return fmt.Errorf("failed to set template locales: %w", err)
+	}
+	if err := s.templates.SetEmailStyle(cfg.EmailLayout.Style()); err != nil {
+		return fmt.Errorf("failed to set email layout: %w", err)
+	}
+	for _, tmplCfg := range cfg.Definitions {
+		tmpl := tmplCfg.Template()
+		tmpl.Configured = true
+		if err := s.templates.Add(tmpl); err != nil {
+			return fmt.Errorf("failed to add template: %w", err)
+		}
+	}
+	return nil
+}
+
+// templateStore returns the store templates created through the API are kept in
+func (s *NotificationService) templateStore() (domain.TemplateStore, error) {
+	store, ok := s.store.(domain.TemplateStore)
+	if !ok {
+		return nil, fmt.Errorf("the notification store doesn't support templates")
+	}
+	return store, nil
+}
+
+// configuredTemplate returns a template from the config file, if there is one by that name
+func (s *NotificationService) configuredTemplate(name string) (domain.Template, bool) {
+	tmpl, ok := s.templates.Get("", name)
+	return tmpl, ok && tmpl.Configured
+}
+
+// checkTemplate validates a template before it's saved
+func (s *NotificationService) checkTemplate(tmpl domain.Template) error {
+	if tmpl.Type != "" && !s.supportsType(tmpl.Type) {
+		return fmt.Errorf("%w: unsupported notification type: %s", domain.ErrInvalidTemplate, tmpl.Type)
+	}
+	for notifType := range tmpl.Variants {
+		if !s.supportsType(notifType) {
+			return fmt.Errorf("%w: variant for unsupported notification type: %s", domain.ErrInvalidTemplate, notifType)
+		}
+	}
+	for tag, locale := range tmpl.Locales {
+		for notifType := range locale.Variants {
+			if !s.supportsType(notifType) {
+				return fmt.Errorf("%w: %s variant for unsupported notification type: %s", domain.ErrInvalidTemplate, tag, notifType)
+			}
+		}
+	}
+	return templates.Validate(tmpl)
+}
+
+// CreateTemplate validates a template and saves it to the store, as one of the caller's tenant
+func (s *NotificationService) CreateTemplate(ctx context.Context, tmpl domain.Template) (*domain.Template, error) {
+	if err := s.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	store, err := s.templateStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.configuredTemplate(tmpl.Name); ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTemplateExists, tmpl.Name)
+	}
+	tmpl.Tenant = auth.TenantFromContext(ctx)
+	if _, err := store.GetTemplate(ctx, tmpl.Tenant, tmpl.Name); err == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTemplateExists, tmpl.Name)
+	} else if !errors.Is(err, domain.ErrTemplateNotFound) {
+		return nil, err
+	}
+
+	tmpl.Configured = false
+	tmpl.CreatedAt = time.Now().UTC()
+	tmpl.UpdatedAt = tmpl.CreatedAt
+	if err := store.SaveTemplate(ctx, &tmpl); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Template created - name=%s, type=%s", tmpl.Name, scopeLabel(string(tmpl.Type)))
+	return &tmpl, nil
+}
+
+// UpdateTemplate validates a template and replaces the caller's tenant's stored template of the
+// same name
+func (s *NotificationService) UpdateTemplate(ctx context.Context, tmpl domain.Template) (*domain.Template, error) {
+	if _, ok := s.configuredTemplate(tmpl.Name); ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTemplateConfigured, tmpl.Name)
+	}
+	if err := s.checkTemplate(tmpl); err != nil {
+		return nil, err
+	}
+	store, err := s.templateStore()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Tenant = auth.TenantFromContext(ctx)
+	existing, err := store.GetTemplate(ctx, tmpl.Tenant, tmpl.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl.Configured = false
+	tmpl.CreatedAt = existing.CreatedAt
+	tmpl.UpdatedAt = time.Now().UTC()
+	if err := store.SaveTemplate(ctx, &tmpl); err != nil {
+		return nil, err
+	}
+
+	s.logger.Infof("Template updated - name=%s, type=%s", tmpl.Name, scopeLabel(string(tmpl.Type)))
+	return &tmpl, nil
+}
+
+// GetTemplate returns a template from the config file, or one of the caller's tenant's from the
+// store
+func (s *NotificationService) GetTemplate(ctx context.Context, name string) (*domain.Template, error) {
+	if tmpl, ok := s.configuredTemplate(name); ok {
+		return &tmpl, nil
+	}
+	store, err := s.templateStore()
+	if err != nil {
+		return nil, err
+	}
+	return lookupTemplate(ctx, store, auth.TenantFromContext(ctx), name)
+}
+
+// lookupTemplate returns one of a tenant's stored templates. Templates the instance's operators
+// created without a tenant are shared by every tenant, unless the tenant has its own by that
+// name.
+func lookupTemplate(ctx context.Context, store domain.TemplateStore, tenant, name string) (*domain.Template, error) {
+	tmpl, err := store.GetTemplate(ctx, tenant, name)
+	if errors.Is(err, domain.ErrTemplateNotFound) && tenant != "" {
+		return store.GetTemplate(ctx, "", name)
+	}
+	return tmpl, err
+}
+
+// ListTemplates returns the configured templates, the caller's tenant's stored ones and the
+// shared ones it doesn't shadow that can render notifications of a type, or every one if the
+// type is empty, sorted by name
+func (s *NotificationService) ListTemplates(ctx context.Context, notifType domain.NotificationType) ([]*domain.Template, error) {
+	var list []*domain.Template
+	for _, tmpl := range s.templates.List() {
+		if tmpl.Configured && (notifType == "" || tmpl.AppliesTo(notifType)) {
+			list = append(list, &tmpl)
+		}
+	}
+
+	store, err := s.templateStore()
+	if err != nil {
+		return nil, err
+	}
+	tenant := auth.TenantFromContext(ctx)
+	stored, err := store.ListTemplates(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if tenant != "" {
+		shared, err := store.ListTemplates(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		stored = append(stored, shared...)
+	}
+	seen := make(map[string]bool, len(stored))
+	for _, tmpl := range stored {
+		if _, ok := s.configuredTemplate(tmpl.Name); ok || seen[tmpl.Name] {
+			continue // Shadowed by the config file or by the tenant's own
+		}
+		seen[tmpl.Name] = true
+		if notifType == "" || tmpl.AppliesTo(notifType) {
+			list = append(list, tmpl)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// DeleteTemplate removes a template the caller's tenant created through the API
+func (s *NotificationService) DeleteTemplate(ctx context.Context, name string) error {
+	if _, ok := s.configuredTemplate(name); ok {
+		return fmt.Errorf("%w: %s", domain.ErrTemplateConfigured, name)
+	}
+	store, err := s.templateStore()
+	if err != nil {
+		return err
+	}
+	tenant := auth.TenantFromContext(ctx)
+	if err := store.DeleteTemplate(ctx, tenant, name); err != nil {
+		return err
+	}
+	s.templates.Remove(tenant, name)
+
+	s.logger.Infof("Template deleted - name=%s", name)
+	return nil
+}
+
+// resolveTemplate makes sure the engine has the current version of a template from the config
+// file or of one a tenant can use. Templates created through the API are read from the store,
+// so a change made through another replica is picked up on the next send.
+func (s *NotificationService) resolveTemplate(ctx context.Context, tenant, name string) (domain.Template, error) {
+	if tmpl, ok := s.configuredTemplate(name); ok {
+		return tmpl, nil
+	}
+	store, err := s.templateStore()
+	if err != nil {
+		return domain.Template{}, err
+	}
+	stored, err := lookupTemplate(ctx, store, tenant, name)
+	if err != nil {
+		if errors.Is(err, domain.ErrTemplateNotFound) {
+			// Deleted through another replica
+			s.templates.Remove(tenant, name)
+			s.templates.Remove("", name)
+		}
+		return domain.Template{}, err
+	}
+	if stored.Tenant != tenant {
+		s.templates.Remove(tenant, name) // The tenant's own was deleted through another replica
+	}
+
+	if cached, ok := s.templates.Get(stored.Tenant, name); !ok || !cached.UpdatedAt.Equal(stored.UpdatedAt) {
+		if err := s.templates.Add(*stored); err != nil {
+			return domain.Template{}, err
+		}
+	}
+	return *stored, nil
+}
+
+// RenderTemplate renders a template without sending anything, so its output can be checked
+func (s *NotificationService) RenderTemplate(ctx context.Context, name string, notifType domain.NotificationType, locale string, vars map[string]interface{}) (*domain.RenderedTemplate, error) {
+	if notifType != "" && !s.supportsType(notifType) {
+		return nil, fmt.Errorf("%w: unsupported notification type: %s", domain.ErrInvalidTemplate, notifType)
+	}
+	tmpl, err := s.resolveTemplate(ctx, auth.TenantFromContext(ctx), name)
+	if err != nil {
+		return nil, err
+	}
+	if notifType == "" {
+		notifType = tmpl.Type
+	}
+
+	display := s.timeFormatter(&domain.Notification{Type: notifType})
+	rendered, err := s.render(tmpl, notifType, locale, vars, display)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RenderedTemplate{
+		Template: name,
+		Type:     notifType,
+		Locale:   rendered.Locale,
+		Subject:  rendered.Subject,
+		Body:     rendered.Body,
+		HTMLBody: rendered.HTMLBody,
+	}, nil
+}
+
+// render renders a resolved template for a notification type and locale, with times rendered
+// by display. An empty type renders the template's own parts.
+func (s *NotificationService) render(tmpl domain.Template, notifType domain.NotificationType, locale string, vars map[string]interface{}, display domain.TimeFormatter) (templates.Rendered, error) {
+	if notifType != "" && !tmpl.AppliesTo(notifType) {
+		return templates.Rendered{}, fmt.Errorf("%w: %s has no rendering for %s notifications", domain.ErrInvalidTemplate, tmpl.Name, notifType)
+	}
+	return s.templates.RenderFor(tmpl.Tenant, tmpl.Name, notifType, locale, vars, display)
+}
+
+// timeFormatter returns what renders times for the recipients of the account a notification is
+// sent through, or nil if its notifier renders them in UTC
+func (s *NotificationService) timeFormatter(notification *domain.Notification) domain.TimeFormatter {
+	if notification.Type == "" {
+		return nil
+	}
+	n, err := s.factory.Create(notification.Type, s.resolveAccount(notification))
+	if err != nil {
+		return nil
+	}
+	display, _ := n.(domain.TimeFormatter)
+	return display
+}
+
+// renderTemplates renders the subject and bodies of the notifications that reference a
+// template, each in its locale's translation and with the variant for its channel where the
+// template has them. The parts the template sets replace the notification's own; the rest are
+// kept.
+func (s *NotificationService) renderTemplates(ctx context.Context, notifications ...*domain.Notification) error {
+	for _, notification := range notifications {
+		if notification.Template == "" {
+			continue
+		}
+
+		tmpl, err := s.resolveTemplate(ctx, notification.Tenant, notification.Template)
+		if err != nil {
+			return err
+		}
+		rendered, err := s.render(tmpl, notification.Type, notification.Locale, notification.TemplateVars, s.timeFormatter(notification))
+		if err != nil {
+			return err
+		}
+		if rendered.Subject != "" {
+			notification.Subject = rendered.Subject
+		}
+		if rendered.Body != "" {
+			notification.Body = rendered.Body
+		}
+		if rendered.HTMLBody != "" {
+			notification.HTMLBody = rendered.HTMLBody
+		}
+		if notification.Body == "" && notification.HTMLBody == "" {
+			return fmt.Errorf("%w: %s rendered an empty body", domain.ErrInvalidTemplate, notification.Template)
+		}
+	}
+	return nil
+}
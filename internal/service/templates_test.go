@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/notifier"
+)
+
+// TestSendRendersTemplate tests that a notification naming a template is queued with the
+// rendered subject and body, keeping the parts the template doesn't set
+func TestSendRendersTemplate(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithTemplatesConfig(config.TemplatesConfig{Definitions: []config.TemplateConfig{
+		{Name: "deploy", Body: "{{.service}} deployed to {{.env}}"},
+	}})
+	if err != nil {
+		t.Fatalf("WithTemplatesConfig() error = %v", err)
+	}
+
+	notification := &domain.Notification{
+		Type:         domain.TypeStdout,
+		Subject:      "Deploys",
+		Recipients:   []string{"console"},
+		Template:     "deploy",
+		TemplateVars: map[string]interface{}{"service": "billing", "env": "prod"},
+	}
+	result, err := svc.Send(ctx, notification)
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	stored, err := svc.GetNotification(ctx, result.NotificationID)
+	if err != nil {
+		t.Fatalf("GetNotification() error = %v", err)
+	}
+	if stored.Subject != "Deploys" || stored.Body != "billing deployed to prod" || stored.Template != "deploy" {
+		t.Errorf("Unexpected rendered notification: subject=%q, body=%q, template=%q", stored.Subject, stored.Body, stored.Template)
+	}
+}
+
+// TestSendRendersTimesForAccount tests that formatTime renders times in the display timezone
+// of the account a notification is sent through
+func TestSendRendersTimesForAccount(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	audit, err := notifier.NewFileNotifier(&notifier.FileConfig{
+		Path:    filepath.Join(t.TempDir(), "audit.log"),
+		Display: notifier.DisplayConfig{Timezone: "Europe/Berlin", Locale: "de-DE"},
+	})
+	if err != nil {
+		t.Fatalf("NewFileNotifier() error = %v", err)
+	}
+	if err := svc.factory.(*notifier.Factory).RegisterNotifier(domain.TypeFile, "audit", audit); err != nil {
+		t.Fatalf("RegisterNotifier() error = %v", err)
+	}
+	err = svc.WithTemplatesConfig(config.TemplatesConfig{Definitions: []config.TemplateConfig{
+		{Name: "maintenance", Body: "Maintenance starts {{formatTime .start}}"},
+	}})
+	if err != nil {
+		t.Fatalf("WithTemplatesConfig() error = %v", err)
+	}
+
+	vars := map[string]interface{}{"start": "2024-05-01T07:00:00Z"}
+	for _, tt := range []struct {
+		notifType domain.NotificationType
+		account   string
+		want      string
+	}{
+		{domain.TypeFile, "audit", "Maintenance starts 01.05.2024 09:00 CEST"},
+		{domain.TypeStdout, "", "Maintenance starts 2024-05-01 07:00 UTC"},
+	} {
+		notification := &domain.Notification{Type: tt.notifType, Account: tt.account, Recipients: []string{"ops"}, Template: "maintenance", TemplateVars: vars}
+		if _, err := svc.Send(ctx, notification); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if notification.Body != tt.want {
+			t.Errorf("%s body = %q, want %q", tt.notifType, notification.Body, tt.want)
+		}
+	}
+}
+
+// TestSendTemplateErrors tests that unknown templates and missing variables are rejected
+// before anything is queued
+func TestSendTemplateErrors(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithTemplatesConfig(config.TemplatesConfig{Definitions: []config.TemplateConfig{
+		{Name: "deploy", Body: "{{.service}} deployed"},
+	}})
+	if err != nil {
+		t.Fatalf("WithTemplatesConfig() error = %v", err)
+	}
+
+	_, err = svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "rollback"})
+	if !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+
+	_, err = svc.SendBatch(ctx, []*domain.Notification{
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Body: "plain"},
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "deploy"},
+	})
+	if !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for a missing variable, got %v", err)
+	}
+}
+
+// TestTemplateLifecycle tests creating, updating and deleting a template through the API, and
+// that sends pick up each change
+func TestTemplateLifecycle(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.CreateTemplate(ctx, domain.Template{Name: "broken", Body: "{{.service"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a template that doesn't parse to be rejected, got %v", err)
+	}
+
+	created, err := svc.CreateTemplate(ctx, domain.Template{Name: "deploy", Body: "{{.service}} deployed"})
+	if err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+	if created.CreatedAt.IsZero() || !created.UpdatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Unexpected timestamps: created=%v, updated=%v", created.CreatedAt, created.UpdatedAt)
+	}
+	if _, err := svc.CreateTemplate(ctx, domain.Template{Name: "deploy", Body: "again"}); !errors.Is(err, domain.ErrTemplateExists) {
+		t.Errorf("Expected ErrTemplateExists, got %v", err)
+	}
+
+	send := func() (*domain.Notification, error) {
+		notification := &domain.Notification{
+			Type:         domain.TypeStdout,
+			Recipients:   []string{"console"},
+			Template:     "deploy",
+			TemplateVars: map[string]interface{}{"service": "billing"},
+		}
+		_, err := svc.Send(ctx, notification)
+		return notification, err
+	}
+	if notification, err := send(); err != nil || notification.Body != "billing deployed" {
+		t.Fatalf("Send() = %q, %v", notification.Body, err)
+	}
+
+	if _, err := svc.UpdateTemplate(ctx, domain.Template{Name: "deploy", Body: "{{upper .service}} is live"}); err != nil {
+		t.Fatalf("UpdateTemplate() error = %v", err)
+	}
+	if notification, err := send(); err != nil || notification.Body != "BILLING is live" {
+		t.Errorf("Expected the updated template to be rendered, got %q, %v", notification.Body, err)
+	}
+
+	if err := svc.DeleteTemplate(ctx, "deploy"); err != nil {
+		t.Fatalf("DeleteTemplate() error = %v", err)
+	}
+	if _, err := send(); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected a deleted template to be unknown, got %v", err)
+	}
+	if _, err := svc.UpdateTemplate(ctx, domain.Template{Name: "deploy", Body: "x"}); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected updating a deleted template to fail, got %v", err)
+	}
+}
+
+// TestListTemplatesByType tests listing templates by channel, and that configured templates
+// can't be changed through the API
+func TestListTemplatesByType(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithTemplatesConfig(config.TemplatesConfig{Definitions: []config.TemplateConfig{
+		{Name: "welcome", Type: "email", Subject: "Welcome", Body: "Hi {{.name}}"},
+	}})
+	if err != nil {
+		t.Fatalf("WithTemplatesConfig() error = %v", err)
+	}
+	for _, tmpl := range []domain.Template{
+		{Name: "alert", Body: "{{.message}}"},
+		{Name: "console", Type: domain.TypeStdout, Body: "{{.message}}"},
+	} {
+		if _, err := svc.CreateTemplate(ctx, tmpl); err != nil {
+			t.Fatalf("CreateTemplate(%s) error = %v", tmpl.Name, err)
+		}
+	}
+
+	names := func(notifType domain.NotificationType) []string {
+		list, err := svc.ListTemplates(ctx, notifType)
+		if err != nil {
+			t.Fatalf("ListTemplates() error = %v", err)
+		}
+		var names []string
+		for _, tmpl := range list {
+			names = append(names, tmpl.Name)
+		}
+		return names
+	}
+	if got := names(""); len(got) != 3 || got[0] != "alert" || got[1] != "console" || got[2] != "welcome" {
+		t.Errorf("Expected every template sorted by name, got %v", got)
+	}
+	if got := names(domain.TypeStdout); len(got) != 2 || got[0] != "alert" || got[1] != "console" {
+		t.Errorf("Expected the stdout and any-channel templates, got %v", got)
+	}
+
+	if _, err := svc.CreateTemplate(ctx, domain.Template{Name: "welcome", Body: "x"}); !errors.Is(err, domain.ErrTemplateExists) {
+		t.Errorf("Expected a configured name to be taken, got %v", err)
+	}
+	if err := svc.DeleteTemplate(ctx, "welcome"); !errors.Is(err, domain.ErrTemplateConfigured) {
+		t.Errorf("Expected ErrTemplateConfigured, got %v", err)
+	}
+
+	_, err = svc.Send(ctx, &domain.Notification{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "welcome", TemplateVars: map[string]interface{}{"name": "Bob"}})
+	if !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected an email template to be rejected for stdout, got %v", err)
+	}
+}
+
+// TestSendRendersChannelVariants tests that each notification in a batch is rendered with the
+// variant for its channel
+func TestSendRendersChannelVariants(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateTemplate(ctx, domain.Template{
+		Name: "outage",
+		Body: "{{.service}} is down",
+		Variants: map[domain.NotificationType]domain.TemplateVariant{
+			domain.TypeStdout: {Body: "[DOWN] {{.service}}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+	if _, err := svc.CreateTemplate(ctx, domain.Template{Name: "bad", Body: "x", Variants: map[domain.NotificationType]domain.TemplateVariant{"fax": {Body: "x"}}}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a variant for an unsupported type to be rejected, got %v", err)
+	}
+
+	vars := map[string]interface{}{"service": "api"}
+	notifications := []*domain.Notification{
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "outage", TemplateVars: vars},
+		{Type: domain.TypeFile, Recipients: []string{"/tmp/outage.log"}, Template: "outage", TemplateVars: vars},
+	}
+	if _, err := svc.SendBatch(ctx, notifications); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+	if notifications[0].Body != "[DOWN] api" || notifications[1].Body != "api is down" {
+		t.Errorf("Unexpected bodies: stdout=%q, file=%q", notifications[0].Body, notifications[1].Body)
+	}
+}
+
+// TestSendRendersLocale tests that notifications are rendered in their locale's translation,
+// through the configured fallbacks, and in the default locale when they don't set one
+func TestSendRendersLocale(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	err := svc.WithTemplatesConfig(config.TemplatesConfig{
+		DefaultLocale:   "en",
+		LocaleFallbacks: map[string][]string{"gl": {"pt"}},
+		Definitions: []config.TemplateConfig{{
+			Name: "deploy",
+			Body: "{{.service}} deployed",
+			Locales: map[string]config.TemplateLocaleConfig{
+				"en": {Body: "{{.service}} is live"},
+				"pt": {Body: "{{.service}} implantado"},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("WithTemplatesConfig() error = %v", err)
+	}
+	if err := svc.WithTemplatesConfig(config.TemplatesConfig{DefaultLocale: "not a locale"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a malformed default locale to be rejected, got %v", err)
+	}
+
+	vars := map[string]interface{}{"service": "api"}
+	notifications := []*domain.Notification{
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "deploy", TemplateVars: vars},
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "deploy", TemplateVars: vars, Locale: "pt-BR"},
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "deploy", TemplateVars: vars, Locale: "gl"},
+		{Type: domain.TypeStdout, Recipients: []string{"console"}, Template: "deploy", TemplateVars: vars, Locale: "ja"},
+	}
+	if _, err := svc.SendBatch(ctx, notifications); err != nil {
+		t.Fatalf("SendBatch() error = %v", err)
+	}
+
+	want := []string{"api is live", "api implantado", "api implantado", "api deployed"}
+	for i, notification := range notifications {
+		if notification.Body != want[i] {
+			t.Errorf("Notification %d (locale %q) body = %q, want %q", i, notification.Locale, notification.Body, want[i])
+		}
+	}
+}
+
+// TestRenderTemplate tests that a template is rendered for a channel and locale without
+// anything being queued, and that rendering errors are reported as they would be on send
+func TestRenderTemplate(t *testing.T) {
+	svc := createTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateTemplate(ctx, domain.Template{
+		Name:    "outage",
+		Subject: "{{.service}} is down",
+		Body:    "{{.service}} has been down since {{.since}}.",
+		Variants: map[domain.NotificationType]domain.TemplateVariant{
+			domain.TypeStdout: {Body: "[DOWN] {{.service}}"},
+		},
+		Locales: map[string]domain.TemplateLocale{
+			"fr": {Subject: "{{.service}} est en panne"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTemplate() error = %v", err)
+	}
+	vars := map[string]interface{}{"service": "api", "since": "09:00"}
+
+	rendered, err := svc.RenderTemplate(ctx, "outage", domain.TypeStdout, "fr-CA", vars)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	want := domain.RenderedTemplate{Template: "outage", Type: domain.TypeStdout, Locale: "fr", Subject: "api est en panne", Body: "[DOWN] api"}
+	if *rendered != want {
+		t.Errorf("RenderTemplate() = %+v, want %+v", *rendered, want)
+	}
+
+	rendered, err = svc.RenderTemplate(ctx, "outage", "", "", vars)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	if rendered.Type != "" || rendered.Body != "api has been down since 09:00." {
+		t.Errorf("Expected the template's own parts without a type, got %+v", *rendered)
+	}
+
+	if _, err := svc.RenderTemplate(ctx, "missing", domain.TypeStdout, "", vars); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, err := svc.RenderTemplate(ctx, "outage", domain.TypeStdout, "", map[string]interface{}{"since": "09:00"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for a missing variable, got %v", err)
+	}
+	if _, err := svc.RenderTemplate(ctx, "outage", "fax", "", vars); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for an unsupported type, got %v", err)
+	}
+
+	page, err := svc.ListNotifications(ctx, &domain.NotificationFilter{})
+	if err != nil {
+		t.Fatalf("ListNotifications() error = %v", err)
+	}
+	if len(page.Notifications) != 0 {
+		t.Errorf("Expected nothing to be queued, got %d notifications", len(page.Notifications))
+	}
+}
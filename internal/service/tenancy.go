@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// tenancy holds the accounts reserved for tenants and each tenant's default accounts
+type tenancy struct {
+	accounts map[string][]string                           // "type:account" -> tenants it's reserved for
+	defaults map[string]map[domain.NotificationType]string // Tenant -> type -> account
+}
+
+// WithTenancyConfig reserves accounts for tenants and sets the accounts their notifications are
+// sent through by default. Which tenant a client belongs to is resolved when it authenticates.
+func (s *NotificationService) WithTenancyConfig(cfg config.TenancyConfig) {
+	if !cfg.Enabled {
+		s.tenancy = nil
+		return
+	}
+
+	t := &tenancy{
+		accounts: make(map[string][]string),
+		defaults: make(map[string]map[domain.NotificationType]string, len(cfg.Tenants)),
+	}
+	for name, tenant := range cfg.Tenants {
+		for _, account := range tenant.Accounts {
+			t.accounts[account] = append(t.accounts[account], name)
+		}
+		defaults := make(map[domain.NotificationType]string, len(tenant.DefaultAccounts))
+		for notifType, account := range tenant.DefaultAccounts {
+			defaults[domain.NotificationType(notifType)] = account
+		}
+		t.defaults[name] = defaults
+	}
+	s.tenancy = t
+}
+
+// stampTenant records the tenant of the client sending a notification. Notifications sent
+// by the service itself, e.g. scheduled ones, keep the tenant they were created with.
+func stampTenant(ctx context.Context, notifications ...*domain.Notification) {
+	tenant := auth.TenantFromContext(ctx)
+	if tenant == "" {
+		return
+	}
+	for _, notification := range notifications {
+		notification.Tenant = tenant
+	}
+}
+
+// visibleTo reports whether the caller may see something belonging to tenant. Callers without
+// a tenant see every tenant's.
+func visibleTo(ctx context.Context, tenant string) bool {
+	caller := auth.TenantFromContext(ctx)
+	return caller == "" || caller == tenant
+}
+
+// tenantAccount returns the account a tenant's notifications of a type are sent through when
+// they don't name one, or empty if the tenant has no default
+func (s *NotificationService) tenantAccount(tenant string, notifType domain.NotificationType) string {
+	if s.tenancy == nil || tenant == "" {
+		return ""
+	}
+	return s.tenancy.defaults[tenant][notifType]
+}
+
+// checkTenantAccounts rejects a tenant's notification sent, or falling back, through an
+// account reserved for other tenants
+func (s *NotificationService) checkTenantAccounts(notification *domain.Notification) error {
+	if s.tenancy == nil || notification.Tenant == "" {
+		return nil
+	}
+
+	account := s.resolveAccount(notification)
+	if tenants := s.reservedElsewhere(notification.Tenant, notification.Type, account); tenants != nil {
+		return fmt.Errorf("%w: %s:%s is reserved for %s", domain.ErrTenantAccount, notification.Type, account, strings.Join(tenants, ", "))
+	}
+	for _, fallback := range notification.Fallbacks {
+		account := fallback.Account
+		if account == "" {
+			account = s.tenantAccount(notification.Tenant, fallback.Type)
+		}
+		account = s.resolveAccount(&domain.Notification{Type: fallback.Type, Account: account})
+		if tenants := s.reservedElsewhere(notification.Tenant, fallback.Type, account); tenants != nil {
+			return fmt.Errorf("%w: %s:%s is reserved for %s", domain.ErrTenantAccount, fallback.Type, account, strings.Join(tenants, ", "))
+		}
+	}
+	return nil
+}
+
+// reservedElsewhere returns the tenants an account is reserved for when tenant isn't one of
+// them, or nil if tenant may use it
+func (s *NotificationService) reservedElsewhere(tenant string, notifType domain.NotificationType, account string) []string {
+	if s.tenancy == nil || tenant == "" {
+		return nil
+	}
+	tenants, reserved := s.tenancy.accounts[string(notifType)+":"+account]
+	if !reserved || slices.Contains(tenants, tenant) {
+		return nil
+	}
+	return tenants
+}
+
+// getNotification retrieves a notification the caller can see. Other tenants' notifications
+// are reported as not found, so callers can't learn which IDs exist.
+func (s *NotificationService) getNotification(ctx context.Context, id string) (*domain.Notification, error) {
+	return s.visibleNotification(ctx, s.store, id)
+}
+
+// visibleNotification retrieves a notification the caller can see from the given reads
+func (s *NotificationService) visibleNotification(ctx context.Context, reader domain.NotificationReader, id string) (*domain.Notification, error) {
+	notification, err := reader.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, notification.Tenant) {
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, id)
+	}
+	return notification, nil
+}
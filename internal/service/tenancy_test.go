@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/igodwin/notifier/internal/auth"
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestTenantIsolation tests that a tenant's clients only see its own notifications, while
+// clients without a tenant see every tenant's
+func TestTenantIsolation(t *testing.T) {
+	svc := createTestService(t)
+	svc.WithTenancyConfig(config.TenancyConfig{Enabled: true})
+	if err := svc.WithDedupConfig(config.DedupConfig{Enabled: true, Window: "1h"}); err != nil {
+		t.Fatalf("WithDedupConfig() error = %v", err)
+	}
+
+	tenantCtx := func(tenant string) context.Context {
+		return auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: tenant + "-service", Tenant: tenant})
+	}
+	payments, search := tenantCtx("payments"), tenantCtx("search")
+	admin := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "ops", Roles: []string{"admin"}})
+
+	send := func(ctx context.Context, id string) {
+		t.Helper()
+		notification := &domain.Notification{ID: id, Type: domain.TypeStdout, Body: "Payout failed", Recipients: []string{"ops"}, DedupKey: "payout-failed"}
+		result, err := svc.Send(ctx, notification)
+		if err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+		if result.Suppressed {
+			t.Fatalf("Expected %s not to be suppressed by another tenant's dedup key", id)
+		}
+	}
+	send(payments, "payments-1")
+	send(search, "search-1")
+
+	stored, err := svc.GetNotification(payments, "payments-1")
+	if err != nil || stored.Tenant != "payments" {
+		t.Fatalf("Expected the notification to be stamped with its tenant, got %+v, error=%v", stored, err)
+	}
+	if _, err := svc.GetNotification(payments, "search-1"); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected another tenant's notification not to be found, got %v", err)
+	}
+	if err := svc.CancelNotification(payments, "search-1"); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected another tenant's notification not to be cancelled, got %v", err)
+	}
+
+	page, err := svc.ListNotifications(payments, &domain.NotificationFilter{Tenant: "search"})
+	if err != nil {
+		t.Fatalf("ListNotifications() error = %v", err)
+	}
+	if len(page.Notifications) != 1 || page.Notifications[0].ID != "payments-1" {
+		t.Errorf("Expected only the tenant's own notification to be listed, got %d", len(page.Notifications))
+	}
+	page, err = svc.ListNotifications(admin, nil)
+	if err != nil {
+		t.Fatalf("ListNotifications() error = %v", err)
+	}
+	if len(page.Notifications) != 2 {
+		t.Errorf("Expected a client without a tenant to list every tenant's notifications, got %d", len(page.Notifications))
+	}
+
+	stats, err := svc.GetStats(search)
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.ByType[string(domain.TypeStdout)] != 1 {
+		t.Errorf("Expected stats to count only the tenant's notifications, got %v", stats.ByType)
+	}
+}
+
+// TestTenantAccounts tests that accounts reserved for a tenant can't be used by others and
+// that a tenant's notifications are sent through its default accounts
+func TestTenantAccounts(t *testing.T) {
+	svc := createTestService(t)
+	svc.WithTenancyConfig(config.TenancyConfig{Enabled: true, Tenants: map[string]config.TenantConfig{
+		"payments": {Accounts: []string{"stdout:payments"}, DefaultAccounts: map[string]string{"stdout": "payments"}},
+		"search":   {},
+	}})
+	payments := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "billing-service", Tenant: "payments"})
+	search := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "indexer", Tenant: "search"})
+
+	defaulted := &domain.Notification{Type: domain.TypeStdout, Body: "Payout failed", Recipients: []string{"ops"}}
+	if _, err := svc.Send(payments, defaulted); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if defaulted.Account != "payments" {
+		t.Errorf("Expected the tenant's default account, got %q", defaulted.Account)
+	}
+
+	reserved := &domain.Notification{Type: domain.TypeStdout, Account: "payments", Body: "Reindex done", Recipients: []string{"ops"}}
+	if _, err := svc.Send(search, reserved); !errors.Is(err, domain.ErrTenantAccount) {
+		t.Errorf("Expected another tenant's account to be rejected, got %v", err)
+	}
+	fallback := &domain.Notification{
+		Type:       domain.TypeStdout,
+		Body:       "Reindex failed",
+		Recipients: []string{"ops"},
+		Fallbacks:  []domain.Fallback{{Type: domain.TypeStdout, Account: "payments", Recipients: []string{"ops"}}},
+	}
+	if _, err := svc.Send(search, fallback); !errors.Is(err, domain.ErrTenantAccount) {
+		t.Errorf("Expected a fallback through another tenant's account to be rejected, got %v", err)
+	}
+	if _, err := svc.Send(search, &domain.Notification{Type: domain.TypeStdout, Body: "Reindex done", Recipients: []string{"ops"}}); err != nil {
+		t.Errorf("Expected an unreserved account to be usable by any tenant, got %v", err)
+	}
+}
+
+// TestTenantContactsTemplatesPreferences tests that templates, contacts and preferences
+// created by one tenant are neither visible to nor used by another, even with the same name,
+// while those created without a tenant are shared
+func TestTenantContactsTemplatesPreferences(t *testing.T) {
+	svc := createTestService(t)
+	svc.WithTenancyConfig(config.TenancyConfig{Enabled: true})
+	payments := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "billing-service", Tenant: "payments"})
+	search := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "indexer", Tenant: "search"})
+	admin := auth.ContextWithAuth(context.Background(), &auth.AuthContext{ClientID: "ops", Roles: []string{"admin"}})
+
+	if _, err := svc.CreateContact(admin, domain.Contact{Name: "sre", Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {"sre"}}}); err != nil {
+		t.Fatalf("CreateContact() error = %v", err)
+	}
+
+	for tenant, ctx := range map[string]context.Context{"payments": payments, "search": search} {
+		if _, err := svc.CreateContact(ctx, domain.Contact{Name: "oncall", Addresses: map[domain.NotificationType][]string{domain.TypeStdout: {tenant + "-oncall"}}}); err != nil {
+			t.Fatalf("CreateContact() error = %v", err)
+		}
+		if _, err := svc.CreateTemplate(ctx, domain.Template{Name: "alert", Body: tenant + ": {{.message}}"}); err != nil {
+			t.Fatalf("CreateTemplate() error = %v", err)
+		}
+	}
+	if _, err := svc.SetPreferences(payments, domain.Preferences{Contact: "oncall", MutedCategories: []string{"billing"}}); err != nil {
+		t.Fatalf("SetPreferences() error = %v", err)
+	}
+
+	contacts, err := svc.ListContacts(search)
+	if err != nil {
+		t.Fatalf("ListContacts() error = %v", err)
+	}
+	if len(contacts) != 2 || contacts[0].Addresses[domain.TypeStdout][0] != "search-oncall" || contacts[1].Name != "sre" {
+		t.Errorf("Expected the tenant's own contact and the shared one to be listed, got %+v", contacts)
+	}
+	tmpl, err := svc.GetTemplate(search, "alert")
+	if err != nil || tmpl.Body != "search: {{.message}}" {
+		t.Errorf("Expected the tenant's own template, got %+v, error=%v", tmpl, err)
+	}
+	if _, err := svc.GetPreferences(search, "oncall"); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Errorf("Expected another tenant's preferences not to be found, got %v", err)
+	}
+
+	notification := &domain.Notification{
+		Type:         domain.TypeStdout,
+		Template:     "alert",
+		TemplateVars: map[string]interface{}{"message": "reindex done"},
+		Contacts:     []string{"oncall", "sre"},
+		Metadata:     map[string]interface{}{"category": "billing"},
+	}
+	if _, err := svc.Send(search, notification); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if notification.Body != "search: reindex done" {
+		t.Errorf("Expected the tenant's own template to be rendered, got %q", notification.Body)
+	}
+	if len(notification.Recipients) != 2 || notification.Recipients[0] != "search-oncall" || notification.Recipients[1] != "sre" {
+		t.Errorf("Expected the tenant's own contact and the shared one to be resolved, got %v", notification.Recipients)
+	}
+
+	if err := svc.DeleteContact(search, "oncall"); err != nil {
+		t.Fatalf("DeleteContact() error = %v", err)
+	}
+	if _, err := svc.GetContact(payments, "oncall"); err != nil {
+		t.Errorf("Expected deleting a contact not to affect another tenant's, got %v", err)
+	}
+	if _, err := svc.GetPreferences(payments, "oncall"); err != nil {
+		t.Errorf("Expected deleting a contact not to affect another tenant's preferences, got %v", err)
+	}
+}
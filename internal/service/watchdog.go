@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// failureWatchdog tracks send attempts per account and flags failure rates
+// that spike above their trailing baseline
+type failureWatchdog struct {
+	mu             sync.Mutex
+	window         time.Duration
+	baselineWindow time.Duration
+	multiplier     float64
+	minFailureRate float64
+	minSamples     int
+	maxQueueAge    time.Duration
+	cooldown       time.Duration
+	attempts       map[string][]sendAttempt
+	lastAlert      map[string]time.Time
+}
+
+// sendAttempt is the outcome of a single send attempt
+type sendAttempt struct {
+	at     time.Time
+	failed bool
+}
+
+// failureAnomaly describes an account whose recent failure rate is abnormal
+type failureAnomaly struct {
+	account      string
+	recentRate   float64
+	baselineRate float64
+	attempts     int
+}
+
+// newFailureWatchdog creates a watchdog from the watchdog configuration
+func newFailureWatchdog(cfg config.WatchdogConfig) (*failureWatchdog, error) {
+	window, err := time.ParseDuration(cfg.Window)
+	if err != nil {
+		return nil, fmt.Errorf("invalid window duration: %w", err)
+	}
+
+	baselineWindow, err := time.ParseDuration(cfg.BaselineWindow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline window duration: %w", err)
+	}
+
+	cooldown, err := time.ParseDuration(cfg.Cooldown)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cooldown duration: %w", err)
+	}
+
+	var maxQueueAge time.Duration
+	if cfg.MaxQueueAge != "" {
+		if maxQueueAge, err = time.ParseDuration(cfg.MaxQueueAge); err != nil {
+			return nil, fmt.Errorf("invalid max queue age duration: %w", err)
+		}
+	}
+
+	return &failureWatchdog{
+		window:         window,
+		baselineWindow: baselineWindow,
+		multiplier:     cfg.FailureRateMultiplier,
+		minFailureRate: cfg.MinFailureRate,
+		minSamples:     cfg.MinSamples,
+		maxQueueAge:    maxQueueAge,
+		cooldown:       cooldown,
+		attempts:       make(map[string][]sendAttempt),
+		lastAlert:      make(map[string]time.Time),
+	}, nil
+}
+
+// record adds a send attempt for an account
+func (w *failureWatchdog) record(account string, failed bool, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.attempts[account] = append(w.attempts[account], sendAttempt{at: at, failed: failed})
+}
+
+// anomalies returns accounts whose failure rate over the recent window is abnormal
+// compared to the baseline window before it
+func (w *failureWatchdog) anomalies(now time.Time) []failureAnomaly {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	recentStart := now.Add(-w.window)
+	baselineStart := recentStart.Add(-w.baselineWindow)
+
+	var anomalies []failureAnomaly
+	for account, attempts := range w.attempts {
+		// Attempts are appended in time order, so anything older than the baseline is at the front
+		expired := 0
+		for expired < len(attempts) && attempts[expired].at.Before(baselineStart) {
+			expired++
+		}
+		attempts = attempts[expired:]
+		if len(attempts) == 0 {
+			delete(w.attempts, account)
+			continue
+		}
+		w.attempts[account] = attempts
+
+		var recent, recentFailed, baseline, baselineFailed int
+		for _, attempt := range attempts {
+			if attempt.at.Before(recentStart) {
+				baseline++
+				if attempt.failed {
+					baselineFailed++
+				}
+			} else {
+				recent++
+				if attempt.failed {
+					recentFailed++
+				}
+			}
+		}
+
+		if recent == 0 || recent < w.minSamples {
+			continue
+		}
+
+		recentRate := float64(recentFailed) / float64(recent)
+		baselineRate := 0.0
+		if baseline > 0 {
+			baselineRate = float64(baselineFailed) / float64(baseline)
+		}
+
+		if recentRate < w.minFailureRate || recentRate < baselineRate*w.multiplier {
+			continue
+		}
+
+		anomalies = append(anomalies, failureAnomaly{
+			account:      account,
+			recentRate:   recentRate,
+			baselineRate: baselineRate,
+			attempts:     recent,
+		})
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool { return anomalies[i].account < anomalies[j].account })
+	return anomalies
+}
+
+// shouldAlert reports whether an alert for key is outside its cooldown, and marks it as alerted
+func (w *failureWatchdog) shouldAlert(key string, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if last, ok := w.lastAlert[key]; ok && now.Sub(last) < w.cooldown {
+		return false
+	}
+	w.lastAlert[key] = now
+	return true
+}
+
+// WithWatchdogConfig enables failure-pattern anomaly detection
+func (s *NotificationService) WithWatchdogConfig(cfg config.WatchdogConfig) error {
+	if !cfg.Enabled {
+		s.watchdog = nil
+		return nil
+	}
+
+	checkFreq, err := time.ParseDuration(cfg.CheckFrequency)
+	if err != nil {
+		return fmt.Errorf("invalid check frequency duration: %w", err)
+	}
+
+	watchdog, err := newFailureWatchdog(cfg)
+	if err != nil {
+		return err
+	}
+
+	s.watchdog = watchdog
+	s.watchdogConfig = cfg
+	s.watchdogCheckFrequency = checkFreq
+
+	return nil
+}
+
+// recordAttempt records a send attempt for anomaly detection
+func (s *NotificationService) recordAttempt(notification *domain.Notification, account string, failed bool) {
+	if s.watchdog == nil || isOperationalAlert(notification) {
+		return
+	}
+	s.watchdog.record(fmt.Sprintf("%s/%s", notification.Type, account), failed, time.Now())
+}
+
+// watchdogLoop periodically looks for failure anomalies and queue backlog
+func (s *NotificationService) watchdogLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.watchdogCheckFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkWatchdog(ctx)
+		}
+	}
+}
+
+// checkWatchdog raises operational alerts for abnormal failure rates and a growing queue age
+func (s *NotificationService) checkWatchdog(ctx context.Context) {
+	now := time.Now()
+
+	for _, anomaly := range s.watchdog.anomalies(now) {
+		if !s.watchdog.shouldAlert("failures:"+anomaly.account, now) {
+			continue
+		}
+
+		s.logger.Warnf("Abnormal failure rate - account=%s, recent=%.2f, baseline=%.2f, attempts=%d",
+			anomaly.account, anomaly.recentRate, anomaly.baselineRate, anomaly.attempts)
+
+		subject := fmt.Sprintf("Abnormal failure rate for %s", anomaly.account)
+		body := fmt.Sprintf("%.0f%% of %d send attempts failed over the last %s (baseline %.0f%% over the previous %s).",
+			anomaly.recentRate*100, anomaly.attempts, s.watchdogConfig.Window, anomaly.baselineRate*100, s.watchdogConfig.BaselineWindow)
+		if err := s.sendOperationalAlert(ctx, s.watchdogConfig.Alert, subject, body, map[string]interface{}{"account": anomaly.account}); err != nil {
+			s.logger.Errorf("Failed to send watchdog alert - account=%s, error=%v", anomaly.account, err)
+		}
+	}
+
+	if s.watchdog.maxQueueAge <= 0 {
+		return
+	}
+
+	age := s.oldestWaitingAge(now)
+	if age <= s.watchdog.maxQueueAge || !s.watchdog.shouldAlert("queue-age", now) {
+		return
+	}
+
+	s.logger.Warnf("Queue age exceeded - oldest=%s, max=%s", age.Round(time.Second), s.watchdog.maxQueueAge)
+
+	subject := "Notification queue is falling behind"
+	body := fmt.Sprintf("The oldest waiting notification has been queued for %s (limit %s).",
+		age.Round(time.Second), s.watchdog.maxQueueAge)
+	if err := s.sendOperationalAlert(ctx, s.watchdogConfig.Alert, subject, body, nil); err != nil {
+		s.logger.Errorf("Failed to send watchdog alert - error=%v", err)
+	}
+}
+
+// oldestWaitingAge returns how long the oldest due, undelivered notification has been waiting
+func (s *NotificationService) oldestWaitingAge(now time.Time) time.Duration {
+	waiting, err := s.store.List(context.Background(), &domain.NotificationFilter{
+		Statuses: []domain.NotificationStatus{domain.StatusQueued, domain.StatusRetrying},
+	})
+	if err != nil {
+		return 0
+	}
+
+	var oldest time.Duration
+	for _, notification := range waiting {
+		if notification.CreatedAt.IsZero() || isOperationalAlert(notification) {
+			continue
+		}
+
+		due := notification.CreatedAt
+		if notification.ScheduledFor != nil && notification.ScheduledFor.After(due) {
+			due = *notification.ScheduledFor
+		}
+		if age := now.Sub(due); age > oldest {
+			oldest = age
+		}
+	}
+
+	return oldest
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+)
+
+// TestFailureWatchdogAnomalies tests failure rate spikes are detected against the baseline
+func TestFailureWatchdogAnomalies(t *testing.T) {
+	watchdog, err := newFailureWatchdog(config.WatchdogConfig{
+		Window:                "5m",
+		BaselineWindow:        "1h",
+		FailureRateMultiplier: 3,
+		MinFailureRate:        0.2,
+		MinSamples:            4,
+		Cooldown:              "15m",
+	})
+	if err != nil {
+		t.Fatalf("newFailureWatchdog() error = %v", err)
+	}
+
+	now := time.Now()
+	baseline := now.Add(-30 * time.Minute)
+	recent := now.Add(-time.Minute)
+
+	// webhook/broken: healthy baseline, now failing
+	for i := 0; i < 10; i++ {
+		watchdog.record("webhook/broken", false, baseline)
+	}
+	for i := 0; i < 4; i++ {
+		watchdog.record("webhook/broken", i > 0, recent)
+	}
+
+	// email/flaky: always fails about half the time, so it isn't anomalous
+	for i := 0; i < 10; i++ {
+		watchdog.record("email/flaky", i%2 == 0, baseline)
+		watchdog.record("email/flaky", i%2 == 0, recent)
+	}
+
+	// slack/quiet: failing but too few attempts to judge
+	watchdog.record("slack/quiet", true, recent)
+
+	anomalies := watchdog.anomalies(now)
+	if len(anomalies) != 1 || anomalies[0].account != "webhook/broken" {
+		t.Fatalf("Expected only webhook/broken to be anomalous, got %+v", anomalies)
+	}
+	if anomalies[0].recentRate != 0.75 || anomalies[0].baselineRate != 0 {
+		t.Errorf("Unexpected rates: %+v", anomalies[0])
+	}
+
+	if !watchdog.shouldAlert("failures:webhook/broken", now) {
+		t.Error("Expected first alert to be sent")
+	}
+	if watchdog.shouldAlert("failures:webhook/broken", now.Add(time.Minute)) {
+		t.Error("Expected repeated alert to be suppressed during cooldown")
+	}
+	if !watchdog.shouldAlert("failures:webhook/broken", now.Add(20*time.Minute)) {
+		t.Error("Expected alert after cooldown")
+	}
+}
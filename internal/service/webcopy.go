@@ -0,0 +1,87 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/preview"
+)
+
+// webCopy links HTML emails to a hosted copy served at a signed URL
+type webCopy struct {
+	signer   *preview.Signer
+	baseURL  string
+	linkText string
+}
+
+// WithWebCopyConfig injects a "View in browser" link into HTML emails, pointing at a copy of
+// the message served under the preview base URL. The link works for as long as the
+// notification is retained.
+func (s *NotificationService) WithWebCopyConfig(cfg config.PreviewConfig) error {
+	if !cfg.Enabled || !cfg.WebCopy.Enabled {
+		s.webCopy = nil
+		return nil
+	}
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("web copy links require a preview base_url")
+	}
+
+	linkText := cfg.WebCopy.LinkText
+	if linkText == "" {
+		linkText = "View in browser"
+	}
+	s.webCopy = &webCopy{
+		signer:   preview.NewSigner([]byte(cfg.Secret)),
+		baseURL:  strings.TrimSuffix(cfg.BaseURL, "/"),
+		linkText: linkText,
+	}
+	return nil
+}
+
+// addWebCopyLinks injects the web copy link into each HTML email and records its URL
+func (s *NotificationService) addWebCopyLinks(notifications ...*domain.Notification) {
+	if s.webCopy == nil {
+		return
+	}
+
+	for _, notification := range notifications {
+		if notification.Type != domain.TypeEmail && notification.Type != domain.TypePostmark {
+			continue
+		}
+		if notification.ID == "" || notification.WebCopyURL != "" {
+			continue
+		}
+
+		body := &notification.HTMLBody
+		if *body == "" {
+			if notification.ContentType != domain.ContentTypeHTML {
+				continue
+			}
+			body = &notification.Body
+		}
+		if preview.HasWebCopyLink(*body) {
+			continue
+		}
+
+		// The copy lives as long as the notification does
+		url := s.webCopy.baseURL + "/view/" + s.webCopy.signer.Sign(preview.PurposeWebCopy, notification.ID, s.linkExpiry(notification))
+		*body = preview.InjectWebCopyLink(*body, url, s.webCopy.linkText)
+		notification.WebCopyURL = url
+	}
+}
+
+// linkExpiry returns when signed links to a notification should stop working: when retention
+// removes it, or never if retention is disabled
+func (s *NotificationService) linkExpiry(notification *domain.Notification) time.Time {
+	if !s.retentionConfig.Enabled || s.ttlDuration <= 0 {
+		return time.Time{}
+	}
+	createdAt := notification.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	return createdAt.Add(s.ttlDuration)
+}
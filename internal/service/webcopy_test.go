@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestAddWebCopyLinks tests which notifications get a web copy link and where it goes
+func TestAddWebCopyLinks(t *testing.T) {
+	svc := createTestService(t)
+	if err := svc.WithRetentionConfig(config.NotificationRetentionConfig{Enabled: true, TTL: "24h", CheckFrequency: "1h"}); err != nil {
+		t.Fatalf("WithRetentionConfig() error = %v", err)
+	}
+	err := svc.WithWebCopyConfig(config.PreviewConfig{
+		Enabled: true,
+		Secret:  "0123456789abcdef0123456789abcdef",
+		BaseURL: "https://notifier.example.com/",
+		WebCopy: config.WebCopyConfig{Enabled: true},
+	})
+	if err != nil {
+		t.Fatalf("WithWebCopyConfig() error = %v", err)
+	}
+
+	createdAt := time.Now()
+	html := &domain.Notification{ID: "n1", Type: domain.TypeEmail, HTMLBody: `<html><BODY class="x"><p>Hi</p></body></html>`, CreatedAt: createdAt}
+	bare := &domain.Notification{ID: "n2", Type: domain.TypePostmark, Body: "<p>Hi</p>", ContentType: domain.ContentTypeHTML}
+	text := &domain.Notification{ID: "n3", Type: domain.TypeEmail, Body: "Hi"}
+	slack := &domain.Notification{ID: "n4", Type: domain.TypeSlack, HTMLBody: "<p>Hi</p>"}
+	svc.addWebCopyLinks(html, bare, text, slack)
+
+	if !strings.HasPrefix(html.WebCopyURL, "https://notifier.example.com/view/") {
+		t.Fatalf("Unexpected web copy URL: %q", html.WebCopyURL)
+	}
+	if !strings.HasPrefix(html.HTMLBody, `<html><BODY class="x"><!--notifier:web-copy-->`) || !strings.Contains(html.HTMLBody, ">View in browser</a>") {
+		t.Errorf("Expected the link after the body tag:\n%s", html.HTMLBody)
+	}
+	if _, expiresAt, err := svc.webCopy.signer.Verify("view", strings.TrimPrefix(html.WebCopyURL, "https://notifier.example.com/view/")); err != nil || !expiresAt.Equal(createdAt.Add(24*time.Hour).Truncate(time.Second)) {
+		t.Errorf("Expected the link to expire with the notification, got %v, %v", expiresAt, err)
+	}
+	if bare.WebCopyURL == "" || !strings.HasPrefix(bare.Body, "<!--notifier:web-copy-->") {
+		t.Errorf("Expected the link prepended to an HTML body:\n%s", bare.Body)
+	}
+	if text.WebCopyURL != "" || slack.WebCopyURL != "" {
+		t.Error("Expected only HTML email to be linked")
+	}
+
+	// A resent notification keeps its one link
+	body := html.HTMLBody
+	html.WebCopyURL = ""
+	svc.addWebCopyLinks(html)
+	if html.HTMLBody != body {
+		t.Errorf("Expected the link to be injected once:\n%s", html.HTMLBody)
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// Outcomes of a simulated notification, from least to most severe
+const (
+	outcomeDeliver = "deliver"
+	outcomeWarn    = "warn"
+	outcomeHold    = "hold"
+	outcomeBlock   = "block"
+)
+
+// maxSimulationChanges caps the changed notifications listed in a simulation report
+const maxSimulationChanges = 100
+
+// policySimulator applies one set of policies to replayed notifications. Budgets are counted
+// from zero over the replay, so each simulator must only be used for a single run.
+type policySimulator struct {
+	content          *contentPolicy
+	budgets          *budgetTracker
+	hedgeRules       map[string]hedgeRule
+	hedgeMinPriority domain.Priority
+	defaultAccount   func(domain.NotificationType) string
+}
+
+// newPolicySimulator compiles a policy set for simulation
+func (s *NotificationService) newPolicySimulator(policies config.PolicySet) (*policySimulator, error) {
+	sim := &policySimulator{
+		defaultAccount: func(domain.NotificationType) string { return "" },
+	}
+	if s.accountResolver != nil {
+		sim.defaultAccount = s.accountResolver.GetDefaultAccount
+	}
+
+	if policies.ContentPolicy.Enabled {
+		content, err := newContentPolicy(policies.ContentPolicy)
+		if err != nil {
+			return nil, err
+		}
+		sim.content = content
+	}
+	if policies.Budgets.Enabled {
+		sim.budgets = newBudgetTracker(policies.Budgets)
+	}
+
+	rules, minPriority, err := parseHedgeRules(policies.Hedging)
+	if err != nil {
+		return nil, err
+	}
+	sim.hedgeRules = rules
+	sim.hedgeMinPriority = minPriority
+
+	return sim, nil
+}
+
+// evaluate decides what the policies would do with a notification, following the order
+// Send applies them: content policy, then budgets, then hedging at delivery. Spam scoring
+// isn't simulated since it needs the external engine.
+func (p *policySimulator) evaluate(notification *domain.Notification) domain.PolicyOutcome {
+	outcome := domain.PolicyOutcome{Action: outcomeDeliver}
+
+	if p.content != nil {
+		violations := p.content.check(notification)
+		seen := make(map[string]bool)
+		for _, violation := range violations {
+			if !seen[violation.Rule] {
+				seen[violation.Rule] = true
+				outcome.Rules = append(outcome.Rules, violation.Rule)
+			}
+		}
+		switch strongestAction(violations) {
+		case config.ContentActionBlock:
+			outcome.Action = outcomeBlock
+			return outcome
+		case config.ContentActionHold:
+			outcome.Action = outcomeHold
+		case config.ContentActionWarn:
+			outcome.Action = outcomeWarn
+		}
+	}
+
+	if p.budgets != nil {
+		if _, err := p.budgets.admit([]*domain.Notification{notification}, notification.CreatedAt); err != nil {
+			outcome.Action = outcomeBlock
+			outcome.Rules = append(outcome.Rules, p.exceededBudgets(notification, true)...)
+			return outcome
+		}
+		if exceeded := p.exceededBudgets(notification, false); len(exceeded) > 0 {
+			outcome.Rules = append(outcome.Rules, exceeded...)
+			if outcome.Action == outcomeDeliver {
+				outcome.Action = outcomeWarn
+			}
+		}
+	}
+
+	if outcome.Action == outcomeHold {
+		return outcome
+	}
+	account := notification.Account
+	if account == "" {
+		account = p.defaultAccount(notification.Type)
+	}
+	if rule, ok := matchHedgeRule(p.hedgeRules, p.hedgeMinPriority, notification, account); ok {
+		outcome.HedgeTo = rule.secondary
+	}
+
+	return outcome
+}
+
+// exceededBudgets names the budgets a notification is over. With pending set, the notification
+// itself hasn't been counted (it was blocked), so a budget is over if counting it would exceed it.
+func (p *policySimulator) exceededBudgets(notification *domain.Notification, pending bool) []string {
+	var names []string
+	for _, i := range p.budgets.matching(notification) {
+		key := budgetKey{rule: i, origin: notification.Origin.StatsKey()}
+		rule := p.budgets.rules[i]
+		used := p.budgets.used[key]
+		if pending {
+			used++
+		}
+		if used > rule.limit {
+			name := "budget:" + rule.origin
+			if rule.notifType != "" {
+				name += "/" + string(rule.notifType)
+			}
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SimulatePolicies replays the notifications created within window through the active policies
+// and through a proposed change, reporting the notifications whose outcome would differ.
+// Nothing is sent and the active policies are left untouched.
+func (s *NotificationService) SimulatePolicies(ctx context.Context, proposal []byte, window time.Duration) (*domain.PolicySimulation, error) {
+	if window <= 0 {
+		return nil, fmt.Errorf("simulation window must be positive")
+	}
+
+	current := config.PolicySet{
+		ContentPolicy: s.contentPolicyConfig,
+		Budgets:       s.budgetConfig,
+		Hedging:       s.hedgingConfig,
+	}
+	proposed, err := config.ParsePolicyProposal(proposal, current)
+	if err != nil {
+		return nil, err
+	}
+
+	currentSim, err := s.newPolicySimulator(current)
+	if err != nil {
+		return nil, fmt.Errorf("invalid active policies: %w", err)
+	}
+	proposedSim, err := s.newPolicySimulator(proposed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy proposal: %w", err)
+	}
+
+	to := time.Now()
+	from := to.Add(-window)
+
+	// Copy the notifications so replay doesn't race with delivery updating them
+	stored, err := s.store.List(ctx, &domain.NotificationFilter{CreatedAfter: &from})
+	if err != nil {
+		return nil, err
+	}
+	var notifications []domain.Notification
+	for _, notification := range stored {
+		if isOperationalAlert(notification) {
+			continue
+		}
+		notifications = append(notifications, *notification)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		if !notifications[i].CreatedAt.Equal(notifications[j].CreatedAt) {
+			return notifications[i].CreatedAt.Before(notifications[j].CreatedAt)
+		}
+		return notifications[i].ID < notifications[j].ID
+	})
+
+	report := &domain.PolicySimulation{
+		From:        from,
+		To:          to,
+		Current:     make(map[string]int),
+		Proposed:    make(map[string]int),
+		Transitions: make(map[string]int),
+	}
+	for i := range notifications {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		notification := &notifications[i]
+		before := currentSim.evaluate(notification)
+		after := proposedSim.evaluate(notification)
+
+		report.Evaluated++
+		report.Current[before.Action]++
+		report.Proposed[after.Action]++
+		if sameOutcome(before, after) {
+			continue
+		}
+
+		report.Changed++
+		if before.Action != after.Action {
+			report.Transitions[before.Action+" -> "+after.Action]++
+		}
+		if len(report.Changes) == maxSimulationChanges {
+			report.Truncated = true
+			continue
+		}
+		report.Changes = append(report.Changes, domain.PolicySimulationChange{
+			NotificationID: notification.ID,
+			Type:           notification.Type,
+			Account:        notification.Account,
+			Origin:         notification.Origin,
+			CreatedAt:      notification.CreatedAt,
+			Current:        before,
+			Proposed:       after,
+		})
+	}
+
+	s.logger.Infof("Policy simulation complete - window=%s, evaluated=%d, changed=%d",
+		window, report.Evaluated, report.Changed)
+
+	return report, nil
+}
+
+// sameOutcome reports whether two outcomes are identical
+func sameOutcome(a, b domain.PolicyOutcome) bool {
+	if a.Action != b.Action || a.HedgeTo != b.HedgeTo || len(a.Rules) != len(b.Rules) {
+		return false
+	}
+	for i := range a.Rules {
+		if a.Rules[i] != b.Rules[i] {
+			return false
+		}
+	}
+	return true
+}
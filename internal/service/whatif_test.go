@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/config"
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestSimulatePolicies tests replaying stored notifications through a proposed policy change
+func TestSimulatePolicies(t *testing.T) {
+	svc := createTestService(t)
+	if err := svc.WithContentPolicyConfig(config.ContentPolicyConfig{
+		Enabled: true,
+		Action:  config.ContentActionWarn,
+		Rules:   []config.ContentRuleConfig{{Detector: config.ContentDetectorPII}},
+	}); err != nil {
+		t.Fatalf("WithContentPolicyConfig() error = %v", err)
+	}
+
+	// Keep the notifications budgets count within one UTC day, however close to midnight the
+	// test runs
+	now := time.Now()
+	if sinceMidnight := now.Sub(now.UTC().Truncate(24 * time.Hour)); sinceMidnight < 5*time.Minute {
+		now = now.Add(-sinceMidnight - time.Minute)
+	}
+	store := func(id, body string, age time.Duration, metadata map[string]interface{}) {
+		svc.storeNotification(&domain.Notification{
+			ID:         id,
+			Type:       domain.TypeStdout,
+			Body:       body,
+			Recipients: []string{"console"},
+			Origin:     domain.Origin{System: "billing"},
+			Metadata:   metadata,
+			Status:     domain.StatusSent,
+			CreatedAt:  now.Add(-age),
+		})
+	}
+	store("n1", "Invoice ready", 4*time.Minute, nil)
+	store("n2", "SSN 123-45-6789 on file", 3*time.Minute, nil)
+	store("n3", "Invoice ready", 2*time.Minute, nil)
+	store("n4", "Invoice ready", time.Minute, nil)
+	store("old", "Invoice ready", 48*time.Hour, nil)
+	store("alert", "Budget exceeded", time.Minute, map[string]interface{}{"source": operationalAlertSource})
+
+	proposal := `
+content_policy:
+  enabled: true
+  action: hold
+  alert:
+    type: stdout
+    recipients: [console]
+  rules:
+    - detector: pii
+budgets:
+  enabled: true
+  action: block
+  rules:
+    - origin: billing
+      limit: 2
+`
+	report, err := svc.SimulatePolicies(context.Background(), []byte(proposal), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SimulatePolicies() error = %v", err)
+	}
+
+	if report.Evaluated != 4 || report.Changed != 3 {
+		t.Errorf("Evaluated %d, changed %d; want 4 and 3", report.Evaluated, report.Changed)
+	}
+	if report.Current["deliver"] != 3 || report.Current["warn"] != 1 {
+		t.Errorf("Unexpected current outcomes: %v", report.Current)
+	}
+	if report.Proposed["deliver"] != 1 || report.Proposed["hold"] != 1 || report.Proposed["block"] != 2 {
+		t.Errorf("Unexpected proposed outcomes: %v", report.Proposed)
+	}
+	if report.Transitions["warn -> hold"] != 1 || report.Transitions["deliver -> block"] != 2 {
+		t.Errorf("Unexpected transitions: %v", report.Transitions)
+	}
+	if len(report.Changes) != 3 || report.Changes[0].NotificationID != "n2" || report.Changes[2].Proposed.Rules[0] != "budget:billing" {
+		t.Errorf("Unexpected changes: %+v", report.Changes)
+	}
+
+	// The active policies are untouched
+	if svc.budgets != nil || svc.contentPolicyConfig.Action != config.ContentActionWarn {
+		t.Error("Simulation changed the active policies")
+	}
+
+	if _, err := svc.SimulatePolicies(context.Background(), []byte("server:\n  port: 8080\n"), time.Hour); err == nil {
+		t.Error("Expected error for a proposal without policy sections")
+	}
+}
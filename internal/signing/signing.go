@@ -0,0 +1,342 @@
+// Package signing signs outgoing HTTP payloads with HMAC-SHA256 so receivers can verify
+// the notifier sent them. Keys are identified by ID so they can be rotated: the active key
+// signs, and retired keys are kept until receivers have switched over. Only receivers that
+// check the signature benefit, such as reply callbacks and self-hosted ntfy behind a
+// verifying proxy; hosted webhooks like Slack's and Discord's ignore it.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Signature headers set on signed requests
+const (
+	HeaderSignature = "X-Notifier-Signature" // sha256=<hex HMAC of "<timestamp>.<body>">
+	HeaderKeyID     = "X-Notifier-Key-Id"    // ID of the key used to sign
+	HeaderTimestamp = "X-Notifier-Timestamp" // Unix seconds when the request was signed
+)
+
+// Config contains payload signing configuration
+type Config struct {
+	Enabled   bool        `mapstructure:"enabled"`    // Enable signing for reply callbacks and ntfy accounts with sign_payloads set
+	ActiveKey string      `mapstructure:"active_key"` // ID of the key used to sign (default: last key)
+	Keys      []KeyConfig `mapstructure:"keys"`
+
+	// PersistPath is the file keys added through the API, and the active key chosen through
+	// it, are saved to so they survive restarts ("" keeps them in memory)
+	PersistPath string `mapstructure:"persist_path"`
+}
+
+// KeyConfig is a single signing key
+type KeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret"`
+}
+
+// KeyInfo describes a key without its secret
+type KeyInfo struct {
+	ID         string    `json:"id"`
+	Active     bool      `json:"active"`
+	Configured bool      `json:"configured"` // Set in the config file rather than added through the API
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// key is a signing key held by the keyring
+type key struct {
+	secret     []byte
+	createdAt  time.Time
+	configured bool
+}
+
+// Keyring holds the signing keys and which one is active
+type Keyring struct {
+	mu     sync.RWMutex
+	keys   map[string]*key
+	active string
+	now    func() time.Time
+
+	path         string // File keys added through the API are saved to ("" keeps them in memory)
+	configActive string // Active key the config selects
+}
+
+// savedKeyring is the file format for keys added through the API
+type savedKeyring struct {
+	Keys   []savedKey `json:"keys"`
+	Active string     `json:"active"`
+
+	// ConfigActive is the config's active key when the file was saved. If the config
+	// selects a different key now, it was edited since and its choice wins.
+	ConfigActive string `json:"config_active"`
+}
+
+// savedKey is a key added through the API
+type savedKey struct {
+	ID        string    `json:"id"`
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewKeyring creates a keyring from configuration, restoring keys saved to its persist path.
+// A saved key with the same ID as a configured one is ignored.
+func NewKeyring(cfg Config) (*Keyring, error) {
+	k := &Keyring{
+		keys: make(map[string]*key),
+		now:  time.Now,
+	}
+
+	for _, kc := range cfg.Keys {
+		if err := k.addLocked(kc.ID, kc.Secret, k.now(), true); err != nil {
+			return nil, err
+		}
+	}
+
+	active := cfg.ActiveKey
+	if active == "" && len(cfg.Keys) > 0 {
+		active = cfg.Keys[len(cfg.Keys)-1].ID
+	}
+	if active != "" {
+		if _, ok := k.keys[active]; !ok {
+			return nil, fmt.Errorf("signing key not found: %s", active)
+		}
+	}
+	k.active = active
+	k.configActive = active
+
+	if cfg.PersistPath != "" {
+		k.path = cfg.PersistPath
+		if err := k.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// load restores the keys saved to the persist path, if there are any
+func (k *Keyring) load() error {
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing added yet
+		}
+		return fmt.Errorf("failed to read signing keys: %w", err)
+	}
+
+	var saved savedKeyring
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to unmarshal signing keys: %w", err)
+	}
+
+	for _, sk := range saved.Keys {
+		if _, configured := k.keys[sk.ID]; configured {
+			continue
+		}
+		if err := k.addLocked(sk.ID, sk.Secret, sk.CreatedAt, false); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := k.keys[saved.Active]; ok && saved.ConfigActive == k.configActive {
+		k.active = saved.Active
+	}
+	return nil
+}
+
+// saveLocked writes the keys added through the API and the active key to the persist path,
+// if there is one. Must be called with mu held.
+func (k *Keyring) saveLocked() error {
+	if k.path == "" {
+		return nil
+	}
+
+	saved := savedKeyring{Active: k.active, ConfigActive: k.configActive}
+	for id, key := range k.keys {
+		if !key.configured {
+			saved.Keys = append(saved.Keys, savedKey{ID: id, Secret: string(key.secret), CreatedAt: key.createdAt})
+		}
+	}
+	sort.Slice(saved.Keys, func(i, j int) bool { return saved.Keys[i].ID < saved.Keys[j].ID })
+
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("failed to save signing keys: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old one, so a crash never leaves a
+	// partially written file. The file holds secrets, so only the owner can read it.
+	tmpPath := k.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to save signing keys: %w", err)
+	}
+	if err := os.Rename(tmpPath, k.path); err != nil {
+		return fmt.Errorf("failed to save signing keys: %w", err)
+	}
+	return nil
+}
+
+// addLocked adds a key. Must be called with mu held.
+func (k *Keyring) addLocked(id, secret string, createdAt time.Time, configured bool) error {
+	if id == "" || secret == "" {
+		return fmt.Errorf("signing key requires an id and secret")
+	}
+	if _, exists := k.keys[id]; exists {
+		return fmt.Errorf("signing key already exists: %s", id)
+	}
+	k.keys[id] = &key{secret: []byte(secret), createdAt: createdAt, configured: configured}
+	return nil
+}
+
+// AddKey adds a key to the keyring without activating it
+func (k *Keyring) AddKey(id, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.addLocked(id, secret, k.now(), false); err != nil {
+		return err
+	}
+	if err := k.saveLocked(); err != nil {
+		delete(k.keys, id)
+		return err
+	}
+	return nil
+}
+
+// GenerateKey adds a key with a random secret and returns the secret
+func (k *Keyring) GenerateKey(id string) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	secret := hex.EncodeToString(buf)
+
+	if err := k.AddKey(id, secret); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Activate makes a key the one used for signing
+func (k *Keyring) Activate(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.keys[id]; !ok {
+		return fmt.Errorf("signing key not found: %s", id)
+	}
+
+	previous := k.active
+	k.active = id
+	if err := k.saveLocked(); err != nil {
+		k.active = previous
+		return err
+	}
+	return nil
+}
+
+// RemoveKey retires a key. The active key and keys set in the config can't be removed.
+func (k *Keyring) RemoveKey(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	removed, ok := k.keys[id]
+	if !ok {
+		return fmt.Errorf("signing key not found: %s", id)
+	}
+	if id == k.active {
+		return fmt.Errorf("cannot remove the active signing key: %s", id)
+	}
+	if removed.configured {
+		return fmt.Errorf("signing key %s is set in the config file; remove it there", id)
+	}
+
+	delete(k.keys, id)
+	if err := k.saveLocked(); err != nil {
+		k.keys[id] = removed
+		return err
+	}
+	return nil
+}
+
+// Keys lists the keys (without secrets), sorted by ID
+func (k *Keyring) Keys() []KeyInfo {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	infos := make([]KeyInfo, 0, len(k.keys))
+	for id, key := range k.keys {
+		infos = append(infos, KeyInfo{ID: id, Active: id == k.active, Configured: key.configured, CreatedAt: key.createdAt})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos
+}
+
+// SignRequest signs a request body with the active key and sets the signature headers
+func (k *Keyring) SignRequest(req *http.Request, body []byte) error {
+	k.mu.RLock()
+	id := k.active
+	active := k.keys[id]
+	k.mu.RUnlock()
+
+	if active == nil {
+		return fmt.Errorf("no active signing key")
+	}
+
+	timestamp := strconv.FormatInt(k.now().Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderKeyID, id)
+	req.Header.Set(HeaderSignature, "sha256="+computeSignature(active.secret, timestamp, body))
+	return nil
+}
+
+// Verify checks a signature produced by SignRequest. Any key still in the keyring is
+// accepted, so receivers keep working while a rotation is in progress. Signatures older
+// than maxAge are rejected to limit replays.
+func (k *Keyring) Verify(header http.Header, body []byte, maxAge time.Duration) error {
+	id := header.Get(HeaderKeyID)
+	timestamp := header.Get(HeaderTimestamp)
+	signature := header.Get(HeaderSignature)
+	if id == "" || timestamp == "" || signature == "" {
+		return fmt.Errorf("missing signature headers")
+	}
+
+	k.mu.RLock()
+	signingKey := k.keys[id]
+	k.mu.RUnlock()
+	if signingKey == nil {
+		return fmt.Errorf("unknown signing key: %s", id)
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp: %w", err)
+	}
+	if age := k.now().Sub(time.Unix(unix, 0)); age > maxAge || age < -maxAge {
+		return fmt.Errorf("signature timestamp outside allowed window")
+	}
+
+	expected := "sha256=" + computeSignature(signingKey.secret, timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+// computeSignature returns the hex HMAC-SHA256 of "<timestamp>.<body>"
+func computeSignature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,163 @@
+package signing
+
+import (
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignAndVerify tests that signed requests verify and tampered ones don't
+func TestSignAndVerify(t *testing.T) {
+	keyring, err := NewKeyring(Config{Keys: []KeyConfig{{ID: "k1", Secret: "secret-one"}}})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+
+	body := []byte(`{"topic":"alerts","message":"hello"}`)
+	req, _ := http.NewRequest("POST", "http://example.com", nil)
+	if err := keyring.SignRequest(req, body); err != nil {
+		t.Fatalf("SignRequest() error = %v", err)
+	}
+
+	if req.Header.Get(HeaderKeyID) != "k1" {
+		t.Errorf("%s = %q, want k1", HeaderKeyID, req.Header.Get(HeaderKeyID))
+	}
+	if !strings.HasPrefix(req.Header.Get(HeaderSignature), "sha256=") {
+		t.Errorf("Unexpected signature format: %s", req.Header.Get(HeaderSignature))
+	}
+
+	if err := keyring.Verify(req.Header, body, time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := keyring.Verify(req.Header, []byte(`{"topic":"alerts","message":"tampered"}`), time.Minute); err == nil {
+		t.Error("Expected tampered body to fail verification")
+	}
+
+	// Replayed signatures are rejected once they're older than maxAge
+	keyring.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	if err := keyring.Verify(req.Header, body, time.Minute); err == nil {
+		t.Error("Expected stale signature to fail verification")
+	}
+}
+
+// TestKeyRotation tests that rotation switches the signing key while old signatures still verify
+func TestKeyRotation(t *testing.T) {
+	keyring, err := NewKeyring(Config{})
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	if err := keyring.AddKey("k1", "secret-one"); err != nil {
+		t.Fatalf("AddKey() error = %v", err)
+	}
+	if err := keyring.Activate("k1"); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+
+	body := []byte("payload")
+	oldReq, _ := http.NewRequest("POST", "http://example.com", nil)
+	keyring.SignRequest(oldReq, body)
+
+	if _, err := keyring.GenerateKey("k2"); err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if err := keyring.Activate("k2"); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+
+	newReq, _ := http.NewRequest("POST", "http://example.com", nil)
+	keyring.SignRequest(newReq, body)
+	if newReq.Header.Get(HeaderKeyID) != "k2" {
+		t.Errorf("Expected new requests to be signed with k2, got %s", newReq.Header.Get(HeaderKeyID))
+	}
+
+	// Both keys verify during the rotation
+	if err := keyring.Verify(oldReq.Header, body, time.Minute); err != nil {
+		t.Errorf("Verify(old) error = %v", err)
+	}
+
+	if err := keyring.RemoveKey("k2"); err == nil {
+		t.Error("Expected removing the active key to fail")
+	}
+	if err := keyring.RemoveKey("k1"); err != nil {
+		t.Fatalf("RemoveKey() error = %v", err)
+	}
+	if err := keyring.Verify(oldReq.Header, body, time.Minute); err == nil {
+		t.Error("Expected signatures from a retired key to fail verification")
+	}
+
+	keys := keyring.Keys()
+	if len(keys) != 1 || keys[0].ID != "k2" || !keys[0].Active {
+		t.Errorf("Keys() = %+v, want only active k2", keys)
+	}
+}
+
+// TestNewKeyringValidation tests invalid key configurations are rejected
+func TestNewKeyringValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"missing secret", Config{Keys: []KeyConfig{{ID: "k1"}}}},
+		{"duplicate id", Config{Keys: []KeyConfig{{ID: "k1", Secret: "a"}, {ID: "k1", Secret: "b"}}}},
+		{"unknown active key", Config{ActiveKey: "k9", Keys: []KeyConfig{{ID: "k1", Secret: "a"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.cfg); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+// TestKeyringPersistence tests that keys added and activated at runtime survive a restart,
+// and that the config file wins when its active key has been changed since
+func TestKeyringPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing-keys.json")
+	cfg := Config{Keys: []KeyConfig{{ID: "k1", Secret: "secret-one"}}, PersistPath: path}
+
+	keyring, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	if _, err := keyring.GenerateKey("k2"); err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if err := keyring.Activate("k2"); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	if err := keyring.RemoveKey("k1"); err == nil {
+		t.Error("Expected removing a key set in the config file to fail")
+	}
+
+	body := []byte("payload")
+	req, _ := http.NewRequest("POST", "http://example.com", nil)
+	keyring.SignRequest(req, body)
+
+	restarted, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring() after restart error = %v", err)
+	}
+	keys := restarted.Keys()
+	if len(keys) != 2 || keys[1].ID != "k2" || !keys[1].Active || keys[1].Configured || !keys[0].Configured {
+		t.Errorf("Keys() after restart = %+v, want configured k1 and active runtime k2", keys)
+	}
+	if err := restarted.Verify(req.Header, body, time.Minute); err != nil {
+		t.Errorf("Verify() with restored key error = %v", err)
+	}
+
+	// Picking a different active key in the config overrides the saved choice
+	cfg.Keys = append(cfg.Keys, KeyConfig{ID: "k3", Secret: "secret-three"})
+	edited, err := NewKeyring(cfg)
+	if err != nil {
+		t.Fatalf("NewKeyring() with edited config error = %v", err)
+	}
+	for _, key := range edited.Keys() {
+		if key.Active != (key.ID == "k3") {
+			t.Errorf("Key %s active = %v, want only k3 active", key.ID, key.Active)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package spamcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// rspamdChecker scores messages with rspamd's /checkv2 endpoint
+type rspamdChecker struct {
+	url        string
+	password   string
+	httpClient *http.Client
+}
+
+// rspamdResponse is the subset of the /checkv2 response we use
+type rspamdResponse struct {
+	Score         float64                    `json:"score"`
+	RequiredScore float64                    `json:"required_score"`
+	Symbols       map[string]json.RawMessage `json:"symbols"`
+}
+
+// newRspamdChecker creates an rspamd checker
+func newRspamdChecker(opts Options) *rspamdChecker {
+	return &rspamdChecker{
+		url:        strings.TrimSuffix(opts.Address, "/") + "/checkv2",
+		password:   opts.Password,
+		httpClient: &http.Client{Timeout: opts.Timeout},
+	}
+}
+
+// Check submits the message to rspamd and returns its score
+func (c *rspamdChecker) Check(ctx context.Context, message []byte) (*Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "message/rfc822")
+	if c.password != "" {
+		req.Header.Set("Password", c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rspamd request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("rspamd returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result rspamdResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode rspamd response: %w", err)
+	}
+
+	symbols := make([]string, 0, len(result.Symbols))
+	for name := range result.Symbols {
+		symbols = append(symbols, name)
+	}
+	sort.Strings(symbols)
+
+	return &Result{Score: result.Score, Threshold: result.RequiredScore, Symbols: symbols}, nil
+}
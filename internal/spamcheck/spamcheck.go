@@ -0,0 +1,55 @@
+// Package spamcheck scores rendered email messages with a spam filter before they are sent,
+// using rspamd's HTTP protocol or SpamAssassin's spamd protocol.
+package spamcheck
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Supported spam filter engines
+const (
+	EngineRspamd       = "rspamd"
+	EngineSpamAssassin = "spamassassin"
+)
+
+// Options configures a spam filter client
+type Options struct {
+	Engine   string        // rspamd or spamassassin
+	Address  string        // rspamd base URL (e.g., http://rspamd:11333) or spamd host:port
+	Password string        // rspamd controller password (optional)
+	User     string        // spamd user whose preferences apply (optional)
+	Timeout  time.Duration // Per-check timeout (default 10s)
+}
+
+// Result is a spam filter's verdict on a message
+type Result struct {
+	Score     float64  // Spam score
+	Threshold float64  // Score at which the engine itself considers the message spam
+	Symbols   []string // Rules that contributed to the score
+}
+
+// Checker scores raw RFC 5322 messages
+type Checker interface {
+	Check(ctx context.Context, message []byte) (*Result, error)
+}
+
+// New creates a checker for the configured engine
+func New(opts Options) (Checker, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("spam check address is required")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	switch opts.Engine {
+	case EngineRspamd:
+		return newRspamdChecker(opts), nil
+	case EngineSpamAssassin:
+		return newSpamdChecker(opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported spam check engine: %s (must be rspamd or spamassassin)", opts.Engine)
+	}
+}
@@ -0,0 +1,95 @@
+package spamcheck
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testMessage = "From: news@example.com\r\nSubject: Sale\r\n\r\nBuy now!\r\n"
+
+// TestRspamdCheck tests scoring with rspamd's /checkv2 endpoint
+func TestRspamdCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/checkv2" || r.Header.Get("Password") != "secret" || string(body) != testMessage {
+			t.Errorf("Unexpected request: %s %v %q", r.URL.Path, r.Header, body)
+		}
+		w.Write([]byte(`{"action":"add header","score":7.5,"required_score":15,"symbols":{"MISSING_DATE":{"score":1},"BAYES_SPAM":{"score":5.1}}}`))
+	}))
+	defer server.Close()
+
+	checker, err := New(Options{Engine: EngineRspamd, Address: server.URL + "/", Password: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := checker.Check(context.Background(), []byte(testMessage))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if result.Score != 7.5 || result.Threshold != 15 || strings.Join(result.Symbols, ",") != "BAYES_SPAM,MISSING_DATE" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+// TestSpamdCheck tests scoring with a SpamAssassin spamd server
+func TestSpamdCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewReader(bufio.NewReader(conn))
+		line, _ := tp.ReadLine()
+		headers, _ := tp.ReadMIMEHeader()
+		length, _ := strconv.Atoi(headers.Get("Content-Length"))
+		body := make([]byte, length)
+		io.ReadFull(tp.R, body)
+		if line != "SYMBOLS SPAMC/1.5" || headers.Get("User") != "mailer" || string(body) != testMessage {
+			t.Errorf("Unexpected request: %q %v %q", line, headers, body)
+		}
+		io.WriteString(conn, "SPAMD/1.1 0 EX_OK\r\nContent-length: 23\r\nSpam: True ; 6.2 / 5.0\r\n\r\nHTML_MESSAGE,URIBL_BLACK\r\n")
+	}()
+
+	checker, err := New(Options{Engine: EngineSpamAssassin, Address: listener.Addr().String(), User: "mailer", Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	result, err := checker.Check(context.Background(), []byte(testMessage))
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if result.Score != 6.2 || result.Threshold != 5 || strings.Join(result.Symbols, ",") != "HTML_MESSAGE,URIBL_BLACK" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+// TestParseSpamdResponseErrors tests that spamd errors and malformed responses are reported
+func TestParseSpamdResponseErrors(t *testing.T) {
+	for _, response := range []string{
+		"SPAMD/1.1 76 Bad header line\r\n\r\n",
+		"HTTP/1.1 200 OK\r\n\r\n",
+		"SPAMD/1.1 0 EX_OK\r\nSpam: True\r\n\r\n",
+		"SPAMD/1.1 0 EX_OK\r\nSpam: True ; high / 5.0\r\n\r\n",
+	} {
+		if _, err := parseSpamdResponse(bufio.NewReader(strings.NewReader(response))); err == nil {
+			t.Errorf("Expected error for %q", response)
+		}
+	}
+}
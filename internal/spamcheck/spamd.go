@@ -0,0 +1,111 @@
+package spamcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spamdChecker scores messages with SpamAssassin's spamd over the SPAMC protocol
+type spamdChecker struct {
+	address string
+	user    string
+	timeout time.Duration
+}
+
+// newSpamdChecker creates a spamd checker
+func newSpamdChecker(opts Options) *spamdChecker {
+	return &spamdChecker{
+		address: opts.Address,
+		user:    opts.User,
+		timeout: opts.Timeout,
+	}
+}
+
+// Check sends a SYMBOLS request to spamd and returns its score
+func (c *spamdChecker) Check(ctx context.Context, message []byte) (*Result, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to spamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	var request strings.Builder
+	request.WriteString("SYMBOLS SPAMC/1.5\r\n")
+	fmt.Fprintf(&request, "Content-length: %d\r\n", len(message))
+	if c.user != "" {
+		fmt.Fprintf(&request, "User: %s\r\n", c.user)
+	}
+	request.WriteString("\r\n")
+	if _, err := io.WriteString(conn, request.String()); err != nil {
+		return nil, fmt.Errorf("failed to write spamd request: %w", err)
+	}
+	if _, err := conn.Write(message); err != nil {
+		return nil, fmt.Errorf("failed to write spamd request: %w", err)
+	}
+
+	return parseSpamdResponse(bufio.NewReader(conn))
+}
+
+// parseSpamdResponse reads a SPAMD/1.x response: a status line, headers including
+// "Spam: True ; 15.0 / 5.0", a blank line and a comma-separated symbol list
+func parseSpamdResponse(r *bufio.Reader) (*Result, error) {
+	tp := textproto.NewReader(r)
+	status, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spamd response: %w", err)
+	}
+	fields := strings.SplitN(status, " ", 3)
+	if len(fields) < 3 || !strings.HasPrefix(fields[0], "SPAMD/") {
+		return nil, fmt.Errorf("invalid spamd response: %q", status)
+	}
+	if fields[1] != "0" {
+		return nil, fmt.Errorf("spamd returned error %s: %s", fields[1], fields[2])
+	}
+
+	headers, err := tp.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read spamd headers: %w", err)
+	}
+
+	// Spam: <True|False> ; <score> / <threshold>
+	spam := headers.Get("Spam")
+	_, scores, ok := strings.Cut(spam, ";")
+	if !ok {
+		return nil, fmt.Errorf("invalid spamd Spam header: %q", spam)
+	}
+	scoreText, thresholdText, ok := strings.Cut(scores, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid spamd Spam header: %q", spam)
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(scoreText), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spamd score: %w", err)
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(thresholdText), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spamd threshold: %w", err)
+	}
+
+	result := &Result{Score: score, Threshold: threshold}
+	body, _ := io.ReadAll(r)
+	for _, symbol := range strings.Split(strings.TrimSpace(string(body)), ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			result.Symbols = append(result.Symbols, symbol)
+		}
+	}
+	return result, nil
+}
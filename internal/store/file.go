@@ -0,0 +1,244 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// FileStore is a MemoryStore that writes its contents to a JSON file and restores them on
+// start, for a single instance without PostgreSQL, such as an edge device. Notification
+// changes are written at most once per flush interval, so a crash loses at most that much
+// status history; templates, contacts and preferences are written as soon as they change.
+type FileStore struct {
+	*MemoryStore
+	path string
+
+	dirty   atomic.Bool
+	writeMu sync.Mutex // Serializes writes to the file
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// fileSnapshot is the file format of a FileStore
+type fileSnapshot struct {
+	Notifications []*domain.Notification `json:"notifications"`
+	Templates     []domain.Template      `json:"templates"`
+	Contacts      []domain.Contact       `json:"contacts"`
+	Preferences   []domain.Preferences   `json:"preferences"`
+}
+
+// NewFileStore opens a file store at path, creating its directory if needed and loading
+// anything saved there, and writes notification changes every flushInterval
+func NewFileStore(path string, flushInterval time.Duration) (*FileStore, error) {
+	if flushInterval <= 0 {
+		return nil, fmt.Errorf("invalid flush interval: %s (must be positive)", flushInterval)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+
+	f := &FileStore{
+		MemoryStore: NewMemoryStore(),
+		path:        path,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	go f.flushLoop(flushInterval)
+	return f, nil
+}
+
+// load restores the saved contents, if there are any
+func (f *FileStore) load() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Nothing saved yet
+		}
+		return fmt.Errorf("failed to read notification store: %w", err)
+	}
+
+	var snapshot fileSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to unmarshal notification store: %w", err)
+	}
+
+	ctx := context.Background()
+	for _, notification := range snapshot.Notifications {
+		f.MemoryStore.Save(ctx, notification)
+	}
+	for i := range snapshot.Templates {
+		f.MemoryStore.SaveTemplate(ctx, &snapshot.Templates[i])
+	}
+	for i := range snapshot.Contacts {
+		f.MemoryStore.SaveContact(ctx, &snapshot.Contacts[i])
+	}
+	for i := range snapshot.Preferences {
+		f.MemoryStore.SavePreferences(ctx, &snapshot.Preferences[i])
+	}
+	return nil
+}
+
+// flushLoop writes notification changes every interval until the store is closed
+func (f *FileStore) flushLoop(interval time.Duration) {
+	defer close(f.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if f.dirty.Load() {
+				// A failed write leaves the store dirty, so it's retried on the next tick
+				f.write()
+			}
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+// write saves the whole store to its file
+func (f *FileStore) write() error {
+	f.writeMu.Lock()
+	defer f.writeMu.Unlock()
+
+	f.dirty.Store(false)
+
+	f.mu.RLock()
+	snapshot := fileSnapshot{
+		Notifications: make([]*domain.Notification, 0, len(f.notifications)),
+		Templates:     make([]domain.Template, 0, len(f.templates)),
+		Contacts:      make([]domain.Contact, 0, len(f.contacts)),
+		Preferences:   make([]domain.Preferences, 0, len(f.preferences)),
+	}
+	for _, notification := range f.notifications {
+		snapshot.Notifications = append(snapshot.Notifications, notification)
+	}
+	for _, tmpl := range f.templates {
+		snapshot.Templates = append(snapshot.Templates, tmpl)
+	}
+	for _, contact := range f.contacts {
+		snapshot.Contacts = append(snapshot.Contacts, contact)
+	}
+	for _, prefs := range f.preferences {
+		snapshot.Preferences = append(snapshot.Preferences, prefs)
+	}
+	data, err := json.Marshal(snapshot)
+	f.mu.RUnlock()
+	if err != nil {
+		f.dirty.Store(true)
+		return fmt.Errorf("failed to marshal notification store: %w", err)
+	}
+
+	// Write to a temporary file and rename it over the old one, so a crash never leaves a
+	// partially written file
+	tmpPath := f.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		f.dirty.Store(true)
+		return fmt.Errorf("failed to write notification store: %w", err)
+	}
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		f.dirty.Store(true)
+		return fmt.Errorf("failed to write notification store: %w", err)
+	}
+	return nil
+}
+
+// Save stores a notification, replacing any with the same ID
+func (f *FileStore) Save(ctx context.Context, notification *domain.Notification) error {
+	if err := f.MemoryStore.Save(ctx, notification); err != nil {
+		return err
+	}
+	f.dirty.Store(true)
+	return nil
+}
+
+// Update replaces a stored notification
+func (f *FileStore) Update(ctx context.Context, notification *domain.Notification) error {
+	if err := f.MemoryStore.Update(ctx, notification); err != nil {
+		return err
+	}
+	f.dirty.Store(true)
+	return nil
+}
+
+// Delete removes notifications by ID
+func (f *FileStore) Delete(ctx context.Context, ids ...string) error {
+	if err := f.MemoryStore.Delete(ctx, ids...); err != nil {
+		return err
+	}
+	f.dirty.Store(true)
+	return nil
+}
+
+// SaveTemplate stores a template and writes the store
+func (f *FileStore) SaveTemplate(ctx context.Context, tmpl *domain.Template) error {
+	if err := f.MemoryStore.SaveTemplate(ctx, tmpl); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// DeleteTemplate removes a tenant's template and writes the store
+func (f *FileStore) DeleteTemplate(ctx context.Context, tenant, name string) error {
+	if err := f.MemoryStore.DeleteTemplate(ctx, tenant, name); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// SaveContact stores a contact and writes the store
+func (f *FileStore) SaveContact(ctx context.Context, contact *domain.Contact) error {
+	if err := f.MemoryStore.SaveContact(ctx, contact); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// DeleteContact removes a tenant's contact and writes the store
+func (f *FileStore) DeleteContact(ctx context.Context, tenant, name string) error {
+	if err := f.MemoryStore.DeleteContact(ctx, tenant, name); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// SavePreferences stores a contact's preferences and writes the store
+func (f *FileStore) SavePreferences(ctx context.Context, prefs *domain.Preferences) error {
+	if err := f.MemoryStore.SavePreferences(ctx, prefs); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// DeletePreferences removes the preferences a tenant set for a contact and writes the store
+func (f *FileStore) DeletePreferences(ctx context.Context, tenant, contact string) error {
+	if err := f.MemoryStore.DeletePreferences(ctx, tenant, contact); err != nil {
+		return err
+	}
+	return f.write()
+}
+
+// Close stops the flush loop and writes any notification changes not yet saved
+func (f *FileStore) Close() error {
+	close(f.stop)
+	<-f.done
+
+	if !f.dirty.Load() {
+		return nil
+	}
+	return f.write()
+}
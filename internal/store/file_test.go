@@ -0,0 +1,74 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestFileStoreRestart tests that notifications, templates, contacts and preferences are
+// restored by a new store opened on the same file, in their tenants
+func TestFileStoreRestart(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	f, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+
+	notification := &domain.Notification{ID: "n1", Type: domain.TypeStdout, Status: domain.StatusQueued, CreatedAt: time.Now()}
+	if err := f.Save(ctx, notification); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	notification.Status = domain.StatusSent
+	if err := f.Update(ctx, notification); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := f.SaveTemplate(ctx, &domain.Template{Name: "welcome", Body: "Hi {{.name}}"}); err != nil {
+		t.Fatalf("SaveTemplate() error = %v", err)
+	}
+	if err := f.SaveContact(ctx, &domain.Contact{Name: "alice", Tenant: "payments"}); err != nil {
+		t.Fatalf("SaveContact() error = %v", err)
+	}
+	if err := f.SavePreferences(ctx, &domain.Preferences{Contact: "alice", Tenant: "payments"}); err != nil {
+		t.Fatalf("SavePreferences() error = %v", err)
+	}
+
+	// Templates are written straight away; notifications wait for the flush or Close
+	reopened, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	if _, err := reopened.GetTemplate(ctx, "", "welcome"); err != nil {
+		t.Errorf("Template not written when it was saved: %v", err)
+	}
+	reopened.Close()
+
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	restarted, err := NewFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("NewFileStore() error = %v", err)
+	}
+	defer restarted.Close()
+
+	got, err := restarted.Get(ctx, "n1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != domain.StatusSent {
+		t.Errorf("Status = %s, want %s", got.Status, domain.StatusSent)
+	}
+	if _, err := restarted.GetContact(ctx, "payments", "alice"); err != nil {
+		t.Errorf("GetContact() error = %v", err)
+	}
+	if _, err := restarted.GetPreferences(ctx, "payments", "alice"); err != nil {
+		t.Errorf("GetPreferences() error = %v", err)
+	}
+}
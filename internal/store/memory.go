@@ -0,0 +1,281 @@
+// Package store provides implementations of domain.NotificationStore
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// MemoryStore keeps notifications, templates, contacts and preferences in maps. Everything is
+// copied in and out, like a database store, so a worker changing a notification it's sending
+// doesn't race with readers; nothing survives a restart.
+type MemoryStore struct {
+	mu            sync.RWMutex
+	notifications map[string]*domain.Notification
+	templates     map[tenantKey]domain.Template
+	contacts      map[tenantKey]domain.Contact
+	preferences   map[tenantKey]domain.Preferences
+}
+
+// tenantKey identifies a template, contact or contact's preferences within its tenant
+type tenantKey struct {
+	tenant string
+	name   string
+}
+
+// NewMemoryStore creates an empty in-memory notification store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		notifications: make(map[string]*domain.Notification),
+		templates:     make(map[tenantKey]domain.Template),
+		contacts:      make(map[tenantKey]domain.Contact),
+		preferences:   make(map[tenantKey]domain.Preferences),
+	}
+}
+
+// Save stores a notification, replacing any with the same ID
+func (m *MemoryStore) Save(ctx context.Context, notification *domain.Notification) error {
+	stored := *notification
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifications[notification.ID] = &stored
+	return nil
+}
+
+// Get retrieves a notification by ID
+func (m *MemoryStore) Get(ctx context.Context, id string) (*domain.Notification, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	notification, exists := m.notifications[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, id)
+	}
+	copied := *notification
+	return &copied, nil
+}
+
+// List retrieves notifications matching the filter, oldest first
+func (m *MemoryStore) List(ctx context.Context, filter *domain.NotificationFilter) ([]*domain.Notification, error) {
+	results := m.matching(filter)
+	if filter == nil {
+		return results, nil
+	}
+	if filter.Offset > 0 {
+		results = results[min(filter.Offset, len(results)):]
+	}
+	if filter.Limit > 0 && filter.Limit < len(results) {
+		results = results[:filter.Limit]
+	}
+	return results, nil
+}
+
+// ListPage retrieves a page of notifications matching the filter, newest first
+func (m *MemoryStore) ListPage(ctx context.Context, filter *domain.NotificationFilter) (*domain.NotificationPage, error) {
+	if filter == nil {
+		filter = &domain.NotificationFilter{}
+	}
+	var cursor *domain.PageCursor
+	if filter.Cursor != "" {
+		parsed, err := domain.ParsePageCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &parsed
+	}
+
+	results := m.matching(filter)
+	page := &domain.NotificationPage{Total: int64(len(results))}
+	slices.Reverse(results)
+
+	if cursor != nil {
+		results = results[sort.Search(len(results), func(i int) bool { return cursor.Precedes(results[i]) }):]
+	}
+	if filter.Offset > 0 {
+		results = results[min(filter.Offset, len(results)):]
+	}
+	if filter.Limit > 0 && filter.Limit < len(results) {
+		results = results[:filter.Limit]
+		page.NextCursor = domain.CursorAfter(results[len(results)-1]).String()
+	}
+	page.Notifications = results
+	return page, nil
+}
+
+// matching returns the notifications matching the filter, oldest first
+func (m *MemoryStore) matching(filter *domain.NotificationFilter) []*domain.Notification {
+	m.mu.RLock()
+	var results []*domain.Notification
+	for _, notification := range m.notifications {
+		if filter.Matches(notification) {
+			copied := *notification
+			results = append(results, &copied)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].CreatedAt.Equal(results[j].CreatedAt) {
+			return results[i].CreatedAt.Before(results[j].CreatedAt)
+		}
+		return results[i].ID < results[j].ID
+	})
+	return results
+}
+
+// Update replaces a stored notification
+func (m *MemoryStore) Update(ctx context.Context, notification *domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.notifications[notification.ID]; !exists {
+		return fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, notification.ID)
+	}
+	stored := *notification
+	m.notifications[notification.ID] = &stored
+	return nil
+}
+
+// Delete removes notifications by ID
+func (m *MemoryStore) Delete(ctx context.Context, ids ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.notifications, id)
+	}
+	return nil
+}
+
+// SaveTemplate stores a template, replacing any of its tenant's with the same name
+func (m *MemoryStore) SaveTemplate(ctx context.Context, tmpl *domain.Template) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates[tenantKey{tmpl.Tenant, tmpl.Name}] = *tmpl
+	return nil
+}
+
+// GetTemplate retrieves a tenant's template by name
+func (m *MemoryStore) GetTemplate(ctx context.Context, tenant, name string) (*domain.Template, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tmpl, exists := m.templates[tenantKey{tenant, name}]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTemplateNotFound, name)
+	}
+	return &tmpl, nil
+}
+
+// ListTemplates retrieves every template of a tenant, sorted by name
+func (m *MemoryStore) ListTemplates(ctx context.Context, tenant string) ([]*domain.Template, error) {
+	m.mu.RLock()
+	templates := make([]*domain.Template, 0, len(m.templates))
+	for key, tmpl := range m.templates {
+		if key.tenant == tenant {
+			templates = append(templates, &tmpl)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// DeleteTemplate removes a tenant's template
+func (m *MemoryStore) DeleteTemplate(ctx context.Context, tenant, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := tenantKey{tenant, name}
+	if _, exists := m.templates[key]; !exists {
+		return fmt.Errorf("%w: %s", domain.ErrTemplateNotFound, name)
+	}
+	delete(m.templates, key)
+	return nil
+}
+
+// SaveContact stores a contact, replacing any of its tenant's with the same name
+func (m *MemoryStore) SaveContact(ctx context.Context, contact *domain.Contact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contacts[tenantKey{contact.Tenant, contact.Name}] = *contact
+	return nil
+}
+
+// GetContact retrieves a tenant's contact by name
+func (m *MemoryStore) GetContact(ctx context.Context, tenant, name string) (*domain.Contact, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	contact, exists := m.contacts[tenantKey{tenant, name}]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrContactNotFound, name)
+	}
+	return &contact, nil
+}
+
+// ListContacts retrieves every contact of a tenant, sorted by name
+func (m *MemoryStore) ListContacts(ctx context.Context, tenant string) ([]*domain.Contact, error) {
+	m.mu.RLock()
+	contacts := make([]*domain.Contact, 0, len(m.contacts))
+	for key, contact := range m.contacts {
+		if key.tenant == tenant {
+			contacts = append(contacts, &contact)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(contacts, func(i, j int) bool { return contacts[i].Name < contacts[j].Name })
+	return contacts, nil
+}
+
+// DeleteContact removes a tenant's contact
+func (m *MemoryStore) DeleteContact(ctx context.Context, tenant, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := tenantKey{tenant, name}
+	if _, exists := m.contacts[key]; !exists {
+		return fmt.Errorf("%w: %s", domain.ErrContactNotFound, name)
+	}
+	delete(m.contacts, key)
+	return nil
+}
+
+// SavePreferences stores a contact's preferences, replacing any its tenant had set
+func (m *MemoryStore) SavePreferences(ctx context.Context, prefs *domain.Preferences) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.preferences[tenantKey{prefs.Tenant, prefs.Contact}] = *prefs
+	return nil
+}
+
+// GetPreferences retrieves the preferences a tenant set for a contact
+func (m *MemoryStore) GetPreferences(ctx context.Context, tenant, contact string) (*domain.Preferences, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefs, exists := m.preferences[tenantKey{tenant, contact}]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPreferencesNotFound, contact)
+	}
+	return &prefs, nil
+}
+
+// DeletePreferences removes the preferences a tenant set for a contact
+func (m *MemoryStore) DeletePreferences(ctx context.Context, tenant, contact string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := tenantKey{tenant, contact}
+	if _, exists := m.preferences[key]; !exists {
+		return fmt.Errorf("%w: %s", domain.ErrPreferencesNotFound, contact)
+	}
+	delete(m.preferences, key)
+	return nil
+}
@@ -0,0 +1,280 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestMemoryStore tests saving, filtering, updating and deleting notifications
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	created := time.Now()
+	for i, id := range []string{"c", "a", "b"} {
+		notification := &domain.Notification{
+			ID:         id,
+			Type:       domain.TypeStdout,
+			Status:     domain.StatusQueued,
+			Recipients: []string{"user-" + id},
+			Tags:       map[string]string{"env": "prod", "team": "team-" + id},
+			CreatedAt:  created.Add(time.Duration(i) * time.Second),
+		}
+		if err := m.Save(ctx, notification); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	all, err := m.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "c" || all[2].ID != "b" {
+		t.Fatalf("Expected notifications oldest first, got %v", ids(all))
+	}
+
+	page, _ := m.List(ctx, &domain.NotificationFilter{Offset: 1, Limit: 1})
+	if len(page) != 1 || page[0].ID != "a" {
+		t.Errorf("Expected the second notification, got %v", ids(page))
+	}
+	if past, _ := m.List(ctx, &domain.NotificationFilter{Offset: 5}); len(past) != 0 {
+		t.Errorf("Expected nothing past the end, got %v", ids(past))
+	}
+
+	b, err := m.Get(ctx, "b")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	b.Status = domain.StatusSent
+	if err := m.Update(ctx, b); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	sent, _ := m.List(ctx, &domain.NotificationFilter{Statuses: []domain.NotificationStatus{domain.StatusSent}})
+	if len(sent) != 1 || sent[0].ID != "b" {
+		t.Errorf("Expected only b to be sent, got %v", ids(sent))
+	}
+	byRecipient, _ := m.List(ctx, &domain.NotificationFilter{Recipients: []string{"user-a", "nobody"}})
+	if len(byRecipient) != 1 || byRecipient[0].ID != "a" {
+		t.Errorf("Expected only a for its recipient, got %v", ids(byRecipient))
+	}
+	byTags, _ := m.List(ctx, &domain.NotificationFilter{Tags: map[string]string{"env": "prod", "team": "team-c"}})
+	if len(byTags) != 1 || byTags[0].ID != "c" {
+		t.Errorf("Expected only c for its tags, got %v", ids(byTags))
+	}
+	if none, _ := m.List(ctx, &domain.NotificationFilter{Tags: map[string]string{"env": "staging"}}); len(none) != 0 {
+		t.Errorf("Expected nothing for another tag value, got %v", ids(none))
+	}
+
+	if err := m.Delete(ctx, "a", "missing"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := m.Get(ctx, "a"); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected ErrNotificationNotFound after delete, got %v", err)
+	}
+	if err := m.Update(ctx, &domain.Notification{ID: "a"}); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected Update of a deleted notification to fail, got %v", err)
+	}
+}
+
+// TestMemoryStorePages tests newest-first pages that stay stable as notifications are added
+func TestMemoryStorePages(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	created := time.Now()
+	for i, id := range []string{"a", "b", "c", "d", "e"} {
+		m.Save(ctx, &domain.Notification{ID: id, Status: domain.StatusSent, CreatedAt: created.Add(time.Duration(i) * time.Second)})
+	}
+	// Shares d's creation time, so the ID breaks the tie
+	m.Save(ctx, &domain.Notification{ID: "d2", Status: domain.StatusSent, CreatedAt: created.Add(3 * time.Second)})
+
+	first, err := m.ListPage(ctx, &domain.NotificationFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if got := ids(first.Notifications); len(got) != 3 || got[0] != "e" || got[1] != "d2" || got[2] != "d" {
+		t.Errorf("Expected e, d2, d, got %v", got)
+	}
+	if first.Total != 6 || first.NextCursor == "" {
+		t.Errorf("Expected a total of 6 and a next cursor, got %d and %q", first.Total, first.NextCursor)
+	}
+
+	// A newer notification doesn't shift the following page
+	m.Save(ctx, &domain.Notification{ID: "f", Status: domain.StatusSent, CreatedAt: created.Add(time.Minute)})
+
+	second, _ := m.ListPage(ctx, &domain.NotificationFilter{Limit: 3, Cursor: first.NextCursor})
+	if got := ids(second.Notifications); len(got) != 3 || got[0] != "c" || got[2] != "a" {
+		t.Errorf("Expected c, b, a, got %v", got)
+	}
+	if second.Total != 7 || second.NextCursor != "" {
+		t.Errorf("Expected a total of 7 and no next cursor, got %d and %q", second.Total, second.NextCursor)
+	}
+
+	if _, err := m.ListPage(ctx, &domain.NotificationFilter{Cursor: "not-a-cursor"}); !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+// TestMemoryStoreCopies tests that notifications are copied in and out, so changes to one
+// aren't seen by the store until it's updated
+func TestMemoryStoreCopies(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	notification := &domain.Notification{ID: "a", Type: domain.TypeStdout, Status: domain.StatusQueued}
+	if err := m.Save(ctx, notification); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	notification.Status = domain.StatusProcessing
+	got, _ := m.Get(ctx, "a")
+	if got.Status != domain.StatusQueued {
+		t.Errorf("Expected a change before Update not to be stored, got %s", got.Status)
+	}
+
+	got.Status = domain.StatusSent
+	if stored, _ := m.Get(ctx, "a"); stored.Status != domain.StatusQueued {
+		t.Errorf("Expected a change to a copy not to be stored, got %s", stored.Status)
+	}
+	if err := m.Update(ctx, got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if stored, _ := m.Get(ctx, "a"); stored.Status != domain.StatusSent {
+		t.Errorf("Expected the update to be stored, got %s", stored.Status)
+	}
+}
+
+// ids returns the IDs of notifications, for failure messages
+func ids(notifications []*domain.Notification) []string {
+	result := make([]string, len(notifications))
+	for i, notification := range notifications {
+		result[i] = notification.ID
+	}
+	return result
+}
+
+// TestMemoryStoreTemplates tests saving, listing and deleting templates, and that the store
+// keeps its own copies
+func TestMemoryStoreTemplates(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	for _, name := range []string{"welcome", "alert"} {
+		if err := m.SaveTemplate(ctx, &domain.Template{Name: name, Body: "Hi"}); err != nil {
+			t.Fatalf("SaveTemplate() error = %v", err)
+		}
+	}
+
+	tmpl, err := m.GetTemplate(ctx, "", "welcome")
+	if err != nil {
+		t.Fatalf("GetTemplate() error = %v", err)
+	}
+	tmpl.Body = "changed"
+	if stored, _ := m.GetTemplate(ctx, "", "welcome"); stored.Body != "Hi" {
+		t.Errorf("Expected the stored template to be unchanged, got %q", stored.Body)
+	}
+
+	list, err := m.ListTemplates(ctx, "")
+	if err != nil || len(list) != 2 || list[0].Name != "alert" || list[1].Name != "welcome" {
+		t.Fatalf("Expected templates sorted by name, got %v, %v", list, err)
+	}
+
+	if err := m.DeleteTemplate(ctx, "", "alert"); err != nil {
+		t.Fatalf("DeleteTemplate() error = %v", err)
+	}
+	if _, err := m.GetTemplate(ctx, "", "alert"); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+	if err := m.DeleteTemplate(ctx, "", "alert"); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected deleting an unknown template to fail, got %v", err)
+	}
+}
+
+// TestMemoryStoreContacts tests saving, listing and deleting contacts
+func TestMemoryStoreContacts(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	for _, name := range []string{"team-payments", "oncall-db"} {
+		contact := &domain.Contact{Name: name, Addresses: map[domain.NotificationType][]string{domain.TypeEmail: {name + "@example.com"}}}
+		if err := m.SaveContact(ctx, contact); err != nil {
+			t.Fatalf("SaveContact() error = %v", err)
+		}
+	}
+
+	list, err := m.ListContacts(ctx, "")
+	if err != nil || len(list) != 2 || list[0].Name != "oncall-db" || list[1].Name != "team-payments" {
+		t.Fatalf("Expected contacts sorted by name, got %v, %v", list, err)
+	}
+
+	if err := m.DeleteContact(ctx, "", "oncall-db"); err != nil {
+		t.Fatalf("DeleteContact() error = %v", err)
+	}
+	if _, err := m.GetContact(ctx, "", "oncall-db"); !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected ErrContactNotFound, got %v", err)
+	}
+	if err := m.DeleteContact(ctx, "", "oncall-db"); !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected deleting an unknown contact to fail, got %v", err)
+	}
+}
+
+// TestMemoryStorePreferences tests saving, replacing and deleting preferences
+func TestMemoryStorePreferences(t *testing.T) {
+	m := NewMemoryStore()
+	ctx := context.Background()
+
+	if _, err := m.GetPreferences(ctx, "", "alice"); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Errorf("Expected ErrPreferencesNotFound, got %v", err)
+	}
+	for _, muted := range []string{"marketing", "billing"} {
+		if err := m.SavePreferences(ctx, &domain.Preferences{Contact: "alice", MutedCategories: []string{muted}}); err != nil {
+			t.Fatalf("SavePreferences() error = %v", err)
+		}
+	}
+	prefs, err := m.GetPreferences(ctx, "", "alice")
+	if err != nil || len(prefs.MutedCategories) != 1 || prefs.MutedCategories[0] != "billing" {
+		t.Fatalf("Expected the preferences to be replaced, got %+v, %v", prefs, err)
+	}
+	if err := m.DeletePreferences(ctx, "", "alice"); err != nil {
+		t.Fatalf("DeletePreferences() error = %v", err)
+	}
+	if err := m.DeletePreferences(ctx, "", "alice"); !errors.Is(err, domain.ErrPreferencesNotFound) {
+		t.Errorf("Expected ErrPreferencesNotFound, got %v", err)
+	}
+}
+
+// TestMemoryStoreTenants tests that tenants' templates, contacts and preferences of the same
+// name are kept apart
+func TestMemoryStoreTenants(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	for _, tenant := range []string{"payments", "search"} {
+		m.SaveTemplate(ctx, &domain.Template{Name: "welcome", Tenant: tenant, Body: "Hi from " + tenant})
+		m.SaveContact(ctx, &domain.Contact{Name: "oncall", Tenant: tenant, Addresses: map[domain.NotificationType][]string{domain.TypeEmail: {tenant + "@example.com"}}})
+		m.SavePreferences(ctx, &domain.Preferences{Contact: "oncall", Tenant: tenant, MutedCategories: []string{tenant}})
+	}
+
+	if tmpl, err := m.GetTemplate(ctx, "search", "welcome"); err != nil || tmpl.Body != "Hi from search" {
+		t.Errorf("Expected the search tenant's template, got %+v, %v", tmpl, err)
+	}
+	if contact, err := m.GetContact(ctx, "payments", "oncall"); err != nil || contact.Addresses[domain.TypeEmail][0] != "payments@example.com" {
+		t.Errorf("Expected the payments tenant's contact, got %+v, %v", contact, err)
+	}
+	if _, err := m.GetContact(ctx, "", "oncall"); !errors.Is(err, domain.ErrContactNotFound) {
+		t.Errorf("Expected tenants' contacts to be hidden without a tenant, got %v", err)
+	}
+	if list, _ := m.ListContacts(ctx, "search"); len(list) != 1 || list[0].Tenant != "search" {
+		t.Errorf("Expected only the search tenant's contact, got %v", list)
+	}
+
+	if err := m.DeletePreferences(ctx, "payments", "oncall"); err != nil {
+		t.Fatalf("DeletePreferences() error = %v", err)
+	}
+	if prefs, err := m.GetPreferences(ctx, "search", "oncall"); err != nil || prefs.MutedCategories[0] != "search" {
+		t.Errorf("Expected the search tenant's preferences to be kept, got %+v, %v", prefs, err)
+	}
+}
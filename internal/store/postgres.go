@@ -0,0 +1,623 @@
+//go:build !lite
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/migrate"
+	"github.com/lib/pq"
+)
+
+// PostgresStore keeps notifications in a PostgreSQL table, so their status and history
+// survive restarts and every replica sharing the database sees the same notifications
+type PostgresStore struct {
+	db *sql.DB
+
+	// readDB serves Reader's queries; it is the primary unless a read replica is configured
+	readDB    *sql.DB
+	isReplica bool
+}
+
+// NewPostgresStore connects to the database and checks the notifications table's schema.
+// When autoMigrate is set, pending migrations are applied first.
+func NewPostgresStore(dbURL string, autoMigrate bool) (*PostgresStore, error) {
+	if dbURL == "" {
+		return nil, fmt.Errorf("postgres store url is required")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := prepareSchema(db, autoMigrate); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &PostgresStore{db: db, readDB: db}, nil
+}
+
+// WithReadReplica serves Reader's lookups, listings and stats from a read-only replica,
+// keeping them off the primary that takes status updates
+func (p *PostgresStore) WithReadReplica(readURL string) error {
+	readDB, err := sql.Open("postgres", readURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to read replica: %w", err)
+	}
+
+	if err := readDB.Ping(); err != nil {
+		readDB.Close()
+		return fmt.Errorf("failed to ping read replica: %w", err)
+	}
+
+	p.readDB = readDB
+	p.isReplica = true
+	return nil
+}
+
+// Reader returns the store's reads, served by the read replica when one is configured.
+// The service still reads the primary before changing a notification.
+func (p *PostgresStore) Reader() domain.NotificationReader {
+	return &PostgresStore{db: p.readDB}
+}
+
+// ReadStaleness reports the source of Reader's queries and its replication lag
+func (p *PostgresStore) ReadStaleness(ctx context.Context) (*domain.ReadStaleness, error) {
+	if !p.isReplica {
+		return &domain.ReadStaleness{Source: "primary"}, nil
+	}
+
+	query := `
+	SELECT CASE WHEN pg_is_in_recovery()
+		THEN COALESCE(EXTRACT(EPOCH FROM (now() - pg_last_xact_replay_timestamp())), 0)
+		ELSE 0 END
+	`
+
+	var lag float64
+	if err := p.readDB.QueryRowContext(ctx, query).Scan(&lag); err != nil {
+		return nil, fmt.Errorf("failed to query replication lag: %w", err)
+	}
+	if lag < 0 {
+		lag = 0
+	}
+
+	return &domain.ReadStaleness{Source: "replica", LagSeconds: lag}, nil
+}
+
+// prepareSchema pings the database and applies or checks schema migrations
+func prepareSchema(db *sql.DB, autoMigrate bool) error {
+	if err := db.Ping(); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	ctx := context.Background()
+	if autoMigrate {
+		if _, err := migrator.Up(ctx); err != nil {
+			return fmt.Errorf("failed to migrate schema: %w", err)
+		}
+		return nil
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check schema version: %w", err)
+	}
+	if pending > 0 {
+		return fmt.Errorf("database schema has %d pending migration(s); run the migrate subcommand or enable auto_migrate", pending)
+	}
+
+	return nil
+}
+
+// Save stores a notification, replacing any with the same ID
+func (p *PostgresStore) Save(ctx context.Context, notification *domain.Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification %s: %w", notification.ID, err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO notifications (notification_id, type, status, recipients, origin_system, origin_user, created_at, notification)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (notification_id) DO UPDATE SET
+			type = EXCLUDED.type,
+			status = EXCLUDED.status,
+			recipients = EXCLUDED.recipients,
+			origin_system = EXCLUDED.origin_system,
+			origin_user = EXCLUDED.origin_user,
+			created_at = EXCLUDED.created_at,
+			updated_at = CURRENT_TIMESTAMP,
+			notification = EXCLUDED.notification`,
+		notification.ID,
+		string(notification.Type),
+		string(notification.Status),
+		pq.Array(notification.Recipients),
+		notification.Origin.System,
+		notification.Origin.User,
+		notification.CreatedAt,
+		payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save notification %s: %w", notification.ID, err)
+	}
+	return nil
+}
+
+// Get retrieves a notification by ID
+func (p *PostgresStore) Get(ctx context.Context, id string) (*domain.Notification, error) {
+	var payload []byte
+	err := p.db.QueryRowContext(ctx, `SELECT notification FROM notifications WHERE notification_id = $1`, id).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification %s: %w", id, err)
+	}
+	return decodeNotification(payload)
+}
+
+// List retrieves notifications matching the filter, oldest first
+func (p *PostgresStore) List(ctx context.Context, filter *domain.NotificationFilter) ([]*domain.Notification, error) {
+	query, args := listQuery(filter)
+	return p.query(ctx, query, args)
+}
+
+// ListPage retrieves a page of notifications matching the filter, newest first
+func (p *PostgresStore) ListPage(ctx context.Context, filter *domain.NotificationFilter) (*domain.NotificationPage, error) {
+	if filter == nil {
+		filter = &domain.NotificationFilter{}
+	}
+	var cursor *domain.PageCursor
+	if filter.Cursor != "" {
+		parsed, err := domain.ParsePageCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &parsed
+	}
+
+	page := &domain.NotificationPage{}
+	query, args := countQuery(filter)
+	if err := p.db.QueryRowContext(ctx, query, args...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	// Fetch one extra row to learn whether there's another page
+	query, args = pageQuery(filter, cursor)
+	notifications, err := p.query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && len(notifications) > filter.Limit {
+		notifications = notifications[:filter.Limit]
+		page.NextCursor = domain.CursorAfter(notifications[len(notifications)-1]).String()
+	}
+	page.Notifications = notifications
+	return page, nil
+}
+
+// query runs a query selecting the notification column
+func (p *PostgresStore) query(ctx context.Context, query string, args []any) ([]*domain.Notification, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*domain.Notification
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("failed to read notification: %w", err)
+		}
+		notification, err := decodeNotification(payload)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return results, nil
+}
+
+// listQuery builds the query for List
+func listQuery(filter *domain.NotificationFilter) (string, []any) {
+	if filter == nil {
+		filter = &domain.NotificationFilter{}
+	}
+	conditions, args := filterConditions(filter)
+
+	var query strings.Builder
+	query.WriteString("SELECT notification FROM notifications")
+	writeWhere(&query, conditions)
+	query.WriteString(" ORDER BY created_at, notification_id")
+	args = writeLimitOffset(&query, args, filter.Limit, filter.Offset)
+
+	return query.String(), args
+}
+
+// countQuery builds the query counting every notification matching the filter
+func countQuery(filter *domain.NotificationFilter) (string, []any) {
+	conditions, args := filterConditions(filter)
+
+	var query strings.Builder
+	query.WriteString("SELECT COUNT(*) FROM notifications")
+	writeWhere(&query, conditions)
+	return query.String(), args
+}
+
+// pageQuery builds the query for a newest-first page, fetching one row past the page size.
+// Timestamps are stored to the microsecond, so the cursor's time is rounded the same way.
+func pageQuery(filter *domain.NotificationFilter, cursor *domain.PageCursor) (string, []any) {
+	conditions, args := filterConditions(filter)
+	if cursor != nil {
+		args = append(args, cursor.CreatedAt.Round(time.Microsecond), cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at, notification_id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	var query strings.Builder
+	query.WriteString("SELECT notification FROM notifications")
+	writeWhere(&query, conditions)
+	query.WriteString(" ORDER BY created_at DESC, notification_id DESC")
+	limit := filter.Limit
+	if limit > 0 {
+		limit++
+	}
+	args = writeLimitOffset(&query, args, limit, filter.Offset)
+
+	return query.String(), args
+}
+
+// filterConditions returns the WHERE conditions for a filter and their numbered parameters.
+// Each condition mirrors NotificationFilter.Matches.
+func filterConditions(filter *domain.NotificationFilter) ([]string, []any) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if len(filter.IDs) > 0 {
+		where("notification_id = ANY($%d)", pq.Array(filter.IDs))
+	}
+	if len(filter.Types) > 0 {
+		types := make([]string, len(filter.Types))
+		for i, t := range filter.Types {
+			types[i] = string(t)
+		}
+		where("type = ANY($%d)", pq.Array(types))
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		where("status = ANY($%d)", pq.Array(statuses))
+	}
+	if len(filter.Recipients) > 0 {
+		where("recipients && $%d", pq.Array(filter.Recipients))
+	}
+	if len(filter.OriginSystems) > 0 {
+		where("origin_system = ANY($%d)", pq.Array(filter.OriginSystems))
+	}
+	if len(filter.OriginUsers) > 0 {
+		where("origin_user = ANY($%d)", pq.Array(filter.OriginUsers))
+	}
+	if len(filter.Tags) > 0 {
+		tags, _ := json.Marshal(filter.Tags) // A map of strings always marshals
+		where("notification->'tags' @> $%d::jsonb", string(tags))
+	}
+	if filter.FanoutID != "" {
+		where("notification->>'fanout_id' = $%d", filter.FanoutID)
+	}
+	if filter.Tenant != "" {
+		where("notification->>'tenant' = $%d", filter.Tenant)
+	}
+	if filter.CreatedAfter != nil {
+		where("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		where("created_at <= $%d", *filter.CreatedBefore)
+	}
+
+	return conditions, args
+}
+
+// writeWhere appends the WHERE clause for conditions, if there are any
+func writeWhere(query *strings.Builder, conditions []string) {
+	if len(conditions) > 0 {
+		query.WriteString(" WHERE " + strings.Join(conditions, " AND "))
+	}
+}
+
+// writeLimitOffset appends LIMIT and OFFSET clauses for positive values, returning the
+// arguments with their parameters added
+func writeLimitOffset(query *strings.Builder, args []any, limit, offset int) []any {
+	if limit > 0 {
+		args = append(args, limit)
+		fmt.Fprintf(query, " LIMIT $%d", len(args))
+	}
+	if offset > 0 {
+		args = append(args, offset)
+		fmt.Fprintf(query, " OFFSET $%d", len(args))
+	}
+	return args
+}
+
+// Update replaces a stored notification
+func (p *PostgresStore) Update(ctx context.Context, notification *domain.Notification) error {
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification %s: %w", notification.ID, err)
+	}
+
+	result, err := p.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET status = $2, updated_at = CURRENT_TIMESTAMP, notification = $3
+		WHERE notification_id = $1`,
+		notification.ID, string(notification.Status), payload)
+	if err != nil {
+		return fmt.Errorf("failed to update notification %s: %w", notification.ID, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrNotificationNotFound, notification.ID)
+	}
+	return nil
+}
+
+// Delete removes notifications by ID
+func (p *PostgresStore) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM notifications WHERE notification_id = ANY($1)`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to delete notifications: %w", err)
+	}
+	return nil
+}
+
+// SaveTemplate stores a template, replacing any of its tenant's with the same name
+func (p *PostgresStore) SaveTemplate(ctx context.Context, tmpl *domain.Template) error {
+	payload, err := json.Marshal(tmpl)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template %s: %w", tmpl.Name, err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO templates (tenant, name, type, updated_at, template)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant, name) DO UPDATE SET
+			type = EXCLUDED.type,
+			updated_at = EXCLUDED.updated_at,
+			template = EXCLUDED.template`,
+		tmpl.Tenant, tmpl.Name, string(tmpl.Type), tmpl.UpdatedAt, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save template %s: %w", tmpl.Name, err)
+	}
+	return nil
+}
+
+// GetTemplate retrieves a tenant's template by name
+func (p *PostgresStore) GetTemplate(ctx context.Context, tenant, name string) (*domain.Template, error) {
+	var payload []byte
+	err := p.db.QueryRowContext(ctx, `SELECT template FROM templates WHERE tenant = $1 AND name = $2`, tenant, name).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTemplateNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template %s: %w", name, err)
+	}
+	return decodeTemplate(payload)
+}
+
+// ListTemplates retrieves every template of a tenant, sorted by name
+func (p *PostgresStore) ListTemplates(ctx context.Context, tenant string) ([]*domain.Template, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT template FROM templates WHERE tenant = $1 ORDER BY name`, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*domain.Template
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		tmpl, err := decodeTemplate(payload)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	return templates, nil
+}
+
+// DeleteTemplate removes a tenant's template
+func (p *PostgresStore) DeleteTemplate(ctx context.Context, tenant, name string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM templates WHERE tenant = $1 AND name = $2`, tenant, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete template %s: %w", name, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrTemplateNotFound, name)
+	}
+	return nil
+}
+
+// SaveContact stores a contact, replacing any of its tenant's with the same name
+func (p *PostgresStore) SaveContact(ctx context.Context, contact *domain.Contact) error {
+	payload, err := json.Marshal(contact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contact %s: %w", contact.Name, err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO contacts (tenant, name, updated_at, contact)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, name) DO UPDATE SET
+			updated_at = EXCLUDED.updated_at,
+			contact = EXCLUDED.contact`,
+		contact.Tenant, contact.Name, contact.UpdatedAt, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save contact %s: %w", contact.Name, err)
+	}
+	return nil
+}
+
+// GetContact retrieves a tenant's contact by name
+func (p *PostgresStore) GetContact(ctx context.Context, tenant, name string) (*domain.Contact, error) {
+	var payload []byte
+	err := p.db.QueryRowContext(ctx, `SELECT contact FROM contacts WHERE tenant = $1 AND name = $2`, tenant, name).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrContactNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact %s: %w", name, err)
+	}
+	return decodeContact(payload)
+}
+
+// ListContacts retrieves every contact of a tenant, sorted by name
+func (p *PostgresStore) ListContacts(ctx context.Context, tenant string) ([]*domain.Contact, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT contact FROM contacts WHERE tenant = $1 ORDER BY name`, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	defer rows.Close()
+
+	var contacts []*domain.Contact
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("failed to read contact: %w", err)
+		}
+		contact, err := decodeContact(payload)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// DeleteContact removes a tenant's contact
+func (p *PostgresStore) DeleteContact(ctx context.Context, tenant, name string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM contacts WHERE tenant = $1 AND name = $2`, tenant, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact %s: %w", name, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrContactNotFound, name)
+	}
+	return nil
+}
+
+// SavePreferences stores a contact's preferences, replacing any its tenant had set
+func (p *PostgresStore) SavePreferences(ctx context.Context, prefs *domain.Preferences) error {
+	payload, err := json.Marshal(prefs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal preferences for %s: %w", prefs.Contact, err)
+	}
+
+	_, err = p.db.ExecContext(ctx, `
+		INSERT INTO preferences (tenant, contact, updated_at, preferences)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant, contact) DO UPDATE SET
+			updated_at = EXCLUDED.updated_at,
+			preferences = EXCLUDED.preferences`,
+		prefs.Tenant, prefs.Contact, prefs.UpdatedAt, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save preferences for %s: %w", prefs.Contact, err)
+	}
+	return nil
+}
+
+// GetPreferences retrieves the preferences a tenant set for a contact
+func (p *PostgresStore) GetPreferences(ctx context.Context, tenant, contact string) (*domain.Preferences, error) {
+	var payload []byte
+	err := p.db.QueryRowContext(ctx, `SELECT preferences FROM preferences WHERE tenant = $1 AND contact = $2`, tenant, contact).Scan(&payload)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", domain.ErrPreferencesNotFound, contact)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preferences for %s: %w", contact, err)
+	}
+
+	var prefs domain.Preferences
+	if err := json.Unmarshal(payload, &prefs); err != nil {
+		return nil, fmt.Errorf("failed to decode preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// DeletePreferences removes the preferences a tenant set for a contact
+func (p *PostgresStore) DeletePreferences(ctx context.Context, tenant, contact string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM preferences WHERE tenant = $1 AND contact = $2`, tenant, contact)
+	if err != nil {
+		return fmt.Errorf("failed to delete preferences for %s: %w", contact, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrPreferencesNotFound, contact)
+	}
+	return nil
+}
+
+// Close closes the database connections
+func (p *PostgresStore) Close() error {
+	if p.isReplica {
+		p.readDB.Close()
+	}
+	return p.db.Close()
+}
+
+// decodeNotification reads a notification from its JSON column
+func decodeNotification(payload []byte) (*domain.Notification, error) {
+	var notification domain.Notification
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return nil, fmt.Errorf("failed to decode notification: %w", err)
+	}
+	return &notification, nil
+}
+
+// decodeTemplate reads a template from its JSON column
+func decodeTemplate(payload []byte) (*domain.Template, error) {
+	var tmpl domain.Template
+	if err := json.Unmarshal(payload, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to decode template: %w", err)
+	}
+	return &tmpl, nil
+}
+
+// decodeContact reads a contact from its JSON column
+func decodeContact(payload []byte) (*domain.Contact, error) {
+	var contact domain.Contact
+	if err := json.Unmarshal(payload, &contact); err != nil {
+		return nil, fmt.Errorf("failed to decode contact: %w", err)
+	}
+	return &contact, nil
+}
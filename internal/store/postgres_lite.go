@@ -0,0 +1,29 @@
+//go:build lite
+
+package store
+
+import (
+	"errors"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// PostgresStore is unavailable in lite builds, which leave out the PostgreSQL driver
+type PostgresStore struct {
+	domain.NotificationStore
+}
+
+// NewPostgresStore reports that lite builds have no PostgreSQL notification store
+func NewPostgresStore(dbURL string, autoMigrate bool) (*PostgresStore, error) {
+	return nil, errors.New("postgres notification store is not included in lite builds")
+}
+
+// WithReadReplica reports that lite builds have no PostgreSQL notification store
+func (p *PostgresStore) WithReadReplica(readURL string) error {
+	return errors.New("postgres notification store is not included in lite builds")
+}
+
+// Close does nothing; a PostgresStore can't be created in lite builds
+func (p *PostgresStore) Close() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build !lite
+
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+)
+
+// TestListQuery tests that filters become indexed conditions with numbered parameters
+func TestListQuery(t *testing.T) {
+	if query, args := listQuery(nil); query != "SELECT notification FROM notifications ORDER BY created_at, notification_id" || len(args) != 0 {
+		t.Errorf("Unexpected unfiltered query %q with %d args", query, len(args))
+	}
+
+	after := time.Now().Add(-time.Hour)
+	query, args := listQuery(&domain.NotificationFilter{
+		Statuses:     []domain.NotificationStatus{domain.StatusQueued, domain.StatusRetrying},
+		Recipients:   []string{"alice"},
+		Tags:         map[string]string{"team": "payments"},
+		CreatedAfter: &after,
+		Limit:        10,
+		Offset:       20,
+	})
+	want := "SELECT notification FROM notifications" +
+		" WHERE status = ANY($1) AND recipients && $2 AND notification->'tags' @> $3::jsonb AND created_at >= $4" +
+		" ORDER BY created_at, notification_id LIMIT $5 OFFSET $6"
+	if query != want {
+		t.Errorf("listQuery() = %q, want %q", query, want)
+	}
+	if len(args) != 6 || args[2] != `{"team":"payments"}` || args[4] != 10 || args[5] != 20 {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+// TestPageQuery tests the newest-first page query and the count that goes with it
+func TestPageQuery(t *testing.T) {
+	filter := &domain.NotificationFilter{Statuses: []domain.NotificationStatus{domain.StatusSent}, Limit: 25}
+	cursor := &domain.PageCursor{CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC), ID: "n-42"}
+
+	query, args := pageQuery(filter, cursor)
+	want := "SELECT notification FROM notifications" +
+		" WHERE status = ANY($1) AND (created_at, notification_id) < ($2, $3)" +
+		" ORDER BY created_at DESC, notification_id DESC LIMIT $4"
+	if query != want {
+		t.Errorf("pageQuery() = %q, want %q", query, want)
+	}
+	if len(args) != 4 || args[3] != 26 || args[2] != "n-42" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if at, _ := args[1].(time.Time); at.Nanosecond() != 123457000 {
+		t.Errorf("Expected the cursor time rounded to the microsecond, got %v", args[1])
+	}
+
+	if query, args := countQuery(filter); query != "SELECT COUNT(*) FROM notifications WHERE status = ANY($1)" || len(args) != 1 {
+		t.Errorf("Unexpected count query %q with %d args", query, len(args))
+	}
+}
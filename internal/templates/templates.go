@@ -0,0 +1,496 @@
+// Package templates renders notification subjects and bodies from named Go templates, so a
+// caller can send a template name and its variables instead of building message bodies itself.
+// Subjects and plain-text bodies use text/template; HTML bodies use html/template, which
+// escapes variables for the context they appear in. A template can have channel variants and
+// translations, which are picked per notification by its type and locale.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/emailhtml"
+)
+
+// validName matches the names templates can have, so they can be used in URL paths
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// validLocale matches a normalized locale tag: a language, then optional subtags such as a
+// script or region
+var validLocale = regexp.MustCompile(`^[a-z]{2,8}(-[a-z0-9]{1,8})*$`)
+
+// funcs are available in every template. formatTime is bound to the account a notification is
+// sent through when it's rendered.
+var funcs = map[string]interface{}{
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"trim":       strings.TrimSpace,
+	"formatTime": timeFunc(nil),
+}
+
+// Rendered is the output of a template. A field is empty if the template doesn't set it.
+type Rendered struct {
+	Subject  string
+	Body     string
+	HTMLBody string
+	Locale   string // Translation the most specific parts came from; empty for the template's own
+}
+
+// compiled is a parsed template
+type compiled struct {
+	def     domain.Template
+	base    rendering
+	locales map[string]rendering // Keyed by normalized locale tag
+}
+
+// rendering is the parsed parts of a template or one of its translations, with its channel
+// variants
+type rendering struct {
+	parts    parts
+	variants map[domain.NotificationType]parts
+}
+
+// parts are the parsed subject and bodies of a template or one of its variants. A part is nil
+// if it isn't set.
+type parts struct {
+	subject  *texttemplate.Template
+	body     *texttemplate.Template
+	htmlBody *htmltemplate.Template
+}
+
+// Engine holds the parsed templates by tenant and name. It's safe for concurrent use.
+type Engine struct {
+	mu            sync.RWMutex
+	templates     map[templateKey]*compiled
+	defaultLocale string
+	fallbacks     map[string][]string
+	emailStyle    emailhtml.Style
+}
+
+// templateKey identifies a template within its tenant
+type templateKey struct {
+	tenant string
+	name   string
+}
+
+// NewEngine creates an engine with no templates
+func NewEngine() *Engine {
+	return &Engine{templates: make(map[templateKey]*compiled)}
+}
+
+// NormalizeLocale lowercases a locale tag and uses '-' between subtags, so "pt_BR" and "pt-br"
+// are the same locale. It returns an error wrapping domain.ErrInvalidTemplate if the tag isn't
+// a language followed by optional subtags.
+func NormalizeLocale(tag string) (string, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if !validLocale.MatchString(normalized) {
+		return "", fmt.Errorf("%w: invalid locale %q", domain.ErrInvalidTemplate, tag)
+	}
+	return normalized, nil
+}
+
+// SetLocales sets the locale notifications without one are rendered in, and the locales each
+// locale falls back to when a template has no translation for it or its language
+func (e *Engine) SetLocales(defaultLocale string, fallbacks map[string][]string) error {
+	var err error
+	if defaultLocale != "" {
+		if defaultLocale, err = NormalizeLocale(defaultLocale); err != nil {
+			return err
+		}
+	}
+	normalized := make(map[string][]string, len(fallbacks))
+	for locale, chain := range fallbacks {
+		if locale, err = NormalizeLocale(locale); err != nil {
+			return err
+		}
+		for _, fallback := range chain {
+			if fallback, err = NormalizeLocale(fallback); err != nil {
+				return err
+			}
+			normalized[locale] = append(normalized[locale], fallback)
+		}
+	}
+
+	e.mu.Lock()
+	e.defaultLocale = defaultLocale
+	e.fallbacks = normalized
+	e.mu.Unlock()
+	return nil
+}
+
+// Validate parses a template without adding it, returning an error wrapping
+// domain.ErrInvalidTemplate if it doesn't parse
+func Validate(def domain.Template) error {
+	_, err := compile(def)
+	return err
+}
+
+// SetEmailStyle sets the look of the layout email templates are wrapped in
+func (e *Engine) SetEmailStyle(style emailhtml.Style) error {
+	if err := style.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidTemplate, err)
+	}
+
+	e.mu.Lock()
+	e.emailStyle = style
+	e.mu.Unlock()
+	return nil
+}
+
+// compile parses each part of a template and its variants. Referencing a variable that isn't
+// supplied is an error when rendering rather than an empty value.
+func compile(def domain.Template) (*compiled, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidTemplate)
+	}
+	if !validName.MatchString(def.Name) {
+		return nil, fmt.Errorf("%w: invalid name %q (letters, digits, '.', '_' and '-' only)", domain.ErrInvalidTemplate, def.Name)
+	}
+	if def.Subject == "" && def.Body == "" && def.HTMLBody == "" && len(def.Variants) == 0 && len(def.Locales) == 0 {
+		return nil, fmt.Errorf("%w: %s sets none of subject, body or html_body", domain.ErrInvalidTemplate, def.Name)
+	}
+
+	if def.Layout != "" {
+		if !emailhtml.ValidLayout(def.Layout) {
+			return nil, fmt.Errorf("%w: %s: unknown layout %q (available: %s)", domain.ErrInvalidTemplate, def.Name, def.Layout, strings.Join(emailhtml.Layouts, ", "))
+		}
+		if def.Type != "" && def.Type != domain.TypeEmail {
+			return nil, fmt.Errorf("%w: %s: layouts are only for email templates", domain.ErrInvalidTemplate, def.Name)
+		}
+	}
+
+	base, err := compileRendering(def, def.Name, domain.TemplateVariant{Subject: def.Subject, Body: def.Body, HTMLBody: def.HTMLBody}, def.Variants)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiled{def: def, base: base, locales: make(map[string]rendering, len(def.Locales))}
+	for tag, locale := range def.Locales {
+		normalized, err := NormalizeLocale(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: invalid locale %q", domain.ErrInvalidTemplate, def.Name, tag)
+		}
+		if _, ok := c.locales[normalized]; ok {
+			return nil, fmt.Errorf("%w: %s has more than one %s translation", domain.ErrInvalidTemplate, def.Name, normalized)
+		}
+		if locale.Subject == "" && locale.Body == "" && locale.HTMLBody == "" && len(locale.Variants) == 0 {
+			return nil, fmt.Errorf("%w: %s %s translation sets none of subject, body or html_body", domain.ErrInvalidTemplate, def.Name, tag)
+		}
+		label := def.Name + " " + tag + " translation"
+		if c.locales[normalized], err = compileRendering(def, label, domain.TemplateVariant{Subject: locale.Subject, Body: locale.Body, HTMLBody: locale.HTMLBody}, locale.Variants); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// compileRendering parses the parts of a template or translation and its channel variants.
+// label names it in errors.
+func compileRendering(def domain.Template, label string, own domain.TemplateVariant, variants map[domain.NotificationType]domain.TemplateVariant) (rendering, error) {
+	p, err := parse(own)
+	if err != nil {
+		return rendering{}, fmt.Errorf("%w: %s: %v", domain.ErrInvalidTemplate, label, err)
+	}
+	r := rendering{parts: p, variants: make(map[domain.NotificationType]parts, len(variants))}
+	for notifType, variant := range variants {
+		if def.Type != "" && notifType != def.Type {
+			return rendering{}, fmt.Errorf("%w: %s is for %s notifications but has a %s variant", domain.ErrInvalidTemplate, label, def.Type, notifType)
+		}
+		if variant.Subject == "" && variant.Body == "" && variant.HTMLBody == "" {
+			return rendering{}, fmt.Errorf("%w: %s %s variant sets none of subject, body or html_body", domain.ErrInvalidTemplate, label, notifType)
+		}
+		if r.variants[notifType], err = parse(variant); err != nil {
+			return rendering{}, fmt.Errorf("%w: %s %s variant: %v", domain.ErrInvalidTemplate, label, notifType, err)
+		}
+	}
+	return r, nil
+}
+
+// parse parses the parts of a template or variant that are set
+func parse(variant domain.TemplateVariant) (parts, error) {
+	var p parts
+	var err error
+	if variant.Subject != "" {
+		if p.subject, err = texttemplate.New("subject").Funcs(funcs).Option("missingkey=error").Parse(variant.Subject); err != nil {
+			return parts{}, err
+		}
+	}
+	if variant.Body != "" {
+		if p.body, err = texttemplate.New("body").Funcs(funcs).Option("missingkey=error").Parse(variant.Body); err != nil {
+			return parts{}, err
+		}
+	}
+	if variant.HTMLBody != "" {
+		if p.htmlBody, err = htmltemplate.New("html_body").Funcs(funcs).Option("missingkey=error").Parse(variant.HTMLBody); err != nil {
+			return parts{}, err
+		}
+	}
+	return p, nil
+}
+
+// Add parses a template and adds it, replacing any template of its tenant with the same name
+func (e *Engine) Add(def domain.Template) error {
+	c, err := compile(def)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.templates[templateKey{def.Tenant, def.Name}] = c
+	e.mu.Unlock()
+	return nil
+}
+
+// Remove removes a tenant's template, reporting whether it existed
+func (e *Engine) Remove(tenant, name string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	key := templateKey{tenant, name}
+	_, ok := e.templates[key]
+	delete(e.templates, key)
+	return ok
+}
+
+// Get returns the definition of a tenant's template
+func (e *Engine) Get(tenant, name string) (domain.Template, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	c, ok := e.templates[templateKey{tenant, name}]
+	if !ok {
+		return domain.Template{}, false
+	}
+	return c.def, true
+}
+
+// List returns every template's definition, of every tenant, sorted by name
+func (e *Engine) List() []domain.Template {
+	e.mu.RLock()
+	defs := make([]domain.Template, 0, len(e.templates))
+	for _, c := range e.templates {
+		defs = append(defs, c.def)
+	}
+	e.mu.RUnlock()
+
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+// Render renders a template without a tenant with vars for a notification type and locale,
+// with formatTime rendering times in UTC. See RenderFor.
+func (e *Engine) Render(name string, notifType domain.NotificationType, locale string, vars map[string]interface{}) (Rendered, error) {
+	return e.RenderFor("", name, notifType, locale, vars, nil)
+}
+
+// RenderFor renders a tenant's template with vars for a notification type and locale. Each part comes
+// from the first place that sets it, trying the locale's translations in fallback order and
+// then the template itself, and within each the type's variant before its own parts. An
+// empty locale uses the default locale. An email template with a layout has its HTML body
+// wrapped in the layout, and a text body generated from the HTML if it doesn't set one. It
+// returns domain.ErrTemplateNotFound if there's no such template, or an error wrapping
+// domain.ErrInvalidTemplate if it fails to render, e.g. because a variable is missing.
+// formatTime renders times with display, the account's timezone and layout; nil uses UTC.
+func (e *Engine) RenderFor(tenant, name string, notifType domain.NotificationType, locale string, vars map[string]interface{}, display domain.TimeFormatter) (Rendered, error) {
+	e.mu.RLock()
+	c, ok := e.templates[templateKey{tenant, name}]
+	if locale == "" {
+		locale = e.defaultLocale
+	}
+	chain := e.chain(locale)
+	emailStyle := e.emailStyle
+	e.mu.RUnlock()
+	if !ok {
+		return Rendered{}, fmt.Errorf("%w: %s", domain.ErrTemplateNotFound, name)
+	}
+	if vars == nil {
+		vars = map[string]interface{}{}
+	}
+
+	var rendered Rendered
+	var candidates []rendering
+	for _, tag := range chain {
+		if r, ok := c.locales[tag]; ok {
+			if rendered.Locale == "" {
+				rendered.Locale = tag
+			}
+			candidates = append(candidates, r)
+		}
+	}
+	candidates = append(candidates, c.base)
+
+	var p parts
+	for _, r := range candidates {
+		for _, source := range []parts{r.variants[notifType], r.parts} {
+			if p.subject == nil {
+				p.subject = source.subject
+			}
+			if p.body == nil {
+				p.body = source.body
+			}
+			if p.htmlBody == nil {
+				p.htmlBody = source.htmlBody
+			}
+		}
+	}
+
+	bound := map[string]interface{}{"formatTime": timeFunc(display)}
+	var err error
+	if p.subject != nil {
+		if rendered.Subject, err = executeText(p.subject, bound, vars); err != nil {
+			return Rendered{}, fmt.Errorf("%w: %s subject: %v", domain.ErrInvalidTemplate, name, err)
+		}
+		// A subject is a single line
+		rendered.Subject = strings.Join(strings.Fields(rendered.Subject), " ")
+	}
+	if p.body != nil {
+		if rendered.Body, err = executeText(p.body, bound, vars); err != nil {
+			return Rendered{}, fmt.Errorf("%w: %s body: %v", domain.ErrInvalidTemplate, name, err)
+		}
+	}
+	if p.htmlBody != nil {
+		if rendered.HTMLBody, err = executeHTML(p.htmlBody, bound, vars); err != nil {
+			return Rendered{}, fmt.Errorf("%w: %s html_body: %v", domain.ErrInvalidTemplate, name, err)
+		}
+	}
+
+	if c.def.Layout != "" && notifType == domain.TypeEmail && rendered.HTMLBody != "" {
+		if rendered.Body == "" {
+			rendered.Body = emailhtml.PlainText(rendered.HTMLBody)
+		}
+		if rendered.HTMLBody, err = emailhtml.Wrap(c.def.Layout, emailStyle, rendered.Subject, rendered.Locale, rendered.HTMLBody); err != nil {
+			return Rendered{}, fmt.Errorf("%w: %s layout: %v", domain.ErrInvalidTemplate, name, err)
+		}
+	}
+	return rendered, nil
+}
+
+// chain returns the locales tried for a locale, most specific first: the locale and its
+// parents ("pt-br", "pt"), then the locales each of those falls back to, with their parents
+// and fallbacks in turn. e.mu must be held.
+func (e *Engine) chain(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+	locale, err := NormalizeLocale(locale)
+	if err != nil {
+		return nil // Not a locale any translation can be for
+	}
+
+	var chain []string
+	seen := make(map[string]bool)
+	var visit func(tag string)
+	visit = func(tag string) {
+		start := len(chain)
+		for ; tag != ""; tag = parentLocale(tag) {
+			if !seen[tag] {
+				seen[tag] = true
+				chain = append(chain, tag)
+			}
+		}
+		for _, tag := range chain[start:] {
+			for _, fallback := range e.fallbacks[tag] {
+				visit(fallback)
+			}
+		}
+	}
+	visit(locale)
+	return chain
+}
+
+// parentLocale drops the last subtag of a locale, returning "" for a bare language
+func parentLocale(tag string) string {
+	i := strings.LastIndex(tag, "-")
+	if i < 0 {
+		return ""
+	}
+	return tag[:i]
+}
+
+// executor is implemented by both text/template and html/template templates
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// execute renders a parsed template to a string
+func execute(t executor, vars map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, vars); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// executeText renders a copy of a parsed text template with funcs bound for this rendering
+func executeText(t *texttemplate.Template, bound map[string]interface{}, vars map[string]interface{}) (string, error) {
+	clone, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	return execute(clone.Funcs(bound), vars)
+}
+
+// executeHTML renders a copy of a parsed HTML template with funcs bound for this rendering. The
+// parsed template itself is never executed, as html/template can't clone a template after that.
+func executeHTML(t *htmltemplate.Template, bound map[string]interface{}, vars map[string]interface{}) (string, error) {
+	clone, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	return execute(clone.Funcs(bound), vars)
+}
+
+// utcDisplay renders times in UTC when a rendering isn't for a particular account
+type utcDisplay struct{}
+
+func (utcDisplay) FormatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 MST")
+}
+
+// timeFunc returns the formatTime template function, which renders a time.Time, an RFC 3339
+// string or Unix seconds with display
+func timeFunc(display domain.TimeFormatter) func(value interface{}) (string, error) {
+	if display == nil {
+		display = utcDisplay{}
+	}
+	return func(value interface{}) (string, error) {
+		var t time.Time
+		switch v := value.(type) {
+		case time.Time:
+			t = v
+		case *time.Time:
+			if v == nil {
+				return "", fmt.Errorf("formatTime: nil time")
+			}
+			t = *v
+		case string:
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return "", fmt.Errorf("formatTime: %q isn't an RFC 3339 time", v)
+			}
+			t = parsed
+		case float64:
+			t = time.Unix(int64(v), 0)
+		case int:
+			t = time.Unix(int64(v), 0)
+		case int64:
+			t = time.Unix(v, 0)
+		case json.Number:
+			seconds, err := v.Int64()
+			if err != nil {
+				return "", fmt.Errorf("formatTime: %s isn't Unix seconds", v)
+			}
+			t = time.Unix(seconds, 0)
+		default:
+			return "", fmt.Errorf("formatTime: can't format %T as a time", value)
+		}
+		return display.FormatTime(t), nil
+	}
+}
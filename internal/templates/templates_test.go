@@ -0,0 +1,293 @@
+package templates
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/igodwin/notifier/internal/domain"
+	"github.com/igodwin/notifier/internal/emailhtml"
+)
+
+// TestRender tests that each part of a template is rendered, with HTML escaped only in the
+// HTML body
+func TestRender(t *testing.T) {
+	e := NewEngine()
+	err := e.Add(domain.Template{
+		Name:     "welcome",
+		Subject:  "Welcome,\n{{.name}}",
+		Body:     "Hi {{.name}}, your plan is {{upper .plan}}",
+		HTMLBody: "<p>Hi {{.name}}</p>",
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	rendered, err := e.Render("welcome", domain.TypeEmail, "", map[string]interface{}{"name": "<Bob>", "plan": "pro"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered.Subject != "Welcome, <Bob>" {
+		t.Errorf("Subject = %q", rendered.Subject)
+	}
+	if rendered.Body != "Hi <Bob>, your plan is PRO" {
+		t.Errorf("Body = %q", rendered.Body)
+	}
+	if rendered.HTMLBody != "<p>Hi &lt;Bob&gt;</p>" {
+		t.Errorf("HTMLBody = %q", rendered.HTMLBody)
+	}
+}
+
+// berlinDisplay renders times for recipients in Berlin
+type berlinDisplay struct{}
+
+func (berlinDisplay) FormatTime(t time.Time) string {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	return t.In(berlin).Format("02.01.2006 15:04 MST")
+}
+
+// TestRenderFormatTime tests that formatTime renders times for the account being sent through,
+// in UTC when there isn't one, whether they're given as times, RFC 3339 strings or Unix seconds
+func TestRenderFormatTime(t *testing.T) {
+	e := NewEngine()
+	err := e.Add(domain.Template{Name: "maintenance", Body: "Starts {{formatTime .start}}", HTMLBody: "<p>Ends {{formatTime .end}}</p>"})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	vars := map[string]interface{}{"start": "2024-05-01T07:00:00Z", "end": float64(1714554000)}
+
+	rendered, err := e.RenderFor("", "maintenance", domain.TypeEmail, "", vars, berlinDisplay{})
+	if err != nil {
+		t.Fatalf("RenderFor() error = %v", err)
+	}
+	if rendered.Body != "Starts 01.05.2024 09:00 CEST" || rendered.HTMLBody != "<p>Ends 01.05.2024 11:00 CEST</p>" {
+		t.Errorf("Unexpected rendering for Berlin: body=%q, html_body=%q", rendered.Body, rendered.HTMLBody)
+	}
+
+	// The HTML template can be rendered again once it's been executed
+	rendered, err = e.Render("maintenance", domain.TypeEmail, "", map[string]interface{}{"start": time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), "end": 1714554000})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered.Body != "Starts 2024-05-01 07:00 UTC" || rendered.HTMLBody != "<p>Ends 2024-05-01 09:00 UTC</p>" {
+		t.Errorf("Unexpected rendering in UTC: body=%q, html_body=%q", rendered.Body, rendered.HTMLBody)
+	}
+
+	if _, err := e.Render("maintenance", domain.TypeEmail, "", map[string]interface{}{"start": "tomorrow", "end": 0}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a time that can't be parsed to be ErrInvalidTemplate, got %v", err)
+	}
+}
+
+// TestRenderErrors tests that unknown templates and missing variables are reported
+func TestRenderErrors(t *testing.T) {
+	e := NewEngine()
+	if err := e.Add(domain.Template{Name: "welcome", Body: "Hi {{.name}}"}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	if _, err := e.Render("goodbye", domain.TypeEmail, "", nil); !errors.Is(err, domain.ErrTemplateNotFound) {
+		t.Errorf("Expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, err := e.Render("welcome", domain.TypeEmail, "", map[string]interface{}{"nmae": "Bob"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a missing variable to be ErrInvalidTemplate, got %v", err)
+	}
+	if err := e.Add(domain.Template{Name: "broken", Body: "Hi {{.name"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected a parse error to be ErrInvalidTemplate, got %v", err)
+	}
+	if _, ok := e.Get("", "broken"); ok {
+		t.Error("Expected a template that doesn't parse not to be added")
+	}
+}
+
+// TestRenderVariants tests that a channel's variant replaces the parts it sets, and that
+// channels without a variant use the template's own
+func TestRenderVariants(t *testing.T) {
+	e := NewEngine()
+	err := e.Add(domain.Template{
+		Name:     "outage",
+		Subject:  "{{.service}} is down",
+		Body:     "{{.service}} has been down since {{.since}}.",
+		HTMLBody: "<p><b>{{.service}}</b> has been down since {{.since}}.</p>",
+		Variants: map[domain.NotificationType]domain.TemplateVariant{
+			domain.TypeSlack: {Body: ":red_circle: *{{.service}}* is down"},
+			domain.TypeSMS:   {Body: "{{upper .service}} DOWN"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	vars := map[string]interface{}{"service": "api", "since": "09:00"}
+
+	tests := []struct {
+		notifType domain.NotificationType
+		want      Rendered
+	}{
+		{domain.TypeEmail, Rendered{Subject: "api is down", Body: "api has been down since 09:00.", HTMLBody: "<p><b>api</b> has been down since 09:00.</p>"}},
+		{domain.TypeSlack, Rendered{Subject: "api is down", Body: ":red_circle: *api* is down", HTMLBody: "<p><b>api</b> has been down since 09:00.</p>"}},
+		{domain.TypeSMS, Rendered{Subject: "api is down", Body: "API DOWN", HTMLBody: "<p><b>api</b> has been down since 09:00.</p>"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.notifType), func(t *testing.T) {
+			rendered, err := e.Render("outage", tt.notifType, "", vars)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if rendered != tt.want {
+				t.Errorf("Render() = %+v, want %+v", rendered, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenderLocales tests that each part comes from the most specific translation that sets
+// it, falling back through the locale's language and configured fallbacks to the template's own
+func TestRenderLocales(t *testing.T) {
+	e := NewEngine()
+	err := e.Add(domain.Template{
+		Name:    "welcome",
+		Subject: "Welcome, {{.name}}",
+		Body:    "Thanks for signing up.",
+		Locales: map[string]domain.TemplateLocale{
+			"fr": {Subject: "Bienvenue, {{.name}}", Body: "Merci de votre inscription."},
+			"pt": {Subject: "Bem-vindo, {{.name}}", Body: "Obrigado por se inscrever."},
+			"pt-BR": {
+				Subject:  "Bem-vindo ao app, {{.name}}",
+				Variants: map[domain.NotificationType]domain.TemplateVariant{domain.TypeSMS: {Body: "Cadastro feito!"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := e.SetLocales("", map[string][]string{"gl": {"pt"}}); err != nil {
+		t.Fatalf("SetLocales() error = %v", err)
+	}
+	vars := map[string]interface{}{"name": "Ana"}
+
+	tests := []struct {
+		name      string
+		locale    string
+		notifType domain.NotificationType
+		want      Rendered
+	}{
+		{"no locale", "", domain.TypeEmail, Rendered{Subject: "Welcome, Ana", Body: "Thanks for signing up."}},
+		{"exact", "fr", domain.TypeEmail, Rendered{Subject: "Bienvenue, Ana", Body: "Merci de votre inscription.", Locale: "fr"}},
+		{"language", "fr-CA", domain.TypeEmail, Rendered{Subject: "Bienvenue, Ana", Body: "Merci de votre inscription.", Locale: "fr"}},
+		{"part from parent", "pt-BR", domain.TypeEmail, Rendered{Subject: "Bem-vindo ao app, Ana", Body: "Obrigado por se inscrever.", Locale: "pt-br"}},
+		{"variant", "pt_br", domain.TypeSMS, Rendered{Subject: "Bem-vindo ao app, Ana", Body: "Cadastro feito!", Locale: "pt-br"}},
+		{"configured fallback", "gl-ES", domain.TypeEmail, Rendered{Subject: "Bem-vindo, Ana", Body: "Obrigado por se inscrever.", Locale: "pt"}},
+		{"no translation", "de", domain.TypeEmail, Rendered{Subject: "Welcome, Ana", Body: "Thanks for signing up."}},
+		{"malformed", "not a locale", domain.TypeEmail, Rendered{Subject: "Welcome, Ana", Body: "Thanks for signing up."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered, err := e.Render("welcome", tt.notifType, tt.locale, vars)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if rendered != tt.want {
+				t.Errorf("Render() = %+v, want %+v", rendered, tt.want)
+			}
+		})
+	}
+
+	t.Run("default locale", func(t *testing.T) {
+		if err := e.SetLocales("fr", nil); err != nil {
+			t.Fatalf("SetLocales() error = %v", err)
+		}
+		rendered, err := e.Render("welcome", domain.TypeEmail, "", vars)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if rendered.Locale != "fr" {
+			t.Errorf("Render() locale = %q, want fr", rendered.Locale)
+		}
+	})
+}
+
+// TestLocaleChain tests the order locales are tried in, and that fallbacks that loop back on
+// themselves end
+func TestLocaleChain(t *testing.T) {
+	e := NewEngine()
+	err := e.SetLocales("", map[string][]string{
+		"pt-BR":   {"pt-PT", "es"},
+		"pt":      {"en"},
+		"es":      {"pt-BR"},
+		"zh_Hant": {"zh-HK"},
+	})
+	if err != nil {
+		t.Fatalf("SetLocales() error = %v", err)
+	}
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{"", nil},
+		{"en", []string{"en"}},
+		{"pt-BR", []string{"pt-br", "pt", "pt-pt", "es", "en"}},
+		{"es-MX", []string{"es-mx", "es", "pt-br", "pt", "pt-pt", "en"}},
+		{"zh-Hant-TW", []string{"zh-hant-tw", "zh-hant", "zh", "zh-hk"}},
+	}
+	for _, tt := range tests {
+		if got := e.chain(tt.locale); !slices.Equal(got, tt.want) {
+			t.Errorf("chain(%q) = %v, want %v", tt.locale, got, tt.want)
+		}
+	}
+
+	if err := e.SetLocales("", map[string][]string{"pt": {"not a locale"}}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for a malformed fallback, got %v", err)
+	}
+}
+
+// TestRenderLayout tests that an email template with a layout has its HTML body wrapped and a
+// text body generated, and that other channels render it unchanged
+func TestRenderLayout(t *testing.T) {
+	e := NewEngine()
+	err := e.Add(domain.Template{
+		Name:     "receipt",
+		Subject:  "Receipt for {{.order}}",
+		HTMLBody: `<h1>Thanks!</h1><p>Order <b>{{.order}}</b> is paid.</p><p><a class="button" href="{{.link}}">View order</a></p>`,
+		Layout:   "responsive",
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := e.SetEmailStyle(emailhtml.Style{AccentColor: "#123456"}); err != nil {
+		t.Fatalf("SetEmailStyle() error = %v", err)
+	}
+	vars := map[string]interface{}{"order": "A-1", "link": "https://example.com/orders/A-1"}
+
+	rendered, err := e.Render("receipt", domain.TypeEmail, "", vars)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := "Thanks!\n\nOrder A-1 is paid.\n\nView order (https://example.com/orders/A-1)"; rendered.Body != want {
+		t.Errorf("Render() body = %q, want %q", rendered.Body, want)
+	}
+	if !strings.HasPrefix(rendered.HTMLBody, "<!DOCTYPE html>") || !strings.Contains(rendered.HTMLBody, "background-color:#123456") {
+		t.Errorf("Expected the HTML body in the layout with the accent color, got %s", rendered.HTMLBody)
+	}
+
+	rendered, err = e.Render("receipt", domain.TypeSlack, "", vars)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if rendered.Body != "" || strings.Contains(rendered.HTMLBody, "<!DOCTYPE html>") {
+		t.Errorf("Expected no layout outside email, got %+v", rendered)
+	}
+
+	for _, def := range []domain.Template{
+		{Name: "fancy", HTMLBody: "<p>Hi</p>", Layout: "fancy"},
+		{Name: "chat", Type: domain.TypeSlack, Body: "Hi", Layout: "responsive"},
+	} {
+		if err := Validate(def); !errors.Is(err, domain.ErrInvalidTemplate) {
+			t.Errorf("Validate(%s) error = %v, want ErrInvalidTemplate", def.Name, err)
+		}
+	}
+	if err := e.SetEmailStyle(emailhtml.Style{AccentColor: "blue"}); !errors.Is(err, domain.ErrInvalidTemplate) {
+		t.Errorf("Expected ErrInvalidTemplate for a named accent color, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -8,6 +9,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -36,6 +39,9 @@ func NewRESTClient(cfg ClientConfig) *RESTClient {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: cfg.TLSInsecure,
 	}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig
+	}
 
 	httpClient := &http.Client{
 		Timeout: cfg.Timeout,
@@ -134,9 +140,52 @@ func (c *RESTClient) GetNotification(ctx context.Context, id string) (*Notificat
 	return &notif, nil
 }
 
-// ListNotifications lists notifications with filters
+// ListNotifications lists a page of notifications matching the filters, newest first. Pass
+// the response's NextCursor as the next request's Cursor to fetch the following page.
 func (c *RESTClient) ListNotifications(ctx context.Context, filter ListNotificationsRequest) (*ListNotificationsResponse, error) {
-	respBody, statusCode, err := c.doRequest(ctx, "GET", "/api/v1/notifications", nil)
+	query := url.Values{}
+	for _, id := range filter.IDs {
+		query.Add("id", id)
+	}
+	for _, t := range filter.Types {
+		query.Add("type", t)
+	}
+	for _, status := range filter.Statuses {
+		query.Add("status", string(status))
+	}
+	for _, recipient := range filter.Recipients {
+		query.Add("recipient", recipient)
+	}
+	for key, value := range filter.Tags {
+		query.Add("tag", key+":"+value)
+	}
+	if filter.FanoutID != "" {
+		query.Set("fanout_id", filter.FanoutID)
+	}
+	if filter.Tenant != "" {
+		query.Set("tenant", filter.Tenant)
+	}
+	if filter.CreatedAfter != nil {
+		query.Set("created_after", filter.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if filter.CreatedBefore != nil {
+		query.Set("created_before", filter.CreatedBefore.Format(time.RFC3339Nano))
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", fmt.Sprint(filter.Limit))
+	}
+	if filter.Offset > 0 {
+		query.Set("offset", fmt.Sprint(filter.Offset))
+	}
+	if filter.Cursor != "" {
+		query.Set("cursor", filter.Cursor)
+	}
+
+	path := "/api/v1/notifications"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	respBody, statusCode, err := c.doRequest(ctx, "GET", path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +275,120 @@ func (c *RESTClient) GetNotifiers(ctx context.Context) (*NotifiersResponse, erro
 	return &resp, nil
 }
 
+// GetVersion returns the server's build information and enabled subsystems
+func (c *RESTClient) GetVersion(ctx context.Context) (*VersionInfo, error) {
+	respBody, statusCode, err := c.doRequest(ctx, "GET", "/api/v1/version", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", statusCode, string(respBody))
+	}
+
+	var info VersionInfo
+	if err := json.Unmarshal(respBody, &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &info, nil
+}
+
+// GetQuotaUsage returns the client's use of its own and its tenant's quotas
+func (c *RESTClient) GetQuotaUsage(ctx context.Context) (*QuotaUsageResponse, error) {
+	respBody, statusCode, err := c.doRequest(ctx, "GET", "/api/v1/quota", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", statusCode, string(respBody))
+	}
+
+	var usage QuotaUsageResponse
+	if err := json.Unmarshal(respBody, &usage); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// Watch streams notification status transitions, calling fn for each event until ctx is
+// cancelled, the server closes the stream, or fn returns an error
+func (c *RESTClient) Watch(ctx context.Context, filter WatchRequest, fn func(StatusEvent) error) error {
+	query := url.Values{}
+	if len(filter.IDs) > 0 {
+		query.Set("id", strings.Join(filter.IDs, ","))
+	}
+	if filter.Type != "" {
+		query.Set("type", filter.Type)
+	}
+	if filter.Origin != "" {
+		query.Set("origin", filter.Origin)
+	}
+
+	endpoint := c.baseURL + "/api/v1/notifications/events"
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+
+	// The stream is long-lived, so the request timeout doesn't apply
+	streamClient := &http.Client{Transport: c.client.Transport}
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends the event
+			if data.Len() == 0 {
+				continue
+			}
+			var event StatusEvent
+			if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+				return fmt.Errorf("failed to unmarshal event: %w", err)
+			}
+			data.Reset()
+			if err := fn(event); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil
+}
+
 // HealthCheck checks service health
 func (c *RESTClient) HealthCheck(ctx context.Context) (bool, error) {
 	url := c.baseURL + "/health"
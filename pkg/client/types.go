@@ -1,15 +1,91 @@
 package client
 
-import "time"
+import (
+	"crypto/tls"
+	"time"
+)
 
 // NotificationRequest represents a notification to send
 type NotificationRequest struct {
-	Type       string            `json:"type"`               // stdout, email, slack, ntfy
+	Type       string            `json:"type"`               // stdout, email, slack, ntfy, discord, pagerduty, fcm, webpush, xmpp, whatsapp, postmark, amqp, file, bark, dingtalk, sms
 	Account    string            `json:"account"`            // Optional: account name (uses default if empty)
 	Subject    string            `json:"subject"`            // Email subject or title
 	Body       string            `json:"body"`               // Notification message body
 	Recipients []string          `json:"recipients"`         // Email addresses, Slack channels, etc.
 	Metadata   map[string]string `json:"metadata,omitempty"` // Optional metadata
+	Options    *SendOptions      `json:"options,omitempty"`  // Optional: typed overrides for the target channel
+	Origin     *Origin           `json:"origin,omitempty"`   // Optional: sending system and triggering user
+	Deadline   *time.Time        `json:"deadline,omitempty"` // Optional: when it must be delivered by
+
+	Template     string                 `json:"template,omitempty"`      // Optional: template to render subject and body from
+	TemplateVars map[string]interface{} `json:"template_vars,omitempty"` // Variables the template is rendered with
+	Locale       string                 `json:"locale,omitempty"`        // Optional: recipient's locale, e.g. "fr" or "pt-BR"; selects the template's translation
+
+	Fallbacks []Fallback `json:"fallbacks,omitempty"` // Optional: channels to try in order if delivery fails permanently
+	Contacts  []string   `json:"contacts,omitempty"`  // Optional: contacts and groups whose addresses on the channel are added to the recipients
+	DedupKey  string     `json:"dedup_key,omitempty"` // Optional: suppresses later notifications with the same key within the dedup window
+
+	Tags map[string]string `json:"tags,omitempty"` // Optional: labels routing rules match and notifications can be listed by
+
+	Targets []Target `json:"targets,omitempty"` // Optional: channels to send through at once, instead of Type and Account
+}
+
+// Target is one channel a fanned-out notification is sent through
+type Target struct {
+	Type       string   `json:"type"`
+	Account    string   `json:"account,omitempty"`    // Uses the type's default account if empty
+	Recipients []string `json:"recipients,omitempty"` // Uses the notification's recipients if empty
+	Contacts   []string `json:"contacts,omitempty"`   // Uses the notification's contacts if empty
+}
+
+// TargetResult is the outcome of sending a fanned-out notification through one of its targets
+type TargetResult struct {
+	Type           string `json:"type"`
+	Account        string `json:"account,omitempty"`
+	NotificationID string `json:"notification_id,omitempty"` // The target's own notification
+	Success        bool   `json:"success"`
+	Message        string `json:"message,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// Fallback is a channel a notification moves on to when it fails permanently
+type Fallback struct {
+	Type       string   `json:"type"`
+	Account    string   `json:"account,omitempty"` // Uses the type's default account if empty
+	Recipients []string `json:"recipients,omitempty"`
+	Contacts   []string `json:"contacts,omitempty"` // Contacts whose addresses on that channel are added to the recipients
+}
+
+// SendOptions are typed provider overrides. Only the block for the notification's type may be set.
+type SendOptions struct {
+	Email *EmailOptions `json:"email,omitempty"` // email and postmark
+	Slack *SlackOptions `json:"slack,omitempty"`
+	Ntfy  *NtfyOptions  `json:"ntfy,omitempty"`
+}
+
+// EmailOptions override how an email is sent
+type EmailOptions struct {
+	ReplyTo string            `json:"reply_to,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"` // Extra headers, e.g. List-Unsubscribe
+}
+
+// SlackOptions override how a Slack message is posted
+type SlackOptions struct {
+	ThreadTS string `json:"thread_ts,omitempty"` // Reply in the thread of this message
+	Icon     string `json:"icon,omitempty"`      // An emoji such as ":rotating_light:" or an image URL
+}
+
+// NtfyOptions override how an ntfy message is published
+type NtfyOptions struct {
+	Tags  []string `json:"tags,omitempty"`
+	Click string   `json:"click,omitempty"` // URL opened when the notification is tapped
+	Delay string   `json:"delay,omitempty"` // A duration such as "30m" or a Unix timestamp
+}
+
+// Origin identifies the system and user that generated a notification
+type Origin struct {
+	System string `json:"system,omitempty"` // Sending service (defaults to the API key's client ID)
+	User   string `json:"user,omitempty"`   // Triggering end user; empty for system-triggered notifications
 }
 
 // NotificationResponse represents the response from sending a notification
@@ -18,18 +94,26 @@ type NotificationResponse struct {
 	Success        bool      `json:"success"`
 	Message        string    `json:"message,omitempty"`
 	Error          string    `json:"error,omitempty"`
+	Suppressed     bool      `json:"suppressed,omitempty"` // A duplicate by dedup key; NotificationID is the original's
 	SentAt         time.Time `json:"sent_at"`
+
+	Targets []TargetResult `json:"targets,omitempty"` // Per-target results of a fanned-out notification
 }
 
 // NotificationStatus represents the status of a notification
 type NotificationStatus string
 
 const (
-	StatusPending  NotificationStatus = "pending"
-	StatusQueued   NotificationStatus = "queued"
-	StatusRetrying NotificationStatus = "retrying"
-	StatusSent     NotificationStatus = "sent"
-	StatusFailed   NotificationStatus = "failed"
+	StatusPending    NotificationStatus = "pending"
+	StatusScheduled  NotificationStatus = "scheduled"
+	StatusDigested   NotificationStatus = "digested"
+	StatusQueued     NotificationStatus = "queued"
+	StatusProcessing NotificationStatus = "processing"
+	StatusRetrying   NotificationStatus = "retrying"
+	StatusHeld       NotificationStatus = "held"
+	StatusPaused     NotificationStatus = "paused"
+	StatusSent       NotificationStatus = "sent"
+	StatusFailed     NotificationStatus = "failed"
 )
 
 // Notification represents a notification with full details
@@ -39,24 +123,108 @@ type Notification struct {
 	Account    string             `json:"account"`
 	Subject    string             `json:"subject"`
 	Body       string             `json:"body"`
+	Template   string             `json:"template,omitempty"` // Template the subject and body were rendered from
+	Locale     string             `json:"locale,omitempty"`   // Locale the template was rendered for
 	Recipients []string           `json:"recipients"`
+	Contacts   []string           `json:"contacts,omitempty"` // Contacts whose addresses were added to the recipients
 	Status     NotificationStatus `json:"status"`
 	RetryCount int                `json:"retry_count"`
 	MaxRetries int                `json:"max_retries"`
 	LastError  string             `json:"last_error,omitempty"`
+	Links      map[string]string  `json:"links,omitempty"` // Recipient to a URL of the delivered message
 	CreatedAt  time.Time          `json:"created_at"`
 	SentAt     *time.Time         `json:"sent_at,omitempty"`
 	Metadata   map[string]string  `json:"metadata,omitempty"`
+	Tags       map[string]string  `json:"tags,omitempty"`
+	Origin     Origin             `json:"origin"`
+	Tenant     string             `json:"tenant,omitempty"` // Tenant of the client that sent it
+	DedupKey   string             `json:"dedup_key,omitempty"`
+
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"` // When a retrying notification is next attempted
+
+	Deadline       *time.Time `json:"deadline,omitempty"`
+	DeadlineMissed bool       `json:"deadline_missed,omitempty"` // Delivered after the deadline, or failed
+
+	Fallbacks  []Fallback `json:"fallbacks,omitempty"`   // Channels still to try if this one fails permanently
+	FallbackOf string     `json:"fallback_of,omitempty"` // Failed notification this one was sent in place of
+	FallbackID string     `json:"fallback_id,omitempty"` // Notification sent in place of this one after it failed
+
+	FanoutID string `json:"fanout_id,omitempty"` // Fanned-out notification this one was sent for, one per target
+
+	DigestID string   `json:"digest_id,omitempty"` // Digest this notification was combined into, once it's sent
+	DigestOf []string `json:"digest_of,omitempty"` // Notifications a digest combines
+
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+}
+
+// PolicyViolation is a content policy rule a notification matched
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Detector string `json:"detector"`
+	Field    string `json:"field"`
+	Match    string `json:"match"`  // Masked excerpt of the matched text
+	Action   string `json:"action"` // warn, hold or block
 }
 
 // NotificationStats represents statistics about notifications
 type NotificationStats struct {
-	TotalSent    int64            `json:"total_sent"`
-	TotalFailed  int64            `json:"total_failed"`
-	TotalPending int64            `json:"total_pending"`
-	TotalQueued  int64            `json:"total_queued"`
-	ByType       map[string]int64 `json:"by_type"`
-	ByStatus     map[string]int64 `json:"by_status"`
+	TotalSent       int64                  `json:"total_sent"`
+	TotalFailed     int64                  `json:"total_failed"`
+	TotalPending    int64                  `json:"total_pending"`
+	TotalQueued     int64                  `json:"total_queued"`
+	TotalSuppressed int64                  `json:"total_suppressed"` // Duplicates suppressed by dedup key
+	ByType          map[string]int64       `json:"by_type"`
+	ByStatus        map[string]int64       `json:"by_status"`
+	ByOrigin        map[string]OriginStats `json:"by_origin"`
+	Budgets         []BudgetStatus         `json:"budgets,omitempty"`
+	Delivery        DeliveryStats          `json:"delivery"`
+}
+
+// DeliveryStats reports delivery latency, measured from when notifications were due, and
+// deadline misses. Latency percentiles cover recent deliveries.
+type DeliveryStats struct {
+	Delivered      int64   `json:"delivered"`
+	Failed         int64   `json:"failed"`
+	LatencyP50Ms   float64 `json:"latency_p50_ms"`
+	LatencyP95Ms   float64 `json:"latency_p95_ms"`
+	DeadlineMet    int64   `json:"deadline_met"`
+	DeadlineMissed int64   `json:"deadline_missed"`
+}
+
+// BudgetStatus reports today's usage of an origin's daily budget
+type BudgetStatus struct {
+	Origin   string `json:"origin"`
+	Type     string `json:"type,omitempty"`
+	Limit    int64  `json:"limit"`
+	Used     int64  `json:"used"`
+	Action   string `json:"action"` // alert or block
+	Exceeded bool   `json:"exceeded"`
+}
+
+// OriginStats breaks down the notifications generated by one origin system
+type OriginStats struct {
+	Total         int64            `json:"total"`
+	UserTriggered int64            `json:"user_triggered"`
+	ByType        map[string]int64 `json:"by_type"`
+}
+
+// StatusEvent is a notification status transition received from the event stream
+type StatusEvent struct {
+	NotificationID string             `json:"notification_id"`
+	Type           string             `json:"type"`
+	Account        string             `json:"account,omitempty"`
+	Origin         Origin             `json:"origin"`
+	Status         NotificationStatus `json:"status"`
+	RetryCount     int                `json:"retry_count"`
+	Error          string             `json:"error,omitempty"`
+	At             time.Time          `json:"at"`
+}
+
+// WatchRequest restricts which status events are streamed
+type WatchRequest struct {
+	IDs    []string // Only these notification IDs
+	Type   string   // Only this notification type
+	Origin string   // Only this origin system
 }
 
 // ListNotificationsRequest represents filters for listing notifications
@@ -65,16 +233,21 @@ type ListNotificationsRequest struct {
 	Types         []string             `json:"types,omitempty"`
 	Statuses      []NotificationStatus `json:"statuses,omitempty"`
 	Recipients    []string             `json:"recipients,omitempty"`
+	Tags          map[string]string    `json:"tags,omitempty"`      // Only notifications carrying every one of these tags
+	FanoutID      string               `json:"fanout_id,omitempty"` // Only the targets of one fanned-out notification
+	Tenant        string               `json:"tenant,omitempty"`    // Only one tenant's notifications; tenant clients only see their own
 	CreatedAfter  *time.Time           `json:"created_after,omitempty"`
 	CreatedBefore *time.Time           `json:"created_before,omitempty"`
 	Offset        int                  `json:"offset,omitempty"`
 	Limit         int                  `json:"limit,omitempty"`
+	Cursor        string               `json:"cursor,omitempty"` // NextCursor from the previous page
 }
 
 // ListNotificationsResponse represents the response from listing notifications
 type ListNotificationsResponse struct {
 	Notifications []*Notification `json:"notifications"`
-	Total         int             `json:"total"`
+	Total         int             `json:"total"`                 // Notifications matching the filters across all pages
+	NextCursor    string          `json:"next_cursor,omitempty"` // Cursor for the next page; empty on the last page
 }
 
 // NotifierInfo represents information about an available notifier
@@ -82,6 +255,7 @@ type NotifierInfo struct {
 	Type           string   `json:"type"`
 	Accounts       []string `json:"accounts"`
 	DefaultAccount string   `json:"default_account"`
+	PausedAccounts []string `json:"paused_accounts,omitempty"` // Accounts whose dispatch is paused
 }
 
 // NotifiersResponse represents available notifiers
@@ -89,6 +263,46 @@ type NotifiersResponse struct {
 	Notifiers []NotifierInfo `json:"notifiers"`
 }
 
+// VersionInfo describes a server's build and the subsystems it has enabled
+type VersionInfo struct {
+	Version   string   `json:"version"`
+	GitCommit string   `json:"git_commit"`
+	BuildTime string   `json:"build_time"`
+	Profile   string   `json:"profile"`   // Build profile: full or lite
+	Features  []string `json:"features"`  // Enabled subsystems, sorted
+	Notifiers []string `json:"notifiers"` // Configured notification types
+}
+
+// HasFeature reports whether the server has a subsystem enabled
+func (v *VersionInfo) HasFeature(feature string) bool {
+	for _, f := range v.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// QuotaCounter is the current use of one quota
+type QuotaCounter struct {
+	Limit     int64     `json:"limit"`
+	Used      int64     `json:"used"`
+	Remaining int64     `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// QuotaUsage is a client's or tenant's use of its quotas. Quotas that aren't set are nil.
+type QuotaUsage struct {
+	Subject             string        `json:"subject"` // "client:<id>" or "tenant:<name>"
+	RequestsPerMinute   *QuotaCounter `json:"requests_per_minute,omitempty"`
+	NotificationsPerDay *QuotaCounter `json:"notifications_per_day,omitempty"`
+}
+
+// QuotaUsageResponse is the response from the quota usage endpoint
+type QuotaUsageResponse struct {
+	Quotas []QuotaUsage `json:"quotas"`
+}
+
 // ClientConfig contains configuration for the client
 type ClientConfig struct {
 	BaseURL      string        // Base URL for REST API (e.g., "http://localhost:8080")
@@ -99,4 +313,6 @@ type ClientConfig struct {
 	// TLSInsecure disables TLS verification - ONLY for testing with self-signed certs in dev/test environments
 	// NEVER set this to true in production. Use proper certificates or provide custom CA certificates instead.
 	TLSInsecure bool
+	// TLSConfig sets a custom CA or a client certificate for servers requiring mutual TLS (overrides TLSInsecure)
+	TLSConfig *tls.Config
 }
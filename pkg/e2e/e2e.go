@@ -0,0 +1,168 @@
+// Package e2e encrypts notification payloads to a recipient's public key so intermediaries
+// (ntfy servers, push providers) can relay them without being able to read them.
+//
+// Payloads are sealed with an ephemeral X25519 key exchange, HKDF-SHA256 and AES-256-GCM.
+// A sealed payload is the string "e2e1:" followed by the standard base64 encoding of
+// ephemeral public key (32 bytes) || nonce (12 bytes) || ciphertext.
+package e2e
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Prefix marks a sealed payload and its format version
+const Prefix = "e2e1:"
+
+// hkdfInfo binds derived keys to this format
+const hkdfInfo = "notifier-e2e-v1"
+
+// Payload is the plaintext content sealed into a message
+type Payload struct {
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// GenerateKey returns a new base64-encoded X25519 key pair
+func GenerateKey() (publicKey, privateKey string, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.StdEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// ParsePublicKey decodes a base64-encoded X25519 public key
+func ParsePublicKey(encoded string) (*ecdh.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return key, nil
+}
+
+// ParsePrivateKey decodes a base64-encoded X25519 private key
+func ParsePrivateKey(encoded string) (*ecdh.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key encoding: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return key, nil
+}
+
+// SealPayload encrypts a payload to the recipient's public key
+func SealPayload(recipient *ecdh.PublicKey, payload Payload) (string, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return Seal(recipient, plaintext)
+}
+
+// OpenPayload decrypts a payload sealed by SealPayload
+func OpenPayload(private *ecdh.PrivateKey, sealed string) (*Payload, error) {
+	plaintext, err := Open(private, sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	return &payload, nil
+}
+
+// Seal encrypts plaintext to the recipient's public key
+func Seal(recipient *ecdh.PublicKey, plaintext []byte) (string, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	ephemeralPub := ephemeral.PublicKey().Bytes()
+	aead, err := deriveAEAD(ephemeral, recipient, ephemeralPub)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(ephemeralPub)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, ephemeralPub...)
+	out = append(out, nonce...)
+	out = aead.Seal(out, nonce, plaintext, ephemeralPub)
+	return Prefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+// Open decrypts a sealed payload with the recipient's private key
+func Open(private *ecdh.PrivateKey, sealed string) ([]byte, error) {
+	if !strings.HasPrefix(sealed, Prefix) {
+		return nil, fmt.Errorf("not a sealed payload")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, Prefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sealed payload encoding: %w", err)
+	}
+
+	const keySize, nonceSize = 32, 12
+	if len(raw) < keySize+nonceSize {
+		return nil, fmt.Errorf("sealed payload too short")
+	}
+
+	ephemeralPub := raw[:keySize]
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+
+	aead, err := deriveAEAD(private, ephemeral, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, raw[keySize:keySize+nonceSize], raw[keySize+nonceSize:], ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// deriveAEAD derives the AES-256-GCM key from the X25519 shared secret
+func deriveAEAD(private *ecdh.PrivateKey, peer *ecdh.PublicKey, salt []byte) (cipher.AEAD, error) {
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("key exchange failed: %w", err)
+	}
+
+	key, err := hkdf.Key(sha256.New, shared, salt, hkdfInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSealOpenRoundTrip tests that a sealed payload opens with the matching private key only
+func TestSealOpenRoundTrip(t *testing.T) {
+	pubB64, privB64, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	pub, err := ParsePublicKey(pubB64)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+	priv, err := ParsePrivateKey(privB64)
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+
+	sealed, err := SealPayload(pub, Payload{Title: "DB password rotated", Message: "new password: hunter2"})
+	if err != nil {
+		t.Fatalf("SealPayload() error = %v", err)
+	}
+	if !strings.HasPrefix(sealed, Prefix) || strings.Contains(sealed, "hunter2") {
+		t.Fatalf("Unexpected sealed payload: %s", sealed)
+	}
+
+	payload, err := OpenPayload(priv, sealed)
+	if err != nil {
+		t.Fatalf("OpenPayload() error = %v", err)
+	}
+	if payload.Title != "DB password rotated" || payload.Message != "new password: hunter2" {
+		t.Errorf("OpenPayload() = %+v", payload)
+	}
+
+	// A different key can't open it
+	_, otherB64, _ := GenerateKey()
+	other, _ := ParsePrivateKey(otherB64)
+	if _, err := OpenPayload(other, sealed); err == nil {
+		t.Error("Expected decryption with the wrong key to fail")
+	}
+}
+
+// TestOpenRejectsTampering tests that modified or malformed payloads are rejected
+func TestOpenRejectsTampering(t *testing.T) {
+	pubB64, privB64, _ := GenerateKey()
+	pub, _ := ParsePublicKey(pubB64)
+	priv, _ := ParsePrivateKey(privB64)
+
+	sealed, err := Seal(pub, []byte("secret"))
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+
+	// Flip a character in the ciphertext portion
+	tampered := []byte(sealed)
+	idx := len(tampered) - 5
+	if tampered[idx] == 'A' {
+		tampered[idx] = 'B'
+	} else {
+		tampered[idx] = 'A'
+	}
+
+	for name, input := range map[string]string{
+		"tampered":   string(tampered),
+		"no prefix":  strings.TrimPrefix(sealed, Prefix),
+		"too short":  Prefix + "AAAA",
+		"bad base64": Prefix + "!!!",
+	} {
+		if _, err := Open(priv, input); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}